@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kiwari-pos/api/internal/config"
 	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/handler"
 	"github.com/kiwari-pos/api/internal/router"
 	"github.com/kiwari-pos/api/internal/ws"
 )
@@ -43,8 +44,27 @@ func main() {
 	go hub.Run()
 	log.Println("WebSocket hub started")
 
+	// Pick how events reach the hub: directly, or through Postgres so every replica sees them
+	var events handler.Broadcaster
+	switch cfg.WSBackend {
+	case "memory":
+		events = hub
+	case "postgres":
+		relay := ws.NewPGRelay(pool, hub)
+		go relay.Publish(ctx)
+		go relay.Listen(ctx)
+		events = relay
+		log.Println("WebSocket events relayed through Postgres")
+	default:
+		log.Fatalf("Unknown WS_BACKEND %q (want memory or postgres)", cfg.WSBackend)
+	}
+
+	// Alert kitchen displays about items past their preparation time
+	lateChecker := handler.NewLateItemChecker(queries, events)
+	go lateChecker.Run(ctx, time.Minute)
+
 	// Create router with all handlers wired
-	r := router.New(cfg, queries, pool, hub)
+	r := router.New(cfg, queries, pool, hub, events)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -0,0 +1,635 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// --- Store interface ---
+
+// SalesStore defines the database methods needed by sales handlers.
+// Satisfied by *database.Queries (and its WithTx variant).
+type SalesStore interface {
+	GetPOSDailySales(ctx context.Context, arg database.GetPOSDailySalesParams) ([]database.GetPOSDailySalesRow, error)
+	LockPOSSalesDay(ctx context.Context, lockKey string) error
+	ListPOSSalesSummaries(ctx context.Context, arg database.ListPOSSalesSummariesParams) ([]database.AcctSalesDailySummary, error)
+	UpsertPOSSalesSummary(ctx context.Context, arg database.UpsertPOSSalesSummaryParams) (database.AcctSalesDailySummary, error)
+	ListSalesSummaryPostings(ctx context.Context, salesSummaryID pgtype.UUID) ([]database.ListSalesSummaryPostingsRow, error)
+	ListAcctSalesDailySummaries(ctx context.Context, arg database.ListAcctSalesDailySummariesParams) ([]database.AcctSalesDailySummary, error)
+	GetDefaultSalesAccount(ctx context.Context) (database.AcctAccount, error)
+	ListAcctSalesPaymentAccounts(ctx context.Context, outletID uuid.UUID) ([]database.AcctSalesPaymentAccount, error)
+	DeleteAcctSalesPaymentAccounts(ctx context.Context, outletID uuid.UUID) error
+	CreateAcctSalesPaymentAccount(ctx context.Context, arg database.CreateAcctSalesPaymentAccountParams) (database.AcctSalesPaymentAccount, error)
+	GetAcctCashAccount(ctx context.Context, id uuid.UUID) (database.AcctCashAccount, error)
+	CreateAcctCashTransaction(ctx context.Context, arg database.CreateAcctCashTransactionParams) (database.AcctCashTransaction, error)
+	GetNextTransactionCode(ctx context.Context) (string, error)
+}
+
+// NewSalesStore creates a SalesStore from a DBTX (pool or tx).
+type NewSalesStore func(db database.DBTX) SalesStore
+
+// --- SalesHandler ---
+
+// SalesHandler handles daily sales summaries and the end-of-day close that
+// posts POS sales into the cash journal.
+type SalesHandler struct {
+	store    SalesStore
+	pool     service.TxBeginner
+	newStore NewSalesStore
+}
+
+// NewSalesHandler creates a new SalesHandler.
+func NewSalesHandler(store SalesStore, pool service.TxBeginner, newStore NewSalesStore) *SalesHandler {
+	return &SalesHandler{store: store, pool: pool, newStore: newStore}
+}
+
+// RegisterRoutes registers sales endpoints.
+func (h *SalesHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.ListSummaries)
+	r.Post("/close", h.CloseDay)
+	r.Get("/payment-accounts/{outlet_id}", h.ListPaymentAccounts)
+	r.Put("/payment-accounts/{outlet_id}", h.SetPaymentAccounts)
+}
+
+// --- Request / Response types ---
+
+type closeSalesDayRequest struct {
+	OutletID  string  `json:"outlet_id"`  // UUID
+	SalesDate string  `json:"sales_date"` // "2026-01-20"
+	AccountID *string `json:"account_id"` // optional UUID (sales account), defaults to the first active SALES account
+}
+
+type closeSalesDayResponse struct {
+	OutletID     uuid.UUID              `json:"outlet_id"`
+	SalesDate    string                 `json:"sales_date"`
+	Summaries    []salesSummaryResponse `json:"summaries"`
+	Transactions []transactionResponse  `json:"transactions"` // posted by this close; empty when nothing changed
+}
+
+type salesSummaryResponse struct {
+	ID             uuid.UUID `json:"id"`
+	SalesDate      string    `json:"sales_date"`
+	Channel        string    `json:"channel"`
+	PaymentMethod  string    `json:"payment_method"`
+	GrossSales     string    `json:"gross_sales"`
+	DiscountAmount string    `json:"discount_amount"`
+	NetSales       string    `json:"net_sales"`
+	CashAccountID  uuid.UUID `json:"cash_account_id"`
+	OutletID       *string   `json:"outlet_id"`
+	Source         string    `json:"source"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type paymentAccountRequest struct {
+	PaymentMethod string `json:"payment_method"`
+	CashAccountID string `json:"cash_account_id"` // UUID
+}
+
+type setPaymentAccountsRequest struct {
+	Accounts []paymentAccountRequest `json:"accounts"`
+}
+
+type paymentAccountResponse struct {
+	PaymentMethod string    `json:"payment_method"`
+	CashAccountID uuid.UUID `json:"cash_account_id"`
+}
+
+func toSalesSummaryResponse(s database.AcctSalesDailySummary) salesSummaryResponse {
+	var outletID *string
+	if s.OutletID.Valid {
+		id := uuid.UUID(s.OutletID.Bytes).String()
+		outletID = &id
+	}
+	return salesSummaryResponse{
+		ID:             s.ID,
+		SalesDate:      s.SalesDate.Time.Format("2006-01-02"),
+		Channel:        s.Channel,
+		PaymentMethod:  s.PaymentMethod,
+		GrossSales:     numericToString(s.GrossSales),
+		DiscountAmount: numericToString(s.DiscountAmount),
+		NetSales:       numericToString(s.NetSales),
+		CashAccountID:  s.CashAccountID,
+		OutletID:       outletID,
+		Source:         s.Source,
+		CreatedAt:      s.CreatedAt,
+	}
+}
+
+// --- Handlers ---
+
+// ListSummaries returns daily sales summaries, POS and manual, between
+// start_date and end_date with an optional outlet_id filter.
+func (h *SalesHandler) ListSummaries(w http.ResponseWriter, r *http.Request) {
+	startDate, err := parseDateParam(r, "start_date")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid start_date format, expected YYYY-MM-DD"})
+		return
+	}
+	endDate, err := parseDateParam(r, "end_date")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid end_date format, expected YYYY-MM-DD"})
+		return
+	}
+	if !startDate.Valid || !endDate.Valid {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "start_date and end_date are required"})
+		return
+	}
+	outletID, err := parseOptionalUUIDParam(r, "outlet_id")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet_id"})
+		return
+	}
+
+	summaries, err := h.store.ListAcctSalesDailySummaries(r.Context(), database.ListAcctSalesDailySummariesParams{
+		StartDate: startDate,
+		EndDate:   endDate,
+		OutletID:  outletID,
+	})
+	if err != nil {
+		log.Printf("ERROR: list sales summaries: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]salesSummaryResponse, len(summaries))
+	for i, s := range summaries {
+		resp[i] = toSalesSummaryResponse(s)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CloseDay aggregates an outlet's completed POS orders for a day into
+// summaries by channel and payment method, and posts each summary's net
+// sales as a SALES cash transaction to the cash account its payment method
+// is mapped to. Closing a day again only posts what changed since the last
+// close (refunds, late completions, a remapped payment method), so a close
+// with nothing new posts nothing.
+func (h *SalesHandler) CloseDay(w http.ResponseWriter, r *http.Request) {
+	var req closeSalesDayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if req.OutletID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "outlet_id is required"})
+		return
+	}
+	if req.SalesDate == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "sales_date is required"})
+		return
+	}
+
+	outletID, err := uuid.Parse(req.OutletID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet_id"})
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	dayStart, err := time.ParseInLocation("2006-01-02", req.SalesDate, loc)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid sales_date format, expected YYYY-MM-DD"})
+		return
+	}
+	if dayStart.After(time.Now()) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "sales_date cannot be in the future"})
+		return
+	}
+	date, _ := time.Parse("2006-01-02", req.SalesDate)
+	pgDate := pgtype.Date{Time: date, Valid: true}
+
+	// Sales account to credit
+	var accountID uuid.UUID
+	if req.AccountID != nil && *req.AccountID != "" {
+		accountID, err = uuid.Parse(*req.AccountID)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid account_id"})
+			return
+		}
+	} else {
+		account, err := h.store.GetDefaultSalesAccount(r.Context())
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "no active SALES account, pass account_id"})
+				return
+			}
+			log.Printf("ERROR: get default sales account: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		accountID = account.ID
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	txStore := h.newStore(tx)
+
+	// Two closes of the same day at once would both post the difference
+	if err := txStore.LockPOSSalesDay(r.Context(), fmt.Sprintf("acct_sales:%s:%s", outletID, req.SalesDate)); err != nil {
+		log.Printf("ERROR: lock sales day: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	mappings, err := txStore.ListAcctSalesPaymentAccounts(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list sales payment accounts: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	cashAccounts := make(map[string]uuid.UUID, len(mappings))
+	for _, m := range mappings {
+		cashAccounts[m.PaymentMethod] = m.CashAccountID
+	}
+
+	rows, err := txStore.GetPOSDailySales(r.Context(), database.GetPOSDailySalesParams{
+		OutletID: outletID,
+		DayStart: dayStart,
+		DayEnd:   dayStart.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		log.Printf("ERROR: get pos daily sales: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	existing, err := txStore.ListPOSSalesSummaries(r.Context(), database.ListPOSSalesSummariesParams{
+		SalesDate: pgDate,
+		OutletID:  uuidToPgUUID(outletID),
+	})
+	if err != nil {
+		log.Printf("ERROR: list pos sales summaries: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	lines, unmapped := buildSalesLines(rows, existing, cashAccounts)
+	if len(unmapped) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"error": "no cash account mapped for payment methods: " + strings.Join(unmapped, ", "),
+		})
+		return
+	}
+
+	// Transaction codes are only read once something needs posting
+	nextNum := 0
+	summaries := make([]salesSummaryResponse, 0, len(lines))
+	transactions := []transactionResponse{}
+	for _, line := range lines {
+		summary, err := txStore.UpsertPOSSalesSummary(r.Context(), database.UpsertPOSSalesSummaryParams{
+			SalesDate:      pgDate,
+			Channel:        line.channel,
+			PaymentMethod:  line.paymentMethod,
+			GrossSales:     decimalToPgNumeric(line.net.Add(line.discount)),
+			DiscountAmount: decimalToPgNumeric(line.discount),
+			NetSales:       decimalToPgNumeric(line.net),
+			CashAccountID:  line.cashAccountID,
+			OutletID:       uuidToPgUUID(outletID),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusConflict, map[string]string{
+					"error": fmt.Sprintf("a manual sales summary already exists for %s %s", line.channel, line.paymentMethod),
+				})
+				return
+			}
+			log.Printf("ERROR: upsert sales summary: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		summaries = append(summaries, toSalesSummaryResponse(summary))
+
+		posted, err := txStore.ListSalesSummaryPostings(r.Context(), uuidToPgUUID(summary.ID))
+		if err != nil {
+			log.Printf("ERROR: list sales summary postings: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+
+		description := fmt.Sprintf("POS sales %s %s", line.channel, line.paymentMethod)
+		if len(posted) > 0 {
+			description = fmt.Sprintf("POS sales correction %s %s", line.channel, line.paymentMethod)
+		}
+
+		for _, p := range salesPostings(line.cashAccountID, line.net, posted) {
+			if nextNum == 0 {
+				maxCode, err := txStore.GetNextTransactionCode(r.Context())
+				if err != nil {
+					log.Printf("ERROR: get next transaction code: %v", err)
+					writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+					return
+				}
+				nextNum, err = strconv.Atoi(maxCode[3:]) // Extract numeric suffix from "PCS000000"
+				if err != nil {
+					log.Printf("ERROR: parse transaction code: %v", err)
+					writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+					return
+				}
+				nextNum++
+			}
+
+			amount := decimalToPgNumeric(p.amount)
+			cashTx, err := txStore.CreateAcctCashTransaction(r.Context(), database.CreateAcctCashTransactionParams{
+				TransactionCode: fmt.Sprintf("PCS%06d", nextNum),
+				TransactionDate: pgDate,
+				Description:     description,
+				Quantity:        decimalToPgNumeric(decimal.NewFromInt(1)),
+				UnitPrice:       amount,
+				Amount:          amount,
+				LineType:        "SALES",
+				AccountID:       accountID,
+				CashAccountID:   uuidToPgUUID(p.cashAccountID),
+				OutletID:        uuidToPgUUID(outletID),
+				SalesSummaryID:  uuidToPgUUID(summary.ID),
+			})
+			if err != nil {
+				log.Printf("ERROR: create cash transaction: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+			nextNum++
+
+			transactions = append(transactions, transactionResponse{
+				ID:              cashTx.ID,
+				TransactionCode: cashTx.TransactionCode,
+				TransactionDate: req.SalesDate,
+				Description:     cashTx.Description,
+				Quantity:        "1.00",
+				UnitPrice:       p.amount.StringFixed(2),
+				Amount:          p.amount.StringFixed(2),
+				LineType:        cashTx.LineType,
+				CreatedAt:       cashTx.CreatedAt,
+			})
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit sales close: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, closeSalesDayResponse{
+		OutletID:     outletID,
+		SalesDate:    req.SalesDate,
+		Summaries:    summaries,
+		Transactions: transactions,
+	})
+}
+
+// ListPaymentAccounts returns which cash account each POS payment method of
+// an outlet is posted to.
+func (h *SalesHandler) ListPaymentAccounts(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "outlet_id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet_id"})
+		return
+	}
+
+	mappings, err := h.store.ListAcctSalesPaymentAccounts(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list sales payment accounts: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]paymentAccountResponse, len(mappings))
+	for i, m := range mappings {
+		resp[i] = paymentAccountResponse{PaymentMethod: m.PaymentMethod, CashAccountID: m.CashAccountID}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// SetPaymentAccounts replaces an outlet's payment method to cash account
+// mapping. Days already closed keep their postings until they are closed
+// again, which moves them to the new accounts.
+func (h *SalesHandler) SetPaymentAccounts(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "outlet_id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet_id"})
+		return
+	}
+
+	var req setPaymentAccountsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	params := make([]database.CreateAcctSalesPaymentAccountParams, 0, len(req.Accounts))
+	seen := make(map[string]bool, len(req.Accounts))
+	for _, a := range req.Accounts {
+		method := strings.ToUpper(strings.TrimSpace(a.PaymentMethod))
+		if method == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "payment_method is required"})
+			return
+		}
+		if method == enum.PaymentMethodPoints {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "POINTS payments are a discount, not cash"})
+			return
+		}
+		if seen[method] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "duplicate payment_method: " + method})
+			return
+		}
+		seen[method] = true
+
+		cashAccountID, err := uuid.Parse(a.CashAccountID)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cash_account_id"})
+			return
+		}
+		if _, err := h.store.GetAcctCashAccount(r.Context(), cashAccountID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cash account not found"})
+				return
+			}
+			log.Printf("ERROR: get cash account: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+
+		params = append(params, database.CreateAcctSalesPaymentAccountParams{
+			OutletID:      outletID,
+			PaymentMethod: method,
+			CashAccountID: cashAccountID,
+		})
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	txStore := h.newStore(tx)
+
+	if err := txStore.DeleteAcctSalesPaymentAccounts(r.Context(), outletID); err != nil {
+		log.Printf("ERROR: delete sales payment accounts: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	resp := make([]paymentAccountResponse, 0, len(params))
+	for _, p := range params {
+		m, err := txStore.CreateAcctSalesPaymentAccount(r.Context(), p)
+		if err != nil {
+			log.Printf("ERROR: create sales payment account: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		resp = append(resp, paymentAccountResponse{PaymentMethod: m.PaymentMethod, CashAccountID: m.CashAccountID})
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit sales payment accounts: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// --- Close helpers ---
+
+// salesLine is one summary row of a day being closed.
+type salesLine struct {
+	channel       string
+	paymentMethod string
+	discount      decimal.Decimal
+	net           decimal.Decimal
+	cashAccountID uuid.UUID
+}
+
+// salesPosting is a cash transaction a close needs to post.
+type salesPosting struct {
+	cashAccountID uuid.UUID
+	amount        decimal.Decimal
+}
+
+// buildSalesLines merges the day's POS sales into one line per channel and
+// payment method, each with the cash account its method is mapped to.
+// Summaries from an earlier close with no sales left (every order refunded)
+// come back as zero lines on their old cash account so their postings get
+// reversed. Payment methods with sales but no mapping are returned in
+// unmapped.
+func buildSalesLines(rows []database.GetPOSDailySalesRow, existing []database.AcctSalesDailySummary, cashAccounts map[string]uuid.UUID) (lines []salesLine, unmapped []string) {
+	index := make(map[string]int)
+	for _, row := range rows {
+		channel := salesChannel(row.OrderType, row.DeliveryPlatform)
+		key := channel + "\x00" + row.PaymentMethod
+		i, ok := index[key]
+		if !ok {
+			cashAccountID, mapped := cashAccounts[row.PaymentMethod]
+			if !mapped && !slices.Contains(unmapped, row.PaymentMethod) {
+				unmapped = append(unmapped, row.PaymentMethod)
+			}
+			i = len(lines)
+			index[key] = i
+			lines = append(lines, salesLine{channel: channel, paymentMethod: row.PaymentMethod, cashAccountID: cashAccountID})
+		}
+		lines[i].discount = lines[i].discount.Add(pgNumericToDecimal(row.DiscountAmount))
+		lines[i].net = lines[i].net.Add(pgNumericToDecimal(row.NetSales))
+	}
+
+	for _, s := range existing {
+		if _, ok := index[s.Channel+"\x00"+s.PaymentMethod]; ok {
+			continue
+		}
+		cashAccountID, ok := cashAccounts[s.PaymentMethod]
+		if !ok {
+			cashAccountID = s.CashAccountID
+		}
+		lines = append(lines, salesLine{channel: s.Channel, paymentMethod: s.PaymentMethod, cashAccountID: cashAccountID})
+	}
+
+	slices.SortFunc(lines, func(a, b salesLine) int {
+		if c := strings.Compare(a.channel, b.channel); c != 0 {
+			return c
+		}
+		return strings.Compare(a.paymentMethod, b.paymentMethod)
+	})
+	slices.Sort(unmapped)
+	return lines, unmapped
+}
+
+// salesChannel names the channel an order was sold through, in the labels
+// the sales sheet used. Delivery orders go by their platform (GoFood,
+// ShopeeFood).
+func salesChannel(orderType, deliveryPlatform string) string {
+	switch orderType {
+	case enum.OrderTypeDineIn:
+		return "Dine In"
+	case enum.OrderTypeTakeaway:
+		return "Take Away"
+	case enum.OrderTypeCatering:
+		return "Catering"
+	case enum.OrderTypeDelivery:
+		if p := strings.TrimSpace(deliveryPlatform); p != "" {
+			return p
+		}
+		return "Delivery"
+	}
+	return orderType
+}
+
+// salesPostings returns the cash transactions that bring what has been
+// posted for a summary to net on cashAccountID: amounts on other cash
+// accounts (the payment method was remapped) are reversed, and the
+// difference is posted to cashAccountID. Nothing is returned when the
+// postings already match.
+func salesPostings(cashAccountID uuid.UUID, net decimal.Decimal, posted []database.ListSalesSummaryPostingsRow) []salesPosting {
+	var postings []salesPosting
+	onAccount := decimal.Zero
+	for _, p := range posted {
+		amount := pgNumericToDecimal(p.Amount)
+		if uuid.UUID(p.CashAccountID.Bytes) == cashAccountID {
+			onAccount = onAccount.Add(amount)
+			continue
+		}
+		if !amount.IsZero() {
+			postings = append(postings, salesPosting{cashAccountID: uuid.UUID(p.CashAccountID.Bytes), amount: amount.Neg()})
+		}
+	}
+	if diff := net.Sub(onAccount); !diff.IsZero() {
+		postings = append(postings, salesPosting{cashAccountID: cashAccountID, amount: diff})
+	}
+	return postings
+}
+
+// pgNumericToDecimal converts pgtype.Numeric to decimal.Decimal, zero for NULL.
+func pgNumericToDecimal(n pgtype.Numeric) decimal.Decimal {
+	d, _ := decimal.NewFromString(numericToString(n))
+	return d
+}
+
+// decimalToPgNumeric converts decimal.Decimal to pgtype.Numeric with 2 decimal places.
+func decimalToPgNumeric(d decimal.Decimal) pgtype.Numeric {
+	s := d.StringFixed(2)
+	return stringToPgNumeric(&s)
+}
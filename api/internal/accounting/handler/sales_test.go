@@ -0,0 +1,487 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/accounting/handler"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock transaction ---
+
+type mockTx struct{}
+
+func (m *mockTx) Begin(ctx context.Context) (pgx.Tx, error) { return nil, nil }
+func (m *mockTx) Commit(ctx context.Context) error          { return nil }
+func (m *mockTx) Rollback(ctx context.Context) error        { return nil }
+func (m *mockTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, nil
+}
+func (m *mockTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults { return nil }
+func (m *mockTx) LargeObjects() pgx.LargeObjects                               { return pgx.LargeObjects{} }
+func (m *mockTx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return nil, nil
+}
+func (m *mockTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+func (m *mockTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, nil
+}
+func (m *mockTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row { return nil }
+func (m *mockTx) Conn() *pgx.Conn                                                       { return nil }
+
+type mockPool struct{}
+
+func (m *mockPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &mockTx{}, nil
+}
+
+// --- Mock Sales Store ---
+
+type mockSalesStore struct {
+	sales          []database.GetPOSDailySalesRow
+	summaries      []database.AcctSalesDailySummary
+	transactions   []database.AcctCashTransaction
+	mappings       []database.AcctSalesPaymentAccount
+	cashAccounts   map[uuid.UUID]bool
+	salesAccountID uuid.UUID
+	nextCode       string
+}
+
+func newMockSalesStore() *mockSalesStore {
+	return &mockSalesStore{
+		cashAccounts:   make(map[uuid.UUID]bool),
+		salesAccountID: uuid.New(),
+		nextCode:       "PCS000010",
+	}
+}
+
+func (m *mockSalesStore) GetPOSDailySales(ctx context.Context, arg database.GetPOSDailySalesParams) ([]database.GetPOSDailySalesRow, error) {
+	return m.sales, nil
+}
+
+func (m *mockSalesStore) LockPOSSalesDay(ctx context.Context, lockKey string) error {
+	return nil
+}
+
+func (m *mockSalesStore) ListPOSSalesSummaries(ctx context.Context, arg database.ListPOSSalesSummariesParams) ([]database.AcctSalesDailySummary, error) {
+	var result []database.AcctSalesDailySummary
+	for _, s := range m.summaries {
+		if s.Source == "pos" && s.SalesDate == arg.SalesDate && s.OutletID == arg.OutletID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSalesStore) UpsertPOSSalesSummary(ctx context.Context, arg database.UpsertPOSSalesSummaryParams) (database.AcctSalesDailySummary, error) {
+	for i, s := range m.summaries {
+		if s.SalesDate == arg.SalesDate && s.Channel == arg.Channel && s.PaymentMethod == arg.PaymentMethod && s.OutletID == arg.OutletID {
+			if s.Source != "pos" {
+				return database.AcctSalesDailySummary{}, pgx.ErrNoRows
+			}
+			m.summaries[i].GrossSales = arg.GrossSales
+			m.summaries[i].DiscountAmount = arg.DiscountAmount
+			m.summaries[i].NetSales = arg.NetSales
+			m.summaries[i].CashAccountID = arg.CashAccountID
+			return m.summaries[i], nil
+		}
+	}
+	s := database.AcctSalesDailySummary{
+		ID:             uuid.New(),
+		SalesDate:      arg.SalesDate,
+		Channel:        arg.Channel,
+		PaymentMethod:  arg.PaymentMethod,
+		GrossSales:     arg.GrossSales,
+		DiscountAmount: arg.DiscountAmount,
+		NetSales:       arg.NetSales,
+		CashAccountID:  arg.CashAccountID,
+		OutletID:       arg.OutletID,
+		Source:         "pos",
+		CreatedAt:      time.Now(),
+	}
+	m.summaries = append(m.summaries, s)
+	return s, nil
+}
+
+func (m *mockSalesStore) ListSalesSummaryPostings(ctx context.Context, salesSummaryID pgtype.UUID) ([]database.ListSalesSummaryPostingsRow, error) {
+	sums := make(map[uuid.UUID]decimal.Decimal)
+	var order []uuid.UUID
+	for _, tx := range m.transactions {
+		if tx.SalesSummaryID != salesSummaryID {
+			continue
+		}
+		id := uuid.UUID(tx.CashAccountID.Bytes)
+		if _, ok := sums[id]; !ok {
+			order = append(order, id)
+		}
+		sums[id] = sums[id].Add(numericDecimal(tx.Amount))
+	}
+	var rows []database.ListSalesSummaryPostingsRow
+	for _, id := range order {
+		rows = append(rows, database.ListSalesSummaryPostingsRow{
+			CashAccountID: pgtype.UUID{Bytes: id, Valid: true},
+			Amount:        makePgNumeric(sums[id].StringFixed(2)),
+		})
+	}
+	return rows, nil
+}
+
+func (m *mockSalesStore) ListAcctSalesDailySummaries(ctx context.Context, arg database.ListAcctSalesDailySummariesParams) ([]database.AcctSalesDailySummary, error) {
+	return m.summaries, nil
+}
+
+func (m *mockSalesStore) GetDefaultSalesAccount(ctx context.Context) (database.AcctAccount, error) {
+	return database.AcctAccount{ID: m.salesAccountID, AccountCode: "4000", LineType: "SALES", IsActive: true}, nil
+}
+
+func (m *mockSalesStore) ListAcctSalesPaymentAccounts(ctx context.Context, outletID uuid.UUID) ([]database.AcctSalesPaymentAccount, error) {
+	var result []database.AcctSalesPaymentAccount
+	for _, a := range m.mappings {
+		if a.OutletID == outletID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSalesStore) DeleteAcctSalesPaymentAccounts(ctx context.Context, outletID uuid.UUID) error {
+	var kept []database.AcctSalesPaymentAccount
+	for _, a := range m.mappings {
+		if a.OutletID != outletID {
+			kept = append(kept, a)
+		}
+	}
+	m.mappings = kept
+	return nil
+}
+
+func (m *mockSalesStore) CreateAcctSalesPaymentAccount(ctx context.Context, arg database.CreateAcctSalesPaymentAccountParams) (database.AcctSalesPaymentAccount, error) {
+	a := database.AcctSalesPaymentAccount{
+		OutletID:      arg.OutletID,
+		PaymentMethod: arg.PaymentMethod,
+		CashAccountID: arg.CashAccountID,
+		CreatedAt:     time.Now(),
+	}
+	m.mappings = append(m.mappings, a)
+	return a, nil
+}
+
+func (m *mockSalesStore) GetAcctCashAccount(ctx context.Context, id uuid.UUID) (database.AcctCashAccount, error) {
+	if !m.cashAccounts[id] {
+		return database.AcctCashAccount{}, pgx.ErrNoRows
+	}
+	return database.AcctCashAccount{ID: id, IsActive: true}, nil
+}
+
+func (m *mockSalesStore) CreateAcctCashTransaction(ctx context.Context, arg database.CreateAcctCashTransactionParams) (database.AcctCashTransaction, error) {
+	tx := database.AcctCashTransaction{
+		ID:              uuid.New(),
+		TransactionCode: arg.TransactionCode,
+		TransactionDate: arg.TransactionDate,
+		Description:     arg.Description,
+		Quantity:        arg.Quantity,
+		UnitPrice:       arg.UnitPrice,
+		Amount:          arg.Amount,
+		LineType:        arg.LineType,
+		AccountID:       arg.AccountID,
+		CashAccountID:   arg.CashAccountID,
+		OutletID:        arg.OutletID,
+		SalesSummaryID:  arg.SalesSummaryID,
+		CreatedAt:       time.Now(),
+	}
+	m.transactions = append(m.transactions, tx)
+	m.nextCode = arg.TransactionCode
+	return tx, nil
+}
+
+func (m *mockSalesStore) GetNextTransactionCode(ctx context.Context) (string, error) {
+	return m.nextCode, nil
+}
+
+// --- Helper functions ---
+
+func setupSalesRouter(store *mockSalesStore) *chi.Mux {
+	h := handler.NewSalesHandler(store, &mockPool{}, func(db database.DBTX) handler.SalesStore {
+		return store
+	})
+	r := chi.NewRouter()
+	r.Route("/accounting/sales", h.RegisterRoutes)
+	return r
+}
+
+func numericDecimal(n pgtype.Numeric) decimal.Decimal {
+	v, _ := n.Value()
+	s, _ := v.(string)
+	d, _ := decimal.NewFromString(s)
+	return d
+}
+
+// postedTotal sums what has been posted to a cash account.
+func postedTotal(store *mockSalesStore, cashAccountID uuid.UUID) decimal.Decimal {
+	total := decimal.Zero
+	for _, tx := range store.transactions {
+		if uuid.UUID(tx.CashAccountID.Bytes) == cashAccountID {
+			total = total.Add(numericDecimal(tx.Amount))
+		}
+	}
+	return total
+}
+
+// --- Tests ---
+
+func TestCloseSalesDay_PostsAndIsIdempotent(t *testing.T) {
+	store := newMockSalesStore()
+	router := setupSalesRouter(store)
+
+	outletID := uuid.New()
+	cashDrawer := uuid.New()
+	bankBCA := uuid.New()
+	store.mappings = []database.AcctSalesPaymentAccount{
+		{OutletID: outletID, PaymentMethod: "CASH", CashAccountID: cashDrawer},
+		{OutletID: outletID, PaymentMethod: "QRIS", CashAccountID: bankBCA},
+	}
+	store.sales = []database.GetPOSDailySalesRow{
+		{OrderType: "DINE_IN", PaymentMethod: "CASH", NetSales: makePgNumeric("150000.00"), DiscountAmount: makePgNumeric("10000.00")},
+		{OrderType: "DINE_IN", PaymentMethod: "QRIS", NetSales: makePgNumeric("80000.00"), DiscountAmount: makePgNumeric("0.00")},
+		{OrderType: "DELIVERY", DeliveryPlatform: "GoFood", PaymentMethod: "QRIS", NetSales: makePgNumeric("45000.00"), DiscountAmount: makePgNumeric("0.00")},
+	}
+
+	body := map[string]interface{}{"outlet_id": outletID.String(), "sales_date": "2026-01-20"}
+	rec := doRequest(t, router, "POST", "/accounting/sales/close", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeJSON(t, rec.Body.Bytes())
+	summaries := resp["summaries"].([]interface{})
+	if len(summaries) != 3 {
+		t.Fatalf("expected 3 summaries, got %d", len(summaries))
+	}
+	first := summaries[0].(map[string]interface{})
+	if first["channel"] != "Dine In" || first["payment_method"] != "CASH" {
+		t.Errorf("expected first summary Dine In CASH, got %v %v", first["channel"], first["payment_method"])
+	}
+	if first["gross_sales"] != "160000.00" || first["net_sales"] != "150000.00" {
+		t.Errorf("expected gross 160000.00 net 150000.00, got %v %v", first["gross_sales"], first["net_sales"])
+	}
+	if len(resp["transactions"].([]interface{})) != 3 {
+		t.Fatalf("expected 3 transactions, got %v", resp["transactions"])
+	}
+
+	tx := store.transactions[0]
+	if tx.TransactionCode != "PCS000011" || tx.LineType != "SALES" || tx.AccountID != store.salesAccountID {
+		t.Errorf("unexpected transaction: %s %s %s", tx.TransactionCode, tx.LineType, tx.AccountID)
+	}
+	if !postedTotal(store, cashDrawer).Equal(decimal.NewFromInt(150000)) {
+		t.Errorf("expected 150000 posted to cash drawer, got %s", postedTotal(store, cashDrawer))
+	}
+	if !postedTotal(store, bankBCA).Equal(decimal.NewFromInt(125000)) {
+		t.Errorf("expected 125000 posted to bank, got %s", postedTotal(store, bankBCA))
+	}
+
+	// Closing again with nothing new posts nothing
+	rec = doRequest(t, router, "POST", "/accounting/sales/close", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp = decodeJSON(t, rec.Body.Bytes())
+	if n := len(resp["transactions"].([]interface{})); n != 0 {
+		t.Errorf("expected no transactions on re-close, got %d", n)
+	}
+	if len(store.transactions) != 3 || len(store.summaries) != 3 {
+		t.Errorf("expected 3 transactions and 3 summaries, got %d and %d", len(store.transactions), len(store.summaries))
+	}
+}
+
+func TestCloseSalesDay_ReclosePostsCorrections(t *testing.T) {
+	store := newMockSalesStore()
+	router := setupSalesRouter(store)
+
+	outletID := uuid.New()
+	cashDrawer := uuid.New()
+	bankBCA := uuid.New()
+	bankMandiri := uuid.New()
+	store.mappings = []database.AcctSalesPaymentAccount{
+		{OutletID: outletID, PaymentMethod: "CASH", CashAccountID: cashDrawer},
+		{OutletID: outletID, PaymentMethod: "QRIS", CashAccountID: bankBCA},
+	}
+	store.sales = []database.GetPOSDailySalesRow{
+		{OrderType: "TAKEAWAY", PaymentMethod: "CASH", NetSales: makePgNumeric("100000.00"), DiscountAmount: makePgNumeric("0.00")},
+		{OrderType: "TAKEAWAY", PaymentMethod: "QRIS", NetSales: makePgNumeric("60000.00"), DiscountAmount: makePgNumeric("0.00")},
+	}
+
+	body := map[string]interface{}{"outlet_id": outletID.String(), "sales_date": "2026-01-20"}
+	if rec := doRequest(t, router, "POST", "/accounting/sales/close", body); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A cash refund, every QRIS order refunded, and QRIS remapped to another bank
+	store.sales = []database.GetPOSDailySalesRow{
+		{OrderType: "TAKEAWAY", PaymentMethod: "CASH", NetSales: makePgNumeric("75000.00"), DiscountAmount: makePgNumeric("0.00")},
+	}
+	store.mappings[1].CashAccountID = bankMandiri
+
+	rec := doRequest(t, router, "POST", "/accounting/sales/close", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeJSON(t, rec.Body.Bytes())
+	transactions := resp["transactions"].([]interface{})
+	if len(transactions) != 2 {
+		t.Fatalf("expected 2 correcting transactions, got %d: %v", len(transactions), transactions)
+	}
+	tx := transactions[0].(map[string]interface{})
+	if tx["amount"] != "-25000.00" || tx["description"] != "POS sales correction Take Away CASH" {
+		t.Errorf("unexpected cash correction: %v %v", tx["amount"], tx["description"])
+	}
+
+	if !postedTotal(store, cashDrawer).Equal(decimal.NewFromInt(75000)) {
+		t.Errorf("expected 75000 on cash drawer, got %s", postedTotal(store, cashDrawer))
+	}
+	if !postedTotal(store, bankBCA).IsZero() {
+		t.Errorf("expected QRIS sales reversed off the old bank, got %s", postedTotal(store, bankBCA))
+	}
+	if !postedTotal(store, bankMandiri).IsZero() {
+		t.Errorf("expected nothing on the new bank, got %s", postedTotal(store, bankMandiri))
+	}
+	for _, s := range store.summaries {
+		if s.PaymentMethod == "QRIS" && !numericDecimal(s.NetSales).IsZero() {
+			t.Errorf("expected QRIS summary zeroed, got %s", numericDecimal(s.NetSales))
+		}
+	}
+}
+
+func TestCloseSalesDay_UnmappedPaymentMethod(t *testing.T) {
+	store := newMockSalesStore()
+	router := setupSalesRouter(store)
+
+	outletID := uuid.New()
+	store.mappings = []database.AcctSalesPaymentAccount{
+		{OutletID: outletID, PaymentMethod: "CASH", CashAccountID: uuid.New()},
+	}
+	store.sales = []database.GetPOSDailySalesRow{
+		{OrderType: "DINE_IN", PaymentMethod: "CASH", NetSales: makePgNumeric("50000.00"), DiscountAmount: makePgNumeric("0.00")},
+		{OrderType: "DINE_IN", PaymentMethod: "TRANSFER", NetSales: makePgNumeric("20000.00"), DiscountAmount: makePgNumeric("0.00")},
+	}
+
+	rec := doRequest(t, router, "POST", "/accounting/sales/close", map[string]interface{}{
+		"outlet_id":  outletID.String(),
+		"sales_date": "2026-01-20",
+	})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("TRANSFER")) {
+		t.Errorf("expected TRANSFER named in error, got %s", rec.Body.String())
+	}
+	if len(store.transactions) != 0 || len(store.summaries) != 0 {
+		t.Errorf("expected nothing written, got %d transactions and %d summaries", len(store.transactions), len(store.summaries))
+	}
+}
+
+func TestCloseSalesDay_ManualSummaryConflict(t *testing.T) {
+	store := newMockSalesStore()
+	router := setupSalesRouter(store)
+
+	outletID := uuid.New()
+	cashDrawer := uuid.New()
+	store.mappings = []database.AcctSalesPaymentAccount{
+		{OutletID: outletID, PaymentMethod: "CASH", CashAccountID: cashDrawer},
+	}
+	store.summaries = []database.AcctSalesDailySummary{{
+		ID:            uuid.New(),
+		SalesDate:     makePgDate(2026, 1, 20),
+		Channel:       "Dine In",
+		PaymentMethod: "CASH",
+		NetSales:      makePgNumeric("90000.00"),
+		CashAccountID: cashDrawer,
+		OutletID:      pgtype.UUID{Bytes: outletID, Valid: true},
+		Source:        "manual",
+	}}
+	store.sales = []database.GetPOSDailySalesRow{
+		{OrderType: "DINE_IN", PaymentMethod: "CASH", NetSales: makePgNumeric("50000.00"), DiscountAmount: makePgNumeric("0.00")},
+	}
+
+	rec := doRequest(t, router, "POST", "/accounting/sales/close", map[string]interface{}{
+		"outlet_id":  outletID.String(),
+		"sales_date": "2026-01-20",
+	})
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCloseSalesDay_Validation(t *testing.T) {
+	router := setupSalesRouter(newMockSalesStore())
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"missing outlet_id", map[string]interface{}{"sales_date": "2026-01-20"}},
+		{"invalid sales_date", map[string]interface{}{"outlet_id": uuid.New().String(), "sales_date": "20-01-2026"}},
+		{"future sales_date", map[string]interface{}{"outlet_id": uuid.New().String(), "sales_date": time.Now().AddDate(0, 0, 2).Format("2006-01-02")}},
+		{"invalid account_id", map[string]interface{}{"outlet_id": uuid.New().String(), "sales_date": "2026-01-20", "account_id": "nope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "POST", "/accounting/sales/close", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetPaymentAccounts(t *testing.T) {
+	store := newMockSalesStore()
+	router := setupSalesRouter(store)
+
+	outletID := uuid.New()
+	cashDrawer := uuid.New()
+	store.cashAccounts[cashDrawer] = true
+	store.mappings = []database.AcctSalesPaymentAccount{
+		{OutletID: outletID, PaymentMethod: "QRIS", CashAccountID: uuid.New()},
+	}
+
+	rec := doRequest(t, router, "PUT", "/accounting/sales/payment-accounts/"+outletID.String(), map[string]interface{}{
+		"accounts": []map[string]string{{"payment_method": "cash", "cash_account_id": cashDrawer.String()}},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.mappings) != 1 || store.mappings[0].PaymentMethod != "CASH" || store.mappings[0].CashAccountID != cashDrawer {
+		t.Errorf("expected mapping replaced with CASH, got %+v", store.mappings)
+	}
+
+	rec = doRequest(t, router, "PUT", "/accounting/sales/payment-accounts/"+outletID.String(), map[string]interface{}{
+		"accounts": []map[string]string{{"payment_method": "QRIS", "cash_account_id": uuid.New().String()}},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for unknown cash account, got %d", rec.Code)
+	}
+
+	rec = doRequest(t, router, "GET", "/accounting/sales/payment-accounts/"+outletID.String(), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp []map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp) != 1 || resp[0]["payment_method"] != "CASH" {
+		t.Errorf("expected CASH mapping, got %v", resp)
+	}
+}
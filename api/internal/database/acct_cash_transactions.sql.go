@@ -16,9 +16,10 @@ const createAcctCashTransaction = `-- name: CreateAcctCashTransaction :one
 INSERT INTO acct_cash_transactions (
     transaction_code, transaction_date, item_id, description,
     quantity, unit_price, amount, line_type,
-    account_id, cash_account_id, outlet_id, reimbursement_batch_id
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at
+    account_id, cash_account_id, outlet_id, reimbursement_batch_id,
+    sales_summary_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at, sales_summary_id
 `
 
 type CreateAcctCashTransactionParams struct {
@@ -34,6 +35,7 @@ type CreateAcctCashTransactionParams struct {
 	CashAccountID        pgtype.UUID    `json:"cash_account_id"`
 	OutletID             pgtype.UUID    `json:"outlet_id"`
 	ReimbursementBatchID pgtype.Text    `json:"reimbursement_batch_id"`
+	SalesSummaryID       pgtype.UUID    `json:"sales_summary_id"`
 }
 
 func (q *Queries) CreateAcctCashTransaction(ctx context.Context, arg CreateAcctCashTransactionParams) (AcctCashTransaction, error) {
@@ -50,6 +52,7 @@ func (q *Queries) CreateAcctCashTransaction(ctx context.Context, arg CreateAcctC
 		arg.CashAccountID,
 		arg.OutletID,
 		arg.ReimbursementBatchID,
+		arg.SalesSummaryID,
 	)
 	var i AcctCashTransaction
 	err := row.Scan(
@@ -67,12 +70,13 @@ func (q *Queries) CreateAcctCashTransaction(ctx context.Context, arg CreateAcctC
 		&i.OutletID,
 		&i.ReimbursementBatchID,
 		&i.CreatedAt,
+		&i.SalesSummaryID,
 	)
 	return i, err
 }
 
 const getAcctCashTransaction = `-- name: GetAcctCashTransaction :one
-SELECT id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at FROM acct_cash_transactions WHERE id = $1
+SELECT id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at, sales_summary_id FROM acct_cash_transactions WHERE id = $1
 `
 
 func (q *Queries) GetAcctCashTransaction(ctx context.Context, id uuid.UUID) (AcctCashTransaction, error) {
@@ -93,6 +97,7 @@ func (q *Queries) GetAcctCashTransaction(ctx context.Context, id uuid.UUID) (Acc
 		&i.OutletID,
 		&i.ReimbursementBatchID,
 		&i.CreatedAt,
+		&i.SalesSummaryID,
 	)
 	return i, err
 }
@@ -124,7 +129,7 @@ func (q *Queries) GetNextTransactionCode(ctx context.Context) (string, error) {
 }
 
 const listAcctCashTransactions = `-- name: ListAcctCashTransactions :many
-SELECT id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at FROM acct_cash_transactions
+SELECT id, transaction_code, transaction_date, item_id, description, quantity, unit_price, amount, line_type, account_id, cash_account_id, outlet_id, reimbursement_batch_id, created_at, sales_summary_id FROM acct_cash_transactions
 WHERE
     ($3::date IS NULL OR transaction_date >= $3) AND
     ($4::date IS NULL OR transaction_date <= $4) AND
@@ -180,6 +185,7 @@ func (q *Queries) ListAcctCashTransactions(ctx context.Context, arg ListAcctCash
 			&i.OutletID,
 			&i.ReimbursementBatchID,
 			&i.CreatedAt,
+			&i.SalesSummaryID,
 		); err != nil {
 			return nil, err
 		}
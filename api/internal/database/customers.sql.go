@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -15,7 +16,7 @@ import (
 const createCustomer = `-- name: CreateCustomer :one
 INSERT INTO customers (outlet_id, name, phone, email, notes)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at
+RETURNING id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at, merged_into_id
 `
 
 type CreateCustomerParams struct {
@@ -45,12 +46,13 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MergedIntoID,
 	)
 	return i, err
 }
 
 const getCustomer = `-- name: GetCustomer :one
-SELECT id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at FROM customers WHERE id = $1 AND outlet_id = $2 AND is_active = true
+SELECT id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at, merged_into_id FROM customers WHERE id = $1 AND outlet_id = $2 AND is_active = true
 `
 
 type GetCustomerParams struct {
@@ -71,6 +73,35 @@ func (q *Queries) GetCustomer(ctx context.Context, arg GetCustomerParams) (Custo
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MergedIntoID,
+	)
+	return i, err
+}
+
+const getCustomerForUpdate = `-- name: GetCustomerForUpdate :one
+SELECT id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at, merged_into_id FROM customers WHERE id = $1 AND outlet_id = $2 AND is_active = true
+FOR UPDATE
+`
+
+type GetCustomerForUpdateParams struct {
+	ID       uuid.UUID `json:"id"`
+	OutletID uuid.UUID `json:"outlet_id"`
+}
+
+func (q *Queries) GetCustomerForUpdate(ctx context.Context, arg GetCustomerForUpdateParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, getCustomerForUpdate, arg.ID, arg.OutletID)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.OutletID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.Notes,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MergedIntoID,
 	)
 	return i, err
 }
@@ -110,6 +141,7 @@ FROM order_items oi
 JOIN orders o ON o.id = oi.order_id
 JOIN products p ON p.id = oi.product_id
 WHERE o.customer_id = $1 AND o.outlet_id = $2 AND o.status != 'CANCELLED'
+    AND oi.parent_item_id IS NULL
 GROUP BY p.id, p.name
 ORDER BY total_qty DESC
 LIMIT 5
@@ -153,7 +185,7 @@ func (q *Queries) GetCustomerTopItems(ctx context.Context, arg GetCustomerTopIte
 }
 
 const listCustomerOrders = `-- name: ListCustomerOrders :many
-SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at FROM orders
+SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount FROM orders
 WHERE customer_id = $1 AND outlet_id = $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -204,6 +236,76 @@ func (q *Queries) ListCustomerOrders(ctx context.Context, arg ListCustomerOrders
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CompletedAt,
+			&i.ServiceChargeAmount,
+			&i.ServiceChargeRate,
+			&i.TaxRate,
+			&i.PricesIncludeTax,
+			&i.PromotionID,
+			&i.PromotionCode,
+			&i.PromotionAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomerRFM = `-- name: ListCustomerRFM :many
+SELECT c.id, c.name, c.phone,
+    MIN(o.created_at)::timestamptz AS first_order_at,
+    MAX(o.created_at)::timestamptz AS last_order_at,
+    COUNT(o.id) FILTER (WHERE o.created_at >= $1) AS order_count,
+    COALESCE(SUM(paid.amount) FILTER (WHERE o.created_at >= $1 AND o.status = 'COMPLETED'), 0)::decimal(12,2) AS total_spend
+FROM customers c
+JOIN orders o ON o.customer_id = c.id AND o.outlet_id = c.outlet_id AND o.status != 'CANCELLED'
+LEFT JOIN LATERAL (
+    SELECT SUM(p.amount - p.refunded_amount) AS amount FROM payments p
+    WHERE p.order_id = o.id AND p.status = 'COMPLETED'
+) paid ON true
+WHERE c.outlet_id = $2 AND c.is_active = true
+GROUP BY c.id
+ORDER BY c.name, c.id
+`
+
+type ListCustomerRFMParams struct {
+	WindowStart time.Time `json:"window_start"`
+	OutletID    uuid.UUID `json:"outlet_id"`
+}
+
+type ListCustomerRFMRow struct {
+	ID           uuid.UUID          `json:"id"`
+	Name         string             `json:"name"`
+	Phone        string             `json:"phone"`
+	FirstOrderAt pgtype.Timestamptz `json:"first_order_at"`
+	LastOrderAt  pgtype.Timestamptz `json:"last_order_at"`
+	OrderCount   int64              `json:"order_count"`
+	TotalSpend   pgtype.Numeric     `json:"total_spend"`
+}
+
+// Active customers with at least one order: their first and last order
+// overall, and their order count and spend from window_start on. Spend is
+// counted as in GetCustomerLoyalty: paid net of refunds on completed orders.
+func (q *Queries) ListCustomerRFM(ctx context.Context, arg ListCustomerRFMParams) ([]ListCustomerRFMRow, error) {
+	rows, err := q.db.Query(ctx, listCustomerRFM, arg.WindowStart, arg.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCustomerRFMRow{}
+	for rows.Next() {
+		var i ListCustomerRFMRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Phone,
+			&i.FirstOrderAt,
+			&i.LastOrderAt,
+			&i.OrderCount,
+			&i.TotalSpend,
 		); err != nil {
 			return nil, err
 		}
@@ -216,7 +318,7 @@ func (q *Queries) ListCustomerOrders(ctx context.Context, arg ListCustomerOrders
 }
 
 const listCustomersByOutlet = `-- name: ListCustomersByOutlet :many
-SELECT id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at FROM customers
+SELECT id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at, merged_into_id FROM customers
 WHERE outlet_id = $1 AND is_active = true
   AND ($4::text IS NULL OR phone LIKE '%' || $4::text || '%' OR name ILIKE '%' || $4::text || '%')
 ORDER BY name
@@ -254,6 +356,7 @@ func (q *Queries) ListCustomersByOutlet(ctx context.Context, arg ListCustomersBy
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MergedIntoID,
 		); err != nil {
 			return nil, err
 		}
@@ -265,6 +368,115 @@ func (q *Queries) ListCustomersByOutlet(ctx context.Context, arg ListCustomersBy
 	return items, nil
 }
 
+const listDuplicateCustomerCandidates = `-- name: ListDuplicateCustomerCandidates :many
+SELECT normalize_phone(c.phone)::text AS normalized_phone,
+    c.id, c.name, c.phone, c.email, c.created_at,
+    (SELECT COUNT(*) FROM orders o WHERE o.customer_id = c.id) AS order_count
+FROM customers c
+WHERE c.outlet_id = $1 AND c.is_active = true
+  AND normalize_phone(c.phone) IN (
+      SELECT normalize_phone(d.phone) FROM customers d
+      WHERE d.outlet_id = $1 AND d.is_active = true
+      GROUP BY normalize_phone(d.phone)
+      HAVING COUNT(*) > 1
+  )
+ORDER BY normalized_phone, c.created_at, c.id
+`
+
+type ListDuplicateCustomerCandidatesRow struct {
+	NormalizedPhone string      `json:"normalized_phone"`
+	ID              uuid.UUID   `json:"id"`
+	Name            string      `json:"name"`
+	Phone           string      `json:"phone"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       time.Time   `json:"created_at"`
+	OrderCount      int64       `json:"order_count"`
+}
+
+// Active customers whose phones normalize to the same number as another
+// active customer's, ordered so each number's customers are adjacent.
+func (q *Queries) ListDuplicateCustomerCandidates(ctx context.Context, outletID uuid.UUID) ([]ListDuplicateCustomerCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, listDuplicateCustomerCandidates, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDuplicateCustomerCandidatesRow{}
+	for rows.Next() {
+		var i ListDuplicateCustomerCandidatesRow
+		if err := rows.Scan(
+			&i.NormalizedPhone,
+			&i.ID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+			&i.CreatedAt,
+			&i.OrderCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markCustomerMerged = `-- name: MarkCustomerMerged :one
+UPDATE customers SET is_active = false, merged_into_id = $2, updated_at = now()
+WHERE id = $1 AND is_active = true
+RETURNING id
+`
+
+type MarkCustomerMergedParams struct {
+	ID           uuid.UUID   `json:"id"`
+	MergedIntoID pgtype.UUID `json:"merged_into_id"`
+}
+
+func (q *Queries) MarkCustomerMerged(ctx context.Context, arg MarkCustomerMergedParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, markCustomerMerged, arg.ID, arg.MergedIntoID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const reassignCustomerLoyaltyTransactions = `-- name: ReassignCustomerLoyaltyTransactions :execrows
+UPDATE loyalty_transactions SET customer_id = $1
+WHERE customer_id = $2
+`
+
+type ReassignCustomerLoyaltyTransactionsParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+func (q *Queries) ReassignCustomerLoyaltyTransactions(ctx context.Context, arg ReassignCustomerLoyaltyTransactionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reassignCustomerLoyaltyTransactions, arg.TargetID, arg.SourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reassignCustomerOrders = `-- name: ReassignCustomerOrders :execrows
+UPDATE orders SET customer_id = $1::uuid
+WHERE customer_id = $2::uuid
+`
+
+type ReassignCustomerOrdersParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+func (q *Queries) ReassignCustomerOrders(ctx context.Context, arg ReassignCustomerOrdersParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reassignCustomerOrders, arg.TargetID, arg.SourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const softDeleteCustomer = `-- name: SoftDeleteCustomer :one
 UPDATE customers SET is_active = false, updated_at = now()
 WHERE id = $1 AND outlet_id = $2 AND is_active = true
@@ -286,7 +498,7 @@ func (q *Queries) SoftDeleteCustomer(ctx context.Context, arg SoftDeleteCustomer
 const updateCustomer = `-- name: UpdateCustomer :one
 UPDATE customers SET name = $2, phone = $3, email = $4, notes = $5, updated_at = now()
 WHERE id = $1 AND outlet_id = $6 AND is_active = true
-RETURNING id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at
+RETURNING id, outlet_id, name, phone, email, notes, is_active, created_at, updated_at, merged_into_id
 `
 
 type UpdateCustomerParams struct {
@@ -318,6 +530,7 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MergedIntoID,
 	)
 	return i, err
 }
@@ -46,6 +46,7 @@ type AcctCashTransaction struct {
 	OutletID             pgtype.UUID    `json:"outlet_id"`
 	ReimbursementBatchID pgtype.Text    `json:"reimbursement_batch_id"`
 	CreatedAt            time.Time      `json:"created_at"`
+	SalesSummaryID       pgtype.UUID    `json:"sales_summary_id"`
 }
 
 type AcctItem struct {
@@ -109,6 +110,39 @@ type AcctSalesDailySummary struct {
 	CreatedAt      time.Time      `json:"created_at"`
 }
 
+type AcctSalesPaymentAccount struct {
+	OutletID      uuid.UUID `json:"outlet_id"`
+	PaymentMethod string    `json:"payment_method"`
+	CashAccountID uuid.UUID `json:"cash_account_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CashMovement struct {
+	ID           uuid.UUID      `json:"id"`
+	ShiftID      uuid.UUID      `json:"shift_id"`
+	MovementType string         `json:"movement_type"`
+	Amount       pgtype.Numeric `json:"amount"`
+	Reason       string         `json:"reason"`
+	CreatedBy    uuid.UUID      `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type CashierShift struct {
+	ID           uuid.UUID          `json:"id"`
+	OutletID     uuid.UUID          `json:"outlet_id"`
+	UserID       uuid.UUID          `json:"user_id"`
+	Status       string             `json:"status"`
+	OpeningFloat pgtype.Numeric     `json:"opening_float"`
+	OpenedAt     time.Time          `json:"opened_at"`
+	ClosedAt     pgtype.Timestamptz `json:"closed_at"`
+	ClosedBy     pgtype.UUID        `json:"closed_by"`
+	CountedCash  pgtype.Numeric     `json:"counted_cash"`
+	ExpectedCash pgtype.Numeric     `json:"expected_cash"`
+	ClosingNotes pgtype.Text        `json:"closing_notes"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
 type Category struct {
 	ID          uuid.UUID   `json:"id"`
 	OutletID    uuid.UUID   `json:"outlet_id"`
@@ -128,15 +162,79 @@ type ComboItem struct {
 }
 
 type Customer struct {
-	ID        uuid.UUID   `json:"id"`
-	OutletID  uuid.UUID   `json:"outlet_id"`
-	Name      string      `json:"name"`
-	Phone     string      `json:"phone"`
-	Email     pgtype.Text `json:"email"`
-	Notes     pgtype.Text `json:"notes"`
-	IsActive  bool        `json:"is_active"`
-	CreatedAt time.Time   `json:"created_at"`
-	UpdatedAt time.Time   `json:"updated_at"`
+	ID           uuid.UUID   `json:"id"`
+	OutletID     uuid.UUID   `json:"outlet_id"`
+	Name         string      `json:"name"`
+	Phone        string      `json:"phone"`
+	Email        pgtype.Text `json:"email"`
+	Notes        pgtype.Text `json:"notes"`
+	IsActive     bool        `json:"is_active"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	MergedIntoID pgtype.UUID `json:"merged_into_id"`
+}
+
+type DiningTable struct {
+	ID              uuid.UUID          `json:"id"`
+	OutletID        uuid.UUID          `json:"outlet_id"`
+	Area            string             `json:"area"`
+	Code            string             `json:"code"`
+	Seats           int32              `json:"seats"`
+	SortOrder       int32              `json:"sort_order"`
+	BillRequestedAt pgtype.Timestamptz `json:"bill_requested_at"`
+	IsActive        bool               `json:"is_active"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	ID             uuid.UUID          `json:"id"`
+	OutletID       uuid.UUID          `json:"outlet_id"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	RequestHash    string             `json:"request_hash"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	ResponseBody   pgtype.Text        `json:"response_body"`
+	CreatedAt      time.Time          `json:"created_at"`
+	CompletedAt    pgtype.Timestamptz `json:"completed_at"`
+}
+
+type KitchenTicket struct {
+	ID              uuid.UUID          `json:"id"`
+	OrderID         uuid.UUID          `json:"order_id"`
+	Sequence        int32              `json:"sequence"`
+	Station         pgtype.Text        `json:"station"`
+	TicketType      string             `json:"ticket_type"`
+	Lines           []byte             `json:"lines"`
+	CreatedBy       uuid.UUID          `json:"created_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	ReprintCount    int32              `json:"reprint_count"`
+	LastReprintedAt pgtype.Timestamptz `json:"last_reprinted_at"`
+}
+
+type LoyaltySetting struct {
+	OutletID         uuid.UUID      `json:"outlet_id"`
+	IsEnabled        bool           `json:"is_enabled"`
+	EarnRates        []byte         `json:"earn_rates"`
+	PointValue       pgtype.Numeric `json:"point_value"`
+	PointsExpiryDays pgtype.Int4    `json:"points_expiry_days"`
+	SilverMinSpend   pgtype.Numeric `json:"silver_min_spend"`
+	SilverMultiplier pgtype.Numeric `json:"silver_multiplier"`
+	GoldMinSpend     pgtype.Numeric `json:"gold_min_spend"`
+	GoldMultiplier   pgtype.Numeric `json:"gold_multiplier"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+type LoyaltyTransaction struct {
+	ID              uuid.UUID          `json:"id"`
+	CustomerID      uuid.UUID          `json:"customer_id"`
+	OrderID         pgtype.UUID        `json:"order_id"`
+	PaymentID       pgtype.UUID        `json:"payment_id"`
+	TransactionType string             `json:"transaction_type"`
+	Points          int32              `json:"points"`
+	Amount          pgtype.Numeric     `json:"amount"`
+	ExpiresAt       pgtype.Timestamptz `json:"expires_at"`
+	CreatedBy       pgtype.UUID        `json:"created_by"`
+	CreatedAt       time.Time          `json:"created_at"`
 }
 
 type Modifier struct {
@@ -159,45 +257,83 @@ type ModifierGroup struct {
 }
 
 type Order struct {
-	ID               uuid.UUID          `json:"id"`
-	OutletID         uuid.UUID          `json:"outlet_id"`
-	OrderNumber      string             `json:"order_number"`
-	CustomerID       pgtype.UUID        `json:"customer_id"`
-	OrderType        string             `json:"order_type"`
-	Status           string             `json:"status"`
-	TableNumber      pgtype.Text        `json:"table_number"`
-	Notes            pgtype.Text        `json:"notes"`
-	Subtotal         pgtype.Numeric     `json:"subtotal"`
-	DiscountType     pgtype.Text        `json:"discount_type"`
-	DiscountValue    pgtype.Numeric     `json:"discount_value"`
-	DiscountAmount   pgtype.Numeric     `json:"discount_amount"`
-	TaxAmount        pgtype.Numeric     `json:"tax_amount"`
-	TotalAmount      pgtype.Numeric     `json:"total_amount"`
-	CateringDate     pgtype.Timestamptz `json:"catering_date"`
-	CateringStatus   pgtype.Text        `json:"catering_status"`
-	CateringDpAmount pgtype.Numeric     `json:"catering_dp_amount"`
-	DeliveryPlatform pgtype.Text        `json:"delivery_platform"`
-	DeliveryAddress  pgtype.Text        `json:"delivery_address"`
-	CreatedBy        uuid.UUID          `json:"created_by"`
-	CreatedAt        time.Time          `json:"created_at"`
-	UpdatedAt        time.Time          `json:"updated_at"`
-	CompletedAt      pgtype.Timestamptz `json:"completed_at"`
+	ID                  uuid.UUID          `json:"id"`
+	OutletID            uuid.UUID          `json:"outlet_id"`
+	OrderNumber         string             `json:"order_number"`
+	CustomerID          pgtype.UUID        `json:"customer_id"`
+	OrderType           string             `json:"order_type"`
+	Status              string             `json:"status"`
+	TableNumber         pgtype.Text        `json:"table_number"`
+	Notes               pgtype.Text        `json:"notes"`
+	Subtotal            pgtype.Numeric     `json:"subtotal"`
+	DiscountType        pgtype.Text        `json:"discount_type"`
+	DiscountValue       pgtype.Numeric     `json:"discount_value"`
+	DiscountAmount      pgtype.Numeric     `json:"discount_amount"`
+	TaxAmount           pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount         pgtype.Numeric     `json:"total_amount"`
+	CateringDate        pgtype.Timestamptz `json:"catering_date"`
+	CateringStatus      pgtype.Text        `json:"catering_status"`
+	CateringDpAmount    pgtype.Numeric     `json:"catering_dp_amount"`
+	DeliveryPlatform    pgtype.Text        `json:"delivery_platform"`
+	DeliveryAddress     pgtype.Text        `json:"delivery_address"`
+	CreatedBy           uuid.UUID          `json:"created_by"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+	CompletedAt         pgtype.Timestamptz `json:"completed_at"`
+	ServiceChargeAmount pgtype.Numeric     `json:"service_charge_amount"`
+	ServiceChargeRate   pgtype.Numeric     `json:"service_charge_rate"`
+	TaxRate             pgtype.Numeric     `json:"tax_rate"`
+	PricesIncludeTax    bool               `json:"prices_include_tax"`
+	PromotionID         pgtype.UUID        `json:"promotion_id"`
+	PromotionCode       pgtype.Text        `json:"promotion_code"`
+	PromotionAmount     pgtype.Numeric     `json:"promotion_amount"`
+}
+
+type OrderCheck struct {
+	ID          uuid.UUID      `json:"id"`
+	OrderID     uuid.UUID      `json:"order_id"`
+	CheckNumber int32          `json:"check_number"`
+	SplitType   string         `json:"split_type"`
+	Amount      pgtype.Numeric `json:"amount"`
+	CreatedBy   uuid.UUID      `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type OrderCheckItem struct {
+	CheckID     uuid.UUID `json:"check_id"`
+	OrderItemID uuid.UUID `json:"order_item_id"`
+}
+
+type OrderEvent struct {
+	ID          uuid.UUID `json:"id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	EventType   string    `json:"event_type"`
+	ActorID     uuid.UUID `json:"actor_id"`
+	BeforeValue []byte    `json:"before_value"`
+	AfterValue  []byte    `json:"after_value"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type OrderItem struct {
-	ID             uuid.UUID      `json:"id"`
-	OrderID        uuid.UUID      `json:"order_id"`
-	ProductID      uuid.UUID      `json:"product_id"`
-	VariantID      pgtype.UUID    `json:"variant_id"`
-	Quantity       int32          `json:"quantity"`
-	UnitPrice      pgtype.Numeric `json:"unit_price"`
-	DiscountType   pgtype.Text    `json:"discount_type"`
-	DiscountValue  pgtype.Numeric `json:"discount_value"`
-	DiscountAmount pgtype.Numeric `json:"discount_amount"`
-	Subtotal       pgtype.Numeric `json:"subtotal"`
-	Notes          pgtype.Text    `json:"notes"`
-	Status         string         `json:"status"`
-	Station        pgtype.Text    `json:"station"`
+	ID             uuid.UUID          `json:"id"`
+	OrderID        uuid.UUID          `json:"order_id"`
+	ProductID      uuid.UUID          `json:"product_id"`
+	VariantID      pgtype.UUID        `json:"variant_id"`
+	Quantity       int32              `json:"quantity"`
+	UnitPrice      pgtype.Numeric     `json:"unit_price"`
+	DiscountType   pgtype.Text        `json:"discount_type"`
+	DiscountValue  pgtype.Numeric     `json:"discount_value"`
+	DiscountAmount pgtype.Numeric     `json:"discount_amount"`
+	Subtotal       pgtype.Numeric     `json:"subtotal"`
+	Notes          pgtype.Text        `json:"notes"`
+	Status         string             `json:"status"`
+	Station        pgtype.Text        `json:"station"`
+	VariantIds     []uuid.UUID        `json:"variant_ids"`
+	ParentItemID   pgtype.UUID        `json:"parent_item_id"`
+	SentAt         time.Time          `json:"sent_at"`
+	StartedAt      pgtype.Timestamptz `json:"started_at"`
+	ReadyAt        pgtype.Timestamptz `json:"ready_at"`
+	LateAlertedAt  pgtype.Timestamptz `json:"late_alerted_at"`
 }
 
 type OrderItemModifier struct {
@@ -208,6 +344,22 @@ type OrderItemModifier struct {
 	UnitPrice   pgtype.Numeric `json:"unit_price"`
 }
 
+type OutletSetting struct {
+	OutletID                uuid.UUID      `json:"outlet_id"`
+	TaxRate                 pgtype.Numeric `json:"tax_rate"`
+	PricesIncludeTax        bool           `json:"prices_include_tax"`
+	TaxOrderTypes           []string       `json:"tax_order_types"`
+	ServiceChargeRate       pgtype.Numeric `json:"service_charge_rate"`
+	ServiceChargeOrderTypes []string       `json:"service_charge_order_types"`
+	RoundingMode            string         `json:"rounding_mode"`
+	RoundingUnit            pgtype.Numeric `json:"rounding_unit"`
+	CreatedAt               time.Time      `json:"created_at"`
+	UpdatedAt               time.Time      `json:"updated_at"`
+	ReceiptHeader           string         `json:"receipt_header"`
+	ReceiptFooter           string         `json:"receipt_footer"`
+	ReceiptPaperWidth       int32          `json:"receipt_paper_width"`
+}
+
 type Outlet struct {
 	ID        uuid.UUID   `json:"id"`
 	Name      string      `json:"name"`
@@ -229,6 +381,28 @@ type Payment struct {
 	ChangeAmount    pgtype.Numeric `json:"change_amount"`
 	ProcessedBy     uuid.UUID      `json:"processed_by"`
 	ProcessedAt     time.Time      `json:"processed_at"`
+	CheckID         pgtype.UUID    `json:"check_id"`
+	RefundedAmount  pgtype.Numeric `json:"refunded_amount"`
+}
+
+type PaymentRefund struct {
+	ID           uuid.UUID      `json:"id"`
+	PaymentID    uuid.UUID      `json:"payment_id"`
+	OrderID      uuid.UUID      `json:"order_id"`
+	Amount       pgtype.Numeric `json:"amount"`
+	Reason       string         `json:"reason"`
+	RefundedBy   uuid.UUID      `json:"refunded_by"`
+	AuthorizedBy uuid.UUID      `json:"authorized_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type PickupDisplay struct {
+	OutletID            uuid.UUID `json:"outlet_id"`
+	Token               string    `json:"token"`
+	OrderTypes          []string  `json:"order_types"`
+	ReadyTimeoutMinutes int32     `json:"ready_timeout_minutes"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 type Product struct {
@@ -247,6 +421,28 @@ type Product struct {
 	UpdatedAt       time.Time      `json:"updated_at"`
 }
 
+type Promotion struct {
+	ID            uuid.UUID          `json:"id"`
+	OutletID      pgtype.UUID        `json:"outlet_id"`
+	Name          string             `json:"name"`
+	Code          pgtype.Text        `json:"code"`
+	PromotionType string             `json:"promotion_type"`
+	CategoryID    pgtype.UUID        `json:"category_id"`
+	ProductID     pgtype.UUID        `json:"product_id"`
+	DiscountValue pgtype.Numeric     `json:"discount_value"`
+	BuyQuantity   pgtype.Int4        `json:"buy_quantity"`
+	GetQuantity   pgtype.Int4        `json:"get_quantity"`
+	MinSpend      pgtype.Numeric     `json:"min_spend"`
+	ValidFrom     pgtype.Timestamptz `json:"valid_from"`
+	ValidUntil    pgtype.Timestamptz `json:"valid_until"`
+	StartTime     pgtype.Time        `json:"start_time"`
+	EndTime       pgtype.Time        `json:"end_time"`
+	UsageLimit    pgtype.Int4        `json:"usage_limit"`
+	IsActive      bool               `json:"is_active"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
 type User struct {
 	ID             uuid.UUID   `json:"id"`
 	OutletID       uuid.UUID   `json:"outlet_id"`
@@ -277,3 +473,17 @@ type VariantGroup struct {
 	IsActive   bool      `json:"is_active"`
 	SortOrder  int32     `json:"sort_order"`
 }
+
+type WsEvent struct {
+	OutletID  uuid.UUID `json:"outlet_id"`
+	Seq       int64     `json:"seq"`
+	EventType string    `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+	Stations  []string  `json:"stations"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WsOutletSequence struct {
+	OutletID uuid.UUID `json:"outlet_id"`
+	LastSeq  int64     `json:"last_seq"`
+}
@@ -16,7 +16,7 @@ import (
 const cancelOrder = `-- name: CancelOrder :one
 UPDATE orders SET status = 'CANCELLED', updated_at = now()
 WHERE id = $1 AND outlet_id = $2 AND status NOT IN ('COMPLETED', 'CANCELLED')
-RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
 type CancelOrderParams struct {
@@ -51,14 +51,72 @@ func (q *Queries) CancelOrder(ctx context.Context, arg CancelOrderParams) (Order
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
+	)
+	return i, err
+}
+
+const completeSyncedOrder = `-- name: CompleteSyncedOrder :one
+UPDATE orders SET status = 'COMPLETED', completed_at = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
+`
+
+type CompleteSyncedOrderParams struct {
+	ID          uuid.UUID          `json:"id"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+// Completes an order synced from offline, keeping the device's completion time.
+func (q *Queries) CompleteSyncedOrder(ctx context.Context, arg CompleteSyncedOrderParams) (Order, error) {
+	row := q.db.QueryRow(ctx, completeSyncedOrder, arg.ID, arg.CompletedAt)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.OutletID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.OrderType,
+		&i.Status,
+		&i.TableNumber,
+		&i.Notes,
+		&i.Subtotal,
+		&i.DiscountType,
+		&i.DiscountValue,
+		&i.DiscountAmount,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.CateringDate,
+		&i.CateringStatus,
+		&i.CateringDpAmount,
+		&i.DeliveryPlatform,
+		&i.DeliveryAddress,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
 
 const countOrderItems = `-- name: CountOrderItems :one
-SELECT COUNT(*) FROM order_items WHERE order_id = $1
+SELECT COUNT(*) FROM order_items WHERE order_id = $1 AND parent_item_id IS NULL
 `
 
+// Counts order lines; combo components are not lines of their own.
 func (q *Queries) CountOrderItems(ctx context.Context, orderID uuid.UUID) (int64, error) {
 	row := q.db.QueryRow(ctx, countOrderItems, orderID)
 	var count int64
@@ -71,34 +129,51 @@ INSERT INTO orders (
     outlet_id, order_number, customer_id, order_type, table_number, notes,
     subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount,
     catering_date, catering_status, catering_dp_amount,
-    delivery_platform, delivery_address, created_by
+    delivery_platform, delivery_address, created_by,
+    service_charge_amount, service_charge_rate, tax_rate, prices_include_tax,
+    promotion_id, promotion_code, promotion_amount,
+    id, created_at, updated_at
 ) VALUES (
     $1, $2, $3, $4, $5, $6,
     $7, $8, $9, $10, $11, $12,
     $13, $14, $15,
-    $16, $17, $18
-) RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+    $16, $17, $18,
+    $19, $20, $21, $22,
+    $23, $24, $25,
+    COALESCE($26::uuid, gen_random_uuid()),
+    COALESCE($27::timestamptz, now()),
+    COALESCE($27::timestamptz, now())
+) RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
 type CreateOrderParams struct {
-	OutletID         uuid.UUID          `json:"outlet_id"`
-	OrderNumber      string             `json:"order_number"`
-	CustomerID       pgtype.UUID        `json:"customer_id"`
-	OrderType        string             `json:"order_type"`
-	TableNumber      pgtype.Text        `json:"table_number"`
-	Notes            pgtype.Text        `json:"notes"`
-	Subtotal         pgtype.Numeric     `json:"subtotal"`
-	DiscountType     pgtype.Text        `json:"discount_type"`
-	DiscountValue    pgtype.Numeric     `json:"discount_value"`
-	DiscountAmount   pgtype.Numeric     `json:"discount_amount"`
-	TaxAmount        pgtype.Numeric     `json:"tax_amount"`
-	TotalAmount      pgtype.Numeric     `json:"total_amount"`
-	CateringDate     pgtype.Timestamptz `json:"catering_date"`
-	CateringStatus   pgtype.Text        `json:"catering_status"`
-	CateringDpAmount pgtype.Numeric     `json:"catering_dp_amount"`
-	DeliveryPlatform pgtype.Text        `json:"delivery_platform"`
-	DeliveryAddress  pgtype.Text        `json:"delivery_address"`
-	CreatedBy        uuid.UUID          `json:"created_by"`
+	OutletID            uuid.UUID          `json:"outlet_id"`
+	OrderNumber         string             `json:"order_number"`
+	CustomerID          pgtype.UUID        `json:"customer_id"`
+	OrderType           string             `json:"order_type"`
+	TableNumber         pgtype.Text        `json:"table_number"`
+	Notes               pgtype.Text        `json:"notes"`
+	Subtotal            pgtype.Numeric     `json:"subtotal"`
+	DiscountType        pgtype.Text        `json:"discount_type"`
+	DiscountValue       pgtype.Numeric     `json:"discount_value"`
+	DiscountAmount      pgtype.Numeric     `json:"discount_amount"`
+	TaxAmount           pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount         pgtype.Numeric     `json:"total_amount"`
+	CateringDate        pgtype.Timestamptz `json:"catering_date"`
+	CateringStatus      pgtype.Text        `json:"catering_status"`
+	CateringDpAmount    pgtype.Numeric     `json:"catering_dp_amount"`
+	DeliveryPlatform    pgtype.Text        `json:"delivery_platform"`
+	DeliveryAddress     pgtype.Text        `json:"delivery_address"`
+	CreatedBy           uuid.UUID          `json:"created_by"`
+	ServiceChargeAmount pgtype.Numeric     `json:"service_charge_amount"`
+	ServiceChargeRate   pgtype.Numeric     `json:"service_charge_rate"`
+	TaxRate             pgtype.Numeric     `json:"tax_rate"`
+	PricesIncludeTax    bool               `json:"prices_include_tax"`
+	PromotionID         pgtype.UUID        `json:"promotion_id"`
+	PromotionCode       pgtype.Text        `json:"promotion_code"`
+	PromotionAmount     pgtype.Numeric     `json:"promotion_amount"`
+	ID                  pgtype.UUID        `json:"id"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
@@ -121,6 +196,15 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.DeliveryPlatform,
 		arg.DeliveryAddress,
 		arg.CreatedBy,
+		arg.ServiceChargeAmount,
+		arg.ServiceChargeRate,
+		arg.TaxRate,
+		arg.PricesIncludeTax,
+		arg.PromotionID,
+		arg.PromotionCode,
+		arg.PromotionAmount,
+		arg.ID,
+		arg.CreatedAt,
 	)
 	var i Order
 	err := row.Scan(
@@ -147,6 +231,13 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
@@ -155,12 +246,12 @@ const createOrderItem = `-- name: CreateOrderItem :one
 INSERT INTO order_items (
     order_id, product_id, variant_id, quantity, unit_price,
     discount_type, discount_value, discount_amount, subtotal,
-    notes, station
+    notes, station, variant_ids, parent_item_id
 ) VALUES (
     $1, $2, $3, $4, $5,
     $6, $7, $8, $9,
-    $10, $11
-) RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station
+    $10, $11, $12, $13
+) RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at
 `
 
 type CreateOrderItemParams struct {
@@ -175,6 +266,8 @@ type CreateOrderItemParams struct {
 	Subtotal       pgtype.Numeric `json:"subtotal"`
 	Notes          pgtype.Text    `json:"notes"`
 	Station        pgtype.Text    `json:"station"`
+	VariantIds     []uuid.UUID    `json:"variant_ids"`
+	ParentItemID   pgtype.UUID    `json:"parent_item_id"`
 }
 
 func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error) {
@@ -190,6 +283,8 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		arg.Subtotal,
 		arg.Notes,
 		arg.Station,
+		arg.VariantIds,
+		arg.ParentItemID,
 	)
 	var i OrderItem
 	err := row.Scan(
@@ -206,6 +301,12 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		&i.Notes,
 		&i.Status,
 		&i.Station,
+		&i.VariantIds,
+		&i.ParentItemID,
+		&i.SentAt,
+		&i.StartedAt,
+		&i.ReadyAt,
+		&i.LateAlertedAt,
 	)
 	return i, err
 }
@@ -255,23 +356,38 @@ func (q *Queries) DeleteOrderItem(ctx context.Context, arg DeleteOrderItemParams
 	return err
 }
 
+const deleteOrderItemModifiers = `-- name: DeleteOrderItemModifiers :exec
+DELETE FROM order_item_modifiers WHERE order_item_id = $1
+`
+
+func (q *Queries) DeleteOrderItemModifiers(ctx context.Context, orderItemID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOrderItemModifiers, orderItemID)
+	return err
+}
+
 const getModifierForOrder = `-- name: GetModifierForOrder :one
-SELECT m.id, m.price, mg.product_id
+SELECT m.id, m.modifier_group_id, m.price, mg.product_id
 FROM modifiers m
 JOIN modifier_groups mg ON mg.id = m.modifier_group_id
 WHERE m.id = $1 AND m.is_active = true AND mg.is_active = true
 `
 
 type GetModifierForOrderRow struct {
-	ID        uuid.UUID      `json:"id"`
-	Price     pgtype.Numeric `json:"price"`
-	ProductID uuid.UUID      `json:"product_id"`
+	ID              uuid.UUID      `json:"id"`
+	ModifierGroupID uuid.UUID      `json:"modifier_group_id"`
+	Price           pgtype.Numeric `json:"price"`
+	ProductID       uuid.UUID      `json:"product_id"`
 }
 
 func (q *Queries) GetModifierForOrder(ctx context.Context, id uuid.UUID) (GetModifierForOrderRow, error) {
 	row := q.db.QueryRow(ctx, getModifierForOrder, id)
 	var i GetModifierForOrderRow
-	err := row.Scan(&i.ID, &i.Price, &i.ProductID)
+	err := row.Scan(
+		&i.ID,
+		&i.ModifierGroupID,
+		&i.Price,
+		&i.ProductID,
+	)
 	return i, err
 }
 
@@ -289,7 +405,7 @@ func (q *Queries) GetNextOrderNumber(ctx context.Context, outletID uuid.UUID) (i
 }
 
 const getOrder = `-- name: GetOrder :one
-SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at FROM orders WHERE id = $1 AND outlet_id = $2
+SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount FROM orders WHERE id = $1 AND outlet_id = $2
 `
 
 type GetOrderParams struct {
@@ -324,12 +440,19 @@ func (q *Queries) GetOrder(ctx context.Context, arg GetOrderParams) (Order, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
 
 const getOrderItem = `-- name: GetOrderItem :one
-SELECT id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station FROM order_items WHERE id = $1 AND order_id = $2
+SELECT id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at FROM order_items WHERE id = $1 AND order_id = $2
 `
 
 type GetOrderItemParams struct {
@@ -354,12 +477,18 @@ func (q *Queries) GetOrderItem(ctx context.Context, arg GetOrderItemParams) (Ord
 		&i.Notes,
 		&i.Status,
 		&i.Station,
+		&i.VariantIds,
+		&i.ParentItemID,
+		&i.SentAt,
+		&i.StartedAt,
+		&i.ReadyAt,
+		&i.LateAlertedAt,
 	)
 	return i, err
 }
 
 const getProductForOrder = `-- name: GetProductForOrder :one
-SELECT id, outlet_id, base_price, station FROM products
+SELECT id, outlet_id, base_price, station, is_combo, category_id FROM products
 WHERE id = $1 AND outlet_id = $2 AND is_active = true
 `
 
@@ -369,10 +498,12 @@ type GetProductForOrderParams struct {
 }
 
 type GetProductForOrderRow struct {
-	ID        uuid.UUID      `json:"id"`
-	OutletID  uuid.UUID      `json:"outlet_id"`
-	BasePrice pgtype.Numeric `json:"base_price"`
-	Station   pgtype.Text    `json:"station"`
+	ID         uuid.UUID      `json:"id"`
+	OutletID   uuid.UUID      `json:"outlet_id"`
+	BasePrice  pgtype.Numeric `json:"base_price"`
+	Station    pgtype.Text    `json:"station"`
+	IsCombo    bool           `json:"is_combo"`
+	CategoryID uuid.UUID      `json:"category_id"`
 }
 
 func (q *Queries) GetProductForOrder(ctx context.Context, arg GetProductForOrderParams) (GetProductForOrderRow, error) {
@@ -383,6 +514,8 @@ func (q *Queries) GetProductForOrder(ctx context.Context, arg GetProductForOrder
 		&i.OutletID,
 		&i.BasePrice,
 		&i.Station,
+		&i.IsCombo,
+		&i.CategoryID,
 	)
 	return i, err
 }
@@ -414,7 +547,7 @@ func (q *Queries) GetVariantForOrder(ctx context.Context, id uuid.UUID) (GetVari
 }
 
 const listActiveOrders = `-- name: ListActiveOrders :many
-SELECT o.id, o.outlet_id, o.order_number, o.customer_id, o.order_type, o.status, o.table_number, o.notes, o.subtotal, o.discount_type, o.discount_value, o.discount_amount, o.tax_amount, o.total_amount, o.catering_date, o.catering_status, o.catering_dp_amount, o.delivery_platform, o.delivery_address, o.created_by, o.created_at, o.updated_at, o.completed_at,
+SELECT o.id, o.outlet_id, o.order_number, o.customer_id, o.order_type, o.status, o.table_number, o.notes, o.subtotal, o.discount_type, o.discount_value, o.discount_amount, o.tax_amount, o.total_amount, o.catering_date, o.catering_status, o.catering_dp_amount, o.delivery_platform, o.delivery_address, o.created_by, o.created_at, o.updated_at, o.completed_at, o.service_charge_amount, o.service_charge_rate, o.tax_rate, o.prices_include_tax, o.promotion_id, o.promotion_code, o.promotion_amount,
        COALESCE(
          (SELECT SUM(p.amount) FROM payments p WHERE p.order_id = o.id AND p.status = 'COMPLETED'),
          0
@@ -436,30 +569,37 @@ type ListActiveOrdersParams struct {
 }
 
 type ListActiveOrdersRow struct {
-	ID               uuid.UUID          `json:"id"`
-	OutletID         uuid.UUID          `json:"outlet_id"`
-	OrderNumber      string             `json:"order_number"`
-	CustomerID       pgtype.UUID        `json:"customer_id"`
-	OrderType        string             `json:"order_type"`
-	Status           string             `json:"status"`
-	TableNumber      pgtype.Text        `json:"table_number"`
-	Notes            pgtype.Text        `json:"notes"`
-	Subtotal         pgtype.Numeric     `json:"subtotal"`
-	DiscountType     pgtype.Text        `json:"discount_type"`
-	DiscountValue    pgtype.Numeric     `json:"discount_value"`
-	DiscountAmount   pgtype.Numeric     `json:"discount_amount"`
-	TaxAmount        pgtype.Numeric     `json:"tax_amount"`
-	TotalAmount      pgtype.Numeric     `json:"total_amount"`
-	CateringDate     pgtype.Timestamptz `json:"catering_date"`
-	CateringStatus   pgtype.Text        `json:"catering_status"`
-	CateringDpAmount pgtype.Numeric     `json:"catering_dp_amount"`
-	DeliveryPlatform pgtype.Text        `json:"delivery_platform"`
-	DeliveryAddress  pgtype.Text        `json:"delivery_address"`
-	CreatedBy        uuid.UUID          `json:"created_by"`
-	CreatedAt        time.Time          `json:"created_at"`
-	UpdatedAt        time.Time          `json:"updated_at"`
-	CompletedAt      pgtype.Timestamptz `json:"completed_at"`
-	AmountPaid       pgtype.Numeric     `json:"amount_paid"`
+	ID                  uuid.UUID          `json:"id"`
+	OutletID            uuid.UUID          `json:"outlet_id"`
+	OrderNumber         string             `json:"order_number"`
+	CustomerID          pgtype.UUID        `json:"customer_id"`
+	OrderType           string             `json:"order_type"`
+	Status              string             `json:"status"`
+	TableNumber         pgtype.Text        `json:"table_number"`
+	Notes               pgtype.Text        `json:"notes"`
+	Subtotal            pgtype.Numeric     `json:"subtotal"`
+	DiscountType        pgtype.Text        `json:"discount_type"`
+	DiscountValue       pgtype.Numeric     `json:"discount_value"`
+	DiscountAmount      pgtype.Numeric     `json:"discount_amount"`
+	TaxAmount           pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount         pgtype.Numeric     `json:"total_amount"`
+	CateringDate        pgtype.Timestamptz `json:"catering_date"`
+	CateringStatus      pgtype.Text        `json:"catering_status"`
+	CateringDpAmount    pgtype.Numeric     `json:"catering_dp_amount"`
+	DeliveryPlatform    pgtype.Text        `json:"delivery_platform"`
+	DeliveryAddress     pgtype.Text        `json:"delivery_address"`
+	CreatedBy           uuid.UUID          `json:"created_by"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+	CompletedAt         pgtype.Timestamptz `json:"completed_at"`
+	ServiceChargeAmount pgtype.Numeric     `json:"service_charge_amount"`
+	ServiceChargeRate   pgtype.Numeric     `json:"service_charge_rate"`
+	TaxRate             pgtype.Numeric     `json:"tax_rate"`
+	PricesIncludeTax    bool               `json:"prices_include_tax"`
+	PromotionID         pgtype.UUID        `json:"promotion_id"`
+	PromotionCode       pgtype.Text        `json:"promotion_code"`
+	PromotionAmount     pgtype.Numeric     `json:"promotion_amount"`
+	AmountPaid          pgtype.Numeric     `json:"amount_paid"`
 }
 
 func (q *Queries) ListActiveOrders(ctx context.Context, arg ListActiveOrdersParams) ([]ListActiveOrdersRow, error) {
@@ -495,6 +635,13 @@ func (q *Queries) ListActiveOrders(ctx context.Context, arg ListActiveOrdersPara
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CompletedAt,
+			&i.ServiceChargeAmount,
+			&i.ServiceChargeRate,
+			&i.TaxRate,
+			&i.PricesIncludeTax,
+			&i.PromotionID,
+			&i.PromotionCode,
+			&i.PromotionAmount,
 			&i.AmountPaid,
 		); err != nil {
 			return nil, err
@@ -507,6 +654,185 @@ func (q *Queries) ListActiveOrders(ctx context.Context, arg ListActiveOrdersPara
 	return items, nil
 }
 
+const listActiveOrdersByTable = `-- name: ListActiveOrdersByTable :many
+SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount FROM orders
+WHERE outlet_id = $1 AND table_number = $2 AND status NOT IN ('COMPLETED', 'CANCELLED')
+ORDER BY created_at
+`
+
+type ListActiveOrdersByTableParams struct {
+	OutletID    uuid.UUID   `json:"outlet_id"`
+	TableNumber pgtype.Text `json:"table_number"`
+}
+
+// Orders not yet completed or cancelled at a table, oldest first.
+func (q *Queries) ListActiveOrdersByTable(ctx context.Context, arg ListActiveOrdersByTableParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, listActiveOrdersByTable, arg.OutletID, arg.TableNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.OutletID,
+			&i.OrderNumber,
+			&i.CustomerID,
+			&i.OrderType,
+			&i.Status,
+			&i.TableNumber,
+			&i.Notes,
+			&i.Subtotal,
+			&i.DiscountType,
+			&i.DiscountValue,
+			&i.DiscountAmount,
+			&i.TaxAmount,
+			&i.TotalAmount,
+			&i.CateringDate,
+			&i.CateringStatus,
+			&i.CateringDpAmount,
+			&i.DeliveryPlatform,
+			&i.DeliveryAddress,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.ServiceChargeAmount,
+			&i.ServiceChargeRate,
+			&i.TaxRate,
+			&i.PricesIncludeTax,
+			&i.PromotionID,
+			&i.PromotionCode,
+			&i.PromotionAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChildOrderItems = `-- name: ListChildOrderItems :many
+SELECT id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at FROM order_items WHERE parent_item_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListChildOrderItems(ctx context.Context, parentItemID pgtype.UUID) ([]OrderItem, error) {
+	rows, err := q.db.Query(ctx, listChildOrderItems, parentItemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderItem{}
+	for rows.Next() {
+		var i OrderItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductID,
+			&i.VariantID,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.DiscountType,
+			&i.DiscountValue,
+			&i.DiscountAmount,
+			&i.Subtotal,
+			&i.Notes,
+			&i.Status,
+			&i.Station,
+			&i.VariantIds,
+			&i.ParentItemID,
+			&i.SentAt,
+			&i.StartedAt,
+			&i.ReadyAt,
+			&i.LateAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listComboComponentsForOrder = `-- name: ListComboComponentsForOrder :many
+SELECT ci.product_id, ci.quantity, p.station
+FROM combo_items ci
+JOIN products p ON p.id = ci.product_id
+WHERE ci.combo_id = $1
+ORDER BY ci.sort_order, ci.id
+`
+
+type ListComboComponentsForOrderRow struct {
+	ProductID uuid.UUID   `json:"product_id"`
+	Quantity  int32       `json:"quantity"`
+	Station   pgtype.Text `json:"station"`
+}
+
+func (q *Queries) ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]ListComboComponentsForOrderRow, error) {
+	rows, err := q.db.Query(ctx, listComboComponentsForOrder, comboID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListComboComponentsForOrderRow{}
+	for rows.Next() {
+		var i ListComboComponentsForOrderRow
+		if err := rows.Scan(&i.ProductID, &i.Quantity, &i.Station); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listModifierGroupsForOrder = `-- name: ListModifierGroupsForOrder :many
+SELECT id, name, min_select, max_select FROM modifier_groups
+WHERE product_id = $1 AND is_active = true
+ORDER BY sort_order, name
+`
+
+type ListModifierGroupsForOrderRow struct {
+	ID        uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	MinSelect int32       `json:"min_select"`
+	MaxSelect pgtype.Int4 `json:"max_select"`
+}
+
+func (q *Queries) ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]ListModifierGroupsForOrderRow, error) {
+	rows, err := q.db.Query(ctx, listModifierGroupsForOrder, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListModifierGroupsForOrderRow{}
+	for rows.Next() {
+		var i ListModifierGroupsForOrderRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.MinSelect,
+			&i.MaxSelect,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listOrderItemModifiersByOrderItem = `-- name: ListOrderItemModifiersByOrderItem :many
 SELECT id, order_item_id, modifier_id, quantity, unit_price FROM order_item_modifiers WHERE order_item_id = $1 ORDER BY id
 `
@@ -538,7 +864,7 @@ func (q *Queries) ListOrderItemModifiersByOrderItem(ctx context.Context, orderIt
 }
 
 const listOrderItemsByOrder = `-- name: ListOrderItemsByOrder :many
-SELECT id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station FROM order_items WHERE order_id = $1 ORDER BY id
+SELECT id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at FROM order_items WHERE order_id = $1 ORDER BY id
 `
 
 func (q *Queries) ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]OrderItem, error) {
@@ -564,6 +890,12 @@ func (q *Queries) ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID)
 			&i.Notes,
 			&i.Status,
 			&i.Station,
+			&i.VariantIds,
+			&i.ParentItemID,
+			&i.SentAt,
+			&i.StartedAt,
+			&i.ReadyAt,
+			&i.LateAlertedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -576,7 +908,7 @@ func (q *Queries) ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID)
 }
 
 const listOrders = `-- name: ListOrders :many
-SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at FROM orders
+SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount FROM orders
 WHERE outlet_id = $1
   AND ($4::text IS NULL OR status = $4)
   AND ($5::text IS NULL OR order_type = $5)
@@ -637,6 +969,13 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CompletedAt,
+			&i.ServiceChargeAmount,
+			&i.ServiceChargeRate,
+			&i.TaxRate,
+			&i.PricesIncludeTax,
+			&i.PromotionID,
+			&i.PromotionCode,
+			&i.PromotionAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -649,7 +988,7 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order
 }
 
 const listPaymentsByOrder = `-- name: ListPaymentsByOrder :many
-SELECT id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at FROM payments WHERE order_id = $1 ORDER BY processed_at
+SELECT id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at, check_id, refunded_amount FROM payments WHERE order_id = $1 ORDER BY processed_at
 `
 
 func (q *Queries) ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([]Payment, error) {
@@ -672,6 +1011,7 @@ func (q *Queries) ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([
 			&i.ChangeAmount,
 			&i.ProcessedBy,
 			&i.ProcessedAt,
+			&i.CheckID,
 		); err != nil {
 			return nil, err
 		}
@@ -683,14 +1023,204 @@ func (q *Queries) ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([
 	return items, nil
 }
 
+const listVariantGroupsForOrder = `-- name: ListVariantGroupsForOrder :many
+SELECT id, name, is_required FROM variant_groups
+WHERE product_id = $1 AND is_active = true
+ORDER BY sort_order, name
+`
+
+type ListVariantGroupsForOrderRow struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	IsRequired bool      `json:"is_required"`
+}
+
+func (q *Queries) ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]ListVariantGroupsForOrderRow, error) {
+	rows, err := q.db.Query(ctx, listVariantGroupsForOrder, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVariantGroupsForOrderRow{}
+	for rows.Next() {
+		var i ListVariantGroupsForOrderRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.IsRequired); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveOrderItems = `-- name: MoveOrderItems :exec
+UPDATE order_items SET order_id = $1
+WHERE order_id = $2
+`
+
+type MoveOrderItemsParams struct {
+	ToOrderID   uuid.UUID `json:"to_order_id"`
+	FromOrderID uuid.UUID `json:"from_order_id"`
+}
+
+// Moves every item (and with it, its modifiers) from one order onto another.
+func (q *Queries) MoveOrderItems(ctx context.Context, arg MoveOrderItemsParams) error {
+	_, err := q.db.Exec(ctx, moveOrderItems, arg.ToOrderID, arg.FromOrderID)
+	return err
+}
+
+const scaleChildItemQuantities = `-- name: ScaleChildItemQuantities :exec
+UPDATE order_items SET
+    quantity = quantity / $2::int * $3::int
+WHERE parent_item_id = $1
+`
+
+type ScaleChildItemQuantitiesParams struct {
+	ParentItemID pgtype.UUID `json:"parent_item_id"`
+	OldQuantity  int32       `json:"old_quantity"`
+	NewQuantity  int32       `json:"new_quantity"`
+}
+
+// Keeps combo components in step with the parent line's quantity. Component
+// quantities are always a multiple of the parent's, so the division is exact.
+func (q *Queries) ScaleChildItemQuantities(ctx context.Context, arg ScaleChildItemQuantitiesParams) error {
+	_, err := q.db.Exec(ctx, scaleChildItemQuantities, arg.ParentItemID, arg.OldQuantity, arg.NewQuantity)
+	return err
+}
+
+const setSyncedOrderTotal = `-- name: SetSyncedOrderTotal :one
+UPDATE orders SET total_amount = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
+`
+
+type SetSyncedOrderTotalParams struct {
+	ID          uuid.UUID      `json:"id"`
+	TotalAmount pgtype.Numeric `json:"total_amount"`
+}
+
+// Sets an order synced from offline to the total the device charged, when
+// the server priced it differently.
+func (q *Queries) SetSyncedOrderTotal(ctx context.Context, arg SetSyncedOrderTotalParams) (Order, error) {
+	row := q.db.QueryRow(ctx, setSyncedOrderTotal, arg.ID, arg.TotalAmount)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.OutletID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.OrderType,
+		&i.Status,
+		&i.TableNumber,
+		&i.Notes,
+		&i.Subtotal,
+		&i.DiscountType,
+		&i.DiscountValue,
+		&i.DiscountAmount,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.CateringDate,
+		&i.CateringStatus,
+		&i.CateringDpAmount,
+		&i.DeliveryPlatform,
+		&i.DeliveryAddress,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
+	)
+	return i, err
+}
+
+const updateOrderCharges = `-- name: UpdateOrderCharges :one
+UPDATE orders SET
+    service_charge_amount = $2,
+    service_charge_rate = $3,
+    tax_amount = $4,
+    tax_rate = $5,
+    prices_include_tax = $6,
+    total_amount = $7,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
+`
+
+type UpdateOrderChargesParams struct {
+	ID                  uuid.UUID      `json:"id"`
+	ServiceChargeAmount pgtype.Numeric `json:"service_charge_amount"`
+	ServiceChargeRate   pgtype.Numeric `json:"service_charge_rate"`
+	TaxAmount           pgtype.Numeric `json:"tax_amount"`
+	TaxRate             pgtype.Numeric `json:"tax_rate"`
+	PricesIncludeTax    bool           `json:"prices_include_tax"`
+	TotalAmount         pgtype.Numeric `json:"total_amount"`
+}
+
+func (q *Queries) UpdateOrderCharges(ctx context.Context, arg UpdateOrderChargesParams) (Order, error) {
+	row := q.db.QueryRow(ctx, updateOrderCharges,
+		arg.ID,
+		arg.ServiceChargeAmount,
+		arg.ServiceChargeRate,
+		arg.TaxAmount,
+		arg.TaxRate,
+		arg.PricesIncludeTax,
+		arg.TotalAmount,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.OutletID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.OrderType,
+		&i.Status,
+		&i.TableNumber,
+		&i.Notes,
+		&i.Subtotal,
+		&i.DiscountType,
+		&i.DiscountValue,
+		&i.DiscountAmount,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.CateringDate,
+		&i.CateringStatus,
+		&i.CateringDpAmount,
+		&i.DeliveryPlatform,
+		&i.DeliveryAddress,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
+	)
+	return i, err
+}
+
 const updateOrderItem = `-- name: UpdateOrderItem :one
 UPDATE order_items SET
     quantity = $3,
     notes = $4,
     discount_amount = $5,
-    subtotal = $6
+    subtotal = $6,
+    unit_price = $7,
+    variant_id = $8,
+    variant_ids = $9
 WHERE id = $1 AND order_id = $2
-RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station
+RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at
 `
 
 type UpdateOrderItemParams struct {
@@ -700,6 +1230,9 @@ type UpdateOrderItemParams struct {
 	Notes          pgtype.Text    `json:"notes"`
 	DiscountAmount pgtype.Numeric `json:"discount_amount"`
 	Subtotal       pgtype.Numeric `json:"subtotal"`
+	UnitPrice      pgtype.Numeric `json:"unit_price"`
+	VariantID      pgtype.UUID    `json:"variant_id"`
+	VariantIds     []uuid.UUID    `json:"variant_ids"`
 }
 
 func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) (OrderItem, error) {
@@ -710,6 +1243,9 @@ func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams
 		arg.Notes,
 		arg.DiscountAmount,
 		arg.Subtotal,
+		arg.UnitPrice,
+		arg.VariantID,
+		arg.VariantIds,
 	)
 	var i OrderItem
 	err := row.Scan(
@@ -726,15 +1262,23 @@ func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams
 		&i.Notes,
 		&i.Status,
 		&i.Station,
+		&i.VariantIds,
+		&i.ParentItemID,
+		&i.SentAt,
+		&i.StartedAt,
+		&i.ReadyAt,
+		&i.LateAlertedAt,
 	)
 	return i, err
 }
 
 const updateOrderItemStatus = `-- name: UpdateOrderItemStatus :one
 UPDATE order_items SET
-    status = $3
+    status = $3,
+    started_at = CASE WHEN $3 IN ('PREPARING', 'READY') THEN COALESCE(started_at, now()) ELSE started_at END,
+    ready_at = CASE WHEN $3 = 'READY' THEN now() ELSE NULL END
 WHERE id = $1 AND order_id = $2
-RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station
+RETURNING id, order_id, product_id, variant_id, quantity, unit_price, discount_type, discount_value, discount_amount, subtotal, notes, status, station, variant_ids, parent_item_id, sent_at, started_at, ready_at, late_alerted_at
 `
 
 type UpdateOrderItemStatusParams struct {
@@ -743,6 +1287,8 @@ type UpdateOrderItemStatusParams struct {
 	Status  string    `json:"status"`
 }
 
+// Stamps kitchen progress: started_at on the first move past PENDING, ready_at
+// on READY. A recall back to PREPARING clears ready_at.
 func (q *Queries) UpdateOrderItemStatus(ctx context.Context, arg UpdateOrderItemStatusParams) (OrderItem, error) {
 	row := q.db.QueryRow(ctx, updateOrderItemStatus, arg.ID, arg.OrderID, arg.Status)
 	var i OrderItem
@@ -760,6 +1306,12 @@ func (q *Queries) UpdateOrderItemStatus(ctx context.Context, arg UpdateOrderItem
 		&i.Notes,
 		&i.Status,
 		&i.Station,
+		&i.VariantIds,
+		&i.ParentItemID,
+		&i.SentAt,
+		&i.StartedAt,
+		&i.ReadyAt,
+		&i.LateAlertedAt,
 	)
 	return i, err
 }
@@ -769,7 +1321,7 @@ UPDATE orders SET status = $3,
     completed_at = CASE WHEN $3 = 'COMPLETED' THEN now() ELSE completed_at END,
     updated_at = now()
 WHERE id = $1 AND outlet_id = $2 AND status = $4
-RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
 type UpdateOrderStatusParams struct {
@@ -811,6 +1363,63 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
+	)
+	return i, err
+}
+
+const updateOrderTable = `-- name: UpdateOrderTable :one
+UPDATE orders SET table_number = $3, updated_at = now()
+WHERE id = $1 AND outlet_id = $2 AND status NOT IN ('COMPLETED', 'CANCELLED')
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
+`
+
+type UpdateOrderTableParams struct {
+	ID          uuid.UUID   `json:"id"`
+	OutletID    uuid.UUID   `json:"outlet_id"`
+	TableNumber pgtype.Text `json:"table_number"`
+}
+
+func (q *Queries) UpdateOrderTable(ctx context.Context, arg UpdateOrderTableParams) (Order, error) {
+	row := q.db.QueryRow(ctx, updateOrderTable, arg.ID, arg.OutletID, arg.TableNumber)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.OutletID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.OrderType,
+		&i.Status,
+		&i.TableNumber,
+		&i.Notes,
+		&i.Subtotal,
+		&i.DiscountType,
+		&i.DiscountValue,
+		&i.DiscountAmount,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.CateringDate,
+		&i.CateringStatus,
+		&i.CateringDpAmount,
+		&i.DeliveryPlatform,
+		&i.DeliveryAddress,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
@@ -823,20 +1432,22 @@ UPDATE orders SET
             (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1) * discount_value / 100
         WHEN discount_type = 'FIXED_AMOUNT' THEN LEAST(discount_value, (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1))
         ELSE 0
-    END,
+    END + promotion_amount,
     total_amount = (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1)
         - CASE
             WHEN discount_type = 'PERCENTAGE' THEN
                 (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1) * discount_value / 100
             WHEN discount_type = 'FIXED_AMOUNT' THEN LEAST(discount_value, (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1))
             ELSE 0
-        END
-        + tax_amount,
+        END - promotion_amount,
     updated_at = now()
 WHERE id = $1
-RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
+// Recomputes subtotal and discount from the order's items. The discount keeps
+// the applied promotion's amount until the promotion is re-evaluated. total_amount
+// is left net of discount only; UpdateOrderCharges then adds service charge and tax.
 func (q *Queries) UpdateOrderTotals(ctx context.Context, orderID uuid.UUID) (Order, error) {
 	row := q.db.QueryRow(ctx, updateOrderTotals, orderID)
 	var i Order
@@ -864,6 +1475,13 @@ func (q *Queries) UpdateOrderTotals(ctx context.Context, orderID uuid.UUID) (Ord
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
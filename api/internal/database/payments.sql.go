@@ -12,10 +12,41 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addPaymentRefundedAmount = `-- name: AddPaymentRefundedAmount :one
+UPDATE payments SET refunded_amount = refunded_amount + $2
+WHERE id = $1
+RETURNING id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at, check_id, refunded_amount
+`
+
+type AddPaymentRefundedAmountParams struct {
+	ID             uuid.UUID      `json:"id"`
+	RefundedAmount pgtype.Numeric `json:"refunded_amount"`
+}
+
+func (q *Queries) AddPaymentRefundedAmount(ctx context.Context, arg AddPaymentRefundedAmountParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, addPaymentRefundedAmount, arg.ID, arg.RefundedAmount)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.PaymentMethod,
+		&i.Amount,
+		&i.Status,
+		&i.ReferenceNumber,
+		&i.AmountReceived,
+		&i.ChangeAmount,
+		&i.ProcessedBy,
+		&i.ProcessedAt,
+		&i.CheckID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
 const completeOrder = `-- name: CompleteOrder :one
 UPDATE orders SET status = 'COMPLETED', completed_at = now(), updated_at = now()
 WHERE id = $1 AND status != 'CANCELLED'
-RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
 func (q *Queries) CompleteOrder(ctx context.Context, id uuid.UUID) (Order, error) {
@@ -45,27 +76,51 @@ func (q *Queries) CompleteOrder(ctx context.Context, id uuid.UUID) (Order, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
 
+const countRefundsByOrder = `-- name: CountRefundsByOrder :one
+SELECT COUNT(*) FROM payment_refunds WHERE order_id = $1
+`
+
+func (q *Queries) CountRefundsByOrder(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countRefundsByOrder, orderID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPayment = `-- name: CreatePayment :one
 INSERT INTO payments (
     order_id, payment_method, amount, status,
-    reference_number, amount_received, change_amount, processed_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at
+    reference_number, amount_received, change_amount, processed_by,
+    check_id, processed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9,
+    COALESCE($10::timestamptz, now())
+)
+RETURNING id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at, check_id, refunded_amount
 `
 
 type CreatePaymentParams struct {
-	OrderID         uuid.UUID      `json:"order_id"`
-	PaymentMethod   string         `json:"payment_method"`
-	Amount          pgtype.Numeric `json:"amount"`
-	Status          string         `json:"status"`
-	ReferenceNumber pgtype.Text    `json:"reference_number"`
-	AmountReceived  pgtype.Numeric `json:"amount_received"`
-	ChangeAmount    pgtype.Numeric `json:"change_amount"`
-	ProcessedBy     uuid.UUID      `json:"processed_by"`
+	OrderID         uuid.UUID          `json:"order_id"`
+	PaymentMethod   string             `json:"payment_method"`
+	Amount          pgtype.Numeric     `json:"amount"`
+	Status          string             `json:"status"`
+	ReferenceNumber pgtype.Text        `json:"reference_number"`
+	AmountReceived  pgtype.Numeric     `json:"amount_received"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	ProcessedBy     uuid.UUID          `json:"processed_by"`
+	CheckID         pgtype.UUID        `json:"check_id"`
+	ProcessedAt     pgtype.Timestamptz `json:"processed_at"`
 }
 
 func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
@@ -78,6 +133,8 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		arg.AmountReceived,
 		arg.ChangeAmount,
 		arg.ProcessedBy,
+		arg.CheckID,
+		arg.ProcessedAt,
 	)
 	var i Payment
 	err := row.Scan(
@@ -91,12 +148,53 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		&i.ChangeAmount,
 		&i.ProcessedBy,
 		&i.ProcessedAt,
+		&i.CheckID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
+const createPaymentRefund = `-- name: CreatePaymentRefund :one
+INSERT INTO payment_refunds (
+    payment_id, order_id, amount, reason, refunded_by, authorized_by
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, payment_id, order_id, amount, reason, refunded_by, authorized_by, created_at
+`
+
+type CreatePaymentRefundParams struct {
+	PaymentID    uuid.UUID      `json:"payment_id"`
+	OrderID      uuid.UUID      `json:"order_id"`
+	Amount       pgtype.Numeric `json:"amount"`
+	Reason       string         `json:"reason"`
+	RefundedBy   uuid.UUID      `json:"refunded_by"`
+	AuthorizedBy uuid.UUID      `json:"authorized_by"`
+}
+
+func (q *Queries) CreatePaymentRefund(ctx context.Context, arg CreatePaymentRefundParams) (PaymentRefund, error) {
+	row := q.db.QueryRow(ctx, createPaymentRefund,
+		arg.PaymentID,
+		arg.OrderID,
+		arg.Amount,
+		arg.Reason,
+		arg.RefundedBy,
+		arg.AuthorizedBy,
+	)
+	var i PaymentRefund
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentID,
+		&i.OrderID,
+		&i.Amount,
+		&i.Reason,
+		&i.RefundedBy,
+		&i.AuthorizedBy,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getOrderForUpdate = `-- name: GetOrderForUpdate :one
-SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at FROM orders WHERE id = $1 AND outlet_id = $2 FOR NO KEY UPDATE
+SELECT id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount FROM orders WHERE id = $1 AND outlet_id = $2 FOR NO KEY UPDATE
 `
 
 type GetOrderForUpdateParams struct {
@@ -131,16 +229,53 @@ func (q *Queries) GetOrderForUpdate(ctx context.Context, arg GetOrderForUpdatePa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
+	)
+	return i, err
+}
+
+const getPaymentForUpdate = `-- name: GetPaymentForUpdate :one
+SELECT id, order_id, payment_method, amount, status, reference_number, amount_received, change_amount, processed_by, processed_at, check_id, refunded_amount FROM payments WHERE id = $1 AND order_id = $2 FOR UPDATE
+`
+
+type GetPaymentForUpdateParams struct {
+	ID      uuid.UUID `json:"id"`
+	OrderID uuid.UUID `json:"order_id"`
+}
+
+func (q *Queries) GetPaymentForUpdate(ctx context.Context, arg GetPaymentForUpdateParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, getPaymentForUpdate, arg.ID, arg.OrderID)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.PaymentMethod,
+		&i.Amount,
+		&i.Status,
+		&i.ReferenceNumber,
+		&i.AmountReceived,
+		&i.ChangeAmount,
+		&i.ProcessedBy,
+		&i.ProcessedAt,
+		&i.CheckID,
+		&i.RefundedAmount,
 	)
 	return i, err
 }
 
 const sumPaymentsByOrder = `-- name: SumPaymentsByOrder :one
-SELECT COALESCE(SUM(amount), 0)::decimal(12,2) AS total_paid
+SELECT COALESCE(SUM(amount - refunded_amount), 0)::decimal(12,2) AS total_paid
 FROM payments
 WHERE order_id = $1 AND status = 'COMPLETED'
 `
 
+// Net of refunds.
 func (q *Queries) SumPaymentsByOrder(ctx context.Context, orderID uuid.UUID) (pgtype.Numeric, error) {
 	row := q.db.QueryRow(ctx, sumPaymentsByOrder, orderID)
 	var total_paid pgtype.Numeric
@@ -151,7 +286,7 @@ func (q *Queries) SumPaymentsByOrder(ctx context.Context, orderID uuid.UUID) (pg
 const updateCateringStatus = `-- name: UpdateCateringStatus :one
 UPDATE orders SET catering_status = $2, updated_at = now()
 WHERE id = $1
-RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at
+RETURNING id, outlet_id, order_number, customer_id, order_type, status, table_number, notes, subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount, catering_date, catering_status, catering_dp_amount, delivery_platform, delivery_address, created_by, created_at, updated_at, completed_at, service_charge_amount, service_charge_rate, tax_rate, prices_include_tax, promotion_id, promotion_code, promotion_amount
 `
 
 type UpdateCateringStatusParams struct {
@@ -186,6 +321,13 @@ func (q *Queries) UpdateCateringStatus(ctx context.Context, arg UpdateCateringSt
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.ServiceChargeAmount,
+		&i.ServiceChargeRate,
+		&i.TaxRate,
+		&i.PricesIncludeTax,
+		&i.PromotionID,
+		&i.PromotionCode,
+		&i.PromotionAmount,
 	)
 	return i, err
 }
@@ -19,8 +19,14 @@ SELECT
     COUNT(o.id) AS order_count,
     SUM(o.total_amount)::decimal(12,2) AS total_revenue,
     SUM(o.discount_amount)::decimal(12,2) AS total_discount,
-    (SUM(o.total_amount) - SUM(o.discount_amount))::decimal(12,2) AS net_revenue
+    COALESCE(SUM(r.refund_amount), 0)::decimal(12,2) AS total_refunds,
+    (SUM(o.total_amount) - SUM(o.discount_amount) - COALESCE(SUM(r.refund_amount), 0))::decimal(12,2) AS net_revenue
 FROM orders o
+LEFT JOIN (
+    SELECT order_id, SUM(amount) AS refund_amount
+    FROM payment_refunds
+    GROUP BY order_id
+) r ON r.order_id = o.id
 WHERE o.outlet_id = $1
     AND o.status != 'CANCELLED'
     AND o.created_at >= $2
@@ -40,9 +46,11 @@ type GetDailySalesRow struct {
 	OrderCount    int64          `json:"order_count"`
 	TotalRevenue  pgtype.Numeric `json:"total_revenue"`
 	TotalDiscount pgtype.Numeric `json:"total_discount"`
+	TotalRefunds  pgtype.Numeric `json:"total_refunds"`
 	NetRevenue    pgtype.Numeric `json:"net_revenue"`
 }
 
+// Refunds are attributed to the sale date of the refunded order.
 func (q *Queries) GetDailySales(ctx context.Context, arg GetDailySalesParams) ([]GetDailySalesRow, error) {
 	rows, err := q.db.Query(ctx, getDailySales, arg.OutletID, arg.CreatedAt, arg.CreatedAt_2)
 	if err != nil {
@@ -57,6 +65,7 @@ func (q *Queries) GetDailySales(ctx context.Context, arg GetDailySalesParams) ([
 			&i.OrderCount,
 			&i.TotalRevenue,
 			&i.TotalDiscount,
+			&i.TotalRefunds,
 			&i.NetRevenue,
 		); err != nil {
 			return nil, err
@@ -170,17 +179,33 @@ func (q *Queries) GetOutletComparison(ctx context.Context, arg GetOutletComparis
 
 const getPaymentSummary = `-- name: GetPaymentSummary :many
 SELECT
-    p.payment_method,
-    COUNT(p.id) AS transaction_count,
-    SUM(p.amount)::decimal(12,2) AS total_amount
-FROM payments p
-JOIN orders o ON o.id = p.order_id
-WHERE o.outlet_id = $1
-    AND o.status != 'CANCELLED'
-    AND p.processed_at >= $2
-    AND p.processed_at < $3
-GROUP BY p.payment_method
-ORDER BY p.payment_method
+    t.payment_method,
+    COUNT(*) FILTER (WHERE t.kind = 'PAYMENT') AS transaction_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'PAYMENT'), 0)::decimal(12,2) AS total_amount,
+    COUNT(*) FILTER (WHERE t.kind = 'REFUND') AS refund_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'REFUND'), 0)::decimal(12,2) AS refund_amount,
+    (COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'PAYMENT'), 0)
+        - COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'REFUND'), 0))::decimal(12,2) AS net_amount
+FROM (
+    SELECT p.payment_method, p.amount, 'PAYMENT' AS kind
+    FROM payments p
+    JOIN orders o ON o.id = p.order_id
+    WHERE o.outlet_id = $1
+        AND o.status != 'CANCELLED'
+        AND p.processed_at >= $2
+        AND p.processed_at < $3
+    UNION ALL
+    SELECT p.payment_method, r.amount, 'REFUND' AS kind
+    FROM payment_refunds r
+    JOIN payments p ON p.id = r.payment_id
+    JOIN orders o ON o.id = r.order_id
+    WHERE o.outlet_id = $1
+        AND o.status != 'CANCELLED'
+        AND r.created_at >= $2
+        AND r.created_at < $3
+) t
+GROUP BY t.payment_method
+ORDER BY t.payment_method
 `
 
 type GetPaymentSummaryParams struct {
@@ -193,8 +218,12 @@ type GetPaymentSummaryRow struct {
 	PaymentMethod    string         `json:"payment_method"`
 	TransactionCount int64          `json:"transaction_count"`
 	TotalAmount      pgtype.Numeric `json:"total_amount"`
+	RefundCount      int64          `json:"refund_count"`
+	RefundAmount     pgtype.Numeric `json:"refund_amount"`
+	NetAmount        pgtype.Numeric `json:"net_amount"`
 }
 
+// Payments are counted on their processed date and refunds on their refund date.
 func (q *Queries) GetPaymentSummary(ctx context.Context, arg GetPaymentSummaryParams) ([]GetPaymentSummaryRow, error) {
 	rows, err := q.db.Query(ctx, getPaymentSummary, arg.OutletID, arg.ProcessedAt, arg.ProcessedAt_2)
 	if err != nil {
@@ -204,7 +233,145 @@ func (q *Queries) GetPaymentSummary(ctx context.Context, arg GetPaymentSummaryPa
 	items := []GetPaymentSummaryRow{}
 	for rows.Next() {
 		var i GetPaymentSummaryRow
-		if err := rows.Scan(&i.PaymentMethod, &i.TransactionCount, &i.TotalAmount); err != nil {
+		if err := rows.Scan(
+			&i.PaymentMethod,
+			&i.TransactionCount,
+			&i.TotalAmount,
+			&i.RefundCount,
+			&i.RefundAmount,
+			&i.NetAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPrepTimesByProduct = `-- name: GetPrepTimesByProduct :many
+SELECT
+    p.id AS product_id,
+    p.name AS product_name,
+    p.preparation_time,
+    COUNT(*) AS item_count,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.sent_at)))), 0)::bigint AS avg_prep_seconds,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.started_at)))), 0)::bigint AS avg_cook_seconds,
+    COUNT(*) FILTER (WHERE p.preparation_time > 0
+        AND oi.ready_at > oi.sent_at + make_interval(mins => p.preparation_time)) AS late_count
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+JOIN products p ON p.id = oi.product_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND oi.ready_at IS NOT NULL
+    AND oi.sent_at >= $2
+    AND oi.sent_at < $3
+    AND NOT EXISTS (SELECT 1 FROM order_items c WHERE c.parent_item_id = oi.id)
+GROUP BY p.id, p.name, p.preparation_time
+ORDER BY avg_prep_seconds DESC
+`
+
+type GetPrepTimesByProductParams struct {
+	OutletID uuid.UUID `json:"outlet_id"`
+	SentAt   time.Time `json:"sent_at"`
+	SentAt_2 time.Time `json:"sent_at_2"`
+}
+
+type GetPrepTimesByProductRow struct {
+	ProductID       uuid.UUID   `json:"product_id"`
+	ProductName     string      `json:"product_name"`
+	PreparationTime pgtype.Int4 `json:"preparation_time"`
+	ItemCount       int64       `json:"item_count"`
+	AvgPrepSeconds  int64       `json:"avg_prep_seconds"`
+	AvgCookSeconds  int64       `json:"avg_cook_seconds"`
+	LateCount       int64       `json:"late_count"`
+}
+
+// Kitchen timing for items that reached READY, by when they were sent.
+// prep is sent to ready, cook is started to ready. Combo lines are left out;
+// their components are counted instead.
+func (q *Queries) GetPrepTimesByProduct(ctx context.Context, arg GetPrepTimesByProductParams) ([]GetPrepTimesByProductRow, error) {
+	rows, err := q.db.Query(ctx, getPrepTimesByProduct, arg.OutletID, arg.SentAt, arg.SentAt_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPrepTimesByProductRow{}
+	for rows.Next() {
+		var i GetPrepTimesByProductRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.ProductName,
+			&i.PreparationTime,
+			&i.ItemCount,
+			&i.AvgPrepSeconds,
+			&i.AvgCookSeconds,
+			&i.LateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPrepTimesByStation = `-- name: GetPrepTimesByStation :many
+SELECT
+    oi.station,
+    COUNT(*) AS item_count,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.sent_at)))), 0)::bigint AS avg_prep_seconds,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.started_at)))), 0)::bigint AS avg_cook_seconds,
+    COUNT(*) FILTER (WHERE p.preparation_time > 0
+        AND oi.ready_at > oi.sent_at + make_interval(mins => p.preparation_time)) AS late_count
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+JOIN products p ON p.id = oi.product_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND oi.ready_at IS NOT NULL
+    AND oi.station IS NOT NULL
+    AND oi.sent_at >= $2
+    AND oi.sent_at < $3
+GROUP BY oi.station
+ORDER BY oi.station
+`
+
+type GetPrepTimesByStationParams struct {
+	OutletID uuid.UUID `json:"outlet_id"`
+	SentAt   time.Time `json:"sent_at"`
+	SentAt_2 time.Time `json:"sent_at_2"`
+}
+
+type GetPrepTimesByStationRow struct {
+	Station        pgtype.Text `json:"station"`
+	ItemCount      int64       `json:"item_count"`
+	AvgPrepSeconds int64       `json:"avg_prep_seconds"`
+	AvgCookSeconds int64       `json:"avg_cook_seconds"`
+	LateCount      int64       `json:"late_count"`
+}
+
+func (q *Queries) GetPrepTimesByStation(ctx context.Context, arg GetPrepTimesByStationParams) ([]GetPrepTimesByStationRow, error) {
+	rows, err := q.db.Query(ctx, getPrepTimesByStation, arg.OutletID, arg.SentAt, arg.SentAt_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPrepTimesByStationRow{}
+	for rows.Next() {
+		var i GetPrepTimesByStationRow
+		if err := rows.Scan(
+			&i.Station,
+			&i.ItemCount,
+			&i.AvgPrepSeconds,
+			&i.AvgCookSeconds,
+			&i.LateCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -226,6 +393,7 @@ JOIN orders o ON o.id = oi.order_id
 JOIN products p ON p.id = oi.product_id
 WHERE o.outlet_id = $1
     AND o.status != 'CANCELLED'
+    AND oi.parent_item_id IS NULL
     AND o.created_at >= $2
     AND o.created_at < $3
 GROUP BY p.id, p.name
@@ -276,3 +444,61 @@ func (q *Queries) GetProductSales(ctx context.Context, arg GetProductSalesParams
 	}
 	return items, nil
 }
+
+const getPromotionUsage = `-- name: GetPromotionUsage :many
+SELECT
+    p.id AS promotion_id,
+    p.name AS promotion_name,
+    p.code,
+    COUNT(o.id) AS order_count,
+    SUM(o.promotion_amount)::decimal(12,2) AS total_discount
+FROM orders o
+JOIN promotions p ON p.id = o.promotion_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND o.created_at >= $2
+    AND o.created_at < $3
+GROUP BY p.id, p.name, p.code
+ORDER BY total_discount DESC
+`
+
+type GetPromotionUsageParams struct {
+	OutletID    uuid.UUID `json:"outlet_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedAt_2 time.Time `json:"created_at_2"`
+}
+
+type GetPromotionUsageRow struct {
+	PromotionID   uuid.UUID      `json:"promotion_id"`
+	PromotionName string         `json:"promotion_name"`
+	Code          pgtype.Text    `json:"code"`
+	OrderCount    int64          `json:"order_count"`
+	TotalDiscount pgtype.Numeric `json:"total_discount"`
+}
+
+// Orders that received each promotion and the discount it gave.
+func (q *Queries) GetPromotionUsage(ctx context.Context, arg GetPromotionUsageParams) ([]GetPromotionUsageRow, error) {
+	rows, err := q.db.Query(ctx, getPromotionUsage, arg.OutletID, arg.CreatedAt, arg.CreatedAt_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPromotionUsageRow{}
+	for rows.Next() {
+		var i GetPromotionUsageRow
+		if err := rows.Scan(
+			&i.PromotionID,
+			&i.PromotionName,
+			&i.Code,
+			&i.OrderCount,
+			&i.TotalDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
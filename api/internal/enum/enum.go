@@ -29,6 +29,36 @@ const (
 	PaymentStatusFailed    = "FAILED"
 )
 
+const (
+	KitchenTicketTypeNew    = "NEW"
+	KitchenTicketTypeChange = "CHANGE"
+)
+
+const (
+	PromotionTypePercentage = "PERCENTAGE"
+	PromotionTypeFixed      = "FIXED_AMOUNT"
+	PromotionTypeBuyXGetY   = "BUY_X_GET_Y"
+	PromotionTypeBundle     = "BUNDLE_PRICE"
+)
+
+const (
+	LoyaltyTransactionEarn           = "EARN"
+	LoyaltyTransactionEarnReversal   = "EARN_REVERSAL"
+	LoyaltyTransactionRedeem         = "REDEEM"
+	LoyaltyTransactionRedeemReversal = "REDEEM_REVERSAL"
+	LoyaltyTransactionExpire         = "EXPIRE"
+)
+
+const (
+	ShiftStatusOpen   = "OPEN"
+	ShiftStatusClosed = "CLOSED"
+)
+
+const (
+	CashMovementIn  = "CASH_IN"
+	CashMovementOut = "CASH_OUT"
+)
+
 // ── Group C: Borderline (CHECK constrained in DB) ──
 
 const (
@@ -45,6 +75,35 @@ const (
 	OrderTypeCatering = "CATERING"
 )
 
+const (
+	RoundingModeHalfUp = "HALF_UP"
+	RoundingModeUp     = "UP"
+	RoundingModeDown   = "DOWN"
+)
+
+const (
+	SplitTypeItem = "ITEM"
+	SplitTypeEven = "EVEN"
+)
+
+const (
+	OrderEventCreated           = "ORDER_CREATED"
+	OrderEventStatusChanged     = "STATUS_CHANGED"
+	OrderEventCancelled         = "ORDER_CANCELLED"
+	OrderEventItemAdded         = "ITEM_ADDED"
+	OrderEventItemUpdated       = "ITEM_UPDATED"
+	OrderEventItemRemoved       = "ITEM_REMOVED"
+	OrderEventItemStatusChanged = "ITEM_STATUS_CHANGED"
+	OrderEventDiscountApplied   = "DISCOUNT_APPLIED"
+	OrderEventPaymentAdded      = "PAYMENT_ADDED"
+	OrderEventPaymentRefunded   = "PAYMENT_REFUNDED"
+	OrderEventTableChanged      = "TABLE_CHANGED"
+	OrderEventOrdersMerged      = "ORDERS_MERGED"
+	OrderEventAcknowledged      = "ORDER_ACKNOWLEDGED"
+	OrderEventPromotionApplied  = "PROMOTION_APPLIED"
+	OrderEventPriceAdjusted     = "PRICE_ADJUSTED"
+)
+
 // ── Group B: Configurable labels (no DB constraint) ──
 
 const (
@@ -58,9 +117,32 @@ const (
 	PaymentMethodCash     = "CASH"
 	PaymentMethodQRIS     = "QRIS"
 	PaymentMethodTransfer = "TRANSFER"
+	PaymentMethodPoints   = "POINTS" // loyalty points redeemed
 )
 
 const (
 	DiscountTypePercentage = "PERCENTAGE"
 	DiscountTypeFixed      = "FIXED_AMOUNT"
 )
+
+// Kitchen ticket line changes, stored in the ticket's printed lines
+const (
+	KitchenLineAdded           = "ADDED"
+	KitchenLineQuantityChanged = "QUANTITY_CHANGED"
+	KitchenLineVoided          = "VOIDED"
+)
+
+// ── Derived (computed from other data, never stored) ──
+
+const (
+	TableStatusFree          = "FREE"
+	TableStatusOccupied      = "OCCUPIED"
+	TableStatusBillRequested = "BILL_REQUESTED"
+)
+
+// Loyalty tiers, from the customer's lifetime spend
+const (
+	LoyaltyTierMember = "MEMBER"
+	LoyaltyTierSilver = "SILVER"
+	LoyaltyTierGold   = "GOLD"
+)
@@ -0,0 +1,416 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// CheckStore defines the database methods needed by check (split bill) handlers.
+type CheckStore interface {
+	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	GetOrderForUpdate(ctx context.Context, arg database.GetOrderForUpdateParams) (database.Order, error)
+	ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error)
+	ListOrderChecks(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderChecksRow, error)
+	ListOrderCheckItems(ctx context.Context, orderID uuid.UUID) ([]database.OrderCheckItem, error)
+	CountPaymentsByOrder(ctx context.Context, orderID uuid.UUID) (int64, error)
+	CreateOrderCheck(ctx context.Context, arg database.CreateOrderCheckParams) (database.OrderCheck, error)
+	CreateOrderCheckItem(ctx context.Context, arg database.CreateOrderCheckItemParams) error
+	DeleteOrderChecks(ctx context.Context, orderID uuid.UUID) error
+}
+
+// NewCheckStore creates a CheckStore from a DBTX (pool or tx).
+type NewCheckStore func(db database.DBTX) CheckStore
+
+// CheckHandler handles splitting an order into separately paid checks.
+type CheckHandler struct {
+	store    CheckStore
+	pool     service.TxBeginner
+	newStore NewCheckStore
+}
+
+// NewCheckHandler creates a new CheckHandler.
+func NewCheckHandler(store CheckStore, pool service.TxBeginner, newStore NewCheckStore) *CheckHandler {
+	return &CheckHandler{store: store, pool: pool, newStore: newStore}
+}
+
+// RegisterRoutes registers check endpoints on the given Chi router.
+// Expected to be mounted at /outlets/{oid}/orders/{id}/checks
+func (h *CheckHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Split)
+	r.Delete("/", h.Unsplit)
+}
+
+// --- Request / Response types ---
+
+type splitCheckRequest struct {
+	ItemIDs []string `json:"item_ids"`
+}
+
+type splitOrderRequest struct {
+	SplitType string              `json:"split_type"`
+	Count     int                 `json:"count"`
+	Checks    []splitCheckRequest `json:"checks"`
+}
+
+type checkResponse struct {
+	ID          uuid.UUID `json:"id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	CheckNumber int32     `json:"check_number"`
+	SplitType   string    `json:"split_type"`
+	Amount      string    `json:"amount"`
+	AmountPaid  string    `json:"amount_paid"`
+	Remaining   string    `json:"remaining"`
+	Settled     bool      `json:"settled"`
+	ItemIDs     []string  `json:"item_ids"`
+}
+
+// --- Handlers ---
+
+// List handles GET /outlets/{oid}/orders/{id}/checks.
+func (h *CheckHandler) List(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	_, err = h.store.GetOrder(r.Context(), database.GetOrderParams{
+		ID:       orderID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return
+		}
+		log.Printf("ERROR: get order for list checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp, err := loadChecks(r.Context(), h.store, orderID)
+	if err != nil {
+		log.Printf("ERROR: list checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Split handles POST /outlets/{oid}/orders/{id}/checks.
+// Replaces any existing split, as long as no payment has been taken yet.
+func (h *CheckHandler) Split(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	var req splitOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	// Parse item assignments up front so malformed requests fail before the tx
+	var checkItemIDs [][]uuid.UUID
+	switch req.SplitType {
+	case enum.SplitTypeEven:
+		if req.Count < 2 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "count must be at least 2"})
+			return
+		}
+	case enum.SplitTypeItem:
+		if len(req.Checks) < 2 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "at least 2 checks are required"})
+			return
+		}
+		checkItemIDs = make([][]uuid.UUID, len(req.Checks))
+		for i, c := range req.Checks {
+			if len(c.ItemIDs) == 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("check %d has no items", i+1)})
+				return
+			}
+			for _, raw := range c.ItemIDs {
+				id, err := uuid.Parse(raw)
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid item_id: " + raw})
+					return
+				}
+				checkItemIDs[i] = append(checkItemIDs[i], id)
+			}
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "split_type must be ITEM or EVEN"})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for split order: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	// Lock the order row so a concurrent payment cannot slip in mid-split
+	order, ok := lockSplittableOrder(w, r, txStore, outletID, orderID)
+	if !ok {
+		return
+	}
+
+	orderTotal, _ := numericToDecimal(order.TotalAmount)
+
+	var amounts []decimal.Decimal
+	if req.SplitType == enum.SplitTypeEven {
+		amounts = service.SplitEvenly(orderTotal, req.Count)
+	} else {
+		items, err := txStore.ListOrderItemsByOrder(r.Context(), orderID)
+		if err != nil {
+			log.Printf("ERROR: list order items for split: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		// Combo components are billed on their combo line
+		subtotals := make(map[uuid.UUID]decimal.Decimal, len(items))
+		for _, item := range items {
+			if item.ParentItemID.Valid {
+				continue
+			}
+			subtotals[item.ID], _ = numericToDecimal(item.Subtotal)
+		}
+
+		// Every item must land on exactly one check
+		assigned := make(map[uuid.UUID]bool, len(items))
+		checkSubtotals := make([]decimal.Decimal, len(checkItemIDs))
+		for i, ids := range checkItemIDs {
+			for _, id := range ids {
+				subtotal, ok := subtotals[id]
+				if !ok {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item not found in order: " + id.String()})
+					return
+				}
+				if assigned[id] {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item assigned to more than one check: " + id.String()})
+					return
+				}
+				assigned[id] = true
+				checkSubtotals[i] = checkSubtotals[i].Add(subtotal)
+			}
+		}
+		if len(assigned) != len(subtotals) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "every order item must be assigned to a check"})
+			return
+		}
+		amounts = service.SplitByItems(orderTotal, checkSubtotals)
+	}
+
+	if err := txStore.DeleteOrderChecks(r.Context(), orderID); err != nil {
+		log.Printf("ERROR: delete existing checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	for i, amount := range amounts {
+		check, err := txStore.CreateOrderCheck(r.Context(), database.CreateOrderCheckParams{
+			OrderID:     orderID,
+			CheckNumber: int32(i + 1),
+			SplitType:   req.SplitType,
+			Amount:      decimalToNumeric(amount),
+			CreatedBy:   claims.UserID,
+		})
+		if err != nil {
+			log.Printf("ERROR: create order check: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if checkItemIDs == nil {
+			continue
+		}
+		for _, itemID := range checkItemIDs[i] {
+			if err := txStore.CreateOrderCheckItem(r.Context(), database.CreateOrderCheckItemParams{
+				CheckID:     check.ID,
+				OrderItemID: itemID,
+			}); err != nil {
+				log.Printf("ERROR: create order check item: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for split order: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp, err := loadChecks(r.Context(), h.store, orderID)
+	if err != nil {
+		log.Printf("ERROR: list checks after split: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// Unsplit handles DELETE /outlets/{oid}/orders/{id}/checks.
+func (h *CheckHandler) Unsplit(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for unsplit order: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	if _, ok := lockSplittableOrder(w, r, txStore, outletID, orderID); !ok {
+		return
+	}
+
+	if err := txStore.DeleteOrderChecks(r.Context(), orderID); err != nil {
+		log.Printf("ERROR: delete checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for unsplit order: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Helpers ---
+
+// lockSplittableOrder locks the order and verifies its split can still change:
+// the order must be open and no payment may have been taken yet.
+// Writes the error response and returns false if the request should stop.
+func lockSplittableOrder(w http.ResponseWriter, r *http.Request, store CheckStore, outletID, orderID uuid.UUID) (database.Order, bool) {
+	order, err := store.GetOrderForUpdate(r.Context(), database.GetOrderForUpdateParams{
+		ID:       orderID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return database.Order{}, false
+		}
+		log.Printf("ERROR: get order for split: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.Order{}, false
+	}
+
+	if order.Status == enum.OrderStatusCompleted || order.Status == enum.OrderStatusCancelled {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot split a " + order.Status + " order"})
+		return database.Order{}, false
+	}
+
+	paymentCount, err := store.CountPaymentsByOrder(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: count payments for split: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.Order{}, false
+	}
+	if paymentCount > 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot change the split after payments have been taken"})
+		return database.Order{}, false
+	}
+
+	return order, true
+}
+
+// loadChecks fetches an order's checks with their items and paid amounts.
+func loadChecks(ctx context.Context, store CheckStore, orderID uuid.UUID) ([]checkResponse, error) {
+	checks, err := store.ListOrderChecks(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list order checks: %w", err)
+	}
+
+	checkItems, err := store.ListOrderCheckItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list order check items: %w", err)
+	}
+	itemsByCheck := make(map[uuid.UUID][]string)
+	for _, ci := range checkItems {
+		itemsByCheck[ci.CheckID] = append(itemsByCheck[ci.CheckID], ci.OrderItemID.String())
+	}
+
+	resp := make([]checkResponse, len(checks))
+	for i, c := range checks {
+		amount, _ := numericToDecimal(c.Amount)
+		paid, _ := numericToDecimal(c.AmountPaid)
+		remaining := amount.Sub(paid)
+		if remaining.IsNegative() {
+			remaining = decimal.Zero
+		}
+		itemIDs := itemsByCheck[c.ID]
+		if itemIDs == nil {
+			itemIDs = []string{}
+		}
+		resp[i] = checkResponse{
+			ID:          c.ID,
+			OrderID:     c.OrderID,
+			CheckNumber: c.CheckNumber,
+			SplitType:   c.SplitType,
+			Amount:      numericToString(c.Amount),
+			AmountPaid:  numericToString(c.AmountPaid),
+			Remaining:   remaining.StringFixed(2),
+			Settled:     paid.GreaterThanOrEqual(amount),
+			ItemIDs:     itemIDs,
+		}
+	}
+	return resp, nil
+}
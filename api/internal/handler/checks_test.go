@@ -0,0 +1,418 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock CheckStore ---
+
+type mockCheckStore struct {
+	orders       map[uuid.UUID]database.Order
+	items        map[uuid.UUID][]database.OrderItem // keyed by order ID
+	checks       map[uuid.UUID]database.OrderCheck  // keyed by check ID
+	checkItems   []database.OrderCheckItem
+	paymentCount int64
+}
+
+func newMockCheckStore() *mockCheckStore {
+	return &mockCheckStore{
+		orders: make(map[uuid.UUID]database.Order),
+		items:  make(map[uuid.UUID][]database.OrderItem),
+		checks: make(map[uuid.UUID]database.OrderCheck),
+	}
+}
+
+func (m *mockCheckStore) GetOrder(_ context.Context, arg database.GetOrderParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (m *mockCheckStore) GetOrderForUpdate(_ context.Context, arg database.GetOrderForUpdateParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (m *mockCheckStore) ListOrderItemsByOrder(_ context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+	return m.items[orderID], nil
+}
+
+func (m *mockCheckStore) ListOrderChecks(_ context.Context, orderID uuid.UUID) ([]database.ListOrderChecksRow, error) {
+	result := make([]database.ListOrderChecksRow, 0)
+	for n := int32(1); n <= int32(len(m.checks)); n++ {
+		for _, c := range m.checks {
+			if c.OrderID == orderID && c.CheckNumber == n {
+				result = append(result, database.ListOrderChecksRow{
+					ID:          c.ID,
+					OrderID:     c.OrderID,
+					CheckNumber: c.CheckNumber,
+					SplitType:   c.SplitType,
+					Amount:      c.Amount,
+					CreatedBy:   c.CreatedBy,
+					CreatedAt:   c.CreatedAt,
+					AmountPaid:  decimalToNumeric(decimal.Zero),
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCheckStore) ListOrderCheckItems(_ context.Context, orderID uuid.UUID) ([]database.OrderCheckItem, error) {
+	var result []database.OrderCheckItem
+	for _, ci := range m.checkItems {
+		if c, ok := m.checks[ci.CheckID]; ok && c.OrderID == orderID {
+			result = append(result, ci)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCheckStore) CountPaymentsByOrder(_ context.Context, _ uuid.UUID) (int64, error) {
+	return m.paymentCount, nil
+}
+
+func (m *mockCheckStore) CreateOrderCheck(_ context.Context, arg database.CreateOrderCheckParams) (database.OrderCheck, error) {
+	c := database.OrderCheck{
+		ID:          uuid.New(),
+		OrderID:     arg.OrderID,
+		CheckNumber: arg.CheckNumber,
+		SplitType:   arg.SplitType,
+		Amount:      arg.Amount,
+		CreatedBy:   arg.CreatedBy,
+		CreatedAt:   time.Now(),
+	}
+	m.checks[c.ID] = c
+	return c, nil
+}
+
+func (m *mockCheckStore) CreateOrderCheckItem(_ context.Context, arg database.CreateOrderCheckItemParams) error {
+	m.checkItems = append(m.checkItems, database.OrderCheckItem{CheckID: arg.CheckID, OrderItemID: arg.OrderItemID})
+	return nil
+}
+
+func (m *mockCheckStore) DeleteOrderChecks(_ context.Context, orderID uuid.UUID) error {
+	for id, c := range m.checks {
+		if c.OrderID == orderID {
+			delete(m.checks, id)
+		}
+	}
+	kept := m.checkItems[:0]
+	for _, ci := range m.checkItems {
+		if _, ok := m.checks[ci.CheckID]; ok {
+			kept = append(kept, ci)
+		}
+	}
+	m.checkItems = kept
+	return nil
+}
+
+// --- Helpers ---
+
+func setupCheckRouter(store *mockCheckStore) *chi.Mux {
+	newStore := func(db database.DBTX) handler.CheckStore {
+		return store
+	}
+	h := handler.NewCheckHandler(store, &mockPool{}, newStore)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/orders/{id}/checks", h.RegisterRoutes)
+	return r
+}
+
+// seedCheckOrder creates a NEW order of 115500 (after tax and service charge)
+// with two items whose subtotals are 40000 and 60000.
+func seedCheckOrder(store *mockCheckStore, outletID uuid.UUID) (uuid.UUID, uuid.UUID, uuid.UUID) {
+	orderID := uuid.New()
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-CHK",
+		OrderType:   enum.OrderTypeDineIn,
+		Status:      enum.OrderStatusNew,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(115500)),
+	}
+	item1, item2 := uuid.New(), uuid.New()
+	store.items[orderID] = []database.OrderItem{
+		{ID: item1, OrderID: orderID, Subtotal: decimalToNumeric(decimal.NewFromInt(40000))},
+		{ID: item2, OrderID: orderID, Subtotal: decimalToNumeric(decimal.NewFromInt(60000))},
+	}
+	return orderID, item1, item2
+}
+
+func decodeCheckListResponse(t *testing.T, rr *httptest.ResponseRecorder) []map[string]interface{} {
+	t.Helper()
+	var resp []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func checksPath(outletID, orderID uuid.UUID) string {
+	return "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/checks"
+}
+
+// --- Split tests ---
+
+func TestSplitOrder_Even(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN",
+		"count":      3,
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodeCheckListResponse(t, rr)
+	if len(resp) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(resp))
+	}
+	want := []string{"38500.00", "38500.00", "38500.00"}
+	for i, c := range resp {
+		if c["amount"] != want[i] {
+			t.Errorf("check %d amount: got %v, want %s", i+1, c["amount"], want[i])
+		}
+		if c["settled"] != false {
+			t.Errorf("check %d settled: got %v, want false", i+1, c["settled"])
+		}
+	}
+}
+
+func TestSplitOrder_ByItem(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, item1, item2 := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "ITEM",
+		"checks": []map[string]interface{}{
+			{"item_ids": []string{item1.String()}},
+			{"item_ids": []string{item2.String()}},
+		},
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodeCheckListResponse(t, rr)
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(resp))
+	}
+	// Charges are shared in proportion to item subtotals (40% / 60%)
+	if resp[0]["amount"] != "46200.00" {
+		t.Errorf("check 1 amount: got %v, want 46200.00", resp[0]["amount"])
+	}
+	if resp[1]["amount"] != "69300.00" {
+		t.Errorf("check 2 amount: got %v, want 69300.00", resp[1]["amount"])
+	}
+	ids, _ := resp[0]["item_ids"].([]interface{})
+	if len(ids) != 1 || ids[0] != item1.String() {
+		t.Errorf("check 1 item_ids: got %v, want [%s]", resp[0]["item_ids"], item1)
+	}
+}
+
+func TestSplitOrder_ReplacesExistingSplit(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 3,
+	}, claims)
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if len(store.checks) != 2 {
+		t.Errorf("expected previous split to be replaced, have %d checks", len(store.checks))
+	}
+}
+
+func TestSplitOrder_Invalid(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, item1, item2 := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"unknown split type", map[string]interface{}{"split_type": "HALF"}},
+		{"even with one check", map[string]interface{}{"split_type": "EVEN", "count": 1}},
+		{"item with one check", map[string]interface{}{"split_type": "ITEM", "checks": []map[string]interface{}{
+			{"item_ids": []string{item1.String(), item2.String()}},
+		}}},
+		{"empty check", map[string]interface{}{"split_type": "ITEM", "checks": []map[string]interface{}{
+			{"item_ids": []string{item1.String(), item2.String()}},
+			{"item_ids": []string{}},
+		}}},
+		{"item assigned twice", map[string]interface{}{"split_type": "ITEM", "checks": []map[string]interface{}{
+			{"item_ids": []string{item1.String(), item2.String()}},
+			{"item_ids": []string{item2.String()}},
+		}}},
+		{"item left unassigned", map[string]interface{}{"split_type": "ITEM", "checks": []map[string]interface{}{
+			{"item_ids": []string{item1.String()}},
+			{"item_ids": []string{uuid.New().String()}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupCheckRouter(store)
+			rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), tt.body, claims)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+			if len(store.checks) != 0 {
+				t.Error("expected no checks to be created")
+			}
+		})
+	}
+}
+
+func TestSplitOrder_WithPayments(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	store.paymentCount = 1
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+func TestSplitOrder_CompletedOrder(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	o := store.orders[orderID]
+	o.Status = enum.OrderStatusCompleted
+	store.orders[orderID] = o
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+func TestSplitOrder_OrderNotFound(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "POST", checksPath(outletID, uuid.New()), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusNotFound, rr.Body.String())
+	}
+}
+
+// --- List / Unsplit tests ---
+
+func TestListChecks_Unsplit(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	rr := doAuthRequest(t, router, "GET", checksPath(outletID, orderID), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if resp := decodeCheckListResponse(t, rr); len(resp) != 0 {
+		t.Errorf("expected no checks, got %d", len(resp))
+	}
+}
+
+func TestUnsplitOrder(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+	rr := doAuthRequest(t, router, "DELETE", checksPath(outletID, orderID), nil, claims)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusNoContent, rr.Body.String())
+	}
+	if len(store.checks) != 0 {
+		t.Errorf("expected checks to be removed, have %d", len(store.checks))
+	}
+}
+
+func TestUnsplitOrder_WithPayments(t *testing.T) {
+	store := newMockCheckStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedCheckOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupCheckRouter(store)
+
+	doAuthRequest(t, router, "POST", checksPath(outletID, orderID), map[string]interface{}{
+		"split_type": "EVEN", "count": 2,
+	}, claims)
+	store.paymentCount = 1
+	rr := doAuthRequest(t, router, "DELETE", checksPath(outletID, orderID), nil, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if len(store.checks) != 2 {
+		t.Error("expected checks to be kept")
+	}
+}
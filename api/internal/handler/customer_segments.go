@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+// Customer segments, from recency/frequency/monetary scores over a window.
+const (
+	segmentChampions = "champions" // recent, frequent and high spending
+	segmentNew       = "new"       // first order within the window
+	segmentRegular   = "regular"   // active in the window, none of the others
+	segmentAtRisk    = "at_risk"   // no order in the later part of the window
+	segmentLapsed    = "lapsed"    // no order within the window
+)
+
+var customerSegments = []string{segmentChampions, segmentNew, segmentRegular, segmentAtRisk, segmentLapsed}
+
+const (
+	defaultSegmentWindowDays = 90
+	maxSegmentWindowDays     = 730
+)
+
+type customerSegmentResponse struct {
+	CustomerID  uuid.UUID `json:"customer_id"`
+	Name        string    `json:"name"`
+	Phone       string    `json:"phone"`
+	Segment     string    `json:"segment"`
+	LastOrderAt time.Time `json:"last_order_at"`
+	RecencyDays int       `json:"recency_days"`
+	Frequency   int64     `json:"frequency"`
+	Monetary    string    `json:"monetary"`
+	RScore      int       `json:"r_score"`
+	FScore      int       `json:"f_score"`
+	MScore      int       `json:"m_score"`
+}
+
+type customerSegmentsResponse struct {
+	WindowDays int                       `json:"window_days"`
+	AsOf       time.Time                 `json:"as_of"`
+	Counts     map[string]int            `json:"counts"`
+	Customers  []customerSegmentResponse `json:"customers"`
+}
+
+// Segments puts every customer who has ordered into an RFM segment over the
+// last ?days (default 90). ?segment= narrows the list to one segment and
+// ?format=csv downloads it for win-back campaigns.
+func (h *CustomerHandler) Segments(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	days := defaultSegmentWindowDays
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > maxSegmentWindowDays {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("days must be between 1 and %d", maxSegmentWindowDays)})
+			return
+		}
+		days = v
+	}
+
+	segment := r.URL.Query().Get("segment")
+	if segment != "" && !slices.Contains(customerSegments, segment) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid segment"})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be json or csv"})
+		return
+	}
+
+	now := time.Now()
+	rows, err := h.store.ListCustomerRFM(r.Context(), database.ListCustomerRFMParams{
+		WindowStart: now.AddDate(0, 0, -days),
+		OutletID:    outletID,
+	})
+	if err != nil {
+		log.Printf("ERROR: list customer rfm: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	all, err := segmentCustomers(rows, now, days)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	counts := make(map[string]int, len(customerSegments))
+	for _, s := range customerSegments {
+		counts[s] = 0
+	}
+	customers := []customerSegmentResponse{}
+	for _, c := range all {
+		counts[c.Segment]++
+		if segment == "" || c.Segment == segment {
+			customers = append(customers, c)
+		}
+	}
+
+	if format == "csv" {
+		writeCustomerSegmentsCSV(w, customers, segment, now)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customerSegmentsResponse{
+		WindowDays: days,
+		AsOf:       now,
+		Counts:     counts,
+		Customers:  customers,
+	})
+}
+
+// segmentCustomers scores each customer 1-5 on recency, frequency and
+// monetary value and assigns a segment. Recency is scored against the
+// window, so 5 is the most recent fifth of it. Frequency and spend are
+// scored by rank among the customers who ordered within the window, so 5
+// is roughly the top fifth and customers who tie get the same score.
+func segmentCustomers(rows []database.ListCustomerRFMRow, now time.Time, days int) ([]customerSegmentResponse, error) {
+	windowStart := now.AddDate(0, 0, -days)
+
+	rowSpends := make([]decimal.Decimal, len(rows))
+	var frequencies, spends []decimal.Decimal
+	for i, row := range rows {
+		spend, err := numericToDecimal(row.TotalSpend)
+		if err != nil {
+			return nil, fmt.Errorf("parse customer %s spend: %w", row.ID, err)
+		}
+		rowSpends[i] = spend
+		if row.OrderCount > 0 {
+			frequencies = append(frequencies, decimal.NewFromInt(row.OrderCount))
+			spends = append(spends, spend)
+		}
+	}
+	slices.SortFunc(frequencies, decimal.Decimal.Cmp)
+	slices.SortFunc(spends, decimal.Decimal.Cmp)
+
+	result := make([]customerSegmentResponse, 0, len(rows))
+	for i, row := range rows {
+		lastOrderAt := row.LastOrderAt.Time
+		spend := rowSpends[i]
+		c := customerSegmentResponse{
+			CustomerID:  row.ID,
+			Name:        row.Name,
+			Phone:       row.Phone,
+			LastOrderAt: lastOrderAt,
+			RecencyDays: int(now.Sub(lastOrderAt) / (24 * time.Hour)),
+			Frequency:   row.OrderCount,
+			Monetary:    spend.StringFixed(2),
+		}
+
+		if row.OrderCount == 0 {
+			c.Segment = segmentLapsed
+			result = append(result, c)
+			continue
+		}
+
+		c.RScore = min(5, max(1, 5-c.RecencyDays*5/days))
+		c.FScore = rankScore(frequencies, decimal.NewFromInt(row.OrderCount))
+		c.MScore = rankScore(spends, spend)
+
+		switch {
+		case c.RScore >= 4 && c.FScore >= 4 && c.MScore >= 4:
+			c.Segment = segmentChampions
+		case !row.FirstOrderAt.Time.Before(windowStart):
+			c.Segment = segmentNew
+		case c.RScore <= 2:
+			c.Segment = segmentAtRisk
+		default:
+			c.Segment = segmentRegular
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// rankScore returns 1-5 from the share of sorted that is below v.
+func rankScore(sorted []decimal.Decimal, v decimal.Decimal) int {
+	below, _ := slices.BinarySearchFunc(sorted, v, decimal.Decimal.Cmp)
+	return 1 + below*5/len(sorted)
+}
+
+// writeCustomerSegmentsCSV writes customers as a spreadsheet-friendly
+// download. Phones are E.164 with the + dropped, the form WhatsApp
+// broadcast tools and wa.me links take.
+func writeCustomerSegmentsCSV(w http.ResponseWriter, customers []customerSegmentResponse, segment string, now time.Time) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+
+	name := segment
+	if name == "" {
+		name = "all"
+	}
+	filename := fmt.Sprintf("customers-%s-%s.csv", name, now.In(loc).Format("20060102"))
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "phone", "segment", "last_order_date", "recency_days", "orders", "spend"})
+	for _, c := range customers {
+		cw.Write([]string{
+			csvSafe(c.Name),
+			csvSafe(whatsAppNumber(c.Phone)),
+			c.Segment,
+			c.LastOrderAt.In(loc).Format("2006-01-02"),
+			strconv.Itoa(c.RecencyDays),
+			strconv.FormatInt(c.Frequency, 10),
+			c.Monetary,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("ERROR: write customer segments csv: %v", err)
+	}
+}
+
+// csvSafe keeps a free-text cell from being read as a formula when the
+// file is opened in a spreadsheet, by prefixing it with a quote.
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// whatsAppNumber returns phone as the digits WhatsApp expects, falling back
+// to the stored value for numbers that predate normalization.
+func whatsAppNumber(phone string) string {
+	if n, err := normalizePhone(phone); err == nil {
+		return n[1:]
+	}
+	return phone
+}
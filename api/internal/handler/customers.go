@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,6 +18,9 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
 )
 
 // CustomerStore defines the database methods needed by customer handlers.
@@ -28,16 +34,32 @@ type CustomerStore interface {
 	GetCustomerStats(ctx context.Context, arg database.GetCustomerStatsParams) (database.GetCustomerStatsRow, error)
 	GetCustomerTopItems(ctx context.Context, arg database.GetCustomerTopItemsParams) ([]database.GetCustomerTopItemsRow, error)
 	ListCustomerOrders(ctx context.Context, arg database.ListCustomerOrdersParams) ([]database.Order, error)
+	ListCustomerRFM(ctx context.Context, arg database.ListCustomerRFMParams) ([]database.ListCustomerRFMRow, error)
+	// Duplicates and merging
+	ListDuplicateCustomerCandidates(ctx context.Context, outletID uuid.UUID) ([]database.ListDuplicateCustomerCandidatesRow, error)
+	GetCustomerForUpdate(ctx context.Context, arg database.GetCustomerForUpdateParams) (database.Customer, error)
+	ReassignCustomerOrders(ctx context.Context, arg database.ReassignCustomerOrdersParams) (int64, error)
+	ReassignCustomerLoyaltyTransactions(ctx context.Context, arg database.ReassignCustomerLoyaltyTransactionsParams) (int64, error)
+	MarkCustomerMerged(ctx context.Context, arg database.MarkCustomerMergedParams) (uuid.UUID, error)
+	// Loyalty points
+	service.LoyaltySettingsStore
+	GetCustomerLoyalty(ctx context.Context, customerID uuid.UUID) (database.GetCustomerLoyaltyRow, error)
+	ListCustomerLoyaltyTransactions(ctx context.Context, arg database.ListCustomerLoyaltyTransactionsParams) ([]database.LoyaltyTransaction, error)
 }
 
+// NewCustomerStore creates a CustomerStore from a DBTX (pool or tx).
+type NewCustomerStore func(db database.DBTX) CustomerStore
+
 // CustomerHandler handles customer CRUD endpoints.
 type CustomerHandler struct {
-	store CustomerStore
+	store    CustomerStore
+	pool     service.TxBeginner
+	newStore NewCustomerStore
 }
 
 // NewCustomerHandler creates a new CustomerHandler.
-func NewCustomerHandler(store CustomerStore) *CustomerHandler {
-	return &CustomerHandler{store: store}
+func NewCustomerHandler(store CustomerStore, pool service.TxBeginner, newStore NewCustomerStore) *CustomerHandler {
+	return &CustomerHandler{store: store, pool: pool, newStore: newStore}
 }
 
 // RegisterRoutes registers customer CRUD endpoints on the given Chi router.
@@ -51,9 +73,19 @@ func (h *CustomerHandler) RegisterRoutes(r chi.Router) {
 		r.Delete("/", h.Delete)
 		r.Get("/stats", h.Stats)
 		r.Get("/orders", h.Orders)
+		r.Get("/points", h.Points)
 	})
 }
 
+// RegisterManageRoutes registers the segment, duplicate report and merge
+// endpoints. Expected to be mounted next to RegisterRoutes behind a manager
+// role check.
+func (h *CustomerHandler) RegisterManageRoutes(r chi.Router) {
+	r.Get("/segments", h.Segments)
+	r.Get("/duplicates", h.Duplicates)
+	r.Post("/{id}/merge", h.Merge)
+}
+
 // --- Request / Response types ---
 
 type createCustomerRequest struct {
@@ -70,6 +102,10 @@ type updateCustomerRequest struct {
 	Notes string `json:"notes"`
 }
 
+type mergeCustomerRequest struct {
+	SourceID string `json:"source_id"` // customer merged into the one in the path
+}
+
 type customerResponse struct {
 	ID        uuid.UUID `json:"id"`
 	OutletID  uuid.UUID `json:"outlet_id"`
@@ -96,6 +132,67 @@ type topItemResponse struct {
 	TotalRevenue string    `json:"total_revenue"`
 }
 
+type duplicateCustomerGroupResponse struct {
+	NormalizedPhone string                      `json:"normalized_phone"`
+	Customers       []duplicateCustomerResponse `json:"customers"`
+}
+
+type duplicateCustomerResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Phone      string    `json:"phone"`
+	Email      *string   `json:"email"`
+	OrderCount int64     `json:"order_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type mergeCustomerResponse struct {
+	Customer                 customerResponse `json:"customer"`
+	OrdersMoved              int64            `json:"orders_moved"`
+	LoyaltyTransactionsMoved int64            `json:"loyalty_transactions_moved"`
+}
+
+type customerPointsResponse struct {
+	PointsBalance int32                        `json:"points_balance"`
+	PointsValue   string                       `json:"points_value"` // balance at the outlet's point value
+	Tier          string                       `json:"tier"`
+	LifetimeSpend string                       `json:"lifetime_spend"`
+	Transactions  []loyaltyTransactionResponse `json:"transactions"`
+}
+
+type loyaltyTransactionResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	OrderID         *string    `json:"order_id"`
+	PaymentID       *string    `json:"payment_id"`
+	TransactionType string     `json:"transaction_type"`
+	Points          int32      `json:"points"`
+	Amount          string     `json:"amount"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func toLoyaltyTransactionResponse(t database.LoyaltyTransaction) loyaltyTransactionResponse {
+	resp := loyaltyTransactionResponse{
+		ID:              t.ID,
+		TransactionType: t.TransactionType,
+		Points:          t.Points,
+		Amount:          numericToString(t.Amount),
+		CreatedAt:       t.CreatedAt,
+	}
+	if t.OrderID.Valid {
+		s := uuid.UUID(t.OrderID.Bytes).String()
+		resp.OrderID = &s
+	}
+	if t.PaymentID.Valid {
+		s := uuid.UUID(t.PaymentID.Bytes).String()
+		resp.PaymentID = &s
+	}
+	if t.ExpiresAt.Valid {
+		resp.ExpiresAt = &t.ExpiresAt.Time
+	}
+	return resp
+}
+
 func toCustomerResponse(c database.Customer) customerResponse {
 	resp := customerResponse{
 		ID:        c.ID,
@@ -146,7 +243,7 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Parse search parameter
 	var search pgtype.Text
 	if s := r.URL.Query().Get("search"); s != "" {
-		search = pgtype.Text{String: s, Valid: true}
+		search = pgtype.Text{String: phoneSearchTerm(s), Valid: true}
 	}
 
 	customers, err := h.store.ListCustomersByOutlet(r.Context(), database.ListCustomersByOutletParams{
@@ -223,6 +320,11 @@ func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "phone is required"})
 		return
 	}
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid phone number"})
+		return
+	}
 
 	var email pgtype.Text
 	if req.Email != "" {
@@ -237,7 +339,7 @@ func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
 	customer, err := h.store.CreateCustomer(r.Context(), database.CreateCustomerParams{
 		OutletID: outletID,
 		Name:     req.Name,
-		Phone:    req.Phone,
+		Phone:    phone,
 		Email:    email,
 		Notes:    notes,
 	})
@@ -284,6 +386,11 @@ func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "phone is required"})
 		return
 	}
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid phone number"})
+		return
+	}
 
 	var email pgtype.Text
 	if req.Email != "" {
@@ -298,7 +405,7 @@ func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
 	customer, err := h.store.UpdateCustomer(r.Context(), database.UpdateCustomerParams{
 		ID:       customerID,
 		Name:     req.Name,
-		Phone:    req.Phone,
+		Phone:    phone,
 		Email:    email,
 		Notes:    notes,
 		OutletID: outletID,
@@ -489,3 +596,289 @@ func (h *CustomerHandler) Orders(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, resp)
 }
+
+// Points returns a customer's loyalty balance, tier and points history,
+// newest first. The balance leaves out points past their expiry; they are
+// written off in the history at the customer's next redemption.
+func (h *CustomerHandler) Points(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid customer ID"})
+		return
+	}
+
+	// Verify customer exists and belongs to outlet
+	_, err = h.store.GetCustomer(r.Context(), database.GetCustomerParams{
+		ID:       customerID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "customer not found"})
+			return
+		}
+		log.Printf("ERROR: get customer for points: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Parse pagination
+	limit := 20
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	offset := 0
+	if s := r.URL.Query().Get("offset"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+
+	loyalty, err := h.store.GetCustomerLoyalty(r.Context(), customerID)
+	if err != nil {
+		log.Printf("ERROR: get customer loyalty: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	program, err := service.LoadLoyaltyProgram(r.Context(), h.store, outletID)
+	if err != nil {
+		log.Printf("ERROR: load loyalty program: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	txns, err := h.store.ListCustomerLoyaltyTransactions(r.Context(), database.ListCustomerLoyaltyTransactionsParams{
+		CustomerID: customerID,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		log.Printf("ERROR: list customer loyalty transactions: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	lifetimeSpend, err := numericToDecimal(loyalty.LifetimeSpend)
+	if err != nil {
+		log.Printf("ERROR: parse lifetime spend: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	resp := customerPointsResponse{
+		PointsBalance: loyalty.PointsBalance,
+		PointsValue:   "0.00",
+		Tier:          enum.LoyaltyTierMember,
+		LifetimeSpend: numericToString(loyalty.LifetimeSpend),
+		Transactions:  make([]loyaltyTransactionResponse, len(txns)),
+	}
+	if program != nil {
+		resp.Tier = program.Tier(lifetimeSpend)
+		resp.PointsValue = program.PointValue.Mul(decimal.NewFromInt32(loyalty.PointsBalance)).StringFixed(2)
+	}
+	for i, t := range txns {
+		resp.Transactions[i] = toLoyaltyTransactionResponse(t)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Duplicates lists groups of active customers whose phone numbers are the
+// same once normalized, typically typed in different formats before phones
+// were normalized. Each group is a candidate for Merge.
+func (h *CustomerHandler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	rows, err := h.store.ListDuplicateCustomerCandidates(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list duplicate customers: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := []duplicateCustomerGroupResponse{}
+	for _, row := range rows {
+		if len(resp) == 0 || resp[len(resp)-1].NormalizedPhone != row.NormalizedPhone {
+			resp = append(resp, duplicateCustomerGroupResponse{NormalizedPhone: row.NormalizedPhone})
+		}
+		c := duplicateCustomerResponse{
+			ID:         row.ID,
+			Name:       row.Name,
+			Phone:      row.Phone,
+			OrderCount: row.OrderCount,
+			CreatedAt:  row.CreatedAt,
+		}
+		if row.Email.Valid {
+			c.Email = &row.Email.String
+		}
+		group := &resp[len(resp)-1]
+		group.Customers = append(group.Customers, c)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Merge folds the source customer into the customer in the path, in one
+// transaction: orders (and so stats) and loyalty points move over, notes
+// are combined, a missing email is taken from the source, and the source is
+// deactivated with a pointer to the survivor. The survivor keeps its name
+// and ends with a normalized phone.
+func (h *CustomerHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	targetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid customer ID"})
+		return
+	}
+
+	var req mergeCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	sourceID, err := uuid.Parse(req.SourceID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid source_id"})
+		return
+	}
+	if sourceID == targetID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cannot merge a customer into itself"})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for customer merge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	// Lock both customers in ID order so concurrent merges cannot deadlock
+	locked := make(map[uuid.UUID]database.Customer, 2)
+	ids := []uuid.UUID{targetID, sourceID}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	for _, id := range ids {
+		c, err := txStore.GetCustomerForUpdate(r.Context(), database.GetCustomerForUpdateParams{
+			ID:       id,
+			OutletID: outletID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "customer not found"})
+				return
+			}
+			log.Printf("ERROR: lock customer for merge: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		locked[id] = c
+	}
+	target, source := locked[targetID], locked[sourceID]
+
+	ordersMoved, err := txStore.ReassignCustomerOrders(r.Context(), database.ReassignCustomerOrdersParams{
+		TargetID: targetID,
+		SourceID: sourceID,
+	})
+	if err != nil {
+		log.Printf("ERROR: reassign customer orders: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	pointsMoved, err := txStore.ReassignCustomerLoyaltyTransactions(r.Context(), database.ReassignCustomerLoyaltyTransactionsParams{
+		TargetID: targetID,
+		SourceID: sourceID,
+	})
+	if err != nil {
+		log.Printf("ERROR: reassign customer loyalty transactions: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Deactivate the source first so the survivor can take the normalized
+	// phone if only the source had it
+	if _, err := txStore.MarkCustomerMerged(r.Context(), database.MarkCustomerMergedParams{
+		ID:           sourceID,
+		MergedIntoID: pgtype.UUID{Bytes: targetID, Valid: true},
+	}); err != nil {
+		log.Printf("ERROR: mark customer merged: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	phone := target.Phone
+	if p, err := normalizePhone(target.Phone); err == nil {
+		phone = p
+	}
+	email := target.Email
+	if !email.Valid {
+		email = source.Email
+	}
+	customer, err := txStore.UpdateCustomer(r.Context(), database.UpdateCustomerParams{
+		ID:       targetID,
+		Name:     target.Name,
+		Phone:    phone,
+		Email:    email,
+		Notes:    mergeCustomerNotes(target.Notes, source.Notes),
+		OutletID: outletID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "phone already exists for this outlet"})
+			return
+		}
+		log.Printf("ERROR: update merged customer: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for customer merge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mergeCustomerResponse{
+		Customer:                 toCustomerResponse(customer),
+		OrdersMoved:              ordersMoved,
+		LoyaltyTransactionsMoved: pointsMoved,
+	})
+}
+
+// mergeCustomerNotes appends the merged customer's notes to the survivor's,
+// skipping them when they add nothing.
+func mergeCustomerNotes(target, source pgtype.Text) pgtype.Text {
+	src := strings.TrimSpace(source.String)
+	if !source.Valid || src == "" || strings.Contains(target.String, src) {
+		return target
+	}
+	if !target.Valid || strings.TrimSpace(target.String) == "" {
+		return pgtype.Text{String: src, Valid: true}
+	}
+	return pgtype.Text{String: target.String + "\n" + src, Valid: true}
+}
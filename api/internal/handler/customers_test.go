@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -18,11 +20,13 @@ import (
 	"github.com/kiwari-pos/api/internal/database"
 	"github.com/kiwari-pos/api/internal/enum"
 	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/shopspring/decimal"
 )
 
 // --- Mock store ---
 
 type mockCustomerStore struct {
+	mockLoyaltyStore
 	customers map[uuid.UUID]database.Customer // keyed by customer ID
 	orders    map[uuid.UUID]database.Order    // keyed by order ID
 }
@@ -159,12 +163,133 @@ func (m *mockCustomerStore) ListCustomerOrders(_ context.Context, arg database.L
 	return result, nil
 }
 
+func (m *mockCustomerStore) ListCustomerRFM(_ context.Context, arg database.ListCustomerRFMParams) ([]database.ListCustomerRFMRow, error) {
+	byCustomer := make(map[uuid.UUID]*database.ListCustomerRFMRow)
+	spend := make(map[uuid.UUID]decimal.Decimal)
+	for _, o := range m.orders {
+		c, ok := m.customers[o.CustomerID.Bytes]
+		if !o.CustomerID.Valid || !ok || !c.IsActive || c.OutletID != arg.OutletID || o.Status == enum.OrderStatusCancelled {
+			continue
+		}
+		row, ok := byCustomer[c.ID]
+		if !ok {
+			row = &database.ListCustomerRFMRow{ID: c.ID, Name: c.Name, Phone: c.Phone}
+			byCustomer[c.ID] = row
+		}
+		if !row.FirstOrderAt.Valid || o.CreatedAt.Before(row.FirstOrderAt.Time) {
+			row.FirstOrderAt = pgtype.Timestamptz{Time: o.CreatedAt, Valid: true}
+		}
+		if !row.LastOrderAt.Valid || o.CreatedAt.After(row.LastOrderAt.Time) {
+			row.LastOrderAt = pgtype.Timestamptz{Time: o.CreatedAt, Valid: true}
+		}
+		if !o.CreatedAt.Before(arg.WindowStart) {
+			row.OrderCount++
+			amount, _ := numericToDecimal(o.TotalAmount)
+			spend[c.ID] = spend[c.ID].Add(amount)
+		}
+	}
+	result := []database.ListCustomerRFMRow{}
+	for id, row := range byCustomer {
+		row.TotalSpend = decimalToNumeric(spend[id])
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockCustomerStore) ListDuplicateCustomerCandidates(_ context.Context, outletID uuid.UUID) ([]database.ListDuplicateCustomerCandidatesRow, error) {
+	byPhone := make(map[string][]database.ListDuplicateCustomerCandidatesRow)
+	for _, c := range m.customers {
+		if c.OutletID != outletID || !c.IsActive {
+			continue
+		}
+		normalized := normalizeTestPhone(c.Phone)
+		byPhone[normalized] = append(byPhone[normalized], database.ListDuplicateCustomerCandidatesRow{
+			NormalizedPhone: normalized,
+			ID:              c.ID,
+			Name:            c.Name,
+			Phone:           c.Phone,
+			Email:           c.Email,
+			CreatedAt:       c.CreatedAt,
+		})
+	}
+	result := []database.ListDuplicateCustomerCandidatesRow{}
+	for _, rows := range byPhone {
+		if len(rows) > 1 {
+			result = append(result, rows...)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NormalizedPhone != result[j].NormalizedPhone {
+			return result[i].NormalizedPhone < result[j].NormalizedPhone
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (m *mockCustomerStore) GetCustomerForUpdate(ctx context.Context, arg database.GetCustomerForUpdateParams) (database.Customer, error) {
+	return m.GetCustomer(ctx, database.GetCustomerParams{ID: arg.ID, OutletID: arg.OutletID})
+}
+
+func (m *mockCustomerStore) ReassignCustomerOrders(_ context.Context, arg database.ReassignCustomerOrdersParams) (int64, error) {
+	var moved int64
+	for id, o := range m.orders {
+		if o.CustomerID.Valid && uuid.UUID(o.CustomerID.Bytes) == arg.SourceID {
+			o.CustomerID = pgtype.UUID{Bytes: arg.TargetID, Valid: true}
+			m.orders[id] = o
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func (m *mockCustomerStore) ReassignCustomerLoyaltyTransactions(_ context.Context, arg database.ReassignCustomerLoyaltyTransactionsParams) (int64, error) {
+	var moved int64
+	for i, t := range m.loyaltyTxns {
+		if t.CustomerID == arg.SourceID {
+			m.loyaltyTxns[i].CustomerID = arg.TargetID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func (m *mockCustomerStore) MarkCustomerMerged(_ context.Context, arg database.MarkCustomerMergedParams) (uuid.UUID, error) {
+	c, ok := m.customers[arg.ID]
+	if !ok || !c.IsActive {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	c.IsActive = false
+	c.MergedIntoID = arg.MergedIntoID
+	m.customers[c.ID] = c
+	return c.ID, nil
+}
+
+// normalizeTestPhone stands in for the database's normalize_phone for the
+// formats these tests use.
+func normalizeTestPhone(phone string) string {
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		return phone
+	case strings.HasPrefix(phone, "0"):
+		return "+62" + phone[1:]
+	}
+	return "+" + phone
+}
+
 // --- Helpers ---
 
 func setupCustomerRouter(store *mockCustomerStore) *chi.Mux {
-	h := handler.NewCustomerHandler(store)
+	newStore := func(db database.DBTX) handler.CustomerStore {
+		return store
+	}
+	h := handler.NewCustomerHandler(store, &mockPool{}, newStore)
 	r := chi.NewRouter()
-	r.Route("/outlets/{oid}/customers", h.RegisterRoutes)
+	r.Route("/outlets/{oid}/customers", func(r chi.Router) {
+		h.RegisterRoutes(r)
+		h.RegisterManageRoutes(r)
+	})
 	return r
 }
 
@@ -199,7 +324,7 @@ func TestCustomerList(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -208,7 +333,7 @@ func TestCustomerList(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "Jane Smith",
-		Phone:     "089876543210",
+		Phone:     "+6289876543210",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -240,7 +365,7 @@ func TestCustomerListWithSearch(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -249,7 +374,7 @@ func TestCustomerListWithSearch(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "Jane Smith",
-		Phone:     "089876543210",
+		Phone:     "+6289876543210",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -282,7 +407,7 @@ func TestCustomerListWithNameSearch(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -291,7 +416,7 @@ func TestCustomerListWithNameSearch(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "Jane Smith",
-		Phone:     "089876543210",
+		Phone:     "+6289876543210",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -356,7 +481,7 @@ func TestCustomerGet(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "Test Customer",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		Email:     pgtype.Text{String: "test@example.com", Valid: true},
 		IsActive:  true,
 		CreatedAt: time.Now(),
@@ -376,8 +501,8 @@ func TestCustomerGet(t *testing.T) {
 	if resp["name"] != "Test Customer" {
 		t.Errorf("name: got %v, want Test Customer", resp["name"])
 	}
-	if resp["phone"] != "081234567890" {
-		t.Errorf("phone: got %v, want 081234567890", resp["phone"])
+	if resp["phone"] != "+6281234567890" {
+		t.Errorf("phone: got %v, want +6281234567890", resp["phone"])
 	}
 	if resp["email"] != "test@example.com" {
 		t.Errorf("email: got %v, want test@example.com", resp["email"])
@@ -425,8 +550,8 @@ func TestCustomerCreate(t *testing.T) {
 	if resp["name"] != "John Doe" {
 		t.Errorf("expected name 'John Doe', got %v", resp["name"])
 	}
-	if resp["phone"] != "081234567890" {
-		t.Errorf("expected phone '081234567890', got %v", resp["phone"])
+	if resp["phone"] != "+6281234567890" {
+		t.Errorf("expected phone normalized to '+6281234567890', got %v", resp["phone"])
 	}
 }
 
@@ -525,7 +650,7 @@ func TestCustomerCreateDuplicatePhone(t *testing.T) {
 		ID:        uuid.New(),
 		OutletID:  outletID,
 		Name:      "Existing Customer",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -566,7 +691,7 @@ func TestCustomerUpdate(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "Old Name",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -592,8 +717,8 @@ func TestCustomerUpdate(t *testing.T) {
 	if resp["name"] != "New Name" {
 		t.Errorf("expected name 'New Name', got %v", resp["name"])
 	}
-	if resp["phone"] != "089999999999" {
-		t.Errorf("expected phone '089999999999', got %v", resp["phone"])
+	if resp["phone"] != "+6289999999999" {
+		t.Errorf("expected phone normalized to '+6289999999999', got %v", resp["phone"])
 	}
 }
 
@@ -631,7 +756,7 @@ func TestCustomerUpdateMissingName(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "Old Name",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -665,7 +790,7 @@ func TestCustomerUpdateDuplicatePhone(t *testing.T) {
 		ID:        customerID1,
 		OutletID:  outletID,
 		Name:      "Customer 1",
-		Phone:     "081111111111",
+		Phone:     "+6281111111111",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -674,7 +799,7 @@ func TestCustomerUpdateDuplicatePhone(t *testing.T) {
 		ID:        customerID2,
 		OutletID:  outletID,
 		Name:      "Customer 2",
-		Phone:     "082222222222",
+		Phone:     "+6282222222222",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -710,7 +835,7 @@ func TestCustomerDelete(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -758,7 +883,7 @@ func TestCustomerStatsWithOrders(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -804,7 +929,7 @@ func TestCustomerStatsNoOrders(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -852,7 +977,7 @@ func TestCustomerOrderHistory(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -902,7 +1027,7 @@ func TestCustomerOrderHistoryEmpty(t *testing.T) {
 		ID:        customerID,
 		OutletID:  outletID,
 		Name:      "John Doe",
-		Phone:     "081234567890",
+		Phone:     "+6281234567890",
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -938,3 +1063,444 @@ func TestCustomerOrderHistoryNotFound(t *testing.T) {
 		t.Errorf("expected status 404, got %d", rr.Code)
 	}
 }
+
+func TestCustomerPoints(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	customerID := uuid.New()
+	store.customers[customerID] = database.Customer{
+		ID:        customerID,
+		OutletID:  outletID,
+		Name:      "John Doe",
+		Phone:     "+6281234567890",
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	store.enableLoyalty(outletID)
+	settings := store.loyaltySettings[outletID]
+	settings.SilverMinSpend = decimalToNumeric(decimal.NewFromInt(1000000))
+	store.loyaltySettings[outletID] = settings
+	store.lifetimeSpend = decimal.NewFromInt(1200000)
+	store.addPoints(customerID, 120)
+	store.addPoints(uuid.New(), 50) // another customer
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID.String()+"/customers/"+customerID.String()+"/points", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resp := decodeCustomerResponse(t, rr)
+	if resp["points_balance"] != float64(120) {
+		t.Errorf("points_balance: got %v, want 120", resp["points_balance"])
+	}
+	if resp["points_value"] != "12000.00" {
+		t.Errorf("points_value: got %v, want 12000.00", resp["points_value"])
+	}
+	if resp["tier"] != enum.LoyaltyTierSilver {
+		t.Errorf("tier: got %v, want %s", resp["tier"], enum.LoyaltyTierSilver)
+	}
+	txns, _ := resp["transactions"].([]interface{})
+	if len(txns) != 1 {
+		t.Errorf("transactions: got %d, want 1", len(txns))
+	}
+}
+
+func TestCustomerPointsNotFound(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+uuid.New().String()+"/customers/"+uuid.New().String()+"/points", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestCustomerCreateNormalizesPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string // "" when the phone is rejected
+	}{
+		{"0812-3456-7890", "+6281234567890"},
+		{"+62 812 3456 7890", "+6281234567890"},
+		{"6281234567890", "+6281234567890"},
+		{"81234567890", "+6281234567890"},
+		{"+62 0812 3456 7890", "+6281234567890"},
+		{"(021) 555-1234", "+62215551234"},
+		{"+65 9123 4567", "+6591234567"},
+		{"0812", ""},
+		{"12345678", ""},
+		{"0812abc4567", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			store := newMockCustomerStore()
+			router := setupCustomerRouter(store)
+
+			bodyJSON, _ := json.Marshal(map[string]interface{}{"name": "John Doe", "phone": tt.phone})
+			req := httptest.NewRequest(http.MethodPost, "/outlets/"+uuid.New().String()+"/customers", bytes.NewReader(bodyJSON))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if tt.want == "" {
+				if rr.Code != http.StatusBadRequest {
+					t.Errorf("expected status 400, got %d", rr.Code)
+				}
+				return
+			}
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if resp := decodeCustomerResponse(t, rr); resp["phone"] != tt.want {
+				t.Errorf("phone: got %v, want %s", resp["phone"], tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomerDuplicates(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	for i, phone := range []string{"+6281234567890", "081234567890", "+6289876543210"} {
+		c := database.Customer{
+			ID:        uuid.New(),
+			OutletID:  outletID,
+			Name:      "Customer " + strconv.Itoa(i),
+			Phone:     phone,
+			IsActive:  true,
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Minute),
+			UpdatedAt: time.Now(),
+		}
+		store.customers[c.ID] = c
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID.String()+"/customers/duplicates", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	resp := decodeCustomerListResponse(t, rr)
+	if len(resp) != 1 {
+		t.Fatalf("expected 1 duplicate group, got %d", len(resp))
+	}
+	if resp[0]["normalized_phone"] != "+6281234567890" {
+		t.Errorf("normalized_phone: got %v, want +6281234567890", resp[0]["normalized_phone"])
+	}
+	if customers, _ := resp[0]["customers"].([]interface{}); len(customers) != 2 {
+		t.Errorf("expected 2 customers in the group, got %d", len(customers))
+	}
+}
+
+func TestCustomerMerge(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	survivor := database.Customer{
+		ID:        uuid.New(),
+		OutletID:  outletID,
+		Name:      "John Doe",
+		Phone:     "081234567890", // typed before phones were normalized
+		Notes:     pgtype.Text{String: "Likes it spicy", Valid: true},
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	duplicate := database.Customer{
+		ID:        uuid.New(),
+		OutletID:  outletID,
+		Name:      "John",
+		Phone:     "+6281234567890",
+		Email:     pgtype.Text{String: "john@example.com", Valid: true},
+		Notes:     pgtype.Text{String: "No peanuts", Valid: true},
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	store.customers[survivor.ID] = survivor
+	store.customers[duplicate.ID] = duplicate
+	for range 2 {
+		o := database.Order{
+			ID:         uuid.New(),
+			OutletID:   outletID,
+			CustomerID: pgtype.UUID{Bytes: duplicate.ID, Valid: true},
+			Status:     enum.OrderStatusCompleted,
+		}
+		store.orders[o.ID] = o
+	}
+	store.addPoints(duplicate.ID, 40)
+
+	bodyJSON, _ := json.Marshal(map[string]interface{}{"source_id": duplicate.ID.String()})
+	req := httptest.NewRequest(http.MethodPost, "/outlets/"+outletID.String()+"/customers/"+survivor.ID.String()+"/merge", bytes.NewReader(bodyJSON))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	resp := decodeCustomerResponse(t, rr)
+	if resp["orders_moved"] != float64(2) {
+		t.Errorf("orders_moved: got %v, want 2", resp["orders_moved"])
+	}
+	customer := resp["customer"].(map[string]interface{})
+	if customer["phone"] != "+6281234567890" {
+		t.Errorf("phone: got %v, want +6281234567890", customer["phone"])
+	}
+	if customer["email"] != "john@example.com" {
+		t.Errorf("email: got %v, want the duplicate's email", customer["email"])
+	}
+	if customer["notes"] != "Likes it spicy\nNo peanuts" {
+		t.Errorf("notes: got %q, want both customers' notes", customer["notes"])
+	}
+
+	merged := store.customers[duplicate.ID]
+	if merged.IsActive || uuid.UUID(merged.MergedIntoID.Bytes) != survivor.ID {
+		t.Errorf("expected the duplicate deactivated and merged into the survivor, got %+v", merged)
+	}
+	if got := store.pointsBalance(survivor.ID); got != 40 {
+		t.Errorf("survivor points: got %d, want 40", got)
+	}
+}
+
+func TestCustomerMergeInvalid(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	customer := database.Customer{
+		ID:        uuid.New(),
+		OutletID:  outletID,
+		Name:      "John Doe",
+		Phone:     "+6281234567890",
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	store.customers[customer.ID] = customer
+
+	tests := []struct {
+		name     string
+		sourceID string
+		want     int
+	}{
+		{"into itself", customer.ID.String(), http.StatusBadRequest},
+		{"invalid source", "not-a-uuid", http.StatusBadRequest},
+		{"unknown source", uuid.New().String(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyJSON, _ := json.Marshal(map[string]interface{}{"source_id": tt.sourceID})
+			req := httptest.NewRequest(http.MethodPost, "/outlets/"+outletID.String()+"/customers/"+customer.ID.String()+"/merge", bytes.NewReader(bodyJSON))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+	if !store.customers[customer.ID].IsActive {
+		t.Error("expected the customer to stay active")
+	}
+}
+
+func TestCustomerSegments(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	now := time.Now()
+	day := 24 * time.Hour
+	// Each customer's orders as (days ago, amount)
+	customers := []struct {
+		name   string
+		orders [][2]int
+		want   string
+	}{
+		{"Ani", [][2]int{{120, 50000}, {30, 50000}, {10, 80000}, {2, 90000}}, "champions"},
+		{"Budi", [][2]int{{5, 40000}}, "new"},
+		{"Citra", [][2]int{{200, 30000}, {80, 30000}}, "at_risk"},
+		{"Dewi", [][2]int{{150, 30000}, {40, 30000}}, "regular"},
+		{"Eko", [][2]int{{300, 100000}, {120, 25000}}, "lapsed"},
+	}
+	for i, tc := range customers {
+		c := database.Customer{
+			ID:        uuid.New(),
+			OutletID:  outletID,
+			Name:      tc.name,
+			Phone:     "+62812000000" + strconv.Itoa(10+i),
+			IsActive:  true,
+			CreatedAt: now.Add(-365 * day),
+			UpdatedAt: now,
+		}
+		store.customers[c.ID] = c
+		for _, o := range tc.orders {
+			order := database.Order{
+				ID:          uuid.New(),
+				OutletID:    outletID,
+				CustomerID:  pgtype.UUID{Bytes: c.ID, Valid: true},
+				Status:      enum.OrderStatusCompleted,
+				TotalAmount: decimalToNumeric(decimal.NewFromInt(int64(o[1]))),
+				CreatedAt:   now.Add(-time.Duration(o[0]) * day),
+			}
+			store.orders[order.ID] = order
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID.String()+"/customers/segments?days=90", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Counts    map[string]int `json:"counts"`
+		Customers []struct {
+			Name     string `json:"name"`
+			Segment  string `json:"segment"`
+			Monetary string `json:"monetary"`
+		} `json:"customers"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Customers) != len(customers) {
+		t.Fatalf("expected %d customers, got %d", len(customers), len(resp.Customers))
+	}
+	for i, tc := range customers {
+		if got := resp.Customers[i]; got.Name != tc.name || got.Segment != tc.want {
+			t.Errorf("customer %d: got %s in %s, want %s in %s", i, got.Name, got.Segment, tc.name, tc.want)
+		}
+		if resp.Counts[tc.want] != 1 {
+			t.Errorf("counts[%s]: got %d, want 1", tc.want, resp.Counts[tc.want])
+		}
+	}
+	// Only orders within the window count towards spend
+	if resp.Customers[0].Monetary != "220000.00" {
+		t.Errorf("monetary: got %s, want 220000.00", resp.Customers[0].Monetary)
+	}
+}
+
+func TestCustomerSegmentsCSV(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	now := time.Now()
+	for i, phone := range []string{"+6281234567890", "081298765432"} {
+		c := database.Customer{
+			ID:        uuid.New(),
+			OutletID:  outletID,
+			Name:      "Customer " + strconv.Itoa(i),
+			Phone:     phone,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		store.customers[c.ID] = c
+		order := database.Order{
+			ID:          uuid.New(),
+			OutletID:    outletID,
+			CustomerID:  pgtype.UUID{Bytes: c.ID, Valid: true},
+			Status:      enum.OrderStatusCompleted,
+			TotalAmount: decimalToNumeric(decimal.NewFromInt(50000)),
+			CreatedAt:   now.AddDate(0, 0, -200+i*190),
+		}
+		store.orders[order.ID] = order
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID.String()+"/customers/segments?segment=lapsed&format=csv", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type: got %s, want text/csv", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, "customers-lapsed-") {
+		t.Errorf("Content-Disposition: got %s", cd)
+	}
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %q", rr.Body.String())
+	}
+	if !strings.HasPrefix(lines[1], "Customer 0,6281234567890,lapsed,") {
+		t.Errorf("row: got %q", lines[1])
+	}
+}
+
+// Cells a spreadsheet would run as a formula are exported as text.
+func TestCustomerSegmentsCSV_EscapesFormulas(t *testing.T) {
+	store := newMockCustomerStore()
+	router := setupCustomerRouter(store)
+
+	outletID := uuid.New()
+	now := time.Now()
+	c := database.Customer{
+		ID:        uuid.New(),
+		OutletID:  outletID,
+		Name:      "=HYPERLINK(A1)",
+		Phone:     "-1234",
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	store.customers[c.ID] = c
+	order := database.Order{
+		ID:          uuid.New(),
+		OutletID:    outletID,
+		CustomerID:  pgtype.UUID{Bytes: c.ID, Valid: true},
+		Status:      enum.OrderStatusCompleted,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(50000)),
+		CreatedAt:   now.AddDate(0, 0, -200),
+	}
+	store.orders[order.ID] = order
+
+	req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID.String()+"/customers/segments?format=csv", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %q", rr.Body.String())
+	}
+	if !strings.HasPrefix(lines[1], "'=HYPERLINK(A1),'-1234,") {
+		t.Errorf("row: got %q", lines[1])
+	}
+}
+
+func TestCustomerSegmentsInvalidParams(t *testing.T) {
+	router := setupCustomerRouter(newMockCustomerStore())
+	outletID := uuid.New().String()
+
+	for _, query := range []string{"days=0", "days=abc", "days=1000", "segment=vip", "format=xlsx"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/outlets/"+outletID+"/customers/segments?"+query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rr.Code)
+			}
+		})
+	}
+}
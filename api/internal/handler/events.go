@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// Broadcaster publishes real-time events to clients subscribed to an outlet.
+// Satisfied by *ws.Hub; narrow interface for testability.
+type Broadcaster interface {
+	BroadcastToOutlet(outletID uuid.UUID, event ws.Event)
+}
+
+// orderDetailStore defines the read methods needed to assemble a full order.
+// Satisfied by both OrderStore and PaymentStore.
+type orderDetailStore interface {
+	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error)
+	ListOrderItemModifiersByOrderItem(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error)
+	ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.Payment, error)
+}
+
+// orderEventPayload is the payload of every order lifecycle event.
+// Item, Payment and Acknowledgement identify what changed (Late says why an item was flagged); Order is the full state after the change.
+type orderEventPayload struct {
+	Order           orderDetailResponse      `json:"order"`
+	Item            *orderItemResponse       `json:"item,omitempty"`
+	RemovedItemID   *uuid.UUID               `json:"removed_item_id,omitempty"`
+	Payment         *paymentResponse         `json:"payment,omitempty"`
+	Acknowledgement *acknowledgementResponse `json:"acknowledgement,omitempty"`
+	Late            *lateItemResponse        `json:"late,omitempty"`
+
+	// stations routes the event when the touched items are no longer in the
+	// order (removals) or only some stations are involved (acknowledgements);
+	// otherwise they are derived from Item or Order.
+	stations []string
+}
+
+// loadOrderDetail fetches an order with its items, modifiers, and payments.
+func loadOrderDetail(ctx context.Context, store orderDetailStore, outletID, orderID uuid.UUID) (orderDetailResponse, error) {
+	order, err := store.GetOrder(ctx, database.GetOrderParams{
+		ID:       orderID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		return orderDetailResponse{}, fmt.Errorf("get order: %w", err)
+	}
+
+	items, err := store.ListOrderItemsByOrder(ctx, orderID)
+	if err != nil {
+		return orderDetailResponse{}, fmt.Errorf("list order items: %w", err)
+	}
+
+	itemResponses := make([]orderItemResponse, len(items))
+	for i, item := range items {
+		mods, err := store.ListOrderItemModifiersByOrderItem(ctx, item.ID)
+		if err != nil {
+			return orderDetailResponse{}, fmt.Errorf("list order item modifiers: %w", err)
+		}
+		itemResponses[i] = dbOrderItemToResponse(item, mods)
+	}
+
+	payments, err := store.ListPaymentsByOrder(ctx, orderID)
+	if err != nil {
+		return orderDetailResponse{}, fmt.Errorf("list payments: %w", err)
+	}
+
+	paymentResps := make([]paymentResponse, len(payments))
+	for i, p := range payments {
+		paymentResps[i] = dbPaymentToResponse(p)
+	}
+
+	orderResp := dbOrderToResponse(order)
+	orderResp.Items = itemResponses
+
+	return orderDetailResponse{
+		orderResponse: orderResp,
+		Payments:      paymentResps,
+	}, nil
+}
+
+// publishOrderEvent broadcasts an order lifecycle event to the outlet room.
+// Must only be called after the change has been committed: the order is
+// re-read from the pool so subscribers never see uncommitted state.
+// Failures are logged and never affect the HTTP response.
+func publishOrderEvent(ctx context.Context, hub Broadcaster, store orderDetailStore, eventType string, outletID, orderID uuid.UUID, payload orderEventPayload) {
+	if hub == nil {
+		return
+	}
+
+	detail, err := loadOrderDetail(ctx, store, outletID, orderID)
+	if err != nil {
+		log.Printf("ERROR: load order for %s event: %v", eventType, err)
+		return
+	}
+	payload.Order = detail
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("ERROR: marshal %s event: %v", eventType, err)
+		return
+	}
+
+	hub.BroadcastToOutlet(outletID, ws.Event{
+		Type:     eventType,
+		Payload:  data,
+		Stations: eventStations(payload),
+	})
+}
+
+// eventStations returns the kitchen stations an event touches: the changed
+// item and its combo components for item events, every item's station for
+// order-level events.
+func eventStations(payload orderEventPayload) []string {
+	if payload.stations != nil {
+		return payload.stations
+	}
+
+	var stations []string
+	seen := make(map[string]bool)
+	add := func(station *string) {
+		if station != nil && !seen[*station] {
+			seen[*station] = true
+			stations = append(stations, *station)
+		}
+	}
+
+	if payload.Item != nil {
+		add(payload.Item.Station)
+		itemID := payload.Item.ID.String()
+		for _, item := range payload.Order.Items {
+			if item.ParentItemID != nil && *item.ParentItemID == itemID {
+				add(item.Station)
+			}
+		}
+		return stations
+	}
+
+	for _, item := range payload.Order.Items {
+		add(item.Station)
+	}
+	return stations
+}
@@ -0,0 +1,462 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock Broadcaster ---
+
+type broadcastedEvent struct {
+	outletID uuid.UUID
+	event    ws.Event
+}
+
+type mockBroadcaster struct {
+	mu     sync.Mutex
+	events []broadcastedEvent
+}
+
+func (m *mockBroadcaster) BroadcastToOutlet(outletID uuid.UUID, event ws.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, broadcastedEvent{outletID: outletID, event: event})
+}
+
+func (m *mockBroadcaster) types() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	types := make([]string, len(m.events))
+	for i, e := range m.events {
+		types[i] = e.event.Type
+	}
+	return types
+}
+
+func setupOrderRouterWithHub(svc *mockOrderService, store *mockOrderStore, hub handler.Broadcaster) *chi.Mux {
+	h := handler.NewOrderHandler(svc, store, &mockPool{}, mockNewStore(store), hub)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/orders", h.RegisterRoutes)
+	return r
+}
+
+func decodeEventPayload(t *testing.T, e ws.Event) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal event payload: %v", err)
+	}
+	return payload
+}
+
+// --- Tests ---
+
+func TestOrderEvents_CreatePublishesFullOrder(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	result := testOrderResult(outletID, claims.UserID)
+
+	svc := &mockOrderService{
+		createFn: func(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error) {
+			return result, nil
+		},
+	}
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return result.Order, nil
+		},
+		listOrderItemsByOrderFn: func(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+			return []database.OrderItem{result.Items[0].Item}, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(svc, store, hub)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders", map[string]interface{}{
+		"order_type": "DINE_IN",
+		"items": []map[string]interface{}{
+			{"product_id": uuid.New().String(), "quantity": 2},
+		},
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if len(hub.events) != 1 {
+		t.Fatalf("events: got %d, want 1", len(hub.events))
+	}
+	if hub.events[0].outletID != outletID {
+		t.Errorf("outlet: got %v, want %v", hub.events[0].outletID, outletID)
+	}
+	if hub.events[0].event.Type != ws.EventOrderCreated {
+		t.Errorf("type: got %s, want %s", hub.events[0].event.Type, ws.EventOrderCreated)
+	}
+
+	payload := decodeEventPayload(t, hub.events[0].event)
+	order, ok := payload["order"].(map[string]interface{})
+	if !ok {
+		t.Fatal("payload.order missing")
+	}
+	if order["id"] != result.Order.ID.String() {
+		t.Errorf("order id: got %v, want %v", order["id"], result.Order.ID)
+	}
+	items, _ := order["items"].([]interface{})
+	if len(items) != 1 {
+		t.Errorf("order items: got %d, want 1", len(items))
+	}
+	if _, ok := order["payments"].([]interface{}); !ok {
+		t.Error("order payments missing")
+	}
+}
+
+func TestOrderEvents_ServiceErrorPublishesNothing(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	svc := &mockOrderService{
+		createFn: func(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error) {
+			return nil, service.ErrProductNotFound
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(svc, &mockOrderStore{}, hub)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders", map[string]interface{}{
+		"order_type": "DINE_IN",
+		"items": []map[string]interface{}{
+			{"product_id": uuid.New().String(), "quantity": 1},
+		},
+	}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	if len(hub.events) != 0 {
+		t.Errorf("events: got %v, want none", hub.types())
+	}
+}
+
+func TestOrderEvents_UpdateStatus(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	updated := order
+	updated.Status = enum.OrderStatusPreparing
+
+	calls := 0
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			calls++
+			if calls == 1 {
+				return order, nil
+			}
+			return updated, nil
+		},
+		updateOrderStatusFn: func(ctx context.Context, arg database.UpdateOrderStatusParams) (database.Order, error) {
+			return updated, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(nil, store, hub)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/status",
+		map[string]string{"status": "PREPARING"}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderStatusChanged {
+		t.Fatalf("events: got %v, want [%s]", got, ws.EventOrderStatusChanged)
+	}
+	payload := decodeEventPayload(t, hub.events[0].event)
+	if payload["order"].(map[string]interface{})["status"] != "PREPARING" {
+		t.Errorf("payload status: got %v, want PREPARING", payload["order"].(map[string]interface{})["status"])
+	}
+}
+
+func TestOrderEvents_InvalidTransitionPublishesNothing(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(nil, store, hub)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/status",
+		map[string]string{"status": "COMPLETED"}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if len(hub.events) != 0 {
+		t.Errorf("events: got %v, want none", hub.types())
+	}
+}
+
+func TestOrderEvents_Cancel(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusCancelled)
+
+	store := &mockOrderStore{
+		cancelOrderFn: func(ctx context.Context, arg database.CancelOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(nil, store, hub)
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+order.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderCancelled {
+		t.Errorf("events: got %v, want [%s]", got, ws.EventOrderCancelled)
+	}
+}
+
+func TestOrderEvents_CommitFailurePublishesNothing(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	item := testDBOrderItem(order.ID)
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		countOrderItemsFn: func(ctx context.Context, orderID uuid.UUID) (int64, error) {
+			return 2, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return item, nil
+		},
+		deleteOrderItemFn: func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+			return nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	h := handler.NewOrderHandler(nil, store, &mockPool{
+		beginFn: func(ctx context.Context) (pgx.Tx, error) {
+			return &mockTx{commitFn: func(ctx context.Context) error { return context.DeadlineExceeded }}, nil
+		},
+	}, mockNewStore(store), hub)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/orders", h.RegisterRoutes)
+
+	rr := doAuthRequest(t, r, "DELETE",
+		"/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+item.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if len(hub.events) != 0 {
+		t.Errorf("events: got %v, want none", hub.types())
+	}
+}
+
+func TestOrderEvents_RemoveItem(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	item := testDBOrderItem(order.ID)
+	item.Station = pgtype.Text{String: enum.StationGrill, Valid: true}
+	drink := testDBOrderItem(order.ID)
+	drink.Station = pgtype.Text{String: enum.StationBeverage, Valid: true}
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		countOrderItemsFn: func(ctx context.Context, orderID uuid.UUID) (int64, error) {
+			return 2, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return item, nil
+		},
+		listOrderItemsByOrderFn: func(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+			return []database.OrderItem{item, drink}, nil
+		},
+		deleteOrderItemFn: func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+			return nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(nil, store, hub)
+	rr := doAuthRequest(t, router, "DELETE",
+		"/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+item.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderItemRemoved {
+		t.Fatalf("events: got %v, want [%s]", got, ws.EventOrderItemRemoved)
+	}
+	payload := decodeEventPayload(t, hub.events[0].event)
+	if payload["removed_item_id"] != item.ID.String() {
+		t.Errorf("removed_item_id: got %v, want %v", payload["removed_item_id"], item.ID)
+	}
+	// Routed by the removed item's station, which is no longer in the order
+	if got := hub.events[0].event.Stations; len(got) != 1 || got[0] != enum.StationGrill {
+		t.Errorf("stations: got %v, want [%s]", got, enum.StationGrill)
+	}
+}
+
+func TestOrderEvents_UpdateItemStatus(t *testing.T) {
+	outletID := uuid.New()
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "KITCHEN"}
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	item := testDBOrderItem(order.ID)
+	updated := item
+	updated.Status = enum.OrderItemStatusPreparing
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return item, nil
+		},
+		updateOrderItemStatusFn: func(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error) {
+			return updated, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	router := setupOrderRouterWithHub(nil, store, hub)
+	rr := doAuthRequest(t, router, "PATCH",
+		"/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+item.ID.String()+"/status",
+		map[string]string{"status": "PREPARING"}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderItemStatusChanged {
+		t.Fatalf("events: got %v, want [%s]", got, ws.EventOrderItemStatusChanged)
+	}
+	payload := decodeEventPayload(t, hub.events[0].event)
+	if payload["item"].(map[string]interface{})["status"] != "PREPARING" {
+		t.Errorf("item status: got %v, want PREPARING", payload["item"].(map[string]interface{})["status"])
+	}
+}
+
+func TestPaymentEvents_FullPaymentPublishesPaymentAndStatusChange(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := uuid.New()
+
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "KWR-001",
+		Status:      enum.OrderStatusReady,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(50000)),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	hub := &mockBroadcaster{}
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	h := handler.NewPaymentHandler(store, &mockPool{}, func(db database.DBTX) handler.PaymentStore { return store }, hub)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/orders/{id}/payments", h.RegisterRoutes)
+
+	rr := doAuthRequest(t, r, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "50000",
+		}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+
+	got := hub.types()
+	if len(got) != 2 || got[0] != ws.EventPaymentAdded || got[1] != ws.EventOrderStatusChanged {
+		t.Fatalf("events: got %v, want [%s %s]", got, ws.EventPaymentAdded, ws.EventOrderStatusChanged)
+	}
+
+	payload := decodeEventPayload(t, hub.events[0].event)
+	if payload["payment"].(map[string]interface{})["amount"] != "50000.00" {
+		t.Errorf("payment amount: got %v, want 50000.00", payload["payment"].(map[string]interface{})["amount"])
+	}
+	order := payload["order"].(map[string]interface{})
+	if order["status"] != "COMPLETED" {
+		t.Errorf("order status: got %v, want COMPLETED", order["status"])
+	}
+	if payments, _ := order["payments"].([]interface{}); len(payments) != 1 {
+		t.Errorf("order payments: got %d, want 1", len(payments))
+	}
+}
+
+func TestPaymentEvents_RejectedPaymentPublishesNothing(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := uuid.New()
+
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		Status:      enum.OrderStatusNew,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(50000)),
+	}
+	hub := &mockBroadcaster{}
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	h := handler.NewPaymentHandler(store, &mockPool{}, func(db database.DBTX) handler.PaymentStore { return store }, hub)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/orders/{id}/payments", h.RegisterRoutes)
+
+	rr := doAuthRequest(t, r, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "60000",
+		}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if len(hub.events) != 0 {
+		t.Errorf("events: got %v, want none", hub.types())
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a POST safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore defines the database methods needed to honour Idempotency-Key.
+// Satisfied by *database.Queries; embedded in the stores of handlers that use it.
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, arg database.ReserveIdempotencyKeyParams) (int64, error)
+	GetIdempotencyKey(ctx context.Context, arg database.GetIdempotencyKeyParams) (database.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, arg database.CompleteIdempotencyKeyParams) error
+	ReleaseIdempotencyKey(ctx context.Context, arg database.ReleaseIdempotencyKeyParams) error
+}
+
+// idempotent makes an outlet-scoped POST replay its first successful response
+// when retried with the same Idempotency-Key. Requests without the header pass
+// through unchanged. Reusing a key for a different request is rejected with 422,
+// and a retry that arrives while the original is still running gets 409.
+// Failed responses are not stored: every handler wrapped here runs in a single
+// transaction, so a failure left nothing behind and the key is freed for retry.
+func idempotent(store IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "idempotency key too long"})
+				return
+			}
+
+			outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// The hash covers the path too, so one key cannot be replayed
+			// against a different order's payments.
+			sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+			requestHash := hex.EncodeToString(sum[:])
+
+			reserved, err := store.ReserveIdempotencyKey(r.Context(), database.ReserveIdempotencyKeyParams{
+				OutletID:       outletID,
+				IdempotencyKey: key,
+				RequestHash:    requestHash,
+			})
+			if err != nil {
+				log.Printf("ERROR: reserve idempotency key: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+
+			if reserved == 0 {
+				existing, err := store.GetIdempotencyKey(r.Context(), database.GetIdempotencyKeyParams{
+					OutletID:       outletID,
+					IdempotencyKey: key,
+				})
+				if err != nil {
+					log.Printf("ERROR: get idempotency key: %v", err)
+					writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+					return
+				}
+				if existing.RequestHash != requestHash {
+					writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "idempotency key already used for a different request"})
+					return
+				}
+				if !existing.ResponseStatus.Valid {
+					writeJSON(w, http.StatusConflict, map[string]string{"error": "a request with this idempotency key is still in progress"})
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(int(existing.ResponseStatus.Int32))
+				io.WriteString(w, existing.ResponseBody.String)
+				return
+			}
+
+			// Finish the key even if the client has gone: the response may
+			// already be committed, and its retry must replay it. A panic
+			// still frees the key for a retry.
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{
+					OutletID:       outletID,
+					IdempotencyKey: key,
+				}); err != nil {
+					log.Printf("ERROR: release idempotency key: %v", err)
+				}
+			}()
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= 200 && rec.status < 300 {
+				completed = true
+				if err := store.CompleteIdempotencyKey(ctx, database.CompleteIdempotencyKeyParams{
+					OutletID:       outletID,
+					IdempotencyKey: key,
+					ResponseStatus: pgtype.Int4{Int32: int32(rec.status), Valid: true},
+					ResponseBody:   pgtype.Text{String: rec.body.String(), Valid: true},
+				}); err != nil {
+					log.Printf("ERROR: complete idempotency key: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+)
+
+// ctxIdempotencyStore holds a single key and, like the database, fails
+// calls made with a cancelled context.
+type ctxIdempotencyStore struct {
+	reserved  bool
+	completed bool
+	released  bool
+}
+
+func (s *ctxIdempotencyStore) ReserveIdempotencyKey(_ context.Context, _ database.ReserveIdempotencyKeyParams) (int64, error) {
+	s.reserved = true
+	return 1, nil
+}
+
+func (s *ctxIdempotencyStore) GetIdempotencyKey(_ context.Context, _ database.GetIdempotencyKeyParams) (database.IdempotencyKey, error) {
+	return database.IdempotencyKey{}, nil
+}
+
+func (s *ctxIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, _ database.CompleteIdempotencyKeyParams) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.completed = true
+	return nil
+}
+
+func (s *ctxIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, _ database.ReleaseIdempotencyKeyParams) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.released = true
+	return nil
+}
+
+func serveIdempotent(t *testing.T, store IdempotencyStore, next http.HandlerFunc, ctx context.Context) {
+	t.Helper()
+	r := chi.NewRouter()
+	r.With(idempotent(store)).Post("/outlets/{oid}/orders", next)
+
+	req := httptest.NewRequest(http.MethodPost, "/outlets/"+uuid.New().String()+"/orders", strings.NewReader("{}")).WithContext(ctx)
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestIdempotent_CompletesAfterClientDisconnects(t *testing.T) {
+	store := &ctxIdempotencyStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveIdempotent(t, store, func(w http.ResponseWriter, r *http.Request) {
+		// The order is committed, then the client goes away
+		writeJSON(w, http.StatusCreated, map[string]string{"id": "order"})
+		cancel()
+	}, ctx)
+
+	if !store.completed {
+		t.Error("expected the key to be completed so a retry replays the response")
+	}
+	if store.released {
+		t.Error("a completed key should not be released")
+	}
+}
+
+func TestIdempotent_PanicReleasesKey(t *testing.T) {
+	store := &ctxIdempotencyStore{}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to reach the caller")
+			}
+		}()
+		serveIdempotent(t, store, func(w http.ResponseWriter, r *http.Request) {
+			panic("handler bug")
+		}, context.Background())
+	}()
+
+	if !store.released || store.completed {
+		t.Errorf("key: released %v, completed %v; want released", store.released, store.completed)
+	}
+}
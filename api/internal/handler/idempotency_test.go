@@ -0,0 +1,282 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock IdempotencyStore ---
+
+// mockIdempotencyStore is embedded in the order and payment store mocks.
+// The zero value is ready to use.
+type mockIdempotencyStore struct {
+	keys map[string]database.IdempotencyKey // keyed by outlet ID + key
+}
+
+func idempotencyMapKey(outletID uuid.UUID, key string) string {
+	return outletID.String() + "/" + key
+}
+
+func (m *mockIdempotencyStore) ReserveIdempotencyKey(_ context.Context, arg database.ReserveIdempotencyKeyParams) (int64, error) {
+	if m.keys == nil {
+		m.keys = make(map[string]database.IdempotencyKey)
+	}
+	k := idempotencyMapKey(arg.OutletID, arg.IdempotencyKey)
+	if _, ok := m.keys[k]; ok {
+		return 0, nil
+	}
+	m.keys[k] = database.IdempotencyKey{
+		ID:             uuid.New(),
+		OutletID:       arg.OutletID,
+		IdempotencyKey: arg.IdempotencyKey,
+		RequestHash:    arg.RequestHash,
+		CreatedAt:      time.Now(),
+	}
+	return 1, nil
+}
+
+func (m *mockIdempotencyStore) GetIdempotencyKey(_ context.Context, arg database.GetIdempotencyKeyParams) (database.IdempotencyKey, error) {
+	ik, ok := m.keys[idempotencyMapKey(arg.OutletID, arg.IdempotencyKey)]
+	if !ok {
+		return database.IdempotencyKey{}, pgx.ErrNoRows
+	}
+	return ik, nil
+}
+
+func (m *mockIdempotencyStore) CompleteIdempotencyKey(_ context.Context, arg database.CompleteIdempotencyKeyParams) error {
+	k := idempotencyMapKey(arg.OutletID, arg.IdempotencyKey)
+	ik, ok := m.keys[k]
+	if !ok {
+		return nil
+	}
+	ik.ResponseStatus = arg.ResponseStatus
+	ik.ResponseBody = arg.ResponseBody
+	m.keys[k] = ik
+	return nil
+}
+
+func (m *mockIdempotencyStore) ReleaseIdempotencyKey(_ context.Context, arg database.ReleaseIdempotencyKeyParams) error {
+	k := idempotencyMapKey(arg.OutletID, arg.IdempotencyKey)
+	if ik, ok := m.keys[k]; ok && !ik.ResponseStatus.Valid {
+		delete(m.keys, k)
+	}
+	return nil
+}
+
+// doIdempotentRequest is doAuthRequest with an Idempotency-Key header.
+func doIdempotentRequest(t *testing.T, router http.Handler, path string, body interface{}, claims *auth.Claims, key string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token, err := auth.GenerateToken(testJWTSecret, claims.UserID, claims.OutletID, claims.Role)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Idempotency-Key", key)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// seedPayableOrder adds a NEW order of 100000 to the payment store.
+func seedPayableOrder(store *mockPaymentStore, outletID uuid.UUID) uuid.UUID {
+	orderID := uuid.New()
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-IDEM",
+		Status:      enum.OrderStatusNew,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(100000)),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	return orderID
+}
+
+// --- Tests ---
+
+func TestAddPayment_IdempotentRetryReplaysResponse(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := seedPayableOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+	body := map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "50000",
+		"amount_received": "50000",
+	}
+
+	first := doIdempotentRequest(t, router, path, body, claims, "retry-1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status: got %d, want %d; body: %s", first.Code, http.StatusCreated, first.Body.String())
+	}
+
+	second := doIdempotentRequest(t, router, path, body, claims, "retry-1")
+	if second.Code != http.StatusCreated {
+		t.Fatalf("retry status: got %d, want %d; body: %s", second.Code, http.StatusCreated, second.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry should be marked as replayed")
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("retry body differs:\nfirst:  %s\nretry: %s", first.Body.String(), second.Body.String())
+	}
+	if len(store.payments) != 1 {
+		t.Errorf("payments: got %d, want 1", len(store.payments))
+	}
+}
+
+func TestAddPayment_IdempotencyKeyReusedWithDifferentPayload(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := seedPayableOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+	rr := doIdempotentRequest(t, router, path, map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "50000",
+		"amount_received": "50000",
+	}, claims, "retry-2")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("first status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+
+	rr = doIdempotentRequest(t, router, path, map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "20000",
+		"amount_received": "20000",
+	}, claims, "retry-2")
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusUnprocessableEntity, rr.Body.String())
+	}
+	if len(store.payments) != 1 {
+		t.Errorf("payments: got %d, want 1", len(store.payments))
+	}
+}
+
+func TestAddPayment_IdempotencyKeyInProgress(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := seedPayableOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+	body := map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "50000",
+		"amount_received": "50000",
+	}
+
+	// Reserve the key the way an in-flight request would, then retry.
+	first := doIdempotentRequest(t, router, path, body, claims, "retry-3")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status: got %d, want %d; body: %s", first.Code, http.StatusCreated, first.Body.String())
+	}
+	k := idempotencyMapKey(outletID, "retry-3")
+	ik := store.keys[k]
+	ik.ResponseStatus.Valid = false
+	store.keys[k] = ik
+
+	rr := doIdempotentRequest(t, router, path, body, claims, "retry-3")
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+func TestAddPayment_FailedRequestReleasesIdempotencyKey(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := seedPayableOrder(store, outletID)
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+
+	// Cash payment without amount_received fails validation
+	rr := doIdempotentRequest(t, router, path, map[string]interface{}{
+		"payment_method": "CASH",
+		"amount":         "50000",
+	}, claims, "retry-4")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("first status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+
+	rr = doIdempotentRequest(t, router, path, map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "50000",
+		"amount_received": "50000",
+	}, claims, "retry-4")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("corrected status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+}
+
+func TestOrderCreate_IdempotentRetryDoesNotDuplicate(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	calls := 0
+	svc := &mockOrderService{
+		createFn: func(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error) {
+			calls++
+			return testOrderResult(outletID, claims.UserID), nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(svc, &mockOrderStore{}, claims)
+	body := map[string]interface{}{
+		"order_type": "DINE_IN",
+		"items": []map[string]interface{}{
+			{"product_id": uuid.New().String(), "quantity": 1},
+		},
+	}
+	path := "/outlets/" + outletID.String() + "/orders"
+
+	first := doIdempotentRequest(t, router, path, body, claims, "order-retry")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status: got %d, want %d; body: %s", first.Code, http.StatusCreated, first.Body.String())
+	}
+	second := doIdempotentRequest(t, router, path, body, claims, "order-retry")
+	if second.Code != http.StatusCreated {
+		t.Fatalf("retry status: got %d, want %d; body: %s", second.Code, http.StatusCreated, second.Body.String())
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Error("retry should replay the original order")
+	}
+	if calls != 1 {
+		t.Errorf("CreateOrder calls: got %d, want 1", calls)
+	}
+
+	// Without the header every request is processed
+	doAuthRequest(t, router, "POST", path, body, claims)
+	if calls != 2 {
+		t.Errorf("CreateOrder calls without key: got %d, want 2", calls)
+	}
+}
@@ -61,7 +61,7 @@ func TestIntegrationFlow(t *testing.T) {
 	go hub.Run()
 
 	// Build router
-	r := router.New(cfg, queries, pool, hub)
+	r := router.New(cfg, queries, pool, hub, hub)
 
 	// Create HTTP test server
 	server := httptest.NewServer(r)
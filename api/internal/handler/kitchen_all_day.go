@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// Time allowed to rebuild the all-day view after an order event
+const allDayPublishTimeout = 5 * time.Second
+
+// allDayTriggers are the events that can change what the kitchen has to cook.
+var allDayTriggers = map[string]bool{
+	ws.EventOrderCreated:           true,
+	ws.EventOrderStatusChanged:     true,
+	ws.EventOrderCancelled:         true,
+	ws.EventOrdersMerged:           true,
+	ws.EventOrderItemAdded:         true,
+	ws.EventOrderItemUpdated:       true,
+	ws.EventOrderItemRemoved:       true,
+	ws.EventOrderItemStatusChanged: true,
+}
+
+// KitchenQueueStore defines the database method needed for the all-day view.
+type KitchenQueueStore interface {
+	ListKitchenQueueItems(ctx context.Context, outletID uuid.UUID) ([]database.ListKitchenQueueItemsRow, error)
+}
+
+// --- Response types ---
+
+type kitchenAllDayResponse struct {
+	Items       []kitchenAllDayItem `json:"items"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+// kitchenAllDayItem is one dish to cook: every waiting item with the same
+// product, variants, modifiers and station.
+type kitchenAllDayItem struct {
+	ProductID         uuid.UUID               `json:"product_id"`
+	ProductName       string                  `json:"product_name"`
+	VariantIDs        []uuid.UUID             `json:"variant_ids"`
+	VariantNames      []string                `json:"variant_names"`
+	Modifiers         []kitchenAllDayModifier `json:"modifiers"`
+	Station           *string                 `json:"station"`
+	Quantity          int32                   `json:"quantity"`
+	PendingQuantity   int32                   `json:"pending_quantity"`
+	PreparingQuantity int32                   `json:"preparing_quantity"`
+	OrderCount        int                     `json:"order_count"`
+	OldestSentAt      time.Time               `json:"oldest_sent_at"`
+	OldestWaitSeconds int64                   `json:"oldest_wait_seconds"`
+}
+
+type kitchenAllDayModifier struct {
+	ModifierID uuid.UUID `json:"modifier_id"`
+	Name       string    `json:"name"`
+	Quantity   int32     `json:"quantity"`
+}
+
+// AllDay handles GET /outlets/{oid}/orders/all-day.
+// Aggregates PENDING and PREPARING items across active orders so cooks can
+// batch them. Optional ?stations=GRILL,RICE narrows it to those stations.
+func (h *OrderHandler) AllDay(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var stations map[string]bool
+	for _, s := range strings.Split(r.URL.Query().Get("stations"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			if stations == nil {
+				stations = make(map[string]bool)
+			}
+			stations[strings.ToUpper(s)] = true
+		}
+	}
+
+	rows, err := h.store.ListKitchenQueueItems(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list kitchen queue items: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if len(stations) > 0 {
+		kept := rows[:0]
+		for _, row := range rows {
+			if row.Station.Valid && stations[row.Station.String] {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+
+	writeJSON(w, http.StatusOK, buildKitchenAllDay(rows, time.Now()))
+}
+
+// buildKitchenAllDay groups queue items into dishes, longest waiting first.
+func buildKitchenAllDay(rows []database.ListKitchenQueueItemsRow, now time.Time) kitchenAllDayResponse {
+	resp := kitchenAllDayResponse{Items: []kitchenAllDayItem{}, GeneratedAt: now}
+	groups := make(map[string]int) // key -> index in resp.Items
+	orders := make(map[string]map[uuid.UUID]bool)
+
+	for _, row := range rows {
+		variantIDs := append([]uuid.UUID{}, row.VariantIds...)
+		sort.Slice(variantIDs, func(i, j int) bool { return variantIDs[i].String() < variantIDs[j].String() })
+
+		// Modifiers arrive ordered by ID, so equal sets give equal keys
+		var key strings.Builder
+		fmt.Fprintf(&key, "%s|%s|", row.ProductID, row.Station.String)
+		for _, id := range variantIDs {
+			fmt.Fprintf(&key, "%s,", id)
+		}
+		key.WriteString("|")
+		for i, id := range row.ModifierIds {
+			fmt.Fprintf(&key, "%s:%d,", id, row.ModifierQuantities[i])
+		}
+
+		k := key.String()
+		idx, ok := groups[k]
+		if !ok {
+			item := kitchenAllDayItem{
+				ProductID:    row.ProductID,
+				ProductName:  row.ProductName,
+				VariantIDs:   variantIDs,
+				VariantNames: row.VariantNames,
+				Modifiers:    make([]kitchenAllDayModifier, len(row.ModifierIds)),
+				OldestSentAt: row.SentAt,
+			}
+			for i, id := range row.ModifierIds {
+				item.Modifiers[i] = kitchenAllDayModifier{
+					ModifierID: id,
+					Name:       row.ModifierNames[i],
+					Quantity:   row.ModifierQuantities[i],
+				}
+			}
+			if row.Station.Valid {
+				item.Station = &row.Station.String
+			}
+			idx = len(resp.Items)
+			groups[k] = idx
+			orders[k] = make(map[uuid.UUID]bool)
+			resp.Items = append(resp.Items, item)
+		}
+
+		item := &resp.Items[idx]
+		item.Quantity += row.Quantity
+		if row.Status == enum.OrderItemStatusPreparing {
+			item.PreparingQuantity += row.Quantity
+		} else {
+			item.PendingQuantity += row.Quantity
+		}
+		if row.SentAt.Before(item.OldestSentAt) {
+			item.OldestSentAt = row.SentAt
+		}
+		if !orders[k][row.OrderID] {
+			orders[k][row.OrderID] = true
+			item.OrderCount++
+		}
+	}
+
+	for i := range resp.Items {
+		resp.Items[i].OldestWaitSeconds = int64(now.Sub(resp.Items[i].OldestSentAt).Seconds())
+	}
+	sort.SliceStable(resp.Items, func(i, j int) bool {
+		return resp.Items[i].OldestSentAt.Before(resp.Items[j].OldestSentAt)
+	})
+
+	return resp
+}
+
+// KitchenAllDayPublisher forwards events to hub and, after each event that
+// changes the kitchen queue, follows it with a kitchen.all_day snapshot. The
+// snapshot is routed to the stations of the triggering event and filtered to
+// them like order items, so each display sees its own dishes.
+type KitchenAllDayPublisher struct {
+	hub   Broadcaster
+	store KitchenQueueStore
+}
+
+// NewKitchenAllDayPublisher creates a new KitchenAllDayPublisher.
+func NewKitchenAllDayPublisher(hub Broadcaster, store KitchenQueueStore) *KitchenAllDayPublisher {
+	return &KitchenAllDayPublisher{hub: hub, store: store}
+}
+
+// BroadcastToOutlet publishes the event, then the refreshed all-day view if
+// the event can change it. Callers publish after commit, so the view is current.
+func (p *KitchenAllDayPublisher) BroadcastToOutlet(outletID uuid.UUID, event ws.Event) {
+	p.hub.BroadcastToOutlet(outletID, event)
+	if !allDayTriggers[event.Type] {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), allDayPublishTimeout)
+	defer cancel()
+
+	rows, err := p.store.ListKitchenQueueItems(ctx, outletID)
+	if err != nil {
+		log.Printf("ERROR: list kitchen queue items for %s event: %v", ws.EventKitchenAllDay, err)
+		return
+	}
+
+	data, err := json.Marshal(map[string]kitchenAllDayResponse{"all_day": buildKitchenAllDay(rows, time.Now())})
+	if err != nil {
+		log.Printf("ERROR: marshal %s event: %v", ws.EventKitchenAllDay, err)
+		return
+	}
+
+	p.hub.BroadcastToOutlet(outletID, ws.Event{
+		Type:     ws.EventKitchenAllDay,
+		Payload:  data,
+		Stations: event.Stations,
+	})
+}
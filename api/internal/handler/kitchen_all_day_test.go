@@ -0,0 +1,182 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+type allDayItem struct {
+	ProductName       string   `json:"product_name"`
+	VariantNames      []string `json:"variant_names"`
+	Station           *string  `json:"station"`
+	Quantity          int32    `json:"quantity"`
+	PendingQuantity   int32    `json:"pending_quantity"`
+	PreparingQuantity int32    `json:"preparing_quantity"`
+	OrderCount        int      `json:"order_count"`
+	OldestSentAt      string   `json:"oldest_sent_at"`
+	Modifiers         []struct {
+		Name     string `json:"name"`
+		Quantity int32  `json:"quantity"`
+	} `json:"modifiers"`
+}
+
+// testKitchenQueue is two orders of grilled chicken (one with extra sambal)
+// and iced tea.
+func testKitchenQueue(now time.Time) []database.ListKitchenQueueItemsRow {
+	chicken, tea := uuid.New(), uuid.New()
+	large, sambal := uuid.New(), uuid.New()
+	order1, order2 := uuid.New(), uuid.New()
+	grill := pgtype.Text{String: enum.StationGrill, Valid: true}
+	beverage := pgtype.Text{String: enum.StationBeverage, Valid: true}
+
+	return []database.ListKitchenQueueItemsRow{
+		{ID: uuid.New(), OrderID: order1, ProductID: chicken, ProductName: "Nasi Bakar Ayam", VariantIds: []uuid.UUID{}, VariantNames: []string{},
+			ModifierIds: []uuid.UUID{}, ModifierNames: []string{}, ModifierQuantities: []int32{},
+			Quantity: 2, Status: enum.OrderItemStatusPreparing, Station: grill, SentAt: now.Add(-15 * time.Minute)},
+		{ID: uuid.New(), OrderID: order1, ProductID: tea, ProductName: "Es Teh", VariantIds: []uuid.UUID{large}, VariantNames: []string{"Large"},
+			ModifierIds: []uuid.UUID{}, ModifierNames: []string{}, ModifierQuantities: []int32{},
+			Quantity: 3, Status: enum.OrderItemStatusPending, Station: beverage, SentAt: now.Add(-10 * time.Minute)},
+		{ID: uuid.New(), OrderID: order2, ProductID: chicken, ProductName: "Nasi Bakar Ayam", VariantIds: []uuid.UUID{}, VariantNames: []string{},
+			ModifierIds: []uuid.UUID{}, ModifierNames: []string{}, ModifierQuantities: []int32{},
+			Quantity: 1, Status: enum.OrderItemStatusPending, Station: grill, SentAt: now.Add(-5 * time.Minute)},
+		{ID: uuid.New(), OrderID: order2, ProductID: chicken, ProductName: "Nasi Bakar Ayam", VariantIds: []uuid.UUID{}, VariantNames: []string{},
+			ModifierIds: []uuid.UUID{sambal}, ModifierNames: []string{"Extra Sambal"}, ModifierQuantities: []int32{2},
+			Quantity: 1, Status: enum.OrderItemStatusPending, Station: grill, SentAt: now.Add(-5 * time.Minute)},
+	}
+}
+
+func TestOrderAllDay_GroupsByDish(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	store := &mockOrderStore{
+		listKitchenQueueItemsFn: func(ctx context.Context, oid uuid.UUID) ([]database.ListKitchenQueueItemsRow, error) {
+			if oid != outletID {
+				t.Errorf("outlet: got %s, want %s", oid, outletID)
+			}
+			return testKitchenQueue(now), nil
+		},
+	}
+
+	router := setupOrderRouterWithHub(nil, store, nil)
+	rr := doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/orders/all-day", nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp struct {
+		Items []allDayItem `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 3 {
+		t.Fatalf("items: got %d, want 3: %+v", len(resp.Items), resp.Items)
+	}
+
+	// Longest waiting first
+	plain := resp.Items[0]
+	if plain.ProductName != "Nasi Bakar Ayam" || len(plain.Modifiers) != 0 {
+		t.Fatalf("first dish: got %+v", plain)
+	}
+	if plain.Quantity != 3 || plain.PendingQuantity != 1 || plain.PreparingQuantity != 2 || plain.OrderCount != 2 {
+		t.Errorf("plain chicken counts: got %+v", plain)
+	}
+	if want := now.Add(-15 * time.Minute).Format(time.RFC3339); plain.OldestSentAt != want {
+		t.Errorf("oldest_sent_at: got %s, want %s", plain.OldestSentAt, want)
+	}
+
+	tea := resp.Items[1]
+	if tea.ProductName != "Es Teh" || tea.Quantity != 3 || len(tea.VariantNames) != 1 || tea.VariantNames[0] != "Large" {
+		t.Errorf("tea: got %+v", tea)
+	}
+
+	sambal := resp.Items[2]
+	if len(sambal.Modifiers) != 1 || sambal.Modifiers[0].Name != "Extra Sambal" || sambal.Modifiers[0].Quantity != 2 || sambal.Quantity != 1 {
+		t.Errorf("chicken with sambal: got %+v", sambal)
+	}
+}
+
+func TestOrderAllDay_FiltersByStation(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	store := &mockOrderStore{
+		listKitchenQueueItemsFn: func(ctx context.Context, oid uuid.UUID) ([]database.ListKitchenQueueItemsRow, error) {
+			return testKitchenQueue(time.Now()), nil
+		},
+	}
+
+	router := setupOrderRouterWithHub(nil, store, nil)
+	rr := doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/orders/all-day?stations=beverage", nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp struct {
+		Items []allDayItem `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].ProductName != "Es Teh" {
+		t.Errorf("items: got %+v, want only Es Teh", resp.Items)
+	}
+}
+
+func TestKitchenAllDayPublisher(t *testing.T) {
+	outletID := uuid.New()
+	store := &mockOrderStore{
+		listKitchenQueueItemsFn: func(ctx context.Context, oid uuid.UUID) ([]database.ListKitchenQueueItemsRow, error) {
+			return testKitchenQueue(time.Now()), nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	p := handler.NewKitchenAllDayPublisher(hub, store)
+
+	// Payments don't change the kitchen queue
+	p.BroadcastToOutlet(outletID, ws.Event{Type: ws.EventPaymentAdded, Payload: json.RawMessage(`{}`)})
+	p.BroadcastToOutlet(outletID, ws.Event{
+		Type:     ws.EventOrderItemStatusChanged,
+		Payload:  json.RawMessage(`{}`),
+		Stations: []string{enum.StationGrill},
+	})
+
+	want := []string{ws.EventPaymentAdded, ws.EventOrderItemStatusChanged, ws.EventKitchenAllDay}
+	got := hub.types()
+	if len(got) != len(want) {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events: got %v, want %v", got, want)
+		}
+	}
+
+	allDay := hub.events[2].event
+	if len(allDay.Stations) != 1 || allDay.Stations[0] != enum.StationGrill {
+		t.Errorf("stations: got %v, want [%s]", allDay.Stations, enum.StationGrill)
+	}
+	var payload struct {
+		AllDay struct {
+			Items []allDayItem `json:"items"`
+		} `json:"all_day"`
+	}
+	if err := json.Unmarshal(allDay.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(payload.AllDay.Items) != 3 {
+		t.Errorf("all_day items: got %d, want 3", len(payload.AllDay.Items))
+	}
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// nextItemStatus is where a bump without an explicit status moves an item.
+var nextItemStatus = map[string]string{
+	enum.OrderItemStatusPending:   enum.OrderItemStatusPreparing,
+	enum.OrderItemStatusPreparing: enum.OrderItemStatusReady,
+}
+
+// --- Command payloads ---
+
+type bumpItemCommand struct {
+	OrderID string `json:"order_id"`
+	ItemID  string `json:"item_id"`
+	Status  string `json:"status"` // optional; defaults to the next status
+}
+
+type recallTicketCommand struct {
+	OrderID string   `json:"order_id"`
+	ItemIDs []string `json:"item_ids"` // optional; defaults to the display's READY items
+}
+
+type acknowledgeOrderCommand struct {
+	OrderID string `json:"order_id"`
+}
+
+type recallTicketResponse struct {
+	Items []orderItemResponse `json:"items"`
+}
+
+type acknowledgementResponse struct {
+	OrderID        uuid.UUID `json:"order_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Stations       []string  `json:"stations"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// HandleCommand carries out a kitchen display command sent over the order
+// WebSocket. Item changes go through the same checks, audit trail and events
+// as the REST endpoints. A station-filtered display can only act on its own items.
+func (h *OrderHandler) HandleCommand(ctx context.Context, req ws.CommandRequest) (interface{}, error) {
+	var result interface{}
+	var err error
+	switch req.Type {
+	case ws.CommandItemBump:
+		result, err = h.bumpItem(ctx, req)
+	case ws.CommandTicketRecall:
+		result, err = h.recallTicket(ctx, req)
+	case ws.CommandOrderAck:
+		result, err = h.acknowledgeOrder(ctx, req)
+	default:
+		return nil, &ws.CommandError{Code: ws.CommandErrBadRequest, Message: "unknown command " + req.Type}
+	}
+	if err != nil {
+		return nil, toCommandError(err)
+	}
+	return result, nil
+}
+
+func (h *OrderHandler) bumpItem(ctx context.Context, req ws.CommandRequest) (interface{}, error) {
+	var cmd bumpItemCommand
+	if err := json.Unmarshal(req.Payload, &cmd); err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid command payload"}
+	}
+	orderID, err := uuid.Parse(cmd.OrderID)
+	if err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid order ID"}
+	}
+	itemID, err := uuid.Parse(cmd.ItemID)
+	if err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid item ID"}
+	}
+
+	// Confirms the order is in this outlet before looking at its items
+	if _, err := h.getOrderForCommand(ctx, req.OutletID, orderID); err != nil {
+		return nil, err
+	}
+	item, err := h.store.GetOrderItem(ctx, database.GetOrderItemParams{ID: itemID, OrderID: orderID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &statusError{http.StatusNotFound, "item not found"}
+		}
+		return nil, fmt.Errorf("get order item for bump: %w", err)
+	}
+	if !atStations(item, req.Stations) {
+		return nil, &statusError{http.StatusForbidden, "item is not at this station"}
+	}
+
+	status := cmd.Status
+	if status == "" {
+		next, ok := nextItemStatus[item.Status]
+		if !ok {
+			return nil, &statusError{http.StatusConflict, "item is already " + item.Status}
+		}
+		status = next
+	}
+
+	return h.changeItemStatus(ctx, req.Claims.UserID, req.OutletID, orderID, itemID, status, false)
+}
+
+func (h *OrderHandler) recallTicket(ctx context.Context, req ws.CommandRequest) (interface{}, error) {
+	var cmd recallTicketCommand
+	if err := json.Unmarshal(req.Payload, &cmd); err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid command payload"}
+	}
+	orderID, err := uuid.Parse(cmd.OrderID)
+	if err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid order ID"}
+	}
+	if _, err := h.getOrderForCommand(ctx, req.OutletID, orderID); err != nil {
+		return nil, err
+	}
+
+	items, err := h.store.ListOrderItemsByOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list order items for recall: %w", err)
+	}
+	byID := make(map[uuid.UUID]database.OrderItem, len(items))
+	hasComponents := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		byID[item.ID] = item
+		if item.ParentItemID.Valid {
+			hasComponents[item.ParentItemID.Bytes] = true
+		}
+	}
+
+	var recall []database.OrderItem
+	if len(cmd.ItemIDs) > 0 {
+		for _, raw := range cmd.ItemIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, &statusError{http.StatusBadRequest, "invalid item ID"}
+			}
+			item, ok := byID[id]
+			if !ok {
+				return nil, &statusError{http.StatusNotFound, "item not found"}
+			}
+			if !atStations(item, req.Stations) {
+				return nil, &statusError{http.StatusForbidden, "item is not at this station"}
+			}
+			if item.Status != enum.OrderItemStatusReady {
+				return nil, &statusError{http.StatusConflict, "only READY items can be recalled"}
+			}
+			recall = append(recall, item)
+		}
+	} else {
+		// The whole ticket as this display sees it; combo lines follow their components
+		for _, item := range items {
+			if item.Status == enum.OrderItemStatusReady && !hasComponents[item.ID] && atStations(item, req.Stations) {
+				recall = append(recall, item)
+			}
+		}
+	}
+	if len(recall) == 0 {
+		return nil, &statusError{http.StatusConflict, "no READY items to recall"}
+	}
+
+	resp := recallTicketResponse{Items: make([]orderItemResponse, 0, len(recall))}
+	for _, item := range recall {
+		itemResp, err := h.changeItemStatus(ctx, req.Claims.UserID, req.OutletID, orderID, item.ID, enum.OrderItemStatusPreparing, true)
+		if err != nil {
+			return nil, err
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+	return resp, nil
+}
+
+func (h *OrderHandler) acknowledgeOrder(ctx context.Context, req ws.CommandRequest) (interface{}, error) {
+	var cmd acknowledgeOrderCommand
+	if err := json.Unmarshal(req.Payload, &cmd); err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid command payload"}
+	}
+	orderID, err := uuid.Parse(cmd.OrderID)
+	if err != nil {
+		return nil, &statusError{http.StatusBadRequest, "invalid order ID"}
+	}
+	order, err := h.getOrderForCommand(ctx, req.OutletID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == enum.OrderStatusCancelled || order.Status == enum.OrderStatusCompleted {
+		return nil, &statusError{http.StatusConflict, "cannot acknowledge a " + order.Status + " order"}
+	}
+
+	ack := acknowledgementResponse{
+		OrderID:        orderID,
+		UserID:         req.Claims.UserID,
+		Stations:       sortedStations(req.Stations),
+		AcknowledgedAt: time.Now(),
+	}
+	if err := service.RecordOrderEvent(ctx, h.store, orderID, req.Claims.UserID, enum.OrderEventAcknowledged,
+		nil, map[string]interface{}{"stations": ack.Stations},
+	); err != nil {
+		return nil, fmt.Errorf("record order acknowledgement: %w", err)
+	}
+
+	payload := orderEventPayload{Acknowledgement: &ack}
+	if len(ack.Stations) > 0 {
+		payload.stations = ack.Stations
+	}
+	publishOrderEvent(ctx, h.hub, h.store, ws.EventOrderAcknowledged, req.OutletID, orderID, payload)
+
+	return ack, nil
+}
+
+// --- Helpers ---
+
+func (h *OrderHandler) getOrderForCommand(ctx context.Context, outletID, orderID uuid.UUID) (database.Order, error) {
+	order, err := h.store.GetOrder(ctx, database.GetOrderParams{ID: orderID, OutletID: outletID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return database.Order{}, &statusError{http.StatusNotFound, "order not found"}
+		}
+		return database.Order{}, fmt.Errorf("get order for command: %w", err)
+	}
+	return order, nil
+}
+
+// atStations reports whether a display subscribed to stations may act on the item.
+// Unfiltered displays may act on any item.
+func atStations(item database.OrderItem, stations map[string]bool) bool {
+	if len(stations) == 0 {
+		return true
+	}
+	return item.Station.Valid && stations[item.Station.String]
+}
+
+func sortedStations(stations map[string]bool) []string {
+	out := make([]string, 0, len(stations))
+	for s := range stations {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// toCommandError maps a rejected request to the code its REST status implies.
+// Other errors are returned as-is and reported as internal.
+func toCommandError(err error) error {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return err
+	}
+	code := ws.CommandErrInternal
+	switch se.status {
+	case http.StatusBadRequest:
+		code = ws.CommandErrBadRequest
+	case http.StatusForbidden:
+		code = ws.CommandErrForbidden
+	case http.StatusNotFound:
+		code = ws.CommandErrNotFound
+	case http.StatusConflict:
+		code = ws.CommandErrConflict
+	}
+	return &ws.CommandError{Code: code, Message: se.msg}
+}
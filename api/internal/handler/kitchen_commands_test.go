@@ -0,0 +1,201 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+func kitchenCommand(t *testing.T, outletID uuid.UUID, stations map[string]bool, cmdType string, payload interface{}) ws.CommandRequest {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return ws.CommandRequest{
+		Command:  ws.Command{ID: "c1", Type: cmdType, Payload: data},
+		Claims:   &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "KITCHEN"},
+		OutletID: outletID,
+		Stations: stations,
+	}
+}
+
+func commandErrorCode(t *testing.T, err error) string {
+	t.Helper()
+	var cmdErr *ws.CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected *ws.CommandError, got %v", err)
+	}
+	return cmdErr.Code
+}
+
+func TestKitchenCommand_BumpAdvancesStatus(t *testing.T) {
+	outletID := uuid.New()
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	item := testDBOrderItem(order.ID)
+	item.Status = enum.OrderItemStatusPreparing
+	item.Station = pgtype.Text{String: enum.StationGrill, Valid: true}
+
+	var gotStatus string
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return item, nil
+		},
+		updateOrderItemStatusFn: func(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error) {
+			gotStatus = arg.Status
+			updated := item
+			updated.Status = arg.Status
+			return updated, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	h := handler.NewOrderHandler(nil, store, &mockPool{}, mockNewStore(store), hub)
+
+	_, err := h.HandleCommand(context.Background(), kitchenCommand(t, outletID, map[string]bool{enum.StationGrill: true},
+		ws.CommandItemBump, map[string]string{"order_id": order.ID.String(), "item_id": item.ID.String()}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotStatus != enum.OrderItemStatusReady {
+		t.Errorf("status: got %s, want %s", gotStatus, enum.OrderItemStatusReady)
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderItemStatusChanged {
+		t.Errorf("events: got %v, want [%s]", got, ws.EventOrderItemStatusChanged)
+	}
+
+	// Another station's display can't touch it
+	_, err = h.HandleCommand(context.Background(), kitchenCommand(t, outletID, map[string]bool{enum.StationBeverage: true},
+		ws.CommandItemBump, map[string]string{"order_id": order.ID.String(), "item_id": item.ID.String()}))
+	if code := commandErrorCode(t, err); code != ws.CommandErrForbidden {
+		t.Errorf("code: got %s, want %s", code, ws.CommandErrForbidden)
+	}
+
+	// READY is as far as a bump goes
+	item.Status = enum.OrderItemStatusReady
+	_, err = h.HandleCommand(context.Background(), kitchenCommand(t, outletID, nil,
+		ws.CommandItemBump, map[string]string{"order_id": order.ID.String(), "item_id": item.ID.String()}))
+	if code := commandErrorCode(t, err); code != ws.CommandErrConflict {
+		t.Errorf("code: got %s, want %s", code, ws.CommandErrConflict)
+	}
+}
+
+func TestKitchenCommand_RecallTicket(t *testing.T) {
+	outletID := uuid.New()
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusReady)
+
+	satay := testDBOrderItem(order.ID)
+	satay.Status = enum.OrderItemStatusReady
+	satay.Station = pgtype.Text{String: enum.StationGrill, Valid: true}
+	tea := testDBOrderItem(order.ID)
+	tea.Status = enum.OrderItemStatusReady
+	tea.Station = pgtype.Text{String: enum.StationBeverage, Valid: true}
+	items := map[uuid.UUID]database.OrderItem{satay.ID: satay, tea.ID: tea}
+
+	var recalled []uuid.UUID
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		listOrderItemsByOrderFn: func(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+			return []database.OrderItem{satay, tea}, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return items[arg.ID], nil
+		},
+		updateOrderItemStatusFn: func(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error) {
+			if arg.Status != enum.OrderItemStatusPreparing {
+				t.Errorf("status: got %s, want %s", arg.Status, enum.OrderItemStatusPreparing)
+			}
+			recalled = append(recalled, arg.ID)
+			updated := items[arg.ID]
+			updated.Status = arg.Status
+			return updated, nil
+		},
+	}
+	h := handler.NewOrderHandler(nil, store, &mockPool{}, mockNewStore(store), &mockBroadcaster{})
+
+	result, err := h.HandleCommand(context.Background(), kitchenCommand(t, outletID, map[string]bool{enum.StationGrill: true},
+		ws.CommandTicketRecall, map[string]string{"order_id": order.ID.String()}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recalled) != 1 || recalled[0] != satay.ID {
+		t.Errorf("recalled: got %v, want only the GRILL item", recalled)
+	}
+	data, _ := json.Marshal(result)
+	var resp struct {
+		Items []struct {
+			Status string `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil || len(resp.Items) != 1 || resp.Items[0].Status != enum.OrderItemStatusPreparing {
+		t.Errorf("result: got %s", data)
+	}
+
+	// Items still in progress can't be recalled
+	tea.Status = enum.OrderItemStatusPreparing
+	items[tea.ID] = tea
+	_, err = h.HandleCommand(context.Background(), kitchenCommand(t, outletID, nil,
+		ws.CommandTicketRecall, map[string]interface{}{"order_id": order.ID.String(), "item_ids": []string{tea.ID.String()}}))
+	if code := commandErrorCode(t, err); code != ws.CommandErrConflict {
+		t.Errorf("code: got %s, want %s", code, ws.CommandErrConflict)
+	}
+}
+
+func TestKitchenCommand_AcknowledgeOrder(t *testing.T) {
+	outletID := uuid.New()
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+
+	var recorded database.CreateOrderEventParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		createOrderEventFn: func(ctx context.Context, arg database.CreateOrderEventParams) error {
+			recorded = arg
+			return nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	h := handler.NewOrderHandler(nil, store, &mockPool{}, mockNewStore(store), hub)
+
+	req := kitchenCommand(t, outletID, map[string]bool{enum.StationRice: true},
+		ws.CommandOrderAck, map[string]string{"order_id": order.ID.String()})
+	if _, err := h.HandleCommand(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recorded.EventType != enum.OrderEventAcknowledged || recorded.ActorID != req.Claims.UserID {
+		t.Errorf("order event: got %+v", recorded)
+	}
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderAcknowledged {
+		t.Fatalf("events: got %v, want [%s]", got, ws.EventOrderAcknowledged)
+	}
+	if got := hub.events[0].event.Stations; len(got) != 1 || got[0] != enum.StationRice {
+		t.Errorf("stations: got %v, want [%s]", got, enum.StationRice)
+	}
+
+	order.Status = enum.OrderStatusCancelled
+	if _, err := h.HandleCommand(context.Background(), req); commandErrorCode(t, err) != ws.CommandErrConflict {
+		t.Errorf("cancelled order: got %v, want %s", err, ws.CommandErrConflict)
+	}
+}
+
+func TestKitchenCommand_Unknown(t *testing.T) {
+	h := handler.NewOrderHandler(nil, &mockOrderStore{}, &mockPool{}, mockNewStore(&mockOrderStore{}), nil)
+	_, err := h.HandleCommand(context.Background(), kitchenCommand(t, uuid.New(), nil, "order.delete", map[string]string{}))
+	if code := commandErrorCode(t, err); code != ws.CommandErrBadRequest {
+		t.Errorf("code: got %s, want %s", code, ws.CommandErrBadRequest)
+	}
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/receipt"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// KitchenTicketStore defines the database methods needed by kitchen ticket handlers.
+// Satisfied by *database.Queries; narrow interface for testability.
+type KitchenTicketStore interface {
+	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error)
+	GetKitchenTicket(ctx context.Context, arg database.GetKitchenTicketParams) (database.KitchenTicket, error)
+	MarkKitchenTicketReprinted(ctx context.Context, arg database.MarkKitchenTicketReprintedParams) (database.KitchenTicket, error)
+}
+
+// KitchenTicketHandler lists, renders and reprints an order's kitchen tickets.
+// Tickets themselves are printed by the order endpoints as the order changes.
+type KitchenTicketHandler struct {
+	store KitchenTicketStore
+}
+
+// NewKitchenTicketHandler creates a new KitchenTicketHandler.
+func NewKitchenTicketHandler(store KitchenTicketStore) *KitchenTicketHandler {
+	return &KitchenTicketHandler{store: store}
+}
+
+// RegisterRoutes registers kitchen ticket endpoints on the given Chi router.
+// Expected to be mounted at /outlets/{oid}/orders/{id}/tickets
+func (h *KitchenTicketHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Get("/{tid}", h.Get)
+	r.Post("/{tid}/reprint", h.Reprint)
+}
+
+// --- Response types ---
+
+type kitchenTicketResponse struct {
+	ID              string                      `json:"id"`
+	OrderID         string                      `json:"order_id"`
+	Sequence        int32                       `json:"sequence"`
+	Station         *string                     `json:"station"`
+	TicketType      string                      `json:"ticket_type"`
+	Lines           []service.KitchenTicketLine `json:"lines"`
+	CreatedBy       string                      `json:"created_by"`
+	CreatedAt       time.Time                   `json:"created_at"`
+	ReprintCount    int32                       `json:"reprint_count"`
+	LastReprintedAt *time.Time                  `json:"last_reprinted_at"`
+}
+
+func toKitchenTicketResponse(t database.KitchenTicket) (kitchenTicketResponse, error) {
+	lines, err := service.DecodeKitchenTicketLines(t)
+	if err != nil {
+		return kitchenTicketResponse{}, err
+	}
+	resp := kitchenTicketResponse{
+		ID:           t.ID.String(),
+		OrderID:      t.OrderID.String(),
+		Sequence:     t.Sequence,
+		TicketType:   t.TicketType,
+		Lines:        lines,
+		CreatedBy:    t.CreatedBy.String(),
+		CreatedAt:    t.CreatedAt,
+		ReprintCount: t.ReprintCount,
+	}
+	if t.Station.Valid {
+		resp.Station = &t.Station.String
+	}
+	if t.LastReprintedAt.Valid {
+		resp.LastReprintedAt = &t.LastReprintedAt.Time
+	}
+	return resp, nil
+}
+
+// --- Handlers ---
+
+// List handles GET /outlets/{oid}/orders/{id}/tickets.
+// Query param: station (optional) limits the list to one station's tickets.
+func (h *KitchenTicketHandler) List(w http.ResponseWriter, r *http.Request) {
+	_, orderID, ok := h.loadOrder(w, r)
+	if !ok {
+		return
+	}
+	station := strings.ToUpper(r.URL.Query().Get("station"))
+
+	tickets, err := h.store.ListKitchenTickets(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: list kitchen tickets: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := []kitchenTicketResponse{}
+	for _, t := range tickets {
+		if station != "" && t.Station.String != station {
+			continue
+		}
+		tr, err := toKitchenTicketResponse(t)
+		if err != nil {
+			log.Printf("ERROR: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		resp = append(resp, tr)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Get handles GET /outlets/{oid}/orders/{id}/tickets/{tid}.
+// Query params: format=text|escpos (default text), paper=58|80.
+func (h *KitchenTicketHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, false)
+}
+
+// Reprint handles POST /outlets/{oid}/orders/{id}/tickets/{tid}/reprint.
+// Renders the ticket again, marked as a reprint, for a printer that jammed.
+// Takes the same query params as Get.
+func (h *KitchenTicketHandler) Reprint(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, true)
+}
+
+func (h *KitchenTicketHandler) render(w http.ResponseWriter, r *http.Request, reprint bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receiptFormatText
+	}
+	if format != receiptFormatText && format != receiptFormatESCPOS {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be text or escpos"})
+		return
+	}
+	paperWidth, ok := paperParam(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "paper must be 58 or 80"})
+		return
+	}
+
+	ticketID, err := uuid.Parse(chi.URLParam(r, "tid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ticket ID"})
+		return
+	}
+
+	order, orderID, ok := h.loadOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var ticket database.KitchenTicket
+	if reprint {
+		ticket, err = h.store.MarkKitchenTicketReprinted(r.Context(), database.MarkKitchenTicketReprintedParams{ID: ticketID, OrderID: orderID})
+	} else {
+		ticket, err = h.store.GetKitchenTicket(r.Context(), database.GetKitchenTicketParams{ID: ticketID, OrderID: orderID})
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "ticket not found"})
+			return
+		}
+		log.Printf("ERROR: get kitchen ticket: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	settings, err := h.store.GetOutletSettings(r.Context(), order.OutletID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("ERROR: get outlet settings for kitchen ticket: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	paperWidth = printerPaperWidth(paperWidth, settings)
+
+	kt, err := buildKitchenTicket(order, ticket)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	kt.Reprint = reprint
+
+	if format == receiptFormatESCPOS {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write(kt.ESCPOS(paperWidth))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(kt.Text(paperWidth)))
+}
+
+// loadOrder parses the outlet and order IDs and fetches the order, writing
+// the error response when that fails.
+func (h *KitchenTicketHandler) loadOrder(w http.ResponseWriter, r *http.Request) (database.Order, uuid.UUID, bool) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return database.Order{}, uuid.Nil, false
+	}
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return database.Order{}, uuid.Nil, false
+	}
+
+	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{ID: orderID, OutletID: outletID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return database.Order{}, uuid.Nil, false
+		}
+		log.Printf("ERROR: get order for kitchen tickets: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.Order{}, uuid.Nil, false
+	}
+	return order, orderID, true
+}
+
+// buildKitchenTicket assembles what a stored ticket prints.
+func buildKitchenTicket(order database.Order, ticket database.KitchenTicket) (receipt.KitchenTicket, error) {
+	lines, err := service.DecodeKitchenTicketLines(ticket)
+	if err != nil {
+		return receipt.KitchenTicket{}, err
+	}
+
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+
+	kt := receipt.KitchenTicket{
+		Station:     ticket.Station.String,
+		OrderNumber: order.OrderNumber,
+		OrderType:   orderTypeLabels[order.OrderType],
+		Sequence:    ticket.Sequence,
+		Change:      ticket.TicketType == enum.KitchenTicketTypeChange,
+		Time:        ticket.CreatedAt.In(loc),
+		Lines:       make([]receipt.TicketLine, len(lines)),
+	}
+	if order.TableNumber.Valid {
+		kt.TableNumber = order.TableNumber.String
+	}
+	for i, l := range lines {
+		name := l.Name
+		if len(l.Variants) > 0 {
+			name += " (" + strings.Join(l.Variants, ", ") + ")"
+		}
+		tl := receipt.TicketLine{
+			Change:           l.Change,
+			Name:             name,
+			Quantity:         l.Quantity,
+			PreviousQuantity: l.PreviousQuantity,
+			Notes:            l.Notes,
+		}
+		for _, m := range l.Modifiers {
+			tl.Modifiers = append(tl.Modifiers, receipt.Modifier{Name: m.Name, Quantity: m.Quantity})
+		}
+		kt.Lines[i] = tl
+	}
+	return kt, nil
+}
+
+// publishKitchenTickets announces newly printed tickets to the stations
+// they are for, so printer clients can fetch and print them.
+func publishKitchenTickets(hub Broadcaster, outletID uuid.UUID, tickets []database.KitchenTicket) {
+	if hub == nil {
+		return
+	}
+	for _, t := range tickets {
+		tr, err := toKitchenTicketResponse(t)
+		if err != nil {
+			log.Printf("ERROR: %s event: %v", ws.EventKitchenTicketCreated, err)
+			continue
+		}
+		data, err := json.Marshal(map[string]interface{}{"ticket": tr})
+		if err != nil {
+			log.Printf("ERROR: marshal %s event: %v", ws.EventKitchenTicketCreated, err)
+			continue
+		}
+		var stations []string
+		if t.Station.Valid {
+			stations = []string{t.Station.String}
+		}
+		hub.BroadcastToOutlet(outletID, ws.Event{
+			Type:     ws.EventKitchenTicketCreated,
+			Payload:  data,
+			Stations: stations,
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// --- Mock KitchenTicketStore ---
+
+type mockKitchenTicketStore struct {
+	order   database.Order
+	tickets []database.KitchenTicket
+}
+
+func (m *mockKitchenTicketStore) GetOrder(_ context.Context, arg database.GetOrderParams) (database.Order, error) {
+	if arg.ID != m.order.ID || arg.OutletID != m.order.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return m.order, nil
+}
+
+func (m *mockKitchenTicketStore) GetOutletSettings(_ context.Context, _ uuid.UUID) (database.OutletSetting, error) {
+	return database.OutletSetting{}, pgx.ErrNoRows
+}
+
+func (m *mockKitchenTicketStore) ListKitchenTickets(_ context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+	return m.tickets, nil
+}
+
+func (m *mockKitchenTicketStore) GetKitchenTicket(_ context.Context, arg database.GetKitchenTicketParams) (database.KitchenTicket, error) {
+	for _, t := range m.tickets {
+		if t.ID == arg.ID && t.OrderID == arg.OrderID {
+			return t, nil
+		}
+	}
+	return database.KitchenTicket{}, pgx.ErrNoRows
+}
+
+func (m *mockKitchenTicketStore) MarkKitchenTicketReprinted(_ context.Context, arg database.MarkKitchenTicketReprintedParams) (database.KitchenTicket, error) {
+	for i, t := range m.tickets {
+		if t.ID == arg.ID && t.OrderID == arg.OrderID {
+			m.tickets[i].ReprintCount++
+			m.tickets[i].LastReprintedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return m.tickets[i], nil
+		}
+	}
+	return database.KitchenTicket{}, pgx.ErrNoRows
+}
+
+// --- Helpers ---
+
+func setupKitchenTicketRouter(store *mockKitchenTicketStore) *chi.Mux {
+	h := handler.NewKitchenTicketHandler(store)
+	r := chi.NewRouter()
+	r.Route("/outlets/{oid}/orders/{id}/tickets", h.RegisterRoutes)
+	return r
+}
+
+func testKitchenTicket(t *testing.T, orderID uuid.UUID, seq int32, station, ticketType string, lines []service.KitchenTicketLine) database.KitchenTicket {
+	t.Helper()
+	data, err := json.Marshal(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return database.KitchenTicket{
+		ID:         uuid.New(),
+		OrderID:    orderID,
+		Sequence:   seq,
+		Station:    pgtype.Text{String: station, Valid: true},
+		TicketType: ticketType,
+		Lines:      data,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func seedKitchenTickets(t *testing.T) *mockKitchenTicketStore {
+	order := database.Order{
+		ID:          uuid.New(),
+		OutletID:    uuid.New(),
+		OrderNumber: "KWR-014",
+		OrderType:   enum.OrderTypeDineIn,
+		TableNumber: pgtype.Text{String: "4", Valid: true},
+	}
+	nasi := service.KitchenTicketLine{ItemID: uuid.New(), Change: enum.KitchenLineAdded, Name: "Nasi Bakar", Variants: []string{"Pedas"}, Quantity: 2}
+	teh := service.KitchenTicketLine{ItemID: uuid.New(), Change: enum.KitchenLineAdded, Name: "Es Teh", Quantity: 1}
+	voided := nasi
+	voided.Change = enum.KitchenLineVoided
+	return &mockKitchenTicketStore{
+		order: order,
+		tickets: []database.KitchenTicket{
+			testKitchenTicket(t, order.ID, 1, enum.StationBeverage, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{teh}),
+			testKitchenTicket(t, order.ID, 2, enum.StationGrill, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{nasi}),
+			testKitchenTicket(t, order.ID, 3, enum.StationGrill, enum.KitchenTicketTypeChange, []service.KitchenTicketLine{voided}),
+		},
+	}
+}
+
+func ticketsPath(store *mockKitchenTicketStore) string {
+	return "/outlets/" + store.order.OutletID.String() + "/orders/" + store.order.ID.String() + "/tickets"
+}
+
+// --- Tests ---
+
+func TestKitchenTicketList_FiltersByStation(t *testing.T) {
+	store := seedKitchenTickets(t)
+	router := setupKitchenTicketRouter(store)
+
+	rr := doRequest(t, router, "GET", ticketsPath(store)+"?station=grill", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("tickets: got %d, want 2 grill tickets", len(resp))
+	}
+	if resp[1]["ticket_type"] != "CHANGE" || resp[1]["sequence"] != float64(3) {
+		t.Errorf("second ticket: got %v #%v", resp[1]["ticket_type"], resp[1]["sequence"])
+	}
+	lines := resp[1]["lines"].([]interface{})
+	if lines[0].(map[string]interface{})["change"] != "VOIDED" {
+		t.Errorf("line change: got %v, want VOIDED", lines[0])
+	}
+}
+
+func TestKitchenTicketGet_Text(t *testing.T) {
+	store := seedKitchenTickets(t)
+	router := setupKitchenTicketRouter(store)
+
+	rr := doRequest(t, router, "GET", ticketsPath(store)+"/"+store.tickets[2].ID.String(), nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"GRILL", "** CHANGE **", "KWR-014", "#3", "Dine in - Table 4", "VOID 2x Nasi Bakar (Pedas)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ticket missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "REPRINT") {
+		t.Errorf("first print should not be marked as a reprint:\n%s", body)
+	}
+}
+
+func TestKitchenTicketReprint(t *testing.T) {
+	store := seedKitchenTickets(t)
+	router := setupKitchenTicketRouter(store)
+
+	rr := doRequest(t, router, "POST", ticketsPath(store)+"/"+store.tickets[1].ID.String()+"/reprint?format=escpos&paper=80", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	body := rr.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte{0x1B, 0x40}) || !bytes.Contains(body, []byte("** REPRINT **")) {
+		t.Errorf("expected ESC/POS reprint, got %q", body)
+	}
+	if store.tickets[1].ReprintCount != 1 {
+		t.Errorf("reprint count: got %d, want 1", store.tickets[1].ReprintCount)
+	}
+}
+
+func TestKitchenTicketGet_NotFound(t *testing.T) {
+	store := seedKitchenTickets(t)
+	router := setupKitchenTicketRouter(store)
+
+	rr := doRequest(t, router, "GET", ticketsPath(store)+"/"+uuid.New().String(), nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("unknown ticket: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	rr = doRequest(t, router, "GET", ticketsPath(store)+"/"+store.tickets[0].ID.String()+"?format=html", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("html format: got %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+// Removing an item from an order the kitchen has a ticket for prints a
+// change ticket voiding it and announces it to the item's station.
+func TestRemoveItem_PrintsVoidTicket(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	removed := database.OrderItem{ID: uuid.New(), OrderID: order.ID, Station: pgtype.Text{String: enum.StationGrill, Valid: true}}
+	kept := database.ListKitchenTicketItemsRow{ID: uuid.New(), ProductName: "Es Teh", VariantNames: []string{}, Quantity: 1, Station: pgtype.Text{String: enum.StationBeverage, Valid: true}}
+
+	first := []database.KitchenTicket{
+		testKitchenTicket(t, order.ID, 1, enum.StationBeverage, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{
+			{ItemID: kept.ID, Change: enum.KitchenLineAdded, Name: "Es Teh", Variants: []string{}, Quantity: 1},
+		}),
+		testKitchenTicket(t, order.ID, 2, enum.StationGrill, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{
+			{ItemID: removed.ID, Change: enum.KitchenLineAdded, Name: "Ayam Bakar", Quantity: 1},
+		}),
+	}
+
+	var created []database.CreateKitchenTicketParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		countOrderItemsFn: func(ctx context.Context, orderID uuid.UUID) (int64, error) {
+			return 2, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return removed, nil
+		},
+		deleteOrderItemFn: func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+			return nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+		listKitchenTicketItemsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error) {
+			return []database.ListKitchenTicketItemsRow{kept}, nil
+		},
+		listKitchenTicketsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+			return first, nil
+		},
+		createKitchenTicketFn: func(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+			created = append(created, arg)
+			return database.KitchenTicket{ID: uuid.New(), OrderID: arg.OrderID, Sequence: 3, Station: arg.Station, TicketType: arg.TicketType, Lines: arg.Lines}, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	router := setupOrderRouterWithHub(nil, store, hub)
+
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+removed.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(created) != 1 {
+		t.Fatalf("tickets created: got %d, want 1", len(created))
+	}
+	if created[0].Station.String != enum.StationGrill || created[0].TicketType != enum.KitchenTicketTypeChange {
+		t.Errorf("ticket: got %s %s, want GRILL CHANGE", created[0].Station.String, created[0].TicketType)
+	}
+	var lines []service.KitchenTicketLine
+	if err := json.Unmarshal(created[0].Lines, &lines); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].ItemID != removed.ID || lines[0].Change != enum.KitchenLineVoided {
+		t.Errorf("lines: got %+v, want the removed item voided", lines)
+	}
+
+	var ticketEvents []ws.Event
+	for _, e := range hub.events {
+		if e.event.Type == ws.EventKitchenTicketCreated {
+			ticketEvents = append(ticketEvents, e.event)
+		}
+	}
+	if len(ticketEvents) != 1 || len(ticketEvents[0].Stations) != 1 || ticketEvents[0].Stations[0] != enum.StationGrill {
+		t.Errorf("ticket events: got %+v", ticketEvents)
+	}
+}
+
+// Changing a dish on an order the kitchen is already preparing prints a
+// change ticket with the new quantity.
+func TestUpdateItem_PreparingOrderPrintsChangeTicket(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	item := database.OrderItem{
+		ID:             uuid.New(),
+		OrderID:        order.ID,
+		ProductID:      uuid.New(),
+		Quantity:       1,
+		UnitPrice:      testNumeric("25000.00"),
+		DiscountAmount: testNumeric("0.00"),
+		Subtotal:       testNumeric("25000.00"),
+		Status:         enum.OrderItemStatusPreparing,
+		Station:        pgtype.Text{String: enum.StationGrill, Valid: true},
+	}
+	current := database.ListKitchenTicketItemsRow{ID: item.ID, ProductName: "Nasi Bakar", VariantNames: []string{}, Quantity: 2, Station: item.Station}
+
+	first := []database.KitchenTicket{
+		testKitchenTicket(t, order.ID, 1, enum.StationGrill, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{
+			{ItemID: item.ID, Change: enum.KitchenLineAdded, Name: "Nasi Bakar", Variants: []string{}, Quantity: 1},
+		}),
+	}
+
+	var created []database.CreateKitchenTicketParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return item, nil
+		},
+		listOrderItemModifiersFn: func(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error) {
+			return []database.OrderItemModifier{}, nil
+		},
+		updateOrderItemFn: func(ctx context.Context, arg database.UpdateOrderItemParams) (database.OrderItem, error) {
+			updated := item
+			updated.Quantity = arg.Quantity
+			return updated, nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+		listKitchenTicketItemsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error) {
+			return []database.ListKitchenTicketItemsRow{current}, nil
+		},
+		listKitchenTicketsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+			return first, nil
+		},
+		createKitchenTicketFn: func(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+			created = append(created, arg)
+			return database.KitchenTicket{ID: uuid.New(), OrderID: arg.OrderID, Sequence: 2, Station: arg.Station, TicketType: arg.TicketType, Lines: arg.Lines}, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	router := setupOrderRouterWithHub(nil, store, hub)
+
+	rr := doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+item.ID.String(), map[string]interface{}{
+		"quantity": 2,
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(created) != 1 {
+		t.Fatalf("tickets created: got %d, want 1", len(created))
+	}
+	if created[0].Station.String != enum.StationGrill || created[0].TicketType != enum.KitchenTicketTypeChange {
+		t.Errorf("ticket: got %s %s, want GRILL CHANGE", created[0].Station.String, created[0].TicketType)
+	}
+	var lines []service.KitchenTicketLine
+	if err := json.Unmarshal(created[0].Lines, &lines); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].Change != enum.KitchenLineQuantityChanged || lines[0].Quantity != 2 || lines[0].PreviousQuantity != 1 {
+		t.Errorf("lines: got %+v, want quantity changed 1 -> 2", lines)
+	}
+	if !slices.Contains(hub.types(), ws.EventKitchenTicketCreated) {
+		t.Errorf("events: got %v, want a kitchen ticket", hub.types())
+	}
+}
+
+// Cancelling an order the kitchen is preparing voids every dish still on
+// its tickets, one change ticket per station.
+func TestOrderCancel_PrintsVoidTickets(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	cancelled := order
+	cancelled.Status = enum.OrderStatusCancelled
+	nasi, teh := uuid.New(), uuid.New()
+
+	first := []database.KitchenTicket{
+		testKitchenTicket(t, order.ID, 1, enum.StationBeverage, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{
+			{ItemID: teh, Change: enum.KitchenLineAdded, Name: "Es Teh", Variants: []string{}, Quantity: 1},
+		}),
+		testKitchenTicket(t, order.ID, 2, enum.StationGrill, enum.KitchenTicketTypeNew, []service.KitchenTicketLine{
+			{ItemID: nasi, Change: enum.KitchenLineAdded, Name: "Nasi Bakar", Variants: []string{}, Quantity: 2},
+		}),
+	}
+
+	var created []database.CreateKitchenTicketParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		cancelOrderFn: func(ctx context.Context, arg database.CancelOrderParams) (database.Order, error) {
+			return cancelled, nil
+		},
+		listKitchenTicketsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+			return first, nil
+		},
+		createKitchenTicketFn: func(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+			created = append(created, arg)
+			return database.KitchenTicket{ID: uuid.New(), OrderID: arg.OrderID, Sequence: int32(2 + len(created)), Station: arg.Station, TicketType: arg.TicketType, Lines: arg.Lines}, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+	router := setupOrderRouterWithHub(nil, store, hub)
+
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+order.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(created) != 2 {
+		t.Fatalf("tickets created: got %d, want 2", len(created))
+	}
+	for i, want := range []struct {
+		station string
+		item    uuid.UUID
+	}{{enum.StationBeverage, teh}, {enum.StationGrill, nasi}} {
+		if created[i].Station.String != want.station || created[i].TicketType != enum.KitchenTicketTypeChange {
+			t.Errorf("ticket %d: got %s %s, want %s CHANGE", i, created[i].Station.String, created[i].TicketType, want.station)
+		}
+		var lines []service.KitchenTicketLine
+		if err := json.Unmarshal(created[i].Lines, &lines); err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 || lines[0].ItemID != want.item || lines[0].Change != enum.KitchenLineVoided {
+			t.Errorf("ticket %d lines: got %+v, want the dish voided", i, lines)
+		}
+	}
+}
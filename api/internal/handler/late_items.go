@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// LateItemStore defines the database methods needed by LateItemChecker.
+type LateItemStore interface {
+	orderDetailStore
+	GetOrderItem(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error)
+	ClaimLateOrderItems(ctx context.Context) ([]database.ClaimLateOrderItemsRow, error)
+}
+
+// lateItemResponse explains why an item was flagged in an order_item.late event.
+type lateItemResponse struct {
+	PreparationTime int32     `json:"preparation_time"` // target, minutes
+	SentAt          time.Time `json:"sent_at"`
+	DueAt           time.Time `json:"due_at"`
+}
+
+// LateItemChecker alerts kitchen displays about items that have been in the
+// kitchen longer than their product's preparation time.
+type LateItemChecker struct {
+	store LateItemStore
+	hub   Broadcaster
+}
+
+// NewLateItemChecker creates a new LateItemChecker.
+func NewLateItemChecker(store LateItemStore, hub Broadcaster) *LateItemChecker {
+	return &LateItemChecker{store: store, hub: hub}
+}
+
+// Run checks for late items every interval until ctx is cancelled.
+// Items are claimed in the database, so running it on every replica is safe.
+// This should be called as a goroutine: go checker.Run(ctx, time.Minute)
+func (c *LateItemChecker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check claims every newly late item and broadcasts an order_item.late event for it.
+// Failures are logged; a claimed item whose event fails is not alerted again.
+func (c *LateItemChecker) Check(ctx context.Context) {
+	rows, err := c.store.ClaimLateOrderItems(ctx)
+	if err != nil {
+		log.Printf("ERROR: claim late order items: %v", err)
+		return
+	}
+
+	for _, row := range rows {
+		c.publish(ctx, row)
+	}
+}
+
+func (c *LateItemChecker) publish(ctx context.Context, row database.ClaimLateOrderItemsRow) {
+	item, err := c.store.GetOrderItem(ctx, database.GetOrderItemParams{ID: row.ID, OrderID: row.OrderID})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) { // removed since it was claimed
+			log.Printf("ERROR: get late order item %s: %v", row.ID, err)
+		}
+		return
+	}
+	mods, err := c.store.ListOrderItemModifiersByOrderItem(ctx, item.ID)
+	if err != nil {
+		log.Printf("ERROR: list modifiers for late order item %s: %v", row.ID, err)
+		return
+	}
+	itemResp := dbOrderItemToResponse(item, mods)
+
+	late := &lateItemResponse{
+		PreparationTime: row.PreparationTime.Int32,
+		SentAt:          row.SentAt,
+		DueAt:           row.SentAt.Add(time.Duration(row.PreparationTime.Int32) * time.Minute),
+	}
+	publishOrderEvent(ctx, c.hub, c.store, ws.EventOrderItemLate, row.OutletID, row.OrderID, orderEventPayload{
+		Item: &itemResp,
+		Late: late,
+	})
+}
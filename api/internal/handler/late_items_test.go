@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+type mockLateItemStore struct {
+	mockOrderStore
+	claimLateFn func(ctx context.Context) ([]database.ClaimLateOrderItemsRow, error)
+}
+
+func (m *mockLateItemStore) ClaimLateOrderItems(ctx context.Context) ([]database.ClaimLateOrderItemsRow, error) {
+	return m.claimLateFn(ctx)
+}
+
+func TestLateItemChecker_PublishesLateItems(t *testing.T) {
+	outletID := uuid.New()
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	item := testDBOrderItem(order.ID)
+	item.Station = pgtype.Text{String: enum.StationGrill, Valid: true}
+	sentAt := time.Now().Add(-20 * time.Minute).UTC().Truncate(time.Second)
+	item.SentAt = sentAt
+
+	store := &mockLateItemStore{
+		mockOrderStore: mockOrderStore{
+			getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+				return order, nil
+			},
+			getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+				return item, nil
+			},
+			listOrderItemsByOrderFn: func(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+				return []database.OrderItem{item}, nil
+			},
+		},
+		claimLateFn: func(ctx context.Context) ([]database.ClaimLateOrderItemsRow, error) {
+			return []database.ClaimLateOrderItemsRow{{
+				ID:              item.ID,
+				OrderID:         order.ID,
+				OutletID:        outletID,
+				ProductName:     "Nasi Bakar Ayam",
+				PreparationTime: pgtype.Int4{Int32: 12, Valid: true},
+				SentAt:          sentAt,
+			}}, nil
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	handler.NewLateItemChecker(store, hub).Check(context.Background())
+
+	if got := hub.types(); len(got) != 1 || got[0] != ws.EventOrderItemLate {
+		t.Fatalf("events: got %v, want [%s]", got, ws.EventOrderItemLate)
+	}
+	e := hub.events[0]
+	if e.outletID != outletID {
+		t.Errorf("outlet: got %s, want %s", e.outletID, outletID)
+	}
+	if len(e.event.Stations) != 1 || e.event.Stations[0] != enum.StationGrill {
+		t.Errorf("stations: got %v, want [%s]", e.event.Stations, enum.StationGrill)
+	}
+
+	payload := decodeEventPayload(t, e.event)
+	if payload["item"].(map[string]interface{})["id"] != item.ID.String() {
+		t.Errorf("item id: got %v, want %s", payload["item"].(map[string]interface{})["id"], item.ID)
+	}
+	late := payload["late"].(map[string]interface{})
+	if late["preparation_time"] != float64(12) {
+		t.Errorf("preparation_time: got %v, want 12", late["preparation_time"])
+	}
+	if want := sentAt.Add(12 * time.Minute).Format(time.RFC3339); late["due_at"] != want {
+		t.Errorf("due_at: got %v, want %s", late["due_at"], want)
+	}
+}
+
+func TestLateItemChecker_ClaimErrorPublishesNothing(t *testing.T) {
+	store := &mockLateItemStore{
+		claimLateFn: func(ctx context.Context) ([]database.ClaimLateOrderItemsRow, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	hub := &mockBroadcaster{}
+
+	handler.NewLateItemChecker(store, hub).Check(context.Background())
+
+	if got := hub.types(); len(got) != 0 {
+		t.Errorf("events: got %v, want none", got)
+	}
+}
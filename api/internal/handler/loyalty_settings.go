@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+// Largest tier multiplier, matching the DECIMAL(4,2) columns
+var maxLoyaltyMultiplier = decimal.RequireFromString("99.99")
+
+// LoyaltySettingsStore defines the database methods needed by loyalty settings handlers.
+// Satisfied by *database.Queries; narrow interface for testability.
+type LoyaltySettingsStore interface {
+	GetLoyaltySettings(ctx context.Context, outletID uuid.UUID) (database.LoyaltySetting, error)
+	UpsertLoyaltySettings(ctx context.Context, arg database.UpsertLoyaltySettingsParams) (database.LoyaltySetting, error)
+}
+
+// LoyaltySettingsHandler handles the per-outlet loyalty program settings:
+// earn rates, point value, expiry and tiers.
+type LoyaltySettingsHandler struct {
+	store LoyaltySettingsStore
+}
+
+// NewLoyaltySettingsHandler creates a new LoyaltySettingsHandler.
+func NewLoyaltySettingsHandler(store LoyaltySettingsStore) *LoyaltySettingsHandler {
+	return &LoyaltySettingsHandler{store: store}
+}
+
+// RegisterRoutes registers loyalty settings endpoints on the given Chi router.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/loyalty
+func (h *LoyaltySettingsHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.Get)
+	r.Put("/", h.Update)
+}
+
+// --- Request / Response types ---
+
+type updateLoyaltySettingsRequest struct {
+	IsEnabled        bool              `json:"is_enabled"`
+	EarnRates        map[string]string `json:"earn_rates"` // order type -> spend per point
+	PointValue       string            `json:"point_value"`
+	PointsExpiryDays *int32            `json:"points_expiry_days"`
+	SilverMinSpend   string            `json:"silver_min_spend"`
+	SilverMultiplier string            `json:"silver_multiplier"`
+	GoldMinSpend     string            `json:"gold_min_spend"`
+	GoldMultiplier   string            `json:"gold_multiplier"`
+}
+
+type loyaltySettingsResponse struct {
+	OutletID         uuid.UUID         `json:"outlet_id"`
+	IsEnabled        bool              `json:"is_enabled"`
+	EarnRates        map[string]string `json:"earn_rates"`
+	PointValue       string            `json:"point_value"`
+	PointsExpiryDays *int32            `json:"points_expiry_days"`
+	SilverMinSpend   *string           `json:"silver_min_spend"`
+	SilverMultiplier string            `json:"silver_multiplier"`
+	GoldMinSpend     *string           `json:"gold_min_spend"`
+	GoldMultiplier   string            `json:"gold_multiplier"`
+	UpdatedAt        *time.Time        `json:"updated_at"`
+}
+
+func toLoyaltySettingsResponse(s database.LoyaltySetting) loyaltySettingsResponse {
+	resp := loyaltySettingsResponse{
+		OutletID:         s.OutletID,
+		IsEnabled:        s.IsEnabled,
+		EarnRates:        map[string]string{},
+		PointValue:       numericToString(s.PointValue),
+		SilverMultiplier: numericToString(s.SilverMultiplier),
+		GoldMultiplier:   numericToString(s.GoldMultiplier),
+		UpdatedAt:        &s.UpdatedAt,
+	}
+	if err := json.Unmarshal(s.EarnRates, &resp.EarnRates); err != nil {
+		log.Printf("WARN: parse loyalty earn rates for outlet %s: %v", s.OutletID, err)
+	}
+	if s.PointsExpiryDays.Valid {
+		resp.PointsExpiryDays = &s.PointsExpiryDays.Int32
+	}
+	if s.SilverMinSpend.Valid {
+		v := numericToString(s.SilverMinSpend)
+		resp.SilverMinSpend = &v
+	}
+	if s.GoldMinSpend.Valid {
+		v := numericToString(s.GoldMinSpend)
+		resp.GoldMinSpend = &v
+	}
+	return resp
+}
+
+// defaultLoyaltySettingsResponse is returned for outlets that have never
+// saved loyalty settings: no program, so nothing is earned or redeemable.
+func defaultLoyaltySettingsResponse(outletID uuid.UUID) loyaltySettingsResponse {
+	return loyaltySettingsResponse{
+		OutletID:         outletID,
+		EarnRates:        map[string]string{},
+		PointValue:       "0.00",
+		SilverMultiplier: "1.00",
+		GoldMultiplier:   "1.00",
+	}
+}
+
+// --- Handlers ---
+
+// Get returns the outlet's loyalty program settings.
+func (h *LoyaltySettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	settings, err := h.store.GetLoyaltySettings(r.Context(), outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusOK, defaultLoyaltySettingsResponse(outletID))
+			return
+		}
+		log.Printf("ERROR: get loyalty settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toLoyaltySettingsResponse(settings))
+}
+
+// Update replaces the outlet's loyalty program settings. Points already
+// earned keep the expiry they were earned with.
+func (h *LoyaltySettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var req updateLoyaltySettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	params, err := parseLoyaltySettingsRequest(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	params.OutletID = outletID
+
+	settings, err := h.store.UpsertLoyaltySettings(r.Context(), params)
+	if err != nil {
+		log.Printf("ERROR: upsert loyalty settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toLoyaltySettingsResponse(settings))
+}
+
+// --- Helpers ---
+
+// parseLoyaltySettingsRequest validates a settings update. Empty amounts mean
+// their defaults: no redemption, no tier, and a multiplier of 1.
+func parseLoyaltySettingsRequest(req updateLoyaltySettingsRequest) (database.UpsertLoyaltySettingsParams, error) {
+	params := database.UpsertLoyaltySettingsParams{IsEnabled: req.IsEnabled}
+
+	rates := make(map[string]string, len(req.EarnRates))
+	for orderType, rate := range req.EarnRates {
+		if !isValidOrderType(orderType) {
+			return params, errors.New("invalid order type in earn_rates: " + orderType)
+		}
+		d, err := decimal.NewFromString(rate)
+		if err != nil || !d.IsPositive() {
+			return params, errors.New("earn_rates must be the spend per point, greater than 0")
+		}
+		rates[orderType] = d.String()
+	}
+	earnRates, err := json.Marshal(rates)
+	if err != nil {
+		return params, err
+	}
+	params.EarnRates = earnRates
+
+	pointValue := decimal.Zero
+	if req.PointValue != "" {
+		pointValue, err = decimal.NewFromString(req.PointValue)
+		if err != nil || pointValue.IsNegative() {
+			return params, errors.New("point_value must be 0 or more")
+		}
+	}
+	params.PointValue = decimalToNumeric(pointValue)
+
+	if req.PointsExpiryDays != nil {
+		if *req.PointsExpiryDays <= 0 {
+			return params, errors.New("points_expiry_days must be > 0")
+		}
+		params.PointsExpiryDays = pgtype.Int4{Int32: *req.PointsExpiryDays, Valid: true}
+	}
+
+	silverMin, err := parseTierMinSpend(req.SilverMinSpend, "silver_min_spend")
+	if err != nil {
+		return params, err
+	}
+	goldMin, err := parseTierMinSpend(req.GoldMinSpend, "gold_min_spend")
+	if err != nil {
+		return params, err
+	}
+	if silverMin.IsPositive() && goldMin.IsPositive() && !goldMin.GreaterThan(silverMin) {
+		return params, errors.New("gold_min_spend must be greater than silver_min_spend")
+	}
+	if silverMin.IsPositive() {
+		params.SilverMinSpend = decimalToNumeric(silverMin)
+	}
+	if goldMin.IsPositive() {
+		params.GoldMinSpend = decimalToNumeric(goldMin)
+	}
+
+	if params.SilverMultiplier, err = parseTierMultiplier(req.SilverMultiplier, "silver_multiplier"); err != nil {
+		return params, err
+	}
+	if params.GoldMultiplier, err = parseTierMultiplier(req.GoldMultiplier, "gold_multiplier"); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// parseTierMinSpend parses the lifetime spend that reaches a tier. Empty
+// means the tier is not offered, returned as zero.
+func parseTierMinSpend(s, field string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil || !d.IsPositive() {
+		return decimal.Zero, errors.New(field + " must be > 0")
+	}
+	return d, nil
+}
+
+// parseTierMultiplier parses a tier's points multiplier. Empty means 1.
+func parseTierMultiplier(s, field string) (pgtype.Numeric, error) {
+	d := decimal.NewFromInt(1)
+	if s != "" {
+		var err error
+		d, err = decimal.NewFromString(s)
+		if err != nil || d.LessThan(decimal.NewFromInt(1)) || d.GreaterThan(maxLoyaltyMultiplier) {
+			return pgtype.Numeric{}, errors.New(field + " must be between 1 and 99.99")
+		}
+	}
+	return decimalToNumeric(d), nil
+}
@@ -0,0 +1,332 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock store ---
+
+// mockLoyaltyStore is an in-memory loyalty ledger, embedded in the order,
+// payment and customer store mocks. The zero value is ready to use and has
+// no loyalty program.
+type mockLoyaltyStore struct {
+	loyaltySettings map[uuid.UUID]database.LoyaltySetting // keyed by outlet ID
+	loyaltyTxns     []database.LoyaltyTransaction
+	lifetimeSpend   decimal.Decimal
+	eligibleSpend   decimal.Decimal
+}
+
+// enableLoyalty sets up a program for outletID: 1 point per 10,000 spent on
+// dine-in orders, each point worth 100 when redeemed.
+func (m *mockLoyaltyStore) enableLoyalty(outletID uuid.UUID) {
+	if m.loyaltySettings == nil {
+		m.loyaltySettings = make(map[uuid.UUID]database.LoyaltySetting)
+	}
+	m.loyaltySettings[outletID] = database.LoyaltySetting{
+		OutletID:         outletID,
+		IsEnabled:        true,
+		EarnRates:        []byte(`{"DINE_IN": "10000"}`),
+		PointValue:       decimalToNumeric(decimal.NewFromInt(100)),
+		PointsExpiryDays: pgtype.Int4{Int32: 365, Valid: true},
+		SilverMultiplier: decimalToNumeric(decimal.NewFromInt(1)),
+		GoldMultiplier:   decimalToNumeric(decimal.NewFromInt(1)),
+	}
+}
+
+// addPoints credits customerID with points, as if earned earlier.
+func (m *mockLoyaltyStore) addPoints(customerID uuid.UUID, points int32) {
+	m.loyaltyTxns = append(m.loyaltyTxns, database.LoyaltyTransaction{
+		ID:              uuid.New(),
+		CustomerID:      customerID,
+		TransactionType: enum.LoyaltyTransactionEarn,
+		Points:          points,
+		CreatedAt:       time.Now(),
+	})
+}
+
+func (m *mockLoyaltyStore) pointsBalance(customerID uuid.UUID) int32 {
+	var balance int32
+	for _, t := range m.loyaltyTxns {
+		if t.CustomerID == customerID {
+			balance += t.Points
+		}
+	}
+	return balance
+}
+
+func (m *mockLoyaltyStore) GetLoyaltySettings(_ context.Context, outletID uuid.UUID) (database.LoyaltySetting, error) {
+	s, ok := m.loyaltySettings[outletID]
+	if !ok {
+		return database.LoyaltySetting{}, pgx.ErrNoRows
+	}
+	return s, nil
+}
+
+func (m *mockLoyaltyStore) UpsertLoyaltySettings(_ context.Context, arg database.UpsertLoyaltySettingsParams) (database.LoyaltySetting, error) {
+	if m.loyaltySettings == nil {
+		m.loyaltySettings = make(map[uuid.UUID]database.LoyaltySetting)
+	}
+	s := database.LoyaltySetting{
+		OutletID:         arg.OutletID,
+		IsEnabled:        arg.IsEnabled,
+		EarnRates:        arg.EarnRates,
+		PointValue:       arg.PointValue,
+		PointsExpiryDays: arg.PointsExpiryDays,
+		SilverMinSpend:   arg.SilverMinSpend,
+		SilverMultiplier: arg.SilverMultiplier,
+		GoldMinSpend:     arg.GoldMinSpend,
+		GoldMultiplier:   arg.GoldMultiplier,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	m.loyaltySettings[arg.OutletID] = s
+	return s, nil
+}
+
+func (m *mockLoyaltyStore) GetCustomerLoyalty(_ context.Context, customerID uuid.UUID) (database.GetCustomerLoyaltyRow, error) {
+	return database.GetCustomerLoyaltyRow{
+		PointsBalance: m.pointsBalance(customerID),
+		LifetimeSpend: decimalToNumeric(m.lifetimeSpend),
+	}, nil
+}
+
+func (m *mockLoyaltyStore) LockCustomer(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	return id, nil
+}
+
+func (m *mockLoyaltyStore) ExpireCustomerPoints(_ context.Context, _ uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockLoyaltyStore) CreateLoyaltyTransaction(_ context.Context, arg database.CreateLoyaltyTransactionParams) (database.LoyaltyTransaction, error) {
+	if arg.TransactionType == enum.LoyaltyTransactionEarn {
+		for _, t := range m.loyaltyTxns {
+			if t.TransactionType == enum.LoyaltyTransactionEarn && t.OrderID == arg.OrderID {
+				return database.LoyaltyTransaction{}, pgx.ErrNoRows
+			}
+		}
+	}
+	t := database.LoyaltyTransaction{
+		ID:              uuid.New(),
+		CustomerID:      arg.CustomerID,
+		OrderID:         arg.OrderID,
+		PaymentID:       arg.PaymentID,
+		TransactionType: arg.TransactionType,
+		Points:          arg.Points,
+		Amount:          arg.Amount,
+		ExpiresAt:       arg.ExpiresAt,
+		CreatedBy:       arg.CreatedBy,
+		CreatedAt:       time.Now(),
+	}
+	m.loyaltyTxns = append(m.loyaltyTxns, t)
+	return t, nil
+}
+
+func (m *mockLoyaltyStore) GetOrderEligibleSpend(_ context.Context, _ uuid.UUID) (pgtype.Numeric, error) {
+	return decimalToNumeric(m.eligibleSpend), nil
+}
+
+func (m *mockLoyaltyStore) GetOrderEarnedPoints(_ context.Context, orderID uuid.UUID) (database.GetOrderEarnedPointsRow, error) {
+	var row database.GetOrderEarnedPointsRow
+	earnAmount := decimal.Zero
+	for _, t := range m.loyaltyTxns {
+		if !t.OrderID.Valid || uuid.UUID(t.OrderID.Bytes) != orderID {
+			continue
+		}
+		switch t.TransactionType {
+		case enum.LoyaltyTransactionEarn:
+			row.EarnedPoints += t.Points
+			amt, _ := numericToDecimal(t.Amount)
+			earnAmount = earnAmount.Add(amt)
+			row.EarnExpiresAt = t.ExpiresAt
+		case enum.LoyaltyTransactionEarnReversal:
+			row.ReversedPoints -= t.Points
+		}
+	}
+	row.EarnAmount = decimalToNumeric(earnAmount)
+	return row, nil
+}
+
+func (m *mockLoyaltyStore) GetPaymentLoyaltyPoints(_ context.Context, paymentID uuid.UUID) (database.GetPaymentLoyaltyPointsRow, error) {
+	var row database.GetPaymentLoyaltyPointsRow
+	for _, t := range m.loyaltyTxns {
+		if !t.PaymentID.Valid || uuid.UUID(t.PaymentID.Bytes) != paymentID {
+			continue
+		}
+		if t.TransactionType == enum.LoyaltyTransactionRedeem {
+			row.RedeemedPoints -= t.Points
+		}
+		row.OutstandingPoints -= t.Points
+	}
+	return row, nil
+}
+
+func (m *mockLoyaltyStore) ListOrderRedeemedPoints(_ context.Context, orderID uuid.UUID) ([]database.ListOrderRedeemedPointsRow, error) {
+	byPayment := make(map[uuid.UUID]*database.ListOrderRedeemedPointsRow)
+	amounts := make(map[uuid.UUID]decimal.Decimal)
+	for _, t := range m.loyaltyTxns {
+		if !t.OrderID.Valid || uuid.UUID(t.OrderID.Bytes) != orderID {
+			continue
+		}
+		if t.TransactionType != enum.LoyaltyTransactionRedeem && t.TransactionType != enum.LoyaltyTransactionRedeemReversal {
+			continue
+		}
+		paymentID := uuid.UUID(t.PaymentID.Bytes)
+		row, ok := byPayment[paymentID]
+		if !ok {
+			row = &database.ListOrderRedeemedPointsRow{PaymentID: paymentID}
+			byPayment[paymentID] = row
+		}
+		row.OutstandingPoints -= t.Points
+		amt, _ := numericToDecimal(t.Amount)
+		if t.TransactionType == enum.LoyaltyTransactionRedeem {
+			amounts[paymentID] = amounts[paymentID].Add(amt)
+		} else {
+			amounts[paymentID] = amounts[paymentID].Sub(amt)
+		}
+	}
+	result := []database.ListOrderRedeemedPointsRow{}
+	for paymentID, row := range byPayment {
+		if row.OutstandingPoints > 0 {
+			row.OutstandingAmount = decimalToNumeric(amounts[paymentID])
+			result = append(result, *row)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockLoyaltyStore) ListCustomerLoyaltyTransactions(_ context.Context, arg database.ListCustomerLoyaltyTransactionsParams) ([]database.LoyaltyTransaction, error) {
+	var result []database.LoyaltyTransaction
+	for _, t := range m.loyaltyTxns {
+		if t.CustomerID == arg.CustomerID {
+			result = append(result, t)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	start := min(int(arg.Offset), len(result))
+	end := min(start+int(arg.Limit), len(result))
+	return result[start:end], nil
+}
+
+// --- Helpers ---
+
+func setupLoyaltySettingsRouter(store *mockLoyaltyStore) *chi.Mux {
+	h := handler.NewLoyaltySettingsHandler(store)
+	r := chi.NewRouter()
+	r.Route("/outlets/{oid}/loyalty", h.RegisterRoutes)
+	return r
+}
+
+func decodeLoyaltySettingsResponse(t *testing.T, rr *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+// --- Tests ---
+
+func TestLoyaltySettingsGet_Defaults(t *testing.T) {
+	router := setupLoyaltySettingsRouter(&mockLoyaltyStore{})
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "GET", "/outlets/"+outletID.String()+"/loyalty", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	resp := decodeLoyaltySettingsResponse(t, rr)
+	if resp["is_enabled"] != false {
+		t.Errorf("is_enabled: got %v, want false", resp["is_enabled"])
+	}
+	if resp["point_value"] != "0.00" {
+		t.Errorf("point_value: got %v, want 0.00", resp["point_value"])
+	}
+}
+
+func TestLoyaltySettingsUpdate_Valid(t *testing.T) {
+	store := &mockLoyaltyStore{}
+	router := setupLoyaltySettingsRouter(store)
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/loyalty", map[string]interface{}{
+		"is_enabled":         true,
+		"earn_rates":         map[string]string{"DINE_IN": "10000", "TAKEAWAY": "20000"},
+		"point_value":        "100",
+		"points_expiry_days": 365,
+		"silver_min_spend":   "1000000",
+		"silver_multiplier":  "1.5",
+		"gold_min_spend":     "5000000",
+		"gold_multiplier":    "2",
+	})
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	resp := decodeLoyaltySettingsResponse(t, rr)
+	rates, _ := resp["earn_rates"].(map[string]interface{})
+	if rates["TAKEAWAY"] != "20000" {
+		t.Errorf("earn_rates: got %v, want TAKEAWAY 20000", resp["earn_rates"])
+	}
+	if resp["silver_multiplier"] != "1.50" {
+		t.Errorf("silver_multiplier: got %v, want 1.50", resp["silver_multiplier"])
+	}
+	if resp["points_expiry_days"] != float64(365) {
+		t.Errorf("points_expiry_days: got %v, want 365", resp["points_expiry_days"])
+	}
+
+	// Persisted and returned by Get
+	rr = doRequest(t, router, "GET", "/outlets/"+outletID.String()+"/loyalty", nil)
+	resp = decodeLoyaltySettingsResponse(t, rr)
+	if resp["gold_min_spend"] != "5000000.00" {
+		t.Errorf("gold_min_spend: got %v, want 5000000.00", resp["gold_min_spend"])
+	}
+}
+
+func TestLoyaltySettingsUpdate_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"unknown order type", map[string]interface{}{"earn_rates": map[string]string{"DRIVE_THRU": "10000"}}},
+		{"zero earn rate", map[string]interface{}{"earn_rates": map[string]string{"DINE_IN": "0"}}},
+		{"negative point value", map[string]interface{}{"point_value": "-1"}},
+		{"zero expiry", map[string]interface{}{"points_expiry_days": 0}},
+		{"multiplier below 1", map[string]interface{}{"gold_multiplier": "0.5"}},
+		{"gold below silver", map[string]interface{}{"silver_min_spend": "5000000", "gold_min_spend": "1000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockLoyaltyStore{}
+			router := setupLoyaltySettingsRouter(store)
+			outletID := uuid.New()
+
+			rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/loyalty", tt.body)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+			if len(store.loyaltySettings) != 0 {
+				t.Error("expected settings not to be saved")
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+// comboOrder is an order with one combo line and its two components.
+type comboOrder struct {
+	order      database.Order
+	combo      database.OrderItem
+	components map[uuid.UUID]*database.OrderItem
+}
+
+func newComboOrder(outletID uuid.UUID, componentStatuses ...string) *comboOrder {
+	c := &comboOrder{
+		order:      testDBOrderWithStatus(outletID, enum.OrderStatusPreparing),
+		components: map[uuid.UUID]*database.OrderItem{},
+	}
+	c.combo = testDBOrderItem(c.order.ID)
+	for _, status := range componentStatuses {
+		child := testDBOrderItem(c.order.ID)
+		child.Status = status
+		child.ParentItemID = pgtype.UUID{Bytes: c.combo.ID, Valid: true}
+		c.components[child.ID] = &child
+	}
+	return c
+}
+
+func (c *comboOrder) store() *mockOrderStore {
+	return &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return c.order, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			if arg.ID == c.combo.ID {
+				return c.combo, nil
+			}
+			return *c.components[arg.ID], nil
+		},
+		listChildOrderItemsFn: func(ctx context.Context, parentItemID pgtype.UUID) ([]database.OrderItem, error) {
+			if parentItemID.Bytes != c.combo.ID {
+				return []database.OrderItem{}, nil
+			}
+			var children []database.OrderItem
+			for _, child := range c.components {
+				children = append(children, *child)
+			}
+			return children, nil
+		},
+		updateOrderItemStatusFn: func(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error) {
+			if arg.ID == c.combo.ID {
+				c.combo.Status = arg.Status
+				return c.combo, nil
+			}
+			c.components[arg.ID].Status = arg.Status
+			return *c.components[arg.ID], nil
+		},
+	}
+}
+
+func (c *comboOrder) componentWithStatus(status string) uuid.UUID {
+	for id, child := range c.components {
+		if child.Status == status {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+func TestUpdateItemStatus_ComboReadyWhenAllComponentsReady(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	c := newComboOrder(outletID, enum.OrderItemStatusReady, enum.OrderItemStatusPreparing)
+	c.combo.Status = enum.OrderItemStatusPreparing
+
+	router := setupOrderRouterWithStore(nil, c.store(), claims)
+	last := c.componentWithStatus(enum.OrderItemStatusPreparing)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+c.order.ID.String()+"/items/"+last.String()+"/status", map[string]string{
+		"status": enum.OrderItemStatusReady,
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp struct {
+		ParentItemID *string `json:"parent_item_id"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ParentItemID == nil || *resp.ParentItemID != c.combo.ID.String() {
+		t.Errorf("parent_item_id: got %v, want %s", resp.ParentItemID, c.combo.ID)
+	}
+	if c.combo.Status != enum.OrderItemStatusReady {
+		t.Errorf("combo status: got %s, want %s", c.combo.Status, enum.OrderItemStatusReady)
+	}
+}
+
+func TestUpdateItemStatus_ComboPreparingWhileComponentsPending(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	c := newComboOrder(outletID, enum.OrderItemStatusPending, enum.OrderItemStatusPending)
+
+	router := setupOrderRouterWithStore(nil, c.store(), claims)
+	first := c.componentWithStatus(enum.OrderItemStatusPending)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+c.order.ID.String()+"/items/"+first.String()+"/status", map[string]string{
+		"status": enum.OrderItemStatusPreparing,
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if c.combo.Status != enum.OrderItemStatusPreparing {
+		t.Errorf("combo status: got %s, want %s", c.combo.Status, enum.OrderItemStatusPreparing)
+	}
+}
+
+func TestUpdateItemStatus_ComboLineRejected(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	c := newComboOrder(outletID, enum.OrderItemStatusPending, enum.OrderItemStatusPending)
+
+	router := setupOrderRouterWithStore(nil, c.store(), claims)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+c.order.ID.String()+"/items/"+c.combo.ID.String()+"/status", map[string]string{
+		"status": enum.OrderItemStatusPreparing,
+	}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if c.combo.Status != enum.OrderItemStatusPending {
+		t.Errorf("combo status changed to %s", c.combo.Status)
+	}
+}
+
+func TestRemoveItem_ComboComponentRejected(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	c := newComboOrder(outletID, enum.OrderItemStatusPending)
+	c.order.Status = enum.OrderStatusNew
+
+	store := c.store()
+	store.countOrderItemsFn = func(ctx context.Context, orderID uuid.UUID) (int64, error) {
+		return 2, nil
+	}
+	store.deleteOrderItemFn = func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+		t.Error("component should not be deleted on its own")
+		return nil
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	child := c.componentWithStatus(enum.OrderItemStatusPending)
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+c.order.ID.String()+"/items/"+child.String(), nil, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
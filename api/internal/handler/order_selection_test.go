@@ -0,0 +1,223 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+type selectionErrorBody struct {
+	Error string `json:"error"`
+	Items []struct {
+		Index      int    `json:"index"`
+		ProductID  string `json:"product_id"`
+		Violations []struct {
+			GroupID   string `json:"group_id"`
+			GroupName string `json:"group_name"`
+			Rule      string `json:"rule"`
+			Message   string `json:"message"`
+		} `json:"violations"`
+	} `json:"items"`
+}
+
+func decodeSelectionError(t *testing.T, body []byte) selectionErrorBody {
+	t.Helper()
+	var resp selectionErrorBody
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func TestAddItem_MissingRequiredVariant(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	productID := uuid.New()
+	spiceGroup := uuid.New()
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getProductForOrderFn: func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error) {
+			return database.GetProductForOrderRow{ID: productID, OutletID: outletID, BasePrice: testNumeric("25000.00")}, nil
+		},
+		listVariantGroupsFn: func(ctx context.Context, pid uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error) {
+			return []database.ListVariantGroupsForOrderRow{{ID: spiceGroup, Name: "Level Pedas", IsRequired: true}}, nil
+		},
+		createOrderItemFn: func(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error) {
+			t.Error("item should not be created without its required variant")
+			return database.OrderItem{}, nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items", map[string]interface{}{
+		"product_id": productID.String(),
+		"quantity":   1,
+	}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	resp := decodeSelectionError(t, rr.Body.Bytes())
+	if len(resp.Items) != 1 || len(resp.Items[0].Violations) != 1 {
+		t.Fatalf("items: got %+v", resp.Items)
+	}
+	v := resp.Items[0].Violations[0]
+	if v.Rule != service.RuleVariantRequired || v.GroupID != spiceGroup.String() || v.GroupName != "Level Pedas" {
+		t.Errorf("violation: got %+v", v)
+	}
+	if resp.Items[0].ProductID != productID.String() {
+		t.Errorf("product_id: got %s, want %s", resp.Items[0].ProductID, productID)
+	}
+}
+
+func TestOrderCreate_SelectionErrorListsItems(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	productID := uuid.New()
+	toppingGroup := uuid.New()
+
+	svc := &mockOrderService{
+		createFn: func(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error) {
+			if got := req.Items[1].VariantIDs; len(got) != 2 {
+				t.Errorf("variant_ids not passed through: got %v", got)
+			}
+			return nil, &service.SelectionError{Items: []service.ItemSelectionError{{
+				Index:     1,
+				ProductID: productID,
+				Violations: []service.GroupViolation{{
+					GroupID:   toppingGroup,
+					GroupName: "Topping",
+					Rule:      service.RuleModifierMaxSelect,
+					Message:   "choose at most 2 from Topping",
+				}},
+			}}}
+		},
+	}
+
+	router := setupOrderRouterWithStore(svc, &mockOrderStore{}, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders", map[string]interface{}{
+		"order_type": "DINE_IN",
+		"items": []map[string]interface{}{
+			{"product_id": productID.String(), "quantity": 1},
+			{"product_id": productID.String(), "quantity": 1, "variant_ids": []string{uuid.New().String(), uuid.New().String()}},
+		},
+	}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	resp := decodeSelectionError(t, rr.Body.Bytes())
+	if resp.Error == "" || len(resp.Items) != 1 || resp.Items[0].Index != 1 {
+		t.Fatalf("response: got %+v", resp)
+	}
+	if resp.Items[0].Violations[0].Rule != service.RuleModifierMaxSelect {
+		t.Errorf("rule: got %s, want %s", resp.Items[0].Violations[0].Rule, service.RuleModifierMaxSelect)
+	}
+}
+
+func TestUpdateItem_ReplacesModifiers(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	toppingGroup := uuid.New()
+	oldModifier, keju := uuid.New(), uuid.New()
+
+	currentItem := testDBOrderItem(order.ID)
+	currentItem.UnitPrice = testNumeric("25000.00")
+	currentItem.Subtotal = testNumeric("58000.00")
+
+	var updated database.UpdateOrderItemParams
+	var deleted bool
+	var created []database.CreateOrderItemModifierParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return currentItem, nil
+		},
+		listOrderItemModifiersFn: func(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error) {
+			return []database.OrderItemModifier{
+				{ID: uuid.New(), OrderItemID: currentItem.ID, ModifierID: oldModifier, Quantity: 2, UnitPrice: testNumeric("4000.00")},
+			}, nil
+		},
+		getProductForOrderFn: func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error) {
+			return database.GetProductForOrderRow{ID: currentItem.ProductID, OutletID: outletID, BasePrice: testNumeric("26000.00")}, nil
+		},
+		getModifierForOrderFn: func(ctx context.Context, mid uuid.UUID) (database.GetModifierForOrderRow, error) {
+			return database.GetModifierForOrderRow{ID: mid, ModifierGroupID: toppingGroup, ProductID: currentItem.ProductID, Price: testNumeric("5000.00")}, nil
+		},
+		listModifierGroupsFn: func(ctx context.Context, pid uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error) {
+			return []database.ListModifierGroupsForOrderRow{
+				{ID: toppingGroup, Name: "Topping", MinSelect: 1, MaxSelect: pgtype.Int4{Int32: 2, Valid: true}},
+			}, nil
+		},
+		updateOrderItemFn: func(ctx context.Context, arg database.UpdateOrderItemParams) (database.OrderItem, error) {
+			updated = arg
+			item := currentItem
+			item.Quantity = arg.Quantity
+			item.UnitPrice = arg.UnitPrice
+			item.Subtotal = arg.Subtotal
+			return item, nil
+		},
+		deleteOrderItemModsFn: func(ctx context.Context, orderItemID uuid.UUID) error {
+			deleted = true
+			return nil
+		},
+		createOrderItemModifierFn: func(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error) {
+			created = append(created, arg)
+			return database.OrderItemModifier{ID: uuid.New(), OrderItemID: arg.OrderItemID, ModifierID: arg.ModifierID, Quantity: arg.Quantity, UnitPrice: arg.UnitPrice}, nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+currentItem.ID.String(), map[string]interface{}{
+		"quantity":  2,
+		"modifiers": []map[string]interface{}{{"modifier_id": keju.String(), "quantity": 1}},
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if !deleted || len(created) != 1 || created[0].ModifierID != keju {
+		t.Errorf("modifiers not replaced: deleted=%v created=%+v", deleted, created)
+	}
+	// Repriced at the current base price: 26000 * 2 + 5000
+	if got, _ := numericToDecimal(updated.Subtotal); !got.Equal(decimal.NewFromInt(57000)) {
+		t.Errorf("subtotal: got %s, want 57000", got)
+	}
+	if got, _ := numericToDecimal(updated.UnitPrice); !got.Equal(decimal.NewFromInt(26000)) {
+		t.Errorf("unit_price: got %s, want 26000", got)
+	}
+
+	// Dropping every topping breaks min_select and changes nothing
+	deleted = false
+	rr = doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/items/"+currentItem.ID.String(), map[string]interface{}{
+		"quantity":  2,
+		"modifiers": []map[string]interface{}{},
+	}, claims)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	if resp := decodeSelectionError(t, rr.Body.Bytes()); resp.Items[0].Violations[0].Rule != service.RuleModifierMinSelect {
+		t.Errorf("violations: got %+v", resp.Items)
+	}
+	if deleted {
+		t.Error("modifiers should be kept when the new selection is rejected")
+	}
+}
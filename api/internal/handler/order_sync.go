@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+const maxSyncBatchSize = 100
+
+// Per-order outcomes reported by the sync endpoint.
+const (
+	syncStatusCreated       = "created"
+	syncStatusAlreadySynced = "already_synced"
+	syncStatusConflict      = "conflict"
+)
+
+type syncOrdersRequest struct {
+	Orders []syncOrderRequest `json:"orders"`
+}
+
+type syncOrderRequest struct {
+	createOrderRequest
+	ID          string               `json:"id"`
+	CreatedAt   string               `json:"created_at"` // RFC3339, device time
+	TotalAmount string               `json:"total_amount"`
+	Payments    []syncPaymentRequest `json:"payments"`
+}
+
+type syncPaymentRequest struct {
+	PaymentMethod   string `json:"payment_method"`
+	Amount          string `json:"amount"`
+	AmountReceived  string `json:"amount_received"`
+	ReferenceNumber string `json:"reference_number"`
+	ProcessedAt     string `json:"processed_at"` // RFC3339; defaults to the order's created_at
+}
+
+type syncOrderResult struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	OrderNumber string `json:"order_number,omitempty"`
+	// Set when the order kept the device's total_amount although current
+	// prices give this total.
+	ServerTotalAmount string `json:"server_total_amount,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// Sync handles POST /outlets/{oid}/orders/sync.
+// Applies orders taken offline, oldest first, each in its own transaction.
+// Orders that were already applied are reported as already_synced, so a
+// device can resend a batch safely. Orders that no longer validate against
+// the current menu are reported as conflict and the rest still apply.
+func (h *OrderHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	var req syncOrdersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if len(req.Orders) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "orders are required"})
+		return
+	}
+	if len(req.Orders) > maxSyncBatchSize {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "too many orders in one batch"})
+		return
+	}
+
+	results := make([]syncOrderResult, 0, len(req.Orders))
+	var pending []service.SyncOrderRequest
+	for _, o := range req.Orders {
+		svcReq, err := o.toService(outletID, claims.UserID)
+		if err != nil {
+			results = append(results, syncOrderResult{ID: o.ID, Status: syncStatusConflict, Error: err.Error()})
+			continue
+		}
+		pending = append(pending, svcReq)
+	}
+
+	// Apply in the order the orders were taken so server-assigned order
+	// numbers follow the offline sequence.
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	for _, svcReq := range pending {
+		res, err := h.svc.SyncOrder(r.Context(), svcReq)
+		if err != nil {
+			if isValidationError(err) || isSyncConflict(err) {
+				results = append(results, syncOrderResult{ID: svcReq.ID.String(), Status: syncStatusConflict, Error: err.Error()})
+				continue
+			}
+			// Orders applied so far are committed; the device resends the
+			// batch and they come back as already_synced.
+			log.Printf("ERROR: sync order %s: %v", svcReq.ID, err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+
+		if res.AlreadySynced {
+			results = append(results, syncOrderResult{ID: svcReq.ID.String(), Status: syncStatusAlreadySynced, OrderNumber: res.Order.OrderNumber})
+			continue
+		}
+		result := syncOrderResult{ID: svcReq.ID.String(), Status: syncStatusCreated, OrderNumber: res.Order.OrderNumber}
+		if res.ServerTotal != nil {
+			result.ServerTotalAmount = res.ServerTotal.StringFixed(2)
+		}
+		results = append(results, result)
+		publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderCreated, outletID, res.Order.ID, orderEventPayload{})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// toService parses the device-supplied identifiers and times.
+func (o syncOrderRequest) toService(outletID, userID uuid.UUID) (service.SyncOrderRequest, error) {
+	id, err := uuid.Parse(o.ID)
+	if err != nil {
+		return service.SyncOrderRequest{}, errors.New("invalid id")
+	}
+	createdAt, err := time.Parse(time.RFC3339, o.CreatedAt)
+	if err != nil {
+		return service.SyncOrderRequest{}, errors.New("invalid created_at")
+	}
+
+	payments := make([]service.SyncPaymentRequest, len(o.Payments))
+	for i, p := range o.Payments {
+		var processedAt time.Time
+		if p.ProcessedAt != "" {
+			processedAt, err = time.Parse(time.RFC3339, p.ProcessedAt)
+			if err != nil {
+				return service.SyncOrderRequest{}, errors.New("invalid payments processed_at")
+			}
+		}
+		payments[i] = service.SyncPaymentRequest{
+			PaymentMethod:   p.PaymentMethod,
+			Amount:          p.Amount,
+			AmountReceived:  p.AmountReceived,
+			ReferenceNumber: p.ReferenceNumber,
+			ProcessedAt:     processedAt,
+		}
+	}
+
+	createReq := o.createOrderRequest.toService(outletID, userID)
+	createReq.ID = id
+	createReq.CreatedAt = createdAt
+	return service.SyncOrderRequest{
+		CreateOrderRequest: createReq,
+		TotalAmount:        o.TotalAmount,
+		Payments:           payments,
+	}, nil
+}
+
+func isSyncConflict(err error) bool {
+	return errors.Is(err, service.ErrSyncMissingID) ||
+		errors.Is(err, service.ErrSyncMissingCreatedAt) ||
+		errors.Is(err, service.ErrSyncCatering) ||
+		errors.Is(err, service.ErrSyncOrderIDTaken) ||
+		errors.Is(err, service.ErrSyncInvalidTotal) ||
+		errors.Is(err, service.ErrSyncInvalidPayment) ||
+		errors.Is(err, service.ErrSyncPaymentsExceedTotal)
+}
@@ -0,0 +1,189 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/service"
+)
+
+type syncResult struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	OrderNumber string `json:"order_number"`
+	Error       string `json:"error"`
+}
+
+func decodeSyncResults(t *testing.T, body []byte) []syncResult {
+	t.Helper()
+	var resp struct {
+		Results []syncResult `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp.Results
+}
+
+func syncOrderBody(id uuid.UUID, createdAt time.Time, productID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         id.String(),
+		"created_at": createdAt.Format(time.RFC3339),
+		"order_type": "TAKEAWAY",
+		"items": []map[string]interface{}{
+			{"product_id": productID.String(), "quantity": 1},
+		},
+		"total_amount": "25000",
+		"payments": []map[string]interface{}{
+			{"payment_method": "CASH", "amount": "25000", "amount_received": "30000"},
+		},
+	}
+}
+
+func TestOrderSync_AppliesOldestFirstAndReportsPerOrder(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	productID := uuid.New()
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	newer, older, synced, stale := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	var applied []uuid.UUID
+	svc := &mockOrderService{
+		syncFn: func(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error) {
+			applied = append(applied, req.ID)
+			if req.CreatedBy != claims.UserID {
+				t.Errorf("created_by: got %s, want %s", req.CreatedBy, claims.UserID)
+			}
+			switch req.ID {
+			case synced:
+				return &service.SyncOrderResult{
+					CreateOrderResult: service.CreateOrderResult{Order: database.Order{ID: req.ID, OrderNumber: "KWR-001"}},
+					AlreadySynced:     true,
+				}, nil
+			case stale:
+				return nil, fmt.Errorf("item[0]: %w", service.ErrProductNotFound)
+			}
+			if req.TotalAmount != "25000" || len(req.Payments) != 1 {
+				t.Errorf("order %s: total/payments not passed through", req.ID)
+			}
+			return &service.SyncOrderResult{
+				CreateOrderResult: service.CreateOrderResult{Order: database.Order{
+					ID:          req.ID,
+					OrderNumber: fmt.Sprintf("KWR-%03d", len(applied)+1),
+					Status:      enum.OrderStatusCompleted,
+					CreatedAt:   req.CreatedAt,
+				}},
+			}, nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(svc, &mockOrderStore{}, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/sync", map[string]interface{}{
+		"orders": []map[string]interface{}{
+			syncOrderBody(newer, base.Add(30*time.Minute), productID),
+			syncOrderBody(older, base.Add(10*time.Minute), productID),
+			syncOrderBody(synced, base, productID),
+			syncOrderBody(stale, base.Add(20*time.Minute), productID),
+		},
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	want := []uuid.UUID{synced, older, stale, newer}
+	for i, id := range want {
+		if i >= len(applied) || applied[i] != id {
+			t.Fatalf("apply order: got %v, want %v", applied, want)
+		}
+	}
+
+	results := decodeSyncResults(t, rr.Body.Bytes())
+	byID := map[string]syncResult{}
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	if got := byID[synced.String()]; got.Status != "already_synced" || got.OrderNumber != "KWR-001" {
+		t.Errorf("synced order: got %+v", got)
+	}
+	if got := byID[older.String()]; got.Status != "created" || got.OrderNumber == "" {
+		t.Errorf("older order: got %+v", got)
+	}
+	if got := byID[stale.String()]; got.Status != "conflict" || got.Error == "" {
+		t.Errorf("stale order: got %+v", got)
+	}
+	if got := byID[newer.String()]; got.Status != "created" {
+		t.Errorf("newer order: got %+v", got)
+	}
+}
+
+func TestOrderSync_InvalidOrderReportedAsConflict(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	svc := &mockOrderService{
+		syncFn: func(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error) {
+			t.Error("order with invalid id should not reach the service")
+			return nil, nil
+		},
+	}
+
+	body := syncOrderBody(uuid.New(), time.Now(), uuid.New())
+	body["id"] = "not-a-uuid"
+
+	router := setupOrderRouterWithStore(svc, &mockOrderStore{}, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/sync", map[string]interface{}{
+		"orders": []map[string]interface{}{body},
+	}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	results := decodeSyncResults(t, rr.Body.Bytes())
+	if len(results) != 1 || results[0].Status != "conflict" || results[0].Error != "invalid id" {
+		t.Errorf("results: got %+v", results)
+	}
+}
+
+func TestOrderSync_ServiceErrorFailsBatch(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	svc := &mockOrderService{
+		syncFn: func(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error) {
+			return nil, errors.New("db down")
+		},
+	}
+
+	router := setupOrderRouterWithStore(svc, &mockOrderStore{}, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/sync", map[string]interface{}{
+		"orders": []map[string]interface{}{syncOrderBody(uuid.New(), time.Now(), uuid.New())},
+	}, claims)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusInternalServerError, rr.Body.String())
+	}
+}
+
+func TestOrderSync_EmptyBatch(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	router := setupOrderRouterWithStore(&mockOrderService{}, &mockOrderStore{}, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/sync", map[string]interface{}{
+		"orders": []map[string]interface{}{},
+	}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -18,6 +19,7 @@ import (
 	"github.com/kiwari-pos/api/internal/enum"
 	"github.com/kiwari-pos/api/internal/middleware"
 	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
 	"github.com/shopspring/decimal"
 )
 
@@ -25,6 +27,7 @@ import (
 // Satisfied by *service.OrderService; narrow interface for testability.
 type OrderServicer interface {
 	CreateOrder(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error)
+	SyncOrder(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error)
 }
 
 // OrderStore defines the database methods needed by order read/update handlers.
@@ -33,6 +36,7 @@ type OrderStore interface {
 	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
 	ListOrders(ctx context.Context, arg database.ListOrdersParams) ([]database.Order, error)
 	ListActiveOrders(ctx context.Context, arg database.ListActiveOrdersParams) ([]database.ListActiveOrdersRow, error)
+	ListKitchenQueueItems(ctx context.Context, outletID uuid.UUID) ([]database.ListKitchenQueueItemsRow, error)
 	ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error)
 	ListOrderItemModifiersByOrderItem(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error)
 	ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.Payment, error)
@@ -45,12 +49,38 @@ type OrderStore interface {
 	UpdateOrderItemStatus(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error)
 	CountOrderItems(ctx context.Context, orderID uuid.UUID) (int64, error)
 	UpdateOrderTotals(ctx context.Context, orderID uuid.UUID) (database.Order, error)
+	UpdateOrderCharges(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error)
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	CountOrderChecks(ctx context.Context, orderID uuid.UUID) (int64, error)
+	// Audit trail
+	CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error
+	ListOrderEvents(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderEventsRow, error)
 	// Product/variant/modifier validation (reused from service layer)
 	GetProductForOrder(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error)
 	GetVariantForOrder(ctx context.Context, variantID uuid.UUID) (database.GetVariantForOrderRow, error)
 	GetModifierForOrder(ctx context.Context, modifierID uuid.UUID) (database.GetModifierForOrderRow, error)
+	ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error)
+	ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error)
 	CreateOrderItem(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error)
 	CreateOrderItemModifier(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error)
+	DeleteOrderItemModifiers(ctx context.Context, orderItemID uuid.UUID) error
+	// Combo components
+	ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error)
+	ListChildOrderItems(ctx context.Context, parentItemID pgtype.UUID) ([]database.OrderItem, error)
+	ScaleChildItemQuantities(ctx context.Context, arg database.ScaleChildItemQuantitiesParams) error
+	// Kitchen tickets for item changes
+	ListKitchenTicketItems(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error)
+	ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error)
+	CreateKitchenTicket(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error)
+	// Promotions, re-evaluated when items change
+	LockOrderPromotions(ctx context.Context, arg database.LockOrderPromotionsParams) error
+	ListOrderPromotions(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error)
+	ListPromotionItems(ctx context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error)
+	UpdateOrderPromotion(ctx context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error)
+	// Loyalty points, earned on completion and returned on cancellation
+	service.LoyaltyStore
+	// Create retries
+	IdempotencyStore
 }
 
 // NewOrderStore creates an OrderStore from a DBTX (pool or tx).
@@ -63,21 +93,26 @@ type OrderHandler struct {
 	store    OrderStore
 	pool     service.TxBeginner
 	newStore NewOrderStore
+	hub      Broadcaster
 }
 
 // NewOrderHandler creates a new OrderHandler.
-func NewOrderHandler(svc OrderServicer, store OrderStore, pool service.TxBeginner, newStore NewOrderStore) *OrderHandler {
-	return &OrderHandler{svc: svc, store: store, pool: pool, newStore: newStore}
+// hub may be nil, in which case no real-time events are published.
+func NewOrderHandler(svc OrderServicer, store OrderStore, pool service.TxBeginner, newStore NewOrderStore, hub Broadcaster) *OrderHandler {
+	return &OrderHandler{svc: svc, store: store, pool: pool, newStore: newStore, hub: hub}
 }
 
 // RegisterRoutes registers order endpoints on the given Chi router.
 // Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/orders
 func (h *OrderHandler) RegisterRoutes(r chi.Router) {
-	r.Post("/", h.Create)
+	r.With(idempotent(h.store)).Post("/", h.Create)
 	r.Get("/", h.List)
 	r.Get("/active", h.ListActive)
+	r.Get("/all-day", h.AllDay)
+	r.Post("/sync", h.Sync)
 	r.Route("/{id}", func(r chi.Router) {
 		r.Get("/", h.Get)
+		r.Get("/history", h.History)
 		r.Patch("/status", h.UpdateStatus)
 		r.Delete("/", h.Cancel)
 		r.Route("/items", func(r chi.Router) {
@@ -98,6 +133,7 @@ type createOrderRequest struct {
 	Notes            string                   `json:"notes"`
 	DiscountType     string                   `json:"discount_type"`
 	DiscountValue    string                   `json:"discount_value"`
+	PromotionCode    string                   `json:"promotion_code"`
 	CateringDate     string                   `json:"catering_date"`
 	CateringDpAmount string                   `json:"catering_dp_amount"`
 	DeliveryPlatform string                   `json:"delivery_platform"`
@@ -108,6 +144,7 @@ type createOrderRequest struct {
 type createOrderItemRequest struct {
 	ProductID     string                           `json:"product_id"`
 	VariantID     string                           `json:"variant_id"`
+	VariantIDs    []string                         `json:"variant_ids"`
 	Quantity      int32                            `json:"quantity"`
 	Notes         string                           `json:"notes"`
 	DiscountType  string                           `json:"discount_type"`
@@ -121,29 +158,36 @@ type createOrderItemModifierRequest struct {
 }
 
 type orderResponse struct {
-	ID               uuid.UUID           `json:"id"`
-	OutletID         uuid.UUID           `json:"outlet_id"`
-	OrderNumber      string              `json:"order_number"`
-	CustomerID       *string             `json:"customer_id"`
-	OrderType        string              `json:"order_type"`
-	Status           string              `json:"status"`
-	TableNumber      *string             `json:"table_number"`
-	Notes            *string             `json:"notes"`
-	Subtotal         string              `json:"subtotal"`
-	DiscountType     *string             `json:"discount_type"`
-	DiscountValue    *string             `json:"discount_value"`
-	DiscountAmount   string              `json:"discount_amount"`
-	TaxAmount        string              `json:"tax_amount"`
-	TotalAmount      string              `json:"total_amount"`
-	CateringDate     *time.Time          `json:"catering_date"`
-	CateringStatus   *string             `json:"catering_status"`
-	CateringDpAmount *string             `json:"catering_dp_amount"`
-	DeliveryPlatform *string             `json:"delivery_platform"`
-	DeliveryAddress  *string             `json:"delivery_address"`
-	CreatedBy        uuid.UUID           `json:"created_by"`
-	CreatedAt        time.Time           `json:"created_at"`
-	UpdatedAt        time.Time           `json:"updated_at"`
-	Items            []orderItemResponse `json:"items"`
+	ID                  uuid.UUID           `json:"id"`
+	OutletID            uuid.UUID           `json:"outlet_id"`
+	OrderNumber         string              `json:"order_number"`
+	CustomerID          *string             `json:"customer_id"`
+	OrderType           string              `json:"order_type"`
+	Status              string              `json:"status"`
+	TableNumber         *string             `json:"table_number"`
+	Notes               *string             `json:"notes"`
+	Subtotal            string              `json:"subtotal"`
+	DiscountType        *string             `json:"discount_type"`
+	DiscountValue       *string             `json:"discount_value"`
+	DiscountAmount      string              `json:"discount_amount"` // includes promotion_amount
+	PromotionID         *string             `json:"promotion_id"`
+	PromotionCode       *string             `json:"promotion_code"`
+	PromotionAmount     string              `json:"promotion_amount"`
+	ServiceChargeAmount string              `json:"service_charge_amount"`
+	ServiceChargeRate   string              `json:"service_charge_rate"`
+	TaxAmount           string              `json:"tax_amount"`
+	TaxRate             string              `json:"tax_rate"`
+	PricesIncludeTax    bool                `json:"prices_include_tax"`
+	TotalAmount         string              `json:"total_amount"`
+	CateringDate        *time.Time          `json:"catering_date"`
+	CateringStatus      *string             `json:"catering_status"`
+	CateringDpAmount    *string             `json:"catering_dp_amount"`
+	DeliveryPlatform    *string             `json:"delivery_platform"`
+	DeliveryAddress     *string             `json:"delivery_address"`
+	CreatedBy           uuid.UUID           `json:"created_by"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	Items               []orderItemResponse `json:"items"`
 }
 
 type orderItemResponse struct {
@@ -159,9 +203,34 @@ type orderItemResponse struct {
 	Notes          *string                     `json:"notes"`
 	Status         string                      `json:"status"`
 	Station        *string                     `json:"station"`
+	VariantIDs     []uuid.UUID                 `json:"variant_ids"`
+	ParentItemID   *string                     `json:"parent_item_id"` // set on combo components
+	SentAt         time.Time                   `json:"sent_at"`
+	StartedAt      *time.Time                  `json:"started_at"`
+	ReadyAt        *time.Time                  `json:"ready_at"`
 	Modifiers      []orderItemModifierResponse `json:"modifiers"`
 }
 
+// selectionErrorResponse is the 400 body for items that break their
+// product's variant or modifier group rules.
+type selectionErrorResponse struct {
+	Error string                  `json:"error"`
+	Items []itemSelectionResponse `json:"items"`
+}
+
+type itemSelectionResponse struct {
+	Index      int                      `json:"index"`
+	ProductID  uuid.UUID                `json:"product_id"`
+	Violations []groupViolationResponse `json:"violations"`
+}
+
+type groupViolationResponse struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Rule      string    `json:"rule"`
+	Message   string    `json:"message"`
+}
+
 type orderItemModifierResponse struct {
 	ID         uuid.UUID `json:"id"`
 	ModifierID uuid.UUID `json:"modifier_id"`
@@ -178,10 +247,22 @@ type paymentResponse struct {
 	ReferenceNumber *string   `json:"reference_number"`
 	AmountReceived  *string   `json:"amount_received"`
 	ChangeAmount    *string   `json:"change_amount"`
+	CheckID         *string   `json:"check_id"`
+	RefundedAmount  string    `json:"refunded_amount"`
 	ProcessedBy     uuid.UUID `json:"processed_by"`
 	ProcessedAt     time.Time `json:"processed_at"`
 }
 
+type orderEventResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	EventType string          `json:"event_type"`
+	ActorID   uuid.UUID       `json:"actor_id"`
+	ActorName string          `json:"actor_name"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
 // orderDetailResponse extends orderResponse with payments for the GET detail endpoint.
 type orderDetailResponse struct {
 	orderResponse
@@ -215,6 +296,7 @@ type updateStatusRequest struct {
 type addItemRequest struct {
 	ProductID     string                           `json:"product_id"`
 	VariantID     string                           `json:"variant_id"`
+	VariantIDs    []string                         `json:"variant_ids"`
 	Quantity      int32                            `json:"quantity"`
 	Notes         string                           `json:"notes"`
 	DiscountType  string                           `json:"discount_type"`
@@ -225,6 +307,10 @@ type addItemRequest struct {
 type updateItemRequest struct {
 	Quantity int32  `json:"quantity"`
 	Notes    string `json:"notes"`
+	// Optional; when either is set the item's selection is replaced,
+	// checked against the product's groups and repriced.
+	VariantIDs *[]string                         `json:"variant_ids"`
+	Modifiers  *[]createOrderItemModifierRequest `json:"modifiers"`
 }
 
 type updateItemStatusRequest struct {
@@ -279,44 +365,14 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Build service request
-	svcItems := make([]service.CreateOrderItemRequest, len(req.Items))
-	for i, item := range req.Items {
-		mods := make([]service.CreateOrderItemModifierRequest, len(item.Modifiers))
-		for j, mod := range item.Modifiers {
-			mods[j] = service.CreateOrderItemModifierRequest{
-				ModifierID: mod.ModifierID,
-				Quantity:   mod.Quantity,
-			}
-		}
-		svcItems[i] = service.CreateOrderItemRequest{
-			ProductID:     item.ProductID,
-			VariantID:     item.VariantID,
-			Quantity:      item.Quantity,
-			Notes:         item.Notes,
-			DiscountType:  item.DiscountType,
-			DiscountValue: item.DiscountValue,
-			Modifiers:     mods,
-		}
-	}
-
-	result, err := h.svc.CreateOrder(r.Context(), service.CreateOrderRequest{
-		OutletID:         outletID,
-		CreatedBy:        claims.UserID,
-		OrderType:        req.OrderType,
-		TableNumber:      req.TableNumber,
-		CustomerID:       req.CustomerID,
-		Notes:            req.Notes,
-		DiscountType:     req.DiscountType,
-		DiscountValue:    req.DiscountValue,
-		CateringDate:     req.CateringDate,
-		CateringDpAmount: req.CateringDpAmount,
-		DeliveryPlatform: req.DeliveryPlatform,
-		DeliveryAddress:  req.DeliveryAddress,
-		Items:            svcItems,
-	})
+	result, err := h.svc.CreateOrder(r.Context(), req.toService(outletID, claims.UserID))
 	if err != nil {
 		// Map known service errors to appropriate HTTP status codes.
+		var selErr *service.SelectionError
+		if errors.As(err, &selErr) {
+			writeSelectionError(w, selErr)
+			return
+		}
 		if isValidationError(err) {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
@@ -326,6 +382,9 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderCreated, outletID, result.Order.ID, orderEventPayload{})
+	publishKitchenTickets(h.hub, outletID, result.Tickets)
+
 	writeJSON(w, http.StatusCreated, toOrderResponse(result))
 }
 
@@ -488,60 +547,18 @@ func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{
-		ID:       orderID,
-		OutletID: outletID,
-	})
+	detail, err := loadOrderDetail(r.Context(), h.store, outletID, orderID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
 			return
 		}
-		log.Printf("ERROR: get order: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
-	}
-
-	// Fetch items
-	items, err := h.store.ListOrderItemsByOrder(r.Context(), orderID)
-	if err != nil {
-		log.Printf("ERROR: list order items: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
-	}
-
-	// Fetch modifiers for each item
-	itemResponses := make([]orderItemResponse, len(items))
-	for i, item := range items {
-		mods, err := h.store.ListOrderItemModifiersByOrderItem(r.Context(), item.ID)
-		if err != nil {
-			log.Printf("ERROR: list order item modifiers: %v", err)
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-			return
-		}
-		itemResponses[i] = dbOrderItemToResponse(item, mods)
-	}
-
-	// Fetch payments
-	payments, err := h.store.ListPaymentsByOrder(r.Context(), orderID)
-	if err != nil {
-		log.Printf("ERROR: list payments: %v", err)
+		log.Printf("ERROR: get order detail: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
-	paymentResps := make([]paymentResponse, len(payments))
-	for i, p := range payments {
-		paymentResps[i] = dbPaymentToResponse(p)
-	}
-
-	orderResp := dbOrderToResponse(order)
-	orderResp.Items = itemResponses
-
-	writeJSON(w, http.StatusOK, orderDetailResponse{
-		orderResponse: orderResp,
-		Payments:      paymentResps,
-	})
+	writeJSON(w, http.StatusOK, detail)
 }
 
 // UpdateStatus handles PATCH /outlets/{oid}/orders/{id}/status.
@@ -600,7 +617,17 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updated, err := h.store.UpdateOrderStatus(r.Context(), database.UpdateOrderStatusParams{
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for status update: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	updated, err := txStore.UpdateOrderStatus(r.Context(), database.UpdateOrderStatusParams{
 		ID:       orderID,
 		OutletID: outletID,
 		Status:   req.Status,
@@ -617,6 +644,31 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventStatusChanged,
+		map[string]string{"status": current.Status},
+		map[string]string{"status": updated.Status},
+	); err != nil {
+		log.Printf("ERROR: record status change: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if updated.Status == enum.OrderStatusCompleted {
+		if err := service.EarnOrderPoints(r.Context(), txStore, updated, claims.UserID); err != nil {
+			log.Printf("ERROR: earn loyalty points: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for status update: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderStatusChanged, outletID, orderID, orderEventPayload{})
+
 	writeJSON(w, http.StatusOK, dbOrderToResponse(updated))
 }
 
@@ -640,9 +692,19 @@ func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for cancel: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
 	// Attempt to cancel the order. The SQL query enforces the precondition atomically:
 	// it will only update if the order exists AND is not COMPLETED or CANCELLED.
-	cancelled, err := h.store.CancelOrder(r.Context(), database.CancelOrderParams{
+	cancelled, err := txStore.CancelOrder(r.Context(), database.CancelOrderParams{
 		ID:       orderID,
 		OutletID: outletID,
 	})
@@ -681,9 +743,94 @@ func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventCancelled,
+		nil, map[string]string{"status": cancelled.Status},
+	); err != nil {
+		log.Printf("ERROR: record cancellation: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Points redeemed on the order go back to the customer
+	if err := service.ReturnOrderPoints(r.Context(), txStore, cancelled, claims.UserID); err != nil {
+		log.Printf("ERROR: return loyalty points: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Stations already cooking for the order are told to stop
+	tickets, err := service.VoidKitchenTickets(r.Context(), txStore, orderID, claims.UserID)
+	if err != nil {
+		log.Printf("ERROR: void kitchen tickets for cancel: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for cancel: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderCancelled, outletID, orderID, orderEventPayload{})
+	publishKitchenTickets(h.hub, outletID, tickets)
+
 	writeJSON(w, http.StatusOK, dbOrderToResponse(cancelled))
 }
 
+// History handles GET /outlets/{oid}/orders/{id}/history.
+// Returns the order's audit trail, oldest first.
+func (h *OrderHandler) History(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	// Verify order exists and belongs to outlet
+	_, err = h.store.GetOrder(r.Context(), database.GetOrderParams{
+		ID:       orderID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return
+		}
+		log.Printf("ERROR: get order for history: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	events, err := h.store.ListOrderEvents(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: list order events: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]orderEventResponse, len(events))
+	for i, e := range events {
+		resp[i] = orderEventResponse{
+			ID:        e.ID,
+			EventType: e.EventType,
+			ActorID:   e.ActorID,
+			ActorName: e.ActorName,
+			Before:    json.RawMessage(e.BeforeValue),
+			After:     json.RawMessage(e.AfterValue),
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // AddItem handles POST /outlets/{oid}/orders/{id}/items.
 func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
@@ -720,7 +867,7 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify order exists, belongs to outlet, and is NEW
+	// Verify order exists, belongs to outlet, and is still open
 	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{
 		ID:       orderID,
 		OutletID: outletID,
@@ -735,8 +882,13 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if order.Status != enum.OrderStatusNew {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": "can only add items to NEW orders"})
+	// Orders the kitchen has started can still change; the kitchen gets a change ticket
+	if order.Status == enum.OrderStatusCompleted || order.Status == enum.OrderStatusCancelled {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot add items to a completed or cancelled order"})
+		return
+	}
+
+	if !h.ensureNotSplit(w, r, orderID) {
 		return
 	}
 
@@ -762,89 +914,30 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate base price (product base_price + variant adjustment)
-	basePrice := product.BasePrice
-	var variantID pgtype.UUID
-	if req.VariantID != "" {
-		vid, err := uuid.Parse(req.VariantID)
-		if err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid variant_id"})
-			return
-		}
-		variant, err := h.store.GetVariantForOrder(r.Context(), vid)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "variant not found"})
-				return
-			}
-			log.Printf("ERROR: get variant for add item: %v", err)
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-			return
-		}
-		if variant.ProductID != productID {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "variant does not belong to this product"})
+	// Validate variants and modifiers against the product's groups
+	selection, violations, err := service.ResolveSelection(r.Context(), h.store, productID,
+		mergeVariantIDs(req.VariantID, req.VariantIDs), toServiceModifiers(req.Modifiers))
+	if err != nil {
+		if isValidationError(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
 		}
-		variantID = pgtype.UUID{Bytes: vid, Valid: true}
-		// Add variant price adjustment
-		if variant.PriceAdjustment.Valid {
-			adj, err := numericToDecimal(variant.PriceAdjustment)
-			if err == nil {
-				base, _ := numericToDecimal(basePrice)
-				basePrice = decimalToNumeric(base.Add(adj))
-			}
-		}
-	}
-
-	// Calculate item subtotal: (base_price * qty) + modifier_prices - item_discount
-	basePriceDecimal, _ := numericToDecimal(basePrice)
-	itemSubtotal := basePriceDecimal.Mul(decimal.NewFromInt32(req.Quantity))
-
-	// Validate and calculate modifier prices
-	// Store validated modifiers to reuse when creating modifier records (Issue 3 fix)
-	type validatedModifier struct {
-		modifierID uuid.UUID
-		quantity   int32
-		price      pgtype.Numeric
+		log.Printf("ERROR: resolve selection for add item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
 	}
-	validatedModifiers := make([]validatedModifier, 0, len(req.Modifiers))
-	var modifierSubtotal decimal.Decimal
-	for _, mod := range req.Modifiers {
-		if mod.ModifierID == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "modifier_id is required"})
-			return
-		}
-		modID, err := uuid.Parse(mod.ModifierID)
-		if err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid modifier_id"})
-			return
-		}
-		modifier, err := h.store.GetModifierForOrder(r.Context(), modID)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "modifier not found"})
-				return
-			}
-			log.Printf("ERROR: get modifier for add item: %v", err)
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-			return
-		}
-		if modifier.ProductID != productID {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "modifier does not belong to this product"})
-			return
-		}
-		modPrice, _ := numericToDecimal(modifier.Price)
-		modifierSubtotal = modifierSubtotal.Add(modPrice.Mul(decimal.NewFromInt32(mod.Quantity)))
-
-		// Store validated modifier data for reuse
-		validatedModifiers = append(validatedModifiers, validatedModifier{
-			modifierID: modID,
-			quantity:   mod.Quantity,
-			price:      modifier.Price,
-		})
+	if len(violations) > 0 {
+		writeSelectionError(w, &service.SelectionError{Items: []service.ItemSelectionError{
+			{Index: 0, ProductID: productID, Violations: violations},
+		}})
+		return
 	}
 
-	itemSubtotal = itemSubtotal.Add(modifierSubtotal)
+	// Calculate item subtotal: (unit_price * qty) + modifier_prices - item_discount
+	// where unit_price = product base_price + variant adjustments
+	basePriceDecimal, _ := numericToDecimal(product.BasePrice)
+	unitPrice := basePriceDecimal.Add(selection.PriceAdjustment)
+	itemSubtotal := unitPrice.Mul(decimal.NewFromInt32(req.Quantity)).Add(selection.ModifiersTotal)
 
 	// Apply item-level discount if provided
 	var discountType pgtype.Text
@@ -894,15 +987,16 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	item, err := txStore.CreateOrderItem(r.Context(), database.CreateOrderItemParams{
 		OrderID:        orderID,
 		ProductID:      productID,
-		VariantID:      variantID,
+		VariantID:      selection.VariantID(),
 		Quantity:       req.Quantity,
-		UnitPrice:      basePrice,
+		UnitPrice:      decimalToNumeric(unitPrice),
 		DiscountType:   discountType,
 		DiscountValue:  discountValue,
 		DiscountAmount: decimalToNumeric(discountAmount),
 		Subtotal:       decimalToNumeric(itemSubtotal),
 		Notes:          notes,
 		Station:        product.Station,
+		VariantIds:     selection.VariantIDs,
 	})
 	if err != nil {
 		log.Printf("ERROR: create order item: %v", err)
@@ -911,30 +1005,59 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create order item modifiers using validated data (no redundant DB calls)
-	modifiers := make([]database.OrderItemModifier, 0, len(validatedModifiers))
-	for _, mod := range validatedModifiers {
-		itemMod, err := txStore.CreateOrderItemModifier(r.Context(), database.CreateOrderItemModifierParams{
-			OrderItemID: item.ID,
-			ModifierID:  mod.modifierID,
-			Quantity:    mod.quantity,
-			UnitPrice:   mod.price,
-		})
+	modifiers, err := createItemModifiers(r.Context(), txStore, item.ID, selection.Modifiers)
+	if err != nil {
+		log.Printf("ERROR: create order item modifier: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Expand combo lines into their kitchen components
+	var components []database.OrderItem
+	if product.IsCombo {
+		components, err = service.ExpandCombo(r.Context(), txStore, item)
 		if err != nil {
-			log.Printf("ERROR: create order item modifier: %v", err)
+			log.Printf("ERROR: expand combo: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventItemAdded,
+		nil, itemAuditSnapshot(item),
+	); err != nil {
+		log.Printf("ERROR: record item added: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if item.DiscountType.Valid {
+		if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventDiscountApplied, nil, map[string]interface{}{
+			"item_id":         item.ID,
+			"discount_type":   item.DiscountType.String,
+			"discount_value":  numericToString(item.DiscountValue),
+			"discount_amount": numericToString(item.DiscountAmount),
+		}); err != nil {
+			log.Printf("ERROR: record item discount: %v", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 			return
 		}
-		modifiers = append(modifiers, itemMod)
 	}
 
 	// Recalculate order totals
-	updatedOrder, err := txStore.UpdateOrderTotals(r.Context(), orderID)
+	updatedOrder, err := recalculateOrderTotals(r.Context(), txStore, orderID, claims.UserID)
 	if err != nil {
 		log.Printf("ERROR: update order totals: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
+	tickets, err := service.SendKitchenTickets(r.Context(), txStore, orderID, claims.UserID)
+	if err != nil {
+		log.Printf("ERROR: send kitchen tickets for add item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit(r.Context()); err != nil {
 		log.Printf("ERROR: commit tx for add item: %v", err)
@@ -944,10 +1067,21 @@ func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 
 	// Return the added item with modifiers
 	itemResp := dbOrderItemToResponse(item, modifiers)
-	writeJSON(w, http.StatusCreated, map[string]interface{}{
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderItemAdded, outletID, orderID, orderEventPayload{Item: &itemResp})
+	resp := map[string]interface{}{
 		"item":  itemResp,
 		"order": dbOrderToResponse(updatedOrder),
-	})
+	}
+	if len(components) > 0 {
+		componentResps := make([]orderItemResponse, len(components))
+		for i, c := range components {
+			componentResps[i] = dbOrderItemToResponse(c, nil)
+			publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderItemAdded, outletID, orderID, orderEventPayload{Item: &componentResps[i]})
+		}
+		resp["components"] = componentResps
+	}
+	publishKitchenTickets(h.hub, outletID, tickets)
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 // UpdateItem handles PUT /outlets/{oid}/orders/{id}/items/{iid}.
@@ -987,7 +1121,7 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify order exists, belongs to outlet, and is NEW
+	// Verify order exists, belongs to outlet, and is still open
 	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{
 		ID:       orderID,
 		OutletID: outletID,
@@ -1002,8 +1136,13 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if order.Status != enum.OrderStatusNew {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": "can only update items in NEW orders"})
+	// Orders the kitchen has started can still change; the kitchen gets a change ticket
+	if order.Status == enum.OrderStatusCompleted || order.Status == enum.OrderStatusCancelled {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot update items in a completed or cancelled order"})
+		return
+	}
+
+	if !h.ensureNotSplit(w, r, orderID) {
 		return
 	}
 
@@ -1022,23 +1161,90 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Recalculate item subtotal based on new quantity
-	// Formula: (unit_price * new_qty) + modifier_prices - item_discount
-	unitPrice, _ := numericToDecimal(currentItem.UnitPrice)
-	newSubtotalBeforeDiscount := unitPrice.Mul(decimal.NewFromInt32(req.Quantity))
+	if currentItem.ParentItemID.Valid {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "combo components change with their combo"})
+		return
+	}
 
-	// Add modifier prices (they don't change with quantity, they're already unit-based)
 	modifiers, err := h.store.ListOrderItemModifiersByOrderItem(r.Context(), itemID)
 	if err != nil {
 		log.Printf("ERROR: list order item modifiers: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
+
+	unitPrice, _ := numericToDecimal(currentItem.UnitPrice)
+	variantID := currentItem.VariantID
+	variantIDs := currentItem.VariantIds
+	modifiersTotal := decimal.Zero
 	for _, mod := range modifiers {
 		modPrice, _ := numericToDecimal(mod.UnitPrice)
-		newSubtotalBeforeDiscount = newSubtotalBeforeDiscount.Add(modPrice.Mul(decimal.NewFromInt32(mod.Quantity)))
+		modifiersTotal = modifiersTotal.Add(modPrice.Mul(decimal.NewFromInt32(mod.Quantity)))
 	}
 
+	// A changed selection is checked against the product's groups and
+	// repriced at current menu prices; the part not sent is kept.
+	var selection *service.Selection
+	if req.VariantIDs != nil || req.Modifiers != nil {
+		variantReq := make([]string, len(currentItem.VariantIds))
+		for i, id := range currentItem.VariantIds {
+			variantReq[i] = id.String()
+		}
+		if req.VariantIDs != nil {
+			variantReq = *req.VariantIDs
+		}
+		modifierReq := make([]service.CreateOrderItemModifierRequest, len(modifiers))
+		for i, mod := range modifiers {
+			modifierReq[i] = service.CreateOrderItemModifierRequest{ModifierID: mod.ModifierID.String(), Quantity: mod.Quantity}
+		}
+		if req.Modifiers != nil {
+			modifierReq = toServiceModifiers(*req.Modifiers)
+		}
+
+		product, err := h.store.GetProductForOrder(r.Context(), database.GetProductForOrderParams{
+			ID:       currentItem.ProductID,
+			OutletID: outletID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "product not found"})
+				return
+			}
+			log.Printf("ERROR: get product for update item: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+
+		var violations []service.GroupViolation
+		selection, violations, err = service.ResolveSelection(r.Context(), h.store, currentItem.ProductID, variantReq, modifierReq)
+		if err != nil {
+			if isValidationError(err) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			log.Printf("ERROR: resolve selection for update item: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if len(violations) > 0 {
+			writeSelectionError(w, &service.SelectionError{Items: []service.ItemSelectionError{
+				{Index: 0, ProductID: currentItem.ProductID, Violations: violations},
+			}})
+			return
+		}
+
+		basePrice, _ := numericToDecimal(product.BasePrice)
+		unitPrice = basePrice.Add(selection.PriceAdjustment)
+		variantID = selection.VariantID()
+		variantIDs = selection.VariantIDs
+		modifiersTotal = selection.ModifiersTotal
+	}
+
+	// Recalculate item subtotal based on new quantity
+	// Formula: (unit_price * new_qty) + modifier_prices - item_discount
+	// Modifier prices don't change with quantity, they're already unit-based
+	newSubtotalBeforeDiscount := unitPrice.Mul(decimal.NewFromInt32(req.Quantity)).Add(modifiersTotal)
+
 	// Recalculate discount amount based on new subtotal
 	var discountAmount decimal.Decimal
 	if currentItem.DiscountType.Valid {
@@ -1080,6 +1286,9 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		Notes:          notes,
 		DiscountAmount: decimalToNumeric(discountAmount),
 		Subtotal:       decimalToNumeric(newSubtotal),
+		UnitPrice:      decimalToNumeric(unitPrice),
+		VariantID:      variantID,
+		VariantIds:     variantIDs,
 	})
 	if err != nil {
 		log.Printf("ERROR: update order item: %v", err)
@@ -1087,14 +1296,56 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Combo components follow the combo's quantity
+	if req.Quantity != currentItem.Quantity {
+		if err := txStore.ScaleChildItemQuantities(r.Context(), database.ScaleChildItemQuantitiesParams{
+			ParentItemID: pgtype.UUID{Bytes: itemID, Valid: true},
+			OldQuantity:  currentItem.Quantity,
+			NewQuantity:  req.Quantity,
+		}); err != nil {
+			log.Printf("ERROR: scale combo components: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	if selection != nil {
+		if err := txStore.DeleteOrderItemModifiers(r.Context(), itemID); err != nil {
+			log.Printf("ERROR: delete order item modifiers: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		modifiers, err = createItemModifiers(r.Context(), txStore, itemID, selection.Modifiers)
+		if err != nil {
+			log.Printf("ERROR: create order item modifier: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventItemUpdated,
+		itemAuditSnapshot(currentItem), itemAuditSnapshot(updatedItem),
+	); err != nil {
+		log.Printf("ERROR: record item update: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
 	// Recalculate order totals
-	updatedOrder, err := txStore.UpdateOrderTotals(r.Context(), orderID)
+	updatedOrder, err := recalculateOrderTotals(r.Context(), txStore, orderID, claims.UserID)
 	if err != nil {
 		log.Printf("ERROR: update order totals: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
+	tickets, err := service.SendKitchenTickets(r.Context(), txStore, orderID, claims.UserID)
+	if err != nil {
+		log.Printf("ERROR: send kitchen tickets for update item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit(r.Context()); err != nil {
 		log.Printf("ERROR: commit tx for update item: %v", err)
@@ -1104,6 +1355,8 @@ func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 
 	// Return updated item with modifiers
 	itemResp := dbOrderItemToResponse(updatedItem, modifiers)
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderItemUpdated, outletID, orderID, orderEventPayload{Item: &itemResp})
+	publishKitchenTickets(h.hub, outletID, tickets)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"item":  itemResp,
 		"order": dbOrderToResponse(updatedOrder),
@@ -1136,7 +1389,7 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify order exists, belongs to outlet, and is NEW
+	// Verify order exists, belongs to outlet, and is still open
 	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{
 		ID:       orderID,
 		OutletID: outletID,
@@ -1151,8 +1404,13 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if order.Status != enum.OrderStatusNew {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": "can only remove items from NEW orders"})
+	// Orders the kitchen has started can still change; the kitchen gets a change ticket
+	if order.Status == enum.OrderStatusCompleted || order.Status == enum.OrderStatusCancelled {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot remove items from a completed or cancelled order"})
+		return
+	}
+
+	if !h.ensureNotSplit(w, r, orderID) {
 		return
 	}
 
@@ -1170,7 +1428,7 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify item exists and belongs to order
-	_, err = h.store.GetOrderItem(r.Context(), database.GetOrderItemParams{
+	removedItem, err := h.store.GetOrderItem(r.Context(), database.GetOrderItemParams{
 		ID:      itemID,
 		OrderID: orderID,
 	})
@@ -1184,6 +1442,27 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if removedItem.ParentItemID.Valid {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "combo components are removed with their combo"})
+		return
+	}
+
+	// Stations of the item and its combo components, for routing the removal
+	// to kitchen displays once they are gone from the order
+	orderItems, err := h.store.ListOrderItemsByOrder(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: list order items for remove: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	removedStations := []string{}
+	for _, item := range orderItems {
+		isComponent := item.ParentItemID.Valid && uuid.UUID(item.ParentItemID.Bytes) == itemID
+		if (item.ID == itemID || isComponent) && item.Station.Valid {
+			removedStations = append(removedStations, item.Station.String)
+		}
+	}
+
 	// Begin transaction for atomic multi-write operation
 	tx, err := h.pool.Begin(r.Context())
 	if err != nil {
@@ -1195,7 +1474,7 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 
 	txStore := h.newStore(tx)
 
-	// Delete item (CASCADE will handle modifiers)
+	// Delete item (CASCADE will handle modifiers and combo components)
 	err = txStore.DeleteOrderItem(r.Context(), database.DeleteOrderItemParams{
 		ID:      itemID,
 		OrderID: orderID,
@@ -1206,14 +1485,29 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventItemRemoved,
+		itemAuditSnapshot(removedItem), nil,
+	); err != nil {
+		log.Printf("ERROR: record item removal: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
 	// Recalculate order totals
-	updatedOrder, err := txStore.UpdateOrderTotals(r.Context(), orderID)
+	updatedOrder, err := recalculateOrderTotals(r.Context(), txStore, orderID, claims.UserID)
 	if err != nil {
 		log.Printf("ERROR: update order totals: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
+	tickets, err := service.SendKitchenTickets(r.Context(), txStore, orderID, claims.UserID)
+	if err != nil {
+		log.Printf("ERROR: send kitchen tickets for remove item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit(r.Context()); err != nil {
 		log.Printf("ERROR: commit tx for remove item: %v", err)
@@ -1221,6 +1515,9 @@ func (h *OrderHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderItemRemoved, outletID, orderID, orderEventPayload{RemovedItemID: &itemID, stations: removedStations})
+	publishKitchenTickets(h.hub, outletID, tickets)
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "item removed successfully",
 		"order":   dbOrderToResponse(updatedOrder),
@@ -1264,105 +1561,302 @@ func (h *OrderHandler) UpdateItemStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !isValidItemStatus(req.Status) {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	itemResp, err := h.changeItemStatus(r.Context(), claims.UserID, outletID, orderID, itemID, req.Status, false)
+	if err != nil {
+		var se *statusError
+		if errors.As(err, &se) {
+			writeJSON(w, se.status, map[string]string{"error": se.msg})
+			return
+		}
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
+	writeJSON(w, http.StatusOK, itemResp)
+}
+
+// statusError is a rejected request with the HTTP status REST callers get.
+// Shared logic returns it so WebSocket commands can report the same errors.
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// changeItemStatus validates and applies an item status change, records it,
+// rolls it up to a combo line and publishes the change. recall allows the
+// kitchen to move a READY item back to PREPARING, which REST callers can't.
+func (h *OrderHandler) changeItemStatus(ctx context.Context, actorID, outletID, orderID, itemID uuid.UUID, status string, recall bool) (orderItemResponse, error) {
+	if !isValidItemStatus(status) {
+		return orderItemResponse{}, &statusError{http.StatusBadRequest, "invalid status"}
+	}
+
 	// Verify order exists and belongs to outlet
-	order, err := h.store.GetOrder(r.Context(), database.GetOrderParams{
+	order, err := h.store.GetOrder(ctx, database.GetOrderParams{
 		ID:       orderID,
 		OutletID: outletID,
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
-			return
+			return orderItemResponse{}, &statusError{http.StatusNotFound, "order not found"}
 		}
-		log.Printf("ERROR: get order for item status update: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
+		return orderItemResponse{}, fmt.Errorf("get order for item status update: %w", err)
 	}
 
 	// Cannot update items on cancelled or completed orders
 	if order.Status == enum.OrderStatusCancelled || order.Status == enum.OrderStatusCompleted {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot update items on a " + order.Status + " order"})
-		return
+		return orderItemResponse{}, &statusError{http.StatusConflict, "cannot update items on a " + order.Status + " order"}
 	}
 
 	// Get current item to validate transition
-	currentItem, err := h.store.GetOrderItem(r.Context(), database.GetOrderItemParams{
+	currentItem, err := h.store.GetOrderItem(ctx, database.GetOrderItemParams{
 		ID:      itemID,
 		OrderID: orderID,
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "item not found"})
-			return
+			return orderItemResponse{}, &statusError{http.StatusNotFound, "item not found"}
 		}
-		log.Printf("ERROR: get order item for status update: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
+		return orderItemResponse{}, fmt.Errorf("get order item for status update: %w", err)
 	}
 
 	// Validate status transition
-	if err := validateItemStatusTransition(currentItem.Status, req.Status); err != nil {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
-		return
+	if recall {
+		if currentItem.Status != enum.OrderItemStatusReady || status != enum.OrderItemStatusPreparing {
+			return orderItemResponse{}, &statusError{http.StatusConflict, "only READY items can be recalled"}
+		}
+	} else if err := validateItemStatusTransition(currentItem.Status, status); err != nil {
+		return orderItemResponse{}, &statusError{http.StatusConflict, err.Error()}
+	}
+
+	// A combo line's status is derived from its components
+	if !currentItem.ParentItemID.Valid {
+		components, err := h.store.ListChildOrderItems(ctx, pgtype.UUID{Bytes: itemID, Valid: true})
+		if err != nil {
+			return orderItemResponse{}, fmt.Errorf("list combo components: %w", err)
+		}
+		if len(components) > 0 {
+			return orderItemResponse{}, &statusError{http.StatusConflict, "combo status follows its components"}
+		}
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return orderItemResponse{}, fmt.Errorf("begin tx for item status update: %w", err)
 	}
+	defer tx.Rollback(ctx)
+
+	txStore := h.newStore(tx)
 
 	// Update item status
-	updatedItem, err := h.store.UpdateOrderItemStatus(r.Context(), database.UpdateOrderItemStatusParams{
+	updatedItem, err := txStore.UpdateOrderItemStatus(ctx, database.UpdateOrderItemStatusParams{
 		ID:      itemID,
 		OrderID: orderID,
-		Status:  req.Status,
+		Status:  status,
 	})
 	if err != nil {
-		log.Printf("ERROR: update order item status: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
+		return orderItemResponse{}, fmt.Errorf("update order item status: %w", err)
+	}
+
+	if err := service.RecordOrderEvent(ctx, txStore, orderID, actorID, enum.OrderEventItemStatusChanged,
+		map[string]interface{}{"item_id": itemID, "status": currentItem.Status},
+		map[string]interface{}{"item_id": itemID, "status": updatedItem.Status},
+	); err != nil {
+		return orderItemResponse{}, fmt.Errorf("record item status change: %w", err)
+	}
+
+	// Roll a component's progress up to its combo line
+	var updatedParent *database.OrderItem
+	if updatedItem.ParentItemID.Valid {
+		updatedParent, err = rollUpComboStatus(ctx, txStore, orderID, updatedItem.ParentItemID, actorID)
+		if err != nil {
+			return orderItemResponse{}, fmt.Errorf("roll up combo status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return orderItemResponse{}, fmt.Errorf("commit tx for item status update: %w", err)
+	}
+
+	if updatedParent != nil {
+		parentMods, err := h.store.ListOrderItemModifiersByOrderItem(ctx, updatedParent.ID)
+		if err != nil {
+			log.Printf("ERROR: list combo modifiers: %v", err)
+		} else {
+			parentResp := dbOrderItemToResponse(*updatedParent, parentMods)
+			publishOrderEvent(ctx, h.hub, h.store, ws.EventOrderItemStatusChanged, outletID, orderID, orderEventPayload{Item: &parentResp})
+		}
 	}
 
 	// Get modifiers for response
-	modifiers, err := h.store.ListOrderItemModifiersByOrderItem(r.Context(), itemID)
+	modifiers, err := h.store.ListOrderItemModifiersByOrderItem(ctx, itemID)
 	if err != nil {
-		log.Printf("ERROR: list order item modifiers: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
+		return orderItemResponse{}, fmt.Errorf("list order item modifiers: %w", err)
 	}
 
-	writeJSON(w, http.StatusOK, dbOrderItemToResponse(updatedItem, modifiers))
+	itemResp := dbOrderItemToResponse(updatedItem, modifiers)
+	publishOrderEvent(ctx, h.hub, h.store, ws.EventOrderItemStatusChanged, outletID, orderID, orderEventPayload{Item: &itemResp})
+
+	return itemResp, nil
 }
 
 // --- Helpers ---
 
+// rollUpComboStatus sets a combo line's status from its components and
+// records the change. Returns nil when the combo's status is unchanged.
+func rollUpComboStatus(ctx context.Context, store OrderStore, orderID uuid.UUID, parentID pgtype.UUID, actorID uuid.UUID) (*database.OrderItem, error) {
+	parent, err := store.GetOrderItem(ctx, database.GetOrderItemParams{ID: parentID.Bytes, OrderID: orderID})
+	if err != nil {
+		return nil, fmt.Errorf("get combo item: %w", err)
+	}
+	components, err := store.ListChildOrderItems(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("list combo components: %w", err)
+	}
+	status := service.ComboStatus(components)
+	if status == parent.Status {
+		return nil, nil
+	}
+
+	updated, err := store.UpdateOrderItemStatus(ctx, database.UpdateOrderItemStatusParams{
+		ID:      parent.ID,
+		OrderID: orderID,
+		Status:  status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update combo status: %w", err)
+	}
+	if err := service.RecordOrderEvent(ctx, store, orderID, actorID, enum.OrderEventItemStatusChanged,
+		map[string]interface{}{"item_id": parent.ID, "status": parent.Status},
+		map[string]interface{}{"item_id": parent.ID, "status": updated.Status},
+	); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// itemAuditSnapshot captures the order item fields recorded in the order history.
+func itemAuditSnapshot(item database.OrderItem) map[string]interface{} {
+	snapshot := map[string]interface{}{
+		"item_id":         item.ID,
+		"product_id":      item.ProductID,
+		"quantity":        item.Quantity,
+		"unit_price":      numericToString(item.UnitPrice),
+		"discount_amount": numericToString(item.DiscountAmount),
+		"subtotal":        numericToString(item.Subtotal),
+		"status":          item.Status,
+	}
+	if item.VariantID.Valid {
+		snapshot["variant_id"] = uuid.UUID(item.VariantID.Bytes)
+	}
+	if len(item.VariantIds) > 1 {
+		snapshot["variant_ids"] = item.VariantIds
+	}
+	if item.ParentItemID.Valid {
+		snapshot["parent_item_id"] = uuid.UUID(item.ParentItemID.Bytes)
+	}
+	if item.Notes.Valid {
+		snapshot["notes"] = item.Notes.String
+	}
+	return snapshot
+}
+
+// createItemModifiers inserts an item's validated modifiers at their
+// order-time prices.
+func createItemModifiers(ctx context.Context, store OrderStore, itemID uuid.UUID, mods []service.SelectedModifier) ([]database.OrderItemModifier, error) {
+	created := make([]database.OrderItemModifier, 0, len(mods))
+	for _, mod := range mods {
+		itemMod, err := store.CreateOrderItemModifier(ctx, database.CreateOrderItemModifierParams{
+			OrderItemID: itemID,
+			ModifierID:  mod.ModifierID,
+			Quantity:    mod.Quantity,
+			UnitPrice:   decimalToNumeric(mod.UnitPrice),
+		})
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, itemMod)
+	}
+	return created, nil
+}
+
+// ensureNotSplit rejects item changes on an order that has been split into
+// checks, since check amounts are fixed at split time. Writes the error
+// response and returns false if the request should stop.
+func (h *OrderHandler) ensureNotSplit(w http.ResponseWriter, r *http.Request, orderID uuid.UUID) bool {
+	checkCount, err := h.store.CountOrderChecks(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: count order checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return false
+	}
+	if checkCount > 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "order is split into checks; remove the split first"})
+		return false
+	}
+	return true
+}
+
+// orderTotalsStore is the subset of stores that can recompute an order's totals.
+type orderTotalsStore interface {
+	UpdateOrderTotals(ctx context.Context, orderID uuid.UUID) (database.Order, error)
+	service.OrderPromotionStore
+	service.ChargeStore
+}
+
+// recalculateOrderTotals recomputes subtotal and discount from the order's
+// items, re-evaluates its promotion, then reapplies the outlet's service
+// charge and tax. actorID is recorded if the promotion changes.
+func recalculateOrderTotals(ctx context.Context, store orderTotalsStore, orderID, actorID uuid.UUID) (database.Order, error) {
+	order, err := store.UpdateOrderTotals(ctx, orderID)
+	if err != nil {
+		return database.Order{}, err
+	}
+	order, err = service.ApplyOrderPromotion(ctx, store, order, actorID)
+	if err != nil {
+		return database.Order{}, err
+	}
+	return service.ApplyOrderCharges(ctx, store, order)
+}
+
 // activeOrderRowToOrder converts a ListActiveOrdersRow to a database.Order
 // so we can reuse dbOrderToResponse.
 func activeOrderRowToOrder(row database.ListActiveOrdersRow) database.Order {
 	return database.Order{
-		ID:               row.ID,
-		OutletID:         row.OutletID,
-		OrderNumber:      row.OrderNumber,
-		CustomerID:       row.CustomerID,
-		OrderType:        row.OrderType,
-		Status:           row.Status,
-		TableNumber:      row.TableNumber,
-		Notes:            row.Notes,
-		Subtotal:         row.Subtotal,
-		DiscountType:     row.DiscountType,
-		DiscountValue:    row.DiscountValue,
-		DiscountAmount:   row.DiscountAmount,
-		TaxAmount:        row.TaxAmount,
-		TotalAmount:      row.TotalAmount,
-		CateringDate:     row.CateringDate,
-		CateringStatus:   row.CateringStatus,
-		CateringDpAmount: row.CateringDpAmount,
-		DeliveryPlatform: row.DeliveryPlatform,
-		DeliveryAddress:  row.DeliveryAddress,
-		CreatedBy:        row.CreatedBy,
-		CompletedAt:      row.CompletedAt,
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
+		ID:                  row.ID,
+		OutletID:            row.OutletID,
+		OrderNumber:         row.OrderNumber,
+		CustomerID:          row.CustomerID,
+		OrderType:           row.OrderType,
+		Status:              row.Status,
+		TableNumber:         row.TableNumber,
+		Notes:               row.Notes,
+		Subtotal:            row.Subtotal,
+		DiscountType:        row.DiscountType,
+		DiscountValue:       row.DiscountValue,
+		DiscountAmount:      row.DiscountAmount,
+		TaxAmount:           row.TaxAmount,
+		TotalAmount:         row.TotalAmount,
+		ServiceChargeAmount: row.ServiceChargeAmount,
+		ServiceChargeRate:   row.ServiceChargeRate,
+		TaxRate:             row.TaxRate,
+		PricesIncludeTax:    row.PricesIncludeTax,
+		PromotionID:         row.PromotionID,
+		PromotionCode:       row.PromotionCode,
+		PromotionAmount:     row.PromotionAmount,
+		CateringDate:        row.CateringDate,
+		CateringStatus:      row.CateringStatus,
+		CateringDpAmount:    row.CateringDpAmount,
+		DeliveryPlatform:    row.DeliveryPlatform,
+		DeliveryAddress:     row.DeliveryAddress,
+		CreatedBy:           row.CreatedBy,
+		CompletedAt:         row.CompletedAt,
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
 	}
 }
 
@@ -1370,10 +1864,91 @@ func formatItemError(idx int, msg string) string {
 	return "items[" + strconv.Itoa(idx) + "]: " + msg
 }
 
+// toService builds the service request for an order created by userID.
+func (req createOrderRequest) toService(outletID, userID uuid.UUID) service.CreateOrderRequest {
+	svcItems := make([]service.CreateOrderItemRequest, len(req.Items))
+	for i, item := range req.Items {
+		svcItems[i] = service.CreateOrderItemRequest{
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			VariantIDs:    item.VariantIDs,
+			Quantity:      item.Quantity,
+			Notes:         item.Notes,
+			DiscountType:  item.DiscountType,
+			DiscountValue: item.DiscountValue,
+			Modifiers:     toServiceModifiers(item.Modifiers),
+		}
+	}
+
+	return service.CreateOrderRequest{
+		OutletID:         outletID,
+		CreatedBy:        userID,
+		OrderType:        req.OrderType,
+		TableNumber:      req.TableNumber,
+		CustomerID:       req.CustomerID,
+		Notes:            req.Notes,
+		DiscountType:     req.DiscountType,
+		DiscountValue:    req.DiscountValue,
+		PromotionCode:    strings.ToUpper(strings.TrimSpace(req.PromotionCode)),
+		CateringDate:     req.CateringDate,
+		CateringDpAmount: req.CateringDpAmount,
+		DeliveryPlatform: req.DeliveryPlatform,
+		DeliveryAddress:  req.DeliveryAddress,
+		Items:            svcItems,
+	}
+}
+
+// toServiceModifiers converts request modifiers to the service type.
+func toServiceModifiers(reqs []createOrderItemModifierRequest) []service.CreateOrderItemModifierRequest {
+	mods := make([]service.CreateOrderItemModifierRequest, len(reqs))
+	for i, mod := range reqs {
+		mods[i] = service.CreateOrderItemModifierRequest{
+			ModifierID: mod.ModifierID,
+			Quantity:   mod.Quantity,
+		}
+	}
+	return mods
+}
+
+// mergeVariantIDs puts the legacy single variant_id ahead of variant_ids.
+func mergeVariantIDs(variantID string, variantIDs []string) []string {
+	if variantID == "" {
+		return variantIDs
+	}
+	return append([]string{variantID}, variantIDs...)
+}
+
+// writeSelectionError writes a 400 listing every group rule each item breaks.
+func writeSelectionError(w http.ResponseWriter, selErr *service.SelectionError) {
+	resp := selectionErrorResponse{
+		Error: "items do not satisfy product options",
+		Items: make([]itemSelectionResponse, len(selErr.Items)),
+	}
+	for i, item := range selErr.Items {
+		violations := make([]groupViolationResponse, len(item.Violations))
+		for j, v := range item.Violations {
+			violations[j] = groupViolationResponse{
+				GroupID:   v.GroupID,
+				GroupName: v.GroupName,
+				Rule:      v.Rule,
+				Message:   v.Message,
+			}
+		}
+		resp.Items[i] = itemSelectionResponse{
+			Index:      item.Index,
+			ProductID:  item.ProductID,
+			Violations: violations,
+		}
+	}
+	writeJSON(w, http.StatusBadRequest, resp)
+}
+
 // isValidationError checks if the error is a known validation error
 // from the service layer that should result in 400 Bad Request.
 func isValidationError(err error) bool {
-	return errors.Is(err, service.ErrEmptyItems) ||
+	var selErr *service.SelectionError
+	return errors.As(err, &selErr) ||
+		errors.Is(err, service.ErrEmptyItems) ||
 		errors.Is(err, service.ErrInvalidOrderType) ||
 		errors.Is(err, service.ErrInvalidQuantity) ||
 		errors.Is(err, service.ErrProductNotFound) ||
@@ -1390,24 +1965,31 @@ func isValidationError(err error) bool {
 		errors.Is(err, service.ErrInvalidDiscountValue) ||
 		errors.Is(err, service.ErrInvalidCustomerID) ||
 		errors.Is(err, service.ErrInvalidCateringDate) ||
-		errors.Is(err, service.ErrInvalidCateringDpAmt)
+		errors.Is(err, service.ErrInvalidCateringDpAmt) ||
+		errors.Is(err, service.ErrInvalidPromotionCode) ||
+		errors.Is(err, service.ErrPromotionNotMet)
 }
 
 func toOrderResponse(result *service.CreateOrderResult) orderResponse {
 	o := result.Order
 	resp := orderResponse{
-		ID:             o.ID,
-		OutletID:       o.OutletID,
-		OrderNumber:    o.OrderNumber,
-		OrderType:      o.OrderType,
-		Status:         o.Status,
-		Subtotal:       numericToString(o.Subtotal),
-		DiscountAmount: numericToString(o.DiscountAmount),
-		TaxAmount:      numericToString(o.TaxAmount),
-		TotalAmount:    numericToString(o.TotalAmount),
-		CreatedBy:      o.CreatedBy,
-		CreatedAt:      o.CreatedAt,
-		UpdatedAt:      o.UpdatedAt,
+		ID:                  o.ID,
+		OutletID:            o.OutletID,
+		OrderNumber:         o.OrderNumber,
+		OrderType:           o.OrderType,
+		Status:              o.Status,
+		Subtotal:            numericToString(o.Subtotal),
+		DiscountAmount:      numericToString(o.DiscountAmount),
+		PromotionAmount:     numericToString(o.PromotionAmount),
+		ServiceChargeAmount: numericToString(o.ServiceChargeAmount),
+		ServiceChargeRate:   numericToString(o.ServiceChargeRate),
+		TaxAmount:           numericToString(o.TaxAmount),
+		TaxRate:             numericToString(o.TaxRate),
+		PricesIncludeTax:    o.PricesIncludeTax,
+		TotalAmount:         numericToString(o.TotalAmount),
+		CreatedBy:           o.CreatedBy,
+		CreatedAt:           o.CreatedAt,
+		UpdatedAt:           o.UpdatedAt,
 	}
 
 	if o.CustomerID.Valid {
@@ -1428,6 +2010,14 @@ func toOrderResponse(result *service.CreateOrderResult) orderResponse {
 		s := numericToString(o.DiscountValue)
 		resp.DiscountValue = &s
 	}
+	if o.PromotionID.Valid {
+		s := uuid.UUID(o.PromotionID.Bytes).String()
+		resp.PromotionID = &s
+	}
+	if o.PromotionCode.Valid {
+		s := o.PromotionCode.String
+		resp.PromotionCode = &s
+	}
 	if o.CateringDate.Valid {
 		resp.CateringDate = &o.CateringDate.Time
 	}
@@ -1464,12 +2054,17 @@ func toOrderItemResponse(ir service.OrderItemResult) orderItemResponse {
 		DiscountAmount: numericToString(item.DiscountAmount),
 		Subtotal:       numericToString(item.Subtotal),
 		Status:         item.Status,
+		SentAt:         item.SentAt,
 	}
 
 	if item.VariantID.Valid {
 		s := uuid.UUID(item.VariantID.Bytes).String()
 		resp.VariantID = &s
 	}
+	resp.VariantIDs = item.VariantIds
+	if resp.VariantIDs == nil {
+		resp.VariantIDs = []uuid.UUID{}
+	}
 	if item.DiscountType.Valid {
 		s := item.DiscountType.String
 		resp.DiscountType = &s
@@ -1485,6 +2080,16 @@ func toOrderItemResponse(ir service.OrderItemResult) orderItemResponse {
 		s := item.Station.String
 		resp.Station = &s
 	}
+	if item.ParentItemID.Valid {
+		s := uuid.UUID(item.ParentItemID.Bytes).String()
+		resp.ParentItemID = &s
+	}
+	if item.StartedAt.Valid {
+		resp.StartedAt = &item.StartedAt.Time
+	}
+	if item.ReadyAt.Valid {
+		resp.ReadyAt = &item.ReadyAt.Time
+	}
 
 	resp.Modifiers = make([]orderItemModifierResponse, len(ir.Modifiers))
 	for j, mod := range ir.Modifiers {
@@ -1519,18 +2124,23 @@ func numericToString(n pgtype.Numeric) string {
 // directly with the DB model for the read endpoints.
 func dbOrderToResponse(o database.Order) orderResponse {
 	resp := orderResponse{
-		ID:             o.ID,
-		OutletID:       o.OutletID,
-		OrderNumber:    o.OrderNumber,
-		OrderType:      o.OrderType,
-		Status:         o.Status,
-		Subtotal:       numericToString(o.Subtotal),
-		DiscountAmount: numericToString(o.DiscountAmount),
-		TaxAmount:      numericToString(o.TaxAmount),
-		TotalAmount:    numericToString(o.TotalAmount),
-		CreatedBy:      o.CreatedBy,
-		CreatedAt:      o.CreatedAt,
-		UpdatedAt:      o.UpdatedAt,
+		ID:                  o.ID,
+		OutletID:            o.OutletID,
+		OrderNumber:         o.OrderNumber,
+		OrderType:           o.OrderType,
+		Status:              o.Status,
+		Subtotal:            numericToString(o.Subtotal),
+		DiscountAmount:      numericToString(o.DiscountAmount),
+		PromotionAmount:     numericToString(o.PromotionAmount),
+		ServiceChargeAmount: numericToString(o.ServiceChargeAmount),
+		ServiceChargeRate:   numericToString(o.ServiceChargeRate),
+		TaxAmount:           numericToString(o.TaxAmount),
+		TaxRate:             numericToString(o.TaxRate),
+		PricesIncludeTax:    o.PricesIncludeTax,
+		TotalAmount:         numericToString(o.TotalAmount),
+		CreatedBy:           o.CreatedBy,
+		CreatedAt:           o.CreatedAt,
+		UpdatedAt:           o.UpdatedAt,
 	}
 
 	if o.CustomerID.Valid {
@@ -1551,6 +2161,14 @@ func dbOrderToResponse(o database.Order) orderResponse {
 		s := numericToString(o.DiscountValue)
 		resp.DiscountValue = &s
 	}
+	if o.PromotionID.Valid {
+		s := uuid.UUID(o.PromotionID.Bytes).String()
+		resp.PromotionID = &s
+	}
+	if o.PromotionCode.Valid {
+		s := o.PromotionCode.String
+		resp.PromotionCode = &s
+	}
 	if o.CateringDate.Valid {
 		resp.CateringDate = &o.CateringDate.Time
 	}
@@ -1582,12 +2200,17 @@ func dbOrderItemToResponse(item database.OrderItem, mods []database.OrderItemMod
 		DiscountAmount: numericToString(item.DiscountAmount),
 		Subtotal:       numericToString(item.Subtotal),
 		Status:         item.Status,
+		SentAt:         item.SentAt,
 	}
 
 	if item.VariantID.Valid {
 		s := uuid.UUID(item.VariantID.Bytes).String()
 		resp.VariantID = &s
 	}
+	resp.VariantIDs = item.VariantIds
+	if resp.VariantIDs == nil {
+		resp.VariantIDs = []uuid.UUID{}
+	}
 	if item.DiscountType.Valid {
 		s := item.DiscountType.String
 		resp.DiscountType = &s
@@ -1603,6 +2226,16 @@ func dbOrderItemToResponse(item database.OrderItem, mods []database.OrderItemMod
 		s := item.Station.String
 		resp.Station = &s
 	}
+	if item.ParentItemID.Valid {
+		s := uuid.UUID(item.ParentItemID.Bytes).String()
+		resp.ParentItemID = &s
+	}
+	if item.StartedAt.Valid {
+		resp.StartedAt = &item.StartedAt.Time
+	}
+	if item.ReadyAt.Valid {
+		resp.ReadyAt = &item.ReadyAt.Time
+	}
 
 	resp.Modifiers = make([]orderItemModifierResponse, len(mods))
 	for j, mod := range mods {
@@ -1620,13 +2253,14 @@ func dbOrderItemToResponse(item database.OrderItem, mods []database.OrderItemMod
 // dbPaymentToResponse converts a database.Payment to a paymentResponse.
 func dbPaymentToResponse(p database.Payment) paymentResponse {
 	resp := paymentResponse{
-		ID:            p.ID,
-		OrderID:       p.OrderID,
-		PaymentMethod: p.PaymentMethod,
-		Amount:        numericToString(p.Amount),
-		Status:        p.Status,
-		ProcessedBy:   p.ProcessedBy,
-		ProcessedAt:   p.ProcessedAt,
+		ID:             p.ID,
+		OrderID:        p.OrderID,
+		PaymentMethod:  p.PaymentMethod,
+		Amount:         numericToString(p.Amount),
+		Status:         p.Status,
+		RefundedAmount: numericToString(p.RefundedAmount),
+		ProcessedBy:    p.ProcessedBy,
+		ProcessedAt:    p.ProcessedAt,
 	}
 	if p.ReferenceNumber.Valid {
 		resp.ReferenceNumber = &p.ReferenceNumber.String
@@ -1639,6 +2273,10 @@ func dbPaymentToResponse(p database.Payment) paymentResponse {
 		s := numericToString(p.ChangeAmount)
 		resp.ChangeAmount = &s
 	}
+	if p.CheckID.Valid {
+		s := uuid.UUID(p.CheckID.Bytes).String()
+		resp.CheckID = &s
+	}
 	return resp
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -21,24 +22,33 @@ import (
 	"github.com/kiwari-pos/api/internal/handler"
 	"github.com/kiwari-pos/api/internal/middleware"
 	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
 )
 
 // --- Mock OrderServicer ---
 
 type mockOrderService struct {
 	createFn func(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error)
+	syncFn   func(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error)
 }
 
 func (m *mockOrderService) CreateOrder(ctx context.Context, req service.CreateOrderRequest) (*service.CreateOrderResult, error) {
 	return m.createFn(ctx, req)
 }
 
+func (m *mockOrderService) SyncOrder(ctx context.Context, req service.SyncOrderRequest) (*service.SyncOrderResult, error) {
+	return m.syncFn(ctx, req)
+}
+
 // --- Mock OrderStore ---
 
 type mockOrderStore struct {
+	mockIdempotencyStore
+	mockLoyaltyStore
 	getOrderFn                func(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
 	listOrdersFn              func(ctx context.Context, arg database.ListOrdersParams) ([]database.Order, error)
 	listActiveOrdersFn        func(ctx context.Context, arg database.ListActiveOrdersParams) ([]database.ListActiveOrdersRow, error)
+	listKitchenQueueItemsFn   func(ctx context.Context, outletID uuid.UUID) ([]database.ListKitchenQueueItemsRow, error)
 	listOrderItemsByOrderFn   func(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error)
 	listOrderItemModifiersFn  func(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error)
 	listPaymentsByOrderFn     func(ctx context.Context, orderID uuid.UUID) ([]database.Payment, error)
@@ -50,11 +60,28 @@ type mockOrderStore struct {
 	updateOrderItemStatusFn   func(ctx context.Context, arg database.UpdateOrderItemStatusParams) (database.OrderItem, error)
 	countOrderItemsFn         func(ctx context.Context, orderID uuid.UUID) (int64, error)
 	updateOrderTotalsFn       func(ctx context.Context, orderID uuid.UUID) (database.Order, error)
+	updateOrderChargesFn      func(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error)
+	getOutletSettingsFn       func(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	countOrderChecksFn        func(ctx context.Context, orderID uuid.UUID) (int64, error)
+	createOrderEventFn        func(ctx context.Context, arg database.CreateOrderEventParams) error
+	listOrderEventsFn         func(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderEventsRow, error)
 	getProductForOrderFn      func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error)
 	getVariantForOrderFn      func(ctx context.Context, variantID uuid.UUID) (database.GetVariantForOrderRow, error)
 	getModifierForOrderFn     func(ctx context.Context, modifierID uuid.UUID) (database.GetModifierForOrderRow, error)
+	listVariantGroupsFn       func(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error)
+	listModifierGroupsFn      func(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error)
 	createOrderItemFn         func(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error)
 	createOrderItemModifierFn func(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error)
+	deleteOrderItemModsFn     func(ctx context.Context, orderItemID uuid.UUID) error
+	listComboComponentsFn     func(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error)
+	listChildOrderItemsFn     func(ctx context.Context, parentItemID pgtype.UUID) ([]database.OrderItem, error)
+	scaleChildItemsFn         func(ctx context.Context, arg database.ScaleChildItemQuantitiesParams) error
+	listKitchenTicketItemsFn  func(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error)
+	listKitchenTicketsFn      func(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error)
+	createKitchenTicketFn     func(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error)
+	listOrderPromotionsFn     func(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error)
+	listPromotionItemsFn      func(ctx context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error)
+	updateOrderPromotionFn    func(ctx context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error)
 }
 
 func (m *mockOrderStore) GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
@@ -78,6 +105,13 @@ func (m *mockOrderStore) ListActiveOrders(ctx context.Context, arg database.List
 	return []database.ListActiveOrdersRow{}, nil
 }
 
+func (m *mockOrderStore) ListKitchenQueueItems(ctx context.Context, outletID uuid.UUID) ([]database.ListKitchenQueueItemsRow, error) {
+	if m.listKitchenQueueItemsFn != nil {
+		return m.listKitchenQueueItemsFn(ctx, outletID)
+	}
+	return []database.ListKitchenQueueItemsRow{}, nil
+}
+
 func (m *mockOrderStore) ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
 	if m.listOrderItemsByOrderFn != nil {
 		return m.listOrderItemsByOrderFn(ctx, orderID)
@@ -155,6 +189,49 @@ func (m *mockOrderStore) UpdateOrderTotals(ctx context.Context, orderID uuid.UUI
 	return database.Order{}, pgx.ErrNoRows
 }
 
+func (m *mockOrderStore) UpdateOrderCharges(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error) {
+	if m.updateOrderChargesFn != nil {
+		return m.updateOrderChargesFn(ctx, arg)
+	}
+	return database.Order{
+		ID:                  arg.ID,
+		ServiceChargeAmount: arg.ServiceChargeAmount,
+		ServiceChargeRate:   arg.ServiceChargeRate,
+		TaxAmount:           arg.TaxAmount,
+		TaxRate:             arg.TaxRate,
+		PricesIncludeTax:    arg.PricesIncludeTax,
+		TotalAmount:         arg.TotalAmount,
+	}, nil
+}
+
+func (m *mockOrderStore) GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error) {
+	if m.getOutletSettingsFn != nil {
+		return m.getOutletSettingsFn(ctx, outletID)
+	}
+	return database.OutletSetting{}, pgx.ErrNoRows
+}
+
+func (m *mockOrderStore) CountOrderChecks(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	if m.countOrderChecksFn != nil {
+		return m.countOrderChecksFn(ctx, orderID)
+	}
+	return 0, nil
+}
+
+func (m *mockOrderStore) CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error {
+	if m.createOrderEventFn != nil {
+		return m.createOrderEventFn(ctx, arg)
+	}
+	return nil
+}
+
+func (m *mockOrderStore) ListOrderEvents(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderEventsRow, error) {
+	if m.listOrderEventsFn != nil {
+		return m.listOrderEventsFn(ctx, orderID)
+	}
+	return []database.ListOrderEventsRow{}, nil
+}
+
 func (m *mockOrderStore) GetProductForOrder(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error) {
 	if m.getProductForOrderFn != nil {
 		return m.getProductForOrderFn(ctx, arg)
@@ -176,6 +253,20 @@ func (m *mockOrderStore) GetModifierForOrder(ctx context.Context, modifierID uui
 	return database.GetModifierForOrderRow{}, pgx.ErrNoRows
 }
 
+func (m *mockOrderStore) ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error) {
+	if m.listVariantGroupsFn != nil {
+		return m.listVariantGroupsFn(ctx, productID)
+	}
+	return []database.ListVariantGroupsForOrderRow{}, nil
+}
+
+func (m *mockOrderStore) ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error) {
+	if m.listModifierGroupsFn != nil {
+		return m.listModifierGroupsFn(ctx, productID)
+	}
+	return []database.ListModifierGroupsForOrderRow{}, nil
+}
+
 func (m *mockOrderStore) CreateOrderItem(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error) {
 	if m.createOrderItemFn != nil {
 		return m.createOrderItemFn(ctx, arg)
@@ -190,6 +281,80 @@ func (m *mockOrderStore) CreateOrderItemModifier(ctx context.Context, arg databa
 	return database.OrderItemModifier{}, pgx.ErrNoRows
 }
 
+func (m *mockOrderStore) DeleteOrderItemModifiers(ctx context.Context, orderItemID uuid.UUID) error {
+	if m.deleteOrderItemModsFn != nil {
+		return m.deleteOrderItemModsFn(ctx, orderItemID)
+	}
+	return nil
+}
+
+func (m *mockOrderStore) ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error) {
+	if m.listComboComponentsFn != nil {
+		return m.listComboComponentsFn(ctx, comboID)
+	}
+	return []database.ListComboComponentsForOrderRow{}, nil
+}
+
+func (m *mockOrderStore) ListChildOrderItems(ctx context.Context, parentItemID pgtype.UUID) ([]database.OrderItem, error) {
+	if m.listChildOrderItemsFn != nil {
+		return m.listChildOrderItemsFn(ctx, parentItemID)
+	}
+	return []database.OrderItem{}, nil
+}
+
+func (m *mockOrderStore) ScaleChildItemQuantities(ctx context.Context, arg database.ScaleChildItemQuantitiesParams) error {
+	if m.scaleChildItemsFn != nil {
+		return m.scaleChildItemsFn(ctx, arg)
+	}
+	return nil
+}
+
+func (m *mockOrderStore) ListKitchenTicketItems(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error) {
+	if m.listKitchenTicketItemsFn != nil {
+		return m.listKitchenTicketItemsFn(ctx, orderID)
+	}
+	return []database.ListKitchenTicketItemsRow{}, nil
+}
+
+func (m *mockOrderStore) ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+	if m.listKitchenTicketsFn != nil {
+		return m.listKitchenTicketsFn(ctx, orderID)
+	}
+	return []database.KitchenTicket{}, nil
+}
+
+func (m *mockOrderStore) CreateKitchenTicket(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+	if m.createKitchenTicketFn != nil {
+		return m.createKitchenTicketFn(ctx, arg)
+	}
+	return database.KitchenTicket{ID: uuid.New(), OrderID: arg.OrderID, Station: arg.Station, TicketType: arg.TicketType, Lines: arg.Lines}, nil
+}
+
+func (m *mockOrderStore) LockOrderPromotions(ctx context.Context, arg database.LockOrderPromotionsParams) error {
+	return nil
+}
+
+func (m *mockOrderStore) ListOrderPromotions(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	if m.listOrderPromotionsFn != nil {
+		return m.listOrderPromotionsFn(ctx, arg)
+	}
+	return []database.ListOrderPromotionsRow{}, nil
+}
+
+func (m *mockOrderStore) ListPromotionItems(ctx context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error) {
+	if m.listPromotionItemsFn != nil {
+		return m.listPromotionItemsFn(ctx, orderID)
+	}
+	return []database.ListPromotionItemsRow{}, nil
+}
+
+func (m *mockOrderStore) UpdateOrderPromotion(ctx context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error) {
+	if m.updateOrderPromotionFn != nil {
+		return m.updateOrderPromotionFn(ctx, arg)
+	}
+	return database.Order{ID: arg.ID, PromotionID: arg.PromotionID, PromotionAmount: arg.PromotionAmount}, nil
+}
+
 // --- Mock TxBeginner ---
 
 type mockTx struct {
@@ -277,7 +442,7 @@ func setupOrderRouterWithAuth(svc *mockOrderService, claims *auth.Claims) *chi.M
 func setupOrderRouterWithStore(svc *mockOrderService, store *mockOrderStore, claims *auth.Claims) *chi.Mux {
 	pool := &mockPool{}
 	newStore := mockNewStore(store)
-	h := handler.NewOrderHandler(svc, store, pool, newStore)
+	h := handler.NewOrderHandler(svc, store, pool, newStore, nil)
 	r := chi.NewRouter()
 	r.Use(middleware.Authenticate(testJWTSecret))
 	r.Route("/outlets/{oid}/orders", h.RegisterRoutes)
@@ -646,7 +811,7 @@ func TestOrderCreate_NoAuth(t *testing.T) {
 	store := &mockOrderStore{}
 	pool := &mockPool{}
 	newStore := mockNewStore(store)
-	h := handler.NewOrderHandler(svc, store, pool, newStore)
+	h := handler.NewOrderHandler(svc, store, pool, newStore, nil)
 	r := chi.NewRouter()
 	r.Use(middleware.Authenticate(testJWTSecret))
 	r.Route("/outlets/{oid}/orders", h.RegisterRoutes)
@@ -1342,7 +1507,7 @@ func TestOrderList_NoAuth(t *testing.T) {
 	store := &mockOrderStore{}
 	pool := &mockPool{}
 	newStore := mockNewStore(store)
-	h := handler.NewOrderHandler(nil, store, pool, newStore)
+	h := handler.NewOrderHandler(nil, store, pool, newStore, nil)
 	r := chi.NewRouter()
 	r.Use(middleware.Authenticate(testJWTSecret))
 	r.Route("/outlets/{oid}/orders", h.RegisterRoutes)
@@ -1860,6 +2025,169 @@ func TestOrderUpdateStatus_InvalidOrderID(t *testing.T) {
 	}
 }
 
+func TestOrderUpdateStatus_RecordsHistory(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	updatedOrder := order
+	updatedOrder.Status = enum.OrderStatusPreparing
+
+	var events []database.CreateOrderEventParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		updateOrderStatusFn: func(ctx context.Context, arg database.UpdateOrderStatusParams) (database.Order, error) {
+			return updatedOrder, nil
+		},
+		createOrderEventFn: func(ctx context.Context, arg database.CreateOrderEventParams) error {
+			events = append(events, arg)
+			return nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/status",
+		map[string]string{"status": "PREPARING"}, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(events) != 1 {
+		t.Fatalf("events: got %d, want 1", len(events))
+	}
+	e := events[0]
+	if e.EventType != enum.OrderEventStatusChanged {
+		t.Errorf("event_type: got %s, want %s", e.EventType, enum.OrderEventStatusChanged)
+	}
+	if e.ActorID != claims.UserID {
+		t.Errorf("actor_id: got %s, want %s", e.ActorID, claims.UserID)
+	}
+	if string(e.BeforeValue) != `{"status":"NEW"}` {
+		t.Errorf("before: got %s", e.BeforeValue)
+	}
+	if string(e.AfterValue) != `{"status":"PREPARING"}` {
+		t.Errorf("after: got %s", e.AfterValue)
+	}
+}
+
+func TestOrderUpdateStatus_HistoryError(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	updatedOrder := order
+	updatedOrder.Status = enum.OrderStatusPreparing
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		updateOrderStatusFn: func(ctx context.Context, arg database.UpdateOrderStatusParams) (database.Order, error) {
+			return updatedOrder, nil
+		},
+		createOrderEventFn: func(ctx context.Context, arg database.CreateOrderEventParams) error {
+			return errors.New("db down")
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "PATCH", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/status",
+		map[string]string{"status": "PREPARING"}, claims)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusInternalServerError, rr.Body.String())
+	}
+}
+
+// --- History endpoint tests ---
+
+func TestOrderHistory_Success(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	order := testDBOrder(outletID)
+	now := time.Now()
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		listOrderEventsFn: func(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderEventsRow, error) {
+			if orderID != order.ID {
+				t.Errorf("order_id: got %s, want %s", orderID, order.ID)
+			}
+			return []database.ListOrderEventsRow{
+				{
+					ID:         uuid.New(),
+					OrderID:    order.ID,
+					EventType:  enum.OrderEventCreated,
+					ActorID:    claims.UserID,
+					ActorName:  "Kasir Satu",
+					AfterValue: []byte(`{"status":"NEW"}`),
+					CreatedAt:  now,
+				},
+				{
+					ID:          uuid.New(),
+					OrderID:     order.ID,
+					EventType:   enum.OrderEventStatusChanged,
+					ActorID:     claims.UserID,
+					ActorName:   "Kasir Satu",
+					BeforeValue: []byte(`{"status":"NEW"}`),
+					AfterValue:  []byte(`{"status":"PREPARING"}`),
+					CreatedAt:   now.Add(time.Minute),
+				},
+			}, nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/orders/"+order.ID.String()+"/history", nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("events: got %d, want 2", len(resp))
+	}
+	if resp[0]["event_type"] != enum.OrderEventCreated {
+		t.Errorf("event_type: got %v, want %s", resp[0]["event_type"], enum.OrderEventCreated)
+	}
+	if resp[0]["before"] != nil {
+		t.Errorf("before: got %v, want null", resp[0]["before"])
+	}
+	if resp[0]["actor_name"] != "Kasir Satu" {
+		t.Errorf("actor_name: got %v, want Kasir Satu", resp[0]["actor_name"])
+	}
+	after, ok := resp[1]["after"].(map[string]interface{})
+	if !ok || after["status"] != "PREPARING" {
+		t.Errorf("after: got %v, want {status: PREPARING}", resp[1]["after"])
+	}
+}
+
+func TestOrderHistory_NotFound(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return database.Order{}, pgx.ErrNoRows
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/orders/"+uuid.New().String()+"/history", nil, claims)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusNotFound, rr.Body.String())
+	}
+}
+
 // --- Cancel endpoint tests ---
 
 func TestOrderCancel_HappyPath_NewOrder(t *testing.T) {
@@ -2026,6 +2354,46 @@ func TestOrderCancel_ReadyOrder(t *testing.T) {
 	}
 }
 
+func TestOrderCancel_ReturnsRedeemedPoints(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	customerID := uuid.New()
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	order.CustomerID = pgtype.UUID{Bytes: customerID, Valid: true}
+	cancelledOrder := order
+	cancelledOrder.Status = enum.OrderStatusCancelled
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		cancelOrderFn: func(ctx context.Context, arg database.CancelOrderParams) (database.Order, error) {
+			return cancelledOrder, nil
+		},
+	}
+	store.addPoints(customerID, 100)
+	store.loyaltyTxns = append(store.loyaltyTxns, database.LoyaltyTransaction{
+		ID:              uuid.New(),
+		CustomerID:      customerID,
+		OrderID:         pgtype.UUID{Bytes: order.ID, Valid: true},
+		PaymentID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TransactionType: enum.LoyaltyTransactionRedeem,
+		Points:          -60,
+		Amount:          decimalToNumeric(decimal.NewFromInt(6000)),
+	})
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+order.ID.String(), nil, claims)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 100 {
+		t.Errorf("balance: got %d, want 100 after the redeemed points came back", got)
+	}
+}
+
 // --- Item modification endpoint tests ---
 
 func TestAddItem_HappyPath(t *testing.T) {
@@ -2098,12 +2466,12 @@ func TestAddItem_HappyPath(t *testing.T) {
 	}
 }
 
-func TestAddItem_OrderNotNew(t *testing.T) {
+func TestAddItem_OrderCompleted(t *testing.T) {
 	outletID := uuid.New()
 	claims := testClaims(outletID)
 	orderID := uuid.New()
 
-	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusCompleted)
 	order.ID = orderID
 
 	store := &mockOrderStore{
@@ -2235,6 +2603,128 @@ func TestRemoveItem_HappyPath(t *testing.T) {
 	}
 }
 
+func TestAddItem_RecalculatesTaxAndServiceCharge(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	orderID := uuid.New()
+	productID := uuid.New()
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	order.ID = orderID
+
+	// After the item is added: subtotal 50000, charges still from the old state.
+	totalsOrder := order
+	totalsOrder.Subtotal = testNumeric("50000.00")
+	totalsOrder.TotalAmount = testNumeric("50000.00")
+
+	var chargesArg *database.UpdateOrderChargesParams
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		getProductForOrderFn: func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error) {
+			return database.GetProductForOrderRow{ID: productID, OutletID: outletID, BasePrice: testNumeric("25000.00")}, nil
+		},
+		createOrderItemFn: func(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error) {
+			return database.OrderItem{ID: uuid.New(), OrderID: orderID, ProductID: productID, Quantity: arg.Quantity, Status: enum.OrderItemStatusPending}, nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return totalsOrder, nil
+		},
+		getOutletSettingsFn: func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+			return database.OutletSetting{
+				OutletID:                oid,
+				TaxRate:                 testNumeric("10"),
+				TaxOrderTypes:           []string{enum.OrderTypeDineIn},
+				ServiceChargeRate:       testNumeric("5"),
+				ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+				RoundingMode:            enum.RoundingModeHalfUp,
+				RoundingUnit:            testNumeric("1"),
+			}, nil
+		},
+		updateOrderChargesFn: func(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error) {
+			chargesArg = &arg
+			o := totalsOrder
+			o.ServiceChargeAmount = arg.ServiceChargeAmount
+			o.ServiceChargeRate = arg.ServiceChargeRate
+			o.TaxAmount = arg.TaxAmount
+			o.TaxRate = arg.TaxRate
+			o.TotalAmount = arg.TotalAmount
+			return o, nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/items", map[string]interface{}{
+		"product_id": productID.String(),
+		"quantity":   2,
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if chargesArg == nil {
+		t.Fatal("expected UpdateOrderCharges to be called")
+	}
+	if chargesArg.ID != orderID {
+		t.Errorf("charges order ID: got %s, want %s", chargesArg.ID, orderID)
+	}
+
+	// service = 2500, tax = 10% of 52500 = 5250, total = 57750
+	resp := decodeOrderResponse(t, rr)
+	orderResp := resp["order"].(map[string]interface{})
+	if orderResp["service_charge_amount"] != "2500.00" {
+		t.Errorf("service_charge_amount: got %v, want 2500.00", orderResp["service_charge_amount"])
+	}
+	if orderResp["tax_amount"] != "5250.00" {
+		t.Errorf("tax_amount: got %v, want 5250.00", orderResp["tax_amount"])
+	}
+	if orderResp["tax_rate"] != "10.00" {
+		t.Errorf("tax_rate: got %v, want 10.00", orderResp["tax_rate"])
+	}
+	if orderResp["total_amount"] != "57750.00" {
+		t.Errorf("total_amount: got %v, want 57750.00", orderResp["total_amount"])
+	}
+}
+
+func TestRemoveItem_ChargesUpdateError(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	orderID := uuid.New()
+	itemID := uuid.New()
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusNew)
+	order.ID = orderID
+
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		countOrderItemsFn: func(ctx context.Context, orderID uuid.UUID) (int64, error) {
+			return 2, nil
+		},
+		getOrderItemFn: func(ctx context.Context, arg database.GetOrderItemParams) (database.OrderItem, error) {
+			return database.OrderItem{ID: itemID, OrderID: orderID}, nil
+		},
+		deleteOrderItemFn: func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+			return nil
+		},
+		updateOrderTotalsFn: func(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+			return order, nil
+		},
+		getOutletSettingsFn: func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+			return database.OutletSetting{}, errors.New("connection refused")
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/items/"+itemID.String(), nil, claims)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusInternalServerError, rr.Body.String())
+	}
+}
+
 func TestRemoveItem_LastItem(t *testing.T) {
 	outletID := uuid.New()
 	claims := testClaims(outletID)
@@ -2701,13 +3191,13 @@ func TestAddItem_OrderNotFound(t *testing.T) {
 
 // --- Additional UpdateItem Tests ---
 
-func TestUpdateItem_OrderNotNew(t *testing.T) {
+func TestUpdateItem_OrderCompleted(t *testing.T) {
 	outletID := uuid.New()
 	claims := testClaims(outletID)
 	orderID := uuid.New()
 	itemID := uuid.New()
 
-	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusCompleted)
 	order.ID = orderID
 
 	store := &mockOrderStore{
@@ -2781,13 +3271,47 @@ func TestUpdateItem_ZeroQuantity(t *testing.T) {
 
 // --- Additional RemoveItem Tests ---
 
-func TestRemoveItem_OrderNotNew(t *testing.T) {
+func TestRemoveItem_SplitOrder(t *testing.T) {
 	outletID := uuid.New()
 	claims := testClaims(outletID)
 	orderID := uuid.New()
 	itemID := uuid.New()
 
-	order := testDBOrderWithStatus(outletID, enum.OrderStatusPreparing)
+	order := testDBOrder(outletID)
+	order.ID = orderID
+
+	deleted := false
+	store := &mockOrderStore{
+		getOrderFn: func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+			return order, nil
+		},
+		countOrderChecksFn: func(ctx context.Context, oid uuid.UUID) (int64, error) {
+			return 2, nil
+		},
+		deleteOrderItemFn: func(ctx context.Context, arg database.DeleteOrderItemParams) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	router := setupOrderRouterWithStore(nil, store, claims)
+	rr := doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/items/"+itemID.String(), nil, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if deleted {
+		t.Error("expected item not to be deleted from a split order")
+	}
+}
+
+func TestRemoveItem_OrderCompleted(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	orderID := uuid.New()
+	itemID := uuid.New()
+
+	order := testDBOrderWithStatus(outletID, enum.OrderStatusCompleted)
 	order.ID = orderID
 
 	store := &mockOrderStore{
@@ -3214,7 +3738,7 @@ func TestListActive_Unauthenticated(t *testing.T) {
 	router.Use(middleware.Authenticate(testJWTSecret))
 	pool := &mockPool{}
 	newStore := mockNewStore(store)
-	h := handler.NewOrderHandler(svc, store, pool, newStore)
+	h := handler.NewOrderHandler(svc, store, pool, newStore, nil)
 	router.Route("/outlets/{oid}/orders", h.RegisterRoutes)
 
 	req := httptest.NewRequest("GET", "/outlets/"+outletID.String()+"/orders/active", nil)
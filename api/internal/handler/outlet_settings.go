@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/receipt"
+	"github.com/shopspring/decimal"
+)
+
+// OutletSettingsStore defines the database methods needed by outlet settings handlers.
+// Satisfied by *database.Queries; narrow interface for testability.
+type OutletSettingsStore interface {
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	UpsertOutletSettings(ctx context.Context, arg database.UpsertOutletSettingsParams) (database.OutletSetting, error)
+}
+
+// OutletSettingsHandler handles per-outlet tax, service charge and receipt settings.
+type OutletSettingsHandler struct {
+	store OutletSettingsStore
+}
+
+// NewOutletSettingsHandler creates a new OutletSettingsHandler.
+func NewOutletSettingsHandler(store OutletSettingsStore) *OutletSettingsHandler {
+	return &OutletSettingsHandler{store: store}
+}
+
+// RegisterRoutes registers outlet settings endpoints on the given Chi router.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/settings
+func (h *OutletSettingsHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.Get)
+	r.Put("/", h.Update)
+}
+
+// --- Request / Response types ---
+
+type updateOutletSettingsRequest struct {
+	TaxRate                 string   `json:"tax_rate"`
+	PricesIncludeTax        bool     `json:"prices_include_tax"`
+	TaxOrderTypes           []string `json:"tax_order_types"`
+	ServiceChargeRate       string   `json:"service_charge_rate"`
+	ServiceChargeOrderTypes []string `json:"service_charge_order_types"`
+	RoundingMode            string   `json:"rounding_mode"`
+	RoundingUnit            string   `json:"rounding_unit"`
+	ReceiptHeader           string   `json:"receipt_header"`
+	ReceiptFooter           string   `json:"receipt_footer"`
+	ReceiptPaperWidth       int32    `json:"receipt_paper_width"`
+}
+
+type outletSettingsResponse struct {
+	OutletID                uuid.UUID  `json:"outlet_id"`
+	TaxRate                 string     `json:"tax_rate"`
+	PricesIncludeTax        bool       `json:"prices_include_tax"`
+	TaxOrderTypes           []string   `json:"tax_order_types"`
+	ServiceChargeRate       string     `json:"service_charge_rate"`
+	ServiceChargeOrderTypes []string   `json:"service_charge_order_types"`
+	RoundingMode            string     `json:"rounding_mode"`
+	RoundingUnit            string     `json:"rounding_unit"`
+	ReceiptHeader           string     `json:"receipt_header"`
+	ReceiptFooter           string     `json:"receipt_footer"`
+	ReceiptPaperWidth       int32      `json:"receipt_paper_width"`
+	UpdatedAt               *time.Time `json:"updated_at"`
+}
+
+func toOutletSettingsResponse(s database.OutletSetting) outletSettingsResponse {
+	return outletSettingsResponse{
+		OutletID:                s.OutletID,
+		TaxRate:                 numericToString(s.TaxRate),
+		PricesIncludeTax:        s.PricesIncludeTax,
+		TaxOrderTypes:           s.TaxOrderTypes,
+		ServiceChargeRate:       numericToString(s.ServiceChargeRate),
+		ServiceChargeOrderTypes: s.ServiceChargeOrderTypes,
+		RoundingMode:            s.RoundingMode,
+		RoundingUnit:            numericToString(s.RoundingUnit),
+		ReceiptHeader:           s.ReceiptHeader,
+		ReceiptFooter:           s.ReceiptFooter,
+		ReceiptPaperWidth:       s.ReceiptPaperWidth,
+		UpdatedAt:               &s.UpdatedAt,
+	}
+}
+
+// defaultOutletSettingsResponse mirrors the column defaults of outlet_settings,
+// returned for outlets that have never saved settings (no charges applied).
+func defaultOutletSettingsResponse(outletID uuid.UUID) outletSettingsResponse {
+	return outletSettingsResponse{
+		OutletID:         outletID,
+		TaxRate:          "0.00",
+		PricesIncludeTax: false,
+		TaxOrderTypes: []string{
+			enum.OrderTypeDineIn, enum.OrderTypeTakeaway,
+			enum.OrderTypeDelivery, enum.OrderTypeCatering,
+		},
+		ServiceChargeRate:       "0.00",
+		ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+		RoundingMode:            enum.RoundingModeHalfUp,
+		RoundingUnit:            "1.00",
+		ReceiptPaperWidth:       receipt.Paper58,
+	}
+}
+
+// --- Handlers ---
+
+// Get returns the outlet's tax and service charge settings.
+func (h *OutletSettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	settings, err := h.store.GetOutletSettings(r.Context(), outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusOK, defaultOutletSettingsResponse(outletID))
+			return
+		}
+		log.Printf("ERROR: get outlet settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toOutletSettingsResponse(settings))
+}
+
+// Update replaces the outlet's tax and service charge settings.
+// Takes effect for new orders and whenever an open order's items change.
+func (h *OutletSettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var req updateOutletSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	taxRate, err := parseRate(req.TaxRate)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "tax_rate must be between 0 and 100"})
+		return
+	}
+	serviceChargeRate, err := parseRate(req.ServiceChargeRate)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "service_charge_rate must be between 0 and 100"})
+		return
+	}
+
+	if req.TaxOrderTypes == nil {
+		req.TaxOrderTypes = []string{}
+	}
+	for _, ot := range req.TaxOrderTypes {
+		if !isValidOrderType(ot) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order type in tax_order_types: " + ot})
+			return
+		}
+	}
+	if req.ServiceChargeOrderTypes == nil {
+		req.ServiceChargeOrderTypes = []string{}
+	}
+	for _, ot := range req.ServiceChargeOrderTypes {
+		if !isValidOrderType(ot) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order type in service_charge_order_types: " + ot})
+			return
+		}
+	}
+
+	if req.RoundingMode == "" {
+		req.RoundingMode = enum.RoundingModeHalfUp
+	}
+	if !isValidRoundingMode(req.RoundingMode) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "rounding_mode must be HALF_UP, UP, or DOWN"})
+		return
+	}
+
+	roundingUnit := decimal.NewFromInt(1)
+	if req.RoundingUnit != "" {
+		roundingUnit, err = decimal.NewFromString(req.RoundingUnit)
+		if err != nil || !roundingUnit.IsPositive() {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "rounding_unit must be greater than 0"})
+			return
+		}
+	}
+
+	if req.ReceiptPaperWidth == 0 {
+		req.ReceiptPaperWidth = receipt.Paper58
+	}
+	if !receipt.IsValidPaperWidth(req.ReceiptPaperWidth) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "receipt_paper_width must be 58 or 80"})
+		return
+	}
+
+	settings, err := h.store.UpsertOutletSettings(r.Context(), database.UpsertOutletSettingsParams{
+		OutletID:                outletID,
+		TaxRate:                 decimalToNumeric(taxRate),
+		PricesIncludeTax:        req.PricesIncludeTax,
+		TaxOrderTypes:           req.TaxOrderTypes,
+		ServiceChargeRate:       decimalToNumeric(serviceChargeRate),
+		ServiceChargeOrderTypes: req.ServiceChargeOrderTypes,
+		RoundingMode:            req.RoundingMode,
+		RoundingUnit:            decimalToNumeric(roundingUnit),
+		ReceiptHeader:           req.ReceiptHeader,
+		ReceiptFooter:           req.ReceiptFooter,
+		ReceiptPaperWidth:       req.ReceiptPaperWidth,
+	})
+	if err != nil {
+		log.Printf("ERROR: upsert outlet settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toOutletSettingsResponse(settings))
+}
+
+// --- Helpers ---
+
+// parseRate parses a percentage in [0, 100]. Empty means 0.
+func parseRate(s string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if d.IsNegative() || d.GreaterThan(decimal.NewFromInt(100)) {
+		return decimal.Zero, errors.New("rate out of range")
+	}
+	return d, nil
+}
+
+func isValidOrderType(ot string) bool {
+	switch ot {
+	case enum.OrderTypeDineIn, enum.OrderTypeTakeaway,
+		enum.OrderTypeDelivery, enum.OrderTypeCatering:
+		return true
+	}
+	return false
+}
+
+func isValidRoundingMode(mode string) bool {
+	switch mode {
+	case enum.RoundingModeHalfUp, enum.RoundingModeUp, enum.RoundingModeDown:
+		return true
+	}
+	return false
+}
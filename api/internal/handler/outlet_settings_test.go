@@ -0,0 +1,198 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/handler"
+)
+
+// --- Mock store ---
+
+type mockOutletSettingsStore struct {
+	settings map[uuid.UUID]database.OutletSetting // keyed by outlet ID
+}
+
+func newMockOutletSettingsStore() *mockOutletSettingsStore {
+	return &mockOutletSettingsStore{settings: make(map[uuid.UUID]database.OutletSetting)}
+}
+
+func (m *mockOutletSettingsStore) GetOutletSettings(_ context.Context, outletID uuid.UUID) (database.OutletSetting, error) {
+	s, ok := m.settings[outletID]
+	if !ok {
+		return database.OutletSetting{}, pgx.ErrNoRows
+	}
+	return s, nil
+}
+
+func (m *mockOutletSettingsStore) UpsertOutletSettings(_ context.Context, arg database.UpsertOutletSettingsParams) (database.OutletSetting, error) {
+	s := database.OutletSetting{
+		OutletID:                arg.OutletID,
+		TaxRate:                 arg.TaxRate,
+		PricesIncludeTax:        arg.PricesIncludeTax,
+		TaxOrderTypes:           arg.TaxOrderTypes,
+		ServiceChargeRate:       arg.ServiceChargeRate,
+		ServiceChargeOrderTypes: arg.ServiceChargeOrderTypes,
+		RoundingMode:            arg.RoundingMode,
+		RoundingUnit:            arg.RoundingUnit,
+		ReceiptHeader:           arg.ReceiptHeader,
+		ReceiptFooter:           arg.ReceiptFooter,
+		ReceiptPaperWidth:       arg.ReceiptPaperWidth,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
+	}
+	m.settings[arg.OutletID] = s
+	return s, nil
+}
+
+// --- Helpers ---
+
+func setupOutletSettingsRouter(store *mockOutletSettingsStore) *chi.Mux {
+	h := handler.NewOutletSettingsHandler(store)
+	r := chi.NewRouter()
+	r.Route("/outlets/{oid}/settings", h.RegisterRoutes)
+	return r
+}
+
+func decodeOutletSettingsResponse(t *testing.T, rr *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+// --- Get tests ---
+
+func TestOutletSettingsGet_Defaults(t *testing.T) {
+	store := newMockOutletSettingsStore()
+	router := setupOutletSettingsRouter(store)
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "GET", "/outlets/"+outletID.String()+"/settings", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	resp := decodeOutletSettingsResponse(t, rr)
+	if resp["tax_rate"] != "0.00" {
+		t.Errorf("tax_rate: got %v, want 0.00", resp["tax_rate"])
+	}
+	if resp["rounding_mode"] != "HALF_UP" {
+		t.Errorf("rounding_mode: got %v, want HALF_UP", resp["rounding_mode"])
+	}
+	if resp["updated_at"] != nil {
+		t.Errorf("updated_at: got %v, want null for unsaved settings", resp["updated_at"])
+	}
+}
+
+// --- Update tests ---
+
+func TestOutletSettingsUpdate_Valid(t *testing.T) {
+	store := newMockOutletSettingsStore()
+	router := setupOutletSettingsRouter(store)
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/settings", map[string]interface{}{
+		"tax_rate":                   "10",
+		"prices_include_tax":         true,
+		"tax_order_types":            []string{"DINE_IN", "TAKEAWAY"},
+		"service_charge_rate":        "5",
+		"service_charge_order_types": []string{"DINE_IN"},
+		"rounding_mode":              "UP",
+		"rounding_unit":              "100",
+	})
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	resp := decodeOutletSettingsResponse(t, rr)
+	if resp["tax_rate"] != "10.00" {
+		t.Errorf("tax_rate: got %v, want 10.00", resp["tax_rate"])
+	}
+	if resp["prices_include_tax"] != true {
+		t.Errorf("prices_include_tax: got %v, want true", resp["prices_include_tax"])
+	}
+	if resp["rounding_unit"] != "100.00" {
+		t.Errorf("rounding_unit: got %v, want 100.00", resp["rounding_unit"])
+	}
+
+	// Persisted and returned by Get
+	rr = doRequest(t, router, "GET", "/outlets/"+outletID.String()+"/settings", nil)
+	resp = decodeOutletSettingsResponse(t, rr)
+	if resp["service_charge_rate"] != "5.00" {
+		t.Errorf("service_charge_rate: got %v, want 5.00", resp["service_charge_rate"])
+	}
+	types, _ := resp["tax_order_types"].([]interface{})
+	if len(types) != 2 {
+		t.Errorf("tax_order_types: got %v, want 2 entries", resp["tax_order_types"])
+	}
+}
+
+func TestOutletSettingsUpdate_DefaultsRounding(t *testing.T) {
+	store := newMockOutletSettingsStore()
+	router := setupOutletSettingsRouter(store)
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/settings", map[string]interface{}{
+		"tax_rate": "10",
+	})
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	resp := decodeOutletSettingsResponse(t, rr)
+	if resp["rounding_mode"] != "HALF_UP" {
+		t.Errorf("rounding_mode: got %v, want HALF_UP", resp["rounding_mode"])
+	}
+	if resp["rounding_unit"] != "1.00" {
+		t.Errorf("rounding_unit: got %v, want 1.00", resp["rounding_unit"])
+	}
+	if resp["receipt_paper_width"] != float64(58) {
+		t.Errorf("receipt_paper_width: got %v, want 58", resp["receipt_paper_width"])
+	}
+}
+
+func TestOutletSettingsUpdate_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"negative tax rate", map[string]interface{}{"tax_rate": "-1"}},
+		{"tax rate over 100", map[string]interface{}{"tax_rate": "101"}},
+		{"non-numeric service charge", map[string]interface{}{"service_charge_rate": "abc"}},
+		{"unknown order type", map[string]interface{}{"tax_order_types": []string{"DRIVE_THRU"}}},
+		{"unknown service charge order type", map[string]interface{}{"service_charge_order_types": []string{"PICKUP"}}},
+		{"invalid rounding mode", map[string]interface{}{"rounding_mode": "BANKERS"}},
+		{"zero rounding unit", map[string]interface{}{"rounding_unit": "0"}},
+		{"unsupported paper width", map[string]interface{}{"receipt_paper_width": 76}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockOutletSettingsStore()
+			router := setupOutletSettingsRouter(store)
+			outletID := uuid.New()
+
+			rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/settings", tt.body)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+			if len(store.settings) != 0 {
+				t.Error("expected settings not to be saved")
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -15,6 +17,7 @@ import (
 	"github.com/kiwari-pos/api/internal/enum"
 	"github.com/kiwari-pos/api/internal/middleware"
 	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
 	"github.com/shopspring/decimal"
 )
 
@@ -23,10 +26,25 @@ type PaymentStore interface {
 	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
 	GetOrderForUpdate(ctx context.Context, arg database.GetOrderForUpdateParams) (database.Order, error)
 	ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.Payment, error)
+	ListOrderItemsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.OrderItem, error)
+	ListOrderItemModifiersByOrderItem(ctx context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error)
+	ListOrderChecks(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderChecksRow, error)
 	CreatePayment(ctx context.Context, arg database.CreatePaymentParams) (database.Payment, error)
 	SumPaymentsByOrder(ctx context.Context, orderID uuid.UUID) (pgtype.Numeric, error)
 	CompleteOrder(ctx context.Context, id uuid.UUID) (database.Order, error)
 	UpdateCateringStatus(ctx context.Context, arg database.UpdateCateringStatusParams) (database.Order, error)
+	// Refunds
+	GetPaymentForUpdate(ctx context.Context, arg database.GetPaymentForUpdateParams) (database.Payment, error)
+	CreatePaymentRefund(ctx context.Context, arg database.CreatePaymentRefundParams) (database.PaymentRefund, error)
+	AddPaymentRefundedAmount(ctx context.Context, arg database.AddPaymentRefundedAmountParams) (database.Payment, error)
+	CountRefundsByOrder(ctx context.Context, orderID uuid.UUID) (int64, error)
+	GetUserByOutletAndPin(ctx context.Context, arg database.GetUserByOutletAndPinParams) (database.User, error)
+	// Audit trail
+	CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error
+	// Add retries
+	IdempotencyStore
+	// Loyalty points
+	service.LoyaltyStore
 }
 
 // NewPaymentStore creates a PaymentStore from a DBTX (pool or tx).
@@ -37,18 +55,21 @@ type PaymentHandler struct {
 	store    PaymentStore
 	pool     service.TxBeginner
 	newStore NewPaymentStore
+	hub      Broadcaster
 }
 
 // NewPaymentHandler creates a new PaymentHandler.
-func NewPaymentHandler(store PaymentStore, pool service.TxBeginner, newStore NewPaymentStore) *PaymentHandler {
-	return &PaymentHandler{store: store, pool: pool, newStore: newStore}
+// hub may be nil, in which case no real-time events are published.
+func NewPaymentHandler(store PaymentStore, pool service.TxBeginner, newStore NewPaymentStore, hub Broadcaster) *PaymentHandler {
+	return &PaymentHandler{store: store, pool: pool, newStore: newStore, hub: hub}
 }
 
 // RegisterRoutes registers payment endpoints on the given Chi router.
 // Expected to be mounted at /outlets/{oid}/orders/{id}/payments
 func (h *PaymentHandler) RegisterRoutes(r chi.Router) {
-	r.Post("/", h.Add)
+	r.With(idempotent(h.store)).Post("/", h.Add)
 	r.Get("/", h.List)
+	r.Post("/{pid}/refund", h.Refund)
 }
 
 // --- Request / Response types ---
@@ -58,6 +79,24 @@ type addPaymentRequest struct {
 	Amount          string `json:"amount"`
 	AmountReceived  string `json:"amount_received"`
 	ReferenceNumber string `json:"reference_number"`
+	CheckID         string `json:"check_id"`
+}
+
+type refundPaymentRequest struct {
+	Amount        string `json:"amount"` // optional; defaults to the full refundable amount
+	Reason        string `json:"reason"`
+	AuthorizerPin string `json:"authorizer_pin"`
+}
+
+type refundResponse struct {
+	ID           uuid.UUID `json:"id"`
+	PaymentID    uuid.UUID `json:"payment_id"`
+	OrderID      uuid.UUID `json:"order_id"`
+	Amount       string    `json:"amount"`
+	Reason       string    `json:"reason"`
+	RefundedBy   uuid.UUID `json:"refunded_by"`
+	AuthorizedBy uuid.UUID `json:"authorized_by"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // --- Handlers ---
@@ -138,6 +177,17 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		referenceNumber = pgtype.Text{String: req.ReferenceNumber, Valid: true}
 	}
 
+	// Optional check ID for orders that have been split into checks
+	var checkID pgtype.UUID
+	if req.CheckID != "" {
+		cid, err := uuid.Parse(req.CheckID)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid check_id"})
+			return
+		}
+		checkID = pgtype.UUID{Bytes: cid, Valid: true}
+	}
+
 	// Begin transaction BEFORE reading order state to prevent TOCTOU races.
 	// Two concurrent payments could both pass validation outside a tx, causing overpayment.
 	tx, err := h.pool.Begin(r.Context())
@@ -171,10 +221,19 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cannot add payment to COMPLETED orders
+	// Cannot add payment to COMPLETED orders, unless a refund has reopened a
+	// balance that needs to be charged again
 	if order.Status == enum.OrderStatusCompleted {
-		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot add payment to completed order"})
-		return
+		refundCount, err := txStore.CountRefundsByOrder(r.Context(), orderID)
+		if err != nil {
+			log.Printf("ERROR: count refunds: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if refundCount == 0 {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot add payment to completed order"})
+			return
+		}
 	}
 
 	// Check if order is already fully paid
@@ -200,6 +259,50 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Split orders are paid check by check: the payment must target a check
+	// and stay within that check's remaining balance.
+	checks, err := txStore.ListOrderChecks(r.Context(), orderID)
+	if err != nil {
+		log.Printf("ERROR: list order checks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	allChecksSettled := true
+	if len(checks) > 0 {
+		if !checkID.Valid {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "check_id is required for split orders"})
+			return
+		}
+		found := false
+		for _, c := range checks {
+			checkAmount, _ := numericToDecimal(c.Amount)
+			checkPaid, _ := numericToDecimal(c.AmountPaid)
+			if c.ID == uuid.UUID(checkID.Bytes) {
+				found = true
+				if checkPaid.GreaterThanOrEqual(checkAmount) {
+					writeJSON(w, http.StatusConflict, map[string]string{"error": "check is already fully paid"})
+					return
+				}
+				if checkPaid.Add(amount).GreaterThan(checkAmount) {
+					writeJSON(w, http.StatusConflict, map[string]string{"error": "payment exceeds check balance"})
+					return
+				}
+				checkPaid = checkPaid.Add(amount)
+			}
+			if checkPaid.LessThan(checkAmount) {
+				allChecksSettled = false
+			}
+		}
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "check not found"})
+			return
+		}
+	} else if checkID.Valid {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "order is not split into checks"})
+		return
+	}
+	fullyPaid := newTotalPaid.GreaterThanOrEqual(orderTotal) && allChecksSettled
+
 	// Create payment
 	payment, err := txStore.CreatePayment(r.Context(), database.CreatePaymentParams{
 		OrderID:         orderID,
@@ -210,6 +313,7 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		AmountReceived:  amountReceived,
 		ChangeAmount:    changeAmount,
 		ProcessedBy:     claims.UserID,
+		CheckID:         checkID,
 	})
 	if err != nil {
 		log.Printf("ERROR: create payment: %v", err)
@@ -217,6 +321,24 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// POINTS payments are paid from the customer's loyalty balance
+	var pointsRedeemed int32
+	if paymentMethod == enum.PaymentMethodPoints {
+		pointsRedeemed, err = service.RedeemPoints(r.Context(), txStore, order, payment, claims.UserID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrPointsNoCustomer), errors.Is(err, service.ErrLoyaltyDisabled):
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			case errors.Is(err, service.ErrInsufficientPoints):
+				writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			default:
+				log.Printf("ERROR: redeem points: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			}
+			return
+		}
+	}
+
 	// Get updated order (will be modified by catering status or completion)
 	updatedOrder := order
 
@@ -242,7 +364,7 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Full payment for catering: -> SETTLED
-		if newTotalPaid.GreaterThanOrEqual(orderTotal) {
+		if fullyPaid {
 			updatedOrder, err = txStore.UpdateCateringStatus(r.Context(), database.UpdateCateringStatusParams{
 				ID: orderID,
 				CateringStatus: pgtype.Text{
@@ -258,8 +380,9 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Auto-complete order if fully paid (only if status is NEW, PREPARING, or READY)
-	if newTotalPaid.GreaterThanOrEqual(orderTotal) {
+	// Auto-complete order if fully paid (only if status is NEW, PREPARING, or READY).
+	// For split orders this means every check is settled.
+	if fullyPaid {
 		if order.Status == enum.OrderStatusNew ||
 			order.Status == enum.OrderStatusPreparing ||
 			order.Status == enum.OrderStatusReady {
@@ -276,6 +399,42 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Completed orders earn the customer loyalty points
+	if updatedOrder.Status == enum.OrderStatusCompleted && order.Status != enum.OrderStatusCompleted {
+		if err := service.EarnOrderPoints(r.Context(), txStore, updatedOrder, claims.UserID); err != nil {
+			log.Printf("ERROR: earn loyalty points: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	paymentEvent := map[string]interface{}{
+		"payment_id":     payment.ID,
+		"payment_method": payment.PaymentMethod,
+		"amount":         numericToString(payment.Amount),
+	}
+	if payment.CheckID.Valid {
+		paymentEvent["check_id"] = uuid.UUID(payment.CheckID.Bytes)
+	}
+	if pointsRedeemed > 0 {
+		paymentEvent["points"] = pointsRedeemed
+	}
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventPaymentAdded, nil, paymentEvent); err != nil {
+		log.Printf("ERROR: record payment added: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if updatedOrder.Status != order.Status {
+		if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventStatusChanged,
+			map[string]string{"status": order.Status},
+			map[string]string{"status": updatedOrder.Status},
+		); err != nil {
+			log.Printf("ERROR: record payment status change: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(r.Context()); err != nil {
 		log.Printf("ERROR: commit tx for add payment: %v", err)
@@ -283,9 +442,15 @@ func (h *PaymentHandler) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	paymentResp := dbPaymentToResponse(payment)
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventPaymentAdded, outletID, orderID, orderEventPayload{Payment: &paymentResp})
+	if updatedOrder.Status != order.Status {
+		publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderStatusChanged, outletID, orderID, orderEventPayload{})
+	}
+
 	// Return the created payment with updated order info
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
-		"payment": dbPaymentToResponse(payment),
+		"payment": paymentResp,
 		"order":   dbOrderToResponse(updatedOrder),
 	})
 }
@@ -341,14 +506,257 @@ func (h *PaymentHandler) List(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// Refund handles POST /outlets/{oid}/orders/{id}/payments/{pid}/refund.
+// Cashiers need a manager's PIN to authorize; managers and owners authorize
+// their own refunds.
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	paymentID, err := uuid.Parse(chi.URLParam(r, "pid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	var req refundPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reason is required"})
+		return
+	}
+
+	var requested decimal.Decimal
+	if req.Amount != "" {
+		requested, err = decimal.NewFromString(req.Amount)
+		if err != nil || requested.LessThanOrEqual(decimal.Zero) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "amount must be positive"})
+			return
+		}
+	}
+
+	// Resolve the authorizing manager
+	authorizedBy := claims.UserID
+	if !isManagerRole(claims.Role) {
+		if req.AuthorizerPin == "" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "manager authorization required"})
+			return
+		}
+		authorizer, err := h.store.GetUserByOutletAndPin(r.Context(), database.GetUserByOutletAndPinParams{
+			OutletID: outletID,
+			Pin:      pgtype.Text{String: req.AuthorizerPin, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid authorizer PIN"})
+				return
+			}
+			log.Printf("ERROR: get refund authorizer: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if !isManagerRole(authorizer.Role) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "authorizer must be a manager"})
+			return
+		}
+		authorizedBy = authorizer.ID
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	// Lock the order first, same as Add, so refunds and payments serialize
+	order, err := txStore.GetOrderForUpdate(r.Context(), database.GetOrderForUpdateParams{
+		ID:       orderID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return
+		}
+		log.Printf("ERROR: get order for refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	payment, err := txStore.GetPaymentForUpdate(r.Context(), database.GetPaymentForUpdateParams{
+		ID:      paymentID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "payment not found"})
+			return
+		}
+		log.Printf("ERROR: get payment for refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if payment.Status != enum.PaymentStatusCompleted {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "only completed payments can be refunded"})
+		return
+	}
+
+	paid, _ := numericToDecimal(payment.Amount)
+	alreadyRefunded, _ := numericToDecimal(payment.RefundedAmount)
+	refundable := paid.Sub(alreadyRefunded)
+	if refundable.LessThanOrEqual(decimal.Zero) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "payment is already fully refunded"})
+		return
+	}
+	amount := refundable
+	if req.Amount != "" {
+		if requested.GreaterThan(refundable) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "refund exceeds refundable amount"})
+			return
+		}
+		amount = requested
+	}
+
+	refund, err := txStore.CreatePaymentRefund(r.Context(), database.CreatePaymentRefundParams{
+		PaymentID:    paymentID,
+		OrderID:      orderID,
+		Amount:       decimalToNumeric(amount),
+		Reason:       strings.TrimSpace(req.Reason),
+		RefundedBy:   claims.UserID,
+		AuthorizedBy: authorizedBy,
+	})
+	if err != nil {
+		log.Printf("ERROR: create payment refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	payment, err = txStore.AddPaymentRefundedAmount(r.Context(), database.AddPaymentRefundedAmountParams{
+		ID:             paymentID,
+		RefundedAmount: decimalToNumeric(amount),
+	})
+	if err != nil {
+		log.Printf("ERROR: update payment refunded amount: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Give back redeemed points, or take back points earned on the refund
+	if err := service.ReverseRefundedPoints(r.Context(), txStore, order, payment, amount, claims.UserID); err != nil {
+		log.Printf("ERROR: reverse loyalty points for refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// A settled catering order that is no longer fully paid drops back to
+	// DP_PAID, or BOOKED if nothing remains paid.
+	updatedOrder := order
+	if order.OrderType == enum.OrderTypeCatering &&
+		order.CateringStatus.Valid && order.CateringStatus.String == enum.CateringStatusSettled {
+		totalPaid, err := txStore.SumPaymentsByOrder(r.Context(), orderID)
+		if err != nil {
+			log.Printf("ERROR: sum payments after refund: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		netPaid, _ := numericToDecimal(totalPaid)
+		orderTotal, _ := numericToDecimal(order.TotalAmount)
+		if netPaid.LessThan(orderTotal) {
+			status := enum.CateringStatusDPPaid
+			if netPaid.IsZero() {
+				status = enum.CateringStatusBooked
+			}
+			updatedOrder, err = txStore.UpdateCateringStatus(r.Context(), database.UpdateCateringStatusParams{
+				ID:             orderID,
+				CateringStatus: pgtype.Text{String: status, Valid: true},
+			})
+			if err != nil {
+				log.Printf("ERROR: update catering status after refund: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+		}
+	}
+
+	if err := service.RecordOrderEvent(r.Context(), txStore, orderID, claims.UserID, enum.OrderEventPaymentRefunded, nil, map[string]interface{}{
+		"refund_id":     refund.ID,
+		"payment_id":    refund.PaymentID,
+		"amount":        numericToString(refund.Amount),
+		"reason":        refund.Reason,
+		"authorized_by": refund.AuthorizedBy,
+	}); err != nil {
+		log.Printf("ERROR: record payment refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for refund: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	paymentResp := dbPaymentToResponse(payment)
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventPaymentRefunded, outletID, orderID, orderEventPayload{Payment: &paymentResp})
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"refund":  dbRefundToResponse(refund),
+		"payment": paymentResp,
+		"order":   dbOrderToResponse(updatedOrder),
+	})
+}
+
 // --- Helpers ---
 
+// isManagerRole reports whether the role may authorize refunds.
+func isManagerRole(role string) bool {
+	return role == enum.UserRoleOwner || role == enum.UserRoleManager
+}
+
+func dbRefundToResponse(r database.PaymentRefund) refundResponse {
+	return refundResponse{
+		ID:           r.ID,
+		PaymentID:    r.PaymentID,
+		OrderID:      r.OrderID,
+		Amount:       numericToString(r.Amount),
+		Reason:       r.Reason,
+		RefundedBy:   r.RefundedBy,
+		AuthorizedBy: r.AuthorizedBy,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
 // isValidPaymentMethod checks if the given payment method is valid.
 func isValidPaymentMethod(pm string) bool {
 	switch pm {
 	case enum.PaymentMethodCash,
 		enum.PaymentMethodQRIS,
-		enum.PaymentMethodTransfer:
+		enum.PaymentMethodTransfer,
+		enum.PaymentMethodPoints:
 		return true
 	}
 	return false
@@ -23,14 +23,21 @@ import (
 // --- Mock PaymentStore ---
 
 type mockPaymentStore struct {
+	mockIdempotencyStore
+	mockLoyaltyStore
 	orders   map[uuid.UUID]database.Order
-	payments map[uuid.UUID]database.Payment // keyed by payment ID
+	payments map[uuid.UUID]database.Payment    // keyed by payment ID
+	checks   map[uuid.UUID]database.OrderCheck // keyed by check ID
+	refunds  []database.PaymentRefund
+	users    []database.User
+	events   []database.CreateOrderEventParams
 }
 
 func newMockPaymentStore() *mockPaymentStore {
 	return &mockPaymentStore{
 		orders:   make(map[uuid.UUID]database.Order),
 		payments: make(map[uuid.UUID]database.Payment),
+		checks:   make(map[uuid.UUID]database.OrderCheck),
 	}
 }
 
@@ -60,6 +67,14 @@ func (m *mockPaymentStore) ListPaymentsByOrder(_ context.Context, orderID uuid.U
 	return result, nil
 }
 
+func (m *mockPaymentStore) ListOrderItemsByOrder(_ context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+	return []database.OrderItem{}, nil
+}
+
+func (m *mockPaymentStore) ListOrderItemModifiersByOrderItem(_ context.Context, orderItemID uuid.UUID) ([]database.OrderItemModifier, error) {
+	return []database.OrderItemModifier{}, nil
+}
+
 func (m *mockPaymentStore) CreatePayment(_ context.Context, arg database.CreatePaymentParams) (database.Payment, error) {
 	p := database.Payment{
 		ID:              uuid.New(),
@@ -72,6 +87,7 @@ func (m *mockPaymentStore) CreatePayment(_ context.Context, arg database.CreateP
 		ChangeAmount:    arg.ChangeAmount,
 		ProcessedBy:     arg.ProcessedBy,
 		ProcessedAt:     time.Now(),
+		CheckID:         arg.CheckID,
 	}
 	m.payments[p.ID] = p
 	return p, nil
@@ -82,12 +98,52 @@ func (m *mockPaymentStore) SumPaymentsByOrder(_ context.Context, orderID uuid.UU
 	for _, p := range m.payments {
 		if p.OrderID == orderID && p.Status == enum.PaymentStatusCompleted {
 			amt, _ := numericToDecimal(p.Amount)
-			total = total.Add(amt)
+			refunded, _ := numericToDecimal(p.RefundedAmount)
+			total = total.Add(amt).Sub(refunded)
 		}
 	}
 	return decimalToNumeric(total), nil
 }
 
+// GetOrderEligibleSpend sums the order's completed payments other than
+// points, net of refunds.
+func (m *mockPaymentStore) GetOrderEligibleSpend(_ context.Context, orderID uuid.UUID) (pgtype.Numeric, error) {
+	total := decimal.Zero
+	for _, p := range m.payments {
+		if p.OrderID == orderID && p.Status == enum.PaymentStatusCompleted && p.PaymentMethod != enum.PaymentMethodPoints {
+			amt, _ := numericToDecimal(p.Amount)
+			refunded, _ := numericToDecimal(p.RefundedAmount)
+			total = total.Add(amt).Sub(refunded)
+		}
+	}
+	return decimalToNumeric(total), nil
+}
+
+func (m *mockPaymentStore) ListOrderChecks(_ context.Context, orderID uuid.UUID) ([]database.ListOrderChecksRow, error) {
+	result := []database.ListOrderChecksRow{}
+	for _, c := range m.checks {
+		if c.OrderID != orderID {
+			continue
+		}
+		paid := decimal.Zero
+		for _, p := range m.payments {
+			if p.CheckID.Valid && uuid.UUID(p.CheckID.Bytes) == c.ID && p.Status == enum.PaymentStatusCompleted {
+				amt, _ := numericToDecimal(p.Amount)
+				paid = paid.Add(amt)
+			}
+		}
+		result = append(result, database.ListOrderChecksRow{
+			ID:          c.ID,
+			OrderID:     c.OrderID,
+			CheckNumber: c.CheckNumber,
+			SplitType:   c.SplitType,
+			Amount:      c.Amount,
+			AmountPaid:  decimalToNumeric(paid),
+		})
+	}
+	return result, nil
+}
+
 func (m *mockPaymentStore) CompleteOrder(_ context.Context, id uuid.UUID) (database.Order, error) {
 	o, ok := m.orders[id]
 	if !ok || o.Status == enum.OrderStatusCancelled {
@@ -112,6 +168,65 @@ func (m *mockPaymentStore) UpdateCateringStatus(_ context.Context, arg database.
 	return o, nil
 }
 
+func (m *mockPaymentStore) GetPaymentForUpdate(_ context.Context, arg database.GetPaymentForUpdateParams) (database.Payment, error) {
+	p, ok := m.payments[arg.ID]
+	if !ok || p.OrderID != arg.OrderID {
+		return database.Payment{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (m *mockPaymentStore) CreatePaymentRefund(_ context.Context, arg database.CreatePaymentRefundParams) (database.PaymentRefund, error) {
+	r := database.PaymentRefund{
+		ID:           uuid.New(),
+		PaymentID:    arg.PaymentID,
+		OrderID:      arg.OrderID,
+		Amount:       arg.Amount,
+		Reason:       arg.Reason,
+		RefundedBy:   arg.RefundedBy,
+		AuthorizedBy: arg.AuthorizedBy,
+		CreatedAt:    time.Now(),
+	}
+	m.refunds = append(m.refunds, r)
+	return r, nil
+}
+
+func (m *mockPaymentStore) AddPaymentRefundedAmount(_ context.Context, arg database.AddPaymentRefundedAmountParams) (database.Payment, error) {
+	p, ok := m.payments[arg.ID]
+	if !ok {
+		return database.Payment{}, pgx.ErrNoRows
+	}
+	refunded, _ := numericToDecimal(p.RefundedAmount)
+	add, _ := numericToDecimal(arg.RefundedAmount)
+	p.RefundedAmount = decimalToNumeric(refunded.Add(add))
+	m.payments[arg.ID] = p
+	return p, nil
+}
+
+func (m *mockPaymentStore) CountRefundsByOrder(_ context.Context, orderID uuid.UUID) (int64, error) {
+	var count int64
+	for _, r := range m.refunds {
+		if r.OrderID == orderID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockPaymentStore) GetUserByOutletAndPin(_ context.Context, arg database.GetUserByOutletAndPinParams) (database.User, error) {
+	for _, u := range m.users {
+		if u.OutletID == arg.OutletID && u.Pin == arg.Pin {
+			return u, nil
+		}
+	}
+	return database.User{}, pgx.ErrNoRows
+}
+
+func (m *mockPaymentStore) CreateOrderEvent(_ context.Context, arg database.CreateOrderEventParams) error {
+	m.events = append(m.events, arg)
+	return nil
+}
+
 // --- Helpers ---
 
 // numericToDecimal converts pgtype.Numeric to decimal.Decimal (for tests)
@@ -138,7 +253,7 @@ func setupPaymentRouterWithStore(store *mockPaymentStore, claims *auth.Claims) *
 	newStore := func(db database.DBTX) handler.PaymentStore {
 		return store
 	}
-	h := handler.NewPaymentHandler(store, pool, newStore)
+	h := handler.NewPaymentHandler(store, pool, newStore, nil)
 	r := chi.NewRouter()
 	r.Use(middleware.Authenticate(testJWTSecret))
 	r.Route("/outlets/{oid}/orders/{id}/payments", h.RegisterRoutes)
@@ -351,6 +466,182 @@ func TestAddPayment_AutoCompleteOrder(t *testing.T) {
 	if order["status"] != "COMPLETED" {
 		t.Errorf("order status: got %v, want COMPLETED (auto-completed)", order["status"])
 	}
+
+	// History records the payment and the auto-completion
+	if len(store.events) != 2 {
+		t.Fatalf("events: got %d, want 2", len(store.events))
+	}
+	if store.events[0].EventType != enum.OrderEventPaymentAdded {
+		t.Errorf("event[0]: got %s, want %s", store.events[0].EventType, enum.OrderEventPaymentAdded)
+	}
+	if store.events[1].EventType != enum.OrderEventStatusChanged {
+		t.Errorf("event[1]: got %s, want %s", store.events[1].EventType, enum.OrderEventStatusChanged)
+	}
+	if string(store.events[1].AfterValue) != `{"status":"COMPLETED"}` {
+		t.Errorf("event[1] after: got %s", store.events[1].AfterValue)
+	}
+}
+
+// seedSplitOrder creates a READY order of 100000 split evenly into two checks.
+func seedSplitOrder(store *mockPaymentStore, outletID uuid.UUID) (uuid.UUID, uuid.UUID, uuid.UUID) {
+	orderID := uuid.New()
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-SPLIT",
+		Status:      enum.OrderStatusReady,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(100000)),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	checkIDs := [2]uuid.UUID{uuid.New(), uuid.New()}
+	for i, id := range checkIDs {
+		store.checks[id] = database.OrderCheck{
+			ID:          id,
+			OrderID:     orderID,
+			CheckNumber: int32(i + 1),
+			SplitType:   enum.SplitTypeEven,
+			Amount:      decimalToNumeric(decimal.NewFromInt(50000)),
+		}
+	}
+	return orderID, checkIDs[0], checkIDs[1]
+}
+
+func TestAddPayment_SplitOrder_CompletesOnlyWhenAllChecksSettled(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	userID := uuid.New()
+	orderID, check1, check2 := seedSplitOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: userID, OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+
+	rr := doAuthRequest(t, router, "POST", path, map[string]interface{}{
+		"payment_method": "QRIS",
+		"amount":         "50000",
+		"check_id":       check1.String(),
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodePaymentResponse(t, rr)
+	payment := resp["payment"].(map[string]interface{})
+	if payment["check_id"] != check1.String() {
+		t.Errorf("check_id: got %v, want %s", payment["check_id"], check1)
+	}
+	order := resp["order"].(map[string]interface{})
+	if order["status"] != "READY" {
+		t.Errorf("order status after first check: got %v, want READY", order["status"])
+	}
+
+	rr = doAuthRequest(t, router, "POST", path, map[string]interface{}{
+		"payment_method": "QRIS",
+		"amount":         "50000",
+		"check_id":       check2.String(),
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp = decodePaymentResponse(t, rr)
+	order = resp["order"].(map[string]interface{})
+	if order["status"] != "COMPLETED" {
+		t.Errorf("order status after all checks: got %v, want COMPLETED", order["status"])
+	}
+}
+
+func TestAddPayment_SplitOrder_MissingCheckID(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedSplitOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "50000",
+		}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	if len(store.payments) != 0 {
+		t.Error("expected no payment to be created")
+	}
+}
+
+func TestAddPayment_SplitOrder_ExceedsCheckBalance(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, check1, _ := seedSplitOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	// Within the order total but more than the check owes
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "60000",
+			"check_id":       check1.String(),
+		}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+func TestAddPayment_SplitOrder_UnknownCheck(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, _, _ := seedSplitOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "10000",
+			"check_id":       uuid.New().String(),
+		}, claims)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusNotFound, rr.Body.String())
+	}
+}
+
+func TestAddPayment_CheckIDOnUnsplitOrder(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := uuid.New()
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-010",
+		Status:      enum.OrderStatusNew,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(50000)),
+	}
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "10000",
+			"check_id":       uuid.New().String(),
+		}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
 }
 
 func TestAddPayment_PartialPayment(t *testing.T) {
@@ -736,7 +1027,7 @@ func TestAddPayment_MissingAuth(t *testing.T) {
 
 	pool := &mockPool{}
 	newStore := func(db database.DBTX) handler.PaymentStore { return store }
-	h := handler.NewPaymentHandler(store, pool, newStore)
+	h := handler.NewPaymentHandler(store, pool, newStore, nil)
 	r := chi.NewRouter()
 	r.Route("/outlets/{oid}/orders/{id}/payments", h.RegisterRoutes)
 
@@ -956,3 +1247,397 @@ func TestListPayments_OrderNotFound(t *testing.T) {
 		t.Errorf("status: got %d, want %d", rr.Code, http.StatusNotFound)
 	}
 }
+
+// --- Refund Tests ---
+
+// seedPaidOrder creates a COMPLETED order of 100000 paid in full by one QRIS payment.
+func seedPaidOrder(store *mockPaymentStore, outletID uuid.UUID) (uuid.UUID, uuid.UUID) {
+	orderID := uuid.New()
+	paymentID := uuid.New()
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-REF",
+		OrderType:   enum.OrderTypeDineIn,
+		Status:      enum.OrderStatusCompleted,
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(100000)),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	store.payments[paymentID] = database.Payment{
+		ID:             paymentID,
+		OrderID:        orderID,
+		PaymentMethod:  enum.PaymentMethodQRIS,
+		Amount:         decimalToNumeric(decimal.NewFromInt(100000)),
+		Status:         enum.PaymentStatusCompleted,
+		RefundedAmount: decimalToNumeric(decimal.Zero),
+		ProcessedBy:    uuid.New(),
+		ProcessedAt:    time.Now(),
+	}
+	return orderID, paymentID
+}
+
+func refundPath(outletID, orderID, paymentID uuid.UUID) string {
+	return "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments/" + paymentID.String() + "/refund"
+}
+
+func TestRefundPayment_ManagerPartial(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, paymentID := seedPaidOrder(store, outletID)
+	managerID := uuid.New()
+
+	claims := &auth.Claims{UserID: managerID, OutletID: outletID, Role: "MANAGER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"amount": "30000",
+		"reason": "charged for wrong item",
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodePaymentResponse(t, rr)
+	refund := resp["refund"].(map[string]interface{})
+	if refund["amount"] != "30000.00" {
+		t.Errorf("refund amount: got %v, want 30000.00", refund["amount"])
+	}
+	if refund["authorized_by"] != managerID.String() {
+		t.Errorf("authorized_by: got %v, want %s", refund["authorized_by"], managerID)
+	}
+	payment := resp["payment"].(map[string]interface{})
+	if payment["refunded_amount"] != "30000.00" {
+		t.Errorf("payment refunded_amount: got %v, want 30000.00", payment["refunded_amount"])
+	}
+}
+
+func TestRefundPayment_FullByDefault(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, paymentID := seedPaidOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "OWNER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"reason": "customer complaint",
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	refunded, _ := numericToDecimal(store.payments[paymentID].RefundedAmount)
+	if !refunded.Equal(decimal.NewFromInt(100000)) {
+		t.Errorf("refunded_amount: got %s, want 100000", refunded)
+	}
+
+	// A second refund has nothing left to return
+	rr = doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"reason": "again",
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+func TestRefundPayment_CashierWithManagerPin(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, paymentID := seedPaidOrder(store, outletID)
+	managerID := uuid.New()
+	store.users = []database.User{
+		{ID: managerID, OutletID: outletID, Role: "MANAGER", Pin: pgtype.Text{String: "4321", Valid: true}},
+	}
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"amount":         "10000",
+		"reason":         "overcharged",
+		"authorizer_pin": "4321",
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if len(store.refunds) != 1 || store.refunds[0].AuthorizedBy != managerID {
+		t.Errorf("expected refund authorized by manager %s, got %+v", managerID, store.refunds)
+	}
+}
+
+func TestRefundPayment_AuthorizationRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		pin  string
+	}{
+		{"no pin", ""},
+		{"unknown pin", "0000"},
+		{"pin of another cashier", "1111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockPaymentStore()
+			outletID := uuid.New()
+			orderID, paymentID := seedPaidOrder(store, outletID)
+			store.users = []database.User{
+				{ID: uuid.New(), OutletID: outletID, Role: "CASHIER", Pin: pgtype.Text{String: "1111", Valid: true}},
+			}
+
+			claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+			router := setupPaymentRouterWithStore(store, claims)
+
+			rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+				"reason":         "overcharged",
+				"authorizer_pin": tt.pin,
+			}, claims)
+
+			if rr.Code != http.StatusForbidden {
+				t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusForbidden, rr.Body.String())
+			}
+			if len(store.refunds) != 0 {
+				t.Error("expected no refund to be created")
+			}
+		})
+	}
+}
+
+func TestRefundPayment_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{"missing reason", map[string]interface{}{"amount": "1000"}, http.StatusBadRequest},
+		{"negative amount", map[string]interface{}{"amount": "-1", "reason": "x"}, http.StatusBadRequest},
+		{"exceeds payment", map[string]interface{}{"amount": "100001", "reason": "x"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockPaymentStore()
+			outletID := uuid.New()
+			orderID, paymentID := seedPaidOrder(store, outletID)
+
+			claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+			router := setupPaymentRouterWithStore(store, claims)
+
+			rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), tt.body, claims)
+
+			if rr.Code != tt.want {
+				t.Fatalf("status: got %d, want %d; body: %s", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRefundPayment_PaymentNotFound(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, _ := seedPaidOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, uuid.New()), map[string]interface{}{
+		"reason": "x",
+	}, claims)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusNotFound, rr.Body.String())
+	}
+}
+
+func TestRefundPayment_CompletedOrderAcceptsCorrectedPayment(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, paymentID := seedPaidOrder(store, outletID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	// Refund the wrong QRIS charge, then take the balance in cash
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"reason": "customer paid by cash instead",
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("refund status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+
+	rr = doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method":  "CASH",
+			"amount":          "100000",
+			"amount_received": "100000",
+		}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("payment status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+}
+
+func TestRefundPayment_CateringRevertsSettled(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID, paymentID := seedPaidOrder(store, outletID)
+	o := store.orders[orderID]
+	o.OrderType = enum.OrderTypeCatering
+	o.Status = enum.OrderStatusReady
+	o.CateringStatus = pgtype.Text{String: enum.CateringStatusSettled, Valid: true}
+	store.orders[orderID] = o
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"amount": "20000",
+		"reason": "reduced headcount",
+	}, claims)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got := store.orders[orderID].CateringStatus.String; got != enum.CateringStatusDPPaid {
+		t.Errorf("catering status: got %s, want %s", got, enum.CateringStatusDPPaid)
+	}
+}
+
+// --- Loyalty Tests ---
+
+// seedLoyaltyOrder creates a NEW dine-in order of 100000 for a customer at an
+// outlet with a loyalty program.
+func seedLoyaltyOrder(store *mockPaymentStore, outletID, customerID uuid.UUID) uuid.UUID {
+	orderID := uuid.New()
+	store.enableLoyalty(outletID)
+	store.orders[orderID] = database.Order{
+		ID:          orderID,
+		OutletID:    outletID,
+		OrderNumber: "ORD-PTS",
+		OrderType:   enum.OrderTypeDineIn,
+		Status:      enum.OrderStatusNew,
+		CustomerID:  pgtype.UUID{Bytes: customerID, Valid: true},
+		TotalAmount: decimalToNumeric(decimal.NewFromInt(100000)),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	return orderID
+}
+
+func TestAddPayment_Points_RedeemsAndEarnsOnRest(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID, customerID := uuid.New(), uuid.New()
+	orderID := seedLoyaltyOrder(store, outletID, customerID)
+	store.addPoints(customerID, 300)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+	path := "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/payments"
+
+	// 20000 paid with 200 points worth 100 each
+	rr := doAuthRequest(t, router, "POST", path, map[string]interface{}{
+		"payment_method": "POINTS",
+		"amount":         "20000",
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("points status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 100 {
+		t.Errorf("balance after redeeming: got %d, want 100", got)
+	}
+
+	// The rest in cash completes the order; only the cash spend earns
+	rr = doAuthRequest(t, router, "POST", path, map[string]interface{}{
+		"payment_method":  "CASH",
+		"amount":          "80000",
+		"amount_received": "80000",
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("cash status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 108 {
+		t.Errorf("balance after completion: got %d, want 108 (8 earned)", got)
+	}
+}
+
+func TestAddPayment_Points_Insufficient(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID, customerID := uuid.New(), uuid.New()
+	orderID := seedLoyaltyOrder(store, outletID, customerID)
+	store.addPoints(customerID, 50)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "POINTS",
+			"amount":         "10000",
+		}, claims)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 50 {
+		t.Errorf("balance: got %d, want 50 unchanged", got)
+	}
+}
+
+func TestAddPayment_Points_NoCustomer(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID := uuid.New()
+	orderID := seedLoyaltyOrder(store, outletID, uuid.New())
+	o := store.orders[orderID]
+	o.CustomerID = pgtype.UUID{}
+	store.orders[orderID] = o
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "CASHIER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "POINTS",
+			"amount":         "10000",
+		}, claims)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+}
+
+func TestRefundPayment_ReversesEarnedPoints(t *testing.T) {
+	store := newMockPaymentStore()
+	outletID, customerID := uuid.New(), uuid.New()
+	orderID := seedLoyaltyOrder(store, outletID, customerID)
+
+	claims := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+	router := setupPaymentRouterWithStore(store, claims)
+
+	rr := doAuthRequest(t, router, "POST",
+		"/outlets/"+outletID.String()+"/orders/"+orderID.String()+"/payments",
+		map[string]interface{}{
+			"payment_method": "QRIS",
+			"amount":         "100000",
+		}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("payment status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 10 {
+		t.Fatalf("balance after completion: got %d, want 10", got)
+	}
+	paymentID := uuid.MustParse(decodePaymentResponse(t, rr)["payment"].(map[string]interface{})["id"].(string))
+
+	rr = doAuthRequest(t, router, "POST", refundPath(outletID, orderID, paymentID), map[string]interface{}{
+		"amount": "35000",
+		"reason": "item unavailable",
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("refund status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got := store.pointsBalance(customerID); got != 6 {
+		t.Errorf("balance after refund: got %d, want 6 (65000 still paid)", got)
+	}
+}
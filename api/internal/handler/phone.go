@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+)
+
+// errInvalidPhone is returned for phone numbers that cannot be read.
+var errInvalidPhone = errors.New("invalid phone number")
+
+// normalizePhone returns phone in E.164 form (+628123456789). Indonesian
+// numbers may be typed with the trunk 0 (0812...), the country code with or
+// without + (62812..., +62812...), or neither (812...). Other countries
+// need + or 00 and their country code. Mirrors normalize_phone in the
+// database, which the duplicate customer report groups by.
+func normalizePhone(phone string) (string, error) {
+	s := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+
+	international := strings.HasPrefix(s, "+")
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return "", errInvalidPhone
+	}
+
+	var digits string
+	switch {
+	case international:
+		digits = s
+	case strings.HasPrefix(s, "00"):
+		digits = s[2:]
+	case strings.HasPrefix(s, "0"):
+		digits = "62" + s[1:]
+	case strings.HasPrefix(s, "62"):
+		digits = s
+	case strings.HasPrefix(s, "8"):
+		digits = "62" + s
+	default:
+		return "", errInvalidPhone
+	}
+
+	// +62 0812... typed with the trunk prefix
+	if strings.HasPrefix(digits, "620") {
+		digits = "62" + digits[3:]
+	}
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", errInvalidPhone
+	}
+	return "+" + digits, nil
+}
+
+// phoneSearchTerm rewrites a customer search that looks like the start of
+// an Indonesian phone number to match stored E.164 numbers, so "0812"
+// finds "+62812...". Other searches are returned unchanged.
+func phoneSearchTerm(search string) string {
+	s := strings.TrimPrefix(strings.ReplaceAll(search, " ", ""), "+")
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return search
+	}
+	if strings.HasPrefix(s, "0") && !strings.HasPrefix(s, "00") {
+		return "62" + s[1:]
+	}
+	return s
+}
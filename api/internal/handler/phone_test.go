@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone string
+		want  string
+	}{
+		{"trunk prefix", "08123456789", "+628123456789"},
+		{"no prefix", "8123456789", "+628123456789"},
+		{"country code", "628123456789", "+628123456789"},
+		{"E.164", "+628123456789", "+628123456789"},
+		{"country code with trunk prefix", "+62 0812-3456-789", "+628123456789"},
+		{"spaces", "0812 3456 789", "+628123456789"},
+		{"dashes", "0812-3456-789", "+628123456789"},
+		{"parentheses and dots", "(0812) 3456.789", "+628123456789"},
+		{"tab", "0812\t3456789", "+628123456789"},
+		{"other country", "+1 (415) 555-0100", "+14155550100"},
+		{"international prefix", "0065 6123 4567", "+6561234567"},
+		{"shortest", "+12345678", "+12345678"},
+		{"longest", "+123456789012345", "+123456789012345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePhone(tt.phone)
+			if err != nil {
+				t.Fatalf("normalizePhone(%q): unexpected error: %v", tt.phone, err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizePhone(%q): got %q, want %q", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePhone_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone string
+	}{
+		{"empty", ""},
+		{"only punctuation", "( ) -"},
+		{"plus only", "+"},
+		{"too short", "0812"},
+		{"too long", "+1234567890123456"},
+		{"letters", "0812abc4567"},
+		{"plus in the middle", "0812+3456789"},
+		{"unknown local prefix", "7123456789"},
+		{"country code starting with 0", "+0812345678"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePhone(tt.phone)
+			if !errors.Is(err, errInvalidPhone) {
+				t.Errorf("normalizePhone(%q): got %q, %v; want errInvalidPhone", tt.phone, got, err)
+			}
+		})
+	}
+}
+
+func TestPhoneSearchTerm(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+	}{
+		{"trunk prefix", "0812", "62812"},
+		{"trunk prefix with spaces", "0812 345", "62812345"},
+		{"country code", "62812", "62812"},
+		{"plus country code", "+62 812", "62812"},
+		{"local digits", "812", "812"},
+		{"international prefix", "0065", "0065"},
+		{"name", "Budi", "Budi"},
+		{"digits and letters", "0812a", "0812a"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := phoneSearchTerm(tt.search); got != tt.want {
+				t.Errorf("phoneSearchTerm(%q): got %q, want %q", tt.search, got, tt.want)
+			}
+		})
+	}
+}
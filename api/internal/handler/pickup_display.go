@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// READY timeout for a new pickup display, matching the column default
+const defaultPickupReadyTimeout = 30
+
+// Time allowed to look up the pickup display for an order event
+const pickupPublishTimeout = 5 * time.Second
+
+// How long the publisher reuses an outlet's pickup display, or its absence,
+// before looking it up again. Configuration changes reach live events after
+// at most this long; screens loading the feed see them at once.
+const pickupDisplayCacheTTL = time.Minute
+
+// PickupDisplayStore defines the database methods needed by pickup display handlers.
+// Satisfied by *database.Queries; narrow interface for testability.
+type PickupDisplayStore interface {
+	GetPickupDisplay(ctx context.Context, outletID uuid.UUID) (database.PickupDisplay, error)
+	GetPickupDisplayByToken(ctx context.Context, token string) (database.PickupDisplay, error)
+	UpsertPickupDisplay(ctx context.Context, arg database.UpsertPickupDisplayParams) (database.PickupDisplay, error)
+	RotatePickupDisplayToken(ctx context.Context, arg database.RotatePickupDisplayTokenParams) (database.PickupDisplay, error)
+	DeletePickupDisplay(ctx context.Context, outletID uuid.UUID) error
+	ListPickupDisplayOrders(ctx context.Context, arg database.ListPickupDisplayOrdersParams) ([]database.ListPickupDisplayOrdersRow, error)
+}
+
+// PickupDisplayHandler handles the customer pickup screen: its configuration
+// for staff, and the read-only feed the screen loads with its token.
+type PickupDisplayHandler struct {
+	store PickupDisplayStore
+	hub   *ws.Hub
+}
+
+// NewPickupDisplayHandler creates a new PickupDisplayHandler.
+func NewPickupDisplayHandler(store PickupDisplayStore, hub *ws.Hub) *PickupDisplayHandler {
+	return &PickupDisplayHandler{store: store, hub: hub}
+}
+
+// RegisterRoutes registers pickup display configuration endpoints on the given Chi router.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/pickup-display
+func (h *PickupDisplayHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.Get)
+	r.Put("/", h.Update)
+	r.Delete("/", h.Delete)
+	r.Post("/token", h.RotateToken)
+}
+
+// RegisterPublicRoutes registers the token-authenticated screen endpoints.
+// Expected to be mounted outside authentication.
+func (h *PickupDisplayHandler) RegisterPublicRoutes(r chi.Router) {
+	r.Get("/display/pickup/{token}", h.Feed)
+	r.Get("/ws/display/pickup/{token}", h.ServeWS)
+}
+
+// --- Request / Response types ---
+
+type updatePickupDisplayRequest struct {
+	OrderTypes          []string `json:"order_types"`
+	ReadyTimeoutMinutes int32    `json:"ready_timeout_minutes"`
+}
+
+type pickupDisplayResponse struct {
+	OutletID            uuid.UUID `json:"outlet_id"`
+	Token               string    `json:"token"`
+	OrderTypes          []string  `json:"order_types"`
+	ReadyTimeoutMinutes int32     `json:"ready_timeout_minutes"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// pickupOrderResponse is all a pickup screen learns about an order.
+// ExpiresAt is when a READY order drops off the screen if not collected.
+type pickupOrderResponse struct {
+	OrderNumber string     `json:"order_number"`
+	Status      string     `json:"status"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type pickupFeedResponse struct {
+	Orders []pickupOrderResponse `json:"orders"`
+}
+
+type pickupOrderRemovedResponse struct {
+	OrderNumber string `json:"order_number"`
+}
+
+func toPickupDisplayResponse(d database.PickupDisplay) pickupDisplayResponse {
+	return pickupDisplayResponse{
+		OutletID:            d.OutletID,
+		Token:               d.Token,
+		OrderTypes:          d.OrderTypes,
+		ReadyTimeoutMinutes: d.ReadyTimeoutMinutes,
+		UpdatedAt:           d.UpdatedAt,
+	}
+}
+
+// toPickupOrderResponse builds what the screen shows for an order. readyAt
+// is when the order became READY and times how long it stays up.
+func toPickupOrderResponse(orderNumber, status string, updatedAt, readyAt time.Time, readyTimeoutMinutes int32) pickupOrderResponse {
+	resp := pickupOrderResponse{
+		OrderNumber: orderNumber,
+		Status:      status,
+		UpdatedAt:   updatedAt,
+	}
+	if status == enum.OrderStatusReady {
+		expires := readyAt.Add(time.Duration(readyTimeoutMinutes) * time.Minute)
+		resp.ExpiresAt = &expires
+	}
+	return resp
+}
+
+// --- Staff handlers ---
+
+// Get returns the outlet's pickup display configuration, including its token.
+func (h *PickupDisplayHandler) Get(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	display, err := h.store.GetPickupDisplay(r.Context(), outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "pickup display not configured"})
+			return
+		}
+		log.Printf("ERROR: get pickup display: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toPickupDisplayResponse(display))
+}
+
+// Update creates or replaces the outlet's pickup display configuration.
+// A token is issued when the display is first created and kept afterwards.
+func (h *PickupDisplayHandler) Update(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var req updatePickupDisplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if len(req.OrderTypes) == 0 {
+		req.OrderTypes = []string{enum.OrderTypeTakeaway}
+	}
+	for _, ot := range req.OrderTypes {
+		if !isValidOrderType(ot) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order type in order_types: " + ot})
+			return
+		}
+	}
+	if req.ReadyTimeoutMinutes == 0 {
+		req.ReadyTimeoutMinutes = defaultPickupReadyTimeout
+	}
+	if req.ReadyTimeoutMinutes < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ready_timeout_minutes must be positive"})
+		return
+	}
+
+	token, err := newDisplayToken()
+	if err != nil {
+		log.Printf("ERROR: generate pickup display token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	display, err := h.store.UpsertPickupDisplay(r.Context(), database.UpsertPickupDisplayParams{
+		OutletID:            outletID,
+		Token:               token,
+		OrderTypes:          req.OrderTypes,
+		ReadyTimeoutMinutes: req.ReadyTimeoutMinutes,
+	})
+	if err != nil {
+		log.Printf("ERROR: upsert pickup display: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toPickupDisplayResponse(display))
+}
+
+// RotateToken issues a new token. Screens using the old one stop working
+// when they next load or reconnect.
+func (h *PickupDisplayHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	token, err := newDisplayToken()
+	if err != nil {
+		log.Printf("ERROR: generate pickup display token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	display, err := h.store.RotatePickupDisplayToken(r.Context(), database.RotatePickupDisplayTokenParams{
+		OutletID: outletID,
+		Token:    token,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "pickup display not configured"})
+			return
+		}
+		log.Printf("ERROR: rotate pickup display token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toPickupDisplayResponse(display))
+}
+
+// Delete removes the outlet's pickup display; its token stops working.
+func (h *PickupDisplayHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	if err := h.store.DeletePickupDisplay(r.Context(), outletID); err != nil {
+		log.Printf("ERROR: delete pickup display: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Screen handlers ---
+
+// Feed handles GET /display/pickup/{token}: the orders the screen shows now.
+// Live changes follow over the WebSocket.
+func (h *PickupDisplayHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	display, ok := h.displayForToken(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := h.store.ListPickupDisplayOrders(r.Context(), database.ListPickupDisplayOrdersParams{
+		OutletID:            display.OutletID,
+		OrderTypes:          display.OrderTypes,
+		ReadyTimeoutMinutes: display.ReadyTimeoutMinutes,
+	})
+	if err != nil {
+		log.Printf("ERROR: list pickup display orders: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := pickupFeedResponse{Orders: make([]pickupOrderResponse, len(rows))}
+	for i, row := range rows {
+		resp.Orders[i] = toPickupOrderResponse(row.OrderNumber, row.Status, row.UpdatedAt, row.ReadyAt.Time, display.ReadyTimeoutMinutes)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ServeWS handles WS /ws/display/pickup/{token}.
+func (h *PickupDisplayHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	display, ok := h.displayForToken(w, r)
+	if !ok {
+		return
+	}
+	ws.ServePickupDisplay(h.hub, display.OutletID, w, r)
+}
+
+// displayForToken resolves the {token} URL param, writing the error response if it fails.
+func (h *PickupDisplayHandler) displayForToken(w http.ResponseWriter, r *http.Request) (database.PickupDisplay, bool) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "display not found"})
+		return database.PickupDisplay{}, false
+	}
+
+	display, err := h.store.GetPickupDisplayByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "display not found"})
+			return database.PickupDisplay{}, false
+		}
+		log.Printf("ERROR: get pickup display by token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.PickupDisplay{}, false
+	}
+	return display, true
+}
+
+// newDisplayToken returns a random URL-safe token.
+func newDisplayToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// --- Publisher ---
+
+// pickupTriggers are the order events that can move an order on the pickup screen.
+var pickupTriggers = map[string]bool{
+	ws.EventOrderCreated:       true,
+	ws.EventOrderStatusChanged: true,
+	ws.EventOrderCancelled:     true,
+}
+
+// pickupDisplayLookup is the store method PickupDisplayPublisher needs.
+type pickupDisplayLookup interface {
+	GetPickupDisplay(ctx context.Context, outletID uuid.UUID) (database.PickupDisplay, error)
+}
+
+// PickupDisplayPublisher forwards events to hub and, after order status
+// changes, follows them with the pickup event the outlet's screen needs:
+// pickup.order_updated while the order is NEW, PREPARING or READY, then
+// pickup.order_removed once it is completed or cancelled. Outlets without a
+// pickup display, and order types it doesn't show, get nothing extra.
+type PickupDisplayPublisher struct {
+	hub   Broadcaster
+	store pickupDisplayLookup
+
+	// Pickup displays by outlet ID, so events don't each need a lookup
+	mu       sync.Mutex
+	displays map[uuid.UUID]cachedPickupDisplay
+}
+
+// cachedPickupDisplay is an outlet's pickup display as last looked up.
+// found is false for outlets without one.
+type cachedPickupDisplay struct {
+	display database.PickupDisplay
+	found   bool
+	expires time.Time
+}
+
+// NewPickupDisplayPublisher creates a new PickupDisplayPublisher.
+func NewPickupDisplayPublisher(hub Broadcaster, store pickupDisplayLookup) *PickupDisplayPublisher {
+	return &PickupDisplayPublisher{
+		hub:      hub,
+		store:    store,
+		displays: make(map[uuid.UUID]cachedPickupDisplay),
+	}
+}
+
+// BroadcastToOutlet publishes the event, then the pickup event it implies.
+func (p *PickupDisplayPublisher) BroadcastToOutlet(outletID uuid.UUID, event ws.Event) {
+	p.hub.BroadcastToOutlet(outletID, event)
+	if !pickupTriggers[event.Type] {
+		return
+	}
+
+	var payload struct {
+		Order struct {
+			OrderNumber string    `json:"order_number"`
+			OrderType   string    `json:"order_type"`
+			Status      string    `json:"status"`
+			UpdatedAt   time.Time `json:"updated_at"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		log.Printf("ERROR: decode %s event for pickup display: %v", event.Type, err)
+		return
+	}
+	order := payload.Order
+
+	display, ok := p.display(outletID)
+	if !ok {
+		return
+	}
+	shown := false
+	for _, ot := range display.OrderTypes {
+		if ot == order.OrderType {
+			shown = true
+			break
+		}
+	}
+	if !shown {
+		return
+	}
+
+	eventType := ws.EventPickupOrderUpdated
+	var body interface{}
+	switch order.Status {
+	case enum.OrderStatusNew, enum.OrderStatusPreparing, enum.OrderStatusReady:
+		// Status changes are what trigger this, so updated_at is when it became READY
+		body = toPickupOrderResponse(order.OrderNumber, order.Status, order.UpdatedAt, order.UpdatedAt, display.ReadyTimeoutMinutes)
+	default:
+		eventType = ws.EventPickupOrderRemoved
+		body = pickupOrderRemovedResponse{OrderNumber: order.OrderNumber}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("ERROR: marshal %s event: %v", eventType, err)
+		return
+	}
+	p.hub.BroadcastToOutlet(outletID, ws.Event{Type: eventType, Payload: data})
+}
+
+// display returns the outlet's pickup display, and false if it has none or
+// the lookup failed. Lookups are cached for pickupDisplayCacheTTL.
+func (p *PickupDisplayPublisher) display(outletID uuid.UUID) (database.PickupDisplay, bool) {
+	p.mu.Lock()
+	cached, ok := p.displays[outletID]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.display, cached.found
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pickupPublishTimeout)
+	defer cancel()
+
+	display, err := p.store.GetPickupDisplay(ctx, outletID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("ERROR: get pickup display: %v", err)
+		return database.PickupDisplay{}, false
+	}
+	found := err == nil
+
+	p.mu.Lock()
+	p.displays[outletID] = cachedPickupDisplay{display: display, found: found, expires: time.Now().Add(pickupDisplayCacheTTL)}
+	p.mu.Unlock()
+	return display, found
+}
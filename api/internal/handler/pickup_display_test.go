@@ -0,0 +1,301 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/ws"
+)
+
+// --- Mock store ---
+
+type mockPickupDisplayStore struct {
+	displays map[uuid.UUID]database.PickupDisplay // keyed by outlet ID
+	orders   []database.ListPickupDisplayOrdersRow
+	lastList database.ListPickupDisplayOrdersParams
+	lookups  int // GetPickupDisplay calls
+}
+
+func newMockPickupDisplayStore() *mockPickupDisplayStore {
+	return &mockPickupDisplayStore{displays: make(map[uuid.UUID]database.PickupDisplay)}
+}
+
+func (m *mockPickupDisplayStore) GetPickupDisplay(_ context.Context, outletID uuid.UUID) (database.PickupDisplay, error) {
+	m.lookups++
+	d, ok := m.displays[outletID]
+	if !ok {
+		return database.PickupDisplay{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (m *mockPickupDisplayStore) GetPickupDisplayByToken(_ context.Context, token string) (database.PickupDisplay, error) {
+	for _, d := range m.displays {
+		if d.Token == token {
+			return d, nil
+		}
+	}
+	return database.PickupDisplay{}, pgx.ErrNoRows
+}
+
+func (m *mockPickupDisplayStore) UpsertPickupDisplay(_ context.Context, arg database.UpsertPickupDisplayParams) (database.PickupDisplay, error) {
+	d, ok := m.displays[arg.OutletID]
+	if !ok {
+		d = database.PickupDisplay{OutletID: arg.OutletID, Token: arg.Token, CreatedAt: time.Now()}
+	}
+	d.OrderTypes = arg.OrderTypes
+	d.ReadyTimeoutMinutes = arg.ReadyTimeoutMinutes
+	d.UpdatedAt = time.Now()
+	m.displays[arg.OutletID] = d
+	return d, nil
+}
+
+func (m *mockPickupDisplayStore) RotatePickupDisplayToken(_ context.Context, arg database.RotatePickupDisplayTokenParams) (database.PickupDisplay, error) {
+	d, ok := m.displays[arg.OutletID]
+	if !ok {
+		return database.PickupDisplay{}, pgx.ErrNoRows
+	}
+	d.Token = arg.Token
+	m.displays[arg.OutletID] = d
+	return d, nil
+}
+
+func (m *mockPickupDisplayStore) DeletePickupDisplay(_ context.Context, outletID uuid.UUID) error {
+	delete(m.displays, outletID)
+	return nil
+}
+
+func (m *mockPickupDisplayStore) ListPickupDisplayOrders(_ context.Context, arg database.ListPickupDisplayOrdersParams) ([]database.ListPickupDisplayOrdersRow, error) {
+	m.lastList = arg
+	return m.orders, nil
+}
+
+// --- Helpers ---
+
+func setupPickupDisplayRouter(store *mockPickupDisplayStore) *chi.Mux {
+	h := handler.NewPickupDisplayHandler(store, nil)
+	r := chi.NewRouter()
+	r.Route("/outlets/{oid}/pickup-display", h.RegisterRoutes)
+	h.RegisterPublicRoutes(r)
+	return r
+}
+
+// --- Configuration tests ---
+
+func TestPickupDisplayUpdate_CreatesWithTokenAndDefaults(t *testing.T) {
+	store := newMockPickupDisplayStore()
+	router := setupPickupDisplayRouter(store)
+	outletID := uuid.New()
+
+	rr := doRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/pickup-display", map[string]interface{}{})
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp struct {
+		Token               string   `json:"token"`
+		OrderTypes          []string `json:"order_types"`
+		ReadyTimeoutMinutes int32    `json:"ready_timeout_minutes"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Token) < 32 {
+		t.Errorf("token: got %q, want a random token", resp.Token)
+	}
+	if len(resp.OrderTypes) != 1 || resp.OrderTypes[0] != enum.OrderTypeTakeaway {
+		t.Errorf("order_types: got %v, want [TAKEAWAY]", resp.OrderTypes)
+	}
+	if resp.ReadyTimeoutMinutes != 30 {
+		t.Errorf("ready_timeout_minutes: got %d, want 30", resp.ReadyTimeoutMinutes)
+	}
+}
+
+func TestPickupDisplayUpdate_InvalidOrderType(t *testing.T) {
+	store := newMockPickupDisplayStore()
+	router := setupPickupDisplayRouter(store)
+
+	rr := doRequest(t, router, "PUT", "/outlets/"+uuid.New().String()+"/pickup-display", map[string]interface{}{
+		"order_types": []string{"TAKEAWAY", "DRIVE_THRU"},
+	})
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPickupDisplayRotateToken(t *testing.T) {
+	store := newMockPickupDisplayStore()
+	router := setupPickupDisplayRouter(store)
+	outletID := uuid.New()
+	store.displays[outletID] = database.PickupDisplay{OutletID: outletID, Token: "old-token", OrderTypes: []string{enum.OrderTypeTakeaway}, ReadyTimeoutMinutes: 30}
+
+	rr := doRequest(t, router, "POST", "/outlets/"+outletID.String()+"/pickup-display/token", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if store.displays[outletID].Token == "old-token" {
+		t.Error("token was not rotated")
+	}
+
+	rr = doRequest(t, router, "GET", "/display/pickup/old-token", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("old token: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+// --- Feed tests ---
+
+func TestPickupDisplayFeed(t *testing.T) {
+	store := newMockPickupDisplayStore()
+	router := setupPickupDisplayRouter(store)
+	outletID := uuid.New()
+	store.displays[outletID] = database.PickupDisplay{OutletID: outletID, Token: "tv-token", OrderTypes: []string{enum.OrderTypeTakeaway}, ReadyTimeoutMinutes: 20}
+	readyAt := time.Now().Add(-5 * time.Minute).UTC().Truncate(time.Second)
+	// Paid after it became READY, which moved updated_at but not the READY window
+	paidAt := readyAt.Add(3 * time.Minute)
+	store.orders = []database.ListPickupDisplayOrdersRow{
+		{OrderNumber: "KWR-041", Status: enum.OrderStatusReady, UpdatedAt: paidAt, ReadyAt: pgtype.Timestamptz{Time: readyAt, Valid: true}},
+		{OrderNumber: "KWR-042", Status: enum.OrderStatusPreparing, UpdatedAt: time.Now()},
+	}
+
+	rr := doRequest(t, router, "GET", "/display/pickup/tv-token", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if store.lastList.OutletID != outletID || store.lastList.ReadyTimeoutMinutes != 20 {
+		t.Errorf("list params: got %+v", store.lastList)
+	}
+
+	var resp struct {
+		Orders []map[string]interface{} `json:"orders"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Orders) != 2 {
+		t.Fatalf("orders: got %d, want 2", len(resp.Orders))
+	}
+	ready := resp.Orders[0]
+	if want := readyAt.Add(20 * time.Minute).Format(time.RFC3339); ready["expires_at"] != want {
+		t.Errorf("expires_at: got %v, want %s", ready["expires_at"], want)
+	}
+	if _, ok := resp.Orders[1]["expires_at"]; ok {
+		t.Error("orders still preparing should not expire")
+	}
+	// Nothing beyond the number and its state
+	for _, key := range []string{"id", "total_amount", "customer_id", "items"} {
+		if _, ok := ready[key]; ok {
+			t.Errorf("feed exposes %s", key)
+		}
+	}
+}
+
+func TestPickupDisplayFeed_UnknownToken(t *testing.T) {
+	store := newMockPickupDisplayStore()
+	router := setupPickupDisplayRouter(store)
+
+	rr := doRequest(t, router, "GET", "/display/pickup/nope", nil)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+// --- Publisher tests ---
+
+func orderEvent(t *testing.T, eventType, orderType, status string) ws.Event {
+	t.Helper()
+	payload, err := json.Marshal(map[string]interface{}{
+		"order": map[string]interface{}{
+			"id":           uuid.New(),
+			"order_number": "KWR-042",
+			"order_type":   orderType,
+			"status":       status,
+			"total_amount": "50000.00",
+			"updated_at":   time.Now(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return ws.Event{Type: eventType, Payload: payload}
+}
+
+func TestPickupDisplayPublisher(t *testing.T) {
+	outletID := uuid.New()
+	store := newMockPickupDisplayStore()
+	store.displays[outletID] = database.PickupDisplay{OutletID: outletID, Token: "tv-token", OrderTypes: []string{enum.OrderTypeTakeaway}, ReadyTimeoutMinutes: 30}
+	hub := &mockBroadcaster{}
+	p := handler.NewPickupDisplayPublisher(hub, store)
+
+	p.BroadcastToOutlet(outletID, orderEvent(t, ws.EventOrderStatusChanged, enum.OrderTypeTakeaway, enum.OrderStatusReady))
+	p.BroadcastToOutlet(outletID, orderEvent(t, ws.EventOrderStatusChanged, enum.OrderTypeDineIn, enum.OrderStatusReady))
+	p.BroadcastToOutlet(outletID, orderEvent(t, ws.EventOrderStatusChanged, enum.OrderTypeTakeaway, enum.OrderStatusCompleted))
+	// Outlets without a display get only the original event
+	p.BroadcastToOutlet(uuid.New(), orderEvent(t, ws.EventOrderCreated, enum.OrderTypeTakeaway, enum.OrderStatusNew))
+
+	want := []string{
+		ws.EventOrderStatusChanged, ws.EventPickupOrderUpdated,
+		ws.EventOrderStatusChanged,
+		ws.EventOrderStatusChanged, ws.EventPickupOrderRemoved,
+		ws.EventOrderCreated,
+	}
+	got := hub.types()
+	if len(got) != len(want) {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events: got %v, want %v", got, want)
+		}
+	}
+
+	updated := decodeEventPayload(t, hub.events[1].event)
+	if updated["order_number"] != "KWR-042" || updated["status"] != enum.OrderStatusReady || updated["expires_at"] == nil {
+		t.Errorf("updated payload: got %v", updated)
+	}
+	if _, ok := updated["total_amount"]; ok {
+		t.Error("pickup event exposes total_amount")
+	}
+	if stations := hub.events[1].event.Stations; len(stations) != 0 {
+		t.Errorf("pickup event stations: got %v, want none", stations)
+	}
+
+	removed := decodeEventPayload(t, hub.events[4].event)
+	if len(removed) != 1 || removed["order_number"] != "KWR-042" {
+		t.Errorf("removed payload: got %v, want only order_number", removed)
+	}
+}
+
+func TestPickupDisplayPublisher_CachesDisplay(t *testing.T) {
+	withDisplay, withoutDisplay := uuid.New(), uuid.New()
+	store := newMockPickupDisplayStore()
+	store.displays[withDisplay] = database.PickupDisplay{OutletID: withDisplay, Token: "tv-token", OrderTypes: []string{enum.OrderTypeTakeaway}, ReadyTimeoutMinutes: 30}
+	hub := &mockBroadcaster{}
+	p := handler.NewPickupDisplayPublisher(hub, store)
+
+	for range 3 {
+		p.BroadcastToOutlet(withDisplay, orderEvent(t, ws.EventOrderStatusChanged, enum.OrderTypeTakeaway, enum.OrderStatusReady))
+		p.BroadcastToOutlet(withoutDisplay, orderEvent(t, ws.EventOrderStatusChanged, enum.OrderTypeTakeaway, enum.OrderStatusReady))
+	}
+
+	if store.lookups != 2 {
+		t.Errorf("lookups: got %d, want one per outlet", store.lookups)
+	}
+	if got := len(hub.types()); got != 9 {
+		t.Errorf("events: got %d, want 9 (3 pickup events for the outlet with a display)", got)
+	}
+}
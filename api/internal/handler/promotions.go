@@ -0,0 +1,540 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/shopspring/decimal"
+)
+
+// Longest promotion code, matching the column
+const maxPromotionCodeLength = 30
+
+// PromotionStore defines the database methods needed by promotion handlers.
+// Satisfied by *database.Queries; narrow interface for testability.
+type PromotionStore interface {
+	ListPromotions(ctx context.Context, outletID uuid.UUID) ([]database.Promotion, error)
+	GetPromotion(ctx context.Context, arg database.GetPromotionParams) (database.Promotion, error)
+	CreatePromotion(ctx context.Context, arg database.CreatePromotionParams) (database.Promotion, error)
+	UpdatePromotion(ctx context.Context, arg database.UpdatePromotionParams) (database.Promotion, error)
+	SoftDeletePromotion(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	// Scope validation
+	GetCategory(ctx context.Context, arg database.GetCategoryParams) (database.Category, error)
+	GetProduct(ctx context.Context, arg database.GetProductParams) (database.Product, error)
+}
+
+// PromotionHandler handles promotion configuration endpoints. Promotions
+// themselves are applied by the order service.
+type PromotionHandler struct {
+	store PromotionStore
+}
+
+// NewPromotionHandler creates a new PromotionHandler.
+func NewPromotionHandler(store PromotionStore) *PromotionHandler {
+	return &PromotionHandler{store: store}
+}
+
+// RegisterRoutes registers the read-only promotion endpoints that cashiers use.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/promotions
+func (h *PromotionHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Get("/{id}", h.Get)
+}
+
+// RegisterManageRoutes registers the endpoints that change promotions.
+// Expected to be mounted next to RegisterRoutes behind a manager role check.
+func (h *PromotionHandler) RegisterManageRoutes(r chi.Router) {
+	r.Post("/", h.Create)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+}
+
+// --- Request / Response types ---
+
+type promotionRequest struct {
+	Name          string     `json:"name"`
+	Code          string     `json:"code"`
+	PromotionType string     `json:"promotion_type"`
+	AllOutlets    bool       `json:"all_outlets"` // create only; owner only
+	CategoryID    string     `json:"category_id"`
+	ProductID     string     `json:"product_id"`
+	DiscountValue string     `json:"discount_value"`
+	BuyQuantity   *int32     `json:"buy_quantity"`
+	GetQuantity   *int32     `json:"get_quantity"`
+	MinSpend      string     `json:"min_spend"`
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until"`
+	StartTime     string     `json:"start_time"` // HH:MM, outlet time
+	EndTime       string     `json:"end_time"`
+	UsageLimit    *int32     `json:"usage_limit"`
+}
+
+type promotionResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	OutletID      *string    `json:"outlet_id"` // null for every outlet
+	Name          string     `json:"name"`
+	Code          *string    `json:"code"`
+	PromotionType string     `json:"promotion_type"`
+	CategoryID    *string    `json:"category_id"`
+	ProductID     *string    `json:"product_id"`
+	DiscountValue string     `json:"discount_value"`
+	BuyQuantity   *int32     `json:"buy_quantity"`
+	GetQuantity   *int32     `json:"get_quantity"`
+	MinSpend      *string    `json:"min_spend"`
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until"`
+	StartTime     *string    `json:"start_time"`
+	EndTime       *string    `json:"end_time"`
+	UsageLimit    *int32     `json:"usage_limit"`
+	IsActive      bool       `json:"is_active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func toPromotionResponse(p database.Promotion) promotionResponse {
+	resp := promotionResponse{
+		ID:            p.ID,
+		Name:          p.Name,
+		PromotionType: p.PromotionType,
+		DiscountValue: numericToString(p.DiscountValue),
+		IsActive:      p.IsActive,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+	if p.OutletID.Valid {
+		s := uuid.UUID(p.OutletID.Bytes).String()
+		resp.OutletID = &s
+	}
+	if p.Code.Valid {
+		resp.Code = &p.Code.String
+	}
+	if p.CategoryID.Valid {
+		s := uuid.UUID(p.CategoryID.Bytes).String()
+		resp.CategoryID = &s
+	}
+	if p.ProductID.Valid {
+		s := uuid.UUID(p.ProductID.Bytes).String()
+		resp.ProductID = &s
+	}
+	if p.BuyQuantity.Valid {
+		resp.BuyQuantity = &p.BuyQuantity.Int32
+	}
+	if p.GetQuantity.Valid {
+		resp.GetQuantity = &p.GetQuantity.Int32
+	}
+	if p.MinSpend.Valid {
+		s := numericToString(p.MinSpend)
+		resp.MinSpend = &s
+	}
+	if p.ValidFrom.Valid {
+		resp.ValidFrom = &p.ValidFrom.Time
+	}
+	if p.ValidUntil.Valid {
+		resp.ValidUntil = &p.ValidUntil.Time
+	}
+	if p.StartTime.Valid {
+		s := formatClock(p.StartTime)
+		resp.StartTime = &s
+	}
+	if p.EndTime.Valid {
+		s := formatClock(p.EndTime)
+		resp.EndTime = &s
+	}
+	if p.UsageLimit.Valid {
+		resp.UsageLimit = &p.UsageLimit.Int32
+	}
+	return resp
+}
+
+// --- Handlers ---
+
+// List returns the promotions offered at the outlet, including those for every outlet.
+func (h *PromotionHandler) List(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	promotions, err := h.store.ListPromotions(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list promotions: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]promotionResponse, len(promotions))
+	for i, p := range promotions {
+		resp[i] = toPromotionResponse(p)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Get returns a single promotion offered at the outlet.
+func (h *PromotionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	promotion, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toPromotionResponse(promotion))
+}
+
+// Create adds a promotion to the outlet, or to every outlet for owners.
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var req promotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	scope := pgtype.UUID{Bytes: outletID, Valid: true}
+	if req.AllOutlets {
+		claims := middleware.ClaimsFromContext(r.Context())
+		if claims == nil || claims.Role != enum.UserRoleOwner {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "only owners can create promotions for every outlet"})
+			return
+		}
+		scope = pgtype.UUID{}
+	}
+
+	params, ok := h.parseRequest(w, r, req, outletID, !scope.Valid)
+	if !ok {
+		return
+	}
+	params.OutletID = scope
+
+	promotion, err := h.store.CreatePromotion(r.Context(), params)
+	if err != nil {
+		if isPromotionCodeConflict(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "promotion code already exists"})
+			return
+		}
+		log.Printf("ERROR: create promotion: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toPromotionResponse(promotion))
+}
+
+// Update replaces a promotion's rules. Its outlet scope cannot change, and
+// promotions for every outlet can only be changed by owners.
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.load(w, r)
+	if !ok || !h.canManage(w, r, current) {
+		return
+	}
+
+	var req promotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	params, ok := h.parseRequest(w, r, req, uuid.UUID(current.OutletID.Bytes), !current.OutletID.Valid)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.store.UpdatePromotion(r.Context(), database.UpdatePromotionParams{
+		Name:          params.Name,
+		Code:          params.Code,
+		PromotionType: params.PromotionType,
+		CategoryID:    params.CategoryID,
+		ProductID:     params.ProductID,
+		DiscountValue: params.DiscountValue,
+		BuyQuantity:   params.BuyQuantity,
+		GetQuantity:   params.GetQuantity,
+		MinSpend:      params.MinSpend,
+		ValidFrom:     params.ValidFrom,
+		ValidUntil:    params.ValidUntil,
+		StartTime:     params.StartTime,
+		EndTime:       params.EndTime,
+		UsageLimit:    params.UsageLimit,
+		ID:            current.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "promotion not found"})
+			return
+		}
+		if isPromotionCodeConflict(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "promotion code already exists"})
+			return
+		}
+		log.Printf("ERROR: update promotion: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toPromotionResponse(promotion))
+}
+
+// Delete soft-deletes a promotion by setting is_active=false. Orders that
+// already received it keep their discount.
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.load(w, r)
+	if !ok || !h.canManage(w, r, current) {
+		return
+	}
+
+	if _, err := h.store.SoftDeletePromotion(r.Context(), current.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "promotion not found"})
+			return
+		}
+		log.Printf("ERROR: delete promotion: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Helpers ---
+
+// load fetches the promotion in the URL if the outlet offers it, writing the
+// error response if not.
+func (h *PromotionHandler) load(w http.ResponseWriter, r *http.Request) (database.Promotion, bool) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return database.Promotion{}, false
+	}
+	promotionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid promotion ID"})
+		return database.Promotion{}, false
+	}
+
+	promotion, err := h.store.GetPromotion(r.Context(), database.GetPromotionParams{
+		ID:       promotionID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "promotion not found"})
+			return database.Promotion{}, false
+		}
+		log.Printf("ERROR: get promotion: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.Promotion{}, false
+	}
+	return promotion, true
+}
+
+// canManage reports whether the caller may change p, writing 403 if not.
+// Promotions for every outlet belong to the owner.
+func (h *PromotionHandler) canManage(w http.ResponseWriter, r *http.Request, p database.Promotion) bool {
+	if p.OutletID.Valid {
+		return true
+	}
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil || claims.Role != enum.UserRoleOwner {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only owners can change promotions for every outlet"})
+		return false
+	}
+	return true
+}
+
+// parseRequest validates a promotion request, writing 400 if it is invalid.
+// Category and product scopes must belong to outletID, and are not allowed
+// for promotions that apply at every outlet. OutletID is left unset.
+func (h *PromotionHandler) parseRequest(w http.ResponseWriter, r *http.Request, req promotionRequest, outletID uuid.UUID, allOutlets bool) (database.CreatePromotionParams, bool) {
+	params, err := parsePromotionRequest(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return params, false
+	}
+
+	if allOutlets && (params.CategoryID.Valid || params.ProductID.Valid) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "promotions for every outlet cannot be limited to a category or product"})
+		return params, false
+	}
+	if params.CategoryID.Valid {
+		_, err := h.store.GetCategory(r.Context(), database.GetCategoryParams{ID: params.CategoryID.Bytes, OutletID: outletID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "category not found"})
+				return params, false
+			}
+			log.Printf("ERROR: get promotion category: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return params, false
+		}
+	}
+	if params.ProductID.Valid {
+		_, err := h.store.GetProduct(r.Context(), database.GetProductParams{ID: params.ProductID.Bytes, OutletID: outletID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "product not found"})
+				return params, false
+			}
+			log.Printf("ERROR: get promotion product: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return params, false
+		}
+	}
+	return params, true
+}
+
+// parsePromotionRequest checks the fields that need no database lookups.
+// Codes are stored upper-cased, matching what cashiers enter on orders.
+func parsePromotionRequest(req promotionRequest) (database.CreatePromotionParams, error) {
+	params := database.CreatePromotionParams{
+		Name:          strings.TrimSpace(req.Name),
+		PromotionType: req.PromotionType,
+	}
+	if params.Name == "" {
+		return params, errors.New("name is required")
+	}
+
+	if code := strings.ToUpper(strings.TrimSpace(req.Code)); code != "" {
+		if len(code) > maxPromotionCodeLength {
+			return params, fmt.Errorf("code must be at most %d characters", maxPromotionCodeLength)
+		}
+		params.Code = pgtype.Text{String: code, Valid: true}
+	}
+
+	value := decimal.Zero
+	if req.DiscountValue != "" {
+		v, err := decimal.NewFromString(req.DiscountValue)
+		if err != nil || v.IsNegative() {
+			return params, errors.New("invalid discount_value")
+		}
+		value = v
+	}
+	params.DiscountValue = decimalToNumeric(value)
+
+	switch req.PromotionType {
+	case enum.PromotionTypePercentage:
+		if !value.IsPositive() || value.GreaterThan(decimal.NewFromInt(100)) {
+			return params, errors.New("discount_value must be a percentage between 0 and 100")
+		}
+	case enum.PromotionTypeFixed:
+		if !value.IsPositive() {
+			return params, errors.New("discount_value must be > 0")
+		}
+	case enum.PromotionTypeBuyXGetY:
+		if req.BuyQuantity == nil || *req.BuyQuantity <= 0 || req.GetQuantity == nil || *req.GetQuantity <= 0 {
+			return params, errors.New("buy_quantity and get_quantity must be > 0")
+		}
+		params.BuyQuantity = pgtype.Int4{Int32: *req.BuyQuantity, Valid: true}
+		params.GetQuantity = pgtype.Int4{Int32: *req.GetQuantity, Valid: true}
+	case enum.PromotionTypeBundle:
+		if req.BuyQuantity == nil || *req.BuyQuantity < 2 {
+			return params, errors.New("buy_quantity must be at least 2 items per bundle")
+		}
+		if !value.IsPositive() {
+			return params, errors.New("discount_value must be the bundle price")
+		}
+		params.BuyQuantity = pgtype.Int4{Int32: *req.BuyQuantity, Valid: true}
+	default:
+		return params, errors.New("invalid promotion_type")
+	}
+
+	if req.CategoryID != "" && req.ProductID != "" {
+		return params, errors.New("set category_id or product_id, not both")
+	}
+	if req.CategoryID != "" {
+		id, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			return params, errors.New("invalid category_id")
+		}
+		params.CategoryID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	if req.ProductID != "" {
+		id, err := uuid.Parse(req.ProductID)
+		if err != nil {
+			return params, errors.New("invalid product_id")
+		}
+		params.ProductID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	if req.MinSpend != "" {
+		v, err := decimal.NewFromString(req.MinSpend)
+		if err != nil || v.IsNegative() {
+			return params, errors.New("invalid min_spend")
+		}
+		params.MinSpend = decimalToNumeric(v)
+	}
+
+	if req.ValidFrom != nil {
+		params.ValidFrom = pgtype.Timestamptz{Time: *req.ValidFrom, Valid: true}
+	}
+	if req.ValidUntil != nil {
+		if req.ValidFrom != nil && !req.ValidUntil.After(*req.ValidFrom) {
+			return params, errors.New("valid_until must be after valid_from")
+		}
+		params.ValidUntil = pgtype.Timestamptz{Time: *req.ValidUntil, Valid: true}
+	}
+
+	if (req.StartTime == "") != (req.EndTime == "") {
+		return params, errors.New("start_time and end_time must be set together")
+	}
+	if req.StartTime != "" {
+		start, err := parseClock(req.StartTime)
+		if err != nil {
+			return params, errors.New("invalid start_time, expected HH:MM")
+		}
+		end, err := parseClock(req.EndTime)
+		if err != nil {
+			return params, errors.New("invalid end_time, expected HH:MM")
+		}
+		if start == end {
+			return params, errors.New("start_time and end_time must differ")
+		}
+		params.StartTime, params.EndTime = start, end
+	}
+
+	if req.UsageLimit != nil {
+		if *req.UsageLimit <= 0 {
+			return params, errors.New("usage_limit must be > 0")
+		}
+		params.UsageLimit = pgtype.Int4{Int32: *req.UsageLimit, Valid: true}
+	}
+
+	return params, nil
+}
+
+// parseClock parses an HH:MM time of day.
+func parseClock(s string) (pgtype.Time, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return pgtype.Time{}, err
+	}
+	since := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	return pgtype.Time{Microseconds: since.Microseconds(), Valid: true}, nil
+}
+
+// formatClock formats a time of day as HH:MM.
+func formatClock(t pgtype.Time) string {
+	minutes := t.Microseconds / time.Minute.Microseconds()
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// isPromotionCodeConflict reports whether err is a duplicate promotion code.
+func isPromotionCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
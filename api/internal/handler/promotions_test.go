@@ -0,0 +1,366 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/middleware"
+)
+
+// --- Mock store ---
+
+type mockPromotionStore struct {
+	promotions map[uuid.UUID]database.Promotion
+	categories map[uuid.UUID]uuid.UUID // category ID -> outlet ID
+	products   map[uuid.UUID]uuid.UUID // product ID -> outlet ID
+}
+
+func newMockPromotionStore() *mockPromotionStore {
+	return &mockPromotionStore{
+		promotions: make(map[uuid.UUID]database.Promotion),
+		categories: make(map[uuid.UUID]uuid.UUID),
+		products:   make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (m *mockPromotionStore) offered(p database.Promotion, outletID uuid.UUID) bool {
+	return p.IsActive && (!p.OutletID.Valid || uuid.UUID(p.OutletID.Bytes) == outletID)
+}
+
+func (m *mockPromotionStore) codeTaken(code pgtype.Text, except uuid.UUID) bool {
+	for _, p := range m.promotions {
+		if code.Valid && p.ID != except && p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockPromotionStore) ListPromotions(_ context.Context, outletID uuid.UUID) ([]database.Promotion, error) {
+	var result []database.Promotion
+	for _, p := range m.promotions {
+		if m.offered(p, outletID) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPromotionStore) GetPromotion(_ context.Context, arg database.GetPromotionParams) (database.Promotion, error) {
+	p, ok := m.promotions[arg.ID]
+	if !ok || !m.offered(p, arg.OutletID) {
+		return database.Promotion{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (m *mockPromotionStore) CreatePromotion(_ context.Context, arg database.CreatePromotionParams) (database.Promotion, error) {
+	if m.codeTaken(arg.Code, uuid.Nil) {
+		return database.Promotion{}, &pgconn.PgError{Code: "23505"}
+	}
+	p := database.Promotion{
+		ID:            uuid.New(),
+		OutletID:      arg.OutletID,
+		Name:          arg.Name,
+		Code:          arg.Code,
+		PromotionType: arg.PromotionType,
+		CategoryID:    arg.CategoryID,
+		ProductID:     arg.ProductID,
+		DiscountValue: arg.DiscountValue,
+		BuyQuantity:   arg.BuyQuantity,
+		GetQuantity:   arg.GetQuantity,
+		MinSpend:      arg.MinSpend,
+		ValidFrom:     arg.ValidFrom,
+		ValidUntil:    arg.ValidUntil,
+		StartTime:     arg.StartTime,
+		EndTime:       arg.EndTime,
+		UsageLimit:    arg.UsageLimit,
+		IsActive:      true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	m.promotions[p.ID] = p
+	return p, nil
+}
+
+func (m *mockPromotionStore) UpdatePromotion(_ context.Context, arg database.UpdatePromotionParams) (database.Promotion, error) {
+	p, ok := m.promotions[arg.ID]
+	if !ok || !p.IsActive {
+		return database.Promotion{}, pgx.ErrNoRows
+	}
+	if m.codeTaken(arg.Code, arg.ID) {
+		return database.Promotion{}, &pgconn.PgError{Code: "23505"}
+	}
+	p.Name = arg.Name
+	p.Code = arg.Code
+	p.PromotionType = arg.PromotionType
+	p.DiscountValue = arg.DiscountValue
+	p.StartTime = arg.StartTime
+	p.EndTime = arg.EndTime
+	m.promotions[p.ID] = p
+	return p, nil
+}
+
+func (m *mockPromotionStore) SoftDeletePromotion(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	p, ok := m.promotions[id]
+	if !ok || !p.IsActive {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	p.IsActive = false
+	m.promotions[id] = p
+	return id, nil
+}
+
+func (m *mockPromotionStore) GetCategory(_ context.Context, arg database.GetCategoryParams) (database.Category, error) {
+	if outletID, ok := m.categories[arg.ID]; !ok || outletID != arg.OutletID {
+		return database.Category{}, pgx.ErrNoRows
+	}
+	return database.Category{ID: arg.ID, OutletID: arg.OutletID}, nil
+}
+
+func (m *mockPromotionStore) GetProduct(_ context.Context, arg database.GetProductParams) (database.Product, error) {
+	if outletID, ok := m.products[arg.ID]; !ok || outletID != arg.OutletID {
+		return database.Product{}, pgx.ErrNoRows
+	}
+	return database.Product{ID: arg.ID, OutletID: arg.OutletID}, nil
+}
+
+// --- Helpers ---
+
+func setupPromotionRouter(store *mockPromotionStore) *chi.Mux {
+	h := handler.NewPromotionHandler(store)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/promotions", func(r chi.Router) {
+		h.RegisterRoutes(r)
+		h.RegisterManageRoutes(r)
+	})
+	return r
+}
+
+func promotionClaims(outletID uuid.UUID, role string) *auth.Claims {
+	return &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: role}
+}
+
+func decodePromotionResponse(t *testing.T, rr *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+// --- Create tests ---
+
+func TestPromotionCreate_HappyHourByCategory(t *testing.T) {
+	store := newMockPromotionStore()
+	router := setupPromotionRouter(store)
+	outletID := uuid.New()
+	categoryID := uuid.New()
+	store.categories[categoryID] = outletID
+
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", map[string]interface{}{
+		"name":           "Happy Hour Kopi",
+		"promotion_type": "PERCENTAGE",
+		"category_id":    categoryID.String(),
+		"discount_value": "20",
+		"start_time":     "15:00",
+		"end_time":       "17:30",
+	}, promotionClaims(outletID, "MANAGER"))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodePromotionResponse(t, rr)
+	if resp["outlet_id"] != outletID.String() {
+		t.Errorf("outlet_id: got %v, want %s", resp["outlet_id"], outletID)
+	}
+	if resp["start_time"] != "15:00" || resp["end_time"] != "17:30" {
+		t.Errorf("window: got %v-%v, want 15:00-17:30", resp["start_time"], resp["end_time"])
+	}
+	if resp["discount_value"] != "20.00" {
+		t.Errorf("discount_value: got %v, want 20.00", resp["discount_value"])
+	}
+}
+
+func TestPromotionCreate_CodeIsUpperCasedAndUnique(t *testing.T) {
+	store := newMockPromotionStore()
+	router := setupPromotionRouter(store)
+	outletID := uuid.New()
+	claims := promotionClaims(outletID, "MANAGER")
+	body := map[string]interface{}{
+		"name":           "Ramadan",
+		"code":           " ramadan10 ",
+		"promotion_type": "FIXED_AMOUNT",
+		"discount_value": "10000",
+		"min_spend":      "100000",
+	}
+
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", body, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if resp := decodePromotionResponse(t, rr); resp["code"] != "RAMADAN10" {
+		t.Errorf("code: got %v, want RAMADAN10", resp["code"])
+	}
+
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", body, claims)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate code: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestPromotionCreate_Validation(t *testing.T) {
+	outletID := uuid.New()
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"missing name", map[string]interface{}{"promotion_type": "PERCENTAGE", "discount_value": "10"}},
+		{"unknown type", map[string]interface{}{"name": "X", "promotion_type": "FREE_LUNCH"}},
+		{"percentage over 100", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "120"}},
+		{"buy x get y without quantities", map[string]interface{}{"name": "X", "promotion_type": "BUY_X_GET_Y"}},
+		{"bundle of one", map[string]interface{}{"name": "X", "promotion_type": "BUNDLE_PRICE", "discount_value": "50000", "buy_quantity": 1}},
+		{"category and product", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "category_id": uuid.New().String(), "product_id": uuid.New().String()}},
+		{"unknown product", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "product_id": uuid.New().String()}},
+		{"half a window", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "start_time": "15:00"}},
+		{"bad time", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "start_time": "3pm", "end_time": "5pm"}},
+		{"ends before it starts", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "valid_from": "2026-03-10T00:00:00Z", "valid_until": "2026-03-01T00:00:00Z"}},
+		{"zero usage limit", map[string]interface{}{"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10", "usage_limit": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupPromotionRouter(newMockPromotionStore())
+			rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", tt.body, promotionClaims(outletID, "MANAGER"))
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("status: got %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPromotionCreate_AllOutletsOwnerOnly(t *testing.T) {
+	store := newMockPromotionStore()
+	router := setupPromotionRouter(store)
+	outletID := uuid.New()
+	body := map[string]interface{}{
+		"name":           "Buy 2 Get 1",
+		"promotion_type": "BUY_X_GET_Y",
+		"buy_quantity":   2,
+		"get_quantity":   1,
+		"all_outlets":    true,
+	}
+
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", body, promotionClaims(outletID, "MANAGER"))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("manager: got %d, want %d", rr.Code, http.StatusForbidden)
+	}
+
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", body, promotionClaims(outletID, "OWNER"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("owner: got %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	resp := decodePromotionResponse(t, rr)
+	if resp["outlet_id"] != nil {
+		t.Errorf("outlet_id: got %v, want null", resp["outlet_id"])
+	}
+
+	// Offered at every outlet, but only the owner may change it
+	otherOutlet := uuid.New()
+	path := "/outlets/" + otherOutlet.String() + "/promotions/" + resp["id"].(string)
+	if rr := doAuthRequest(t, router, "GET", path, nil, promotionClaims(otherOutlet, "CASHIER")); rr.Code != http.StatusOK {
+		t.Errorf("get at other outlet: got %d, want %d", rr.Code, http.StatusOK)
+	}
+	if rr := doAuthRequest(t, router, "DELETE", path, nil, promotionClaims(otherOutlet, "MANAGER")); rr.Code != http.StatusForbidden {
+		t.Errorf("manager delete: got %d, want %d", rr.Code, http.StatusForbidden)
+	}
+}
+
+// --- Update / Delete tests ---
+
+func TestPromotionUpdate(t *testing.T) {
+	store := newMockPromotionStore()
+	router := setupPromotionRouter(store)
+	outletID := uuid.New()
+	claims := promotionClaims(outletID, "MANAGER")
+
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", map[string]interface{}{
+		"name":           "Paket Hemat",
+		"promotion_type": "BUNDLE_PRICE",
+		"buy_quantity":   3,
+		"discount_value": "50000",
+	}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: got %d; body: %s", rr.Code, rr.Body.String())
+	}
+	id := decodePromotionResponse(t, rr)["id"].(string)
+
+	rr = doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/promotions/"+id, map[string]interface{}{
+		"name":           "Paket Hemat Malam",
+		"promotion_type": "BUNDLE_PRICE",
+		"buy_quantity":   3,
+		"discount_value": "45000",
+		"start_time":     "21:00",
+		"end_time":       "02:00",
+	}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: got %d; body: %s", rr.Code, rr.Body.String())
+	}
+	resp := decodePromotionResponse(t, rr)
+	if resp["name"] != "Paket Hemat Malam" || resp["discount_value"] != "45000.00" {
+		t.Errorf("update: got %v / %v", resp["name"], resp["discount_value"])
+	}
+	if resp["start_time"] != "21:00" || resp["end_time"] != "02:00" {
+		t.Errorf("window: got %v-%v, want 21:00-02:00", resp["start_time"], resp["end_time"])
+	}
+
+	// Another outlet's promotion is not found
+	other := uuid.New()
+	rr = doAuthRequest(t, router, "PUT", "/outlets/"+other.String()+"/promotions/"+id, map[string]interface{}{
+		"name": "X", "promotion_type": "PERCENTAGE", "discount_value": "10",
+	}, promotionClaims(other, "MANAGER"))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("other outlet: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestPromotionDelete(t *testing.T) {
+	store := newMockPromotionStore()
+	router := setupPromotionRouter(store)
+	outletID := uuid.New()
+	claims := promotionClaims(outletID, "MANAGER")
+
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/promotions", map[string]interface{}{
+		"name":           "Diskon 10%",
+		"promotion_type": "PERCENTAGE",
+		"discount_value": "10",
+	}, claims)
+	id := decodePromotionResponse(t, rr)["id"].(string)
+
+	rr = doAuthRequest(t, router, "DELETE", "/outlets/"+outletID.String()+"/promotions/"+id, nil, claims)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d, want %d", rr.Code, http.StatusNoContent)
+	}
+
+	rr = doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/promotions", nil, claims)
+	var list []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("list after delete: got %d promotions, want 0", len(list))
+	}
+}
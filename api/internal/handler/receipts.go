@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/receipt"
+	"github.com/shopspring/decimal"
+)
+
+// Receipt output formats
+const (
+	receiptFormatText   = "text"
+	receiptFormatHTML   = "html"
+	receiptFormatESCPOS = "escpos"
+)
+
+var orderTypeLabels = map[string]string{
+	enum.OrderTypeDineIn:   "Dine in",
+	enum.OrderTypeTakeaway: "Takeaway",
+	enum.OrderTypeDelivery: "Delivery",
+	enum.OrderTypeCatering: "Catering",
+}
+
+var paymentMethodLabels = map[string]string{
+	enum.PaymentMethodCash:     "Cash",
+	enum.PaymentMethodQRIS:     "QRIS",
+	enum.PaymentMethodTransfer: "Transfer",
+	enum.PaymentMethodPoints:   "Points",
+}
+
+// ReceiptStore defines the database methods needed to render a receipt.
+// Satisfied by *database.Queries; narrow interface for testability.
+type ReceiptStore interface {
+	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	GetOutlet(ctx context.Context, id uuid.UUID) (database.Outlet, error)
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+	ListReceiptItems(ctx context.Context, orderID uuid.UUID) ([]database.ListReceiptItemsRow, error)
+	ListReceiptItemModifiers(ctx context.Context, orderID uuid.UUID) ([]database.ListReceiptItemModifiersRow, error)
+	ListPaymentsByOrder(ctx context.Context, orderID uuid.UUID) ([]database.Payment, error)
+}
+
+// ReceiptHandler renders customer receipts.
+type ReceiptHandler struct {
+	store ReceiptStore
+}
+
+// NewReceiptHandler creates a new ReceiptHandler.
+func NewReceiptHandler(store ReceiptStore) *ReceiptHandler {
+	return &ReceiptHandler{store: store}
+}
+
+// RegisterRoutes registers receipt endpoints on the given Chi router.
+// Expected to be mounted at /outlets/{oid}/orders/{id}/receipt
+func (h *ReceiptHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.Get)
+}
+
+// Get renders the order's receipt.
+// Query params: format=text|html|escpos (default text), paper=58|80
+// (default the outlet's receipt_paper_width).
+func (h *ReceiptHandler) Get(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receiptFormatText
+	}
+	if format != receiptFormatText && format != receiptFormatHTML && format != receiptFormatESCPOS {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be text, html or escpos"})
+		return
+	}
+
+	paperWidth, ok := paperParam(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "paper must be 58 or 80"})
+		return
+	}
+
+	ctx := r.Context()
+
+	order, err := h.store.GetOrder(ctx, database.GetOrderParams{ID: orderID, OutletID: outletID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found"})
+			return
+		}
+		log.Printf("ERROR: get order for receipt: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	outlet, err := h.store.GetOutlet(ctx, outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "outlet not found"})
+			return
+		}
+		log.Printf("ERROR: get outlet for receipt: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Outlets that never saved settings print without header or footer text
+	settings, err := h.store.GetOutletSettings(ctx, outletID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("ERROR: get outlet settings for receipt: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	paperWidth = printerPaperWidth(paperWidth, settings)
+
+	items, err := h.store.ListReceiptItems(ctx, orderID)
+	if err != nil {
+		log.Printf("ERROR: list receipt items: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	mods, err := h.store.ListReceiptItemModifiers(ctx, orderID)
+	if err != nil {
+		log.Printf("ERROR: list receipt item modifiers: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	payments, err := h.store.ListPaymentsByOrder(ctx, orderID)
+	if err != nil {
+		log.Printf("ERROR: list payments for receipt: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// A deactivated cashier is left off rather than failing the receipt
+	cashier := ""
+	if user, err := h.store.GetUserByID(ctx, order.CreatedBy); err == nil {
+		cashier = user.FullName
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("ERROR: get cashier for receipt: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	rc, err := buildReceipt(outlet, settings, order, cashier, items, mods, payments)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	switch format {
+	case receiptFormatESCPOS:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write(rc.ESCPOS(paperWidth))
+	case receiptFormatHTML:
+		body, err := rc.HTML(paperWidth)
+		if err != nil {
+			log.Printf("ERROR: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(rc.Text(paperWidth)))
+	}
+}
+
+// buildReceipt assembles what the receipt shows from the stored order.
+// Combo components are listed under their combo; only completed payments count.
+func buildReceipt(
+	outlet database.Outlet,
+	settings database.OutletSetting,
+	order database.Order,
+	cashier string,
+	items []database.ListReceiptItemsRow,
+	mods []database.ListReceiptItemModifiersRow,
+	payments []database.Payment,
+) (receipt.Receipt, error) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+
+	rc := receipt.Receipt{
+		OutletName:  outlet.Name,
+		OrderNumber: order.OrderNumber,
+		OrderType:   orderTypeLabels[order.OrderType],
+		Cashier:     cashier,
+		Time:        order.CreatedAt.In(loc),
+		TaxIncluded: order.PricesIncludeTax,
+		Footer:      splitLines(settings.ReceiptFooter),
+	}
+	for _, amount := range []struct {
+		name string
+		dst  *decimal.Decimal
+		src  pgtype.Numeric
+	}{
+		{"subtotal", &rc.Subtotal, order.Subtotal},
+		{"discount", &rc.Discount, order.DiscountAmount},
+		{"service charge", &rc.ServiceCharge, order.ServiceChargeAmount},
+		{"service charge rate", &rc.ServiceChargeRate, order.ServiceChargeRate},
+		{"tax", &rc.Tax, order.TaxAmount},
+		{"tax rate", &rc.TaxRate, order.TaxRate},
+		{"total", &rc.Total, order.TotalAmount},
+	} {
+		if *amount.dst, err = numericToDecimal(amount.src); err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse order %s: %w", amount.name, err)
+		}
+	}
+	if outlet.Address.Valid && outlet.Address.String != "" {
+		rc.Header = append(rc.Header, outlet.Address.String)
+	}
+	if outlet.Phone.Valid && outlet.Phone.String != "" {
+		rc.Header = append(rc.Header, "Tel. "+outlet.Phone.String)
+	}
+	rc.Header = append(rc.Header, splitLines(settings.ReceiptHeader)...)
+	if order.TableNumber.Valid {
+		rc.TableNumber = order.TableNumber.String
+	}
+	if order.DeliveryPlatform.Valid && order.DeliveryPlatform.String != "" {
+		rc.OrderType += " (" + order.DeliveryPlatform.String + ")"
+	}
+
+	modsByItem := make(map[uuid.UUID][]receipt.Modifier)
+	for _, m := range mods {
+		unitPrice, err := numericToDecimal(m.UnitPrice)
+		if err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse modifier %s price: %w", m.Name, err)
+		}
+		modsByItem[m.OrderItemID] = append(modsByItem[m.OrderItemID], receipt.Modifier{
+			Name:     m.Name,
+			Quantity: m.Quantity,
+			Amount:   unitPrice.Mul(decimal.NewFromInt32(m.Quantity)),
+		})
+	}
+
+	components := make(map[uuid.UUID][]string)
+	for _, item := range items {
+		if item.ParentItemID.Valid {
+			parentID := uuid.UUID(item.ParentItemID.Bytes)
+			components[parentID] = append(components[parentID], strconv.Itoa(int(item.Quantity))+"x "+receiptItemName(item))
+		}
+	}
+
+	for _, item := range items {
+		if item.ParentItemID.Valid {
+			continue
+		}
+		unitPrice, err := numericToDecimal(item.UnitPrice)
+		if err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse item %s price: %w", item.ID, err)
+		}
+		discount, err := numericToDecimal(item.DiscountAmount)
+		if err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse item %s discount: %w", item.ID, err)
+		}
+		ri := receipt.Item{
+			Name:       receiptItemName(item),
+			Quantity:   item.Quantity,
+			Amount:     unitPrice.Mul(decimal.NewFromInt32(item.Quantity)),
+			Modifiers:  modsByItem[item.ID],
+			Discount:   discount,
+			Components: components[item.ID],
+		}
+		if item.Notes.Valid {
+			ri.Notes = item.Notes.String
+		}
+		rc.Items = append(rc.Items, ri)
+	}
+
+	for _, p := range payments {
+		if p.Status != enum.PaymentStatusCompleted {
+			continue
+		}
+		method, ok := paymentMethodLabels[p.PaymentMethod]
+		if !ok {
+			method = p.PaymentMethod
+		}
+		amount := p.Amount
+		if p.PaymentMethod == enum.PaymentMethodCash && p.AmountReceived.Valid {
+			// The customer handed over more than the amount due
+			amount = p.AmountReceived
+		}
+		rp := receipt.Payment{Method: method}
+		if rp.Amount, err = numericToDecimal(amount); err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse payment %s amount: %w", p.ID, err)
+		}
+		if p.ReferenceNumber.Valid {
+			rp.Reference = p.ReferenceNumber.String
+		}
+		change, err := numericToDecimal(p.ChangeAmount)
+		if err != nil {
+			return receipt.Receipt{}, fmt.Errorf("parse payment %s change: %w", p.ID, err)
+		}
+		rc.Payments = append(rc.Payments, rp)
+		rc.Change = rc.Change.Add(change)
+	}
+
+	return rc, nil
+}
+
+// paperParam reads the optional ?paper= width. Returns 0 when absent and
+// false when it is not a supported width.
+func paperParam(r *http.Request) (int32, bool) {
+	s := r.URL.Query().Get("paper")
+	if s == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || !receipt.IsValidPaperWidth(int32(v)) {
+		return 0, false
+	}
+	return int32(v), true
+}
+
+// printerPaperWidth is the requested width, else the outlet's configured one.
+func printerPaperWidth(requested int32, settings database.OutletSetting) int32 {
+	if requested != 0 {
+		return requested
+	}
+	if receipt.IsValidPaperWidth(settings.ReceiptPaperWidth) {
+		return settings.ReceiptPaperWidth
+	}
+	return receipt.Paper58
+}
+
+func receiptItemName(item database.ListReceiptItemsRow) string {
+	if len(item.VariantNames) == 0 {
+		return item.ProductName
+	}
+	return item.ProductName + " (" + strings.Join(item.VariantNames, ", ") + ")"
+}
+
+// splitLines splits multi-line settings text, dropping trailing blank lines.
+func splitLines(s string) []string {
+	s = strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n ")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
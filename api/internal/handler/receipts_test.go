@@ -0,0 +1,301 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+)
+
+// --- Mock ReceiptStore ---
+
+type mockReceiptStore struct {
+	orders    map[uuid.UUID]database.Order
+	outlet    database.Outlet
+	settings  *database.OutletSetting
+	users     map[uuid.UUID]database.User
+	items     []database.ListReceiptItemsRow
+	modifiers []database.ListReceiptItemModifiersRow
+	payments  []database.Payment
+}
+
+func newMockReceiptStore() *mockReceiptStore {
+	return &mockReceiptStore{
+		orders: make(map[uuid.UUID]database.Order),
+		users:  make(map[uuid.UUID]database.User),
+	}
+}
+
+func (m *mockReceiptStore) GetOrder(_ context.Context, arg database.GetOrderParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (m *mockReceiptStore) GetOutlet(_ context.Context, id uuid.UUID) (database.Outlet, error) {
+	if m.outlet.ID != id {
+		return database.Outlet{}, pgx.ErrNoRows
+	}
+	return m.outlet, nil
+}
+
+func (m *mockReceiptStore) GetOutletSettings(_ context.Context, _ uuid.UUID) (database.OutletSetting, error) {
+	if m.settings == nil {
+		return database.OutletSetting{}, pgx.ErrNoRows
+	}
+	return *m.settings, nil
+}
+
+func (m *mockReceiptStore) GetUserByID(_ context.Context, id uuid.UUID) (database.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return database.User{}, pgx.ErrNoRows
+	}
+	return u, nil
+}
+
+func (m *mockReceiptStore) ListReceiptItems(_ context.Context, _ uuid.UUID) ([]database.ListReceiptItemsRow, error) {
+	return m.items, nil
+}
+
+func (m *mockReceiptStore) ListReceiptItemModifiers(_ context.Context, _ uuid.UUID) ([]database.ListReceiptItemModifiersRow, error) {
+	return m.modifiers, nil
+}
+
+func (m *mockReceiptStore) ListPaymentsByOrder(_ context.Context, _ uuid.UUID) ([]database.Payment, error) {
+	return m.payments, nil
+}
+
+// --- Helpers ---
+
+func setupReceiptRouter(store *mockReceiptStore) *chi.Mux {
+	h := handler.NewReceiptHandler(store)
+	r := chi.NewRouter()
+	r.Route("/outlets/{oid}/orders/{id}/receipt", h.RegisterRoutes)
+	return r
+}
+
+// seedReceiptOrder stores a paid dine-in order: 2x Nasi Goreng (Pedas) with
+// extra egg and a 5.000 discount, a combo with one component, 10% tax and
+// a cash payment of 100.000.
+func seedReceiptOrder(store *mockReceiptStore) (uuid.UUID, uuid.UUID) {
+	outletID := uuid.New()
+	orderID := uuid.New()
+	cashierID := uuid.New()
+
+	store.outlet = database.Outlet{
+		ID:      outletID,
+		Name:    "Kiwari Bandung",
+		Address: pgtype.Text{String: "Jl. Braga 12", Valid: true},
+		Phone:   pgtype.Text{String: "022-123456", Valid: true},
+	}
+	store.users[cashierID] = database.User{ID: cashierID, FullName: "Sari"}
+	store.orders[orderID] = database.Order{
+		ID:               orderID,
+		OutletID:         outletID,
+		OrderNumber:      "KWR-001",
+		OrderType:        enum.OrderTypeDineIn,
+		TableNumber:      pgtype.Text{String: "7", Valid: true},
+		Subtotal:         testNumeric("75000"),
+		DiscountAmount:   testNumeric("0"),
+		TaxAmount:        testNumeric("7500"),
+		TaxRate:          testNumeric("10"),
+		TotalAmount:      testNumeric("82500"),
+		CreatedBy:        cashierID,
+		CreatedAt:        time.Date(2026, 3, 1, 5, 30, 0, 0, time.UTC),
+		PricesIncludeTax: false,
+	}
+
+	nasiID := uuid.New()
+	comboID := uuid.New()
+	store.items = []database.ListReceiptItemsRow{
+		{
+			ID:             nasiID,
+			ProductName:    "Nasi Goreng",
+			VariantNames:   []string{"Pedas"},
+			Quantity:       2,
+			UnitPrice:      testNumeric("25000"),
+			DiscountAmount: testNumeric("5000"),
+			Subtotal:       testNumeric("50000"),
+			Notes:          pgtype.Text{String: "no onion", Valid: true},
+		},
+		{
+			ID:             comboID,
+			ProductName:    "Paket Hemat",
+			Quantity:       1,
+			UnitPrice:      testNumeric("30000"),
+			DiscountAmount: testNumeric("0"),
+			Subtotal:       testNumeric("30000"),
+		},
+		{
+			ID:             uuid.New(),
+			ParentItemID:   pgtype.UUID{Bytes: comboID, Valid: true},
+			ProductName:    "Es Teh",
+			Quantity:       1,
+			UnitPrice:      testNumeric("0"),
+			DiscountAmount: testNumeric("0"),
+			Subtotal:       testNumeric("0"),
+		},
+	}
+	store.modifiers = []database.ListReceiptItemModifiersRow{
+		{OrderItemID: nasiID, Name: "Telur", Quantity: 1, UnitPrice: testNumeric("2500")},
+	}
+	store.payments = []database.Payment{
+		{
+			ID:             uuid.New(),
+			OrderID:        orderID,
+			PaymentMethod:  enum.PaymentMethodCash,
+			Amount:         testNumeric("82500"),
+			Status:         enum.PaymentStatusCompleted,
+			AmountReceived: testNumeric("100000"),
+			ChangeAmount:   testNumeric("17500"),
+		},
+		{
+			ID:            uuid.New(),
+			OrderID:       orderID,
+			PaymentMethod: enum.PaymentMethodQRIS,
+			Amount:        testNumeric("82500"),
+			Status:        enum.PaymentStatusFailed,
+		},
+	}
+	return outletID, orderID
+}
+
+func receiptPath(outletID, orderID uuid.UUID) string {
+	return "/outlets/" + outletID.String() + "/orders/" + orderID.String() + "/receipt"
+}
+
+// --- Tests ---
+
+func TestReceiptGet_Text(t *testing.T) {
+	store := newMockReceiptStore()
+	outletID, orderID := seedReceiptOrder(store)
+	store.settings = &database.OutletSetting{
+		OutletID:          outletID,
+		ReceiptFooter:     "Terima kasih!",
+		ReceiptPaperWidth: 58,
+	}
+	router := setupReceiptRouter(store)
+
+	rr := doRequest(t, router, "GET", receiptPath(outletID, orderID), nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("content type: got %q", ct)
+	}
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		"Kiwari Bandung",
+		"Jl. Braga 12",
+		"KWR-001",
+		"01/03/2026 12:30", // Asia/Jakarta
+		"Dine in - Table 7",
+		"Cashier: Sari",
+		"2x Nasi Goreng (Pedas)",
+		"+ Telur",
+		"Note: no onion",
+		"- 1x Es Teh",
+		"Tax 10% ",
+		"82.500",
+		"100.000",
+		"17.500",
+		"Terima kasih!",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("receipt missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "QRIS") {
+		t.Errorf("failed payment should not be printed:\n%s", body)
+	}
+	for _, row := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		if n := len([]rune(row)); n > 32 {
+			t.Errorf("row wider than 58mm paper (%d): %q", n, row)
+		}
+	}
+}
+
+func TestReceiptGet_ESCPOS(t *testing.T) {
+	store := newMockReceiptStore()
+	outletID, orderID := seedReceiptOrder(store)
+	router := setupReceiptRouter(store)
+
+	rr := doRequest(t, router, "GET", receiptPath(outletID, orderID)+"?format=escpos&paper=80", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("content type: got %q", ct)
+	}
+	body := rr.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte{0x1B, 0x40}) {
+		t.Errorf("expected printer init, got % x", body[:2])
+	}
+	if !bytes.HasSuffix(body, []byte{0x1D, 0x56, 0x42, 0x00}) {
+		t.Errorf("expected paper cut at end, got % x", body[len(body)-4:])
+	}
+	if !bytes.Contains(body, []byte(strings.Repeat("-", 48))) {
+		t.Error("expected 48-column separator for 80mm paper")
+	}
+}
+
+func TestReceiptGet_HTML(t *testing.T) {
+	store := newMockReceiptStore()
+	outletID, orderID := seedReceiptOrder(store)
+	store.settings = &database.OutletSetting{OutletID: outletID, ReceiptHeader: "<b>Halal</b>", ReceiptPaperWidth: 80}
+	router := setupReceiptRouter(store)
+
+	rr := doRequest(t, router, "GET", receiptPath(outletID, orderID)+"?format=html", nil)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "width: 80mm") {
+		t.Error("expected page sized to the outlet's paper width")
+	}
+	if !strings.Contains(body, "&lt;b&gt;Halal&lt;/b&gt;") {
+		t.Errorf("expected header text to be escaped:\n%s", body)
+	}
+}
+
+func TestReceiptGet_InvalidParams(t *testing.T) {
+	store := newMockReceiptStore()
+	outletID, orderID := seedReceiptOrder(store)
+	router := setupReceiptRouter(store)
+
+	for _, query := range []string{"?format=pdf", "?paper=76", "?paper=abc"} {
+		rr := doRequest(t, router, "GET", receiptPath(outletID, orderID)+query, nil)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want %d", query, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestReceiptGet_OrderNotFound(t *testing.T) {
+	store := newMockReceiptStore()
+	outletID, _ := seedReceiptOrder(store)
+	router := setupReceiptRouter(store)
+
+	rr := doRequest(t, router, "GET", receiptPath(outletID, uuid.New()), nil)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
@@ -22,6 +22,9 @@ type ReportsStore interface {
 	GetPaymentSummary(ctx context.Context, arg database.GetPaymentSummaryParams) ([]database.GetPaymentSummaryRow, error)
 	GetHourlySales(ctx context.Context, arg database.GetHourlySalesParams) ([]database.GetHourlySalesRow, error)
 	GetOutletComparison(ctx context.Context, arg database.GetOutletComparisonParams) ([]database.GetOutletComparisonRow, error)
+	GetPrepTimesByProduct(ctx context.Context, arg database.GetPrepTimesByProductParams) ([]database.GetPrepTimesByProductRow, error)
+	GetPrepTimesByStation(ctx context.Context, arg database.GetPrepTimesByStationParams) ([]database.GetPrepTimesByStationRow, error)
+	GetPromotionUsage(ctx context.Context, arg database.GetPromotionUsageParams) ([]database.GetPromotionUsageRow, error)
 }
 
 // ReportsHandler handles report endpoints.
@@ -41,6 +44,8 @@ func (h *ReportsHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/product-sales", h.ProductSales)
 	r.Get("/payment-summary", h.PaymentSummary)
 	r.Get("/hourly-sales", h.HourlySales)
+	r.Get("/prep-times", h.PrepTimes)
+	r.Get("/promotions", h.PromotionUsage)
 }
 
 // RegisterOwnerRoutes registers owner-only report endpoints.
@@ -56,6 +61,7 @@ type dailySalesResponse struct {
 	OrderCount    int64  `json:"order_count"`
 	TotalRevenue  string `json:"total_revenue"`
 	TotalDiscount string `json:"total_discount"`
+	TotalRefunds  string `json:"total_refunds"`
 	NetRevenue    string `json:"net_revenue"`
 }
 
@@ -70,6 +76,9 @@ type paymentSummaryResponse struct {
 	PaymentMethod    string `json:"payment_method"`
 	TransactionCount int64  `json:"transaction_count"`
 	TotalAmount      string `json:"total_amount"`
+	RefundCount      int64  `json:"refund_count"`
+	RefundAmount     string `json:"refund_amount"`
+	NetAmount        string `json:"net_amount"`
 }
 
 type hourlySalesResponse struct {
@@ -78,6 +87,38 @@ type hourlySalesResponse struct {
 	TotalRevenue string `json:"total_revenue"`
 }
 
+type prepTimesResponse struct {
+	Products []productPrepTimeResponse `json:"products"`
+	Stations []stationPrepTimeResponse `json:"stations"`
+}
+
+// Prep is sent to ready, cook is started to ready; both in seconds.
+type productPrepTimeResponse struct {
+	ProductID       uuid.UUID `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	PreparationTime *int32    `json:"preparation_time"` // target, minutes
+	ItemCount       int64     `json:"item_count"`
+	AvgPrepSeconds  int64     `json:"avg_prep_seconds"`
+	AvgCookSeconds  int64     `json:"avg_cook_seconds"`
+	LateCount       int64     `json:"late_count"`
+}
+
+type stationPrepTimeResponse struct {
+	Station        string `json:"station"`
+	ItemCount      int64  `json:"item_count"`
+	AvgPrepSeconds int64  `json:"avg_prep_seconds"`
+	AvgCookSeconds int64  `json:"avg_cook_seconds"`
+	LateCount      int64  `json:"late_count"`
+}
+
+type promotionUsageResponse struct {
+	PromotionID   uuid.UUID `json:"promotion_id"`
+	PromotionName string    `json:"promotion_name"`
+	Code          *string   `json:"code"`
+	OrderCount    int64     `json:"order_count"`
+	TotalDiscount string    `json:"total_discount"`
+}
+
 type outletComparisonResponse struct {
 	OutletID     uuid.UUID `json:"outlet_id"`
 	OutletName   string    `json:"outlet_name"`
@@ -123,6 +164,7 @@ func (h *ReportsHandler) DailySales(w http.ResponseWriter, r *http.Request) {
 			OrderCount:    row.OrderCount,
 			TotalRevenue:  numericToString(row.TotalRevenue),
 			TotalDiscount: numericToString(row.TotalDiscount),
+			TotalRefunds:  numericToString(row.TotalRefunds),
 			NetRevenue:    numericToString(row.NetRevenue),
 		}
 	}
@@ -211,6 +253,9 @@ func (h *ReportsHandler) PaymentSummary(w http.ResponseWriter, r *http.Request)
 			PaymentMethod:    row.PaymentMethod,
 			TransactionCount: row.TransactionCount,
 			TotalAmount:      numericToString(row.TotalAmount),
+			RefundCount:      row.RefundCount,
+			RefundAmount:     numericToString(row.RefundAmount),
+			NetAmount:        numericToString(row.NetAmount),
 		}
 	}
 
@@ -254,6 +299,117 @@ func (h *ReportsHandler) HourlySales(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// PrepTimes returns average kitchen preparation times per product and per
+// station for items sent in the date range.
+func (h *ReportsHandler) PrepTimes(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	startDate, endDate, err := parseDateRange(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	productRows, err := h.store.GetPrepTimesByProduct(r.Context(), database.GetPrepTimesByProductParams{
+		OutletID: outletID,
+		SentAt:   startDate,
+		SentAt_2: endDate,
+	})
+	if err != nil {
+		log.Printf("ERROR: get prep times by product: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	stationRows, err := h.store.GetPrepTimesByStation(r.Context(), database.GetPrepTimesByStationParams{
+		OutletID: outletID,
+		SentAt:   startDate,
+		SentAt_2: endDate,
+	})
+	if err != nil {
+		log.Printf("ERROR: get prep times by station: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := prepTimesResponse{
+		Products: make([]productPrepTimeResponse, len(productRows)),
+		Stations: make([]stationPrepTimeResponse, len(stationRows)),
+	}
+	for i, row := range productRows {
+		resp.Products[i] = productPrepTimeResponse{
+			ProductID:      row.ProductID,
+			ProductName:    row.ProductName,
+			ItemCount:      row.ItemCount,
+			AvgPrepSeconds: row.AvgPrepSeconds,
+			AvgCookSeconds: row.AvgCookSeconds,
+			LateCount:      row.LateCount,
+		}
+		if row.PreparationTime.Valid {
+			pt := row.PreparationTime.Int32
+			resp.Products[i].PreparationTime = &pt
+		}
+	}
+	for i, row := range stationRows {
+		resp.Stations[i] = stationPrepTimeResponse{
+			Station:        row.Station.String,
+			ItemCount:      row.ItemCount,
+			AvgPrepSeconds: row.AvgPrepSeconds,
+			AvgCookSeconds: row.AvgCookSeconds,
+			LateCount:      row.LateCount,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PromotionUsage returns how many orders received each promotion and the
+// discount it gave in the date range.
+func (h *ReportsHandler) PromotionUsage(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	startDate, endDate, err := parseDateRange(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.store.GetPromotionUsage(r.Context(), database.GetPromotionUsageParams{
+		OutletID:    outletID,
+		CreatedAt:   startDate,
+		CreatedAt_2: endDate,
+	})
+	if err != nil {
+		log.Printf("ERROR: get promotion usage: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]promotionUsageResponse, len(rows))
+	for i, row := range rows {
+		resp[i] = promotionUsageResponse{
+			PromotionID:   row.PromotionID,
+			PromotionName: row.PromotionName,
+			OrderCount:    row.OrderCount,
+			TotalDiscount: numericToString(row.TotalDiscount),
+		}
+		if row.Code.Valid {
+			code := row.Code.String
+			resp[i].Code = &code
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // OutletComparison returns cross-outlet comparison (owner only).
 func (h *ReportsHandler) OutletComparison(w http.ResponseWriter, r *http.Request) {
 	// Check role
@@ -30,11 +30,15 @@ type mockReportsStore struct {
 	paymentSummary    []database.GetPaymentSummaryRow
 	hourlySales       []database.GetHourlySalesRow
 	outletComparison  []database.GetOutletComparisonRow
+	prepByProduct     []database.GetPrepTimesByProductRow
+	prepByStation     []database.GetPrepTimesByStationRow
+	promotionUsage    []database.GetPromotionUsageRow
 	dailySalesErr     error
 	productSalesErr   error
 	paymentSummaryErr error
 	hourlySalesErr    error
 	outletCompErr     error
+	prepTimesErr      error
 }
 
 func (m *mockReportsStore) GetDailySales(ctx context.Context, arg database.GetDailySalesParams) ([]database.GetDailySalesRow, error) {
@@ -72,6 +76,24 @@ func (m *mockReportsStore) GetOutletComparison(ctx context.Context, arg database
 	return m.outletComparison, nil
 }
 
+func (m *mockReportsStore) GetPrepTimesByProduct(ctx context.Context, arg database.GetPrepTimesByProductParams) ([]database.GetPrepTimesByProductRow, error) {
+	if m.prepTimesErr != nil {
+		return nil, m.prepTimesErr
+	}
+	return m.prepByProduct, nil
+}
+
+func (m *mockReportsStore) GetPrepTimesByStation(ctx context.Context, arg database.GetPrepTimesByStationParams) ([]database.GetPrepTimesByStationRow, error) {
+	if m.prepTimesErr != nil {
+		return nil, m.prepTimesErr
+	}
+	return m.prepByStation, nil
+}
+
+func (m *mockReportsStore) GetPromotionUsage(ctx context.Context, arg database.GetPromotionUsageParams) ([]database.GetPromotionUsageRow, error) {
+	return m.promotionUsage, nil
+}
+
 // --- Test Helpers ---
 
 func toNumeric(s string) pgtype.Numeric {
@@ -352,6 +374,49 @@ func TestPaymentSummary(t *testing.T) {
 	}
 }
 
+func TestPaymentSummary_WithRefunds(t *testing.T) {
+	outletID := uuid.New()
+
+	store := &mockReportsStore{
+		paymentSummary: []database.GetPaymentSummaryRow{
+			{
+				PaymentMethod:    enum.PaymentMethodQRIS,
+				TransactionCount: 3,
+				TotalAmount:      toNumeric("300000.00"),
+				RefundCount:      1,
+				RefundAmount:     toNumeric("50000.00"),
+				NetAmount:        toNumeric("250000.00"),
+			},
+		},
+	}
+
+	router := setupReportsRouter(store)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/outlets/%s/reports/payment-summary?start_date=2026-02-01&end_date=2026-02-02", outletID), nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+
+	var resp []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp[0]["refund_count"] != float64(1) {
+		t.Errorf("expected refund_count 1, got %v", resp[0]["refund_count"])
+	}
+	if resp[0]["refund_amount"] != "50000.00" {
+		t.Errorf("expected refund_amount '50000.00', got %v", resp[0]["refund_amount"])
+	}
+	if resp[0]["net_amount"] != "250000.00" {
+		t.Errorf("expected net_amount '250000.00', got %v", resp[0]["net_amount"])
+	}
+}
+
 // --- Hourly Sales Tests ---
 
 func TestHourlySales(t *testing.T) {
@@ -400,6 +465,123 @@ func TestHourlySales(t *testing.T) {
 	}
 }
 
+// --- Prep Times Tests ---
+
+func TestPrepTimes(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+
+	store := &mockReportsStore{
+		prepByProduct: []database.GetPrepTimesByProductRow{
+			{
+				ProductID:       productID,
+				ProductName:     "Nasi Bakar Ayam",
+				PreparationTime: pgtype.Int4{Int32: 12, Valid: true},
+				ItemCount:       40,
+				AvgPrepSeconds:  690,
+				AvgCookSeconds:  540,
+				LateCount:       6,
+			},
+		},
+		prepByStation: []database.GetPrepTimesByStationRow{
+			{Station: pgtype.Text{String: enum.StationGrill, Valid: true}, ItemCount: 40, AvgPrepSeconds: 690, AvgCookSeconds: 540, LateCount: 6},
+			{Station: pgtype.Text{String: enum.StationBeverage, Valid: true}, ItemCount: 25, AvgPrepSeconds: 180, AvgCookSeconds: 120},
+		},
+	}
+
+	router := setupReportsRouter(store)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/outlets/%s/reports/prep-times?start_date=2026-02-01&end_date=2026-02-07", outletID), nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Products []struct {
+			ProductID       string `json:"product_id"`
+			PreparationTime *int32 `json:"preparation_time"`
+			AvgPrepSeconds  int64  `json:"avg_prep_seconds"`
+			LateCount       int64  `json:"late_count"`
+		} `json:"products"`
+		Stations []struct {
+			Station   string `json:"station"`
+			ItemCount int64  `json:"item_count"`
+		} `json:"stations"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(resp.Products) != 1 || resp.Products[0].ProductID != productID.String() {
+		t.Fatalf("products: got %+v", resp.Products)
+	}
+	if p := resp.Products[0]; p.PreparationTime == nil || *p.PreparationTime != 12 || p.AvgPrepSeconds != 690 || p.LateCount != 6 {
+		t.Errorf("product row: got %+v", p)
+	}
+	if len(resp.Stations) != 2 || resp.Stations[1].Station != enum.StationBeverage || resp.Stations[1].ItemCount != 25 {
+		t.Errorf("stations: got %+v", resp.Stations)
+	}
+}
+
+func TestPrepTimes_StoreError(t *testing.T) {
+	store := &mockReportsStore{prepTimesErr: fmt.Errorf("db down")}
+	router := setupReportsRouter(store)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/outlets/%s/reports/prep-times", uuid.New()), nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rr.Code)
+	}
+}
+
+// --- Promotion Usage Tests ---
+
+func TestPromotionUsage(t *testing.T) {
+	promotionID := uuid.New()
+	store := &mockReportsStore{
+		promotionUsage: []database.GetPromotionUsageRow{
+			{PromotionID: promotionID, PromotionName: "Happy Hour Kopi", OrderCount: 18, TotalDiscount: toNumeric("81000.00")},
+			{PromotionID: uuid.New(), PromotionName: "Ramadan", Code: pgtype.Text{String: "RAMADAN10", Valid: true}, OrderCount: 4, TotalDiscount: toNumeric("22000.00")},
+		},
+	}
+	router := setupReportsRouter(store)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/outlets/%s/reports/promotions?start_date=2026-02-01&end_date=2026-02-07", uuid.New()), nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp []struct {
+		PromotionID   string  `json:"promotion_id"`
+		Code          *string `json:"code"`
+		OrderCount    int64   `json:"order_count"`
+		TotalDiscount string  `json:"total_discount"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(resp))
+	}
+	if resp[0].PromotionID != promotionID.String() || resp[0].Code != nil || resp[0].OrderCount != 18 || resp[0].TotalDiscount != "81000.00" {
+		t.Errorf("first row: got %+v", resp[0])
+	}
+	if resp[1].Code == nil || *resp[1].Code != "RAMADAN10" {
+		t.Errorf("expected code RAMADAN10, got %v", resp[1].Code)
+	}
+}
+
 // --- Outlet Comparison Tests ---
 
 func TestOutletComparison_Success(t *testing.T) {
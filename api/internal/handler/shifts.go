@@ -0,0 +1,768 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// ShiftStore defines the database methods needed by cashier shift handlers.
+// Satisfied by *database.Queries (and its WithTx variant).
+type ShiftStore interface {
+	CreateCashierShift(ctx context.Context, arg database.CreateCashierShiftParams) (database.CashierShift, error)
+	GetCashierShift(ctx context.Context, arg database.GetCashierShiftParams) (database.CashierShift, error)
+	GetCashierShiftForUpdate(ctx context.Context, arg database.GetCashierShiftForUpdateParams) (database.CashierShift, error)
+	GetOpenCashierShift(ctx context.Context, arg database.GetOpenCashierShiftParams) (database.CashierShift, error)
+	ListCashierShifts(ctx context.Context, arg database.ListCashierShiftsParams) ([]database.CashierShift, error)
+	CloseCashierShift(ctx context.Context, arg database.CloseCashierShiftParams) (database.CashierShift, error)
+	CreateCashMovement(ctx context.Context, arg database.CreateCashMovementParams) (database.CashMovement, error)
+	ListCashMovements(ctx context.Context, shiftID uuid.UUID) ([]database.CashMovement, error)
+	GetCashierShiftPaymentSummary(ctx context.Context, id uuid.UUID) ([]database.GetCashierShiftPaymentSummaryRow, error)
+}
+
+// NewShiftStore creates a ShiftStore from a DBTX (pool or tx).
+type NewShiftStore func(db database.DBTX) ShiftStore
+
+// ShiftHandler handles cashier shifts: opening the till with a float, cash
+// put in or taken out, closing with a count, and X/Z reports.
+type ShiftHandler struct {
+	store    ShiftStore
+	pool     service.TxBeginner
+	newStore NewShiftStore
+}
+
+// NewShiftHandler creates a new ShiftHandler.
+func NewShiftHandler(store ShiftStore, pool service.TxBeginner, newStore NewShiftStore) *ShiftHandler {
+	return &ShiftHandler{store: store, pool: pool, newStore: newStore}
+}
+
+// RegisterRoutes registers shift endpoints on the given Chi router.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/shifts
+// Cashiers work with their own shifts; owners and managers with anyone's.
+func (h *ShiftHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Open)
+	r.Get("/current", h.Current)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.Get)
+		r.Post("/cash-movements", h.AddCashMovement)
+		r.Post("/close", h.Close)
+		r.Get("/x-report", h.XReport)
+		r.Get("/z-report", h.ZReport)
+	})
+}
+
+// --- Request / Response types ---
+
+type openShiftRequest struct {
+	OpeningFloat string `json:"opening_float"`
+}
+
+type cashMovementRequest struct {
+	MovementType string `json:"movement_type"`
+	Amount       string `json:"amount"`
+	Reason       string `json:"reason"`
+}
+
+type closeShiftRequest struct {
+	CountedCash string `json:"counted_cash"`
+	Notes       string `json:"notes"`
+}
+
+type shiftResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	OutletID     uuid.UUID  `json:"outlet_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Status       string     `json:"status"`
+	OpeningFloat string     `json:"opening_float"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	ClosedBy     *uuid.UUID `json:"closed_by"`
+	CountedCash  *string    `json:"counted_cash"`
+	ExpectedCash *string    `json:"expected_cash"`
+	Variance     *string    `json:"variance"`
+	ClosingNotes *string    `json:"closing_notes"`
+}
+
+type shiftDetailResponse struct {
+	shiftResponse
+	CashMovements []cashMovementResponse `json:"cash_movements"`
+}
+
+type cashMovementResponse struct {
+	ID           uuid.UUID `json:"id"`
+	MovementType string    `json:"movement_type"`
+	Amount       string    `json:"amount"`
+	Reason       string    `json:"reason"`
+	CreatedBy    uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type shiftPaymentMethodResponse struct {
+	PaymentMethod    string `json:"payment_method"`
+	TransactionCount int64  `json:"transaction_count"`
+	TotalAmount      string `json:"total_amount"`
+	RefundCount      int64  `json:"refund_count"`
+	RefundAmount     string `json:"refund_amount"`
+	NetAmount        string `json:"net_amount"`
+}
+
+// An X report reads a shift without closing it; the Z report is the final
+// one, with the drawer count and variance.
+type shiftReportResponse struct {
+	ReportType   string                       `json:"report_type"`
+	GeneratedAt  time.Time                    `json:"generated_at"`
+	Shift        shiftResponse                `json:"shift"`
+	Payments     []shiftPaymentMethodResponse `json:"payments"`
+	TotalSales   string                       `json:"total_sales"`
+	TotalRefunds string                       `json:"total_refunds"`
+	NetSales     string                       `json:"net_sales"`
+	OpeningFloat string                       `json:"opening_float"`
+	CashSales    string                       `json:"cash_sales"`
+	CashRefunds  string                       `json:"cash_refunds"`
+	CashIn       string                       `json:"cash_in"`
+	CashOut      string                       `json:"cash_out"`
+	ExpectedCash string                       `json:"expected_cash"`
+	CountedCash  *string                      `json:"counted_cash"`
+	Variance     *string                      `json:"variance"`
+}
+
+// --- Handlers ---
+
+// List returns the outlet's shifts, newest first. Cashiers only see their
+// own; managers can filter by ?user_id. ?status filters by OPEN or CLOSED.
+func (h *ShiftHandler) List(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	// Parse pagination
+	limit := 20
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	offset := 0
+	if s := r.URL.Query().Get("offset"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+
+	var status pgtype.Text
+	if s := r.URL.Query().Get("status"); s != "" {
+		if s != enum.ShiftStatusOpen && s != enum.ShiftStatusClosed {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be OPEN or CLOSED"})
+			return
+		}
+		status = pgtype.Text{String: s, Valid: true}
+	}
+
+	userID := pgtype.UUID{Bytes: claims.UserID, Valid: true}
+	if isManagerRole(claims.Role) {
+		userID = pgtype.UUID{}
+		if s := r.URL.Query().Get("user_id"); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user_id"})
+				return
+			}
+			userID = pgtype.UUID{Bytes: id, Valid: true}
+		}
+	}
+
+	shifts, err := h.store.ListCashierShifts(r.Context(), database.ListCashierShiftsParams{
+		OutletID: outletID,
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+		UserID:   userID,
+		Status:   status,
+	})
+	if err != nil {
+		log.Printf("ERROR: list cashier shifts: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]shiftResponse, len(shifts))
+	for i, s := range shifts {
+		if resp[i], err = toShiftResponse(s); err != nil {
+			log.Printf("ERROR: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Open starts a shift for the authenticated user with the float counted
+// into the drawer. A user has at most one open shift per outlet.
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	var req openShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	openingFloat, err := decimal.NewFromString(req.OpeningFloat)
+	if err != nil || openingFloat.IsNegative() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "opening_float must be zero or more"})
+		return
+	}
+
+	shift, err := h.store.CreateCashierShift(r.Context(), database.CreateCashierShiftParams{
+		OutletID:     outletID,
+		UserID:       claims.UserID,
+		OpeningFloat: decimalToNumeric(openingFloat),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "you already have an open shift"})
+			return
+		}
+		log.Printf("ERROR: create cashier shift: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp, err := toShiftResponse(shift)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// Current returns the authenticated user's open shift with its cash movements.
+func (h *ShiftHandler) Current(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	shift, err := h.store.GetOpenCashierShift(r.Context(), database.GetOpenCashierShiftParams{
+		OutletID: outletID,
+		UserID:   claims.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no open shift"})
+			return
+		}
+		log.Printf("ERROR: get open cashier shift: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	h.writeShiftDetail(w, r, shift)
+}
+
+// Get returns a shift with its cash movements.
+func (h *ShiftHandler) Get(w http.ResponseWriter, r *http.Request) {
+	shift, ok := h.loadShift(w, r)
+	if !ok {
+		return
+	}
+	h.writeShiftDetail(w, r, shift)
+}
+
+// AddCashMovement records cash put into (CASH_IN) or taken out of
+// (CASH_OUT) the drawer of an open shift.
+func (h *ShiftHandler) AddCashMovement(w http.ResponseWriter, r *http.Request) {
+	outletID, shiftID, claims, ok := parseShiftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req cashMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.MovementType != enum.CashMovementIn && req.MovementType != enum.CashMovementOut {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "movement_type must be CASH_IN or CASH_OUT"})
+		return
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "amount must be positive"})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reason is required"})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	txStore := h.newStore(tx)
+
+	// Lock the shift so cash can't be recorded against one being closed
+	shift, ok := lockOpenShift(w, r, txStore, shiftID, outletID, claims)
+	if !ok {
+		return
+	}
+
+	movement, err := txStore.CreateCashMovement(r.Context(), database.CreateCashMovementParams{
+		ShiftID:      shift.ID,
+		MovementType: req.MovementType,
+		Amount:       decimalToNumeric(amount),
+		Reason:       reason,
+		CreatedBy:    claims.UserID,
+	})
+	if err != nil {
+		log.Printf("ERROR: create cash movement: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toCashMovementResponse(movement))
+}
+
+// Close ends a shift with the cash counted in the drawer. Expected cash is
+// the opening float plus the cashier's cash payments, less the cash they
+// refunded, plus cash in and less cash out; the response is the Z report.
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request) {
+	outletID, shiftID, claims, ok := parseShiftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req closeShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	counted, err := decimal.NewFromString(req.CountedCash)
+	if err != nil || counted.IsNegative() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "counted_cash must be zero or more"})
+		return
+	}
+	var notes pgtype.Text
+	if s := strings.TrimSpace(req.Notes); s != "" {
+		notes = pgtype.Text{String: s, Valid: true}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	txStore := h.newStore(tx)
+
+	shift, ok := lockOpenShift(w, r, txStore, shiftID, outletID, claims)
+	if !ok {
+		return
+	}
+
+	// Read in the same transaction that closes the shift: now() is the
+	// transaction start for both, so the report covers exactly the shift.
+	totals, err := loadShiftTotals(r.Context(), txStore, shift.ID)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	openingFloat, err := numericToDecimal(shift.OpeningFloat)
+	if err != nil {
+		log.Printf("ERROR: parse opening float: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	closed, err := txStore.CloseCashierShift(r.Context(), database.CloseCashierShiftParams{
+		ID:           shift.ID,
+		ClosedBy:     pgtype.UUID{Bytes: claims.UserID, Valid: true},
+		CountedCash:  decimalToNumeric(counted),
+		ExpectedCash: decimalToNumeric(totals.expectedCash(openingFloat)),
+		ClosingNotes: notes,
+	})
+	if err != nil {
+		log.Printf("ERROR: close cashier shift: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	report, err := buildShiftReport("Z", closed, totals)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// XReport returns a shift's takings so far by payment method, without
+// closing it.
+func (h *ShiftHandler) XReport(w http.ResponseWriter, r *http.Request) {
+	h.writeReport(w, r, "X")
+}
+
+// ZReport returns the final report of a closed shift, with the counted cash
+// and variance.
+func (h *ShiftHandler) ZReport(w http.ResponseWriter, r *http.Request) {
+	h.writeReport(w, r, "Z")
+}
+
+func (h *ShiftHandler) writeReport(w http.ResponseWriter, r *http.Request, reportType string) {
+	shift, ok := h.loadShift(w, r)
+	if !ok {
+		return
+	}
+	if reportType == "Z" && shift.Status != enum.ShiftStatusClosed {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "shift is still open"})
+		return
+	}
+
+	totals, err := loadShiftTotals(r.Context(), h.store, shift.ID)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	report, err := buildShiftReport(reportType, shift, totals)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// --- Helpers ---
+
+// parseShiftRequest reads the outlet and shift IDs from the URL and the
+// caller's claims, writing an error response when any is missing.
+func parseShiftRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, *auth.Claims, bool) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return uuid.Nil, uuid.Nil, nil, false
+	}
+	shiftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid shift ID"})
+		return uuid.Nil, uuid.Nil, nil, false
+	}
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return uuid.Nil, uuid.Nil, nil, false
+	}
+	return outletID, shiftID, claims, true
+}
+
+// loadShift fetches the shift in the URL if the caller may see it.
+func (h *ShiftHandler) loadShift(w http.ResponseWriter, r *http.Request) (database.CashierShift, bool) {
+	outletID, shiftID, claims, ok := parseShiftRequest(w, r)
+	if !ok {
+		return database.CashierShift{}, false
+	}
+
+	shift, err := h.store.GetCashierShift(r.Context(), database.GetCashierShiftParams{
+		ID:       shiftID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "shift not found"})
+			return database.CashierShift{}, false
+		}
+		log.Printf("ERROR: get cashier shift: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.CashierShift{}, false
+	}
+	if !canAccessShift(claims, shift) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "shift belongs to another cashier"})
+		return database.CashierShift{}, false
+	}
+	return shift, true
+}
+
+// lockOpenShift locks the shift for the rest of the transaction, writing an
+// error response unless it is open and the caller may work with it.
+func lockOpenShift(w http.ResponseWriter, r *http.Request, store ShiftStore, shiftID, outletID uuid.UUID, claims *auth.Claims) (database.CashierShift, bool) {
+	shift, err := store.GetCashierShiftForUpdate(r.Context(), database.GetCashierShiftForUpdateParams{
+		ID:       shiftID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "shift not found"})
+			return database.CashierShift{}, false
+		}
+		log.Printf("ERROR: lock cashier shift: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.CashierShift{}, false
+	}
+	if !canAccessShift(claims, shift) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "shift belongs to another cashier"})
+		return database.CashierShift{}, false
+	}
+	if shift.Status != enum.ShiftStatusOpen {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "shift is closed"})
+		return database.CashierShift{}, false
+	}
+	return shift, true
+}
+
+// canAccessShift reports whether the caller may see or work with a shift:
+// their own, or anyone's for owners and managers.
+func canAccessShift(claims *auth.Claims, shift database.CashierShift) bool {
+	return shift.UserID == claims.UserID || isManagerRole(claims.Role)
+}
+
+func (h *ShiftHandler) writeShiftDetail(w http.ResponseWriter, r *http.Request, shift database.CashierShift) {
+	movements, err := h.store.ListCashMovements(r.Context(), shift.ID)
+	if err != nil {
+		log.Printf("ERROR: list cash movements: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	shiftResp, err := toShiftResponse(shift)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := shiftDetailResponse{
+		shiftResponse: shiftResp,
+		CashMovements: make([]cashMovementResponse, len(movements)),
+	}
+	for i, m := range movements {
+		resp.CashMovements[i] = toCashMovementResponse(m)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// loadShiftTotals sums the shift's payments, refunds and cash movements.
+func loadShiftTotals(ctx context.Context, store ShiftStore, shiftID uuid.UUID) (shiftTotals, error) {
+	payments, err := store.GetCashierShiftPaymentSummary(ctx, shiftID)
+	if err != nil {
+		return shiftTotals{}, fmt.Errorf("shift payment summary: %w", err)
+	}
+	movements, err := store.ListCashMovements(ctx, shiftID)
+	if err != nil {
+		return shiftTotals{}, fmt.Errorf("list cash movements: %w", err)
+	}
+	return sumShiftActivity(payments, movements)
+}
+
+// shiftTotals is a shift's takings by payment method and the cash that
+// went through its drawer.
+type shiftTotals struct {
+	methods                []shiftPaymentMethodResponse
+	sales, refunds         decimal.Decimal
+	cashSales, cashRefunds decimal.Decimal
+	cashIn, cashOut        decimal.Decimal
+}
+
+func sumShiftActivity(payments []database.GetCashierShiftPaymentSummaryRow, movements []database.CashMovement) (shiftTotals, error) {
+	t := shiftTotals{methods: make([]shiftPaymentMethodResponse, len(payments))}
+	for i, p := range payments {
+		total, err := numericToDecimal(p.TotalAmount)
+		if err != nil {
+			return shiftTotals{}, fmt.Errorf("parse %s total: %w", p.PaymentMethod, err)
+		}
+		refunded, err := numericToDecimal(p.RefundAmount)
+		if err != nil {
+			return shiftTotals{}, fmt.Errorf("parse %s refunds: %w", p.PaymentMethod, err)
+		}
+		t.methods[i] = shiftPaymentMethodResponse{
+			PaymentMethod:    p.PaymentMethod,
+			TransactionCount: p.TransactionCount,
+			TotalAmount:      total.StringFixed(2),
+			RefundCount:      p.RefundCount,
+			RefundAmount:     refunded.StringFixed(2),
+			NetAmount:        total.Sub(refunded).StringFixed(2),
+		}
+		t.sales = t.sales.Add(total)
+		t.refunds = t.refunds.Add(refunded)
+		if p.PaymentMethod == enum.PaymentMethodCash {
+			t.cashSales = t.cashSales.Add(total)
+			t.cashRefunds = t.cashRefunds.Add(refunded)
+		}
+	}
+	for _, m := range movements {
+		amount, err := numericToDecimal(m.Amount)
+		if err != nil {
+			return shiftTotals{}, fmt.Errorf("parse cash movement %s amount: %w", m.ID, err)
+		}
+		if m.MovementType == enum.CashMovementIn {
+			t.cashIn = t.cashIn.Add(amount)
+		} else {
+			t.cashOut = t.cashOut.Add(amount)
+		}
+	}
+	return t, nil
+}
+
+// expectedCash is what the drawer should hold: the opening float plus cash
+// taken, less cash refunded, plus cash put in and less cash taken out.
+func (t shiftTotals) expectedCash(openingFloat decimal.Decimal) decimal.Decimal {
+	return openingFloat.Add(t.cashSales).Sub(t.cashRefunds).Add(t.cashIn).Sub(t.cashOut)
+}
+
+// buildShiftReport lays out an X or Z report. A closed shift reports the
+// expected cash fixed when it was closed.
+func buildShiftReport(reportType string, shift database.CashierShift, t shiftTotals) (shiftReportResponse, error) {
+	shiftResp, err := toShiftResponse(shift)
+	if err != nil {
+		return shiftReportResponse{}, err
+	}
+	openingFloat, err := numericToDecimal(shift.OpeningFloat)
+	if err != nil {
+		return shiftReportResponse{}, fmt.Errorf("parse opening float: %w", err)
+	}
+	expected := t.expectedCash(openingFloat)
+	if shift.ExpectedCash.Valid {
+		if expected, err = numericToDecimal(shift.ExpectedCash); err != nil {
+			return shiftReportResponse{}, fmt.Errorf("parse expected cash: %w", err)
+		}
+	}
+
+	resp := shiftReportResponse{
+		ReportType:   reportType,
+		GeneratedAt:  time.Now(),
+		Shift:        shiftResp,
+		Payments:     t.methods,
+		TotalSales:   t.sales.StringFixed(2),
+		TotalRefunds: t.refunds.StringFixed(2),
+		NetSales:     t.sales.Sub(t.refunds).StringFixed(2),
+		OpeningFloat: openingFloat.StringFixed(2),
+		CashSales:    t.cashSales.StringFixed(2),
+		CashRefunds:  t.cashRefunds.StringFixed(2),
+		CashIn:       t.cashIn.StringFixed(2),
+		CashOut:      t.cashOut.StringFixed(2),
+		ExpectedCash: expected.StringFixed(2),
+	}
+	if reportType == "Z" {
+		resp.CountedCash = resp.Shift.CountedCash
+		resp.Variance = resp.Shift.Variance
+	}
+	return resp, nil
+}
+
+func toShiftResponse(s database.CashierShift) (shiftResponse, error) {
+	resp := shiftResponse{
+		ID:           s.ID,
+		OutletID:     s.OutletID,
+		UserID:       s.UserID,
+		Status:       s.Status,
+		OpeningFloat: numericToString(s.OpeningFloat),
+		OpenedAt:     s.OpenedAt,
+	}
+	if s.ClosedAt.Valid {
+		resp.ClosedAt = &s.ClosedAt.Time
+	}
+	if s.ClosedBy.Valid {
+		id := uuid.UUID(s.ClosedBy.Bytes)
+		resp.ClosedBy = &id
+	}
+	if s.CountedCash.Valid && s.ExpectedCash.Valid {
+		counted, err := numericToDecimal(s.CountedCash)
+		if err != nil {
+			return shiftResponse{}, fmt.Errorf("parse shift %s counted cash: %w", s.ID, err)
+		}
+		expected, err := numericToDecimal(s.ExpectedCash)
+		if err != nil {
+			return shiftResponse{}, fmt.Errorf("parse shift %s expected cash: %w", s.ID, err)
+		}
+		countedStr := counted.StringFixed(2)
+		expectedStr := expected.StringFixed(2)
+		variance := counted.Sub(expected).StringFixed(2)
+		resp.CountedCash = &countedStr
+		resp.ExpectedCash = &expectedStr
+		resp.Variance = &variance
+	}
+	if s.ClosingNotes.Valid {
+		resp.ClosingNotes = &s.ClosingNotes.String
+	}
+	return resp, nil
+}
+
+func toCashMovementResponse(m database.CashMovement) cashMovementResponse {
+	return cashMovementResponse{
+		ID:           m.ID,
+		MovementType: m.MovementType,
+		Amount:       numericToString(m.Amount),
+		Reason:       m.Reason,
+		CreatedBy:    m.CreatedBy,
+		CreatedAt:    m.CreatedAt,
+	}
+}
@@ -0,0 +1,353 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/middleware"
+)
+
+// --- Mock store ---
+
+type mockShiftStore struct {
+	shifts    map[uuid.UUID]database.CashierShift
+	movements []database.CashMovement
+	payments  map[uuid.UUID][]database.GetCashierShiftPaymentSummaryRow // keyed by shift ID
+}
+
+func newMockShiftStore() *mockShiftStore {
+	return &mockShiftStore{
+		shifts:   make(map[uuid.UUID]database.CashierShift),
+		payments: make(map[uuid.UUID][]database.GetCashierShiftPaymentSummaryRow),
+	}
+}
+
+func (m *mockShiftStore) CreateCashierShift(_ context.Context, arg database.CreateCashierShiftParams) (database.CashierShift, error) {
+	for _, s := range m.shifts {
+		if s.OutletID == arg.OutletID && s.UserID == arg.UserID && s.Status == enum.ShiftStatusOpen {
+			return database.CashierShift{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	s := database.CashierShift{
+		ID:           uuid.New(),
+		OutletID:     arg.OutletID,
+		UserID:       arg.UserID,
+		Status:       enum.ShiftStatusOpen,
+		OpeningFloat: arg.OpeningFloat,
+		OpenedAt:     time.Now(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	m.shifts[s.ID] = s
+	return s, nil
+}
+
+func (m *mockShiftStore) GetCashierShift(_ context.Context, arg database.GetCashierShiftParams) (database.CashierShift, error) {
+	s, ok := m.shifts[arg.ID]
+	if !ok || s.OutletID != arg.OutletID {
+		return database.CashierShift{}, pgx.ErrNoRows
+	}
+	return s, nil
+}
+
+func (m *mockShiftStore) GetCashierShiftForUpdate(ctx context.Context, arg database.GetCashierShiftForUpdateParams) (database.CashierShift, error) {
+	return m.GetCashierShift(ctx, database.GetCashierShiftParams{ID: arg.ID, OutletID: arg.OutletID})
+}
+
+func (m *mockShiftStore) GetOpenCashierShift(_ context.Context, arg database.GetOpenCashierShiftParams) (database.CashierShift, error) {
+	for _, s := range m.shifts {
+		if s.OutletID == arg.OutletID && s.UserID == arg.UserID && s.Status == enum.ShiftStatusOpen {
+			return s, nil
+		}
+	}
+	return database.CashierShift{}, pgx.ErrNoRows
+}
+
+func (m *mockShiftStore) ListCashierShifts(_ context.Context, arg database.ListCashierShiftsParams) ([]database.CashierShift, error) {
+	result := []database.CashierShift{}
+	for _, s := range m.shifts {
+		if s.OutletID != arg.OutletID {
+			continue
+		}
+		if arg.UserID.Valid && s.UserID != arg.UserID.Bytes {
+			continue
+		}
+		if arg.Status.Valid && s.Status != arg.Status.String {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+func (m *mockShiftStore) CloseCashierShift(_ context.Context, arg database.CloseCashierShiftParams) (database.CashierShift, error) {
+	s, ok := m.shifts[arg.ID]
+	if !ok || s.Status != enum.ShiftStatusOpen {
+		return database.CashierShift{}, pgx.ErrNoRows
+	}
+	s.Status = enum.ShiftStatusClosed
+	s.ClosedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	s.ClosedBy = arg.ClosedBy
+	s.CountedCash = arg.CountedCash
+	s.ExpectedCash = arg.ExpectedCash
+	s.ClosingNotes = arg.ClosingNotes
+	m.shifts[s.ID] = s
+	return s, nil
+}
+
+func (m *mockShiftStore) CreateCashMovement(_ context.Context, arg database.CreateCashMovementParams) (database.CashMovement, error) {
+	mv := database.CashMovement{
+		ID:           uuid.New(),
+		ShiftID:      arg.ShiftID,
+		MovementType: arg.MovementType,
+		Amount:       arg.Amount,
+		Reason:       arg.Reason,
+		CreatedBy:    arg.CreatedBy,
+		CreatedAt:    time.Now(),
+	}
+	m.movements = append(m.movements, mv)
+	return mv, nil
+}
+
+func (m *mockShiftStore) ListCashMovements(_ context.Context, shiftID uuid.UUID) ([]database.CashMovement, error) {
+	result := []database.CashMovement{}
+	for _, mv := range m.movements {
+		if mv.ShiftID == shiftID {
+			result = append(result, mv)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockShiftStore) GetCashierShiftPaymentSummary(_ context.Context, id uuid.UUID) ([]database.GetCashierShiftPaymentSummaryRow, error) {
+	return m.payments[id], nil
+}
+
+// --- Helpers ---
+
+func setupShiftRouter(store *mockShiftStore) *chi.Mux {
+	newStore := func(db database.DBTX) handler.ShiftStore {
+		return store
+	}
+	h := handler.NewShiftHandler(store, &mockPool{}, newStore)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/shifts", h.RegisterRoutes)
+	return r
+}
+
+func decodeShiftResponse(t *testing.T, rr *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+// --- Tests ---
+
+func TestShiftOpen(t *testing.T) {
+	store := newMockShiftStore()
+	router := setupShiftRouter(store)
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	path := "/outlets/" + outletID.String() + "/shifts"
+
+	rr := doAuthRequest(t, router, http.MethodPost, path, map[string]interface{}{"opening_float": "500000"}, claims)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	resp := decodeShiftResponse(t, rr)
+	if resp["status"] != "OPEN" || resp["opening_float"] != "500000.00" {
+		t.Errorf("got status %v, opening_float %v", resp["status"], resp["opening_float"])
+	}
+	if resp["user_id"] != claims.UserID.String() {
+		t.Errorf("user_id: got %v, want %s", resp["user_id"], claims.UserID)
+	}
+
+	// One open shift per cashier
+	rr = doAuthRequest(t, router, http.MethodPost, path, map[string]interface{}{"opening_float": "100000"}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("second open: expected status 409, got %d", rr.Code)
+	}
+
+	rr = doAuthRequest(t, router, http.MethodGet, path+"/current", nil, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("current: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = doAuthRequest(t, router, http.MethodGet, path+"/current", nil, testClaims(outletID))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("current without a shift: expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestShiftOpen_InvalidFloat(t *testing.T) {
+	router := setupShiftRouter(newMockShiftStore())
+	outletID := uuid.New()
+
+	for _, float := range []string{"", "abc", "-1000"} {
+		rr := doAuthRequest(t, router, http.MethodPost, "/outlets/"+outletID.String()+"/shifts", map[string]interface{}{"opening_float": float}, testClaims(outletID))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("opening_float %q: expected status 400, got %d", float, rr.Code)
+		}
+	}
+}
+
+func TestShiftClose_ReconcilesCash(t *testing.T) {
+	store := newMockShiftStore()
+	router := setupShiftRouter(store)
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	path := "/outlets/" + outletID.String() + "/shifts"
+
+	rr := doAuthRequest(t, router, http.MethodPost, path, map[string]interface{}{"opening_float": "500000"}, claims)
+	shiftPath := path + "/" + decodeShiftResponse(t, rr)["id"].(string)
+
+	for _, mv := range []map[string]interface{}{
+		{"movement_type": "CASH_IN", "amount": "100000", "reason": "Change top-up"},
+		{"movement_type": "CASH_OUT", "amount": "50000", "reason": "Ice and gas"},
+	} {
+		rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/cash-movements", mv, claims)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("cash movement: expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	for id := range store.shifts {
+		store.payments[id] = []database.GetCashierShiftPaymentSummaryRow{
+			{PaymentMethod: "CASH", TransactionCount: 6, TotalAmount: testNumeric("300000"), RefundCount: 1, RefundAmount: testNumeric("20000")},
+			{PaymentMethod: "QRIS", TransactionCount: 3, TotalAmount: testNumeric("150000"), RefundAmount: testNumeric("0")},
+		}
+	}
+
+	rr = doAuthRequest(t, router, http.MethodGet, shiftPath+"/x-report", nil, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("x-report: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if x := decodeShiftResponse(t, rr); x["expected_cash"] != "830000.00" || x["variance"] != nil {
+		t.Errorf("x-report: got expected_cash %v, variance %v", x["expected_cash"], x["variance"])
+	}
+
+	rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/close", map[string]interface{}{"counted_cash": "820000", "notes": "Short one 10k note"}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("close: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	z := decodeShiftResponse(t, rr)
+	// 500000 float + 300000 cash - 20000 refunded + 100000 in - 50000 out
+	if z["report_type"] != "Z" || z["expected_cash"] != "830000.00" || z["counted_cash"] != "820000.00" || z["variance"] != "-10000.00" {
+		t.Errorf("z report: got %v expected %v counted %v variance %v", z["report_type"], z["expected_cash"], z["counted_cash"], z["variance"])
+	}
+	if z["total_sales"] != "450000.00" || z["net_sales"] != "430000.00" {
+		t.Errorf("z report: got total_sales %v, net_sales %v", z["total_sales"], z["net_sales"])
+	}
+	payments := z["payments"].([]interface{})
+	if len(payments) != 2 || payments[0].(map[string]interface{})["net_amount"] != "280000.00" {
+		t.Errorf("payments by method: got %v", payments)
+	}
+
+	rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/cash-movements", map[string]interface{}{"movement_type": "CASH_OUT", "amount": "1000", "reason": "late"}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("movement on closed shift: expected status 409, got %d", rr.Code)
+	}
+	rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/close", map[string]interface{}{"counted_cash": "820000"}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("closing twice: expected status 409, got %d", rr.Code)
+	}
+	rr = doAuthRequest(t, router, http.MethodGet, shiftPath+"/z-report", nil, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("z-report: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := decodeShiftResponse(t, rr); got["variance"] != "-10000.00" {
+		t.Errorf("z-report variance: got %v", got["variance"])
+	}
+}
+
+func TestShiftCashMovement_Invalid(t *testing.T) {
+	store := newMockShiftStore()
+	router := setupShiftRouter(store)
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	rr := doAuthRequest(t, router, http.MethodPost, "/outlets/"+outletID.String()+"/shifts", map[string]interface{}{"opening_float": "0"}, claims)
+	shiftPath := "/outlets/" + outletID.String() + "/shifts/" + decodeShiftResponse(t, rr)["id"].(string)
+
+	for _, body := range []map[string]interface{}{
+		{"movement_type": "REFUND", "amount": "1000", "reason": "x"},
+		{"movement_type": "CASH_IN", "amount": "0", "reason": "x"},
+		{"movement_type": "CASH_IN", "amount": "1000", "reason": "  "},
+	} {
+		rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/cash-movements", body, claims)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+func TestShiftZReport_OpenShift(t *testing.T) {
+	store := newMockShiftStore()
+	router := setupShiftRouter(store)
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+
+	rr := doAuthRequest(t, router, http.MethodPost, "/outlets/"+outletID.String()+"/shifts", map[string]interface{}{"opening_float": "0"}, claims)
+	shiftPath := "/outlets/" + outletID.String() + "/shifts/" + decodeShiftResponse(t, rr)["id"].(string)
+
+	rr = doAuthRequest(t, router, http.MethodGet, shiftPath+"/z-report", nil, claims)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+}
+
+func TestShiftAccess(t *testing.T) {
+	store := newMockShiftStore()
+	router := setupShiftRouter(store)
+	outletID := uuid.New()
+	owner := testClaims(outletID)
+	path := "/outlets/" + outletID.String() + "/shifts"
+
+	rr := doAuthRequest(t, router, http.MethodPost, path, map[string]interface{}{"opening_float": "0"}, owner)
+	shiftPath := path + "/" + decodeShiftResponse(t, rr)["id"].(string)
+
+	other := testClaims(outletID)
+	rr = doAuthRequest(t, router, http.MethodGet, shiftPath, nil, other)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("other cashier get: expected status 403, got %d", rr.Code)
+	}
+	rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/close", map[string]interface{}{"counted_cash": "0"}, other)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("other cashier close: expected status 403, got %d", rr.Code)
+	}
+
+	// Other cashiers' shifts are left out of their list
+	rr = doAuthRequest(t, router, http.MethodGet, path, nil, other)
+	var list []map[string]interface{}
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list) != 0 {
+		t.Errorf("other cashier list: expected 0 shifts, got %d", len(list))
+	}
+
+	manager := &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "MANAGER"}
+	rr = doAuthRequest(t, router, http.MethodGet, path, nil, manager)
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list) != 1 {
+		t.Errorf("manager list: expected 1 shift, got %d", len(list))
+	}
+	rr = doAuthRequest(t, router, http.MethodPost, shiftPath+"/close", map[string]interface{}{"counted_cash": "0"}, manager)
+	if rr.Code != http.StatusOK {
+		t.Errorf("manager close: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,749 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/kiwari-pos/api/internal/service"
+	"github.com/kiwari-pos/api/internal/ws"
+	"github.com/shopspring/decimal"
+)
+
+// TableStore defines the database methods needed by table (floor plan) handlers.
+// Satisfied by *database.Queries (and its WithTx variant).
+type TableStore interface {
+	ListDiningTablesWithOrders(ctx context.Context, outletID uuid.UUID) ([]database.ListDiningTablesWithOrdersRow, error)
+	GetDiningTable(ctx context.Context, arg database.GetDiningTableParams) (database.DiningTable, error)
+	CreateDiningTable(ctx context.Context, arg database.CreateDiningTableParams) (database.DiningTable, error)
+	UpdateDiningTable(ctx context.Context, arg database.UpdateDiningTableParams) (database.DiningTable, error)
+	RequestDiningTableBill(ctx context.Context, arg database.RequestDiningTableBillParams) (database.DiningTable, error)
+	SoftDeleteDiningTable(ctx context.Context, arg database.SoftDeleteDiningTableParams) (uuid.UUID, error)
+	// Orders seated at a table
+	ListActiveOrdersByTable(ctx context.Context, arg database.ListActiveOrdersByTableParams) ([]database.Order, error)
+	GetOrderForUpdate(ctx context.Context, arg database.GetOrderForUpdateParams) (database.Order, error)
+	UpdateOrderTable(ctx context.Context, arg database.UpdateOrderTableParams) (database.Order, error)
+	MoveOrderItems(ctx context.Context, arg database.MoveOrderItemsParams) error
+	MoveKitchenTickets(ctx context.Context, arg database.MoveKitchenTicketsParams) error
+	CancelOrder(ctx context.Context, arg database.CancelOrderParams) (database.Order, error)
+	CountOrderChecks(ctx context.Context, orderID uuid.UUID) (int64, error)
+	CountPaymentsByOrder(ctx context.Context, orderID uuid.UUID) (int64, error)
+	UpdateOrderTotals(ctx context.Context, orderID uuid.UUID) (database.Order, error)
+	UpdateOrderCharges(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error)
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error
+	// Promotion of the merged bill
+	LockOrderPromotions(ctx context.Context, arg database.LockOrderPromotionsParams) error
+	ListOrderPromotions(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error)
+	ListPromotionItems(ctx context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error)
+	UpdateOrderPromotion(ctx context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error)
+	// Order detail for responses and events
+	orderDetailStore
+}
+
+// NewTableStore creates a TableStore from a DBTX (pool or tx).
+type NewTableStore func(db database.DBTX) TableStore
+
+// TableHandler handles the floor plan: dining tables, their live status, and
+// moving or merging the orders seated at them.
+type TableHandler struct {
+	store    TableStore
+	pool     service.TxBeginner
+	newStore NewTableStore
+	hub      Broadcaster
+}
+
+// NewTableHandler creates a new TableHandler.
+func NewTableHandler(store TableStore, pool service.TxBeginner, newStore NewTableStore, hub Broadcaster) *TableHandler {
+	return &TableHandler{store: store, pool: pool, newStore: newStore, hub: hub}
+}
+
+// RegisterRoutes registers table endpoints on the given Chi router.
+// Expected to be mounted inside an outlet-scoped subrouter: /outlets/{oid}/tables
+func (h *TableHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+	r.Post("/{id}/bill-request", h.RequestBill)
+	r.Post("/{id}/transfer", h.Transfer)
+	r.Post("/{id}/merge", h.Merge)
+}
+
+// --- Request / Response types ---
+
+type tableRequest struct {
+	Area      string `json:"area"`
+	Code      string `json:"code"`
+	Seats     int32  `json:"seats"`
+	SortOrder int32  `json:"sort_order"`
+}
+
+type transferTableRequest struct {
+	ToTableID string `json:"to_table_id"`
+	OrderID   string `json:"order_id"` // optional; empty moves every active order
+}
+
+type mergeTableRequest struct {
+	FromTableID string `json:"from_table_id"`
+}
+
+type tableResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	Area            string     `json:"area"`
+	Code            string     `json:"code"`
+	Seats           int32      `json:"seats"`
+	SortOrder       int32      `json:"sort_order"`
+	Status          string     `json:"status"`
+	ActiveOrders    int        `json:"active_orders"`
+	ActiveTotal     string     `json:"active_total"`
+	OccupiedSince   *time.Time `json:"occupied_since"`
+	BillRequestedAt *time.Time `json:"bill_requested_at"`
+}
+
+type floorAreaResponse struct {
+	Area   string          `json:"area"`
+	Tables []tableResponse `json:"tables"`
+}
+
+// toTableResponse builds a table's response from the active orders seated at it.
+func toTableResponse(t database.DiningTable, orders []database.Order) tableResponse {
+	total := decimal.Zero
+	var occupiedSince time.Time
+	for i, o := range orders {
+		amount, _ := numericToDecimal(o.TotalAmount)
+		total = total.Add(amount)
+		if i == 0 || o.CreatedAt.Before(occupiedSince) {
+			occupiedSince = o.CreatedAt
+		}
+	}
+
+	resp := tableResponse{
+		ID:           t.ID,
+		Area:         t.Area,
+		Code:         t.Code,
+		Seats:        t.Seats,
+		SortOrder:    t.SortOrder,
+		Status:       service.TableStatus(len(orders), occupiedSince, t.BillRequestedAt),
+		ActiveOrders: len(orders),
+		ActiveTotal:  total.StringFixed(2),
+	}
+	if len(orders) > 0 {
+		resp.OccupiedSince = &occupiedSince
+	}
+	if t.BillRequestedAt.Valid && resp.Status == enum.TableStatusBillRequested {
+		resp.BillRequestedAt = &t.BillRequestedAt.Time
+	}
+	return resp
+}
+
+func tableRowToResponse(row database.ListDiningTablesWithOrdersRow) tableResponse {
+	resp := tableResponse{
+		ID:           row.ID,
+		Area:         row.Area,
+		Code:         row.Code,
+		Seats:        row.Seats,
+		SortOrder:    row.SortOrder,
+		Status:       service.TableStatus(int(row.ActiveOrders), row.OccupiedSince.Time, row.BillRequestedAt),
+		ActiveOrders: int(row.ActiveOrders),
+		ActiveTotal:  numericToString(row.ActiveTotal),
+	}
+	if row.OccupiedSince.Valid {
+		resp.OccupiedSince = &row.OccupiedSince.Time
+	}
+	if row.BillRequestedAt.Valid && resp.Status == enum.TableStatusBillRequested {
+		resp.BillRequestedAt = &row.BillRequestedAt.Time
+	}
+	return resp
+}
+
+// --- Handlers ---
+
+// List handles GET /outlets/{oid}/tables.
+// Returns the floor plan: tables grouped by area with their live status.
+func (h *TableHandler) List(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	rows, err := h.store.ListDiningTablesWithOrders(r.Context(), outletID)
+	if err != nil {
+		log.Printf("ERROR: list dining tables: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	// Rows arrive ordered by area
+	areas := []floorAreaResponse{}
+	for _, row := range rows {
+		if len(areas) == 0 || areas[len(areas)-1].Area != row.Area {
+			areas = append(areas, floorAreaResponse{Area: row.Area, Tables: []tableResponse{}})
+		}
+		last := &areas[len(areas)-1]
+		last.Tables = append(last.Tables, tableRowToResponse(row))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"areas": areas})
+}
+
+// Create handles POST /outlets/{oid}/tables.
+func (h *TableHandler) Create(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	var req tableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	table, err := h.store.CreateDiningTable(r.Context(), database.CreateDiningTableParams{
+		OutletID:  outletID,
+		Area:      strings.TrimSpace(req.Area),
+		Code:      strings.TrimSpace(req.Code),
+		Seats:     req.Seats,
+		SortOrder: req.SortOrder,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "table code already exists"})
+			return
+		}
+		log.Printf("ERROR: create dining table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTableResponse(table, nil))
+}
+
+// Update handles PUT /outlets/{oid}/tables/{id}.
+// An occupied table keeps its code, since its orders refer to it by code.
+func (h *TableHandler) Update(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid table ID"})
+		return
+	}
+
+	var req tableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	current, orders, ok := h.loadTable(w, r, h.store, outletID, tableID)
+	if !ok {
+		return
+	}
+	code := strings.TrimSpace(req.Code)
+	if code != current.Code && len(orders) > 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot change the code of an occupied table"})
+		return
+	}
+
+	table, err := h.store.UpdateDiningTable(r.Context(), database.UpdateDiningTableParams{
+		Area:      strings.TrimSpace(req.Area),
+		Code:      code,
+		Seats:     req.Seats,
+		SortOrder: req.SortOrder,
+		ID:        tableID,
+		OutletID:  outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "table not found"})
+			return
+		}
+		if isUniqueViolation(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "table code already exists"})
+			return
+		}
+		log.Printf("ERROR: update dining table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTableResponse(table, orders))
+}
+
+// Delete handles DELETE /outlets/{oid}/tables/{id}.
+func (h *TableHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid table ID"})
+		return
+	}
+
+	_, orders, ok := h.loadTable(w, r, h.store, outletID, tableID)
+	if !ok {
+		return
+	}
+	if len(orders) > 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot remove an occupied table"})
+		return
+	}
+
+	_, err = h.store.SoftDeleteDiningTable(r.Context(), database.SoftDeleteDiningTableParams{
+		ID:       tableID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "table not found"})
+			return
+		}
+		log.Printf("ERROR: delete dining table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestBill handles POST /outlets/{oid}/tables/{id}/bill-request.
+// Marks the seated party as waiting for the bill until the table turns over.
+func (h *TableHandler) RequestBill(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid table ID"})
+		return
+	}
+
+	_, orders, ok := h.loadTable(w, r, h.store, outletID, tableID)
+	if !ok {
+		return
+	}
+	if len(orders) == 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "table has no active orders"})
+		return
+	}
+
+	table, err := h.store.RequestDiningTableBill(r.Context(), database.RequestDiningTableBillParams{
+		ID:       tableID,
+		OutletID: outletID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "table not found"})
+			return
+		}
+		log.Printf("ERROR: request table bill: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTableResponse(table, orders))
+}
+
+// Transfer handles POST /outlets/{oid}/tables/{id}/transfer.
+// Moves one active order, or the whole party when order_id is omitted, to
+// another table.
+func (h *TableHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid table ID"})
+		return
+	}
+
+	var req transferTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	toTableID, err := uuid.Parse(req.ToTableID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid to_table_id"})
+		return
+	}
+	if toTableID == tableID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "order is already at this table"})
+		return
+	}
+	var orderID uuid.UUID
+	if req.OrderID != "" {
+		if orderID, err = uuid.Parse(req.OrderID); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order_id"})
+			return
+		}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for table transfer: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	from, orders, ok := h.loadTable(w, r, txStore, outletID, tableID)
+	if !ok {
+		return
+	}
+	to, err := txStore.GetDiningTable(r.Context(), database.GetDiningTableParams{ID: toTableID, OutletID: outletID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "target table not found"})
+			return
+		}
+		log.Printf("ERROR: get target table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if orderID != uuid.Nil {
+		var picked []database.Order
+		for _, o := range orders {
+			if o.ID == orderID {
+				picked = append(picked, o)
+			}
+		}
+		if len(picked) == 0 {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "order not found at this table"})
+			return
+		}
+		orders = picked
+	}
+	if len(orders) == 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "table has no active orders"})
+		return
+	}
+
+	moved := make([]database.Order, len(orders))
+	for i, o := range orders {
+		moved[i], err = txStore.UpdateOrderTable(r.Context(), database.UpdateOrderTableParams{
+			ID:          o.ID,
+			OutletID:    outletID,
+			TableNumber: pgtype.Text{String: to.Code, Valid: true},
+		})
+		if err != nil {
+			log.Printf("ERROR: update order table: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if err := service.RecordOrderEvent(r.Context(), txStore, o.ID, claims.UserID, enum.OrderEventTableChanged,
+			map[string]interface{}{"table_number": from.Code},
+			map[string]interface{}{"table_number": to.Code},
+		); err != nil {
+			log.Printf("ERROR: record table change: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for table transfer: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resp := make([]orderResponse, len(moved))
+	for i, o := range moved {
+		resp[i] = dbOrderToResponse(o)
+		publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderTableChanged, outletID, o.ID, orderEventPayload{})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"orders": resp})
+}
+
+// Merge handles POST /outlets/{oid}/tables/{id}/merge.
+// Combines every active order at this table and at from_table_id into one
+// bill: the oldest order at this table (or, if it is free, at the other
+// table) takes all the items and the rest are cancelled. Item discounts carry
+// over; order-level discounts on the merged-away orders do not. Orders that
+// are split into checks or already have payments cannot be merged away.
+// from_table_id may be this table, to merge the orders of a single party.
+func (h *TableHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	outletID, err := uuid.Parse(chi.URLParam(r, "oid"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid outlet ID"})
+		return
+	}
+
+	claims := middleware.ClaimsFromContext(r.Context())
+	if claims == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid table ID"})
+		return
+	}
+
+	var req mergeTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	fromTableID, err := uuid.Parse(req.FromTableID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid from_table_id"})
+		return
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		log.Printf("ERROR: begin tx for table merge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	txStore := h.newStore(tx)
+
+	table, orders, ok := h.loadTable(w, r, txStore, outletID, tableID)
+	if !ok {
+		return
+	}
+	if fromTableID != tableID {
+		_, fromOrders, ok := h.loadTable(w, r, txStore, outletID, fromTableID)
+		if !ok {
+			return
+		}
+		orders = append(orders, fromOrders...)
+	}
+	if len(orders) < 2 {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "at least two active orders are needed to merge"})
+		return
+	}
+
+	// Lock the orders before checking them, so a payment cannot land on one
+	// while it is merged away. Locked in id order so that concurrent merges
+	// of the same orders cannot deadlock.
+	lockOrder := slices.Clone(orders)
+	slices.SortFunc(lockOrder, func(a, b database.Order) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	locked := make(map[uuid.UUID]database.Order, len(orders))
+	for _, o := range lockOrder {
+		current, err := txStore.GetOrderForUpdate(r.Context(), database.GetOrderForUpdateParams{ID: o.ID, OutletID: outletID})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("ERROR: lock order for merge: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if err != nil || current.Status == enum.OrderStatusCompleted || current.Status == enum.OrderStatusCancelled {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("order %s is no longer open", o.OrderNumber)})
+			return
+		}
+		locked[o.ID] = current
+	}
+	for i, o := range orders {
+		orders[i] = locked[o.ID]
+	}
+
+	for _, o := range orders {
+		checks, err := txStore.CountOrderChecks(r.Context(), o.ID)
+		if err != nil {
+			log.Printf("ERROR: count order checks for merge: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if checks > 0 {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("order %s is split into checks; remove the split first", o.OrderNumber)})
+			return
+		}
+	}
+
+	bill, merged := orders[0], orders[1:]
+	for _, o := range merged {
+		payments, err := txStore.CountPaymentsByOrder(r.Context(), o.ID)
+		if err != nil {
+			log.Printf("ERROR: count payments for merge: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if payments > 0 {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("order %s already has payments", o.OrderNumber)})
+			return
+		}
+	}
+
+	// The bill stays at (or moves to) this table
+	if !bill.TableNumber.Valid || bill.TableNumber.String != table.Code {
+		bill, err = txStore.UpdateOrderTable(r.Context(), database.UpdateOrderTableParams{
+			ID:          bill.ID,
+			OutletID:    outletID,
+			TableNumber: pgtype.Text{String: table.Code, Valid: true},
+		})
+		if err != nil {
+			log.Printf("ERROR: update order table for merge: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
+
+	mergedNumbers := make([]string, len(merged))
+	for i, o := range merged {
+		if err := txStore.MoveOrderItems(r.Context(), database.MoveOrderItemsParams{
+			ToOrderID:   bill.ID,
+			FromOrderID: o.ID,
+		}); err != nil {
+			log.Printf("ERROR: move order items: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if err := txStore.MoveKitchenTickets(r.Context(), database.MoveKitchenTicketsParams{
+			ToOrderID:   bill.ID,
+			FromOrderID: o.ID,
+		}); err != nil {
+			log.Printf("ERROR: move kitchen tickets: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if _, err := txStore.CancelOrder(r.Context(), database.CancelOrderParams{ID: o.ID, OutletID: outletID}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("order %s is no longer open", o.OrderNumber)})
+				return
+			}
+			log.Printf("ERROR: cancel merged order: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if err := service.RecordOrderEvent(r.Context(), txStore, o.ID, claims.UserID, enum.OrderEventOrdersMerged,
+			nil, map[string]interface{}{"merged_into": bill.ID, "order_number": bill.OrderNumber},
+		); err != nil {
+			log.Printf("ERROR: record merged order: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		mergedNumbers[i] = o.OrderNumber
+	}
+
+	before := map[string]interface{}{"total_amount": numericToString(bill.TotalAmount)}
+	bill, err = recalculateOrderTotals(r.Context(), txStore, bill.ID, claims.UserID)
+	if err != nil {
+		log.Printf("ERROR: update order totals for merge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if err := service.RecordOrderEvent(r.Context(), txStore, bill.ID, claims.UserID, enum.OrderEventOrdersMerged,
+		before, map[string]interface{}{"merged_from": mergedNumbers, "total_amount": numericToString(bill.TotalAmount)},
+	); err != nil {
+		log.Printf("ERROR: record orders merged: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("ERROR: commit tx for table merge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	for _, o := range merged {
+		publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrderCancelled, outletID, o.ID, orderEventPayload{})
+	}
+	publishOrderEvent(r.Context(), h.hub, h.store, ws.EventOrdersMerged, outletID, bill.ID, orderEventPayload{})
+
+	detail, err := loadOrderDetail(r.Context(), h.store, outletID, bill.ID)
+	if err != nil {
+		log.Printf("ERROR: load merged order: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// --- Helpers ---
+
+func (req tableRequest) validate() string {
+	code := strings.TrimSpace(req.Code)
+	switch {
+	case code == "":
+		return "code is required"
+	case len(code) > 20:
+		return "code must be at most 20 characters"
+	case len(strings.TrimSpace(req.Area)) > 50:
+		return "area must be at most 50 characters"
+	case req.Seats <= 0:
+		return "seats must be > 0"
+	}
+	return ""
+}
+
+// loadTable fetches a table and the active orders seated at it, writing a
+// 404 or 500 response and returning false if it cannot.
+func (h *TableHandler) loadTable(w http.ResponseWriter, r *http.Request, store TableStore, outletID, tableID uuid.UUID) (database.DiningTable, []database.Order, bool) {
+	table, err := store.GetDiningTable(r.Context(), database.GetDiningTableParams{ID: tableID, OutletID: outletID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "table not found"})
+			return database.DiningTable{}, nil, false
+		}
+		log.Printf("ERROR: get dining table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.DiningTable{}, nil, false
+	}
+
+	orders, err := store.ListActiveOrdersByTable(r.Context(), database.ListActiveOrdersByTableParams{
+		OutletID:    outletID,
+		TableNumber: pgtype.Text{String: table.Code, Valid: true},
+	})
+	if err != nil {
+		log.Printf("ERROR: list orders at table: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return database.DiningTable{}, nil, false
+	}
+	return table, orders, true
+}
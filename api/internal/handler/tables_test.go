@@ -0,0 +1,581 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/kiwari-pos/api/internal/handler"
+	"github.com/kiwari-pos/api/internal/middleware"
+	"github.com/shopspring/decimal"
+)
+
+// --- Mock TableStore ---
+
+type mockTableStore struct {
+	tables   map[uuid.UUID]database.DiningTable
+	orders   map[uuid.UUID]database.Order
+	items    map[uuid.UUID][]database.OrderItem // keyed by order ID
+	checks   map[uuid.UUID]int64                // keyed by order ID
+	payments map[uuid.UUID]int64                // keyed by order ID
+	events   []database.CreateOrderEventParams
+	// onLock runs before an order is locked, standing in for a concurrent
+	// transaction the lock waited for
+	onLock func(orderID uuid.UUID)
+}
+
+func newMockTableStore() *mockTableStore {
+	return &mockTableStore{
+		tables:   make(map[uuid.UUID]database.DiningTable),
+		orders:   make(map[uuid.UUID]database.Order),
+		items:    make(map[uuid.UUID][]database.OrderItem),
+		checks:   make(map[uuid.UUID]int64),
+		payments: make(map[uuid.UUID]int64),
+	}
+}
+
+func (m *mockTableStore) addTable(outletID uuid.UUID, area, code string) database.DiningTable {
+	t := database.DiningTable{ID: uuid.New(), OutletID: outletID, Area: area, Code: code, Seats: 4, IsActive: true}
+	m.tables[t.ID] = t
+	return t
+}
+
+// seat places an active order at a table with one item of the given subtotal.
+func (m *mockTableStore) seat(outletID uuid.UUID, code, number, subtotal string, createdAt time.Time) database.Order {
+	o := database.Order{
+		ID:          uuid.New(),
+		OutletID:    outletID,
+		OrderNumber: number,
+		OrderType:   enum.OrderTypeDineIn,
+		Status:      enum.OrderStatusNew,
+		TableNumber: pgtype.Text{String: code, Valid: true},
+		Subtotal:    testNumeric(subtotal),
+		TotalAmount: testNumeric(subtotal),
+		CreatedAt:   createdAt,
+	}
+	m.orders[o.ID] = o
+	m.items[o.ID] = []database.OrderItem{{
+		ID:        uuid.New(),
+		OrderID:   o.ID,
+		ProductID: uuid.New(),
+		Quantity:  1,
+		UnitPrice: testNumeric(subtotal),
+		Subtotal:  testNumeric(subtotal),
+		Status:    enum.OrderItemStatusPending,
+	}}
+	return o
+}
+
+func (m *mockTableStore) activeOrders(outletID uuid.UUID, code string) []database.Order {
+	var orders []database.Order
+	for _, o := range m.orders {
+		if o.OutletID == outletID && o.TableNumber.String == code &&
+			o.Status != enum.OrderStatusCompleted && o.Status != enum.OrderStatusCancelled {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	return orders
+}
+
+func (m *mockTableStore) ListDiningTablesWithOrders(_ context.Context, outletID uuid.UUID) ([]database.ListDiningTablesWithOrdersRow, error) {
+	rows := []database.ListDiningTablesWithOrdersRow{}
+	for _, t := range m.tables {
+		if t.OutletID != outletID || !t.IsActive {
+			continue
+		}
+		row := database.ListDiningTablesWithOrdersRow{
+			ID: t.ID, Area: t.Area, Code: t.Code, Seats: t.Seats, SortOrder: t.SortOrder,
+			BillRequestedAt: t.BillRequestedAt,
+		}
+		total := decimal.Zero
+		for i, o := range m.activeOrders(outletID, t.Code) {
+			if i == 0 {
+				row.OccupiedSince = pgtype.Timestamptz{Time: o.CreatedAt, Valid: true}
+			}
+			row.ActiveOrders++
+			amount, _ := numericToDecimal(o.TotalAmount)
+			total = total.Add(amount)
+		}
+		row.ActiveTotal = decimalToNumeric(total)
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Area != rows[j].Area {
+			return rows[i].Area < rows[j].Area
+		}
+		return rows[i].Code < rows[j].Code
+	})
+	return rows, nil
+}
+
+func (m *mockTableStore) GetDiningTable(_ context.Context, arg database.GetDiningTableParams) (database.DiningTable, error) {
+	t, ok := m.tables[arg.ID]
+	if !ok || t.OutletID != arg.OutletID || !t.IsActive {
+		return database.DiningTable{}, pgx.ErrNoRows
+	}
+	return t, nil
+}
+
+func (m *mockTableStore) CreateDiningTable(_ context.Context, arg database.CreateDiningTableParams) (database.DiningTable, error) {
+	for _, t := range m.tables {
+		if t.OutletID == arg.OutletID && t.Code == arg.Code && t.IsActive {
+			return database.DiningTable{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	t := database.DiningTable{
+		ID: uuid.New(), OutletID: arg.OutletID, Area: arg.Area, Code: arg.Code,
+		Seats: arg.Seats, SortOrder: arg.SortOrder, IsActive: true, CreatedAt: time.Now(),
+	}
+	m.tables[t.ID] = t
+	return t, nil
+}
+
+func (m *mockTableStore) UpdateDiningTable(_ context.Context, arg database.UpdateDiningTableParams) (database.DiningTable, error) {
+	t, ok := m.tables[arg.ID]
+	if !ok || t.OutletID != arg.OutletID || !t.IsActive {
+		return database.DiningTable{}, pgx.ErrNoRows
+	}
+	t.Area, t.Code, t.Seats, t.SortOrder = arg.Area, arg.Code, arg.Seats, arg.SortOrder
+	m.tables[t.ID] = t
+	return t, nil
+}
+
+func (m *mockTableStore) RequestDiningTableBill(_ context.Context, arg database.RequestDiningTableBillParams) (database.DiningTable, error) {
+	t, ok := m.tables[arg.ID]
+	if !ok || t.OutletID != arg.OutletID || !t.IsActive {
+		return database.DiningTable{}, pgx.ErrNoRows
+	}
+	t.BillRequestedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	m.tables[t.ID] = t
+	return t, nil
+}
+
+func (m *mockTableStore) SoftDeleteDiningTable(_ context.Context, arg database.SoftDeleteDiningTableParams) (uuid.UUID, error) {
+	t, ok := m.tables[arg.ID]
+	if !ok || t.OutletID != arg.OutletID || !t.IsActive {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	t.IsActive = false
+	m.tables[t.ID] = t
+	return t.ID, nil
+}
+
+func (m *mockTableStore) ListActiveOrdersByTable(_ context.Context, arg database.ListActiveOrdersByTableParams) ([]database.Order, error) {
+	return m.activeOrders(arg.OutletID, arg.TableNumber.String), nil
+}
+
+func (m *mockTableStore) GetOrderForUpdate(_ context.Context, arg database.GetOrderForUpdateParams) (database.Order, error) {
+	if m.onLock != nil {
+		m.onLock(arg.ID)
+	}
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (m *mockTableStore) UpdateOrderTable(_ context.Context, arg database.UpdateOrderTableParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	o.TableNumber = arg.TableNumber
+	m.orders[o.ID] = o
+	return o, nil
+}
+
+func (m *mockTableStore) MoveOrderItems(_ context.Context, arg database.MoveOrderItemsParams) error {
+	for _, item := range m.items[arg.FromOrderID] {
+		item.OrderID = arg.ToOrderID
+		m.items[arg.ToOrderID] = append(m.items[arg.ToOrderID], item)
+	}
+	delete(m.items, arg.FromOrderID)
+	return nil
+}
+
+func (m *mockTableStore) MoveKitchenTickets(_ context.Context, arg database.MoveKitchenTicketsParams) error {
+	return nil
+}
+
+func (m *mockTableStore) LockOrderPromotions(_ context.Context, arg database.LockOrderPromotionsParams) error {
+	return nil
+}
+
+func (m *mockTableStore) ListOrderPromotions(_ context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	return []database.ListOrderPromotionsRow{}, nil
+}
+
+func (m *mockTableStore) ListPromotionItems(_ context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error) {
+	return []database.ListPromotionItemsRow{}, nil
+}
+
+func (m *mockTableStore) UpdateOrderPromotion(_ context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error) {
+	o := m.orders[arg.ID]
+	o.PromotionID = arg.PromotionID
+	o.PromotionAmount = arg.PromotionAmount
+	m.orders[o.ID] = o
+	return o, nil
+}
+
+func (m *mockTableStore) CancelOrder(_ context.Context, arg database.CancelOrderParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID || o.Status == enum.OrderStatusCompleted || o.Status == enum.OrderStatusCancelled {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	o.Status = enum.OrderStatusCancelled
+	m.orders[o.ID] = o
+	return o, nil
+}
+
+func (m *mockTableStore) CountOrderChecks(_ context.Context, orderID uuid.UUID) (int64, error) {
+	return m.checks[orderID], nil
+}
+
+func (m *mockTableStore) CountPaymentsByOrder(_ context.Context, orderID uuid.UUID) (int64, error) {
+	return m.payments[orderID], nil
+}
+
+func (m *mockTableStore) UpdateOrderTotals(_ context.Context, orderID uuid.UUID) (database.Order, error) {
+	o := m.orders[orderID]
+	total := decimal.Zero
+	for _, item := range m.items[orderID] {
+		subtotal, _ := numericToDecimal(item.Subtotal)
+		total = total.Add(subtotal)
+	}
+	o.Subtotal = decimalToNumeric(total)
+	o.TotalAmount = decimalToNumeric(total)
+	m.orders[orderID] = o
+	return o, nil
+}
+
+func (m *mockTableStore) UpdateOrderCharges(_ context.Context, arg database.UpdateOrderChargesParams) (database.Order, error) {
+	return m.orders[arg.ID], nil
+}
+
+func (m *mockTableStore) GetOutletSettings(_ context.Context, _ uuid.UUID) (database.OutletSetting, error) {
+	return database.OutletSetting{}, pgx.ErrNoRows
+}
+
+func (m *mockTableStore) CreateOrderEvent(_ context.Context, arg database.CreateOrderEventParams) error {
+	m.events = append(m.events, arg)
+	return nil
+}
+
+func (m *mockTableStore) GetOrder(_ context.Context, arg database.GetOrderParams) (database.Order, error) {
+	o, ok := m.orders[arg.ID]
+	if !ok || o.OutletID != arg.OutletID {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (m *mockTableStore) ListOrderItemsByOrder(_ context.Context, orderID uuid.UUID) ([]database.OrderItem, error) {
+	return m.items[orderID], nil
+}
+
+func (m *mockTableStore) ListOrderItemModifiersByOrderItem(_ context.Context, _ uuid.UUID) ([]database.OrderItemModifier, error) {
+	return []database.OrderItemModifier{}, nil
+}
+
+func (m *mockTableStore) ListPaymentsByOrder(_ context.Context, _ uuid.UUID) ([]database.Payment, error) {
+	return []database.Payment{}, nil
+}
+
+// --- Helpers ---
+
+func setupTableRouter(store *mockTableStore) *chi.Mux {
+	newStore := func(db database.DBTX) handler.TableStore {
+		return store
+	}
+	h := handler.NewTableHandler(store, &mockPool{}, newStore, nil)
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(testJWTSecret))
+	r.Route("/outlets/{oid}/tables", h.RegisterRoutes)
+	return r
+}
+
+type floorPlanBody struct {
+	Areas []struct {
+		Area   string `json:"area"`
+		Tables []struct {
+			ID           string `json:"id"`
+			Code         string `json:"code"`
+			Status       string `json:"status"`
+			ActiveOrders int    `json:"active_orders"`
+			ActiveTotal  string `json:"active_total"`
+		} `json:"tables"`
+	} `json:"areas"`
+}
+
+// --- Tests ---
+
+func TestTableList_FloorPlanStatus(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	seated := time.Now().Add(-30 * time.Minute)
+
+	store.addTable(outletID, "Indoor", "A1")
+	a2 := store.addTable(outletID, "Indoor", "A2")
+	b1 := store.addTable(outletID, "Teras", "B1")
+	store.seat(outletID, "A2", "KWR-001", "50000.00", seated)
+	store.seat(outletID, "A2", "KWR-002", "20000.00", seated.Add(5*time.Minute))
+	store.seat(outletID, "B1", "KWR-003", "30000.00", seated)
+	b1.BillRequestedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	store.tables[b1.ID] = b1
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "GET", "/outlets/"+outletID.String()+"/tables", nil, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp floorPlanBody
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Areas) != 2 || resp.Areas[0].Area != "Indoor" || len(resp.Areas[0].Tables) != 2 {
+		t.Fatalf("areas: got %+v", resp.Areas)
+	}
+	free, occupied, billRequested := resp.Areas[0].Tables[0], resp.Areas[0].Tables[1], resp.Areas[1].Tables[0]
+	if free.Status != enum.TableStatusFree {
+		t.Errorf("A1: got %s, want %s", free.Status, enum.TableStatusFree)
+	}
+	if occupied.ID != a2.ID.String() || occupied.Status != enum.TableStatusOccupied || occupied.ActiveOrders != 2 || occupied.ActiveTotal != "70000.00" {
+		t.Errorf("A2: got %+v", occupied)
+	}
+	if billRequested.Status != enum.TableStatusBillRequested {
+		t.Errorf("B1: got %s, want %s", billRequested.Status, enum.TableStatusBillRequested)
+	}
+}
+
+func TestTableCreate_DuplicateCode(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	store.addTable(outletID, "Indoor", "A1")
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables", map[string]interface{}{
+		"area": "Indoor", "code": "A1", "seats": 4,
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables", map[string]interface{}{
+		"area": "Indoor", "code": "A2", "seats": 0,
+	}, claims)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("zero seats: got %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTableUpdate_OccupiedKeepsCode(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now())
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String(), map[string]interface{}{
+		"area": "Indoor", "code": "A9", "seats": 4,
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+
+	// Seats can still change
+	rr = doAuthRequest(t, router, "PUT", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String(), map[string]interface{}{
+		"area": "Indoor", "code": "A1", "seats": 6,
+	}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if store.tables[a1.ID].Seats != 6 {
+		t.Errorf("seats: got %d, want 6", store.tables[a1.ID].Seats)
+	}
+}
+
+func TestTableRequestBill(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	a2 := store.addTable(outletID, "Indoor", "A2")
+	store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now().Add(-time.Minute))
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a2.ID.String()+"/bill-request", nil, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("free table: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/bill-request", nil, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != enum.TableStatusBillRequested {
+		t.Errorf("status: got %s, want %s", resp.Status, enum.TableStatusBillRequested)
+	}
+}
+
+func TestTableTransfer_MovesParty(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	b1 := store.addTable(outletID, "Teras", "B1")
+	first := store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now().Add(-time.Hour))
+	second := store.seat(outletID, "A1", "KWR-002", "20000.00", time.Now())
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/transfer", map[string]interface{}{
+		"to_table_id": b1.ID.String(),
+		"order_id":    second.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if store.orders[second.ID].TableNumber.String != "B1" || store.orders[first.ID].TableNumber.String != "A1" {
+		t.Errorf("single order: got %s and %s", store.orders[first.ID].TableNumber.String, store.orders[second.ID].TableNumber.String)
+	}
+	if len(store.events) != 1 || store.events[0].EventType != enum.OrderEventTableChanged || store.events[0].OrderID != second.ID {
+		t.Errorf("events: got %+v", store.events)
+	}
+
+	// Without order_id the rest of the party follows
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/transfer", map[string]interface{}{
+		"to_table_id": b1.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if store.orders[first.ID].TableNumber.String != "B1" {
+		t.Errorf("party: first order still at %s", store.orders[first.ID].TableNumber.String)
+	}
+
+	// A1 is now free
+	rr = doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/transfer", map[string]interface{}{
+		"to_table_id": b1.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("free table: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestTableMerge_CombinesIntoOneBill(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	a2 := store.addTable(outletID, "Indoor", "A2")
+	bill := store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now().Add(-time.Hour))
+	other := store.seat(outletID, "A2", "KWR-002", "20000.00", time.Now().Add(-2*time.Hour))
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/merge", map[string]interface{}{
+		"from_table_id": a2.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp struct {
+		ID          string `json:"id"`
+		TotalAmount string `json:"total_amount"`
+		Items       []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ID != bill.ID.String() || len(resp.Items) != 2 || resp.TotalAmount != "70000.00" {
+		t.Errorf("merged bill: got %+v", resp)
+	}
+	if store.orders[other.ID].Status != enum.OrderStatusCancelled {
+		t.Errorf("merged-away order: got status %s", store.orders[other.ID].Status)
+	}
+	var mergeEvents int
+	for _, e := range store.events {
+		if e.EventType == enum.OrderEventOrdersMerged {
+			mergeEvents++
+		}
+	}
+	if mergeEvents != 2 {
+		t.Errorf("ORDERS_MERGED events: got %d, want 2", mergeEvents)
+	}
+}
+
+// A payment that completes an order while the merge waits for its lock
+// keeps the order out of the merge.
+func TestTableMerge_OrderPaidWhileLocking(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	a2 := store.addTable(outletID, "Indoor", "A2")
+	store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now().Add(-time.Hour))
+	paid := store.seat(outletID, "A2", "KWR-002", "20000.00", time.Now())
+	store.onLock = func(orderID uuid.UUID) {
+		if orderID == paid.ID {
+			o := store.orders[paid.ID]
+			o.Status = enum.OrderStatusCompleted
+			store.orders[paid.ID] = o
+			store.payments[paid.ID] = 1
+		}
+	}
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/merge", map[string]interface{}{
+		"from_table_id": a2.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if store.orders[paid.ID].Status != enum.OrderStatusCompleted {
+		t.Errorf("paid order: got status %s, want COMPLETED", store.orders[paid.ID].Status)
+	}
+}
+
+func TestTableMerge_PaidOrderRejected(t *testing.T) {
+	outletID := uuid.New()
+	claims := testClaims(outletID)
+	store := newMockTableStore()
+	a1 := store.addTable(outletID, "Indoor", "A1")
+	a2 := store.addTable(outletID, "Indoor", "A2")
+	store.seat(outletID, "A1", "KWR-001", "50000.00", time.Now().Add(-time.Hour))
+	paid := store.seat(outletID, "A2", "KWR-002", "20000.00", time.Now())
+	store.payments[paid.ID] = 1
+
+	router := setupTableRouter(store)
+	rr := doAuthRequest(t, router, "POST", "/outlets/"+outletID.String()+"/tables/"+a1.ID.String()+"/merge", map[string]interface{}{
+		"from_table_id": a2.ID.String(),
+	}, claims)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	if store.orders[paid.ID].Status == enum.OrderStatusCancelled {
+		t.Error("paid order should not be cancelled")
+	}
+}
@@ -0,0 +1,78 @@
+package receipt
+
+import (
+	"bytes"
+	"unicode"
+)
+
+// ESC/POS commands understood by common 58mm and 80mm thermal printers.
+var (
+	escInit        = []byte{0x1B, 0x40}             // ESC @: reset
+	escAlignLeft   = []byte{0x1B, 0x61, 0x00}       // ESC a 0
+	escAlignCenter = []byte{0x1B, 0x61, 0x01}       // ESC a 1
+	escBoldOn      = []byte{0x1B, 0x45, 0x01}       // ESC E 1
+	escBoldOff     = []byte{0x1B, 0x45, 0x00}       // ESC E 0
+	escTallOn      = []byte{0x1D, 0x21, 0x01}       // GS ! 1: double height
+	escSizeNormal  = []byte{0x1D, 0x21, 0x00}       // GS ! 0
+	escFeed        = []byte{0x1B, 0x64, 0x04}       // ESC d 4: feed 4 lines
+	escCut         = []byte{0x1D, 0x56, 0x42, 0x00} // GS V B 0: feed to cutter and partial cut
+)
+
+// ESCPOS renders the receipt as raw printer bytes for the given paper
+// width, ending with a paper cut.
+func (r Receipt) ESCPOS(paperWidth int32) []byte {
+	return renderESCPOS(r.layout(), paperWidth)
+}
+
+// renderESCPOS writes lines as printer bytes. Text is padded to the
+// printer's columns, so alignment commands are only needed for centred lines.
+func renderESCPOS(lines []line, paperWidth int32) []byte {
+	cols := columns(paperWidth)
+	var b bytes.Buffer
+	b.Write(escInit)
+
+	for _, l := range lines {
+		if l.bold {
+			b.Write(escBoldOn)
+		}
+		if l.tall {
+			b.Write(escTallOn)
+		}
+		if l.align == alignCenter {
+			// The printer centres; padding would push text off-centre
+			b.Write(escAlignCenter)
+			for _, row := range wrap(l.left, cols) {
+				b.WriteString(printable(row))
+				b.WriteByte('\n')
+			}
+			b.Write(escAlignLeft)
+		} else {
+			for _, row := range l.format(cols) {
+				b.WriteString(printable(row))
+				b.WriteByte('\n')
+			}
+		}
+		if l.tall {
+			b.Write(escSizeNormal)
+		}
+		if l.bold {
+			b.Write(escBoldOff)
+		}
+	}
+
+	b.Write(escFeed)
+	b.Write(escCut)
+	return b.Bytes()
+}
+
+// printable replaces what the printer's default code page can't show.
+// Control characters would be read as commands.
+func printable(s string) string {
+	out := []rune(s)
+	for i, c := range out {
+		if c > unicode.MaxASCII || unicode.IsControl(c) {
+			out[i] = '?'
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,76 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money": FormatMoney,
+	"rate":  formatRate,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.R.OrderNumber}}</title>
+<style>
+body { width: {{.Width}}mm; margin: 0 auto; font-family: monospace; font-size: 12px; }
+.center { text-align: center; }
+table { width: 100%; border-collapse: collapse; }
+td { vertical-align: top; padding: 1px 0; }
+td.amount { text-align: right; white-space: nowrap; }
+.sub { padding-left: 1.5em; }
+.total td { font-weight: bold; font-size: 1.3em; }
+hr { border: none; border-top: 1px dashed #000; }
+</style>
+</head>
+<body>
+<div class="center"><strong>{{.R.OutletName}}</strong></div>
+{{range .R.Header}}<div class="center">{{.}}</div>
+{{end}}<hr>
+<table>
+<tr><td>{{.R.OrderNumber}}</td><td class="amount">{{.R.Time.Format "02/01/2006 15:04"}}</td></tr>
+{{if .R.OrderType}}<tr><td colspan="2">{{.R.OrderType}}{{if .R.TableNumber}} - Table {{.R.TableNumber}}{{end}}</td></tr>
+{{end}}{{if .R.Cashier}}<tr><td colspan="2">Cashier: {{.R.Cashier}}</td></tr>
+{{end}}</table>
+<hr>
+<table>
+{{range .R.Items}}<tr><td>{{.Quantity}}x {{.Name}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{range .Components}}<tr><td class="sub" colspan="2">- {{.}}</td></tr>
+{{end}}{{range .Modifiers}}<tr><td class="sub">+ {{.Name}}{{if gt .Quantity 1}} x{{.Quantity}}{{end}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{end}}{{if .Discount.IsPositive}}<tr><td class="sub">Discount</td><td class="amount">{{money .Discount.Neg}}</td></tr>
+{{end}}{{if .Notes}}<tr><td class="sub" colspan="2">Note: {{.Notes}}</td></tr>
+{{end}}{{end}}</table>
+<hr>
+<table>
+<tr><td>Subtotal</td><td class="amount">{{money .R.Subtotal}}</td></tr>
+{{if .R.Discount.IsPositive}}<tr><td>Discount</td><td class="amount">{{money .R.Discount.Neg}}</td></tr>
+{{end}}{{if .R.ServiceCharge.IsPositive}}<tr><td>Service {{rate .R.ServiceChargeRate}}</td><td class="amount">{{money .R.ServiceCharge}}</td></tr>
+{{end}}{{if .R.Tax.IsPositive}}<tr><td>Tax {{rate .R.TaxRate}}{{if .R.TaxIncluded}} (incl.){{end}}</td><td class="amount">{{money .R.Tax}}</td></tr>
+{{end}}<tr class="total"><td>TOTAL</td><td class="amount">{{money .R.Total}}</td></tr>
+</table>
+{{if .R.Payments}}<hr>
+<table>
+{{range .R.Payments}}<tr><td>{{.Method}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{if .Reference}}<tr><td class="sub" colspan="2">Ref: {{.Reference}}</td></tr>
+{{end}}{{end}}{{if .R.Change.IsPositive}}<tr><td><strong>Change</strong></td><td class="amount"><strong>{{money .R.Change}}</strong></td></tr>
+{{end}}</table>
+{{end}}{{if .R.Footer}}<hr>
+{{range .R.Footer}}<div class="center">{{.}}</div>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// HTML renders the receipt as a standalone page sized to the paper width.
+func (r Receipt) HTML(paperWidth int32) ([]byte, error) {
+	var b bytes.Buffer
+	err := htmlTemplate.Execute(&b, struct {
+		R     Receipt
+		Width int32
+	}{r, paperWidth})
+	if err != nil {
+		return nil, fmt.Errorf("render receipt html: %w", err)
+	}
+	return b.Bytes(), nil
+}
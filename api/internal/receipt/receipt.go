@@ -0,0 +1,296 @@
+// Package receipt renders customer receipts and kitchen tickets as plain
+// text and ESC/POS printer bytes (receipts also as HTML) from one layout, so
+// every client prints the same thing.
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// Thermal paper widths in millimetres.
+const (
+	Paper58 = 58
+	Paper80 = 80
+)
+
+// IsValidPaperWidth reports whether mm is a supported paper width.
+func IsValidPaperWidth(mm int32) bool {
+	return mm == Paper58 || mm == Paper80
+}
+
+// columns is how many characters of the printer's standard font fit on a line.
+func columns(paperWidth int32) int {
+	if paperWidth == Paper80 {
+		return 48
+	}
+	return 32
+}
+
+// Receipt is everything printed on a customer receipt. Amounts are in rupiah.
+type Receipt struct {
+	OutletName string
+	Header     []string // address, phone and the outlet's own header text
+	Footer     []string
+
+	OrderNumber string
+	OrderType   string // label, e.g. "Dine in"
+	TableNumber string
+	Cashier     string
+	Time        time.Time // in the outlet's timezone
+
+	Items []Item
+
+	Subtotal          decimal.Decimal // after item discounts
+	Discount          decimal.Decimal // order-level
+	ServiceCharge     decimal.Decimal
+	ServiceChargeRate decimal.Decimal // percent
+	Tax               decimal.Decimal
+	TaxRate           decimal.Decimal // percent
+	TaxIncluded       bool            // tax is part of the prices, not added on top
+	Total             decimal.Decimal
+
+	Payments []Payment
+	Change   decimal.Decimal
+}
+
+// Item is one ordered product.
+type Item struct {
+	Name       string // with variants, e.g. "Es Teh (Large)"
+	Quantity   int32
+	Amount     decimal.Decimal // unit price × quantity
+	Modifiers  []Modifier
+	Discount   decimal.Decimal
+	Notes      string
+	Components []string // combo contents, printed without prices
+}
+
+// Modifier is an add-on to an item.
+type Modifier struct {
+	Name     string
+	Quantity int32
+	Amount   decimal.Decimal
+}
+
+// Payment is one completed payment towards the order.
+type Payment struct {
+	Method    string // label, e.g. "Cash"
+	Amount    decimal.Decimal
+	Reference string
+}
+
+// FormatMoney formats a rupiah amount with dot thousands separators,
+// e.g. 1250000 → "1.250.000". Cents are only shown when present.
+func FormatMoney(d decimal.Decimal) string {
+	sign := ""
+	if d.IsNegative() {
+		sign = "-"
+		d = d.Neg()
+	}
+	whole := d.Truncate(0)
+	frac := d.Sub(whole)
+
+	digits := whole.String()
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	if !frac.IsZero() {
+		b.WriteString("," + frac.Shift(2).Round(0).StringFixed(0))
+	}
+	return sign + b.String()
+}
+
+// --- Layout shared by the text and ESC/POS renderers ---
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+)
+
+// line is one logical receipt line: left text, optional right-aligned amount.
+type line struct {
+	left, right string
+	align       align
+	bold        bool
+	tall        bool // double height; keeps the column count
+	separator   bool
+}
+
+func (r Receipt) layout() []line {
+	var lines []line
+
+	lines = append(lines, line{left: r.OutletName, align: alignCenter, bold: true})
+	for _, h := range r.Header {
+		lines = append(lines, line{left: h, align: alignCenter})
+	}
+	lines = append(lines, line{separator: true})
+
+	lines = append(lines, line{left: r.OrderNumber, right: r.Time.Format("02/01/2006 15:04")})
+	info := r.OrderType
+	if r.TableNumber != "" {
+		info += " - Table " + r.TableNumber
+	}
+	if info != "" {
+		lines = append(lines, line{left: info})
+	}
+	if r.Cashier != "" {
+		lines = append(lines, line{left: "Cashier: " + r.Cashier})
+	}
+	lines = append(lines, line{separator: true})
+
+	for _, item := range r.Items {
+		lines = append(lines, line{left: fmt.Sprintf("%dx %s", item.Quantity, item.Name), right: FormatMoney(item.Amount)})
+		for _, c := range item.Components {
+			lines = append(lines, line{left: "   - " + c})
+		}
+		for _, m := range item.Modifiers {
+			name := m.Name
+			if m.Quantity > 1 {
+				name = fmt.Sprintf("%s x%d", name, m.Quantity)
+			}
+			lines = append(lines, line{left: "   + " + name, right: FormatMoney(m.Amount)})
+		}
+		if item.Discount.IsPositive() {
+			lines = append(lines, line{left: "   Discount", right: FormatMoney(item.Discount.Neg())})
+		}
+		if item.Notes != "" {
+			lines = append(lines, line{left: "   Note: " + item.Notes})
+		}
+	}
+	lines = append(lines, line{separator: true})
+
+	lines = append(lines, line{left: "Subtotal", right: FormatMoney(r.Subtotal)})
+	if r.Discount.IsPositive() {
+		lines = append(lines, line{left: "Discount", right: FormatMoney(r.Discount.Neg())})
+	}
+	if r.ServiceCharge.IsPositive() {
+		lines = append(lines, line{left: "Service " + formatRate(r.ServiceChargeRate), right: FormatMoney(r.ServiceCharge)})
+	}
+	if r.Tax.IsPositive() {
+		label := "Tax " + formatRate(r.TaxRate)
+		if r.TaxIncluded {
+			label += " (incl.)"
+		}
+		lines = append(lines, line{left: label, right: FormatMoney(r.Tax)})
+	}
+	lines = append(lines, line{left: "TOTAL", right: FormatMoney(r.Total), bold: true, tall: true})
+
+	if len(r.Payments) > 0 {
+		lines = append(lines, line{separator: true})
+		for _, p := range r.Payments {
+			lines = append(lines, line{left: p.Method, right: FormatMoney(p.Amount)})
+			if p.Reference != "" {
+				lines = append(lines, line{left: "   Ref: " + p.Reference})
+			}
+		}
+		if r.Change.IsPositive() {
+			lines = append(lines, line{left: "Change", right: FormatMoney(r.Change), bold: true})
+		}
+	}
+
+	if len(r.Footer) > 0 {
+		lines = append(lines, line{separator: true})
+		for _, f := range r.Footer {
+			lines = append(lines, line{left: f, align: alignCenter})
+		}
+	}
+
+	return lines
+}
+
+func formatRate(rate decimal.Decimal) string {
+	return rate.String() + "%"
+}
+
+// format lays out one line in cols characters, wrapping long text. The
+// amount goes on the first row; wrapped rows keep the left text's indent.
+func (l line) format(cols int) []string {
+	if l.separator {
+		return []string{strings.Repeat("-", cols)}
+	}
+
+	width := cols
+	if l.right != "" {
+		width = cols - utf8.RuneCountInString(l.right) - 1
+	}
+	rows := wrap(l.left, width)
+
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		switch {
+		case i == 0 && l.right != "":
+			out[i] = row + strings.Repeat(" ", cols-utf8.RuneCountInString(row)-utf8.RuneCountInString(l.right)) + l.right
+		case l.align == alignCenter:
+			out[i] = strings.Repeat(" ", (cols-utf8.RuneCountInString(row))/2) + row
+		default:
+			out[i] = row
+		}
+	}
+	return out
+}
+
+// wrap splits s into rows of at most width runes, breaking at spaces where
+// possible. Continuation rows repeat s's leading indent.
+func wrap(s string, width int) []string {
+	trimmed := strings.TrimLeft(s, " ")
+	indent := s[:len(s)-len(trimmed)]
+	if width <= len(indent) {
+		width = len(indent) + 1
+	}
+
+	var rows []string
+	cur := indent
+	for _, word := range strings.Fields(trimmed) {
+		for utf8.RuneCountInString(word) > width-len(indent) {
+			// A word longer than the line: fill the current row and break it
+			if cur != indent {
+				rows = append(rows, cur)
+				cur = indent
+			}
+			r := []rune(word)
+			n := width - len(indent)
+			rows = append(rows, indent+string(r[:n]))
+			word = string(r[n:])
+		}
+		switch {
+		case cur == indent:
+			cur += word
+		case utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(word) <= width:
+			cur += " " + word
+		default:
+			rows = append(rows, cur)
+			cur = indent + word
+		}
+	}
+	if cur != indent || len(rows) == 0 {
+		rows = append(rows, cur)
+	}
+	return rows
+}
+
+// Text renders the receipt as plain text for the given paper width.
+func (r Receipt) Text(paperWidth int32) string {
+	return renderText(r.layout(), paperWidth)
+}
+
+func renderText(lines []line, paperWidth int32) string {
+	cols := columns(paperWidth)
+	var b strings.Builder
+	for _, l := range lines {
+		for _, row := range l.format(cols) {
+			b.WriteString(row)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
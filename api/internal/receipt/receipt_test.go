@@ -0,0 +1,128 @@
+package receipt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"500", "500"},
+		{"1000", "1.000"},
+		{"1250000", "1.250.000"},
+		{"-7500", "-7.500"},
+		{"1234.5", "1.234,50"},
+	}
+	for _, tt := range tests {
+		if got := FormatMoney(decimal.RequireFromString(tt.in)); got != tt.want {
+			t.Errorf("FormatMoney(%s): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	rows := wrap("   Note: please make it extra spicy", 16)
+	want := []string{"   Note: please", "   make it extra", "   spicy"}
+	if strings.Join(rows, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+
+	rows = wrap("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 10)
+	if len(rows) != 3 || rows[0] != "ABCDEFGHIJ" || rows[2] != "UVWXYZ" {
+		t.Errorf("long word: got %q", rows)
+	}
+}
+
+func TestLineFormat_RightAlignsAmount(t *testing.T) {
+	l := line{left: "2x Ayam Bakar Madu Spesial Pedas Manis", right: "90.000"}
+	rows := l.format(32)
+	if len(rows) != 2 {
+		t.Fatalf("expected name to wrap onto 2 rows, got %q", rows)
+	}
+	if len(rows[0]) != 32 || !strings.HasSuffix(rows[0], " 90.000") {
+		t.Errorf("first row: got %q", rows[0])
+	}
+}
+
+func TestText_PaperWidths(t *testing.T) {
+	r := Receipt{
+		OutletName: "Kiwari",
+		Items: []Item{{
+			Name:     "Nasi Goreng Kampung Spesial Dengan Telur Mata Sapi",
+			Quantity: 1,
+			Amount:   decimal.NewFromInt(35000),
+		}},
+		Subtotal: decimal.NewFromInt(35000),
+		Total:    decimal.NewFromInt(35000),
+	}
+	for _, tt := range []struct {
+		paper int32
+		cols  int
+	}{{Paper58, 32}, {Paper80, 48}} {
+		text := r.Text(tt.paper)
+		if !strings.Contains(text, strings.Repeat("-", tt.cols)+"\n") {
+			t.Errorf("%dmm: expected %d-column separator", tt.paper, tt.cols)
+		}
+		for _, row := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			if len(row) > tt.cols {
+				t.Errorf("%dmm: row too wide: %q", tt.paper, row)
+			}
+		}
+	}
+}
+
+func TestPrintable(t *testing.T) {
+	if got := printable("Kopi Susu\x1bé"); got != "Kopi Susu??" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestKitchenTicketText(t *testing.T) {
+	kt := KitchenTicket{
+		Station:     "GRILL",
+		OrderNumber: "KWR-014",
+		OrderType:   "Dine in",
+		TableNumber: "4",
+		Sequence:    3,
+		Change:      true,
+		Reprint:     true,
+		Lines: []TicketLine{
+			{Change: enum.KitchenLineVoided, Name: "Ayam Bakar", Quantity: 1},
+			{Change: enum.KitchenLineQuantityChanged, Name: "Nasi Bakar (Pedas)", Quantity: 3, PreviousQuantity: 2},
+			{Change: enum.KitchenLineAdded, Name: "Tahu Bakar", Quantity: 1, Modifiers: []Modifier{{Name: "Sambal", Quantity: 2}}, Notes: "no onion"},
+		},
+	}
+
+	text := kt.Text(Paper58)
+	for _, want := range []string{
+		"** CHANGE **",
+		"** REPRINT **",
+		"#3",
+		"Dine in - Table 4",
+		"VOID 1x Ayam Bakar",
+		"3x Nasi Bakar (Pedas)",
+		"(was 2)",
+		"ADD 1x Tahu Bakar",
+		"+ Sambal x2",
+		"* no onion",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("ticket missing %q:\n%s", want, text)
+		}
+	}
+
+	// First tickets print dishes without change marks
+	kt.Change, kt.Reprint = false, false
+	kt.Lines = kt.Lines[2:]
+	text = kt.Text(Paper58)
+	if strings.Contains(text, "CHANGE") || strings.Contains(text, "ADD ") {
+		t.Errorf("new ticket should not be marked as a change:\n%s", text)
+	}
+}
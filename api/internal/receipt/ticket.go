@@ -0,0 +1,94 @@
+package receipt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+// KitchenTicket is what one kitchen station prints for an order change.
+type KitchenTicket struct {
+	Station     string // empty for items without a station
+	OrderNumber string
+	OrderType   string // label, e.g. "Dine in"
+	TableNumber string
+	Sequence    int32
+	Change      bool // lists only what changed since the station's last ticket
+	Reprint     bool
+	Time        time.Time // in the outlet's timezone
+	Lines       []TicketLine
+}
+
+// TicketLine is one dish on a kitchen ticket.
+type TicketLine struct {
+	Change           string // enum.KitchenLine*
+	Name             string // with variants, e.g. "Es Teh (Large)"
+	Quantity         int32
+	PreviousQuantity int32 // for quantity changes
+	Modifiers        []Modifier
+	Notes            string
+}
+
+func (t KitchenTicket) layout() []line {
+	station := t.Station
+	if station == "" {
+		station = "KITCHEN"
+	}
+
+	lines := []line{{left: station, align: alignCenter, bold: true, tall: true}}
+	if t.Change {
+		lines = append(lines, line{left: "** CHANGE **", align: alignCenter, bold: true})
+	}
+	if t.Reprint {
+		lines = append(lines, line{left: "** REPRINT **", align: alignCenter, bold: true})
+	}
+	lines = append(lines, line{left: t.OrderNumber, right: fmt.Sprintf("#%d", t.Sequence), bold: true})
+	info := t.OrderType
+	if t.TableNumber != "" {
+		info += " - Table " + t.TableNumber
+	}
+	if info != "" {
+		lines = append(lines, line{left: info})
+	}
+	lines = append(lines, line{left: t.Time.Format("02/01/2006 15:04")})
+	lines = append(lines, line{separator: true})
+
+	for _, l := range t.Lines {
+		text := fmt.Sprintf("%dx %s", l.Quantity, l.Name)
+		switch {
+		case l.Change == enum.KitchenLineVoided:
+			text = "VOID " + text
+		case l.Change == enum.KitchenLineAdded && t.Change:
+			text = "ADD " + text
+		}
+		lines = append(lines, line{left: text, bold: true, tall: true})
+		if l.Change == enum.KitchenLineQuantityChanged {
+			lines = append(lines, line{left: fmt.Sprintf("   (was %d)", l.PreviousQuantity)})
+		}
+		for _, m := range l.Modifiers {
+			name := m.Name
+			if m.Quantity > 1 {
+				name = fmt.Sprintf("%s x%d", name, m.Quantity)
+			}
+			lines = append(lines, line{left: "   + " + name})
+		}
+		if l.Notes != "" {
+			lines = append(lines, line{left: "   * " + l.Notes})
+		}
+	}
+	lines = append(lines, line{separator: true})
+
+	return lines
+}
+
+// Text renders the ticket as plain text for the given paper width.
+func (t KitchenTicket) Text(paperWidth int32) string {
+	return renderText(t.layout(), paperWidth)
+}
+
+// ESCPOS renders the ticket as raw printer bytes for the given paper width,
+// ending with a paper cut.
+func (t KitchenTicket) ESCPOS(paperWidth int32) []byte {
+	return renderESCPOS(t.layout(), paperWidth)
+}
@@ -22,9 +22,16 @@ import (
 
 // New creates a Chi router with all application routes wired up.
 // Applies authentication, outlet scoping, and role-based middleware as needed.
-func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub *ws.Hub) chi.Router {
+// WebSocket clients attach to hub; handlers publish through events, which is
+// the hub itself or a relay that fans out across replicas.
+func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub *ws.Hub, events handler.Broadcaster) chi.Router {
 	r := chi.NewRouter()
 
+	// Kitchen displays subscribed to kitchen.all_day get a fresh view after each order change
+	events = handler.NewKitchenAllDayPublisher(events, queries)
+	// Outlets with a pickup display get order numbers and states for their screen
+	events = handler.NewPickupDisplayPublisher(events, queries)
+
 	// Standard middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
@@ -38,8 +45,8 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 			"https://pos.nasibakarkiwari.com",        // Legacy admin (remove after migration)
 		},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300, // 5 minutes
 	}))
@@ -54,11 +61,30 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 	authHandler := handler.NewAuthHandler(queries, cfg.JWTSecret)
 	authHandler.RegisterRoutes(r)
 
+	// Orders (routes registered under outlets below; also handles kitchen WebSocket commands)
+	newOrderStore := func(db database.DBTX) service.OrderStore {
+		return database.New(db)
+	}
+	orderService := service.NewOrderService(pool, newOrderStore)
+	orderHandler := handler.NewOrderHandler(
+		orderService,
+		queries,
+		pool,
+		func(db database.DBTX) handler.OrderStore {
+			return database.New(db)
+		},
+		events,
+	)
+
 	// WebSocket route (handles auth internally via query param)
 	r.Get("/ws/outlets/{oid}/orders", func(w http.ResponseWriter, r *http.Request) {
-		ws.ServeWS(hub, cfg.JWTSecret, w, r)
+		ws.ServeWS(hub, cfg.JWTSecret, orderHandler, w, r)
 	})
 
+	// Customer pickup screens (public, authorized by the display token in the URL)
+	pickupDisplayHandler := handler.NewPickupDisplayHandler(queries, hub)
+	pickupDisplayHandler.RegisterPublicRoutes(r)
+
 	// Protected routes (require authentication)
 	r.Group(func(r chi.Router) {
 		r.Use(mw.Authenticate(cfg.JWTSecret))
@@ -123,6 +149,16 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 				r.Post("/from-whatsapp", whatsappHandler.FromWhatsApp)
 			})
 
+			// Sales (daily summaries, POS end-of-day close)
+			salesHandler := accthandler.NewSalesHandler(
+				queries,
+				pool,
+				func(db database.DBTX) accthandler.SalesStore {
+					return database.New(db)
+				},
+			)
+			r.Route("/accounting/sales", salesHandler.RegisterRoutes)
+
 			// Reports
 			reportHandler := accthandler.NewReportHandler(queries)
 			r.Route("/accounting/reports", reportHandler.RegisterRoutes)
@@ -140,6 +176,26 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 			userHandler := handler.NewUserHandler(queries)
 			r.Route("/users", userHandler.RegisterRoutes)
 
+			// Outlet settings (tax, service charge)
+			outletSettingsHandler := handler.NewOutletSettingsHandler(queries)
+			r.Route("/settings", func(r chi.Router) {
+				r.Use(mw.RequireRole("OWNER", "MANAGER"))
+				outletSettingsHandler.RegisterRoutes(r)
+			})
+
+			// Loyalty program settings (earn rates, point value, tiers)
+			loyaltySettingsHandler := handler.NewLoyaltySettingsHandler(queries)
+			r.Route("/loyalty", func(r chi.Router) {
+				r.Use(mw.RequireRole("OWNER", "MANAGER"))
+				loyaltySettingsHandler.RegisterRoutes(r)
+			})
+
+			// Pickup display configuration
+			r.Route("/pickup-display", func(r chi.Router) {
+				r.Use(mw.RequireRole("OWNER", "MANAGER"))
+				pickupDisplayHandler.RegisterRoutes(r)
+			})
+
 			// Categories
 			categoryHandler := handler.NewCategoryHandler(queries)
 			r.Route("/categories", categoryHandler.RegisterRoutes)
@@ -163,18 +219,6 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 			})
 
 			// Orders
-			newOrderStore := func(db database.DBTX) service.OrderStore {
-				return database.New(db)
-			}
-			orderService := service.NewOrderService(pool, newOrderStore)
-			orderHandler := handler.NewOrderHandler(
-				orderService,
-				queries,
-				pool,
-				func(db database.DBTX) handler.OrderStore {
-					return database.New(db)
-				},
-			)
 			r.Route("/orders", func(r chi.Router) {
 				orderHandler.RegisterRoutes(r)
 
@@ -186,14 +230,82 @@ func New(cfg *config.Config, queries *database.Queries, pool *pgxpool.Pool, hub
 						func(db database.DBTX) handler.PaymentStore {
 							return database.New(db)
 						},
+						events,
 					)
 					paymentHandler.RegisterRoutes(r)
 				})
+
+				// Split bill checks (nested under orders)
+				r.Route("/{id}/checks", func(r chi.Router) {
+					checkHandler := handler.NewCheckHandler(
+						queries,
+						pool,
+						func(db database.DBTX) handler.CheckStore {
+							return database.New(db)
+						},
+					)
+					checkHandler.RegisterRoutes(r)
+				})
+
+				// Receipts (nested under orders)
+				r.Route("/{id}/receipt", func(r chi.Router) {
+					receiptHandler := handler.NewReceiptHandler(queries)
+					receiptHandler.RegisterRoutes(r)
+				})
+
+				// Kitchen tickets (nested under orders)
+				r.Route("/{id}/tickets", func(r chi.Router) {
+					kitchenTicketHandler := handler.NewKitchenTicketHandler(queries)
+					kitchenTicketHandler.RegisterRoutes(r)
+				})
+			})
+
+			// Tables (floor plan)
+			tableHandler := handler.NewTableHandler(
+				queries,
+				pool,
+				func(db database.DBTX) handler.TableStore {
+					return database.New(db)
+				},
+				events,
+			)
+			r.Route("/tables", tableHandler.RegisterRoutes)
+
+			// Cashier shifts (drawer float, cash in/out, close and X/Z reports)
+			shiftHandler := handler.NewShiftHandler(
+				queries,
+				pool,
+				func(db database.DBTX) handler.ShiftStore {
+					return database.New(db)
+				},
+			)
+			r.Route("/shifts", shiftHandler.RegisterRoutes)
+
+			// Promotions (managers configure, cashiers can look them up)
+			promotionHandler := handler.NewPromotionHandler(queries)
+			r.Route("/promotions", func(r chi.Router) {
+				promotionHandler.RegisterRoutes(r)
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RequireRole("OWNER", "MANAGER"))
+					promotionHandler.RegisterManageRoutes(r)
+				})
 			})
 
 			// Customers
-			customerHandler := handler.NewCustomerHandler(queries)
-			r.Route("/customers", customerHandler.RegisterRoutes)
+			customerHandler := handler.NewCustomerHandler(
+				queries,
+				pool,
+				func(db database.DBTX) handler.CustomerStore {
+					return database.New(db)
+				},
+			)
+			r.Route("/customers", func(r chi.Router) {
+				customerHandler.RegisterRoutes(r)
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RequireRole("OWNER", "MANAGER"))
+					customerHandler.RegisterManageRoutes(r)
+				})
+			})
 
 			// Reports (outlet-scoped)
 			reportsHandler := handler.NewReportsHandler(queries)
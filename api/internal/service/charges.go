@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// ChargeSettings is an outlet's PB1 tax and service charge configuration.
+// The zero value charges nothing.
+type ChargeSettings struct {
+	TaxRate                 decimal.Decimal // percent, e.g. 10 for PB1 10%
+	PricesIncludeTax        bool
+	TaxOrderTypes           []string
+	ServiceChargeRate       decimal.Decimal // percent
+	ServiceChargeOrderTypes []string
+	RoundingMode            string
+	RoundingUnit            decimal.Decimal
+}
+
+// ChargeSettingsFromDB converts an outlet_settings row into ChargeSettings.
+func ChargeSettingsFromDB(s database.OutletSetting) ChargeSettings {
+	return ChargeSettings{
+		TaxRate:                 numericToDecimal(s.TaxRate),
+		PricesIncludeTax:        s.PricesIncludeTax,
+		TaxOrderTypes:           s.TaxOrderTypes,
+		ServiceChargeRate:       numericToDecimal(s.ServiceChargeRate),
+		ServiceChargeOrderTypes: s.ServiceChargeOrderTypes,
+		RoundingMode:            s.RoundingMode,
+		RoundingUnit:            numericToDecimal(s.RoundingUnit),
+	}
+}
+
+// OrderCharges is the tax and service charge breakdown for an order.
+// Rates are zero when the charge does not apply to the order type.
+type OrderCharges struct {
+	ServiceChargeRate   decimal.Decimal
+	ServiceChargeAmount decimal.Decimal
+	TaxRate             decimal.Decimal
+	TaxAmount           decimal.Decimal
+	PricesIncludeTax    bool
+	TotalAmount         decimal.Decimal
+}
+
+// CalculateCharges computes service charge, tax, and total for an order whose
+// subtotal less discounts is net.
+//
+// Service charge is levied on the pre-tax amount and is itself taxed (PB1).
+// When prices include tax, the tax already contained in net is extracted
+// rather than added, so only the tax on the service charge raises the total.
+// Each charge is rounded to the outlet's rounding unit.
+func CalculateCharges(s ChargeSettings, orderType string, net decimal.Decimal) OrderCharges {
+	if net.IsNegative() {
+		net = decimal.Zero
+	}
+
+	c := OrderCharges{PricesIncludeTax: s.PricesIncludeTax}
+	if slices.Contains(s.TaxOrderTypes, orderType) {
+		c.TaxRate = s.TaxRate
+	}
+	if slices.Contains(s.ServiceChargeOrderTypes, orderType) {
+		c.ServiceChargeRate = s.ServiceChargeRate
+	}
+
+	if c.PricesIncludeTax && c.TaxRate.IsPositive() {
+		includedTax := s.round(net.Mul(c.TaxRate).Div(hundred.Add(c.TaxRate)))
+		base := net.Sub(includedTax)
+		c.ServiceChargeAmount = s.round(base.Mul(c.ServiceChargeRate).Div(hundred))
+		serviceTax := s.round(c.ServiceChargeAmount.Mul(c.TaxRate).Div(hundred))
+		c.TaxAmount = includedTax.Add(serviceTax)
+		c.TotalAmount = net.Add(c.ServiceChargeAmount).Add(serviceTax)
+		return c
+	}
+
+	c.ServiceChargeAmount = s.round(net.Mul(c.ServiceChargeRate).Div(hundred))
+	c.TaxAmount = s.round(net.Add(c.ServiceChargeAmount).Mul(c.TaxRate).Div(hundred))
+	c.TotalAmount = net.Add(c.ServiceChargeAmount).Add(c.TaxAmount)
+	return c
+}
+
+// round rounds d to a multiple of the rounding unit (default 1 rupiah).
+func (s ChargeSettings) round(d decimal.Decimal) decimal.Decimal {
+	unit := s.RoundingUnit
+	if !unit.IsPositive() {
+		unit = decimal.NewFromInt(1)
+	}
+	q := d.Div(unit)
+	switch s.RoundingMode {
+	case enum.RoundingModeUp:
+		q = q.Ceil()
+	case enum.RoundingModeDown:
+		q = q.Floor()
+	default:
+		q = q.Round(0)
+	}
+	return q.Mul(unit)
+}
+
+// ChargeStore defines the DB methods needed to recompute order charges.
+// Satisfied by *database.Queries (and its WithTx variant).
+type ChargeStore interface {
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	UpdateOrderCharges(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error)
+}
+
+// outletSettingsGetter is the subset of stores that can read outlet settings.
+type outletSettingsGetter interface {
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+}
+
+// LoadChargeSettings returns the outlet's charge settings, or the zero value
+// if the outlet has not configured any.
+func LoadChargeSettings(ctx context.Context, store outletSettingsGetter, outletID uuid.UUID) (ChargeSettings, error) {
+	settings, err := store.GetOutletSettings(ctx, outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ChargeSettings{}, nil
+		}
+		return ChargeSettings{}, fmt.Errorf("get outlet settings: %w", err)
+	}
+	return ChargeSettingsFromDB(settings), nil
+}
+
+// ApplyOrderCharges recomputes service charge, tax, and total for an order
+// whose subtotal and discount are already up to date (see UpdateOrderTotals).
+// The order is written only if the breakdown changed.
+func ApplyOrderCharges(ctx context.Context, store ChargeStore, order database.Order) (database.Order, error) {
+	settings, err := LoadChargeSettings(ctx, store, order.OutletID)
+	if err != nil {
+		return database.Order{}, err
+	}
+
+	net := numericToDecimal(order.Subtotal).Sub(numericToDecimal(order.DiscountAmount))
+	c := CalculateCharges(settings, order.OrderType, net)
+
+	if c.ServiceChargeAmount.Equal(numericToDecimal(order.ServiceChargeAmount)) &&
+		c.ServiceChargeRate.Equal(numericToDecimal(order.ServiceChargeRate)) &&
+		c.TaxAmount.Equal(numericToDecimal(order.TaxAmount)) &&
+		c.TaxRate.Equal(numericToDecimal(order.TaxRate)) &&
+		c.PricesIncludeTax == order.PricesIncludeTax &&
+		c.TotalAmount.Equal(numericToDecimal(order.TotalAmount)) {
+		return order, nil
+	}
+
+	updated, err := store.UpdateOrderCharges(ctx, database.UpdateOrderChargesParams{
+		ID:                  order.ID,
+		ServiceChargeAmount: decimalToNumeric(c.ServiceChargeAmount),
+		ServiceChargeRate:   decimalToNumeric(c.ServiceChargeRate),
+		TaxAmount:           decimalToNumeric(c.TaxAmount),
+		TaxRate:             decimalToNumeric(c.TaxRate),
+		PricesIncludeTax:    c.PricesIncludeTax,
+		TotalAmount:         decimalToNumeric(c.TotalAmount),
+	})
+	if err != nil {
+		return database.Order{}, fmt.Errorf("update order charges: %w", err)
+	}
+	return updated, nil
+}
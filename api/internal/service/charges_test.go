@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+func pb1Settings() ChargeSettings {
+	return ChargeSettings{
+		TaxRate:                 decimal.NewFromInt(10),
+		TaxOrderTypes:           []string{enum.OrderTypeDineIn, enum.OrderTypeTakeaway, enum.OrderTypeDelivery, enum.OrderTypeCatering},
+		ServiceChargeRate:       decimal.NewFromInt(5),
+		ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+		RoundingMode:            enum.RoundingModeHalfUp,
+		RoundingUnit:            decimal.NewFromInt(1),
+	}
+}
+
+func TestCalculateCharges(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  func() ChargeSettings
+		orderType string
+		net       string
+		service   string
+		tax       string
+		total     string
+	}{
+		{
+			name:      "zero settings charge nothing",
+			settings:  func() ChargeSettings { return ChargeSettings{} },
+			orderType: enum.OrderTypeDineIn,
+			net:       "50000",
+			service:   "0", tax: "0", total: "50000",
+		},
+		{
+			name:      "exclusive: service charge then tax on top",
+			settings:  pb1Settings,
+			orderType: enum.OrderTypeDineIn,
+			net:       "100000",
+			// service 5000, tax 10% of 105000
+			service: "5000", tax: "10500", total: "115500",
+		},
+		{
+			name:      "service charge not applicable to takeaway",
+			settings:  pb1Settings,
+			orderType: enum.OrderTypeTakeaway,
+			net:       "100000",
+			service:   "0", tax: "10000", total: "110000",
+		},
+		{
+			name: "tax not applicable to order type",
+			settings: func() ChargeSettings {
+				s := pb1Settings()
+				s.TaxOrderTypes = []string{enum.OrderTypeDineIn}
+				return s
+			},
+			orderType: enum.OrderTypeDelivery,
+			net:       "100000",
+			service:   "0", tax: "0", total: "100000",
+		},
+		{
+			name: "inclusive: tax extracted, service charge taxed on top",
+			settings: func() ChargeSettings {
+				s := pb1Settings()
+				s.PricesIncludeTax = true
+				return s
+			},
+			orderType: enum.OrderTypeDineIn,
+			net:       "110000",
+			// included tax 10000, base 100000, service 5000, service tax 500
+			service: "5000", tax: "10500", total: "115500",
+		},
+		{
+			name: "inclusive without service charge leaves total unchanged",
+			settings: func() ChargeSettings {
+				s := pb1Settings()
+				s.PricesIncludeTax = true
+				return s
+			},
+			orderType: enum.OrderTypeTakeaway,
+			net:       "25000",
+			// 25000 * 10/110 = 2272.72... -> 2273
+			service: "0", tax: "2273", total: "25000",
+		},
+		{
+			name: "round up to 100",
+			settings: func() ChargeSettings {
+				s := pb1Settings()
+				s.RoundingMode = enum.RoundingModeUp
+				s.RoundingUnit = decimal.NewFromInt(100)
+				return s
+			},
+			orderType: enum.OrderTypeDineIn,
+			net:       "33333",
+			// service 1666.65 -> 1700, tax 3503.3 -> 3600
+			service: "1700", tax: "3600", total: "38633",
+		},
+		{
+			name: "round down",
+			settings: func() ChargeSettings {
+				s := pb1Settings()
+				s.RoundingMode = enum.RoundingModeDown
+				return s
+			},
+			orderType: enum.OrderTypeDineIn,
+			net:       "33333",
+			// service 1666.65 -> 1666, tax 3499.9 -> 3499
+			service: "1666", tax: "3499", total: "38498",
+		},
+		{
+			name:      "negative net clamps to zero",
+			settings:  pb1Settings,
+			orderType: enum.OrderTypeDineIn,
+			net:       "-500",
+			service:   "0", tax: "0", total: "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := CalculateCharges(tt.settings(), tt.orderType, decimal.RequireFromString(tt.net))
+			if !c.ServiceChargeAmount.Equal(decimal.RequireFromString(tt.service)) {
+				t.Errorf("service charge: expected %s, got %s", tt.service, c.ServiceChargeAmount)
+			}
+			if !c.TaxAmount.Equal(decimal.RequireFromString(tt.tax)) {
+				t.Errorf("tax: expected %s, got %s", tt.tax, c.TaxAmount)
+			}
+			if !c.TotalAmount.Equal(decimal.RequireFromString(tt.total)) {
+				t.Errorf("total: expected %s, got %s", tt.total, c.TotalAmount)
+			}
+		})
+	}
+}
+
+func TestCalculateCharges_RatesZeroWhenNotApplicable(t *testing.T) {
+	c := CalculateCharges(pb1Settings(), enum.OrderTypeTakeaway, decimal.NewFromInt(1000))
+	if !c.ServiceChargeRate.IsZero() {
+		t.Errorf("expected service charge rate 0 for takeaway, got %s", c.ServiceChargeRate)
+	}
+	if !c.TaxRate.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected tax rate 10, got %s", c.TaxRate)
+	}
+}
+
+// mockChargeStore implements ChargeStore.
+type mockChargeStore struct {
+	settings    *database.OutletSetting
+	settingsErr error
+	updated     *database.UpdateOrderChargesParams
+}
+
+func (m *mockChargeStore) GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error) {
+	if m.settingsErr != nil {
+		return database.OutletSetting{}, m.settingsErr
+	}
+	if m.settings == nil {
+		return database.OutletSetting{}, pgx.ErrNoRows
+	}
+	return *m.settings, nil
+}
+
+func (m *mockChargeStore) UpdateOrderCharges(ctx context.Context, arg database.UpdateOrderChargesParams) (database.Order, error) {
+	m.updated = &arg
+	return database.Order{
+		ID:                  arg.ID,
+		ServiceChargeAmount: arg.ServiceChargeAmount,
+		ServiceChargeRate:   arg.ServiceChargeRate,
+		TaxAmount:           arg.TaxAmount,
+		TaxRate:             arg.TaxRate,
+		PricesIncludeTax:    arg.PricesIncludeTax,
+		TotalAmount:         arg.TotalAmount,
+	}, nil
+}
+
+func TestApplyOrderCharges_RecomputesFromSettings(t *testing.T) {
+	store := &mockChargeStore{settings: &database.OutletSetting{
+		TaxRate:                 makeNumeric("10"),
+		TaxOrderTypes:           []string{enum.OrderTypeDineIn},
+		ServiceChargeRate:       makeNumeric("5"),
+		ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+		RoundingMode:            enum.RoundingModeHalfUp,
+		RoundingUnit:            makeNumeric("1"),
+	}}
+	order := database.Order{
+		ID:             uuid.New(),
+		OutletID:       uuid.New(),
+		OrderType:      enum.OrderTypeDineIn,
+		Subtotal:       makeNumeric("120000"),
+		DiscountAmount: makeNumeric("20000"),
+		TaxAmount:      makeNumeric("0"),
+		TotalAmount:    makeNumeric("100000"),
+	}
+
+	updated, err := ApplyOrderCharges(context.Background(), store, order)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.updated == nil {
+		t.Fatal("expected UpdateOrderCharges to be called")
+	}
+	if !numericEquals(updated.ServiceChargeAmount, "5000") {
+		t.Errorf("expected service charge 5000, got %v", numericToDecimal(updated.ServiceChargeAmount))
+	}
+	if !numericEquals(updated.TaxAmount, "10500") {
+		t.Errorf("expected tax 10500, got %v", numericToDecimal(updated.TaxAmount))
+	}
+	if !numericEquals(updated.TotalAmount, "115500") {
+		t.Errorf("expected total 115500, got %v", numericToDecimal(updated.TotalAmount))
+	}
+}
+
+func TestApplyOrderCharges_NoSettingsNoWrite(t *testing.T) {
+	store := &mockChargeStore{}
+	order := database.Order{
+		ID:             uuid.New(),
+		OrderType:      enum.OrderTypeDineIn,
+		Subtotal:       makeNumeric("50000"),
+		DiscountAmount: makeNumeric("0"),
+		TotalAmount:    makeNumeric("50000"),
+	}
+
+	updated, err := ApplyOrderCharges(context.Background(), store, order)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.updated != nil {
+		t.Error("expected no write when charges are unchanged")
+	}
+	if updated.ID != order.ID {
+		t.Error("expected original order to be returned")
+	}
+}
+
+func TestApplyOrderCharges_SettingsError(t *testing.T) {
+	store := &mockChargeStore{settingsErr: errors.New("db down")}
+	_, err := ApplyOrderCharges(context.Background(), store, database.Order{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
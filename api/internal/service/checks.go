@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// SplitEvenly divides total into n check amounts. Every check but the last is
+// rounded down to a whole unit; the last absorbs the remainder so the amounts
+// always sum to total exactly.
+func SplitEvenly(total decimal.Decimal, n int) []decimal.Decimal {
+	if n <= 0 {
+		return nil
+	}
+	share := total.Div(decimal.NewFromInt(int64(n))).Floor()
+	amounts := make([]decimal.Decimal, n)
+	allocated := decimal.Zero
+	for i := 0; i < n-1; i++ {
+		amounts[i] = share
+		allocated = allocated.Add(share)
+	}
+	amounts[n-1] = total.Sub(allocated)
+	return amounts
+}
+
+// SplitByItems allocates total across checks in proportion to each check's
+// item subtotal, so order-level discounts, service charge, and tax are shared
+// pro rata. Rounding follows SplitEvenly: the last check absorbs the remainder.
+func SplitByItems(total decimal.Decimal, checkSubtotals []decimal.Decimal) []decimal.Decimal {
+	n := len(checkSubtotals)
+	if n == 0 {
+		return nil
+	}
+	sum := decimal.Zero
+	for _, s := range checkSubtotals {
+		sum = sum.Add(s)
+	}
+
+	amounts := make([]decimal.Decimal, n)
+	allocated := decimal.Zero
+	for i := 0; i < n-1; i++ {
+		share := decimal.Zero
+		if sum.IsPositive() {
+			share = total.Mul(checkSubtotals[i]).Div(sum).Floor()
+		}
+		amounts[i] = share
+		allocated = allocated.Add(share)
+	}
+	amounts[n-1] = total.Sub(allocated)
+	return amounts
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func sumDecimals(ds []decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, d := range ds {
+		total = total.Add(d)
+	}
+	return total
+}
+
+func TestSplitEvenly(t *testing.T) {
+	tests := []struct {
+		name  string
+		total string
+		n     int
+		want  []string
+	}{
+		{"divides exactly", "90000", 3, []string{"30000", "30000", "30000"}},
+		{"last check absorbs remainder", "100000", 3, []string{"33333", "33333", "33334"}},
+		{"fractional total", "1000.50", 2, []string{"500", "500.50"}},
+		{"single check", "25000", 1, []string{"25000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := decimal.RequireFromString(tt.total)
+			got := SplitEvenly(total, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d checks, got %d", len(tt.want), len(got))
+			}
+			for i, w := range tt.want {
+				if !got[i].Equal(decimal.RequireFromString(w)) {
+					t.Errorf("check %d: expected %s, got %s", i+1, w, got[i])
+				}
+			}
+			if !sumDecimals(got).Equal(total) {
+				t.Errorf("amounts sum to %s, want %s", sumDecimals(got), total)
+			}
+		})
+	}
+}
+
+func TestSplitEvenly_InvalidCount(t *testing.T) {
+	if got := SplitEvenly(decimal.NewFromInt(1000), 0); got != nil {
+		t.Errorf("expected nil for zero checks, got %v", got)
+	}
+}
+
+func TestSplitByItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     string
+		subtotals []string
+		want      []string
+	}{
+		{
+			name:      "no charges: amounts equal subtotals",
+			total:     "75000",
+			subtotals: []string{"25000", "50000"},
+			want:      []string{"25000", "50000"},
+		},
+		{
+			name:      "tax and service charge shared pro rata",
+			total:     "115500",
+			subtotals: []string{"40000", "60000"},
+			want:      []string{"46200", "69300"},
+		},
+		{
+			name:      "remainder goes to last check",
+			total:     "100000",
+			subtotals: []string{"10000", "10000", "10000"},
+			want:      []string{"33333", "33333", "33334"},
+		},
+		{
+			name:      "zero subtotal: last check takes total",
+			total:     "0",
+			subtotals: []string{"0", "0"},
+			want:      []string{"0", "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := decimal.RequireFromString(tt.total)
+			subtotals := make([]decimal.Decimal, len(tt.subtotals))
+			for i, s := range tt.subtotals {
+				subtotals[i] = decimal.RequireFromString(s)
+			}
+			got := SplitByItems(total, subtotals)
+			for i, w := range tt.want {
+				if !got[i].Equal(decimal.RequireFromString(w)) {
+					t.Errorf("check %d: expected %s, got %s", i+1, w, got[i])
+				}
+			}
+			if !sumDecimals(got).Equal(total) {
+				t.Errorf("amounts sum to %s, want %s", sumDecimals(got), total)
+			}
+		})
+	}
+}
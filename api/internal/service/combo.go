@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// ComboStore defines the DB methods needed to expand a combo line.
+type ComboStore interface {
+	ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error)
+	CreateOrderItem(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error)
+}
+
+// ExpandCombo inserts one child item per component of the combo line parent,
+// each routed to the component's own station. Children are priced at zero so
+// the order total is carried by the parent alone, and they inherit the
+// parent's notes so the kitchen sees them.
+func ExpandCombo(ctx context.Context, store ComboStore, parent database.OrderItem) ([]database.OrderItem, error) {
+	components, err := store.ListComboComponentsForOrder(ctx, parent.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("list combo components: %w", err)
+	}
+
+	zero := decimalToNumeric(decimal.Zero)
+	children := make([]database.OrderItem, 0, len(components))
+	for _, c := range components {
+		child, err := store.CreateOrderItem(ctx, database.CreateOrderItemParams{
+			OrderID:        parent.OrderID,
+			ProductID:      c.ProductID,
+			Quantity:       c.Quantity * parent.Quantity,
+			UnitPrice:      zero,
+			DiscountAmount: zero,
+			Subtotal:       zero,
+			Notes:          parent.Notes,
+			Station:        c.Station,
+			VariantIds:     []uuid.UUID{},
+			ParentItemID:   pgtype.UUID{Bytes: parent.ID, Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create combo component: %w", err)
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// ComboStatus derives a combo line's status from its components: READY once
+// every component is ready, PREPARING once any has started, PENDING otherwise.
+func ComboStatus(components []database.OrderItem) string {
+	ready, started := 0, 0
+	for _, c := range components {
+		switch c.Status {
+		case enum.OrderItemStatusReady:
+			ready++
+			started++
+		case enum.OrderItemStatusPreparing:
+			started++
+		}
+	}
+	switch {
+	case len(components) > 0 && ready == len(components):
+		return enum.OrderItemStatusReady
+	case started > 0:
+		return enum.OrderItemStatusPreparing
+	default:
+		return enum.OrderItemStatusPending
+	}
+}
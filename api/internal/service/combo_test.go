@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+func TestCreateOrder_ExpandsComboIntoComponents(t *testing.T) {
+	outletID := uuid.New()
+	comboID := uuid.New()
+	nasi, teh := uuid.New(), uuid.New()
+
+	store := defaultStore(outletID, comboID)
+	getProduct := store.getProductForOrderFn
+	store.getProductForOrderFn = func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error) {
+		p, err := getProduct(ctx, arg)
+		p.IsCombo = true
+		return p, err
+	}
+	store.listComboComponentsFn = func(ctx context.Context, id uuid.UUID) ([]database.ListComboComponentsForOrderRow, error) {
+		if id != comboID {
+			t.Errorf("combo id: got %s, want %s", id, comboID)
+		}
+		return []database.ListComboComponentsForOrderRow{
+			{ProductID: nasi, Quantity: 1, Station: pgtype.Text{String: enum.StationGrill, Valid: true}},
+			{ProductID: teh, Quantity: 2, Station: pgtype.Text{String: enum.StationBeverage, Valid: true}},
+		}, nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicReq(outletID, comboID.String())
+	req.Items[0].Notes = "tanpa sambal"
+
+	result, err := svc.CreateOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("items: got %d, want 3 (combo + 2 components)", len(result.Items))
+	}
+
+	parent := result.Items[0].Item
+	if parent.ParentItemID.Valid || !numericEquals(parent.Subtotal, "50000") {
+		t.Errorf("parent: got parent_item_id %v, subtotal %v", parent.ParentItemID, numericToDecimal(parent.Subtotal))
+	}
+	// Order total carries the combo price only
+	if !numericEquals(result.Order.Subtotal, "50000") {
+		t.Errorf("order subtotal: got %v, want 50000", numericToDecimal(result.Order.Subtotal))
+	}
+
+	for i, want := range []struct {
+		productID uuid.UUID
+		quantity  int32
+		station   string
+	}{
+		{nasi, 2, enum.StationGrill},
+		{teh, 4, enum.StationBeverage},
+	} {
+		child := result.Items[i+1].Item
+		if child.ParentItemID.Bytes != parent.ID || !child.ParentItemID.Valid {
+			t.Errorf("component %d: not linked to parent", i)
+		}
+		if child.ProductID != want.productID || child.Quantity != want.quantity || child.Station.String != want.station {
+			t.Errorf("component %d: got product %s qty %d station %s", i, child.ProductID, child.Quantity, child.Station.String)
+		}
+		if !numericEquals(child.Subtotal, "0") || !numericEquals(child.UnitPrice, "0") {
+			t.Errorf("component %d: should be unpriced, got subtotal %v", i, numericToDecimal(child.Subtotal))
+		}
+		if child.Notes.String != "tanpa sambal" {
+			t.Errorf("component %d: notes got %q", i, child.Notes.String)
+		}
+	}
+}
+
+func TestComboStatus(t *testing.T) {
+	items := func(statuses ...string) []database.OrderItem {
+		out := make([]database.OrderItem, len(statuses))
+		for i, s := range statuses {
+			out[i] = database.OrderItem{Status: s}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		components []database.OrderItem
+		want       string
+	}{
+		{"none started", items(enum.OrderItemStatusPending, enum.OrderItemStatusPending), enum.OrderItemStatusPending},
+		{"one preparing", items(enum.OrderItemStatusPreparing, enum.OrderItemStatusPending), enum.OrderItemStatusPreparing},
+		{"one ready", items(enum.OrderItemStatusReady, enum.OrderItemStatusPending), enum.OrderItemStatusPreparing},
+		{"all ready", items(enum.OrderItemStatusReady, enum.OrderItemStatusReady), enum.OrderItemStatusReady},
+		{"no components", nil, enum.OrderItemStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComboStatus(tt.components); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+// KitchenTicketStore defines the DB methods needed to print kitchen tickets.
+type KitchenTicketStore interface {
+	ListKitchenTicketItems(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error)
+	ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error)
+	CreateKitchenTicket(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error)
+}
+
+// KitchenTicketLine is one dish printed on a kitchen ticket. On change
+// tickets, Change says what happened to it since the last ticket; voided
+// lines carry the quantity that was cancelled.
+type KitchenTicketLine struct {
+	ItemID           uuid.UUID               `json:"item_id"`
+	Change           string                  `json:"change"`
+	Name             string                  `json:"name"`
+	Variants         []string                `json:"variants"`
+	Modifiers        []KitchenTicketModifier `json:"modifiers"`
+	Quantity         int32                   `json:"quantity"`
+	PreviousQuantity int32                   `json:"previous_quantity,omitempty"`
+	Notes            string                  `json:"notes,omitempty"`
+}
+
+// KitchenTicketModifier is a modifier printed under its dish.
+type KitchenTicketModifier struct {
+	Name     string `json:"name"`
+	Quantity int32  `json:"quantity"`
+}
+
+// DecodeKitchenTicketLines reads the lines stored with a ticket.
+func DecodeKitchenTicketLines(ticket database.KitchenTicket) ([]KitchenTicketLine, error) {
+	var lines []KitchenTicketLine
+	if err := json.Unmarshal(ticket.Lines, &lines); err != nil {
+		return nil, fmt.Errorf("decode kitchen ticket %s lines: %w", ticket.ID, err)
+	}
+	return lines, nil
+}
+
+// SendKitchenTickets prints what the kitchen hasn't been told about the
+// order yet: one ticket per station with new, re-quantified or voided dishes,
+// compared against the lines of the order's earlier tickets. A station's
+// first ticket for the order is NEW; later ones are CHANGE tickets holding
+// only the delta. A dish whose printed name, modifiers or notes changed is
+// voided and added again. Returns no tickets when nothing changed.
+//
+// Call it with a transaction-scoped store after the order row has been
+// written in the same transaction, so concurrent changes to one order are
+// ticketed one after the other.
+func SendKitchenTickets(ctx context.Context, store KitchenTicketStore, orderID, actorID uuid.UUID) ([]database.KitchenTicket, error) {
+	items, err := store.ListKitchenTicketItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list kitchen ticket items: %w", err)
+	}
+	return printKitchenTickets(ctx, store, orderID, actorID, items)
+}
+
+// VoidKitchenTickets prints a CHANGE ticket to every station still holding
+// dishes of the order, voiding all of them. Used when the order is
+// cancelled. Returns no tickets when nothing was sent to the kitchen.
+func VoidKitchenTickets(ctx context.Context, store KitchenTicketStore, orderID, actorID uuid.UUID) ([]database.KitchenTicket, error) {
+	return printKitchenTickets(ctx, store, orderID, actorID, nil)
+}
+
+// printKitchenTickets tickets the difference between the order's earlier
+// tickets and items, the dishes the kitchen should now be making.
+func printKitchenTickets(ctx context.Context, store KitchenTicketStore, orderID, actorID uuid.UUID, items []database.ListKitchenTicketItemsRow) ([]database.KitchenTicket, error) {
+	previous, err := store.ListKitchenTickets(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list kitchen tickets: %w", err)
+	}
+
+	// Replay earlier tickets into what the kitchen currently holds
+	type printedLine struct {
+		line    KitchenTicketLine
+		station string
+	}
+	printed := make(map[uuid.UUID]printedLine)
+	var printedOrder []uuid.UUID
+	ticketed := make(map[string]bool)
+	for _, t := range previous {
+		lines, err := DecodeKitchenTicketLines(t)
+		if err != nil {
+			return nil, err
+		}
+		ticketed[t.Station.String] = true
+		for _, l := range lines {
+			if l.Change == enum.KitchenLineVoided {
+				delete(printed, l.ItemID)
+				continue
+			}
+			if !slices.Contains(printedOrder, l.ItemID) {
+				printedOrder = append(printedOrder, l.ItemID)
+			}
+			printed[l.ItemID] = printedLine{line: l, station: t.Station.String}
+		}
+	}
+
+	changes := make(map[string][]KitchenTicketLine)
+	add := func(station string, l KitchenTicketLine) {
+		changes[station] = append(changes[station], l)
+	}
+
+	current := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		current[item.ID] = true
+	}
+
+	// Removed dishes first, so a cancellation is the first thing read
+	for _, id := range printedOrder {
+		p, ok := printed[id]
+		if ok && !current[id] {
+			void := p.line
+			void.Change = enum.KitchenLineVoided
+			void.PreviousQuantity = 0
+			add(p.station, void)
+		}
+	}
+
+	for _, item := range items {
+		line := kitchenTicketLine(item)
+		station := item.Station.String
+		p, ok := printed[item.ID]
+		switch {
+		case !ok:
+			add(station, line)
+		case !samePrintedDish(p.line, line):
+			void := p.line
+			void.Change = enum.KitchenLineVoided
+			void.PreviousQuantity = 0
+			add(p.station, void)
+			add(station, line)
+		case p.line.Quantity != line.Quantity:
+			line.Change = enum.KitchenLineQuantityChanged
+			line.PreviousQuantity = p.line.Quantity
+			add(station, line)
+		}
+	}
+
+	stations := make([]string, 0, len(changes))
+	for station := range changes {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+
+	var tickets []database.KitchenTicket
+	for _, station := range stations {
+		ticketType := enum.KitchenTicketTypeNew
+		if ticketed[station] {
+			ticketType = enum.KitchenTicketTypeChange
+		}
+		lines, err := json.Marshal(changes[station])
+		if err != nil {
+			return nil, fmt.Errorf("marshal kitchen ticket lines: %w", err)
+		}
+		ticket, err := store.CreateKitchenTicket(ctx, database.CreateKitchenTicketParams{
+			OrderID:    orderID,
+			Station:    pgtype.Text{String: station, Valid: station != ""},
+			TicketType: ticketType,
+			Lines:      lines,
+			CreatedBy:  actorID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create kitchen ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+func kitchenTicketLine(item database.ListKitchenTicketItemsRow) KitchenTicketLine {
+	line := KitchenTicketLine{
+		ItemID:    item.ID,
+		Change:    enum.KitchenLineAdded,
+		Name:      item.ProductName,
+		Variants:  item.VariantNames,
+		Modifiers: make([]KitchenTicketModifier, len(item.ModifierNames)),
+		Quantity:  item.Quantity,
+	}
+	for i, name := range item.ModifierNames {
+		line.Modifiers[i] = KitchenTicketModifier{Name: name}
+		if i < len(item.ModifierQuantities) {
+			line.Modifiers[i].Quantity = item.ModifierQuantities[i]
+		}
+	}
+	if item.Notes.Valid {
+		line.Notes = item.Notes.String
+	}
+	return line
+}
+
+// samePrintedDish reports whether two lines print the same dish, ignoring quantity.
+func samePrintedDish(a, b KitchenTicketLine) bool {
+	return a.Name == b.Name &&
+		a.Notes == b.Notes &&
+		slices.Equal(a.Variants, b.Variants) &&
+		slices.Equal(a.Modifiers, b.Modifiers)
+}
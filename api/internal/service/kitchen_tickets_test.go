@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+// mockKitchenTicketStore keeps tickets in memory, numbered like the database does.
+type mockKitchenTicketStore struct {
+	items   []database.ListKitchenTicketItemsRow
+	tickets []database.KitchenTicket
+}
+
+func (m *mockKitchenTicketStore) ListKitchenTicketItems(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error) {
+	return m.items, nil
+}
+
+func (m *mockKitchenTicketStore) ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+	return m.tickets, nil
+}
+
+func (m *mockKitchenTicketStore) CreateKitchenTicket(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+	t := database.KitchenTicket{
+		ID:         uuid.New(),
+		OrderID:    arg.OrderID,
+		Sequence:   int32(len(m.tickets) + 1),
+		Station:    arg.Station,
+		TicketType: arg.TicketType,
+		Lines:      arg.Lines,
+		CreatedBy:  arg.CreatedBy,
+	}
+	m.tickets = append(m.tickets, t)
+	return t, nil
+}
+
+func ticketItem(name, station string, qty int32) database.ListKitchenTicketItemsRow {
+	return database.ListKitchenTicketItemsRow{
+		ID:           uuid.New(),
+		ProductName:  name,
+		VariantNames: []string{},
+		Quantity:     qty,
+		Station:      pgtype.Text{String: station, Valid: station != ""},
+	}
+}
+
+func decodeLines(t *testing.T, ticket database.KitchenTicket) []KitchenTicketLine {
+	t.Helper()
+	lines, err := DecodeKitchenTicketLines(ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestSendKitchenTickets_FirstTicketsPerStation(t *testing.T) {
+	store := &mockKitchenTicketStore{items: []database.ListKitchenTicketItemsRow{
+		ticketItem("Nasi Bakar", enum.StationGrill, 2),
+		ticketItem("Es Teh", enum.StationBeverage, 1),
+		ticketItem("Ayam Bakar", enum.StationGrill, 1),
+	}}
+
+	tickets, err := SendKitchenTickets(context.Background(), store, uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("tickets: got %d, want 2 (one per station)", len(tickets))
+	}
+	// Stations in name order, numbered in sequence
+	if tickets[0].Station.String != enum.StationBeverage || tickets[1].Station.String != enum.StationGrill {
+		t.Errorf("stations: got %s, %s", tickets[0].Station.String, tickets[1].Station.String)
+	}
+	if tickets[1].Sequence != 2 {
+		t.Errorf("sequence: got %d, want 2", tickets[1].Sequence)
+	}
+	for _, ticket := range tickets {
+		if ticket.TicketType != enum.KitchenTicketTypeNew {
+			t.Errorf("ticket type: got %s, want NEW", ticket.TicketType)
+		}
+	}
+	if lines := decodeLines(t, tickets[1]); len(lines) != 2 || lines[0].Name != "Nasi Bakar" || lines[0].Quantity != 2 {
+		t.Errorf("grill lines: got %+v", lines)
+	}
+}
+
+func TestSendKitchenTickets_ChangeTicketHoldsOnlyDelta(t *testing.T) {
+	nasi := ticketItem("Nasi Bakar", enum.StationGrill, 2)
+	ayam := ticketItem("Ayam Bakar", enum.StationGrill, 1)
+	sate := ticketItem("Sate", enum.StationGrill, 1)
+	teh := ticketItem("Es Teh", enum.StationBeverage, 1)
+	store := &mockKitchenTicketStore{items: []database.ListKitchenTicketItemsRow{nasi, ayam, sate, teh}}
+	if _, err := SendKitchenTickets(context.Background(), store, uuid.New(), uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nasi goes to 3, ayam is removed, sate gets a note, a new dish is added;
+	// nothing changes at the beverage station
+	nasi.Quantity = 3
+	sate.Notes = pgtype.Text{String: "extra pedas", Valid: true}
+	tahu := ticketItem("Tahu Bakar", enum.StationGrill, 1)
+	store.items = []database.ListKitchenTicketItemsRow{nasi, sate, teh, tahu}
+
+	tickets, err := SendKitchenTickets(context.Background(), store, uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Fatalf("tickets: got %d, want 1 (grill only)", len(tickets))
+	}
+	if tickets[0].TicketType != enum.KitchenTicketTypeChange || tickets[0].Sequence != 3 {
+		t.Errorf("ticket: got %s #%d, want CHANGE #3", tickets[0].TicketType, tickets[0].Sequence)
+	}
+
+	lines := decodeLines(t, tickets[0])
+	want := []struct {
+		name     string
+		change   string
+		quantity int32
+	}{
+		{"Ayam Bakar", enum.KitchenLineVoided, 1},
+		{"Nasi Bakar", enum.KitchenLineQuantityChanged, 3},
+		{"Sate", enum.KitchenLineVoided, 1},
+		{"Sate", enum.KitchenLineAdded, 1},
+		{"Tahu Bakar", enum.KitchenLineAdded, 1},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("lines: got %+v", lines)
+	}
+	for i, w := range want {
+		if lines[i].Name != w.name || lines[i].Change != w.change || lines[i].Quantity != w.quantity {
+			t.Errorf("line %d: got %s %s x%d, want %s %s x%d", i, lines[i].Name, lines[i].Change, lines[i].Quantity, w.name, w.change, w.quantity)
+		}
+	}
+	if lines[1].PreviousQuantity != 2 {
+		t.Errorf("previous quantity: got %d, want 2", lines[1].PreviousQuantity)
+	}
+
+	// Nothing changed since: no tickets
+	tickets, err = SendKitchenTickets(context.Background(), store, uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 0 {
+		t.Errorf("tickets: got %d, want none", len(tickets))
+	}
+}
+
+func TestVoidKitchenTickets_VoidsEverythingPrinted(t *testing.T) {
+	nasi := ticketItem("Nasi Bakar", enum.StationGrill, 2)
+	teh := ticketItem("Es Teh", enum.StationBeverage, 1)
+	store := &mockKitchenTicketStore{items: []database.ListKitchenTicketItemsRow{nasi, teh}}
+	if _, err := SendKitchenTickets(context.Background(), store, uuid.New(), uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The items are still on the order; voiding ignores them
+	tickets, err := VoidKitchenTickets(context.Background(), store, uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("tickets: got %d, want 2 (one per station)", len(tickets))
+	}
+	for _, ticket := range tickets {
+		lines := decodeLines(t, ticket)
+		if ticket.TicketType != enum.KitchenTicketTypeChange || len(lines) != 1 || lines[0].Change != enum.KitchenLineVoided {
+			t.Errorf("%s ticket: got %s %+v, want one voided line", ticket.Station.String, ticket.TicketType, lines)
+		}
+	}
+
+	// Nothing left to void the second time
+	tickets, err = VoidKitchenTickets(context.Background(), store, uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 0 {
+		t.Errorf("tickets: got %d, want none", len(tickets))
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// Errors returned when a POINTS payment cannot be taken.
+var (
+	ErrLoyaltyDisabled    = errors.New("loyalty points cannot be redeemed at this outlet")
+	ErrPointsNoCustomer   = errors.New("order has no customer to redeem points from")
+	ErrInsufficientPoints = errors.New("customer does not have enough points")
+)
+
+// LoyaltySettingsStore reads an outlet's loyalty settings.
+type LoyaltySettingsStore interface {
+	GetLoyaltySettings(ctx context.Context, outletID uuid.UUID) (database.LoyaltySetting, error)
+}
+
+// LoyaltyStore defines the DB methods needed to earn, redeem and reverse
+// loyalty points. Satisfied by *database.Queries (and its WithTx variant).
+type LoyaltyStore interface {
+	LoyaltySettingsStore
+	GetCustomerLoyalty(ctx context.Context, customerID uuid.UUID) (database.GetCustomerLoyaltyRow, error)
+	LockCustomer(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	ExpireCustomerPoints(ctx context.Context, customerID uuid.UUID) (int64, error)
+	CreateLoyaltyTransaction(ctx context.Context, arg database.CreateLoyaltyTransactionParams) (database.LoyaltyTransaction, error)
+	GetOrderEligibleSpend(ctx context.Context, orderID uuid.UUID) (pgtype.Numeric, error)
+	GetOrderEarnedPoints(ctx context.Context, orderID uuid.UUID) (database.GetOrderEarnedPointsRow, error)
+	GetPaymentLoyaltyPoints(ctx context.Context, paymentID uuid.UUID) (database.GetPaymentLoyaltyPointsRow, error)
+	ListOrderRedeemedPoints(ctx context.Context, orderID uuid.UUID) ([]database.ListOrderRedeemedPointsRow, error)
+}
+
+// LoyaltyProgram is an outlet's loyalty settings, parsed.
+type LoyaltyProgram struct {
+	EarnRates        map[string]decimal.Decimal // order type -> spend per point
+	PointValue       decimal.Decimal            // zero when points cannot be redeemed
+	ExpiryDays       int32                      // zero when points never expire
+	SilverMinSpend   decimal.Decimal            // zero when the tier is not offered
+	SilverMultiplier decimal.Decimal
+	GoldMinSpend     decimal.Decimal
+	GoldMultiplier   decimal.Decimal
+}
+
+// NewLoyaltyProgram parses stored loyalty settings.
+func NewLoyaltyProgram(s database.LoyaltySetting) (*LoyaltyProgram, error) {
+	var rates map[string]string
+	if err := json.Unmarshal(s.EarnRates, &rates); err != nil {
+		return nil, fmt.Errorf("parse earn rates: %w", err)
+	}
+	p := &LoyaltyProgram{
+		EarnRates:        make(map[string]decimal.Decimal, len(rates)),
+		PointValue:       numericToDecimal(s.PointValue),
+		SilverMinSpend:   numericToDecimal(s.SilverMinSpend),
+		SilverMultiplier: numericToDecimal(s.SilverMultiplier),
+		GoldMinSpend:     numericToDecimal(s.GoldMinSpend),
+		GoldMultiplier:   numericToDecimal(s.GoldMultiplier),
+	}
+	for orderType, rate := range rates {
+		d, err := decimal.NewFromString(rate)
+		if err != nil {
+			return nil, fmt.Errorf("parse earn rate for %s: %w", orderType, err)
+		}
+		p.EarnRates[orderType] = d
+	}
+	if s.PointsExpiryDays.Valid {
+		p.ExpiryDays = s.PointsExpiryDays.Int32
+	}
+	return p, nil
+}
+
+// LoadLoyaltyProgram returns the outlet's loyalty program, or nil when the
+// outlet has none or it is disabled.
+func LoadLoyaltyProgram(ctx context.Context, store LoyaltySettingsStore, outletID uuid.UUID) (*LoyaltyProgram, error) {
+	settings, err := store.GetLoyaltySettings(ctx, outletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get loyalty settings: %w", err)
+	}
+	if !settings.IsEnabled {
+		return nil, nil
+	}
+	return NewLoyaltyProgram(settings)
+}
+
+// Tier returns the tier a customer with the given lifetime spend is in.
+func (p *LoyaltyProgram) Tier(lifetimeSpend decimal.Decimal) string {
+	switch {
+	case p.GoldMinSpend.IsPositive() && lifetimeSpend.GreaterThanOrEqual(p.GoldMinSpend):
+		return enum.LoyaltyTierGold
+	case p.SilverMinSpend.IsPositive() && lifetimeSpend.GreaterThanOrEqual(p.SilverMinSpend):
+		return enum.LoyaltyTierSilver
+	}
+	return enum.LoyaltyTierMember
+}
+
+// PointsEarned returns the points spend earns on an order of orderType for a
+// customer in tier. Part points are dropped.
+func (p *LoyaltyProgram) PointsEarned(orderType string, spend decimal.Decimal, tier string) int32 {
+	rate, ok := p.EarnRates[orderType]
+	if !ok || !rate.IsPositive() || !spend.IsPositive() {
+		return 0
+	}
+	points := spend.Div(rate)
+	switch tier {
+	case enum.LoyaltyTierGold:
+		points = points.Mul(p.GoldMultiplier)
+	case enum.LoyaltyTierSilver:
+		points = points.Mul(p.SilverMultiplier)
+	}
+	return int32(points.Floor().IntPart())
+}
+
+// PointsToRedeem returns the points needed to pay amount. Part points are
+// rounded up so a payment is never worth more than the points spent.
+func (p *LoyaltyProgram) PointsToRedeem(amount decimal.Decimal) int32 {
+	return int32(amount.Div(p.PointValue).Ceil().IntPart())
+}
+
+// EarnOrderPoints credits the customer of a just-completed order with points
+// for what they paid other than with points. Orders without a customer, at
+// outlets without a loyalty program, or that already earned, earn nothing.
+func EarnOrderPoints(ctx context.Context, store LoyaltyStore, order database.Order, actorID uuid.UUID) error {
+	if !order.CustomerID.Valid {
+		return nil
+	}
+	program, err := LoadLoyaltyProgram(ctx, store, order.OutletID)
+	if err != nil || program == nil {
+		return err
+	}
+	if _, ok := program.EarnRates[order.OrderType]; !ok {
+		return nil
+	}
+	customerID := uuid.UUID(order.CustomerID.Bytes)
+
+	spend, err := store.GetOrderEligibleSpend(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("get eligible spend: %w", err)
+	}
+	customer, err := store.GetCustomerLoyalty(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("get customer loyalty: %w", err)
+	}
+	tier := program.Tier(numericToDecimal(customer.LifetimeSpend))
+	points := program.PointsEarned(order.OrderType, numericToDecimal(spend), tier)
+	if points <= 0 {
+		return nil
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if program.ExpiryDays > 0 {
+		expiresAt = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, int(program.ExpiryDays)), Valid: true}
+	}
+	_, err = store.CreateLoyaltyTransaction(ctx, database.CreateLoyaltyTransactionParams{
+		CustomerID:      customerID,
+		OrderID:         pgtype.UUID{Bytes: order.ID, Valid: true},
+		TransactionType: enum.LoyaltyTransactionEarn,
+		Points:          points,
+		Amount:          spend,
+		ExpiresAt:       expiresAt,
+		CreatedBy:       pgtype.UUID{Bytes: actorID, Valid: true},
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("create earn transaction: %w", err)
+	}
+	return nil
+}
+
+// RedeemPoints debits the points paying for a POINTS payment from the
+// order's customer, after expiring any points past their expiry. Returns the
+// points redeemed. Must run in the payment's transaction so that a failed
+// redemption rolls the payment back.
+func RedeemPoints(ctx context.Context, store LoyaltyStore, order database.Order, payment database.Payment, actorID uuid.UUID) (int32, error) {
+	if !order.CustomerID.Valid {
+		return 0, ErrPointsNoCustomer
+	}
+	program, err := LoadLoyaltyProgram(ctx, store, order.OutletID)
+	if err != nil {
+		return 0, err
+	}
+	if program == nil || !program.PointValue.IsPositive() {
+		return 0, ErrLoyaltyDisabled
+	}
+	customerID := uuid.UUID(order.CustomerID.Bytes)
+
+	if _, err := store.LockCustomer(ctx, customerID); err != nil {
+		return 0, fmt.Errorf("lock customer: %w", err)
+	}
+	if _, err := store.ExpireCustomerPoints(ctx, customerID); err != nil {
+		return 0, fmt.Errorf("expire points: %w", err)
+	}
+	customer, err := store.GetCustomerLoyalty(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("get customer loyalty: %w", err)
+	}
+
+	points := program.PointsToRedeem(numericToDecimal(payment.Amount))
+	if customer.PointsBalance < points {
+		return 0, fmt.Errorf("%w: %d needed, %d available", ErrInsufficientPoints, points, customer.PointsBalance)
+	}
+
+	_, err = store.CreateLoyaltyTransaction(ctx, database.CreateLoyaltyTransactionParams{
+		CustomerID:      customerID,
+		OrderID:         pgtype.UUID{Bytes: order.ID, Valid: true},
+		PaymentID:       pgtype.UUID{Bytes: payment.ID, Valid: true},
+		TransactionType: enum.LoyaltyTransactionRedeem,
+		Points:          -points,
+		Amount:          payment.Amount,
+		CreatedBy:       pgtype.UUID{Bytes: actorID, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create redeem transaction: %w", err)
+	}
+	return points, nil
+}
+
+// ReverseRefundedPoints adjusts points after amount of payment was refunded.
+// A refunded POINTS payment gives its points back in proportion. Any other
+// refund takes back the points the order earned on the refunded spend.
+// payment is the payment after the refund was recorded.
+func ReverseRefundedPoints(ctx context.Context, store LoyaltyStore, order database.Order, payment database.Payment, amount decimal.Decimal, actorID uuid.UUID) error {
+	if !order.CustomerID.Valid {
+		return nil
+	}
+	customerID := uuid.UUID(order.CustomerID.Bytes)
+
+	if payment.PaymentMethod == enum.PaymentMethodPoints {
+		redeemed, err := store.GetPaymentLoyaltyPoints(ctx, payment.ID)
+		if err != nil {
+			return fmt.Errorf("get payment points: %w", err)
+		}
+		paid := numericToDecimal(payment.Amount)
+		back := redeemed.OutstandingPoints
+		if numericToDecimal(payment.RefundedAmount).LessThan(paid) {
+			share := decimal.NewFromInt32(redeemed.RedeemedPoints).Mul(amount).Div(paid)
+			back = min(back, int32(share.Round(0).IntPart()))
+		}
+		return returnRedeemedPoints(ctx, store, customerID, order.ID, payment.ID, back, amount, actorID)
+	}
+
+	earned, err := store.GetOrderEarnedPoints(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("get order earned points: %w", err)
+	}
+	earnAmount := numericToDecimal(earned.EarnAmount)
+	if earned.EarnedPoints <= 0 || !earnAmount.IsPositive() {
+		return nil
+	}
+	spend, err := store.GetOrderEligibleSpend(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("get eligible spend: %w", err)
+	}
+	keep := decimal.NewFromInt32(earned.EarnedPoints).Mul(numericToDecimal(spend)).Div(earnAmount)
+	reverse := earned.EarnedPoints - earned.ReversedPoints - int32(keep.Floor().IntPart())
+	if reverse <= 0 {
+		return nil
+	}
+	_, err = store.CreateLoyaltyTransaction(ctx, database.CreateLoyaltyTransactionParams{
+		CustomerID:      customerID,
+		OrderID:         pgtype.UUID{Bytes: order.ID, Valid: true},
+		PaymentID:       pgtype.UUID{Bytes: payment.ID, Valid: true},
+		TransactionType: enum.LoyaltyTransactionEarnReversal,
+		Points:          -reverse,
+		Amount:          decimalToNumeric(amount),
+		ExpiresAt:       earned.EarnExpiresAt,
+		CreatedBy:       pgtype.UUID{Bytes: actorID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create earn reversal: %w", err)
+	}
+	return nil
+}
+
+// ReturnOrderPoints gives back the points redeemed on a cancelled order.
+func ReturnOrderPoints(ctx context.Context, store LoyaltyStore, order database.Order, actorID uuid.UUID) error {
+	if !order.CustomerID.Valid {
+		return nil
+	}
+	redeemed, err := store.ListOrderRedeemedPoints(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("list redeemed points: %w", err)
+	}
+	for _, r := range redeemed {
+		if err := returnRedeemedPoints(ctx, store, uuid.UUID(order.CustomerID.Bytes), order.ID, r.PaymentID, r.OutstandingPoints, numericToDecimal(r.OutstandingAmount), actorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returnRedeemedPoints credits back points redeemed by a POINTS payment,
+// worth value of the payment.
+func returnRedeemedPoints(ctx context.Context, store LoyaltyStore, customerID, orderID, paymentID uuid.UUID, points int32, value decimal.Decimal, actorID uuid.UUID) error {
+	if points <= 0 {
+		return nil
+	}
+	_, err := store.CreateLoyaltyTransaction(ctx, database.CreateLoyaltyTransactionParams{
+		CustomerID:      customerID,
+		OrderID:         pgtype.UUID{Bytes: orderID, Valid: true},
+		PaymentID:       pgtype.UUID{Bytes: paymentID, Valid: true},
+		TransactionType: enum.LoyaltyTransactionRedeemReversal,
+		Points:          points,
+		Amount:          decimalToNumeric(value),
+		CreatedBy:       pgtype.UUID{Bytes: actorID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create redeem reversal: %w", err)
+	}
+	return nil
+}
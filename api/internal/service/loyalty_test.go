@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// fakeLoyaltyStore is an in-memory points ledger for one order. It is
+// embedded in mockOrderStore; the zero value has no loyalty program.
+type fakeLoyaltyStore struct {
+	settings      *database.LoyaltySetting
+	txns          []database.CreateLoyaltyTransactionParams
+	eligibleSpend decimal.Decimal
+}
+
+func (f *fakeLoyaltyStore) GetLoyaltySettings(_ context.Context, _ uuid.UUID) (database.LoyaltySetting, error) {
+	if f.settings == nil {
+		return database.LoyaltySetting{}, pgx.ErrNoRows
+	}
+	return *f.settings, nil
+}
+
+func (f *fakeLoyaltyStore) GetCustomerLoyalty(_ context.Context, _ uuid.UUID) (database.GetCustomerLoyaltyRow, error) {
+	var balance int32
+	for _, t := range f.txns {
+		balance += t.Points
+	}
+	return database.GetCustomerLoyaltyRow{PointsBalance: balance, LifetimeSpend: decimalToNumeric(decimal.Zero)}, nil
+}
+
+func (f *fakeLoyaltyStore) LockCustomer(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	return id, nil
+}
+
+func (f *fakeLoyaltyStore) ExpireCustomerPoints(_ context.Context, _ uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeLoyaltyStore) CreateLoyaltyTransaction(_ context.Context, arg database.CreateLoyaltyTransactionParams) (database.LoyaltyTransaction, error) {
+	f.txns = append(f.txns, arg)
+	return database.LoyaltyTransaction{ID: uuid.New(), TransactionType: arg.TransactionType, Points: arg.Points}, nil
+}
+
+func (f *fakeLoyaltyStore) GetOrderEligibleSpend(_ context.Context, _ uuid.UUID) (pgtype.Numeric, error) {
+	return decimalToNumeric(f.eligibleSpend), nil
+}
+
+func (f *fakeLoyaltyStore) GetOrderEarnedPoints(_ context.Context, _ uuid.UUID) (database.GetOrderEarnedPointsRow, error) {
+	var row database.GetOrderEarnedPointsRow
+	row.EarnAmount = decimalToNumeric(decimal.Zero)
+	for _, t := range f.txns {
+		switch t.TransactionType {
+		case enum.LoyaltyTransactionEarn:
+			row.EarnedPoints += t.Points
+			row.EarnAmount = t.Amount
+		case enum.LoyaltyTransactionEarnReversal:
+			row.ReversedPoints -= t.Points
+		}
+	}
+	return row, nil
+}
+
+func (f *fakeLoyaltyStore) GetPaymentLoyaltyPoints(_ context.Context, paymentID uuid.UUID) (database.GetPaymentLoyaltyPointsRow, error) {
+	var row database.GetPaymentLoyaltyPointsRow
+	for _, t := range f.txns {
+		if uuid.UUID(t.PaymentID.Bytes) != paymentID {
+			continue
+		}
+		if t.TransactionType == enum.LoyaltyTransactionRedeem {
+			row.RedeemedPoints -= t.Points
+		}
+		row.OutstandingPoints -= t.Points
+	}
+	return row, nil
+}
+
+func (f *fakeLoyaltyStore) ListOrderRedeemedPoints(_ context.Context, _ uuid.UUID) ([]database.ListOrderRedeemedPointsRow, error) {
+	return []database.ListOrderRedeemedPointsRow{}, nil
+}
+
+func testLoyaltyProgram() *LoyaltyProgram {
+	return &LoyaltyProgram{
+		EarnRates:        map[string]decimal.Decimal{enum.OrderTypeDineIn: decimal.NewFromInt(10000)},
+		PointValue:       decimal.NewFromInt(100),
+		SilverMinSpend:   decimal.NewFromInt(1_000_000),
+		SilverMultiplier: decimal.RequireFromString("1.5"),
+		GoldMinSpend:     decimal.NewFromInt(5_000_000),
+		GoldMultiplier:   decimal.NewFromInt(2),
+	}
+}
+
+func TestLoyaltyProgram_TiersAndEarning(t *testing.T) {
+	p := testLoyaltyProgram()
+	spend := decimal.NewFromInt(95000)
+
+	tests := []struct {
+		name      string
+		orderType string
+		lifetime  int64
+		wantTier  string
+		want      int32
+	}{
+		{"member drops part points", enum.OrderTypeDineIn, 0, enum.LoyaltyTierMember, 9},
+		{"silver multiplies", enum.OrderTypeDineIn, 1_000_000, enum.LoyaltyTierSilver, 14},
+		{"gold multiplies", enum.OrderTypeDineIn, 7_500_000, enum.LoyaltyTierGold, 19},
+		{"order type without a rate", enum.OrderTypeDelivery, 0, enum.LoyaltyTierMember, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := p.Tier(decimal.NewFromInt(tt.lifetime))
+			if tier != tt.wantTier {
+				t.Errorf("tier: got %s, want %s", tier, tt.wantTier)
+			}
+			if got := p.PointsEarned(tt.orderType, spend, tier); got != tt.want {
+				t.Errorf("points: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Part points needed to pay are rounded up
+	if got := p.PointsToRedeem(decimal.NewFromInt(1050)); got != 11 {
+		t.Errorf("points to redeem: got %d, want 11", got)
+	}
+}
+
+func TestRedeemPoints(t *testing.T) {
+	ctx := context.Background()
+	settings := database.LoyaltySetting{
+		IsEnabled:  true,
+		EarnRates:  []byte(`{}`),
+		PointValue: decimalToNumeric(decimal.NewFromInt(100)),
+	}
+	customerID := uuid.New()
+	order := database.Order{ID: uuid.New(), CustomerID: pgtype.UUID{Bytes: customerID, Valid: true}}
+	payment := database.Payment{ID: uuid.New(), PaymentMethod: enum.PaymentMethodPoints, Amount: decimalToNumeric(decimal.NewFromInt(5000))}
+
+	store := &fakeLoyaltyStore{settings: &settings}
+	store.txns = append(store.txns, database.CreateLoyaltyTransactionParams{TransactionType: enum.LoyaltyTransactionEarn, Points: 40})
+	if _, err := RedeemPoints(ctx, store, order, payment, uuid.New()); !errors.Is(err, ErrInsufficientPoints) {
+		t.Fatalf("40 points for 50 needed: got %v, want ErrInsufficientPoints", err)
+	}
+
+	store.txns = append(store.txns, database.CreateLoyaltyTransactionParams{TransactionType: enum.LoyaltyTransactionEarn, Points: 20})
+	points, err := RedeemPoints(ctx, store, order, payment, uuid.New())
+	if err != nil || points != 50 {
+		t.Fatalf("got %d, %v; want 50 points", points, err)
+	}
+
+	// A partial refund gives back the same share of the points
+	payment.RefundedAmount = decimalToNumeric(decimal.NewFromInt(2000))
+	if err := ReverseRefundedPoints(ctx, store, order, payment, decimal.NewFromInt(2000), uuid.New()); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	last := store.txns[len(store.txns)-1]
+	if last.TransactionType != enum.LoyaltyTransactionRedeemReversal || last.Points != 20 {
+		t.Errorf("expected 20 points returned, got %+v", last)
+	}
+
+	noCustomer := order
+	noCustomer.CustomerID = pgtype.UUID{}
+	if _, err := RedeemPoints(ctx, store, noCustomer, payment, uuid.New()); !errors.Is(err, ErrPointsNoCustomer) {
+		t.Errorf("no customer: got %v, want ErrPointsNoCustomer", err)
+	}
+}
+
+func TestReverseRefundedPoints_TakesBackEarned(t *testing.T) {
+	ctx := context.Background()
+	customerID := uuid.New()
+	order := database.Order{ID: uuid.New(), CustomerID: pgtype.UUID{Bytes: customerID, Valid: true}}
+	payment := database.Payment{ID: uuid.New(), PaymentMethod: enum.PaymentMethodCash}
+
+	store := &fakeLoyaltyStore{eligibleSpend: decimal.NewFromInt(60000)}
+	store.txns = append(store.txns, database.CreateLoyaltyTransactionParams{
+		TransactionType: enum.LoyaltyTransactionEarn,
+		Points:          10,
+		Amount:          decimalToNumeric(decimal.NewFromInt(100000)),
+	})
+
+	// 40% of the spend refunded: 6 of the 10 points remain
+	if err := ReverseRefundedPoints(ctx, store, order, payment, decimal.NewFromInt(40000), uuid.New()); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	last := store.txns[len(store.txns)-1]
+	if last.TransactionType != enum.LoyaltyTransactionEarnReversal || last.Points != -4 {
+		t.Errorf("expected 4 points reversed, got %+v", last)
+	}
+
+	// Refunding the rest reverses what is left, never more
+	store.eligibleSpend = decimal.Zero
+	if err := ReverseRefundedPoints(ctx, store, order, payment, decimal.NewFromInt(60000), uuid.New()); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	last = store.txns[len(store.txns)-1]
+	if last.Points != -6 {
+		t.Errorf("expected the remaining 6 points reversed, got %+v", last)
+	}
+}
@@ -51,9 +51,25 @@ type OrderStore interface {
 	GetProductForOrder(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error)
 	GetVariantForOrder(ctx context.Context, id uuid.UUID) (database.GetVariantForOrderRow, error)
 	GetModifierForOrder(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error)
+	ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error)
+	ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error)
+	ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error)
 	CreateOrder(ctx context.Context, arg database.CreateOrderParams) (database.Order, error)
 	CreateOrderItem(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error)
 	CreateOrderItemModifier(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error)
+	GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error
+	// Offline sync
+	GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	CreatePayment(ctx context.Context, arg database.CreatePaymentParams) (database.Payment, error)
+	CompleteSyncedOrder(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error)
+	SetSyncedOrderTotal(ctx context.Context, arg database.SetSyncedOrderTotalParams) (database.Order, error)
+	// Kitchen tickets
+	KitchenTicketStore
+	// Promotions
+	PromotionStore
+	// Loyalty points for synced orders that complete
+	LoyaltyStore
 }
 
 // NewOrderStore creates an OrderStore from a DBTX (pool or tx).
@@ -70,17 +86,23 @@ type CreateOrderRequest struct {
 	Notes            string
 	DiscountType     string
 	DiscountValue    string
+	PromotionCode    string
 	CateringDate     string // RFC3339
 	CateringDpAmount string
 	DeliveryPlatform string
 	DeliveryAddress  string
 	Items            []CreateOrderItemRequest
+	// Set only for orders synced from offline devices; zero values let the
+	// database assign the ID and creation time.
+	ID        uuid.UUID
+	CreatedAt time.Time
 }
 
 // CreateOrderItemRequest is a single item in the order.
 type CreateOrderItemRequest struct {
 	ProductID     string
-	VariantID     string
+	VariantID     string   // legacy single variant; merged into VariantIDs
+	VariantIDs    []string // at most one per variant group
 	Quantity      int32
 	Notes         string
 	DiscountType  string
@@ -96,8 +118,9 @@ type CreateOrderItemModifierRequest struct {
 
 // CreateOrderResult is the full created order with items.
 type CreateOrderResult struct {
-	Order database.Order
-	Items []OrderItemResult
+	Order   database.Order
+	Items   []OrderItemResult
+	Tickets []database.KitchenTicket // one per station; none for synced orders
 }
 
 // OrderItemResult is an item with its modifiers.
@@ -117,65 +140,79 @@ func NewOrderService(pool TxBeginner, newStore NewOrderStore) *OrderService {
 	return &OrderService{pool: pool, newStore: newStore}
 }
 
-// modifierInfo holds data about a modifier to insert.
-type modifierInfo struct {
-	modifierID uuid.UUID
-	quantity   int32
-	unitPrice  decimal.Decimal
-}
-
 // processedItem holds a prepared order item and its modifiers.
 type processedItem struct {
 	params    database.CreateOrderItemParams
-	modifiers []modifierInfo
+	modifiers []SelectedModifier
+	isCombo   bool
+}
+
+// variantIDs returns the item's variant selection with the legacy VariantID
+// first.
+func (r CreateOrderItemRequest) variantIDs() []string {
+	if r.VariantID == "" {
+		return r.VariantIDs
+	}
+	return append([]string{r.VariantID}, r.VariantIDs...)
 }
 
 // CreateOrder validates, calculates prices, and creates an order atomically.
 // Retries up to maxOrderNumberRetries times on order_number unique constraint
 // violations (race condition where concurrent transactions get the same MAX).
 func (s *OrderService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*CreateOrderResult, error) {
+	orderType, err := validateCreateOrder(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retry loop: handles order_number unique constraint race condition.
+	var lastErr error
+	for attempt := 0; attempt < maxOrderNumberRetries; attempt++ {
+		result, err := s.createOrderTx(ctx, req, orderType)
+		if err == nil {
+			return result, nil
+		}
+		if isOrderNumberConflict(err) {
+			lastErr = err
+			continue
+		}
+		return nil, err
+	}
+	return nil, lastErr
+}
+
+// validateCreateOrder checks the order-level fields that need no database
+// lookups and returns the validated order type.
+func validateCreateOrder(req CreateOrderRequest) (string, error) {
 	// --- Validate order type ---
 	orderType, err := validateOrderType(req.OrderType)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	// --- Validate items non-empty ---
 	if len(req.Items) == 0 {
-		return nil, ErrEmptyItems
+		return "", ErrEmptyItems
 	}
 
 	// --- Validate catering requirements ---
 	if orderType == enum.OrderTypeCatering {
 		if req.CateringDate == "" {
-			return nil, ErrCateringDate
+			return "", ErrCateringDate
 		}
 		if req.CustomerID == "" {
-			return nil, ErrCateringCustomer
+			return "", ErrCateringCustomer
 		}
 	}
 
 	// --- Validate order-level discount ---
 	if req.DiscountType != "" {
 		if !isValidDiscountType(req.DiscountType) {
-			return nil, ErrInvalidDiscount
+			return "", ErrInvalidDiscount
 		}
 	}
 
-	// Retry loop: handles order_number unique constraint race condition.
-	var lastErr error
-	for attempt := 0; attempt < maxOrderNumberRetries; attempt++ {
-		result, err := s.createOrderTx(ctx, req, orderType)
-		if err == nil {
-			return result, nil
-		}
-		if isOrderNumberConflict(err) {
-			lastErr = err
-			continue
-		}
-		return nil, err
-	}
-	return nil, lastErr
+	return orderType, nil
 }
 
 // isOrderNumberConflict checks if the error is a unique constraint violation
@@ -199,6 +236,22 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 
 	store := s.newStore(tx)
 
+	result, err := insertOrder(ctx, store, req, orderType)
+	if err != nil {
+		return nil, err
+	}
+
+	// --- Commit ---
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return result, nil
+}
+
+// insertOrder prices the items and writes the order, its items and its
+// history entries through store, which must be transaction-scoped.
+func insertOrder(ctx context.Context, store OrderStore, req CreateOrderRequest, orderType string) (*CreateOrderResult, error) {
 	// --- Generate order number ---
 	nextNum, err := store.GetNextOrderNumber(ctx, req.OutletID)
 	if err != nil {
@@ -209,6 +262,8 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 	// --- Process items: validate + calculate prices ---
 	orderSubtotal := decimal.Zero
 	var items []processedItem
+	var promotionItems []PromotionItem
+	selectionErr := &SelectionError{}
 
 	for i, item := range req.Items {
 		if item.Quantity <= 0 {
@@ -236,61 +291,24 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		// Get base price as decimal
 		basePrice := numericToDecimal(product.BasePrice)
 
-		// Validate variant if provided
-		variantID := pgtype.UUID{}
-		variantAdjustment := decimal.Zero
-		if item.VariantID != "" {
-			vid, err := uuid.Parse(item.VariantID)
-			if err != nil {
-				return nil, fmt.Errorf("item[%d]: %w", i, ErrInvalidVariantID)
-			}
-			variant, err := store.GetVariantForOrder(ctx, vid)
-			if err != nil {
-				if errors.Is(err, pgx.ErrNoRows) {
-					return nil, fmt.Errorf("item[%d]: %w", i, ErrVariantNotFound)
-				}
-				return nil, fmt.Errorf("item[%d]: get variant: %w", i, err)
-			}
-			if variant.ProductID != productID {
-				return nil, fmt.Errorf("item[%d]: %w", i, ErrVariantMismatch)
-			}
-			variantID = pgtype.UUID{Bytes: vid, Valid: true}
-			variantAdjustment = numericToDecimal(variant.PriceAdjustment)
+		// Validate variants and modifiers against the product's groups
+		selection, violations, err := ResolveSelection(ctx, store, productID, item.variantIDs(), item.Modifiers)
+		if err != nil {
+			return nil, fmt.Errorf("item[%d]: %w", i, err)
 		}
-
-		// unit_price = base_price + variant adjustment
-		unitPrice := basePrice.Add(variantAdjustment)
-
-		// Process modifiers
-		modifiersTotal := decimal.Zero
-		var itemModifiers []modifierInfo
-		for j, mod := range item.Modifiers {
-			if mod.Quantity <= 0 {
-				return nil, fmt.Errorf("item[%d].modifiers[%d]: %w", i, j, ErrInvalidQuantity)
-			}
-			modID, err := uuid.Parse(mod.ModifierID)
-			if err != nil {
-				return nil, fmt.Errorf("item[%d].modifiers[%d]: %w", i, j, ErrInvalidModifierID)
-			}
-			modifier, err := store.GetModifierForOrder(ctx, modID)
-			if err != nil {
-				if errors.Is(err, pgx.ErrNoRows) {
-					return nil, fmt.Errorf("item[%d].modifiers[%d]: %w", i, j, ErrModifierNotFound)
-				}
-				return nil, fmt.Errorf("item[%d].modifiers[%d]: get modifier: %w", i, j, err)
-			}
-			if modifier.ProductID != productID {
-				return nil, fmt.Errorf("item[%d].modifiers[%d]: %w", i, j, ErrModifierMismatch)
-			}
-			modPrice := numericToDecimal(modifier.Price)
-			modifiersTotal = modifiersTotal.Add(modPrice.Mul(decimal.NewFromInt32(mod.Quantity)))
-			itemModifiers = append(itemModifiers, modifierInfo{
-				modifierID: modID,
-				quantity:   mod.Quantity,
-				unitPrice:  modPrice,
+		if len(violations) > 0 {
+			selectionErr.Items = append(selectionErr.Items, ItemSelectionError{
+				Index:      i,
+				ProductID:  productID,
+				Violations: violations,
 			})
+			continue
 		}
 
+		// unit_price = base_price + variant adjustments
+		unitPrice := basePrice.Add(selection.PriceAdjustment)
+		modifiersTotal := selection.ModifiersTotal
+
 		// Calculate item discount
 		itemDiscountType := pgtype.Text{}
 		itemDiscountValue := pgtype.Numeric{}
@@ -326,6 +344,12 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		}
 
 		orderSubtotal = orderSubtotal.Add(itemSubtotal)
+		promotionItems = append(promotionItems, PromotionItem{
+			ProductID:  productID,
+			CategoryID: product.CategoryID,
+			Quantity:   item.Quantity,
+			Subtotal:   itemSubtotal,
+		})
 
 		// Build item notes
 		itemNotes := pgtype.Text{}
@@ -336,7 +360,7 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		items = append(items, processedItem{
 			params: database.CreateOrderItemParams{
 				ProductID:      productID,
-				VariantID:      variantID,
+				VariantID:      selection.VariantID(),
 				Quantity:       item.Quantity,
 				UnitPrice:      decimalToNumeric(unitPrice),
 				DiscountType:   itemDiscountType,
@@ -345,11 +369,18 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 				Subtotal:       decimalToNumeric(itemSubtotal),
 				Notes:          itemNotes,
 				Station:        product.Station,
+				VariantIds:     selection.VariantIDs,
 			},
-			modifiers: itemModifiers,
+			modifiers: selection.Modifiers,
+			isCombo:   product.IsCombo,
 		})
 	}
 
+	// Report every item's group violations together
+	if len(selectionErr.Items) > 0 {
+		return nil, selectionErr
+	}
+
 	// --- Calculate order-level discount ---
 	orderDiscountType := pgtype.Text{}
 	orderDiscountValue := pgtype.Numeric{}
@@ -372,12 +403,33 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		}
 	}
 
-	// --- Calculate total ---
-	taxAmount := decimal.Zero // tax = 0 for now
-	totalAmount := orderSubtotal.Sub(orderDiscountAmount).Add(taxAmount)
-	if totalAmount.IsNegative() {
-		totalAmount = decimal.Zero
+	// --- Apply promotion, as of the device's time for synced orders ---
+	promotedAt := req.CreatedAt
+	if promotedAt.IsZero() {
+		promotedAt = time.Now()
 	}
+	promotion, err := EvaluatePromotion(ctx, store, req.OutletID, req.ID, req.PromotionCode, promotionItems, orderDiscountAmount, promotedAt)
+	if err != nil {
+		return nil, err
+	}
+	promotionID := pgtype.UUID{}
+	promotionCode := pgtype.Text{}
+	promotionAmount := decimal.Zero
+	if promotion != nil {
+		promotionID = pgtype.UUID{Bytes: promotion.ID, Valid: true}
+		promotionAmount = promotion.Amount
+	}
+	if req.PromotionCode != "" {
+		promotionCode = pgtype.Text{String: req.PromotionCode, Valid: true}
+	}
+	discountAmount := orderDiscountAmount.Add(promotionAmount)
+
+	// --- Calculate service charge, tax, and total ---
+	chargeSettings, err := LoadChargeSettings(ctx, store, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	charges := CalculateCharges(chargeSettings, orderType, orderSubtotal.Sub(discountAmount))
 
 	// --- Build order params ---
 	customerID := pgtype.UUID{}
@@ -432,26 +484,44 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		}
 	}
 
+	orderID := pgtype.UUID{}
+	createdAt := pgtype.Timestamptz{}
+	if req.ID != uuid.Nil {
+		orderID = pgtype.UUID{Bytes: req.ID, Valid: true}
+	}
+	if !req.CreatedAt.IsZero() {
+		createdAt = pgtype.Timestamptz{Time: req.CreatedAt, Valid: true}
+	}
+
 	// --- Insert order ---
 	order, err := store.CreateOrder(ctx, database.CreateOrderParams{
-		OutletID:         req.OutletID,
-		OrderNumber:      orderNumber,
-		CustomerID:       customerID,
-		OrderType:        orderType,
-		TableNumber:      tableNumber,
-		Notes:            notes,
-		Subtotal:         decimalToNumeric(orderSubtotal),
-		DiscountType:     orderDiscountType,
-		DiscountValue:    orderDiscountValue,
-		DiscountAmount:   decimalToNumeric(orderDiscountAmount),
-		TaxAmount:        decimalToNumeric(taxAmount),
-		TotalAmount:      decimalToNumeric(totalAmount),
-		CateringDate:     cateringDate,
-		CateringStatus:   cateringStatus,
-		CateringDpAmount: cateringDpAmount,
-		DeliveryPlatform: deliveryPlatform,
-		DeliveryAddress:  deliveryAddress,
-		CreatedBy:        req.CreatedBy,
+		OutletID:            req.OutletID,
+		OrderNumber:         orderNumber,
+		CustomerID:          customerID,
+		OrderType:           orderType,
+		TableNumber:         tableNumber,
+		Notes:               notes,
+		Subtotal:            decimalToNumeric(orderSubtotal),
+		DiscountType:        orderDiscountType,
+		DiscountValue:       orderDiscountValue,
+		DiscountAmount:      decimalToNumeric(discountAmount),
+		TaxAmount:           decimalToNumeric(charges.TaxAmount),
+		TotalAmount:         decimalToNumeric(charges.TotalAmount),
+		CateringDate:        cateringDate,
+		CateringStatus:      cateringStatus,
+		CateringDpAmount:    cateringDpAmount,
+		DeliveryPlatform:    deliveryPlatform,
+		DeliveryAddress:     deliveryAddress,
+		CreatedBy:           req.CreatedBy,
+		ServiceChargeAmount: decimalToNumeric(charges.ServiceChargeAmount),
+		ServiceChargeRate:   decimalToNumeric(charges.ServiceChargeRate),
+		TaxRate:             decimalToNumeric(charges.TaxRate),
+		PricesIncludeTax:    charges.PricesIncludeTax,
+		PromotionID:         promotionID,
+		PromotionCode:       promotionCode,
+		PromotionAmount:     decimalToNumeric(promotionAmount),
+		ID:                  orderID,
+		CreatedAt:           createdAt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create order: %w", err)
@@ -470,9 +540,9 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 		for _, mod := range pi.modifiers {
 			oim, err := store.CreateOrderItemModifier(ctx, database.CreateOrderItemModifierParams{
 				OrderItemID: item.ID,
-				ModifierID:  mod.modifierID,
-				Quantity:    mod.quantity,
-				UnitPrice:   decimalToNumeric(mod.unitPrice),
+				ModifierID:  mod.ModifierID,
+				Quantity:    mod.Quantity,
+				UnitPrice:   decimalToNumeric(mod.UnitPrice),
 			})
 			if err != nil {
 				return nil, fmt.Errorf("create order item modifier: %w", err)
@@ -484,16 +554,62 @@ func (s *OrderService) createOrderTx(ctx context.Context, req CreateOrderRequest
 			Item:      item,
 			Modifiers: modResults,
 		})
+
+		// Combo components follow their parent line
+		if pi.isCombo {
+			children, err := ExpandCombo(ctx, store, item)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				itemResults = append(itemResults, OrderItemResult{Item: child})
+			}
+		}
 	}
 
-	// --- Commit ---
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit tx: %w", err)
+	// --- Audit trail ---
+	created := map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"order_type":   order.OrderType,
+		"status":       order.Status,
+		"item_count":   len(itemResults),
+		"total_amount": charges.TotalAmount.StringFixed(2),
+	}
+	if createdAt.Valid {
+		created["offline"] = true
+	}
+	if err := RecordOrderEvent(ctx, store, order.ID, req.CreatedBy, enum.OrderEventCreated, nil, created); err != nil {
+		return nil, err
+	}
+	if orderDiscountType.Valid {
+		if err := RecordOrderEvent(ctx, store, order.ID, req.CreatedBy, enum.OrderEventDiscountApplied, nil, map[string]interface{}{
+			"discount_type":   orderDiscountType.String,
+			"discount_value":  numericToDecimal(orderDiscountValue).StringFixed(2),
+			"discount_amount": orderDiscountAmount.StringFixed(2),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if promotion != nil {
+		if err := RecordOrderEvent(ctx, store, order.ID, req.CreatedBy, enum.OrderEventPromotionApplied, nil, promotion.eventValue()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Orders synced from offline devices were already made in the kitchen
+	var tickets []database.KitchenTicket
+	if !createdAt.Valid {
+		tickets, err = SendKitchenTickets(ctx, store, order.ID, req.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &CreateOrderResult{
-		Order: order,
-		Items: itemResults,
+		Order:   order,
+		Items:   itemResults,
+		Tickets: tickets,
 	}, nil
 }
 
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/database"
+)
+
+// OrderEventStore is the store method needed to append to an order's history.
+type OrderEventStore interface {
+	CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error
+}
+
+// RecordOrderEvent appends an entry to the order's audit trail. before and
+// after are JSON-encoded snapshots of the fields that changed; nil leaves the
+// column NULL. Call it with a transaction-scoped store so the entry commits
+// or rolls back together with the change it describes.
+func RecordOrderEvent(ctx context.Context, store OrderEventStore, orderID, actorID uuid.UUID, eventType string, before, after interface{}) error {
+	beforeJSON, err := marshalEventValue(before)
+	if err != nil {
+		return fmt.Errorf("marshal before value: %w", err)
+	}
+	afterJSON, err := marshalEventValue(after)
+	if err != nil {
+		return fmt.Errorf("marshal after value: %w", err)
+	}
+
+	if err := store.CreateOrderEvent(ctx, database.CreateOrderEventParams{
+		OrderID:     orderID,
+		EventType:   eventType,
+		ActorID:     actorID,
+		BeforeValue: beforeJSON,
+		AfterValue:  afterJSON,
+	}); err != nil {
+		return fmt.Errorf("create order event: %w", err)
+	}
+	return nil
+}
+
+func marshalEventValue(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -60,13 +60,26 @@ func (m *mockTxBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
 
 // mockOrderStore implements OrderStore with configurable behavior.
 type mockOrderStore struct {
+	fakeLoyaltyStore
 	getNextOrderNumberFn    func(ctx context.Context, outletID uuid.UUID) (int32, error)
 	getProductForOrderFn    func(ctx context.Context, arg database.GetProductForOrderParams) (database.GetProductForOrderRow, error)
 	getVariantForOrderFn    func(ctx context.Context, id uuid.UUID) (database.GetVariantForOrderRow, error)
 	getModifierForOrderFn   func(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error)
+	listVariantGroupsFn     func(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error)
+	listModifierGroupsFn    func(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error)
+	listComboComponentsFn   func(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error)
 	createOrderFn           func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error)
 	createOrderItemFn       func(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error)
 	createOrderItemModFn    func(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error)
+	getOutletSettingsFn     func(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error)
+	createOrderEventFn      func(ctx context.Context, arg database.CreateOrderEventParams) error
+	getOrderFn              func(ctx context.Context, arg database.GetOrderParams) (database.Order, error)
+	createPaymentFn         func(ctx context.Context, arg database.CreatePaymentParams) (database.Payment, error)
+	completeSyncedOrderFn   func(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error)
+	setSyncedOrderTotalFn   func(ctx context.Context, arg database.SetSyncedOrderTotalParams) (database.Order, error)
+	kitchenTicketItems      []database.ListKitchenTicketItemsRow
+	kitchenTickets          []database.KitchenTicket
+	promotions              []database.ListOrderPromotionsRow
 }
 
 func (m *mockOrderStore) GetNextOrderNumber(ctx context.Context, outletID uuid.UUID) (int32, error) {
@@ -81,6 +94,15 @@ func (m *mockOrderStore) GetVariantForOrder(ctx context.Context, id uuid.UUID) (
 func (m *mockOrderStore) GetModifierForOrder(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error) {
 	return m.getModifierForOrderFn(ctx, id)
 }
+func (m *mockOrderStore) ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error) {
+	return m.listVariantGroupsFn(ctx, productID)
+}
+func (m *mockOrderStore) ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error) {
+	return m.listModifierGroupsFn(ctx, productID)
+}
+func (m *mockOrderStore) ListComboComponentsForOrder(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error) {
+	return m.listComboComponentsFn(ctx, comboID)
+}
 func (m *mockOrderStore) CreateOrder(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
 	return m.createOrderFn(ctx, arg)
 }
@@ -90,6 +112,53 @@ func (m *mockOrderStore) CreateOrderItem(ctx context.Context, arg database.Creat
 func (m *mockOrderStore) CreateOrderItemModifier(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error) {
 	return m.createOrderItemModFn(ctx, arg)
 }
+func (m *mockOrderStore) GetOutletSettings(ctx context.Context, outletID uuid.UUID) (database.OutletSetting, error) {
+	return m.getOutletSettingsFn(ctx, outletID)
+}
+func (m *mockOrderStore) CreateOrderEvent(ctx context.Context, arg database.CreateOrderEventParams) error {
+	if m.createOrderEventFn == nil {
+		return nil
+	}
+	return m.createOrderEventFn(ctx, arg)
+}
+func (m *mockOrderStore) GetOrder(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+	return m.getOrderFn(ctx, arg)
+}
+func (m *mockOrderStore) CreatePayment(ctx context.Context, arg database.CreatePaymentParams) (database.Payment, error) {
+	return m.createPaymentFn(ctx, arg)
+}
+func (m *mockOrderStore) CompleteSyncedOrder(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error) {
+	return m.completeSyncedOrderFn(ctx, arg)
+}
+func (m *mockOrderStore) SetSyncedOrderTotal(ctx context.Context, arg database.SetSyncedOrderTotalParams) (database.Order, error) {
+	return m.setSyncedOrderTotalFn(ctx, arg)
+}
+func (m *mockOrderStore) ListKitchenTicketItems(ctx context.Context, orderID uuid.UUID) ([]database.ListKitchenTicketItemsRow, error) {
+	return m.kitchenTicketItems, nil
+}
+func (m *mockOrderStore) ListKitchenTickets(ctx context.Context, orderID uuid.UUID) ([]database.KitchenTicket, error) {
+	return m.kitchenTickets, nil
+}
+func (m *mockOrderStore) CreateKitchenTicket(ctx context.Context, arg database.CreateKitchenTicketParams) (database.KitchenTicket, error) {
+	t := database.KitchenTicket{
+		ID:         uuid.New(),
+		OrderID:    arg.OrderID,
+		Sequence:   int32(len(m.kitchenTickets) + 1),
+		Station:    arg.Station,
+		TicketType: arg.TicketType,
+		Lines:      arg.Lines,
+		CreatedBy:  arg.CreatedBy,
+	}
+	m.kitchenTickets = append(m.kitchenTickets, t)
+	return t, nil
+}
+func (m *mockOrderStore) LockOrderPromotions(ctx context.Context, arg database.LockOrderPromotionsParams) error {
+	return nil
+}
+
+func (m *mockOrderStore) ListOrderPromotions(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	return m.promotions, nil
+}
 
 // --- Test helpers ---
 
@@ -138,6 +207,15 @@ func defaultStore(outletID, productID uuid.UUID) *mockOrderStore {
 		getModifierForOrderFn: func(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error) {
 			return database.GetModifierForOrderRow{}, pgx.ErrNoRows
 		},
+		listVariantGroupsFn: func(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error) {
+			return nil, nil
+		},
+		listModifierGroupsFn: func(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error) {
+			return nil, nil
+		},
+		listComboComponentsFn: func(ctx context.Context, comboID uuid.UUID) ([]database.ListComboComponentsForOrderRow, error) {
+			return nil, nil
+		},
 		createOrderFn: func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
 			return database.Order{
 				ID:             uuid.New(),
@@ -169,6 +247,8 @@ func defaultStore(outletID, productID uuid.UUID) *mockOrderStore {
 				Notes:          arg.Notes,
 				Status:         enum.OrderItemStatusPending,
 				Station:        arg.Station,
+				VariantIds:     arg.VariantIds,
+				ParentItemID:   arg.ParentItemID,
 			}, nil
 		},
 		createOrderItemModFn: func(ctx context.Context, arg database.CreateOrderItemModifierParams) (database.OrderItemModifier, error) {
@@ -180,6 +260,9 @@ func defaultStore(outletID, productID uuid.UUID) *mockOrderStore {
 				UnitPrice:   arg.UnitPrice,
 			}, nil
 		},
+		getOutletSettingsFn: func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+			return database.OutletSetting{}, pgx.ErrNoRows
+		},
 	}
 }
 
@@ -824,6 +907,67 @@ func TestCreateOrder_OrderPercentageDiscount(t *testing.T) {
 	}
 }
 
+func TestCreateOrder_RecordsHistory(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	store := defaultStore(outletID, productID)
+
+	var events []database.CreateOrderEventParams
+	store.createOrderEventFn = func(ctx context.Context, arg database.CreateOrderEventParams) error {
+		events = append(events, arg)
+		return nil
+	}
+
+	userID := uuid.New()
+	svc, _ := newTestService(store)
+	result, err := svc.CreateOrder(context.Background(), CreateOrderRequest{
+		OutletID:      outletID,
+		CreatedBy:     userID,
+		OrderType:     "DINE_IN",
+		DiscountType:  "FIXED_AMOUNT",
+		DiscountValue: "5000",
+		Items: []CreateOrderItemRequest{
+			{ProductID: productID.String(), Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].EventType != enum.OrderEventCreated {
+		t.Errorf("first event: got %s, want %s", events[0].EventType, enum.OrderEventCreated)
+	}
+	if events[1].EventType != enum.OrderEventDiscountApplied {
+		t.Errorf("second event: got %s, want %s", events[1].EventType, enum.OrderEventDiscountApplied)
+	}
+	for _, e := range events {
+		if e.OrderID != result.Order.ID || e.ActorID != userID {
+			t.Errorf("event %s: got order %s actor %s", e.EventType, e.OrderID, e.ActorID)
+		}
+	}
+	if !strings.Contains(string(events[1].AfterValue), `"discount_amount":"5000.00"`) {
+		t.Errorf("discount event after value: got %s", events[1].AfterValue)
+	}
+}
+
+func TestCreateOrder_HistoryError(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	store := defaultStore(outletID, productID)
+	store.createOrderEventFn = func(ctx context.Context, arg database.CreateOrderEventParams) error {
+		return errors.New("db down")
+	}
+
+	svc, _ := newTestService(store)
+	_, err := svc.CreateOrder(context.Background(), basicReq(outletID, productID.String()))
+	if err == nil {
+		t.Fatal("expected error when history cannot be written")
+	}
+}
+
 func TestCreateOrder_OrderFixedDiscount(t *testing.T) {
 	outletID := uuid.New()
 	productID := uuid.New()
@@ -903,6 +1047,121 @@ func TestCreateOrder_NegativeSubtotalClampedToZero(t *testing.T) {
 	}
 }
 
+func TestCreateOrder_TaxAndServiceCharge(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	store := defaultStore(outletID, productID)
+	store.getOutletSettingsFn = func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+		return database.OutletSetting{
+			OutletID:                oid,
+			TaxRate:                 makeNumeric("10"),
+			TaxOrderTypes:           []string{enum.OrderTypeDineIn, enum.OrderTypeTakeaway},
+			ServiceChargeRate:       makeNumeric("5"),
+			ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+			RoundingMode:            enum.RoundingModeHalfUp,
+			RoundingUnit:            makeNumeric("1"),
+		}, nil
+	}
+
+	var capturedOrder database.CreateOrderParams
+	store.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		capturedOrder = arg
+		return database.Order{ID: uuid.New(), OutletID: arg.OutletID, CreatedBy: arg.CreatedBy}, nil
+	}
+
+	svc, _ := newTestService(store)
+	_, err := svc.CreateOrder(context.Background(), CreateOrderRequest{
+		OutletID:      outletID,
+		CreatedBy:     uuid.New(),
+		OrderType:     "DINE_IN",
+		DiscountType:  "FIXED_AMOUNT",
+		DiscountValue: "10000",
+		Items: []CreateOrderItemRequest{
+			{ProductID: productID.String(), Quantity: 2}, // 25000 * 2 = 50000
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// net = 40000, service = 2000, tax = 10% of 42000 = 4200, total = 46200
+	if !numericEquals(capturedOrder.ServiceChargeAmount, "2000.00") {
+		t.Errorf("service charge: got %v, want 2000.00", numericToDecimal(capturedOrder.ServiceChargeAmount))
+	}
+	if !numericEquals(capturedOrder.ServiceChargeRate, "5") {
+		t.Errorf("service charge rate: got %v, want 5", numericToDecimal(capturedOrder.ServiceChargeRate))
+	}
+	if !numericEquals(capturedOrder.TaxAmount, "4200.00") {
+		t.Errorf("tax: got %v, want 4200.00", numericToDecimal(capturedOrder.TaxAmount))
+	}
+	if !numericEquals(capturedOrder.TaxRate, "10") {
+		t.Errorf("tax rate: got %v, want 10", numericToDecimal(capturedOrder.TaxRate))
+	}
+	if !numericEquals(capturedOrder.TotalAmount, "46200.00") {
+		t.Errorf("order total: got %v, want 46200.00", numericToDecimal(capturedOrder.TotalAmount))
+	}
+}
+
+func TestCreateOrder_ServiceChargeNotApplicableToOrderType(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	store := defaultStore(outletID, productID)
+	store.getOutletSettingsFn = func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+		return database.OutletSetting{
+			OutletID:                oid,
+			TaxRate:                 makeNumeric("10"),
+			PricesIncludeTax:        true,
+			TaxOrderTypes:           []string{enum.OrderTypeDineIn, enum.OrderTypeTakeaway},
+			ServiceChargeRate:       makeNumeric("5"),
+			ServiceChargeOrderTypes: []string{enum.OrderTypeDineIn},
+			RoundingMode:            enum.RoundingModeHalfUp,
+			RoundingUnit:            makeNumeric("1"),
+		}, nil
+	}
+
+	var capturedOrder database.CreateOrderParams
+	store.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		capturedOrder = arg
+		return database.Order{ID: uuid.New(), OutletID: arg.OutletID, CreatedBy: arg.CreatedBy}, nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicReq(outletID, productID.String()) // 50000
+	req.OrderType = "TAKEAWAY"
+	if _, err := svc.CreateOrder(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tax-inclusive takeaway: no service charge, total unchanged, tax extracted.
+	if !numericEquals(capturedOrder.ServiceChargeAmount, "0") {
+		t.Errorf("service charge: got %v, want 0", numericToDecimal(capturedOrder.ServiceChargeAmount))
+	}
+	if !numericEquals(capturedOrder.TaxAmount, "4545.00") {
+		t.Errorf("tax: got %v, want 4545.00", numericToDecimal(capturedOrder.TaxAmount))
+	}
+	if !numericEquals(capturedOrder.TotalAmount, "50000.00") {
+		t.Errorf("order total: got %v, want 50000.00", numericToDecimal(capturedOrder.TotalAmount))
+	}
+	if !capturedOrder.PricesIncludeTax {
+		t.Error("expected prices_include_tax to be recorded on the order")
+	}
+}
+
+func TestCreateOrder_OutletSettingsError(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	store := defaultStore(outletID, productID)
+	store.getOutletSettingsFn = func(ctx context.Context, oid uuid.UUID) (database.OutletSetting, error) {
+		return database.OutletSetting{}, errors.New("connection refused")
+	}
+
+	svc, _ := newTestService(store)
+	_, err := svc.CreateOrder(context.Background(), basicReq(outletID, productID.String()))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestCreateOrder_ItemNegativeSubtotalClampedToZero(t *testing.T) {
 	outletID := uuid.New()
 	productID := uuid.New()
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// Errors returned when the promotion code entered for an order cannot be used.
+var (
+	ErrInvalidPromotionCode = errors.New("promotion code is invalid or expired")
+	ErrPromotionNotMet      = errors.New("order does not meet the promotion's conditions")
+)
+
+// PromotionStore defines the DB methods needed to evaluate promotions.
+// Satisfied by *database.Queries (and its WithTx variant).
+type PromotionStore interface {
+	LockOrderPromotions(ctx context.Context, arg database.LockOrderPromotionsParams) error
+	ListOrderPromotions(ctx context.Context, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error)
+}
+
+// OrderPromotionStore defines the DB methods needed to re-evaluate the
+// promotion of an existing order.
+type OrderPromotionStore interface {
+	PromotionStore
+	ListPromotionItems(ctx context.Context, orderID uuid.UUID) ([]database.ListPromotionItemsRow, error)
+	UpdateOrderPromotion(ctx context.Context, arg database.UpdateOrderPromotionParams) (database.Order, error)
+	OrderEventStore
+}
+
+// PromotionItem is an order line as promotion rules see it.
+type PromotionItem struct {
+	ProductID  uuid.UUID
+	CategoryID uuid.UUID
+	Quantity   int32
+	Subtotal   decimal.Decimal // after the line's own discount
+}
+
+// AppliedPromotion is the promotion chosen for an order and the discount it gives.
+type AppliedPromotion struct {
+	ID     uuid.UUID
+	Name   string
+	Amount decimal.Decimal
+}
+
+// promotionQuote is what an order looks like to the promotion rules.
+type promotionQuote struct {
+	Items    []PromotionItem
+	Subtotal decimal.Decimal // sum of item subtotals
+	Room     decimal.Decimal // subtotal less the manual order discount
+	At       time.Time
+	Code     string
+}
+
+// EvaluatePromotion picks the promotion for a new order. A promotion code
+// must match an available promotion whose conditions the order meets;
+// otherwise the automatic promotion giving the largest discount is applied.
+// Returns nil when no promotion applies.
+//
+// Call it with a transaction-scoped store: promotions with a usage limit
+// stay locked until the order is written, so concurrent orders cannot both
+// take the last use.
+func EvaluatePromotion(ctx context.Context, store PromotionStore, outletID, orderID uuid.UUID, code string, items []PromotionItem, manualDiscount decimal.Decimal, at time.Time) (*AppliedPromotion, error) {
+	promos, err := listOrderPromotions(ctx, store, database.ListOrderPromotionsParams{
+		OrderID:  orderID,
+		OutletID: outletID,
+		Code:     pgtype.Text{String: code, Valid: code != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q := promotionQuote{Items: items, At: at, Code: code}
+	for _, item := range items {
+		q.Subtotal = q.Subtotal.Add(item.Subtotal)
+	}
+	q.Room = q.Subtotal.Sub(manualDiscount)
+
+	applied, codeErr := choosePromotion(promos, q)
+	if codeErr != nil {
+		return nil, codeErr
+	}
+	return applied, nil
+}
+
+// ApplyOrderPromotion re-evaluates an existing order's promotion after its
+// items changed (see UpdateOrderTotals). Rules are checked as of the order's
+// creation, so an order opened during happy hour keeps its happy-hour price.
+// A code that no longer applies gives way to the best automatic promotion
+// and is kept on the order in case later changes qualify it again. The order
+// is written only if the promotion or its discount changed.
+func ApplyOrderPromotion(ctx context.Context, store OrderPromotionStore, order database.Order, actorID uuid.UUID) (database.Order, error) {
+	rows, err := store.ListPromotionItems(ctx, order.ID)
+	if err != nil {
+		return database.Order{}, fmt.Errorf("list promotion items: %w", err)
+	}
+	promos, err := listOrderPromotions(ctx, store, database.ListOrderPromotionsParams{
+		OrderID:  order.ID,
+		OutletID: order.OutletID,
+		Code:     order.PromotionCode,
+	})
+	if err != nil {
+		return database.Order{}, err
+	}
+
+	q := promotionQuote{
+		Items:    make([]PromotionItem, len(rows)),
+		Subtotal: numericToDecimal(order.Subtotal),
+		At:       order.CreatedAt,
+		Code:     order.PromotionCode.String,
+	}
+	for i, row := range rows {
+		q.Items[i] = PromotionItem{
+			ProductID:  row.ProductID,
+			CategoryID: row.CategoryID,
+			Quantity:   row.Quantity,
+			Subtotal:   numericToDecimal(row.Subtotal),
+		}
+	}
+	currentAmount := numericToDecimal(order.PromotionAmount)
+	q.Room = q.Subtotal.Sub(numericToDecimal(order.DiscountAmount).Sub(currentAmount))
+
+	applied, _ := choosePromotion(promos, q)
+
+	promotionID := pgtype.UUID{}
+	amount := decimal.Zero
+	if applied != nil {
+		promotionID = pgtype.UUID{Bytes: applied.ID, Valid: true}
+		amount = applied.Amount
+	}
+	if promotionID == order.PromotionID && amount.Equal(currentAmount) {
+		return order, nil
+	}
+
+	updated, err := store.UpdateOrderPromotion(ctx, database.UpdateOrderPromotionParams{
+		ID:              order.ID,
+		PromotionID:     promotionID,
+		PromotionAmount: decimalToNumeric(amount),
+	})
+	if err != nil {
+		return database.Order{}, fmt.Errorf("update order promotion: %w", err)
+	}
+
+	// Amount changes follow the items and are already in their history
+	if promotionID != order.PromotionID {
+		var before, after map[string]interface{}
+		if order.PromotionID.Valid {
+			before = map[string]interface{}{
+				"promotion_id":     uuid.UUID(order.PromotionID.Bytes),
+				"promotion_amount": currentAmount.StringFixed(2),
+			}
+		}
+		if applied != nil {
+			after = applied.eventValue()
+		}
+		if err := RecordOrderEvent(ctx, store, order.ID, actorID, enum.OrderEventPromotionApplied, before, after); err != nil {
+			return database.Order{}, err
+		}
+	}
+	return updated, nil
+}
+
+// listOrderPromotions locks the usage-limited promotions the order may
+// receive, then lists them with their use by other orders. Listing after
+// the lock is held counts orders committed while waiting for it.
+func listOrderPromotions(ctx context.Context, store PromotionStore, arg database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	if err := store.LockOrderPromotions(ctx, database.LockOrderPromotionsParams{
+		OutletID: arg.OutletID,
+		Code:     arg.Code,
+	}); err != nil {
+		return nil, fmt.Errorf("lock promotions: %w", err)
+	}
+	promos, err := store.ListOrderPromotions(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("list promotions: %w", err)
+	}
+	return promos, nil
+}
+
+// eventValue is the promotion's entry in the order history.
+func (a *AppliedPromotion) eventValue() map[string]interface{} {
+	return map[string]interface{}{
+		"promotion_id":     a.ID,
+		"promotion_name":   a.Name,
+		"promotion_amount": a.Amount.StringFixed(2),
+	}
+}
+
+// choosePromotion applies the entered code's promotion if the order meets
+// its conditions, otherwise the automatic promotion with the largest
+// discount. The error explains why the code was not applied.
+func choosePromotion(promos []database.ListOrderPromotionsRow, q promotionQuote) (*AppliedPromotion, error) {
+	var codeErr error
+	if q.Code != "" {
+		codeErr = ErrInvalidPromotionCode
+		for _, p := range promos {
+			if !p.Code.Valid || p.Code.String != q.Code || !promotionAvailable(p, q.At) {
+				continue
+			}
+			if amount := promotionDiscount(p, q); amount.IsPositive() {
+				return &AppliedPromotion{ID: p.ID, Name: p.Name, Amount: amount}, nil
+			}
+			codeErr = ErrPromotionNotMet
+		}
+	}
+
+	var best *AppliedPromotion
+	for _, p := range promos {
+		if p.Code.Valid || !promotionAvailable(p, q.At) {
+			continue
+		}
+		amount := promotionDiscount(p, q)
+		if amount.IsPositive() && (best == nil || amount.GreaterThan(best.Amount)) {
+			best = &AppliedPromotion{ID: p.ID, Name: p.Name, Amount: amount}
+		}
+	}
+	return best, codeErr
+}
+
+// promotionAvailable reports whether p can be used at time at: within its
+// validity dates and daily window, and under its usage limit.
+func promotionAvailable(p database.ListOrderPromotionsRow, at time.Time) bool {
+	if p.ValidFrom.Valid && at.Before(p.ValidFrom.Time) {
+		return false
+	}
+	if p.ValidUntil.Valid && !at.Before(p.ValidUntil.Time) {
+		return false
+	}
+	if p.UsageLimit.Valid && p.UsedCount >= int64(p.UsageLimit.Int32) {
+		return false
+	}
+	if p.StartTime.Valid && p.EndTime.Valid {
+		return inDailyWindow(p.StartTime, p.EndTime, at)
+	}
+	return true
+}
+
+// inDailyWindow reports whether at falls between start and end in outlet
+// time. A window whose end is before its start wraps past midnight.
+func inDailyWindow(start, end pgtype.Time, at time.Time) bool {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	local := at.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	t := local.Sub(midnight).Microseconds()
+
+	if start.Microseconds <= end.Microseconds {
+		return t >= start.Microseconds && t < end.Microseconds
+	}
+	return t >= start.Microseconds || t < end.Microseconds
+}
+
+// promotionDiscount returns the discount p gives the order, or zero when
+// the order does not meet its conditions. The discount never exceeds what
+// is left of the order after its manual discount.
+func promotionDiscount(p database.ListOrderPromotionsRow, q promotionQuote) decimal.Decimal {
+	if p.MinSpend.Valid && q.Subtotal.LessThan(numericToDecimal(p.MinSpend)) {
+		return decimal.Zero
+	}
+
+	var covered []PromotionItem
+	coveredTotal := decimal.Zero
+	for _, item := range q.Items {
+		if promotionCovers(p, item) {
+			covered = append(covered, item)
+			coveredTotal = coveredTotal.Add(item.Subtotal)
+		}
+	}
+	if len(covered) == 0 {
+		return decimal.Zero
+	}
+
+	value := numericToDecimal(p.DiscountValue)
+	amount := decimal.Zero
+	switch p.PromotionType {
+	case enum.PromotionTypePercentage:
+		amount = coveredTotal.Mul(value).Div(hundred)
+	case enum.PromotionTypeFixed:
+		amount = decimal.Min(value, coveredTotal)
+	case enum.PromotionTypeBuyXGetY:
+		// Every buy+get units, the cheapest get units are free
+		buy, get := int(p.BuyQuantity.Int32), int(p.GetQuantity.Int32)
+		if buy <= 0 || get <= 0 {
+			return decimal.Zero
+		}
+		units := unitPrices(covered)
+		for set := 0; set+buy+get <= len(units); set += buy + get {
+			for _, price := range units[set+buy : set+buy+get] {
+				amount = amount.Add(price)
+			}
+		}
+	case enum.PromotionTypeBundle:
+		// Every size units sell for the bundle price, dearest units first
+		size := int(p.BuyQuantity.Int32)
+		if size <= 0 {
+			return decimal.Zero
+		}
+		units := unitPrices(covered)
+		bundles := len(units) / size
+		for _, price := range units[:bundles*size] {
+			amount = amount.Add(price)
+		}
+		amount = amount.Sub(value.Mul(decimal.NewFromInt(int64(bundles))))
+	}
+
+	amount = decimal.Min(amount, q.Room).Round(2)
+	if !amount.IsPositive() {
+		return decimal.Zero
+	}
+	return amount
+}
+
+// promotionCovers reports whether item is one of the promotion's items. A
+// product takes precedence over a category; neither covers every item.
+func promotionCovers(p database.ListOrderPromotionsRow, item PromotionItem) bool {
+	switch {
+	case p.ProductID.Valid:
+		return item.ProductID == uuid.UUID(p.ProductID.Bytes)
+	case p.CategoryID.Valid:
+		return item.CategoryID == uuid.UUID(p.CategoryID.Bytes)
+	}
+	return true
+}
+
+// unitPrices expands items into the price of each unit, dearest first.
+func unitPrices(items []PromotionItem) []decimal.Decimal {
+	var units []decimal.Decimal
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		price := item.Subtotal.Div(decimal.NewFromInt32(item.Quantity))
+		for range item.Quantity {
+			units = append(units, price)
+		}
+	}
+	slices.SortStableFunc(units, func(a, b decimal.Decimal) int {
+		return b.Cmp(a)
+	})
+	return units
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+type fakePromotionStore []database.ListOrderPromotionsRow
+
+func (f fakePromotionStore) ListOrderPromotions(_ context.Context, _ database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	return f, nil
+}
+
+func (f fakePromotionStore) LockOrderPromotions(_ context.Context, _ database.LockOrderPromotionsParams) error {
+	return nil
+}
+
+func testPromotion(promotionType, value string) database.ListOrderPromotionsRow {
+	return database.ListOrderPromotionsRow{
+		ID:            uuid.New(),
+		Name:          promotionType,
+		PromotionType: promotionType,
+		DiscountValue: decimalToNumeric(decimal.RequireFromString(value)),
+	}
+}
+
+func clock(hour, minute int) pgtype.Time {
+	d := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	return pgtype.Time{Microseconds: d.Microseconds(), Valid: true}
+}
+
+func promotionLine(product, category uuid.UUID, qty int32, subtotal int64) PromotionItem {
+	return PromotionItem{ProductID: product, CategoryID: category, Quantity: qty, Subtotal: decimal.NewFromInt(subtotal)}
+}
+
+func TestEvaluatePromotion_Rules(t *testing.T) {
+	coffee, food := uuid.New(), uuid.New()
+	latte, tea, rice := uuid.New(), uuid.New(), uuid.New()
+	wib := time.FixedZone("WIB", 7*3600)
+	afternoon := time.Date(2026, 3, 2, 16, 0, 0, 0, wib)
+	items := []PromotionItem{
+		promotionLine(latte, coffee, 2, 60000), // 30k each
+		promotionLine(tea, coffee, 1, 20000),
+		promotionLine(rice, food, 1, 40000),
+	}
+
+	happyHour := testPromotion(enum.PromotionTypePercentage, "20")
+	happyHour.CategoryID = pgtype.UUID{Bytes: coffee, Valid: true}
+	happyHour.StartTime, happyHour.EndTime = clock(15, 0), clock(17, 0)
+
+	buy2get1 := testPromotion(enum.PromotionTypeBuyXGetY, "0")
+	buy2get1.CategoryID = pgtype.UUID{Bytes: coffee, Valid: true}
+	buy2get1.BuyQuantity = pgtype.Int4{Int32: 2, Valid: true}
+	buy2get1.GetQuantity = pgtype.Int4{Int32: 1, Valid: true}
+
+	bundle := testPromotion(enum.PromotionTypeBundle, "70000")
+	bundle.BuyQuantity = pgtype.Int4{Int32: 3, Valid: true}
+
+	minSpend := testPromotion(enum.PromotionTypeFixed, "15000")
+	minSpend.MinSpend = decimalToNumeric(decimal.NewFromInt(150000))
+
+	tests := []struct {
+		name  string
+		promo database.ListOrderPromotionsRow
+		at    time.Time
+		want  string // "" when no promotion applies
+	}{
+		{"happy hour covers the category", happyHour, afternoon, "16000"},
+		{"happy hour outside its window", happyHour, afternoon.Add(2 * time.Hour), ""},
+		{"cheapest unit of each set is free", buy2get1, afternoon, "20000"},
+		{"bundle takes the dearest units", bundle, afternoon, "30000"},
+		{"minimum spend not reached", minSpend, afternoon, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, err := EvaluatePromotion(context.Background(), fakePromotionStore{tt.promo}, uuid.New(), uuid.New(), "", items, decimal.Zero, tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == "" {
+				if applied != nil {
+					t.Fatalf("expected no promotion, got %s", applied.Amount)
+				}
+				return
+			}
+			if applied == nil || !applied.Amount.Equal(decimal.RequireFromString(tt.want)) {
+				t.Fatalf("expected discount %s, got %+v", tt.want, applied)
+			}
+		})
+	}
+}
+
+func TestEvaluatePromotion_PicksLargestAutomatic(t *testing.T) {
+	small := testPromotion(enum.PromotionTypeFixed, "5000")
+	large := testPromotion(enum.PromotionTypePercentage, "10")
+	items := []PromotionItem{promotionLine(uuid.New(), uuid.New(), 1, 100000)}
+
+	applied, err := EvaluatePromotion(context.Background(), fakePromotionStore{small, large}, uuid.New(), uuid.New(), "", items, decimal.Zero, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied == nil || applied.ID != large.ID || !applied.Amount.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("expected the 10%% promotion, got %+v", applied)
+	}
+}
+
+func TestEvaluatePromotion_Codes(t *testing.T) {
+	items := []PromotionItem{promotionLine(uuid.New(), uuid.New(), 1, 50000)}
+	ctx := context.Background()
+
+	coded := testPromotion(enum.PromotionTypeFixed, "10000")
+	coded.Code = pgtype.Text{String: "HEMAT10", Valid: true}
+
+	// Coded promotions only apply when their code is entered
+	applied, err := EvaluatePromotion(ctx, fakePromotionStore{coded}, uuid.New(), uuid.New(), "", items, decimal.Zero, time.Now())
+	if err != nil || applied != nil {
+		t.Fatalf("without code: got %+v, %v", applied, err)
+	}
+	applied, err = EvaluatePromotion(ctx, fakePromotionStore{coded}, uuid.New(), uuid.New(), "HEMAT10", items, decimal.Zero, time.Now())
+	if err != nil || applied == nil || applied.ID != coded.ID {
+		t.Fatalf("with code: got %+v, %v", applied, err)
+	}
+
+	if _, err := EvaluatePromotion(ctx, fakePromotionStore{coded}, uuid.New(), uuid.New(), "NOPE", items, decimal.Zero, time.Now()); !errors.Is(err, ErrInvalidPromotionCode) {
+		t.Errorf("unknown code: got %v, want ErrInvalidPromotionCode", err)
+	}
+
+	expired := coded
+	expired.ValidUntil = pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}
+	if _, err := EvaluatePromotion(ctx, fakePromotionStore{expired}, uuid.New(), uuid.New(), "HEMAT10", items, decimal.Zero, time.Now()); !errors.Is(err, ErrInvalidPromotionCode) {
+		t.Errorf("expired code: got %v, want ErrInvalidPromotionCode", err)
+	}
+
+	usedUp := coded
+	usedUp.UsageLimit = pgtype.Int4{Int32: 100, Valid: true}
+	usedUp.UsedCount = 100
+	if _, err := EvaluatePromotion(ctx, fakePromotionStore{usedUp}, uuid.New(), uuid.New(), "HEMAT10", items, decimal.Zero, time.Now()); !errors.Is(err, ErrInvalidPromotionCode) {
+		t.Errorf("used up code: got %v, want ErrInvalidPromotionCode", err)
+	}
+
+	notMet := coded
+	notMet.MinSpend = decimalToNumeric(decimal.NewFromInt(75000))
+	if _, err := EvaluatePromotion(ctx, fakePromotionStore{notMet}, uuid.New(), uuid.New(), "HEMAT10", items, decimal.Zero, time.Now()); !errors.Is(err, ErrPromotionNotMet) {
+		t.Errorf("min spend: got %v, want ErrPromotionNotMet", err)
+	}
+}
+
+func TestEvaluatePromotion_CappedByManualDiscount(t *testing.T) {
+	promo := testPromotion(enum.PromotionTypeFixed, "20000")
+	items := []PromotionItem{promotionLine(uuid.New(), uuid.New(), 1, 50000)}
+
+	applied, err := EvaluatePromotion(context.Background(), fakePromotionStore{promo}, uuid.New(), uuid.New(), "", items, decimal.NewFromInt(40000), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied == nil || !applied.Amount.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("expected the discount capped at 10000, got %+v", applied)
+	}
+}
+
+// recordingPromotionStore records the calls made to it, in order.
+type recordingPromotionStore struct {
+	calls []string
+	code  pgtype.Text
+}
+
+func (r *recordingPromotionStore) LockOrderPromotions(_ context.Context, arg database.LockOrderPromotionsParams) error {
+	r.calls = append(r.calls, "lock")
+	r.code = arg.Code
+	return nil
+}
+
+func (r *recordingPromotionStore) ListOrderPromotions(_ context.Context, _ database.ListOrderPromotionsParams) ([]database.ListOrderPromotionsRow, error) {
+	r.calls = append(r.calls, "list")
+	return nil, nil
+}
+
+func TestEvaluatePromotion_LocksBeforeCountingUse(t *testing.T) {
+	store := &recordingPromotionStore{}
+	items := []PromotionItem{promotionLine(uuid.New(), uuid.New(), 1, 50000)}
+
+	if _, err := EvaluatePromotion(context.Background(), store, uuid.New(), uuid.New(), "", items, decimal.Zero, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.calls) != 2 || store.calls[0] != "lock" || store.calls[1] != "list" {
+		t.Errorf("calls: got %v, want [lock list]", store.calls)
+	}
+	if store.code.Valid {
+		t.Errorf("lock code: got %q, want none", store.code.String)
+	}
+}
+
+func TestInDailyWindow_WrapsPastMidnight(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	start, end := clock(21, 0), clock(2, 0)
+
+	for _, tt := range []struct {
+		hour int
+		want bool
+	}{{20, false}, {21, true}, {23, true}, {1, true}, {2, false}, {12, false}} {
+		at := time.Date(2026, 3, 2, tt.hour, 0, 0, 0, wib)
+		if got := inDailyWindow(start, end, at); got != tt.want {
+			t.Errorf("%02d:00: got %v, want %v", tt.hour, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+// Rules a variant or modifier selection can break.
+const (
+	RuleVariantRequired   = "VARIANT_REQUIRED"
+	RuleVariantMultiple   = "VARIANT_MULTIPLE"
+	RuleModifierMinSelect = "MODIFIER_MIN_SELECT"
+	RuleModifierMaxSelect = "MODIFIER_MAX_SELECT"
+)
+
+// SelectionStore defines the DB methods needed to check an item's variants
+// and modifiers against its product's groups.
+type SelectionStore interface {
+	GetVariantForOrder(ctx context.Context, id uuid.UUID) (database.GetVariantForOrderRow, error)
+	GetModifierForOrder(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error)
+	ListVariantGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error)
+	ListModifierGroupsForOrder(ctx context.Context, productID uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error)
+}
+
+// Selection is a validated set of variants and modifiers for one item.
+type Selection struct {
+	VariantIDs      []uuid.UUID
+	PriceAdjustment decimal.Decimal // sum of the variants' price adjustments
+	Modifiers       []SelectedModifier
+	ModifiersTotal  decimal.Decimal // sum of modifier price * quantity
+}
+
+// SelectedModifier is a modifier to insert with its price at order time.
+type SelectedModifier struct {
+	ModifierID uuid.UUID
+	Quantity   int32
+	UnitPrice  decimal.Decimal
+}
+
+// VariantID returns the first selected variant, stored in order_items.variant_id
+// for readers that only know a single variant.
+func (s *Selection) VariantID() pgtype.UUID {
+	if len(s.VariantIDs) == 0 {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: s.VariantIDs[0], Valid: true}
+}
+
+// GroupViolation is a variant or modifier group rule an item breaks.
+type GroupViolation struct {
+	GroupID   uuid.UUID
+	GroupName string
+	Rule      string
+	Message   string
+}
+
+// ItemSelectionError lists the group rules broken by the item at Index.
+type ItemSelectionError struct {
+	Index      int
+	ProductID  uuid.UUID
+	Violations []GroupViolation
+}
+
+// SelectionError is returned when one or more items break their product's
+// variant or modifier group rules. It carries every violation so clients can
+// point at each offending item at once.
+type SelectionError struct {
+	Items []ItemSelectionError
+}
+
+func (e *SelectionError) Error() string {
+	var msgs []string
+	for _, item := range e.Items {
+		for _, v := range item.Violations {
+			msgs = append(msgs, fmt.Sprintf("item[%d]: %s", item.Index, v.Message))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ResolveSelection looks up the chosen variants and modifiers, checks they
+// belong to productID, and checks the selection against the product's active
+// groups: one variant per group, required groups chosen, and each modifier
+// group's min_select/max_select. Repeated variant IDs count once.
+//
+// A malformed or unknown ID is returned as an error. Group rule violations are
+// returned separately so callers can report all items together; the Selection
+// is only usable when both are empty.
+func ResolveSelection(ctx context.Context, store SelectionStore, productID uuid.UUID, variantIDs []string, modifiers []CreateOrderItemModifierRequest) (*Selection, []GroupViolation, error) {
+	// VariantIDs is written to a NOT NULL array column, so never nil
+	sel := &Selection{VariantIDs: []uuid.UUID{}}
+
+	// --- Variants ---
+	variantsPerGroup := make(map[uuid.UUID]int)
+	seenVariants := make(map[uuid.UUID]bool)
+	for _, raw := range variantIDs {
+		vid, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, nil, ErrInvalidVariantID
+		}
+		if seenVariants[vid] {
+			continue
+		}
+		seenVariants[vid] = true
+
+		variant, err := store.GetVariantForOrder(ctx, vid)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil, ErrVariantNotFound
+			}
+			return nil, nil, fmt.Errorf("get variant: %w", err)
+		}
+		if variant.ProductID != productID {
+			return nil, nil, ErrVariantMismatch
+		}
+		variantsPerGroup[variant.VariantGroupID]++
+		sel.VariantIDs = append(sel.VariantIDs, vid)
+		sel.PriceAdjustment = sel.PriceAdjustment.Add(numericToDecimal(variant.PriceAdjustment))
+	}
+
+	// --- Modifiers ---
+	modifiersPerGroup := make(map[uuid.UUID]int)
+	seenModifiers := make(map[uuid.UUID]bool)
+	for j, mod := range modifiers {
+		if mod.Quantity <= 0 {
+			return nil, nil, fmt.Errorf("modifiers[%d]: %w", j, ErrInvalidQuantity)
+		}
+		modID, err := uuid.Parse(mod.ModifierID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("modifiers[%d]: %w", j, ErrInvalidModifierID)
+		}
+		modifier, err := store.GetModifierForOrder(ctx, modID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil, fmt.Errorf("modifiers[%d]: %w", j, ErrModifierNotFound)
+			}
+			return nil, nil, fmt.Errorf("modifiers[%d]: get modifier: %w", j, err)
+		}
+		if modifier.ProductID != productID {
+			return nil, nil, fmt.Errorf("modifiers[%d]: %w", j, ErrModifierMismatch)
+		}
+		if !seenModifiers[modID] {
+			seenModifiers[modID] = true
+			modifiersPerGroup[modifier.ModifierGroupID]++
+		}
+		modPrice := numericToDecimal(modifier.Price)
+		sel.ModifiersTotal = sel.ModifiersTotal.Add(modPrice.Mul(decimal.NewFromInt32(mod.Quantity)))
+		sel.Modifiers = append(sel.Modifiers, SelectedModifier{
+			ModifierID: modID,
+			Quantity:   mod.Quantity,
+			UnitPrice:  modPrice,
+		})
+	}
+
+	// --- Group rules ---
+	var violations []GroupViolation
+
+	variantGroups, err := store.ListVariantGroupsForOrder(ctx, productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list variant groups: %w", err)
+	}
+	for _, g := range variantGroups {
+		switch n := variantsPerGroup[g.ID]; {
+		case n == 0 && g.IsRequired:
+			violations = append(violations, GroupViolation{
+				GroupID: g.ID, GroupName: g.Name, Rule: RuleVariantRequired,
+				Message: fmt.Sprintf("%s is required", g.Name),
+			})
+		case n > 1:
+			violations = append(violations, GroupViolation{
+				GroupID: g.ID, GroupName: g.Name, Rule: RuleVariantMultiple,
+				Message: fmt.Sprintf("only one %s can be chosen", g.Name),
+			})
+		}
+	}
+
+	modifierGroups, err := store.ListModifierGroupsForOrder(ctx, productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list modifier groups: %w", err)
+	}
+	for _, g := range modifierGroups {
+		n := modifiersPerGroup[g.ID]
+		if n < int(g.MinSelect) {
+			violations = append(violations, GroupViolation{
+				GroupID: g.ID, GroupName: g.Name, Rule: RuleModifierMinSelect,
+				Message: fmt.Sprintf("choose at least %d from %s", g.MinSelect, g.Name),
+			})
+		}
+		if g.MaxSelect.Valid && n > int(g.MaxSelect.Int32) {
+			violations = append(violations, GroupViolation{
+				GroupID: g.ID, GroupName: g.Name, Rule: RuleModifierMaxSelect,
+				Message: fmt.Sprintf("choose at most %d from %s", g.MaxSelect.Int32, g.Name),
+			})
+		}
+	}
+
+	return sel, violations, nil
+}
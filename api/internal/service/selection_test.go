@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+)
+
+// menuStore is defaultStore with one product offering a required spice level
+// (Sedang +0, Pedas +2000), an optional size group (Jumbo +5000) and a
+// topping group allowing 1-2 choices (Telur 4000, Keju 5000, Sosis 6000).
+type menuStore struct {
+	*mockOrderStore
+	spiceGroup, sizeGroup, toppingGroup uuid.UUID
+	sedang, pedas, jumbo                uuid.UUID
+	telur, keju, sosis                  uuid.UUID
+}
+
+func newMenuStore(outletID, productID uuid.UUID) *menuStore {
+	m := &menuStore{
+		mockOrderStore: defaultStore(outletID, productID),
+		spiceGroup:     uuid.New(),
+		sizeGroup:      uuid.New(),
+		toppingGroup:   uuid.New(),
+		sedang:         uuid.New(),
+		pedas:          uuid.New(),
+		jumbo:          uuid.New(),
+		telur:          uuid.New(),
+		keju:           uuid.New(),
+		sosis:          uuid.New(),
+	}
+
+	variants := map[uuid.UUID]database.GetVariantForOrderRow{
+		m.sedang: {ID: m.sedang, VariantGroupID: m.spiceGroup, PriceAdjustment: makeNumeric("0"), ProductID: productID},
+		m.pedas:  {ID: m.pedas, VariantGroupID: m.spiceGroup, PriceAdjustment: makeNumeric("2000"), ProductID: productID},
+		m.jumbo:  {ID: m.jumbo, VariantGroupID: m.sizeGroup, PriceAdjustment: makeNumeric("5000"), ProductID: productID},
+	}
+	m.getVariantForOrderFn = func(ctx context.Context, id uuid.UUID) (database.GetVariantForOrderRow, error) {
+		if v, ok := variants[id]; ok {
+			return v, nil
+		}
+		return database.GetVariantForOrderRow{}, pgx.ErrNoRows
+	}
+
+	modifiers := map[uuid.UUID]database.GetModifierForOrderRow{
+		m.telur: {ID: m.telur, ModifierGroupID: m.toppingGroup, Price: makeNumeric("4000"), ProductID: productID},
+		m.keju:  {ID: m.keju, ModifierGroupID: m.toppingGroup, Price: makeNumeric("5000"), ProductID: productID},
+		m.sosis: {ID: m.sosis, ModifierGroupID: m.toppingGroup, Price: makeNumeric("6000"), ProductID: productID},
+	}
+	m.getModifierForOrderFn = func(ctx context.Context, id uuid.UUID) (database.GetModifierForOrderRow, error) {
+		if mod, ok := modifiers[id]; ok {
+			return mod, nil
+		}
+		return database.GetModifierForOrderRow{}, pgx.ErrNoRows
+	}
+
+	m.listVariantGroupsFn = func(ctx context.Context, pid uuid.UUID) ([]database.ListVariantGroupsForOrderRow, error) {
+		if pid != productID {
+			return nil, nil
+		}
+		return []database.ListVariantGroupsForOrderRow{
+			{ID: m.spiceGroup, Name: "Level Pedas", IsRequired: true},
+			{ID: m.sizeGroup, Name: "Ukuran", IsRequired: false},
+		}, nil
+	}
+	m.listModifierGroupsFn = func(ctx context.Context, pid uuid.UUID) ([]database.ListModifierGroupsForOrderRow, error) {
+		if pid != productID {
+			return nil, nil
+		}
+		return []database.ListModifierGroupsForOrderRow{
+			{ID: m.toppingGroup, Name: "Topping", MinSelect: 1, MaxSelect: pgtype.Int4{Int32: 2, Valid: true}},
+		}, nil
+	}
+	return m
+}
+
+func modifierReqs(ids ...uuid.UUID) []CreateOrderItemModifierRequest {
+	reqs := make([]CreateOrderItemModifierRequest, len(ids))
+	for i, id := range ids {
+		reqs[i] = CreateOrderItemModifierRequest{ModifierID: id.String(), Quantity: 1}
+	}
+	return reqs
+}
+
+func TestResolveSelection_Valid(t *testing.T) {
+	productID := uuid.New()
+	m := newMenuStore(uuid.New(), productID)
+
+	sel, violations, err := ResolveSelection(context.Background(), m, productID,
+		[]string{m.pedas.String(), m.jumbo.String(), m.pedas.String()},
+		modifierReqs(m.telur, m.keju),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("unexpected violations: %+v", violations)
+	}
+	if len(sel.VariantIDs) != 2 || sel.VariantIDs[0] != m.pedas || sel.VariantIDs[1] != m.jumbo {
+		t.Errorf("variant ids: got %v, want [pedas jumbo]", sel.VariantIDs)
+	}
+	if !sel.PriceAdjustment.Equal(numericToDecimal(makeNumeric("7000"))) {
+		t.Errorf("price adjustment: got %s, want 7000", sel.PriceAdjustment)
+	}
+	if !sel.ModifiersTotal.Equal(numericToDecimal(makeNumeric("9000"))) {
+		t.Errorf("modifiers total: got %s, want 9000", sel.ModifiersTotal)
+	}
+	if v := sel.VariantID(); !v.Valid || v.Bytes != m.pedas {
+		t.Errorf("primary variant: got %v, want pedas", v)
+	}
+}
+
+func TestResolveSelection_GroupRules(t *testing.T) {
+	productID := uuid.New()
+	m := newMenuStore(uuid.New(), productID)
+
+	tests := []struct {
+		name      string
+		variants  []uuid.UUID
+		modifiers []uuid.UUID
+		want      []string
+	}{
+		{"missing required variant", nil, []uuid.UUID{m.telur}, []string{RuleVariantRequired}},
+		{"two variants in one group", []uuid.UUID{m.sedang, m.pedas}, []uuid.UUID{m.telur}, []string{RuleVariantMultiple}},
+		{"below min_select", []uuid.UUID{m.sedang}, nil, []string{RuleModifierMinSelect}},
+		{"above max_select", []uuid.UUID{m.sedang}, []uuid.UUID{m.telur, m.keju, m.sosis}, []string{RuleModifierMaxSelect}},
+		{"several at once", nil, nil, []string{RuleVariantRequired, RuleModifierMinSelect}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variantIDs := make([]string, len(tt.variants))
+			for i, id := range tt.variants {
+				variantIDs[i] = id.String()
+			}
+			_, violations, err := ResolveSelection(context.Background(), m, productID, variantIDs, modifierReqs(tt.modifiers...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(violations) != len(tt.want) {
+				t.Fatalf("violations: got %+v, want rules %v", violations, tt.want)
+			}
+			for i, rule := range tt.want {
+				if violations[i].Rule != rule {
+					t.Errorf("violation[%d]: got %s, want %s", i, violations[i].Rule, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateOrder_SelectionErrorsReportedPerItem(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	m := newMenuStore(outletID, productID)
+	m.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		t.Error("order should not be created when items break group rules")
+		return database.Order{}, nil
+	}
+
+	svc, _ := newTestService(m.mockOrderStore)
+	req := basicReq(outletID, productID.String())
+	req.Items = []CreateOrderItemRequest{
+		{ProductID: productID.String(), Quantity: 1, Modifiers: modifierReqs(m.telur)},
+		{ProductID: productID.String(), VariantID: m.sedang.String(), Quantity: 1, Modifiers: modifierReqs(m.telur)},
+		{ProductID: productID.String(), VariantIDs: []string{m.sedang.String(), m.pedas.String()}, Quantity: 1},
+	}
+
+	_, err := svc.CreateOrder(context.Background(), req)
+	var selErr *SelectionError
+	if !errors.As(err, &selErr) {
+		t.Fatalf("expected *SelectionError, got %v", err)
+	}
+	if len(selErr.Items) != 2 {
+		t.Fatalf("items with violations: got %d, want 2", len(selErr.Items))
+	}
+	if selErr.Items[0].Index != 0 || selErr.Items[0].Violations[0].Rule != RuleVariantRequired {
+		t.Errorf("item 0: got %+v", selErr.Items[0])
+	}
+	if selErr.Items[1].Index != 2 || len(selErr.Items[1].Violations) != 2 {
+		t.Errorf("item 2: got %+v", selErr.Items[1])
+	}
+	if selErr.Items[1].ProductID != productID {
+		t.Errorf("product id: got %s, want %s", selErr.Items[1].ProductID, productID)
+	}
+}
+
+func TestCreateOrder_StoresAllVariants(t *testing.T) {
+	outletID := uuid.New()
+	productID := uuid.New()
+	m := newMenuStore(outletID, productID)
+
+	var captured database.CreateOrderItemParams
+	createItem := m.createOrderItemFn
+	m.createOrderItemFn = func(ctx context.Context, arg database.CreateOrderItemParams) (database.OrderItem, error) {
+		captured = arg
+		return createItem(ctx, arg)
+	}
+
+	svc, _ := newTestService(m.mockOrderStore)
+	req := basicReq(outletID, productID.String())
+	req.Items = []CreateOrderItemRequest{{
+		ProductID:  productID.String(),
+		VariantID:  m.pedas.String(),
+		VariantIDs: []string{m.jumbo.String()},
+		Quantity:   1,
+		Modifiers:  modifierReqs(m.keju),
+	}}
+
+	if _, err := svc.CreateOrder(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(captured.VariantIds) != 2 || !captured.VariantID.Valid || captured.VariantID.Bytes != m.pedas {
+		t.Errorf("variants: got variant_id %v, variant_ids %v", captured.VariantID, captured.VariantIds)
+	}
+	// unit_price = 25000 + 2000 + 5000; subtotal adds the 5000 topping
+	if !numericEquals(captured.UnitPrice, "32000") {
+		t.Errorf("unit_price: got %v, want 32000", numericToDecimal(captured.UnitPrice))
+	}
+	if !numericEquals(captured.Subtotal, "37000") {
+		t.Errorf("subtotal: got %v, want 37000", numericToDecimal(captured.Subtotal))
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// Errors returned when an offline order cannot be applied. Together with the
+// CreateOrder validation errors they mean the order conflicts with the
+// current server state and will not succeed on retry.
+var (
+	ErrSyncMissingID           = errors.New("id is required")
+	ErrSyncMissingCreatedAt    = errors.New("created_at is required")
+	ErrSyncCatering            = errors.New("CATERING orders cannot be synced from offline")
+	ErrSyncOrderIDTaken        = errors.New("order id is already used by another outlet")
+	ErrSyncInvalidTotal        = errors.New("invalid total_amount")
+	ErrSyncInvalidPayment      = errors.New("invalid payment")
+	ErrSyncPaymentsExceedTotal = errors.New("payments exceed order total")
+)
+
+// SyncOrderRequest is an order taken on a device while it was offline.
+// CreateOrderRequest.ID and CreatedAt are required.
+type SyncOrderRequest struct {
+	CreateOrderRequest
+	// TotalAmount is the total the device charged. The sale has been made, so
+	// when the server prices the order differently (menu prices changed while
+	// the device was offline) the order keeps this total and the difference
+	// is recorded in its history.
+	TotalAmount string
+	Payments    []SyncPaymentRequest
+}
+
+// SyncPaymentRequest is a payment taken offline.
+type SyncPaymentRequest struct {
+	PaymentMethod   string
+	Amount          string
+	AmountReceived  string
+	ReferenceNumber string
+	ProcessedAt     time.Time // zero means the order's CreatedAt
+}
+
+// SyncOrderResult is the outcome of applying one offline order.
+// AlreadySynced is true when the order had been applied by an earlier sync;
+// Order is then the stored order and Items and Payments are empty.
+// ServerTotal is set when the order kept a device total that differs from
+// the server's pricing, and holds the server's total.
+type SyncOrderResult struct {
+	CreateOrderResult
+	Payments      []database.Payment
+	AlreadySynced bool
+	ServerTotal   *decimal.Decimal
+}
+
+// SyncOrder applies an offline order atomically: the order is priced against
+// the current menu like CreateOrder, its payments are recorded with their
+// original times, and it is completed if fully paid. Applying the same order
+// ID again returns the stored order instead of creating a duplicate.
+func (s *OrderService) SyncOrder(ctx context.Context, req SyncOrderRequest) (*SyncOrderResult, error) {
+	if req.ID == uuid.Nil {
+		return nil, ErrSyncMissingID
+	}
+	if req.CreatedAt.IsZero() {
+		return nil, ErrSyncMissingCreatedAt
+	}
+	orderType, err := validateCreateOrder(req.CreateOrderRequest)
+	if err != nil {
+		return nil, err
+	}
+	if orderType == enum.OrderTypeCatering {
+		return nil, ErrSyncCatering
+	}
+
+	var deviceTotal *decimal.Decimal
+	if req.TotalAmount != "" {
+		t, err := decimal.NewFromString(req.TotalAmount)
+		if err != nil || t.IsNegative() {
+			return nil, ErrSyncInvalidTotal
+		}
+		deviceTotal = &t
+	}
+
+	payments, err := validateSyncPayments(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxOrderNumberRetries; attempt++ {
+		result, err := s.syncOrderTx(ctx, req, orderType, deviceTotal, payments)
+		if err == nil {
+			return result, nil
+		}
+		if isOrderNumberConflict(err) {
+			lastErr = err
+			continue
+		}
+		return nil, err
+	}
+	return nil, lastErr
+}
+
+func (s *OrderService) syncOrderTx(ctx context.Context, req SyncOrderRequest, orderType string, deviceTotal *decimal.Decimal, payments []database.CreatePaymentParams) (*SyncOrderResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	store := s.newStore(tx)
+
+	existing, err := store.GetOrder(ctx, database.GetOrderParams{ID: req.ID, OutletID: req.OutletID})
+	if err == nil {
+		return &SyncOrderResult{
+			CreateOrderResult: CreateOrderResult{Order: existing},
+			AlreadySynced:     true,
+		}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get existing order: %w", err)
+	}
+
+	created, err := insertOrder(ctx, store, req.CreateOrderRequest, orderType)
+	if err != nil {
+		if isOrderIDConflict(err) {
+			return nil, ErrSyncOrderIDTaken
+		}
+		return nil, err
+	}
+	result := &SyncOrderResult{CreateOrderResult: *created}
+
+	total := numericToDecimal(created.Order.TotalAmount)
+	if deviceTotal != nil && !deviceTotal.Equal(total) {
+		adjusted, err := store.SetSyncedOrderTotal(ctx, database.SetSyncedOrderTotalParams{
+			ID:          created.Order.ID,
+			TotalAmount: decimalToNumeric(*deviceTotal),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("set synced order total: %w", err)
+		}
+		if err := RecordOrderEvent(ctx, store, adjusted.ID, req.CreatedBy, enum.OrderEventPriceAdjusted,
+			map[string]string{"total_amount": total.StringFixed(2)},
+			map[string]string{"total_amount": deviceTotal.StringFixed(2)},
+		); err != nil {
+			return nil, err
+		}
+		serverTotal := total
+		result.ServerTotal = &serverTotal
+		result.Order = adjusted
+		total = *deviceTotal
+	}
+
+	paid := decimal.Zero
+	lastPaidAt := req.CreatedAt
+	for _, p := range payments {
+		paid = paid.Add(numericToDecimal(p.Amount))
+	}
+	if paid.GreaterThan(total) {
+		return nil, fmt.Errorf("%w: server total is %s", ErrSyncPaymentsExceedTotal, total.StringFixed(2))
+	}
+
+	for _, p := range payments {
+		p.OrderID = created.Order.ID
+		payment, err := store.CreatePayment(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("create payment: %w", err)
+		}
+		if err := RecordOrderEvent(ctx, store, payment.OrderID, req.CreatedBy, enum.OrderEventPaymentAdded, nil, map[string]interface{}{
+			"payment_id":     payment.ID,
+			"payment_method": payment.PaymentMethod,
+			"amount":         numericToDecimal(payment.Amount).StringFixed(2),
+		}); err != nil {
+			return nil, err
+		}
+		if p.ProcessedAt.Time.After(lastPaidAt) {
+			lastPaidAt = p.ProcessedAt.Time
+		}
+		result.Payments = append(result.Payments, payment)
+	}
+
+	if len(payments) > 0 && paid.Equal(total) {
+		completed, err := store.CompleteSyncedOrder(ctx, database.CompleteSyncedOrderParams{
+			ID:          created.Order.ID,
+			CompletedAt: pgtype.Timestamptz{Time: lastPaidAt, Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("complete synced order: %w", err)
+		}
+		if err := RecordOrderEvent(ctx, store, completed.ID, req.CreatedBy, enum.OrderEventStatusChanged,
+			map[string]string{"status": created.Order.Status},
+			map[string]string{"status": completed.Status},
+		); err != nil {
+			return nil, err
+		}
+		if err := EarnOrderPoints(ctx, store, completed, req.CreatedBy); err != nil {
+			return nil, err
+		}
+		result.Order = completed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return result, nil
+}
+
+// validateSyncPayments parses the offline payments into insert params.
+// OrderID is filled in once the order exists.
+func validateSyncPayments(req SyncOrderRequest) ([]database.CreatePaymentParams, error) {
+	params := make([]database.CreatePaymentParams, len(req.Payments))
+	for i, p := range req.Payments {
+		switch p.PaymentMethod {
+		case enum.PaymentMethodCash, enum.PaymentMethodQRIS, enum.PaymentMethodTransfer:
+		default:
+			return nil, fmt.Errorf("payments[%d]: %w: invalid payment_method", i, ErrSyncInvalidPayment)
+		}
+
+		amount, err := decimal.NewFromString(p.Amount)
+		if err != nil || !amount.IsPositive() {
+			return nil, fmt.Errorf("payments[%d]: %w: amount must be > 0", i, ErrSyncInvalidPayment)
+		}
+
+		amountReceived := pgtype.Numeric{}
+		changeAmount := pgtype.Numeric{}
+		if p.PaymentMethod == enum.PaymentMethodCash {
+			received, err := decimal.NewFromString(p.AmountReceived)
+			if err != nil || received.LessThan(amount) {
+				return nil, fmt.Errorf("payments[%d]: %w: amount_received must cover amount", i, ErrSyncInvalidPayment)
+			}
+			amountReceived = decimalToNumeric(received)
+			changeAmount = decimalToNumeric(received.Sub(amount))
+		}
+
+		referenceNumber := pgtype.Text{}
+		if p.ReferenceNumber != "" {
+			referenceNumber = pgtype.Text{String: p.ReferenceNumber, Valid: true}
+		}
+
+		processedAt := p.ProcessedAt
+		if processedAt.IsZero() {
+			processedAt = req.CreatedAt
+		}
+
+		params[i] = database.CreatePaymentParams{
+			PaymentMethod:   p.PaymentMethod,
+			Amount:          decimalToNumeric(amount),
+			Status:          enum.PaymentStatusCompleted,
+			ReferenceNumber: referenceNumber,
+			AmountReceived:  amountReceived,
+			ChangeAmount:    changeAmount,
+			ProcessedBy:     req.CreatedBy,
+			ProcessedAt:     pgtype.Timestamptz{Time: processedAt, Valid: true},
+		}
+	}
+	return params, nil
+}
+
+// isOrderIDConflict checks if the error is a primary key violation on orders,
+// meaning the client-generated ID already belongs to another outlet's order.
+func isOrderIDConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == "orders_pkey"
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kiwari-pos/api/internal/database"
+	"github.com/kiwari-pos/api/internal/enum"
+	"github.com/shopspring/decimal"
+)
+
+// syncStore extends defaultStore with the offline sync methods. Orders keep
+// the client ID and creation time passed to CreateOrder.
+func syncStore(outletID, productID uuid.UUID) *mockOrderStore {
+	store := defaultStore(outletID, productID)
+	store.getOrderFn = func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+		return database.Order{}, pgx.ErrNoRows
+	}
+	store.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		return database.Order{
+			ID:          arg.ID.Bytes,
+			OutletID:    arg.OutletID,
+			OrderNumber: arg.OrderNumber,
+			OrderType:   arg.OrderType,
+			Status:      enum.OrderStatusNew,
+			Subtotal:    arg.Subtotal,
+			TotalAmount: arg.TotalAmount,
+			CreatedBy:   arg.CreatedBy,
+			CreatedAt:   arg.CreatedAt.Time,
+		}, nil
+	}
+	store.createPaymentFn = func(ctx context.Context, arg database.CreatePaymentParams) (database.Payment, error) {
+		return database.Payment{
+			ID:            uuid.New(),
+			OrderID:       arg.OrderID,
+			PaymentMethod: arg.PaymentMethod,
+			Amount:        arg.Amount,
+			Status:        arg.Status,
+			ProcessedBy:   arg.ProcessedBy,
+			ProcessedAt:   arg.ProcessedAt.Time,
+		}, nil
+	}
+	store.completeSyncedOrderFn = func(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error) {
+		return database.Order{ID: arg.ID, Status: enum.OrderStatusCompleted, CompletedAt: arg.CompletedAt}, nil
+	}
+	store.setSyncedOrderTotalFn = func(ctx context.Context, arg database.SetSyncedOrderTotalParams) (database.Order, error) {
+		return database.Order{ID: arg.ID, Status: enum.OrderStatusNew, TotalAmount: arg.TotalAmount}, nil
+	}
+	return store
+}
+
+func basicSyncReq(outletID uuid.UUID, productID string) SyncOrderRequest {
+	req := basicReq(outletID, productID)
+	req.ID = uuid.New()
+	req.CreatedAt = time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	return SyncOrderRequest{CreateOrderRequest: req}
+}
+
+func TestSyncOrder_KeepsClientIDAndTimes(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+
+	var created database.CreateOrderParams
+	createOrder := store.createOrderFn
+	store.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		created = arg
+		return createOrder(ctx, arg)
+	}
+	var completed database.CompleteSyncedOrderParams
+	store.completeSyncedOrderFn = func(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error) {
+		completed = arg
+		return database.Order{ID: arg.ID, Status: enum.OrderStatusCompleted, CompletedAt: arg.CompletedAt}, nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicSyncReq(outletID, productID.String())
+	req.TotalAmount = "50000"
+	paidAt := req.CreatedAt.Add(5 * time.Minute)
+	req.Payments = []SyncPaymentRequest{
+		{PaymentMethod: enum.PaymentMethodCash, Amount: "50000", AmountReceived: "100000", ProcessedAt: paidAt},
+	}
+
+	result, err := svc.SyncOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !created.ID.Valid || created.ID.Bytes != req.ID {
+		t.Errorf("order id: got %v, want %s", created.ID, req.ID)
+	}
+	if !created.CreatedAt.Valid || !created.CreatedAt.Time.Equal(req.CreatedAt) {
+		t.Errorf("created_at: got %v, want %s", created.CreatedAt, req.CreatedAt)
+	}
+	if len(result.Payments) != 1 || !result.Payments[0].ProcessedAt.Equal(paidAt) {
+		t.Errorf("payment processed_at: got %+v, want %s", result.Payments, paidAt)
+	}
+	if !numericEquals(result.Payments[0].Amount, "50000") {
+		t.Errorf("payment amount: got %v, want 50000", numericToDecimal(result.Payments[0].Amount))
+	}
+	if !completed.CompletedAt.Time.Equal(paidAt) {
+		t.Errorf("completed_at: got %s, want %s", completed.CompletedAt.Time, paidAt)
+	}
+	if result.Order.Status != enum.OrderStatusCompleted {
+		t.Errorf("status: got %s, want COMPLETED", result.Order.Status)
+	}
+	if result.AlreadySynced {
+		t.Error("new order reported as already synced")
+	}
+}
+
+func TestSyncOrder_PartialPaymentStaysOpen(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+	store.completeSyncedOrderFn = func(ctx context.Context, arg database.CompleteSyncedOrderParams) (database.Order, error) {
+		t.Error("partially paid order should not be completed")
+		return database.Order{}, nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicSyncReq(outletID, productID.String())
+	req.Payments = []SyncPaymentRequest{
+		{PaymentMethod: enum.PaymentMethodQRIS, Amount: "20000"},
+	}
+
+	result, err := svc.SyncOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Order.Status != enum.OrderStatusNew {
+		t.Errorf("status: got %s, want NEW", result.Order.Status)
+	}
+	// Payment time defaults to the order's offline creation time
+	if !result.Payments[0].ProcessedAt.Equal(req.CreatedAt) {
+		t.Errorf("processed_at: got %s, want %s", result.Payments[0].ProcessedAt, req.CreatedAt)
+	}
+}
+
+func TestSyncOrder_AlreadySynced(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+	req := basicSyncReq(outletID, productID.String())
+
+	store.getOrderFn = func(ctx context.Context, arg database.GetOrderParams) (database.Order, error) {
+		return database.Order{ID: arg.ID, OutletID: arg.OutletID, OrderNumber: "KWR-007", Status: enum.OrderStatusCompleted}, nil
+	}
+	store.createOrderFn = func(ctx context.Context, arg database.CreateOrderParams) (database.Order, error) {
+		t.Error("already synced order should not be created again")
+		return database.Order{}, nil
+	}
+
+	svc, _ := newTestService(store)
+	result, err := svc.SyncOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.AlreadySynced {
+		t.Error("expected AlreadySynced")
+	}
+	if result.Order.OrderNumber != "KWR-007" {
+		t.Errorf("order_number: got %s, want KWR-007", result.Order.OrderNumber)
+	}
+}
+
+func TestSyncOrder_ProductDeactivated(t *testing.T) {
+	outletID := uuid.New()
+	store := syncStore(outletID, uuid.New())
+
+	svc, _ := newTestService(store)
+	_, err := svc.SyncOrder(context.Background(), basicSyncReq(outletID, uuid.New().String()))
+	if !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+// An order sold at the device's prices before a price change keeps the
+// device's total; the server's total is reported and recorded.
+func TestSyncOrder_KeepsDeviceTotal(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+	var events []database.CreateOrderEventParams
+	store.createOrderEventFn = func(ctx context.Context, arg database.CreateOrderEventParams) error {
+		events = append(events, arg)
+		return nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicSyncReq(outletID, productID.String())
+	req.TotalAmount = "45000"
+	req.Payments = []SyncPaymentRequest{
+		{PaymentMethod: enum.PaymentMethodCash, Amount: "45000", AmountReceived: "50000"},
+	}
+
+	result, err := svc.SyncOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ServerTotal == nil || !result.ServerTotal.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("server total: got %v, want 50000", result.ServerTotal)
+	}
+	if result.Order.Status != enum.OrderStatusCompleted {
+		t.Errorf("status: got %s, want COMPLETED (paid in full at the device's total)", result.Order.Status)
+	}
+	var adjusted []database.CreateOrderEventParams
+	for _, e := range events {
+		if e.EventType == enum.OrderEventPriceAdjusted {
+			adjusted = append(adjusted, e)
+		}
+	}
+	if len(adjusted) != 1 || string(adjusted[0].BeforeValue) != `{"total_amount":"50000.00"}` || string(adjusted[0].AfterValue) != `{"total_amount":"45000.00"}` {
+		t.Errorf("PRICE_ADJUSTED events: got %+v", adjusted)
+	}
+}
+
+func TestSyncOrder_MatchingTotalNotAdjusted(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+	store.setSyncedOrderTotalFn = func(ctx context.Context, arg database.SetSyncedOrderTotalParams) (database.Order, error) {
+		t.Error("total should not be adjusted when it matches")
+		return database.Order{}, nil
+	}
+
+	svc, _ := newTestService(store)
+	req := basicSyncReq(outletID, productID.String())
+	req.TotalAmount = "50000.00"
+
+	result, err := svc.SyncOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ServerTotal != nil {
+		t.Errorf("server total: got %s, want none", result.ServerTotal)
+	}
+}
+
+func TestSyncOrder_InvalidTotal(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+
+	svc, _ := newTestService(store)
+	for _, total := range []string{"abc", "-1"} {
+		req := basicSyncReq(outletID, productID.String())
+		req.TotalAmount = total
+		if _, err := svc.SyncOrder(context.Background(), req); !errors.Is(err, ErrSyncInvalidTotal) {
+			t.Errorf("total %q: expected ErrSyncInvalidTotal, got %v", total, err)
+		}
+	}
+}
+
+func TestSyncOrder_PaymentsExceedTotal(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+	store := syncStore(outletID, productID)
+
+	svc, _ := newTestService(store)
+	req := basicSyncReq(outletID, productID.String())
+	req.Payments = []SyncPaymentRequest{
+		{PaymentMethod: enum.PaymentMethodTransfer, Amount: "60000"},
+	}
+
+	_, err := svc.SyncOrder(context.Background(), req)
+	if !errors.Is(err, ErrSyncPaymentsExceedTotal) {
+		t.Fatalf("expected ErrSyncPaymentsExceedTotal, got %v", err)
+	}
+}
+
+func TestSyncOrder_Validation(t *testing.T) {
+	outletID, productID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name   string
+		modify func(*SyncOrderRequest)
+		want   error
+	}{
+		{"missing id", func(r *SyncOrderRequest) { r.ID = uuid.Nil }, ErrSyncMissingID},
+		{"missing created_at", func(r *SyncOrderRequest) { r.CreatedAt = time.Time{} }, ErrSyncMissingCreatedAt},
+		{"catering", func(r *SyncOrderRequest) {
+			r.OrderType = enum.OrderTypeCatering
+			r.CateringDate = "2026-03-02T10:00:00Z"
+			r.CustomerID = uuid.New().String()
+		}, ErrSyncCatering},
+		{"invalid payment method", func(r *SyncOrderRequest) {
+			r.Payments = []SyncPaymentRequest{{PaymentMethod: "CARD", Amount: "1000"}}
+		}, ErrSyncInvalidPayment},
+		{"cash without amount_received", func(r *SyncOrderRequest) {
+			r.Payments = []SyncPaymentRequest{{PaymentMethod: enum.PaymentMethodCash, Amount: "1000"}}
+		}, ErrSyncInvalidPayment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(syncStore(outletID, productID))
+			req := basicSyncReq(outletID, productID.String())
+			tt.modify(&req)
+			_, err := svc.SyncOrder(context.Background(), req)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+// TableStatus derives a dining table's live status from its active orders.
+// occupiedSince is when the oldest active order was placed. A bill request
+// only counts if it was made while the current party was seated, so it
+// clears itself once the table turns over.
+func TableStatus(activeOrders int, occupiedSince time.Time, billRequestedAt pgtype.Timestamptz) string {
+	if activeOrders == 0 {
+		return enum.TableStatusFree
+	}
+	if billRequestedAt.Valid && !billRequestedAt.Time.Before(occupiedSince) {
+		return enum.TableStatusBillRequested
+	}
+	return enum.TableStatusOccupied
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kiwari-pos/api/internal/enum"
+)
+
+func TestTableStatus(t *testing.T) {
+	seated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: seated.Add(d), Valid: true}
+	}
+
+	tests := []struct {
+		name          string
+		activeOrders  int
+		billRequested pgtype.Timestamptz
+		want          string
+	}{
+		{"no orders", 0, pgtype.Timestamptz{}, enum.TableStatusFree},
+		{"no orders, stale bill request", 0, at(-time.Hour), enum.TableStatusFree},
+		{"seated", 2, pgtype.Timestamptz{}, enum.TableStatusOccupied},
+		{"bill requested by this party", 1, at(45 * time.Minute), enum.TableStatusBillRequested},
+		{"bill requested by previous party", 1, at(-time.Hour), enum.TableStatusOccupied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TableStatus(tt.activeOrders, seated, tt.billRequested); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -21,8 +22,8 @@ const (
 	// Send pings to peer with this period (must be less than pongWait)
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer
-	maxMessageSize = 512
+	// Maximum message size allowed from peer (one command)
+	maxMessageSize = 4096
 )
 
 var upgrader = websocket.Upgrader{
@@ -38,12 +39,21 @@ type Client struct {
 	hub      *Hub
 	conn     *websocket.Conn
 	outletID uuid.UUID
+	sub      Subscription
 	send     chan []byte
+
+	// Sender identity and handler for inbound commands
+	claims   *auth.Claims
+	commands CommandHandler
+
+	// Set when reconnecting with ?since=<seq>: the hub replays later events
+	replay bool
+	since  uint64
 }
 
 // ReadPump pumps messages from the WebSocket connection to the hub
 // The application runs ReadPump in a per-connection goroutine
-// For a POS system, clients don't send messages - we just detect disconnects
+// Inbound messages are commands, handled one at a time and answered to this client
 func (c *Client) ReadPump() {
 	defer func() {
 		c.hub.unregister <- c
@@ -57,14 +67,16 @@ func (c *Client) ReadPump() {
 		return nil
 	})
 
-	// Read loop - we just wait for disconnect or errors
+	// Read loop - handle commands until disconnect or errors
 	for {
-		if _, _, err := c.conn.ReadMessage(); err != nil {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("websocket error: %v", err)
 			}
 			break
 		}
+		c.handleCommand(message)
 	}
 }
 
@@ -114,8 +126,10 @@ func (c *Client) WritePump() {
 }
 
 // ServeWS handles WebSocket requests from clients
-// Endpoint: WS /ws/outlets/:oid/orders?token=JWT
-func ServeWS(hub *Hub, jwtSecret string, w http.ResponseWriter, r *http.Request) {
+// commands may be nil, in which case inbound commands are rejected
+// Endpoint: WS /ws/outlets/:oid/orders?token=JWT[&stations=GRILL,RICE][&events=order_item.added,...][&since=SEQ]
+// since is the last seq the client received; missed events are sent first.
+func ServeWS(hub *Hub, jwtSecret string, commands CommandHandler, w http.ResponseWriter, r *http.Request) {
 	// 1. Extract token from query param
 	tokenStr := r.URL.Query().Get("token")
 	if tokenStr == "" {
@@ -139,28 +153,94 @@ func ServeWS(hub *Hub, jwtSecret string, w http.ResponseWriter, r *http.Request)
 	}
 
 	// 4. Verify outlet access (OWNER can access any outlet, others only their own)
-	if claims.Role != "OWNER" && claims.OutletID != outletID {
+	if !canAccessOutlet(claims, outletID) {
 		http.Error(w, "outlet access denied", http.StatusForbidden)
 		return
 	}
 
-	// 5. Upgrade to WebSocket
+	// 5. Parse optional station and event type filters and replay position
+	sub, err := ParseSubscription(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	replay, since, err := parseSince(r)
+	if err != nil {
+		http.Error(w, "invalid since", http.StatusBadRequest)
+		return
+	}
+
+	// 6. Upgrade to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("websocket upgrade error: %v", err)
 		return
 	}
 
-	// 6. Create client and register with hub
+	// 7. Create client and register with hub
 	client := &Client{
 		hub:      hub,
 		conn:     conn,
 		outletID: outletID,
+		sub:      sub,
 		send:     make(chan []byte, 256),
+		replay:   replay,
+		since:    since,
+		claims:   claims,
+		commands: commands,
+	}
+	client.hub.register <- client
+
+	// 8. Start pumps in separate goroutines
+	go client.WritePump()
+	go client.ReadPump()
+}
+
+// ServePickupDisplay handles WebSocket requests from customer pickup screens.
+// The caller has already resolved the display token to outletID. The client
+// receives pickup events only and cannot send commands.
+// Endpoint: WS /ws/display/pickup/:token[?since=SEQ]
+func ServePickupDisplay(hub *Hub, outletID uuid.UUID, w http.ResponseWriter, r *http.Request) {
+	replay, since, err := parseSince(r)
+	if err != nil {
+		http.Error(w, "invalid since", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade error: %v", err)
+		return
+	}
+
+	client := &Client{
+		hub:      hub,
+		conn:     conn,
+		outletID: outletID,
+		sub: Subscription{Events: map[string]bool{
+			EventPickupOrderUpdated: true,
+			EventPickupOrderRemoved: true,
+		}},
+		send:   make(chan []byte, 256),
+		replay: replay,
+		since:  since,
 	}
 	client.hub.register <- client
 
-	// 7. Start pumps in separate goroutines
 	go client.WritePump()
 	go client.ReadPump()
 }
+
+// parseSince reads the optional ?since=<seq> replay position.
+func parseSince(r *http.Request) (replay bool, since uint64, err error) {
+	sinceStr := r.URL.Query().Get("since")
+	if sinceStr == "" {
+		return false, 0, nil
+	}
+	since, err = strconv.ParseUint(sinceStr, 10, 64)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, since, nil
+}
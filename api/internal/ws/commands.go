@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/auth"
+)
+
+// Commands kitchen displays send over their socket.
+const (
+	CommandItemBump     = "item.bump"         // advance an item's status
+	CommandTicketRecall = "ticket.recall"     // move READY items back to PREPARING
+	CommandOrderAck     = "order.acknowledge" // kitchen has seen a new order
+)
+
+// Command error codes, mirroring the HTTP status a REST call would return.
+const (
+	CommandErrBadRequest   = "BAD_REQUEST"
+	CommandErrUnauthorized = "UNAUTHORIZED"
+	CommandErrForbidden    = "FORBIDDEN"
+	CommandErrNotFound     = "NOT_FOUND"
+	CommandErrConflict     = "CONFLICT"
+	CommandErrInternal     = "INTERNAL"
+)
+
+// Time allowed to handle one command
+const commandTimeout = 10 * time.Second
+
+// Command is a message from a client. ID is chosen by the client and echoed
+// in the reply so it can match acks to commands.
+type Command struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// CommandRequest is a command with the sender's identity and subscription.
+type CommandRequest struct {
+	Command
+	Claims   *auth.Claims
+	OutletID uuid.UUID
+	Stations map[string]bool // empty when the client is not station-filtered
+}
+
+// CommandError is a command failure reported to the sender.
+type CommandError struct {
+	Code    string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+// CommandHandler carries out commands. Satisfied by the order handler.
+type CommandHandler interface {
+	HandleCommand(ctx context.Context, req CommandRequest) (interface{}, error)
+}
+
+// commandReply is the payload of command.ack and command.error events.
+type commandReply struct {
+	ID      string      `json:"id"`
+	Command string      `json:"command"`
+	Result  interface{} `json:"result,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// canAccessOutlet applies the outlet rule of the REST API: OWNER can access
+// any outlet, others only their own.
+func canAccessOutlet(claims *auth.Claims, outletID uuid.UUID) bool {
+	return claims.Role == "OWNER" || claims.OutletID == outletID
+}
+
+// handleCommand runs one inbound message and replies to the sender.
+func (c *Client) handleCommand(message []byte) {
+	var cmd Command
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Type == "" {
+		c.replyError(cmd, &CommandError{Code: CommandErrBadRequest, Message: "invalid command"})
+		return
+	}
+
+	if c.commands == nil {
+		c.replyError(cmd, &CommandError{Code: CommandErrBadRequest, Message: "commands are not supported"})
+		return
+	}
+	// The token was checked on connect; the socket may outlive it
+	if exp := c.claims.ExpiresAt; exp != nil && exp.Before(time.Now()) {
+		c.replyError(cmd, &CommandError{Code: CommandErrUnauthorized, Message: "token expired"})
+		return
+	}
+	if !canAccessOutlet(c.claims, c.outletID) {
+		c.replyError(cmd, &CommandError{Code: CommandErrForbidden, Message: "outlet access denied"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	result, err := c.commands.HandleCommand(ctx, CommandRequest{
+		Command:  cmd,
+		Claims:   c.claims,
+		OutletID: c.outletID,
+		Stations: c.sub.Stations,
+	})
+	if err != nil {
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) {
+			log.Printf("ERROR: ws command %s: %v", cmd.Type, err)
+			cmdErr = &CommandError{Code: CommandErrInternal, Message: "internal server error"}
+		}
+		c.replyError(cmd, cmdErr)
+		return
+	}
+
+	c.reply(EventCommandAck, commandReply{ID: cmd.ID, Command: cmd.Type, Result: result})
+}
+
+func (c *Client) replyError(cmd Command, err *CommandError) {
+	c.reply(EventCommandError, commandReply{ID: cmd.ID, Command: cmd.Type, Code: err.Code, Error: err.Message})
+}
+
+func (c *Client) reply(eventType string, reply commandReply) {
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("ERROR: marshal %s: %v", eventType, err)
+		return
+	}
+	message, err := json.Marshal(Event{Type: eventType, Payload: payload})
+	if err != nil {
+		log.Printf("ERROR: marshal %s: %v", eventType, err)
+		return
+	}
+	c.hub.direct <- &clientMessage{client: c, message: message}
+}
@@ -0,0 +1,71 @@
+package ws
+
+// Event types broadcast to outlet rooms.
+// Order lifecycle events carry the full order (items, modifiers, payments) as payload.
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderCancelled     = "order.cancelled"
+	EventOrderTableChanged  = "order.table_changed"
+	EventOrdersMerged       = "order.merged"
+	EventOrderAcknowledged  = "order.acknowledged"
+
+	EventOrderItemAdded         = "order_item.added"
+	EventOrderItemUpdated       = "order_item.updated"
+	EventOrderItemRemoved       = "order_item.removed"
+	EventOrderItemStatusChanged = "order_item.status_changed"
+	EventOrderItemLate          = "order_item.late"
+
+	EventPaymentAdded    = "payment.added"
+	EventPaymentRefunded = "payment.refunded"
+
+	// EventKitchenAllDay carries the outlet's kitchen queue grouped by dish
+	// after every change to it. Opt-in: only sent to clients that ask for it.
+	EventKitchenAllDay = "kitchen.all_day"
+
+	// EventKitchenTicketCreated announces a printed kitchen ticket to its
+	// station. Opt-in, for printer clients.
+	EventKitchenTicketCreated = "kitchen.ticket_created"
+
+	// Pickup screen events carry only an order number and its state.
+	// Opt-in; pickup display clients receive nothing else.
+	EventPickupOrderUpdated = "pickup.order_updated"
+	EventPickupOrderRemoved = "pickup.order_removed"
+
+	// EventResyncRequired is sent instead of a replay when a reconnecting
+	// client's missed events are no longer buffered.
+	EventResyncRequired = "resync_required"
+
+	// Replies to a client's own command; never broadcast.
+	EventCommandAck   = "command.ack"
+	EventCommandError = "command.error"
+)
+
+// knownEvents lists the event types a client may subscribe to.
+var knownEvents = map[string]bool{
+	EventOrderCreated:           true,
+	EventOrderStatusChanged:     true,
+	EventOrderCancelled:         true,
+	EventOrderTableChanged:      true,
+	EventOrdersMerged:           true,
+	EventOrderAcknowledged:      true,
+	EventOrderItemAdded:         true,
+	EventOrderItemUpdated:       true,
+	EventOrderItemRemoved:       true,
+	EventOrderItemStatusChanged: true,
+	EventOrderItemLate:          true,
+	EventPaymentAdded:           true,
+	EventPaymentRefunded:        true,
+	EventKitchenAllDay:          true,
+	EventKitchenTicketCreated:   true,
+	EventPickupOrderUpdated:     true,
+	EventPickupOrderRemoved:     true,
+}
+
+// optInEvents are only sent to clients that list them in their subscription.
+var optInEvents = map[string]bool{
+	EventKitchenAllDay:        true,
+	EventKitchenTicketCreated: true,
+	EventPickupOrderUpdated:   true,
+	EventPickupOrderRemoved:   true,
+}
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"encoding/json"
+	"log"
 	"sync"
 
 	"github.com/google/uuid"
@@ -10,7 +11,12 @@ import (
 // Event represents a WebSocket message to be broadcast
 type Event struct {
 	Type    string          `json:"type"`
+	Seq     uint64          `json:"seq,omitempty"` // per-outlet, assigned by the hub
 	Payload json.RawMessage `json:"payload"`
+
+	// Stations whose kitchen work this event touches. Not sent to clients;
+	// used to route events to station-filtered subscriptions.
+	Stations []string `json:"-"`
 }
 
 // outletEvent is an internal struct for routing events to specific outlets
@@ -19,11 +25,20 @@ type outletEvent struct {
 	Event    Event
 }
 
+// clientMessage is a message for one client, such as a command reply
+type clientMessage struct {
+	client  *Client
+	message []byte
+}
+
 // Hub maintains the set of active clients and broadcasts messages to them
 type Hub struct {
 	// Registered clients by outlet ID
 	rooms map[uuid.UUID]map[*Client]bool
 
+	// Sequence numbers and recent events by outlet ID, kept while rooms are empty
+	logs map[uuid.UUID]*eventLog
+
 	// Inbound messages from clients (register/unregister)
 	register   chan *Client
 	unregister chan *Client
@@ -31,6 +46,9 @@ type Hub struct {
 	// Outbound messages to broadcast
 	broadcast chan *outletEvent
 
+	// Outbound messages to a single client
+	direct chan *clientMessage
+
 	// Mutex for thread-safe room access
 	mu sync.RWMutex
 }
@@ -39,9 +57,11 @@ type Hub struct {
 func NewHub() *Hub {
 	return &Hub{
 		rooms:      make(map[uuid.UUID]map[*Client]bool),
+		logs:       make(map[uuid.UUID]*eventLog),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan *outletEvent, 256),
+		direct:     make(chan *clientMessage, 256),
 	}
 }
 
@@ -56,6 +76,9 @@ func (h *Hub) Run() {
 				h.rooms[client.outletID] = make(map[*Client]bool)
 			}
 			h.rooms[client.outletID][client] = true
+			if client.replay {
+				h.replay(client)
+			}
 			h.mu.Unlock()
 
 		case client := <-h.unregister:
@@ -72,35 +95,129 @@ func (h *Hub) Run() {
 			}
 			h.mu.Unlock()
 
+		case m := <-h.direct:
+			h.mu.Lock()
+			// Skip clients that have gone away since sending the command
+			if h.rooms[m.client.outletID][m.client] {
+				h.send(m.client, m.message)
+			}
+			h.mu.Unlock()
+
 		case event := <-h.broadcast:
 			h.mu.Lock()
-			clients := h.rooms[event.OutletID]
+			events := h.logs[event.OutletID]
+			if events == nil {
+				events = &eventLog{}
+				h.logs[event.OutletID] = events
+			}
+			e := events.append(event.Event)
 
 			// Marshal event to JSON once
-			message, err := json.Marshal(event.Event)
+			message, err := json.Marshal(e)
 			if err != nil {
 				h.mu.Unlock()
 				continue
 			}
 
-			// Send to all clients in this outlet's room
-			for client := range clients {
-				select {
-				case client.send <- message:
-				default:
-					// Client's send buffer is full, close and unregister
-					close(client.send)
-					delete(h.rooms[event.OutletID], client)
-					if len(h.rooms[event.OutletID]) == 0 {
-						delete(h.rooms, event.OutletID)
+			// Station-filtered payloads, built once per distinct station set
+			filtered := make(map[string][]byte)
+
+			// Send to all matching clients in this outlet's room
+			for client := range h.rooms[event.OutletID] {
+				if !client.sub.wants(e) {
+					continue
+				}
+
+				msg := message
+				if client.sub.isFiltered() {
+					key := client.sub.key()
+					if msg = filtered[key]; msg == nil {
+						msg, err = h.filterEvent(client.sub, e)
+						if err != nil {
+							log.Printf("ERROR: filter %s event: %v", e.Type, err)
+							continue
+						}
+						filtered[key] = msg
 					}
 				}
+
+				h.send(client, msg)
 			}
 			h.mu.Unlock()
 		}
 	}
 }
 
+// send queues a message for the client. A client whose send buffer is full
+// is closed and unregistered. Caller must hold h.mu.
+func (h *Hub) send(client *Client, msg []byte) bool {
+	select {
+	case client.send <- msg:
+		return true
+	default:
+		close(client.send)
+		delete(h.rooms[client.outletID], client)
+		if len(h.rooms[client.outletID]) == 0 {
+			delete(h.rooms, client.outletID)
+		}
+		return false
+	}
+}
+
+// replay sends a reconnecting client the events it missed since client.since,
+// or a resync_required message when they are no longer buffered. Runs before
+// any live event reaches the client, so ordering is preserved.
+// Caller must hold h.mu.
+func (h *Hub) replay(client *Client) {
+	events := h.logs[client.outletID]
+	if events == nil {
+		events = &eventLog{}
+	}
+
+	missed, ok := events.since(client.since)
+	if !ok || len(missed) > cap(client.send)-len(client.send) {
+		payload, _ := json.Marshal(resyncPayload{LatestSeq: events.seq})
+		message, err := json.Marshal(Event{Type: EventResyncRequired, Payload: payload})
+		if err != nil {
+			log.Printf("ERROR: marshal resync: %v", err)
+			return
+		}
+		h.send(client, message)
+		return
+	}
+
+	for _, e := range missed {
+		if !client.sub.wants(e) {
+			continue
+		}
+		var message []byte
+		var err error
+		if client.sub.isFiltered() {
+			message, err = h.filterEvent(client.sub, e)
+		} else {
+			message, err = json.Marshal(e)
+		}
+		if err != nil {
+			log.Printf("ERROR: replay %s event: %v", e.Type, err)
+			continue
+		}
+		if !h.send(client, message) {
+			return
+		}
+	}
+}
+
+// filterEvent marshals the event with its order items narrowed to the
+// subscription's stations.
+func (h *Hub) filterEvent(sub Subscription, event Event) ([]byte, error) {
+	payload, err := sub.filterPayload(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+	event.Payload = payload
+	return json.Marshal(event)
+}
+
 // BroadcastToOutlet sends an event to all clients subscribed to a specific outlet
 // This is the public API for handlers to broadcast events
 func (h *Hub) BroadcastToOutlet(outletID uuid.UUID, event Event) {
@@ -1,11 +1,16 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/kiwari-pos/api/internal/auth"
+	"github.com/kiwari-pos/api/internal/database"
 )
 
 // mockClient creates a client for testing without a real WebSocket connection
@@ -342,3 +347,525 @@ func TestBroadcastToNonExistentOutlet(t *testing.T) {
 		// Expected - no message
 	}
 }
+
+func TestParseSubscription(t *testing.T) {
+	q := url.Values{}
+	q.Set("stations", "grill, BEVERAGE,")
+	q.Set("events", "order_item.added,order_item.status_changed")
+
+	sub, err := ParseSubscription(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sub.Stations) != 2 || !sub.Stations["GRILL"] || !sub.Stations["BEVERAGE"] {
+		t.Errorf("stations: got %v", sub.Stations)
+	}
+	if len(sub.Events) != 2 || !sub.Events[EventOrderItemAdded] {
+		t.Errorf("events: got %v", sub.Events)
+	}
+
+	q.Set("events", "order.paid")
+	if _, err := ParseSubscription(q); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+
+	sub, err = ParseSubscription(url.Values{})
+	if err != nil || sub.Stations != nil || sub.Events != nil {
+		t.Errorf("empty query: got %+v, %v", sub, err)
+	}
+}
+
+func TestBroadcastFiltersByStation(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	grill := mockClient(hub, outletID)
+	grill.sub = Subscription{Stations: map[string]bool{"GRILL": true}}
+	dessert := mockClient(hub, outletID)
+	dessert.sub = Subscription{Stations: map[string]bool{"DESSERT": true}}
+	cashier := mockClient(hub, outletID)
+
+	hub.register <- grill
+	hub.register <- dessert
+	hub.register <- cashier
+	time.Sleep(10 * time.Millisecond)
+
+	payload := json.RawMessage(`{"order":{"id":"o1","items":[` +
+		`{"id":"i1","station":"GRILL"},{"id":"i2","station":"BEVERAGE"},{"id":"i3","station":null}]},` +
+		`"item":{"id":"i1","station":"GRILL"}}`)
+	hub.BroadcastToOutlet(outletID, Event{
+		Type:     EventOrderItemAdded,
+		Payload:  payload,
+		Stations: []string{"GRILL"},
+	})
+
+	// GRILL display sees only its own items
+	select {
+	case msg := <-grill.send:
+		var received struct {
+			Type    string `json:"type"`
+			Payload struct {
+				Order struct {
+					ID    string `json:"id"`
+					Items []struct {
+						ID string `json:"id"`
+					} `json:"items"`
+				} `json:"order"`
+				Item struct {
+					ID string `json:"id"`
+				} `json:"item"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(msg, &received); err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		if received.Type != EventOrderItemAdded || received.Payload.Order.ID != "o1" || received.Payload.Item.ID != "i1" {
+			t.Errorf("event: got %+v", received)
+		}
+		if items := received.Payload.Order.Items; len(items) != 1 || items[0].ID != "i1" {
+			t.Errorf("items: got %+v, want only i1", items)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("grill client did not receive message")
+	}
+
+	// DESSERT display has nothing to do
+	select {
+	case <-dessert.send:
+		t.Fatal("dessert client should not receive a GRILL event")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Unfiltered clients get the payload untouched
+	select {
+	case msg := <-cashier.send:
+		var received Event
+		if err := json.Unmarshal(msg, &received); err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		if string(received.Payload) != string(payload) {
+			t.Errorf("payload: got %s, want %s", received.Payload, payload)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("unfiltered client did not receive message")
+	}
+}
+
+func TestBroadcastFiltersByEventType(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	client := mockClient(hub, outletID)
+	client.sub = Subscription{Events: map[string]bool{EventOrderItemStatusChanged: true}}
+	hub.register <- client
+	time.Sleep(10 * time.Millisecond)
+
+	hub.BroadcastToOutlet(outletID, Event{Type: EventPaymentAdded, Payload: json.RawMessage(`{}`)})
+	hub.BroadcastToOutlet(outletID, Event{Type: EventOrderItemStatusChanged, Payload: json.RawMessage(`{}`)})
+
+	select {
+	case msg := <-client.send:
+		var received Event
+		if err := json.Unmarshal(msg, &received); err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		if received.Type != EventOrderItemStatusChanged {
+			t.Errorf("type: got %s, want %s", received.Type, EventOrderItemStatusChanged)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("client did not receive subscribed event")
+	}
+
+	select {
+	case msg := <-client.send:
+		t.Fatalf("unexpected extra message: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOptInEventsNeedExplicitSubscription(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	cashier := mockClient(hub, outletID)
+	grill := mockClient(hub, outletID)
+	grill.sub = Subscription{
+		Stations: map[string]bool{"GRILL": true},
+		Events:   map[string]bool{EventKitchenAllDay: true},
+	}
+	hub.register <- cashier
+	hub.register <- grill
+	time.Sleep(10 * time.Millisecond)
+
+	hub.BroadcastToOutlet(outletID, Event{
+		Type: EventKitchenAllDay,
+		Payload: json.RawMessage(`{"all_day":{"items":[` +
+			`{"product_name":"Nasi Bakar Ayam","station":"GRILL"},{"product_name":"Es Teh","station":"BEVERAGE"}]}}`),
+		Stations: []string{"GRILL", "BEVERAGE"},
+	})
+
+	select {
+	case msg := <-grill.send:
+		var received struct {
+			Payload struct {
+				AllDay struct {
+					Items []struct {
+						ProductName string `json:"product_name"`
+					} `json:"items"`
+				} `json:"all_day"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(msg, &received); err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		if items := received.Payload.AllDay.Items; len(items) != 1 || items[0].ProductName != "Nasi Bakar Ayam" {
+			t.Errorf("all_day items: got %+v, want only the GRILL dish", items)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("subscribed client did not receive all-day view")
+	}
+
+	select {
+	case msg := <-cashier.send:
+		t.Fatalf("unsubscribed client received opt-in event: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBroadcastAssignsSequencePerOutlet(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outlet1 := uuid.New()
+	outlet2 := uuid.New()
+	client1 := mockClient(hub, outlet1)
+	client2 := mockClient(hub, outlet2)
+	hub.register <- client1
+	hub.register <- client2
+	time.Sleep(10 * time.Millisecond)
+
+	hub.BroadcastToOutlet(outlet1, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+	hub.BroadcastToOutlet(outlet1, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+	hub.BroadcastToOutlet(outlet2, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+
+	for _, tc := range []struct {
+		client *Client
+		want   []uint64
+	}{
+		{client1, []uint64{1, 2}},
+		{client2, []uint64{1}},
+	} {
+		for _, want := range tc.want {
+			select {
+			case msg := <-tc.client.send:
+				var received Event
+				if err := json.Unmarshal(msg, &received); err != nil {
+					t.Fatalf("unmarshal error: %v", err)
+				}
+				if received.Seq != want {
+					t.Errorf("outlet %s seq: got %d, want %d", tc.client.outletID, received.Seq, want)
+				}
+			case <-time.After(100 * time.Millisecond):
+				t.Fatalf("outlet %s: missing event seq %d", tc.client.outletID, want)
+			}
+		}
+	}
+}
+
+func TestReconnectReplaysMissedEvents(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	for i := 0; i < 3; i++ {
+		hub.BroadcastToOutlet(outletID, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// Saw seq 1 before disconnecting
+	client := mockClient(hub, outletID)
+	client.replay = true
+	client.since = 1
+	hub.register <- client
+	hub.BroadcastToOutlet(outletID, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+
+	for _, want := range []uint64{2, 3, 4} {
+		select {
+		case msg := <-client.send:
+			var received Event
+			if err := json.Unmarshal(msg, &received); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			if received.Type != EventOrderCreated || received.Seq != want {
+				t.Errorf("got %s seq %d, want %s seq %d", received.Type, received.Seq, EventOrderCreated, want)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("missing event seq %d", want)
+		}
+	}
+}
+
+func TestReconnectResyncWhenGapTooOld(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	for i := 0; i < replayBufferSize+5; i++ {
+		hub.BroadcastToOutlet(outletID, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	tests := []struct {
+		name  string
+		since uint64
+	}{
+		{"events dropped from buffer", 2},
+		{"ahead of server (restart)", replayBufferSize + 100},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := mockClient(hub, outletID)
+			client.replay = true
+			client.since = tc.since
+			hub.register <- client
+
+			select {
+			case msg := <-client.send:
+				var received struct {
+					Type    string        `json:"type"`
+					Payload resyncPayload `json:"payload"`
+				}
+				if err := json.Unmarshal(msg, &received); err != nil {
+					t.Fatalf("unmarshal error: %v", err)
+				}
+				if received.Type != EventResyncRequired {
+					t.Errorf("type: got %s, want %s", received.Type, EventResyncRequired)
+				}
+				if received.Payload.LatestSeq != replayBufferSize+5 {
+					t.Errorf("latest_seq: got %d, want %d", received.Payload.LatestSeq, replayBufferSize+5)
+				}
+			case <-time.After(100 * time.Millisecond):
+				t.Fatal("client did not receive resync message")
+			}
+
+			select {
+			case msg := <-client.send:
+				t.Fatalf("unexpected message after resync: %s", msg)
+			case <-time.After(20 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestRelayedEventsKeepTheirSequence(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	// Sequence numbers assigned by another replica; seq 8 never arrived
+	for _, seq := range []uint64{5, 6, 7, 9, 10} {
+		hub.BroadcastToOutlet(outletID, Event{Type: EventOrderCreated, Seq: seq, Payload: json.RawMessage(`{}`)})
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	tests := []struct {
+		name  string
+		since uint64
+		want  []uint64 // nil means resync
+	}{
+		{"after the gap", 9, []uint64{10}},
+		{"before the gap", 6, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := mockClient(hub, outletID)
+			client.replay = true
+			client.since = tc.since
+			hub.register <- client
+
+			select {
+			case msg := <-client.send:
+				var received Event
+				if err := json.Unmarshal(msg, &received); err != nil {
+					t.Fatalf("unmarshal error: %v", err)
+				}
+				if tc.want == nil {
+					if received.Type != EventResyncRequired {
+						t.Errorf("type: got %s, want %s", received.Type, EventResyncRequired)
+					}
+					return
+				}
+				if received.Seq != tc.want[0] {
+					t.Errorf("seq: got %d, want %d", received.Seq, tc.want[0])
+				}
+			case <-time.After(100 * time.Millisecond):
+				t.Fatal("client did not receive a message")
+			}
+		})
+	}
+}
+
+func TestRelayDeliversEachEventOnce(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	client := mockClient(hub, outletID)
+	hub.register <- client
+	time.Sleep(10 * time.Millisecond)
+
+	relay := &PGRelay{hub: hub, seen: make(map[uuid.UUID]int64)}
+	// Caught up after a reconnect, then notified of events already read back
+	for _, seq := range []int64{1, 2, 3, 2, 3, 4} {
+		relay.deliver(database.WsEvent{OutletID: outletID, Seq: seq, EventType: EventOrderCreated, Payload: []byte(`{}`)})
+	}
+
+	for _, want := range []uint64{1, 2, 3, 4} {
+		select {
+		case msg := <-client.send:
+			var received Event
+			if err := json.Unmarshal(msg, &received); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			if received.Seq != want {
+				t.Errorf("seq: got %d, want %d", received.Seq, want)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("missing event seq %d", want)
+		}
+	}
+	select {
+	case msg := <-client.send:
+		t.Fatalf("unexpected duplicate: %s", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestRelayBroadcastDoesNotWaitForPublish(t *testing.T) {
+	relay := &PGRelay{outbox: make(chan *outletEvent, 1)}
+	outletID := uuid.New()
+
+	done := make(chan struct{})
+	go func() {
+		// Nothing is publishing; the second event finds the outbox full
+		relay.BroadcastToOutlet(outletID, Event{Type: EventOrderCreated, Payload: json.RawMessage(`{}`)})
+		relay.BroadcastToOutlet(outletID, Event{Type: EventOrderCancelled, Payload: json.RawMessage(`{}`)})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("BroadcastToOutlet blocked")
+	}
+	if e := <-relay.outbox; e.OutletID != outletID || e.Event.Type != EventOrderCreated {
+		t.Errorf("queued: got %s %s, want %s %s", e.OutletID, e.Event.Type, outletID, EventOrderCreated)
+	}
+}
+
+func TestRelayMessageRoundTrip(t *testing.T) {
+	outletID := uuid.New()
+
+	gotOutlet, gotSeq, err := parseRelayMessage(relayMessage(outletID, 42))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotOutlet != outletID || gotSeq != 42 {
+		t.Errorf("got %s/%d, want %s/42", gotOutlet, gotSeq, outletID)
+	}
+
+	for _, msg := range []string{"", "not-a-uuid:1", outletID.String() + ":x", outletID.String()} {
+		if _, _, err := parseRelayMessage(msg); err == nil {
+			t.Errorf("parseRelayMessage(%q): expected error", msg)
+		}
+	}
+}
+
+type commandFunc func(ctx context.Context, req CommandRequest) (interface{}, error)
+
+func (f commandFunc) HandleCommand(ctx context.Context, req CommandRequest) (interface{}, error) {
+	return f(ctx, req)
+}
+
+func TestClientCommandsRepliedToSender(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	outletID := uuid.New()
+	sender := mockClient(hub, outletID)
+	sender.claims = &auth.Claims{UserID: uuid.New(), OutletID: outletID, Role: "KITCHEN"}
+	sender.sub = Subscription{Stations: map[string]bool{"GRILL": true}}
+	sender.commands = commandFunc(func(ctx context.Context, req CommandRequest) (interface{}, error) {
+		if req.OutletID != outletID || !req.Stations["GRILL"] {
+			t.Errorf("request: got %+v", req)
+		}
+		if req.Type == CommandItemBump {
+			return map[string]string{"status": "READY"}, nil
+		}
+		return nil, &CommandError{Code: CommandErrConflict, Message: "no READY items to recall"}
+	})
+	other := mockClient(hub, outletID)
+	hub.register <- sender
+	hub.register <- other
+	time.Sleep(10 * time.Millisecond)
+
+	tests := []struct {
+		message   string
+		wantType  string
+		wantCode  string
+		wantID    string
+		wantError bool
+	}{
+		{`{"id":"a1","type":"item.bump","payload":{}}`, EventCommandAck, "", "a1", false},
+		{`{"id":"a2","type":"ticket.recall","payload":{}}`, EventCommandError, CommandErrConflict, "a2", true},
+		{`not json`, EventCommandError, CommandErrBadRequest, "", true},
+	}
+	for _, tc := range tests {
+		sender.handleCommand([]byte(tc.message))
+
+		select {
+		case msg := <-sender.send:
+			var received struct {
+				Type    string       `json:"type"`
+				Payload commandReply `json:"payload"`
+			}
+			if err := json.Unmarshal(msg, &received); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			if received.Type != tc.wantType || received.Payload.ID != tc.wantID || received.Payload.Code != tc.wantCode {
+				t.Errorf("%s: got %+v", tc.message, received)
+			}
+			if (received.Payload.Error != "") != tc.wantError {
+				t.Errorf("%s: error %q", tc.message, received.Payload.Error)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("%s: no reply", tc.message)
+		}
+	}
+
+	// Replies go to the sender only
+	select {
+	case msg := <-other.send:
+		t.Fatalf("other client received %s", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// An expired token stops commands on a socket opened before expiry
+	sender.claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	sender.handleCommand([]byte(`{"id":"a3","type":"item.bump","payload":{}}`))
+	select {
+	case msg := <-sender.send:
+		var received struct {
+			Payload commandReply `json:"payload"`
+		}
+		json.Unmarshal(msg, &received)
+		if received.Payload.Code != CommandErrUnauthorized {
+			t.Errorf("expired token: got %+v", received.Payload)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("expired token: no reply")
+	}
+}
@@ -0,0 +1,245 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kiwari-pos/api/internal/database"
+)
+
+const (
+	// relayChannel is the Postgres NOTIFY channel shared by all replicas
+	relayChannel = "ws_events"
+
+	// Time allowed to store and notify one event
+	relayPublishTimeout = 5 * time.Second
+
+	// Events waiting to be stored and notified
+	relayOutboxSize = 256
+
+	// Wait before re-establishing a lost LISTEN connection
+	relayRetryWait = 2 * time.Second
+)
+
+// PGRelay fans events out to every API replica through Postgres LISTEN/NOTIFY.
+// BroadcastToOutlet queues the event for Publish, which stores it and
+// notifies; Listen feeds notifications from all replicas, including this one,
+// into the local Hub. Sequence numbers
+// come from the database so they agree across replicas.
+type PGRelay struct {
+	pool    *pgxpool.Pool
+	queries *database.Queries
+	hub     *Hub
+
+	// Events queued by BroadcastToOutlet for Publish
+	outbox chan *outletEvent
+
+	// Last seq relayed into the hub, by outlet. Only used by Listen.
+	seen map[uuid.UUID]int64
+}
+
+// NewPGRelay creates a relay delivering to the given local hub.
+func NewPGRelay(pool *pgxpool.Pool, hub *Hub) *PGRelay {
+	return &PGRelay{
+		pool:    pool,
+		queries: database.New(pool),
+		hub:     hub,
+		outbox:  make(chan *outletEvent, relayOutboxSize),
+		seen:    make(map[uuid.UUID]int64),
+	}
+}
+
+// BroadcastToOutlet publishes an event to the outlet's clients on every replica.
+// It only queues the event, so handlers don't wait on the database. Events
+// that can't be queued or published are logged and not delivered anywhere.
+func (r *PGRelay) BroadcastToOutlet(outletID uuid.UUID, event Event) {
+	select {
+	case r.outbox <- &outletEvent{OutletID: outletID, Event: event}:
+	default:
+		log.Printf("ERROR: relay %s event: outbox full", event.Type)
+	}
+}
+
+// Publish stores and notifies queued events until ctx is cancelled, one at a
+// time so this replica's events keep their order.
+// This should be called as a goroutine: go relay.Publish(ctx)
+func (r *PGRelay) Publish(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-r.outbox:
+			pubCtx, cancel := context.WithTimeout(ctx, relayPublishTimeout)
+			if err := r.publish(pubCtx, e.OutletID, e.Event); err != nil {
+				log.Printf("ERROR: relay %s event: %v", e.Event.Type, err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (r *PGRelay) publish(ctx context.Context, outletID uuid.UUID, event Event) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+
+	seq, err := q.NextWSEventSeq(ctx, outletID)
+	if err != nil {
+		return fmt.Errorf("next seq: %w", err)
+	}
+
+	stations := event.Stations
+	if stations == nil {
+		stations = []string{}
+	}
+	if err := q.CreateWSEvent(ctx, database.CreateWSEventParams{
+		OutletID:  outletID,
+		Seq:       seq,
+		EventType: event.Type,
+		Payload:   event.Payload,
+		Stations:  stations,
+	}); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+
+	// Keep only what a reconnecting client could still replay
+	if err := q.PruneWSEvents(ctx, database.PruneWSEventsParams{
+		OutletID: outletID,
+		Seq:      seq - replayBufferSize,
+	}); err != nil {
+		return fmt.Errorf("prune events: %w", err)
+	}
+
+	if err := q.NotifyWSEvent(ctx, relayMessage(outletID, seq)); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Listen relays notified events into the local hub until ctx is cancelled,
+// reconnecting when the LISTEN connection drops. Events stored while it was
+// disconnected are read back from ws_events once it is listening again.
+// This should be called as a goroutine: go relay.Listen(ctx)
+func (r *PGRelay) Listen(ctx context.Context) {
+	for {
+		err := r.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("ERROR: ws relay listen: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetryWait):
+		}
+	}
+}
+
+func (r *PGRelay) listen(ctx context.Context) error {
+	pooled, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire conn: %w", err)
+	}
+	// The connection stays subscribed, so it never goes back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{relayChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	log.Printf("WebSocket relay listening on %s", relayChannel)
+
+	if err := r.catchUp(ctx); err != nil {
+		return fmt.Errorf("catch up: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		outletID, seq, err := parseRelayMessage(n.Payload)
+		if err != nil {
+			log.Printf("ERROR: ws relay: %v", err)
+			continue
+		}
+		if seq <= r.seen[outletID] {
+			continue // already relayed by catchUp
+		}
+
+		row, err := r.queries.GetWSEvent(ctx, database.GetWSEventParams{OutletID: outletID, Seq: seq})
+		if err != nil {
+			log.Printf("ERROR: ws relay get event %s/%d: %v", outletID, seq, err)
+			continue
+		}
+
+		r.deliver(row)
+	}
+}
+
+// catchUp relays the events stored since the last one seen for each outlet,
+// e.g. while the LISTEN connection was down. It runs after LISTEN, so events
+// committed meanwhile are either read here or notified afterwards. Events
+// already pruned leave a gap, and the hub sends a resync to clients behind it.
+func (r *PGRelay) catchUp(ctx context.Context) error {
+	for outletID, seq := range r.seen {
+		rows, err := r.queries.ListWSEventsSince(ctx, database.ListWSEventsSinceParams{OutletID: outletID, Seq: seq})
+		if err != nil {
+			return fmt.Errorf("list events for %s: %w", outletID, err)
+		}
+		for _, row := range rows {
+			r.deliver(row)
+		}
+	}
+	return nil
+}
+
+// deliver passes a stored event to the local hub, skipping ones already relayed.
+func (r *PGRelay) deliver(row database.WsEvent) {
+	if row.Seq <= r.seen[row.OutletID] {
+		return
+	}
+	r.seen[row.OutletID] = row.Seq
+
+	r.hub.BroadcastToOutlet(row.OutletID, Event{
+		Type:     row.EventType,
+		Seq:      uint64(row.Seq),
+		Payload:  row.Payload,
+		Stations: row.Stations,
+	})
+}
+
+// relayMessage is the NOTIFY payload naming a stored event: "<outlet_id>:<seq>".
+func relayMessage(outletID uuid.UUID, seq int64) string {
+	return outletID.String() + ":" + strconv.FormatInt(seq, 10)
+}
+
+func parseRelayMessage(msg string) (uuid.UUID, int64, error) {
+	oid, seqStr, ok := strings.Cut(msg, ":")
+	if !ok {
+		return uuid.Nil, 0, fmt.Errorf("malformed notification %q", msg)
+	}
+	outletID, err := uuid.Parse(oid)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("malformed notification %q: %w", msg, err)
+	}
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("malformed notification %q: %w", msg, err)
+	}
+	return outletID, seq, nil
+}
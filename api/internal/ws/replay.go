@@ -0,0 +1,57 @@
+package ws
+
+// replayBufferSize is how many recent events each outlet keeps for clients
+// reconnecting with ?since=<seq>. Matches the client send buffer so a full
+// replay never overflows it.
+const replayBufferSize = 256
+
+// resyncPayload tells a reconnecting client its gap can't be replayed and it
+// should reload orders over HTTP, then resume from LatestSeq.
+type resyncPayload struct {
+	LatestSeq uint64 `json:"latest_seq"`
+}
+
+// eventLog numbers an outlet's events and keeps the most recent ones.
+type eventLog struct {
+	seq    uint64  // last assigned sequence number
+	events []Event // ascending by Seq, at most replayBufferSize
+}
+
+// append assigns the next sequence number to the event and buffers it.
+// Events relayed from Postgres arrive already numbered; if some were missed
+// in between, the buffer restarts so older positions get a resync.
+func (l *eventLog) append(event Event) Event {
+	if event.Seq == 0 {
+		event.Seq = l.seq + 1
+	} else if event.Seq != l.seq+1 {
+		l.events = nil
+	}
+	l.seq = event.Seq
+	l.events = append(l.events, event)
+	if len(l.events) > replayBufferSize {
+		l.events = l.events[len(l.events)-replayBufferSize:]
+	}
+	return event
+}
+
+// since returns the events after seq. ok is false when some of them have
+// already been dropped, or seq is ahead of the log (e.g. the server restarted
+// and numbering began again).
+func (l *eventLog) since(seq uint64) (missed []Event, ok bool) {
+	if seq > l.seq {
+		return nil, false
+	}
+	oldest := l.seq + 1
+	if len(l.events) > 0 {
+		oldest = l.events[0].Seq
+	}
+	if seq+1 < oldest {
+		return nil, false
+	}
+	for _, e := range l.events {
+		if e.Seq > seq {
+			missed = append(missed, e)
+		}
+	}
+	return missed, true
+}
@@ -0,0 +1,147 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Subscription narrows what a client receives from its outlet room.
+// Empty sets mean no filtering, so a client without query params gets every event.
+type Subscription struct {
+	Stations map[string]bool
+	Events   map[string]bool
+}
+
+// ParseSubscription reads the stations and events query params, each a
+// comma-separated list: ?stations=GRILL,RICE&events=order_item.added.
+// Stations are configurable labels and only upper-cased; event types must be known.
+func ParseSubscription(q url.Values) (Subscription, error) {
+	var sub Subscription
+
+	for _, s := range splitList(q.Get("stations")) {
+		if sub.Stations == nil {
+			sub.Stations = make(map[string]bool)
+		}
+		sub.Stations[strings.ToUpper(s)] = true
+	}
+
+	for _, e := range splitList(q.Get("events")) {
+		if !knownEvents[e] {
+			return Subscription{}, fmt.Errorf("unknown event type %q", e)
+		}
+		if sub.Events == nil {
+			sub.Events = make(map[string]bool)
+		}
+		sub.Events[e] = true
+	}
+
+	return sub, nil
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// isFiltered reports whether the client needs a per-client payload.
+func (s Subscription) isFiltered() bool {
+	return len(s.Stations) > 0
+}
+
+// key identifies subscriptions that produce the same payload, so the hub
+// filters once per distinct station set rather than once per client.
+func (s Subscription) key() string {
+	stations := make([]string, 0, len(s.Stations))
+	for st := range s.Stations {
+		stations = append(stations, st)
+	}
+	sort.Strings(stations)
+	return strings.Join(stations, ",")
+}
+
+// wants reports whether the event matches the client's event types and,
+// for station-filtered clients, touches at least one subscribed station.
+// Events without stations (e.g. payments) never reach station-filtered clients.
+// Opt-in events must be named explicitly.
+func (s Subscription) wants(event Event) bool {
+	if (len(s.Events) > 0 || optInEvents[event.Type]) && !s.Events[event.Type] {
+		return false
+	}
+	if len(s.Stations) == 0 {
+		return true
+	}
+	for _, st := range event.Stations {
+		if s.Stations[st] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPayload drops order.items and all_day.items whose station is not
+// subscribed. Payloads without either object are returned unchanged.
+func (s Subscription) filterPayload(payload json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	changed := false
+	for _, key := range []string{"order", "all_day"} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		filtered, err := s.filterItems(raw)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", key, err)
+		}
+		fields[key] = filtered
+		changed = true
+	}
+	if !changed {
+		return payload, nil
+	}
+	return json.Marshal(fields)
+}
+
+// filterItems drops entries of obj.items whose station is not subscribed.
+func (s Subscription) filterItems(rawObj json.RawMessage) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(rawObj, &obj); err != nil {
+		return nil, fmt.Errorf("decode object: %w", err)
+	}
+	var items []json.RawMessage
+	if raw, ok := obj["items"]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("decode items: %w", err)
+		}
+	}
+
+	kept := []json.RawMessage{}
+	for _, raw := range items {
+		var item struct {
+			Station *string `json:"station"`
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, fmt.Errorf("decode item: %w", err)
+		}
+		if item.Station != nil && s.Stations[*item.Station] {
+			kept = append(kept, raw)
+		}
+	}
+
+	var err error
+	if obj["items"], err = json.Marshal(kept); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
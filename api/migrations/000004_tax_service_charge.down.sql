@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE orders DROP COLUMN IF EXISTS service_charge_rate;
+ALTER TABLE orders DROP COLUMN IF EXISTS service_charge_amount;
+
+DROP TABLE IF EXISTS outlet_settings;
@@ -0,0 +1,34 @@
+-- Per-outlet tax (PB1) and service charge settings.
+-- Outlets without a row are treated as charging neither.
+CREATE TABLE outlet_settings (
+    outlet_id                   UUID PRIMARY KEY REFERENCES outlets(id),
+    tax_rate                    DECIMAL(5,2) NOT NULL DEFAULT 0,
+    prices_include_tax          BOOLEAN NOT NULL DEFAULT false,
+    tax_order_types             TEXT[] NOT NULL DEFAULT '{DINE_IN,TAKEAWAY,DELIVERY,CATERING}',
+    service_charge_rate         DECIMAL(5,2) NOT NULL DEFAULT 0,
+    service_charge_order_types  TEXT[] NOT NULL DEFAULT '{DINE_IN}',
+    rounding_mode               VARCHAR(10) NOT NULL DEFAULT 'HALF_UP',
+    rounding_unit               DECIMAL(12,2) NOT NULL DEFAULT 1,
+    created_at                  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at                  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE outlet_settings ADD CONSTRAINT chk_outlet_settings_tax_rate
+  CHECK (tax_rate >= 0 AND tax_rate <= 100);
+
+ALTER TABLE outlet_settings ADD CONSTRAINT chk_outlet_settings_service_charge_rate
+  CHECK (service_charge_rate >= 0 AND service_charge_rate <= 100);
+
+ALTER TABLE outlet_settings ADD CONSTRAINT chk_outlet_settings_rounding_mode
+  CHECK (rounding_mode IN ('HALF_UP', 'UP', 'DOWN'));
+
+ALTER TABLE outlet_settings ADD CONSTRAINT chk_outlet_settings_rounding_unit
+  CHECK (rounding_unit > 0);
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON outlet_settings FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Charge breakdown on orders: the rates applied at the last (re)calculation.
+ALTER TABLE orders ADD COLUMN service_charge_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN service_charge_rate DECIMAL(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT false;
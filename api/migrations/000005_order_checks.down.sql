@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payments_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS check_id;
+
+DROP TABLE IF EXISTS order_check_items;
+DROP TABLE IF EXISTS order_checks;
@@ -0,0 +1,25 @@
+-- Split bills: an order can be divided into checks, each paid separately.
+-- Check amounts are fixed at split time and always sum to the order total.
+CREATE TABLE order_checks (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id        UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    check_number    INT NOT NULL,
+    split_type      VARCHAR(10) NOT NULL,
+    amount          DECIMAL(12,2) NOT NULL,
+    created_by      UUID NOT NULL REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(order_id, check_number)
+);
+
+ALTER TABLE order_checks ADD CONSTRAINT chk_order_checks_split_type
+  CHECK (split_type IN ('ITEM', 'EVEN'));
+
+-- Items assigned to a check (ITEM splits only). An item belongs to at most one check.
+CREATE TABLE order_check_items (
+    check_id        UUID NOT NULL REFERENCES order_checks(id) ON DELETE CASCADE,
+    order_item_id   UUID NOT NULL UNIQUE REFERENCES order_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (check_id, order_item_id)
+);
+
+ALTER TABLE payments ADD COLUMN check_id UUID REFERENCES order_checks(id);
+CREATE INDEX idx_payments_check ON payments(check_id);
@@ -0,0 +1,25 @@
+-- Refunds are linked records against the original payment. The payment keeps
+-- its original amount; refunded_amount tracks how much of it has been returned,
+-- so the net paid on an order is SUM(amount - refunded_amount).
+ALTER TABLE payments ADD COLUMN refunded_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payments ADD CONSTRAINT chk_payments_refunded_amount
+  CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+CREATE TABLE payment_refunds (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id      UUID NOT NULL REFERENCES payments(id),
+    order_id        UUID NOT NULL REFERENCES orders(id),
+    amount          DECIMAL(12,2) NOT NULL,
+    reason          TEXT NOT NULL,
+    refunded_by     UUID NOT NULL REFERENCES users(id),
+    authorized_by   UUID NOT NULL REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE payment_refunds ADD CONSTRAINT chk_payment_refunds_amount
+  CHECK (amount > 0);
+
+CREATE INDEX idx_payment_refunds_payment ON payment_refunds(payment_id);
+CREATE INDEX idx_payment_refunds_order ON payment_refunds(order_id);
+CREATE INDEX idx_payment_refunds_created ON payment_refunds(created_at);
@@ -0,0 +1,20 @@
+-- Append-only audit trail of everything that happens to an order.
+-- before_value / after_value hold JSON snapshots of the fields that changed.
+CREATE TABLE order_events (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id        UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    event_type      VARCHAR(30) NOT NULL,
+    actor_id        UUID NOT NULL REFERENCES users(id),
+    before_value    JSONB,
+    after_value     JSONB,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED'
+  ));
+
+CREATE INDEX idx_order_events_order ON order_events(order_id, created_at);
@@ -0,0 +1,15 @@
+-- Responses to idempotent POSTs, keyed by the client-supplied Idempotency-Key.
+-- A row with NULL response_status is a request still in flight.
+CREATE TABLE idempotency_keys (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    outlet_id        UUID NOT NULL REFERENCES outlets(id),
+    idempotency_key  VARCHAR(255) NOT NULL,
+    request_hash     VARCHAR(64) NOT NULL,
+    response_status  INTEGER,
+    response_body    TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at     TIMESTAMPTZ,
+    UNIQUE (outlet_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);
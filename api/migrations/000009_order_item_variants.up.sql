@@ -0,0 +1,5 @@
+-- An item can carry one variant per variant group (e.g. size and spiciness).
+-- variant_id keeps the first selection for existing readers.
+ALTER TABLE order_items ADD COLUMN variant_ids UUID[] NOT NULL DEFAULT '{}';
+
+UPDATE order_items SET variant_ids = ARRAY[variant_id] WHERE variant_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_order_items_parent;
+ALTER TABLE order_items DROP COLUMN IF EXISTS parent_item_id;
@@ -0,0 +1,6 @@
+-- A combo line is expanded into one child item per component so each dish
+-- reaches its own kitchen station. The price stays on the parent line;
+-- children are priced at zero.
+ALTER TABLE order_items ADD COLUMN parent_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_order_items_parent ON order_items(parent_item_id);
@@ -0,0 +1,11 @@
+DELETE FROM order_events WHERE event_type IN ('TABLE_CHANGED', 'ORDERS_MERGED');
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED'
+  ));
+
+DROP INDEX IF EXISTS idx_orders_outlet_table;
+DROP TABLE IF EXISTS dining_tables;
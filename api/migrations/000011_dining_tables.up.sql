@@ -0,0 +1,30 @@
+-- Floor plan: the tables an outlet seats guests at, grouped into areas.
+-- Orders keep the table code in orders.table_number; a table's live status
+-- (free, occupied, bill requested) is derived from its active orders.
+CREATE TABLE dining_tables (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    outlet_id           UUID NOT NULL REFERENCES outlets(id),
+    area                VARCHAR(50) NOT NULL DEFAULT '',
+    code                VARCHAR(20) NOT NULL,
+    seats               INT NOT NULL DEFAULT 2,
+    sort_order          INT NOT NULL DEFAULT 0,
+    bill_requested_at   TIMESTAMPTZ,
+    is_active           BOOLEAN NOT NULL DEFAULT true,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE dining_tables ADD CONSTRAINT chk_dining_tables_seats CHECK (seats > 0);
+
+-- Codes are reused once a table is removed
+CREATE UNIQUE INDEX idx_dining_tables_outlet_code ON dining_tables(outlet_id, code) WHERE is_active;
+
+CREATE INDEX idx_orders_outlet_table ON orders(outlet_id, table_number) WHERE table_number IS NOT NULL;
+
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED',
+    'TABLE_CHANGED', 'ORDERS_MERGED'
+  ));
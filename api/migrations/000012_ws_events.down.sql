@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ws_events;
+DROP TABLE IF EXISTS ws_outlet_sequences;
@@ -0,0 +1,18 @@
+-- Real-time events shared between API replicas. Each replica LISTENs on the
+-- ws_events channel and reads the event row named in the notification, so
+-- payloads are not bound by the NOTIFY size limit. Only recent events are
+-- kept, for replay to reconnecting clients.
+CREATE TABLE ws_outlet_sequences (
+    outlet_id   UUID PRIMARY KEY REFERENCES outlets(id),
+    last_seq    BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE ws_events (
+    outlet_id   UUID NOT NULL REFERENCES outlets(id),
+    seq         BIGINT NOT NULL,
+    event_type  VARCHAR(50) NOT NULL,
+    payload     JSONB NOT NULL,
+    stations    TEXT[] NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (outlet_id, seq)
+);
@@ -0,0 +1,9 @@
+DELETE FROM order_events WHERE event_type = 'ORDER_ACKNOWLEDGED';
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED',
+    'TABLE_CHANGED', 'ORDERS_MERGED'
+  ));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_order_items_in_progress;
+ALTER TABLE order_items DROP COLUMN IF EXISTS late_alerted_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS ready_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS started_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS sent_at;
@@ -0,0 +1,12 @@
+-- Kitchen timing per item: sent to the kitchen, started, ready. late_alerted_at
+-- marks items already reported as running past their product's preparation
+-- time, so each is alerted once across API replicas.
+ALTER TABLE order_items ADD COLUMN sent_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE order_items ADD COLUMN started_at TIMESTAMPTZ;
+ALTER TABLE order_items ADD COLUMN ready_at TIMESTAMPTZ;
+ALTER TABLE order_items ADD COLUMN late_alerted_at TIMESTAMPTZ;
+
+UPDATE order_items oi SET sent_at = o.created_at FROM orders o WHERE o.id = oi.order_id;
+
+CREATE INDEX idx_order_items_in_progress ON order_items(sent_at)
+  WHERE status IN ('PENDING', 'PREPARING') AND late_alerted_at IS NULL;
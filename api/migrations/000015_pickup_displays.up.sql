@@ -0,0 +1,16 @@
+-- Customer-facing pickup screens. Each outlet has at most one display
+-- configuration; every TV showing it uses the same token, which replaces a
+-- login for the read-only feed and can be rotated to revoke old screens.
+CREATE TABLE pickup_displays (
+    outlet_id               UUID PRIMARY KEY REFERENCES outlets(id),
+    token                   VARCHAR(64) NOT NULL UNIQUE,
+    order_types             TEXT[] NOT NULL DEFAULT '{TAKEAWAY}',
+    ready_timeout_minutes   INT NOT NULL DEFAULT 30,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE pickup_displays ADD CONSTRAINT chk_pickup_displays_ready_timeout
+  CHECK (ready_timeout_minutes > 0);
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON pickup_displays FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
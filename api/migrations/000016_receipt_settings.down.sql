@@ -0,0 +1,4 @@
+ALTER TABLE outlet_settings DROP CONSTRAINT IF EXISTS chk_outlet_settings_receipt_paper_width;
+ALTER TABLE outlet_settings DROP COLUMN IF EXISTS receipt_paper_width;
+ALTER TABLE outlet_settings DROP COLUMN IF EXISTS receipt_footer;
+ALTER TABLE outlet_settings DROP COLUMN IF EXISTS receipt_header;
@@ -0,0 +1,9 @@
+-- Receipt layout per outlet: free text printed above the items (below the
+-- outlet name, address and phone) and at the bottom, and the thermal paper
+-- width in millimetres.
+ALTER TABLE outlet_settings ADD COLUMN receipt_header TEXT NOT NULL DEFAULT '';
+ALTER TABLE outlet_settings ADD COLUMN receipt_footer TEXT NOT NULL DEFAULT '';
+ALTER TABLE outlet_settings ADD COLUMN receipt_paper_width INT NOT NULL DEFAULT 58;
+
+ALTER TABLE outlet_settings ADD CONSTRAINT chk_outlet_settings_receipt_paper_width
+  CHECK (receipt_paper_width IN (58, 80));
@@ -0,0 +1,20 @@
+-- Printed kitchen tickets. Each order's tickets are numbered in sequence;
+-- one ticket goes to each station an order change touches. lines holds what
+-- the ticket printed, so later changes can be printed as a delta against it
+-- and a jammed ticket can be reprinted exactly.
+CREATE TABLE kitchen_tickets (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id            UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    sequence            INT NOT NULL,
+    station             VARCHAR(20),
+    ticket_type         VARCHAR(10) NOT NULL,
+    lines               JSONB NOT NULL,
+    created_by          UUID NOT NULL REFERENCES users(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reprint_count       INT NOT NULL DEFAULT 0,
+    last_reprinted_at   TIMESTAMPTZ,
+    UNIQUE (order_id, sequence)
+);
+
+ALTER TABLE kitchen_tickets ADD CONSTRAINT chk_kitchen_tickets_ticket_type
+  CHECK (ticket_type IN ('NEW', 'CHANGE'));
@@ -0,0 +1,16 @@
+DELETE FROM order_events WHERE event_type = 'PROMOTION_APPLIED';
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED',
+    'TABLE_CHANGED', 'ORDERS_MERGED', 'ORDER_ACKNOWLEDGED'
+  ));
+
+DROP INDEX IF EXISTS idx_orders_promotion;
+ALTER TABLE orders DROP COLUMN IF EXISTS promotion_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS promotion_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS promotion_id;
+
+DROP TABLE IF EXISTS promotions;
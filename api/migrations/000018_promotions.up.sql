@@ -0,0 +1,52 @@
+-- Configurable promotions, evaluated by the server whenever an order's items
+-- change. Promotions without an outlet apply at every outlet; promotions with
+-- a code apply only when the cashier enters it. An order carries at most one
+-- promotion, whose discount is included in orders.discount_amount.
+CREATE TABLE promotions (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    outlet_id           UUID REFERENCES outlets(id),
+    name                VARCHAR(255) NOT NULL,
+    code                VARCHAR(30) UNIQUE,
+    promotion_type      VARCHAR(20) NOT NULL,
+    -- Items the promotion covers; both NULL covers the whole order
+    category_id         UUID REFERENCES categories(id),
+    product_id          UUID REFERENCES products(id),
+    -- Percent or amount off, or the price of one bundle
+    discount_value      DECIMAL(12,2) NOT NULL DEFAULT 0,
+    -- Buy X get Y, or X items per bundle
+    buy_quantity        INT,
+    get_quantity        INT,
+    min_spend           DECIMAL(12,2),
+    valid_from          TIMESTAMPTZ,
+    valid_until         TIMESTAMPTZ,
+    -- Daily window in outlet time (happy hour); may wrap past midnight
+    start_time          TIME,
+    end_time            TIME,
+    usage_limit         INT,
+    is_active           BOOLEAN NOT NULL DEFAULT true,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_promotions_outlet ON promotions(outlet_id) WHERE is_active = true;
+
+ALTER TABLE promotions ADD CONSTRAINT chk_promotions_promotion_type
+  CHECK (promotion_type IN ('PERCENTAGE', 'FIXED_AMOUNT', 'BUY_X_GET_Y', 'BUNDLE_PRICE'));
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON promotions FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- The promotion applied to each order, and the code entered for it so the
+-- promotion can be re-evaluated when items change
+ALTER TABLE orders ADD COLUMN promotion_id UUID REFERENCES promotions(id);
+ALTER TABLE orders ADD COLUMN promotion_code VARCHAR(30);
+ALTER TABLE orders ADD COLUMN promotion_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+CREATE INDEX idx_orders_promotion ON orders(promotion_id) WHERE promotion_id IS NOT NULL;
+
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED',
+    'TABLE_CHANGED', 'ORDERS_MERGED', 'ORDER_ACKNOWLEDGED',
+    'PROMOTION_APPLIED'
+  ));
@@ -0,0 +1,56 @@
+-- Loyalty program per outlet. Customers earn points when their orders are
+-- completed and redeem them as a POINTS payment. Without a row (or when
+-- disabled) the outlet has no loyalty program.
+CREATE TABLE loyalty_settings (
+    outlet_id           UUID PRIMARY KEY REFERENCES outlets(id),
+    is_enabled          BOOLEAN NOT NULL DEFAULT true,
+    -- Spend per point by order type, e.g. {"DINE_IN": "10000"}; order types
+    -- not listed earn nothing
+    earn_rates          JSONB NOT NULL DEFAULT '{}',
+    -- Rupiah value of one point when redeemed; 0 disables redemption
+    point_value         DECIMAL(12,2) NOT NULL DEFAULT 0,
+    -- Days until earned points expire; NULL keeps them forever
+    points_expiry_days  INT,
+    -- Tiers by lifetime spend on completed orders; each multiplies points earned
+    silver_min_spend    DECIMAL(12,2),
+    silver_multiplier   DECIMAL(4,2) NOT NULL DEFAULT 1,
+    gold_min_spend      DECIMAL(12,2),
+    gold_multiplier     DECIMAL(4,2) NOT NULL DEFAULT 1,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE loyalty_settings ADD CONSTRAINT chk_loyalty_settings_point_value
+  CHECK (point_value >= 0);
+ALTER TABLE loyalty_settings ADD CONSTRAINT chk_loyalty_settings_points_expiry_days
+  CHECK (points_expiry_days IS NULL OR points_expiry_days > 0);
+ALTER TABLE loyalty_settings ADD CONSTRAINT chk_loyalty_settings_multipliers
+  CHECK (silver_multiplier >= 1 AND gold_multiplier >= 1);
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON loyalty_settings FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Points ledger. A customer's balance is the sum of their rows. Reversals
+-- reference the order (and payment) whose points they give back; EXPIRE
+-- rows are written lazily when points past expires_at are still unspent.
+CREATE TABLE loyalty_transactions (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id         UUID NOT NULL REFERENCES customers(id),
+    order_id            UUID REFERENCES orders(id),
+    payment_id          UUID REFERENCES payments(id),
+    transaction_type    VARCHAR(20) NOT NULL,
+    points              INT NOT NULL,
+    -- Spend that earned the points, or the value of the points redeemed
+    amount              DECIMAL(12,2) NOT NULL DEFAULT 0,
+    -- Earned points only; reversals copy the expiry of the points they reverse
+    expires_at          TIMESTAMPTZ,
+    created_by          UUID REFERENCES users(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_loyalty_transactions_customer ON loyalty_transactions(customer_id, created_at);
+CREATE INDEX idx_loyalty_transactions_order ON loyalty_transactions(order_id) WHERE order_id IS NOT NULL;
+CREATE INDEX idx_loyalty_transactions_payment ON loyalty_transactions(payment_id) WHERE payment_id IS NOT NULL;
+-- An order earns points once
+CREATE UNIQUE INDEX uq_loyalty_transactions_earn ON loyalty_transactions(order_id) WHERE transaction_type = 'EARN';
+
+ALTER TABLE loyalty_transactions ADD CONSTRAINT chk_loyalty_transactions_transaction_type
+  CHECK (transaction_type IN ('EARN', 'EARN_REVERSAL', 'REDEEM', 'REDEEM_REVERSAL', 'EXPIRE'));
@@ -0,0 +1,18 @@
+-- Normalized phones are kept; the original formatting is not recorded.
+DROP INDEX IF EXISTS uq_customers_outlet_phone;
+
+-- Merged and deleted customers may share a phone with the customer that
+-- replaced them, which the old constraint does not allow. The active
+-- customer keeps the number; the others get it suffixed with #2, #3, ...
+UPDATE customers c
+SET phone = left(d.phone, 20 - length('#' || d.rn)) || '#' || d.rn
+FROM (
+    SELECT id, phone,
+           row_number() OVER (PARTITION BY outlet_id, phone ORDER BY is_active DESC, created_at, id) AS rn
+    FROM customers
+) d
+WHERE c.id = d.id AND d.rn > 1;
+
+ALTER TABLE customers ADD CONSTRAINT customers_outlet_id_phone_key UNIQUE (outlet_id, phone);
+ALTER TABLE customers DROP COLUMN IF EXISTS merged_into_id;
+DROP FUNCTION IF EXISTS normalize_phone(TEXT);
@@ -0,0 +1,57 @@
+-- Phone numbers are stored in E.164 (+628123456789). normalize_phone mirrors
+-- the API's normalization and returns NULL for numbers it cannot read; it is
+-- used here and by the duplicate customer report.
+CREATE OR REPLACE FUNCTION normalize_phone(raw TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    s TEXT := regexp_replace(raw, '[[:space:]().-]', '', 'g');
+    digits TEXT;
+BEGIN
+    IF s ~ '^\+[0-9]+$' THEN
+        digits := substr(s, 2);
+    ELSIF s !~ '^[0-9]+$' THEN
+        RETURN NULL;
+    ELSIF s LIKE '00%' THEN
+        digits := substr(s, 3);
+    ELSIF s LIKE '0%' THEN
+        digits := '62' || substr(s, 2);
+    ELSIF s LIKE '62%' THEN
+        digits := s;
+    ELSIF s LIKE '8%' THEN
+        digits := '62' || s;
+    ELSE
+        RETURN NULL;
+    END IF;
+    -- +62 0812... typed with the trunk prefix
+    IF digits LIKE '620%' THEN
+        digits := '62' || substr(digits, 4);
+    END IF;
+    IF length(digits) < 8 OR length(digits) > 15 OR digits LIKE '0%' THEN
+        RETURN NULL;
+    END IF;
+    RETURN '+' || digits;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- A merged customer is deactivated and points at the customer it was merged into
+ALTER TABLE customers ADD COLUMN merged_into_id UUID REFERENCES customers(id);
+
+-- Phones only need to be unique among active customers, so a deleted or
+-- merged customer's number can be given to the surviving customer
+ALTER TABLE customers DROP CONSTRAINT customers_outlet_id_phone_key;
+CREATE UNIQUE INDEX uq_customers_outlet_phone ON customers(outlet_id, phone) WHERE is_active = true;
+
+-- Normalize existing phones. Numbers that would collide with another active
+-- customer are left as typed; they show up in the duplicate report to merge.
+UPDATE customers c
+SET phone = normalize_phone(c.phone)
+WHERE c.is_active = true
+  AND normalize_phone(c.phone) IS NOT NULL
+  AND normalize_phone(c.phone) <> c.phone
+  AND NOT EXISTS (
+      SELECT 1 FROM customers o
+      WHERE o.outlet_id = c.outlet_id
+        AND o.id <> c.id
+        AND o.is_active = true
+        AND normalize_phone(o.phone) = normalize_phone(c.phone)
+  );
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_refunds_refunded_by;
+DROP INDEX IF EXISTS idx_payments_processed_by;
+DROP TABLE IF EXISTS cash_movements;
+DROP TABLE IF EXISTS cashier_shifts;
@@ -0,0 +1,53 @@
+-- A cashier's time on the till. Opened with the float counted into the
+-- drawer and closed with the cash counted out of it; expected_cash is what
+-- the drawer should have held at close, so variance is counted - expected.
+CREATE TABLE cashier_shifts (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    outlet_id       UUID NOT NULL REFERENCES outlets(id),
+    user_id         UUID NOT NULL REFERENCES users(id),
+    status          VARCHAR(10) NOT NULL DEFAULT 'OPEN',
+    opening_float   DECIMAL(12,2) NOT NULL,
+    opened_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Set on close
+    closed_at       TIMESTAMPTZ,
+    closed_by       UUID REFERENCES users(id),
+    counted_cash    DECIMAL(12,2),
+    expected_cash   DECIMAL(12,2),
+    closing_notes   TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_cashier_shifts_outlet ON cashier_shifts(outlet_id, opened_at DESC);
+-- A cashier has at most one open shift per outlet
+CREATE UNIQUE INDEX uq_cashier_shifts_open ON cashier_shifts(outlet_id, user_id) WHERE status = 'OPEN';
+
+ALTER TABLE cashier_shifts ADD CONSTRAINT chk_cashier_shifts_status
+  CHECK (status IN ('OPEN', 'CLOSED'));
+ALTER TABLE cashier_shifts ADD CONSTRAINT chk_cashier_shifts_opening_float
+  CHECK (opening_float >= 0);
+ALTER TABLE cashier_shifts ADD CONSTRAINT chk_cashier_shifts_closed
+  CHECK (status = 'OPEN' OR (closed_at IS NOT NULL AND counted_cash IS NOT NULL AND expected_cash IS NOT NULL));
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON cashier_shifts FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Cash put into or taken out of the drawer during a shift other than by
+-- payments: petty cash, change top-ups, owner pickups.
+CREATE TABLE cash_movements (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    shift_id        UUID NOT NULL REFERENCES cashier_shifts(id),
+    movement_type   VARCHAR(10) NOT NULL,
+    amount          DECIMAL(12,2) NOT NULL,
+    reason          TEXT NOT NULL,
+    created_by      UUID NOT NULL REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_cash_movements_shift ON cash_movements(shift_id, created_at);
+
+ALTER TABLE cash_movements ADD CONSTRAINT chk_cash_movements_movement_type
+  CHECK (movement_type IN ('CASH_IN', 'CASH_OUT'));
+ALTER TABLE cash_movements ADD CONSTRAINT chk_cash_movements_amount
+  CHECK (amount > 0);
+
+-- Shift reports find the cashier's payments and refunds by time
+CREATE INDEX idx_payments_processed_by ON payments(processed_by, processed_at);
+CREATE INDEX idx_payment_refunds_refunded_by ON payment_refunds(refunded_by, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_cash_tx_sales_summary;
+ALTER TABLE acct_cash_transactions DROP COLUMN IF EXISTS sales_summary_id;
+DROP TABLE IF EXISTS acct_sales_payment_accounts;
@@ -0,0 +1,16 @@
+-- Which cash account each POS payment method lands in, per outlet. The
+-- end-of-day close posts each day's POS sales to these accounts.
+CREATE TABLE acct_sales_payment_accounts (
+    outlet_id           UUID NOT NULL REFERENCES outlets(id),
+    payment_method      VARCHAR(20) NOT NULL,
+    cash_account_id     UUID NOT NULL REFERENCES acct_cash_accounts(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (outlet_id, payment_method)
+);
+
+-- Cash transactions posted by the close point back at their summary, so
+-- closing a day again only posts the difference.
+ALTER TABLE acct_cash_transactions
+  ADD COLUMN sales_summary_id UUID REFERENCES acct_sales_daily_summaries(id);
+CREATE INDEX idx_cash_tx_sales_summary ON acct_cash_transactions(sales_summary_id)
+  WHERE sales_summary_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Offline orders keep the total the device charged when the server prices
+-- them differently; the difference is recorded in the order history
+ALTER TABLE order_events DROP CONSTRAINT chk_order_events_event_type;
+ALTER TABLE order_events ADD CONSTRAINT chk_order_events_event_type
+  CHECK (event_type IN (
+    'ORDER_CREATED', 'STATUS_CHANGED', 'ORDER_CANCELLED',
+    'ITEM_ADDED', 'ITEM_UPDATED', 'ITEM_REMOVED', 'ITEM_STATUS_CHANGED',
+    'DISCOUNT_APPLIED', 'PAYMENT_ADDED', 'PAYMENT_REFUNDED',
+    'TABLE_CHANGED', 'ORDERS_MERGED', 'ORDER_ACKNOWLEDGED',
+    'PROMOTION_APPLIED', 'PRICE_ADJUSTED'
+  ));
@@ -17,8 +17,9 @@ SELECT * FROM acct_cash_transactions WHERE id = $1;
 INSERT INTO acct_cash_transactions (
     transaction_code, transaction_date, item_id, description,
     quantity, unit_price, amount, line_type,
-    account_id, cash_account_id, outlet_id, reimbursement_batch_id
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    account_id, cash_account_id, outlet_id, reimbursement_batch_id,
+    sales_summary_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 RETURNING *;
 
 -- name: GetNextTransactionCode :one
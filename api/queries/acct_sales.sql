@@ -0,0 +1,100 @@
+-- name: GetPOSDailySales :many
+-- Completed orders of an outlet in [day_start, day_end), by order type,
+-- delivery platform and payment method. Net sales is what was paid less
+-- refunds. Points redemptions are a discount rather than money received,
+-- so they and the order discount are spread over the order's other
+-- payments by their share of it.
+WITH order_payments AS (
+    SELECT
+        o.id AS order_id,
+        o.order_type,
+        COALESCE(o.delivery_platform, '') AS delivery_platform,
+        o.discount_amount,
+        p.payment_method,
+        SUM(p.amount - p.refunded_amount) AS paid
+    FROM orders o
+    JOIN payments p ON p.order_id = o.id AND p.status = 'COMPLETED'
+    WHERE o.outlet_id = sqlc.arg('outlet_id')
+      AND o.status = 'COMPLETED'
+      AND o.completed_at >= sqlc.arg('day_start')::timestamptz
+      AND o.completed_at < sqlc.arg('day_end')::timestamptz
+    GROUP BY o.id, p.payment_method
+),
+order_totals AS (
+    SELECT
+        order_id,
+        COALESCE(SUM(paid) FILTER (WHERE payment_method <> 'POINTS'), 0) AS money_paid,
+        COALESCE(SUM(paid) FILTER (WHERE payment_method = 'POINTS'), 0) AS points_paid
+    FROM order_payments
+    GROUP BY order_id
+)
+SELECT
+    op.order_type::text AS order_type,
+    op.delivery_platform::text AS delivery_platform,
+    op.payment_method::text AS payment_method,
+    SUM(op.paid)::decimal(12,2) AS net_sales,
+    SUM((op.discount_amount + t.points_paid) * op.paid / t.money_paid)::decimal(12,2) AS discount_amount
+FROM order_payments op
+JOIN order_totals t ON t.order_id = op.order_id
+WHERE op.payment_method <> 'POINTS' AND t.money_paid > 0
+GROUP BY op.order_type, op.delivery_platform, op.payment_method
+ORDER BY op.order_type, op.delivery_platform, op.payment_method;
+
+-- name: LockPOSSalesDay :exec
+-- Serializes closes of the same outlet and day until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('lock_key')::text, 0));
+
+-- name: ListPOSSalesSummaries :many
+SELECT * FROM acct_sales_daily_summaries
+WHERE sales_date = $1 AND outlet_id = $2 AND source = 'pos'
+ORDER BY channel, payment_method;
+
+-- name: UpsertPOSSalesSummary :one
+-- Never overwrites a manual summary for the same day, channel and method;
+-- no row comes back when one is in the way.
+INSERT INTO acct_sales_daily_summaries (
+    sales_date, channel, payment_method, gross_sales,
+    discount_amount, net_sales, cash_account_id, outlet_id, source
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'pos')
+ON CONFLICT (sales_date, channel, payment_method, outlet_id) DO UPDATE SET
+    gross_sales = EXCLUDED.gross_sales,
+    discount_amount = EXCLUDED.discount_amount,
+    net_sales = EXCLUDED.net_sales,
+    cash_account_id = EXCLUDED.cash_account_id
+WHERE acct_sales_daily_summaries.source = 'pos'
+RETURNING *;
+
+-- name: ListSalesSummaryPostings :many
+-- What has been posted for a summary so far, per cash account.
+SELECT cash_account_id, SUM(amount)::decimal(12,2) AS amount
+FROM acct_cash_transactions
+WHERE sales_summary_id = $1
+GROUP BY cash_account_id
+ORDER BY cash_account_id;
+
+-- name: ListAcctSalesDailySummaries :many
+SELECT * FROM acct_sales_daily_summaries
+WHERE
+    sales_date >= sqlc.arg('start_date') AND
+    sales_date <= sqlc.arg('end_date') AND
+    (sqlc.narg('outlet_id')::uuid IS NULL OR outlet_id = sqlc.narg('outlet_id'))
+ORDER BY sales_date DESC, channel, payment_method;
+
+-- name: GetDefaultSalesAccount :one
+SELECT * FROM acct_accounts
+WHERE line_type = 'SALES' AND is_active = true
+ORDER BY account_code
+LIMIT 1;
+
+-- name: ListAcctSalesPaymentAccounts :many
+SELECT * FROM acct_sales_payment_accounts
+WHERE outlet_id = $1
+ORDER BY payment_method;
+
+-- name: DeleteAcctSalesPaymentAccounts :exec
+DELETE FROM acct_sales_payment_accounts WHERE outlet_id = $1;
+
+-- name: CreateAcctSalesPaymentAccount :one
+INSERT INTO acct_sales_payment_accounts (outlet_id, payment_method, cash_account_id)
+VALUES ($1, $2, $3)
+RETURNING *;
@@ -0,0 +1,68 @@
+-- name: CreateCashierShift :one
+INSERT INTO cashier_shifts (outlet_id, user_id, opening_float)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCashierShift :one
+SELECT * FROM cashier_shifts WHERE id = $1 AND outlet_id = $2;
+
+-- name: GetCashierShiftForUpdate :one
+SELECT * FROM cashier_shifts WHERE id = $1 AND outlet_id = $2 FOR UPDATE;
+
+-- name: GetOpenCashierShift :one
+SELECT * FROM cashier_shifts WHERE outlet_id = $1 AND user_id = $2 AND status = 'OPEN';
+
+-- name: ListCashierShifts :many
+SELECT * FROM cashier_shifts
+WHERE outlet_id = $1
+  AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY opened_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CloseCashierShift :one
+UPDATE cashier_shifts SET status = 'CLOSED', closed_at = now(), closed_by = $2,
+    counted_cash = $3, expected_cash = $4, closing_notes = $5
+WHERE id = $1 AND status = 'OPEN'
+RETURNING *;
+
+-- name: CreateCashMovement :one
+INSERT INTO cash_movements (shift_id, movement_type, amount, reason, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListCashMovements :many
+SELECT * FROM cash_movements WHERE shift_id = $1 ORDER BY created_at;
+
+-- name: GetCashierShiftPaymentSummary :many
+-- Payments the shift's cashier took and refunds they paid out, from the
+-- shift opening until its close (or now, while it is open), by payment method.
+SELECT
+    t.payment_method,
+    COUNT(*) FILTER (WHERE t.kind = 'PAYMENT') AS transaction_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'PAYMENT'), 0)::decimal(12,2) AS total_amount,
+    COUNT(*) FILTER (WHERE t.kind = 'REFUND') AS refund_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'REFUND'), 0)::decimal(12,2) AS refund_amount
+FROM cashier_shifts s
+JOIN LATERAL (
+    SELECT p.payment_method, p.amount, 'PAYMENT' AS kind
+    FROM payments p
+    JOIN orders o ON o.id = p.order_id
+    WHERE o.outlet_id = s.outlet_id
+        AND p.processed_by = s.user_id
+        AND p.status = 'COMPLETED'
+        AND p.processed_at >= s.opened_at
+        AND p.processed_at <= COALESCE(s.closed_at, now())
+    UNION ALL
+    SELECT p.payment_method, r.amount, 'REFUND' AS kind
+    FROM payment_refunds r
+    JOIN payments p ON p.id = r.payment_id
+    JOIN orders o ON o.id = r.order_id
+    WHERE o.outlet_id = s.outlet_id
+        AND r.refunded_by = s.user_id
+        AND r.created_at >= s.opened_at
+        AND r.created_at <= COALESCE(s.closed_at, now())
+) t ON true
+WHERE s.id = $1
+GROUP BY t.payment_method
+ORDER BY t.payment_method;
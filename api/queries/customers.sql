@@ -8,6 +8,10 @@ LIMIT $2 OFFSET $3;
 -- name: GetCustomer :one
 SELECT * FROM customers WHERE id = $1 AND outlet_id = $2 AND is_active = true;
 
+-- name: GetCustomerForUpdate :one
+SELECT * FROM customers WHERE id = $1 AND outlet_id = $2 AND is_active = true
+FOR UPDATE;
+
 -- name: CreateCustomer :one
 INSERT INTO customers (outlet_id, name, phone, email, notes)
 VALUES ($1, $2, $3, $4, $5)
@@ -39,6 +43,7 @@ FROM order_items oi
 JOIN orders o ON o.id = oi.order_id
 JOIN products p ON p.id = oi.product_id
 WHERE o.customer_id = $1 AND o.outlet_id = $2 AND o.status != 'CANCELLED'
+    AND oi.parent_item_id IS NULL
 GROUP BY p.id, p.name
 ORDER BY total_qty DESC
 LIMIT 5;
@@ -48,3 +53,51 @@ SELECT * FROM orders
 WHERE customer_id = $1 AND outlet_id = $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4;
+
+-- name: ListCustomerRFM :many
+-- Active customers with at least one order: their first and last order
+-- overall, and their order count and spend from window_start on. Spend is
+-- counted as in GetCustomerLoyalty: paid net of refunds on completed orders.
+SELECT c.id, c.name, c.phone,
+    MIN(o.created_at)::timestamptz AS first_order_at,
+    MAX(o.created_at)::timestamptz AS last_order_at,
+    COUNT(o.id) FILTER (WHERE o.created_at >= sqlc.arg(window_start)) AS order_count,
+    COALESCE(SUM(paid.amount) FILTER (WHERE o.created_at >= sqlc.arg(window_start) AND o.status = 'COMPLETED'), 0)::decimal(12,2) AS total_spend
+FROM customers c
+JOIN orders o ON o.customer_id = c.id AND o.outlet_id = c.outlet_id AND o.status != 'CANCELLED'
+LEFT JOIN LATERAL (
+    SELECT SUM(p.amount - p.refunded_amount) AS amount FROM payments p
+    WHERE p.order_id = o.id AND p.status = 'COMPLETED'
+) paid ON true
+WHERE c.outlet_id = sqlc.arg(outlet_id) AND c.is_active = true
+GROUP BY c.id
+ORDER BY c.name, c.id;
+
+-- name: ListDuplicateCustomerCandidates :many
+-- Active customers whose phones normalize to the same number as another
+-- active customer's, ordered so each number's customers are adjacent.
+SELECT normalize_phone(c.phone)::text AS normalized_phone,
+    c.id, c.name, c.phone, c.email, c.created_at,
+    (SELECT COUNT(*) FROM orders o WHERE o.customer_id = c.id) AS order_count
+FROM customers c
+WHERE c.outlet_id = $1 AND c.is_active = true
+  AND normalize_phone(c.phone) IN (
+      SELECT normalize_phone(d.phone) FROM customers d
+      WHERE d.outlet_id = $1 AND d.is_active = true
+      GROUP BY normalize_phone(d.phone)
+      HAVING COUNT(*) > 1
+  )
+ORDER BY normalized_phone, c.created_at, c.id;
+
+-- name: ReassignCustomerOrders :execrows
+UPDATE orders SET customer_id = sqlc.arg(target_id)::uuid
+WHERE customer_id = sqlc.arg(source_id)::uuid;
+
+-- name: ReassignCustomerLoyaltyTransactions :execrows
+UPDATE loyalty_transactions SET customer_id = sqlc.arg(target_id)
+WHERE customer_id = sqlc.arg(source_id);
+
+-- name: MarkCustomerMerged :one
+UPDATE customers SET is_active = false, merged_into_id = $2, updated_at = now()
+WHERE id = $1 AND is_active = true
+RETURNING id;
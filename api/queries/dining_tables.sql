@@ -0,0 +1,34 @@
+-- name: ListDiningTablesWithOrders :many
+-- Lists an outlet's tables with a summary of the active orders seated at
+-- each, from which the floor plan derives a table's status.
+SELECT t.id, t.area, t.code, t.seats, t.sort_order, t.bill_requested_at,
+    COUNT(o.id)::int AS active_orders,
+    MIN(o.created_at)::timestamptz AS occupied_since,
+    COALESCE(SUM(o.total_amount), 0)::decimal AS active_total
+FROM dining_tables t
+LEFT JOIN orders o ON o.outlet_id = t.outlet_id AND o.table_number = t.code
+    AND o.status NOT IN ('COMPLETED', 'CANCELLED')
+WHERE t.outlet_id = $1 AND t.is_active = true
+GROUP BY t.id
+ORDER BY t.area, t.sort_order, t.code;
+
+-- name: GetDiningTable :one
+SELECT * FROM dining_tables WHERE id = $1 AND outlet_id = $2 AND is_active = true;
+
+-- name: CreateDiningTable :one
+INSERT INTO dining_tables (outlet_id, area, code, seats, sort_order)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateDiningTable :one
+UPDATE dining_tables SET area = $1, code = $2, seats = $3, sort_order = $4
+WHERE id = $5 AND outlet_id = $6 AND is_active = true
+RETURNING *;
+
+-- name: RequestDiningTableBill :one
+UPDATE dining_tables SET bill_requested_at = now()
+WHERE id = $1 AND outlet_id = $2 AND is_active = true
+RETURNING *;
+
+-- name: SoftDeleteDiningTable :one
+UPDATE dining_tables SET is_active = false WHERE id = $1 AND outlet_id = $2 AND is_active = true RETURNING id;
@@ -0,0 +1,25 @@
+-- name: ReserveIdempotencyKey :execrows
+-- Claims the key for a new request. Keys older than 24 hours are reclaimed;
+-- zero rows affected means the key is already held.
+INSERT INTO idempotency_keys (outlet_id, idempotency_key, request_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (outlet_id, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response_status = NULL,
+    response_body = NULL,
+    created_at = now(),
+    completed_at = NULL
+WHERE idempotency_keys.created_at < now() - INTERVAL '24 hours';
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE outlet_id = $1 AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_status = $3, response_body = $4, completed_at = now()
+WHERE outlet_id = $1 AND idempotency_key = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE outlet_id = $1 AND idempotency_key = $2 AND response_status IS NULL;
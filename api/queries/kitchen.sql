@@ -0,0 +1,38 @@
+-- name: ClaimLateOrderItems :many
+-- Marks items still in the kitchen past their product's preparation time as
+-- alerted and returns them. A checker on another replica skips rows already
+-- claimed, so each item is alerted once. Combo lines follow their components.
+UPDATE order_items oi
+SET late_alerted_at = now()
+FROM orders o, products p
+WHERE o.id = oi.order_id
+    AND p.id = oi.product_id
+    AND oi.status IN ('PENDING', 'PREPARING')
+    AND oi.late_alerted_at IS NULL
+    AND p.preparation_time > 0
+    AND o.status NOT IN ('COMPLETED', 'CANCELLED')
+    AND oi.sent_at + make_interval(mins => p.preparation_time) < now()
+    AND NOT EXISTS (SELECT 1 FROM order_items c WHERE c.parent_item_id = oi.id)
+RETURNING oi.id, oi.order_id, o.outlet_id, p.name AS product_name, p.preparation_time, oi.sent_at;
+
+-- name: ListKitchenQueueItems :many
+-- Items waiting in the kitchen across the outlet's active orders (as in
+-- ListActiveOrders), with the variant and modifier names the kitchen groups
+-- them by. Combo lines are left out; their components are listed instead.
+SELECT oi.id, oi.order_id, oi.product_id, p.name AS product_name, oi.variant_ids,
+    COALESCE((SELECT array_agg(v.name ORDER BY v.name) FROM variants v WHERE v.id = ANY(oi.variant_ids)), '{}')::text[] AS variant_names,
+    COALESCE((SELECT array_agg(oim.modifier_id ORDER BY oim.modifier_id) FROM order_item_modifiers oim WHERE oim.order_item_id = oi.id), '{}')::uuid[] AS modifier_ids,
+    COALESCE((SELECT array_agg(m.name ORDER BY oim.modifier_id) FROM order_item_modifiers oim JOIN modifiers m ON m.id = oim.modifier_id WHERE oim.order_item_id = oi.id), '{}')::text[] AS modifier_names,
+    COALESCE((SELECT array_agg(oim.quantity ORDER BY oim.modifier_id) FROM order_item_modifiers oim WHERE oim.order_item_id = oi.id), '{}')::int[] AS modifier_quantities,
+    oi.quantity, oi.status, oi.station, oi.sent_at
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+JOIN products p ON p.id = oi.product_id
+WHERE o.outlet_id = $1
+    AND (
+        o.status IN ('NEW', 'PREPARING', 'READY')
+        OR (o.order_type = 'CATERING' AND o.catering_status IN ('BOOKED', 'DP_PAID'))
+    )
+    AND oi.status IN ('PENDING', 'PREPARING')
+    AND NOT EXISTS (SELECT 1 FROM order_items c WHERE c.parent_item_id = oi.id)
+ORDER BY oi.sent_at;
@@ -0,0 +1,38 @@
+-- name: ListKitchenTicketItems :many
+-- What the kitchen makes for an order, with the names printed on tickets.
+-- Combo lines are left out; their components are listed instead.
+SELECT oi.id, p.name AS product_name,
+    COALESCE((SELECT array_agg(v.name ORDER BY v.name) FROM variants v WHERE v.id = ANY(oi.variant_ids)), '{}')::text[] AS variant_names,
+    COALESCE((SELECT array_agg(m.name ORDER BY m.name) FROM order_item_modifiers oim JOIN modifiers m ON m.id = oim.modifier_id WHERE oim.order_item_id = oi.id), '{}')::text[] AS modifier_names,
+    COALESCE((SELECT array_agg(oim.quantity ORDER BY m.name) FROM order_item_modifiers oim JOIN modifiers m ON m.id = oim.modifier_id WHERE oim.order_item_id = oi.id), '{}')::int[] AS modifier_quantities,
+    oi.quantity, oi.notes, oi.station
+FROM order_items oi
+JOIN products p ON p.id = oi.product_id
+WHERE oi.order_id = $1
+    AND NOT EXISTS (SELECT 1 FROM order_items c WHERE c.parent_item_id = oi.id)
+ORDER BY oi.sent_at, oi.id;
+
+-- name: ListKitchenTickets :many
+SELECT * FROM kitchen_tickets WHERE order_id = $1 ORDER BY sequence;
+
+-- name: GetKitchenTicket :one
+SELECT * FROM kitchen_tickets WHERE id = $1 AND order_id = $2;
+
+-- name: CreateKitchenTicket :one
+-- Numbers the ticket after the order's last one.
+INSERT INTO kitchen_tickets (order_id, sequence, station, ticket_type, lines, created_by)
+VALUES ($1, (SELECT COALESCE(MAX(sequence), 0) + 1 FROM kitchen_tickets WHERE order_id = $1), $2, $3, $4, $5)
+RETURNING *;
+
+-- name: MarkKitchenTicketReprinted :one
+UPDATE kitchen_tickets SET reprint_count = reprint_count + 1, last_reprinted_at = now()
+WHERE id = $1 AND order_id = $2
+RETURNING *;
+
+-- name: MoveKitchenTickets :exec
+-- Moves a merged order's tickets onto the order it was merged into, numbered
+-- after that order's own, so the items moved with them aren't ticketed again.
+UPDATE kitchen_tickets SET
+    order_id = sqlc.arg('to_order_id'),
+    sequence = sequence + (SELECT COALESCE(MAX(kt.sequence), 0) FROM kitchen_tickets kt WHERE kt.order_id = sqlc.arg('to_order_id'))
+WHERE order_id = sqlc.arg('from_order_id');
@@ -0,0 +1,108 @@
+-- name: GetLoyaltySettings :one
+SELECT * FROM loyalty_settings WHERE outlet_id = $1;
+
+-- name: UpsertLoyaltySettings :one
+INSERT INTO loyalty_settings (
+    outlet_id, is_enabled, earn_rates, point_value, points_expiry_days,
+    silver_min_spend, silver_multiplier, gold_min_spend, gold_multiplier
+) VALUES (
+    $1, $2, $3, $4, $5,
+    $6, $7, $8, $9
+)
+ON CONFLICT (outlet_id) DO UPDATE SET
+    is_enabled = EXCLUDED.is_enabled,
+    earn_rates = EXCLUDED.earn_rates,
+    point_value = EXCLUDED.point_value,
+    points_expiry_days = EXCLUDED.points_expiry_days,
+    silver_min_spend = EXCLUDED.silver_min_spend,
+    silver_multiplier = EXCLUDED.silver_multiplier,
+    gold_min_spend = EXCLUDED.gold_min_spend,
+    gold_multiplier = EXCLUDED.gold_multiplier
+RETURNING *;
+
+-- name: LockCustomer :one
+-- Serializes redemptions from the same customer's points.
+SELECT id FROM customers WHERE id = $1 FOR NO KEY UPDATE;
+
+-- name: GetCustomerLoyalty :one
+-- Points balance and lifetime spend on completed orders, which sets the tier.
+-- The balance leaves out points past their expiry that ExpireCustomerPoints
+-- has not written off yet, so reading it needs no write. Spend is what was
+-- paid net of refunds, as in SumPaymentsByOrder.
+SELECT
+    (COALESCE(SUM(lt.points), 0) - GREATEST(
+        COALESCE(SUM(lt.points) FILTER (WHERE lt.transaction_type IN ('EARN', 'EARN_REVERSAL') AND lt.expires_at <= now()), 0)
+        + COALESCE(SUM(lt.points) FILTER (WHERE lt.transaction_type IN ('REDEEM', 'REDEEM_REVERSAL', 'EXPIRE')), 0),
+        0))::int AS points_balance,
+    COALESCE((SELECT SUM(p.amount - p.refunded_amount) FROM payments p
+              JOIN orders o ON o.id = p.order_id
+              WHERE o.customer_id = $1 AND o.status = 'COMPLETED' AND p.status = 'COMPLETED'), 0)::decimal(12,2) AS lifetime_spend
+FROM loyalty_transactions lt
+WHERE lt.customer_id = $1;
+
+-- name: ExpireCustomerPoints :execrows
+-- Expires earned points past their expiry that were not spent. Points are
+-- spent oldest first, so what expired is the expired points earned less
+-- everything redeemed or expired before.
+INSERT INTO loyalty_transactions (customer_id, transaction_type, points)
+SELECT $1, 'EXPIRE', -(due - spent)
+FROM (
+    SELECT
+        COALESCE(SUM(points) FILTER (WHERE transaction_type IN ('EARN', 'EARN_REVERSAL') AND expires_at <= now()), 0) AS due,
+        COALESCE(-SUM(points) FILTER (WHERE transaction_type IN ('REDEEM', 'REDEEM_REVERSAL', 'EXPIRE')), 0) AS spent
+    FROM loyalty_transactions
+    WHERE customer_id = $1
+) t
+WHERE due > spent;
+
+-- name: CreateLoyaltyTransaction :one
+-- An order earns points once: a second EARN for the same order inserts
+-- nothing and returns no rows.
+INSERT INTO loyalty_transactions (customer_id, order_id, payment_id, transaction_type, points, amount, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (order_id) WHERE transaction_type = 'EARN' DO NOTHING
+RETURNING *;
+
+-- name: GetOrderEarnedPoints :one
+-- Points an order earned, how many were reversed since, and the spend that
+-- earned them.
+SELECT
+    COALESCE(SUM(points) FILTER (WHERE transaction_type = 'EARN'), 0)::int AS earned_points,
+    COALESCE(-SUM(points) FILTER (WHERE transaction_type = 'EARN_REVERSAL'), 0)::int AS reversed_points,
+    COALESCE(SUM(amount) FILTER (WHERE transaction_type = 'EARN'), 0)::decimal(12,2) AS earn_amount,
+    MAX(expires_at) FILTER (WHERE transaction_type = 'EARN')::timestamptz AS earn_expires_at
+FROM loyalty_transactions
+WHERE order_id = sqlc.arg(order_id)::uuid;
+
+-- name: ListOrderRedeemedPoints :many
+-- POINTS payments on an order whose points have not all been given back.
+SELECT payment_id::uuid AS payment_id,
+    (-SUM(points))::int AS outstanding_points,
+    (SUM(amount) FILTER (WHERE transaction_type = 'REDEEM')
+        - COALESCE(SUM(amount) FILTER (WHERE transaction_type = 'REDEEM_REVERSAL'), 0))::decimal(12,2) AS outstanding_amount
+FROM loyalty_transactions
+WHERE order_id = sqlc.arg(order_id)::uuid
+    AND transaction_type IN ('REDEEM', 'REDEEM_REVERSAL')
+GROUP BY payment_id
+HAVING -SUM(points) > 0
+ORDER BY payment_id;
+
+-- name: GetPaymentLoyaltyPoints :one
+-- Points redeemed by a POINTS payment, and how many have not been given back.
+SELECT
+    COALESCE(-SUM(points) FILTER (WHERE transaction_type = 'REDEEM'), 0)::int AS redeemed_points,
+    COALESCE(-SUM(points), 0)::int AS outstanding_points
+FROM loyalty_transactions
+WHERE payment_id = sqlc.arg(payment_id)::uuid;
+
+-- name: GetOrderEligibleSpend :one
+-- What the customer paid for an order other than with points, net of refunds.
+SELECT COALESCE(SUM(amount - refunded_amount), 0)::decimal(12,2) AS eligible_spend
+FROM payments
+WHERE order_id = $1 AND status = 'COMPLETED' AND payment_method != 'POINTS';
+
+-- name: ListCustomerLoyaltyTransactions :many
+SELECT * FROM loyalty_transactions
+WHERE customer_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2 OFFSET $3;
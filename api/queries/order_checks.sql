@@ -0,0 +1,33 @@
+-- name: CreateOrderCheck :one
+INSERT INTO order_checks (order_id, check_number, split_type, amount, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CreateOrderCheckItem :exec
+INSERT INTO order_check_items (check_id, order_item_id) VALUES ($1, $2);
+
+-- name: ListOrderChecks :many
+SELECT oc.*,
+       COALESCE(
+         (SELECT SUM(p.amount - p.refunded_amount) FROM payments p WHERE p.check_id = oc.id AND p.status = 'COMPLETED'),
+         0
+       )::decimal(12,2) AS amount_paid
+FROM order_checks oc
+WHERE oc.order_id = $1
+ORDER BY oc.check_number;
+
+-- name: ListOrderCheckItems :many
+SELECT oci.check_id, oci.order_item_id
+FROM order_check_items oci
+JOIN order_checks oc ON oc.id = oci.check_id
+WHERE oc.order_id = $1
+ORDER BY oc.check_number, oci.order_item_id;
+
+-- name: CountOrderChecks :one
+SELECT COUNT(*) FROM order_checks WHERE order_id = $1;
+
+-- name: DeleteOrderChecks :exec
+DELETE FROM order_checks WHERE order_id = $1;
+
+-- name: CountPaymentsByOrder :one
+SELECT COUNT(*) FROM payments WHERE order_id = $1;
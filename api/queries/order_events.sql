@@ -0,0 +1,11 @@
+-- name: CreateOrderEvent :exec
+INSERT INTO order_events (order_id, event_type, actor_id, before_value, after_value)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListOrderEvents :many
+SELECT e.id, e.order_id, e.event_type, e.actor_id, u.full_name AS actor_name,
+       e.before_value, e.after_value, e.created_at
+FROM order_events e
+JOIN users u ON u.id = e.actor_id
+WHERE e.order_id = $1
+ORDER BY e.created_at, e.id;
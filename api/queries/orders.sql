@@ -8,23 +8,44 @@ INSERT INTO orders (
     outlet_id, order_number, customer_id, order_type, table_number, notes,
     subtotal, discount_type, discount_value, discount_amount, tax_amount, total_amount,
     catering_date, catering_status, catering_dp_amount,
-    delivery_platform, delivery_address, created_by
+    delivery_platform, delivery_address, created_by,
+    service_charge_amount, service_charge_rate, tax_rate, prices_include_tax,
+    promotion_id, promotion_code, promotion_amount,
+    id, created_at, updated_at
 ) VALUES (
     $1, $2, $3, $4, $5, $6,
     $7, $8, $9, $10, $11, $12,
     $13, $14, $15,
-    $16, $17, $18
+    $16, $17, $18,
+    $19, $20, $21, $22,
+    $23, $24, $25,
+    COALESCE(sqlc.narg('id')::uuid, gen_random_uuid()),
+    COALESCE(sqlc.narg('created_at')::timestamptz, now()),
+    COALESCE(sqlc.narg('created_at')::timestamptz, now())
 ) RETURNING *;
 
+-- name: CompleteSyncedOrder :one
+-- Completes an order synced from offline, keeping the device's completion time.
+UPDATE orders SET status = 'COMPLETED', completed_at = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetSyncedOrderTotal :one
+-- Sets an order synced from offline to the total the device charged, when
+-- the server priced it differently.
+UPDATE orders SET total_amount = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
 -- name: CreateOrderItem :one
 INSERT INTO order_items (
     order_id, product_id, variant_id, quantity, unit_price,
     discount_type, discount_value, discount_amount, subtotal,
-    notes, station
+    notes, station, variant_ids, parent_item_id
 ) VALUES (
     $1, $2, $3, $4, $5,
     $6, $7, $8, $9,
-    $10, $11
+    $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: CreateOrderItemModifier :one
@@ -33,9 +54,16 @@ INSERT INTO order_item_modifiers (
 ) VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: GetProductForOrder :one
-SELECT id, outlet_id, base_price, station FROM products
+SELECT id, outlet_id, base_price, station, is_combo, category_id FROM products
 WHERE id = $1 AND outlet_id = $2 AND is_active = true;
 
+-- name: ListComboComponentsForOrder :many
+SELECT ci.product_id, ci.quantity, p.station
+FROM combo_items ci
+JOIN products p ON p.id = ci.product_id
+WHERE ci.combo_id = $1
+ORDER BY ci.sort_order, ci.id;
+
 -- name: GetVariantForOrder :one
 SELECT v.id, v.variant_group_id, v.price_adjustment, vg.product_id
 FROM variants v
@@ -43,17 +71,37 @@ JOIN variant_groups vg ON vg.id = v.variant_group_id
 WHERE v.id = $1 AND v.is_active = true AND vg.is_active = true;
 
 -- name: GetModifierForOrder :one
-SELECT m.id, m.price, mg.product_id
+SELECT m.id, m.modifier_group_id, m.price, mg.product_id
 FROM modifiers m
 JOIN modifier_groups mg ON mg.id = m.modifier_group_id
 WHERE m.id = $1 AND m.is_active = true AND mg.is_active = true;
 
+-- name: ListVariantGroupsForOrder :many
+SELECT id, name, is_required FROM variant_groups
+WHERE product_id = $1 AND is_active = true
+ORDER BY sort_order, name;
+
+-- name: ListModifierGroupsForOrder :many
+SELECT id, name, min_select, max_select FROM modifier_groups
+WHERE product_id = $1 AND is_active = true
+ORDER BY sort_order, name;
+
 -- name: GetOrder :one
 SELECT * FROM orders WHERE id = $1 AND outlet_id = $2;
 
 -- name: ListOrderItemsByOrder :many
 SELECT * FROM order_items WHERE order_id = $1 ORDER BY id;
 
+-- name: ListChildOrderItems :many
+SELECT * FROM order_items WHERE parent_item_id = $1 ORDER BY id;
+
+-- name: ScaleChildItemQuantities :exec
+-- Keeps combo components in step with the parent line's quantity. Component
+-- quantities are always a multiple of the parent's, so the division is exact.
+UPDATE order_items SET
+    quantity = quantity / sqlc.arg('old_quantity')::int * sqlc.arg('new_quantity')::int
+WHERE parent_item_id = $1;
+
 -- name: ListOrderItemModifiersByOrderItem :many
 SELECT * FROM order_item_modifiers WHERE order_item_id = $1 ORDER BY id;
 
@@ -90,23 +138,37 @@ UPDATE order_items SET
     quantity = $3,
     notes = $4,
     discount_amount = $5,
-    subtotal = $6
+    subtotal = $6,
+    unit_price = $7,
+    variant_id = $8,
+    variant_ids = $9
 WHERE id = $1 AND order_id = $2
 RETURNING *;
 
 -- name: DeleteOrderItem :exec
 DELETE FROM order_items WHERE id = $1 AND order_id = $2;
 
+-- name: DeleteOrderItemModifiers :exec
+DELETE FROM order_item_modifiers WHERE order_item_id = $1;
+
 -- name: UpdateOrderItemStatus :one
+-- Stamps kitchen progress: started_at on the first move past PENDING, ready_at
+-- on READY. A recall back to PREPARING clears ready_at.
 UPDATE order_items SET
-    status = $3
+    status = $3,
+    started_at = CASE WHEN $3 IN ('PREPARING', 'READY') THEN COALESCE(started_at, now()) ELSE started_at END,
+    ready_at = CASE WHEN $3 = 'READY' THEN now() ELSE NULL END
 WHERE id = $1 AND order_id = $2
 RETURNING *;
 
 -- name: CountOrderItems :one
-SELECT COUNT(*) FROM order_items WHERE order_id = $1;
+-- Counts order lines; combo components are not lines of their own.
+SELECT COUNT(*) FROM order_items WHERE order_id = $1 AND parent_item_id IS NULL;
 
 -- name: UpdateOrderTotals :one
+-- Recomputes subtotal and discount from the order's items. The discount keeps
+-- the applied promotion's amount until the promotion is re-evaluated. total_amount
+-- is left net of discount only; UpdateOrderCharges then adds service charge and tax.
 UPDATE orders SET
     subtotal = (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1),
     discount_amount = CASE
@@ -114,15 +176,26 @@ UPDATE orders SET
             (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1) * discount_value / 100
         WHEN discount_type = 'FIXED_AMOUNT' THEN LEAST(discount_value, (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1))
         ELSE 0
-    END,
+    END + promotion_amount,
     total_amount = (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1)
         - CASE
             WHEN discount_type = 'PERCENTAGE' THEN
                 (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1) * discount_value / 100
             WHEN discount_type = 'FIXED_AMOUNT' THEN LEAST(discount_value, (SELECT COALESCE(SUM(oi.subtotal), 0) FROM order_items oi WHERE oi.order_id = $1))
             ELSE 0
-        END
-        + tax_amount,
+        END - promotion_amount,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateOrderCharges :one
+UPDATE orders SET
+    service_charge_amount = $2,
+    service_charge_rate = $3,
+    tax_amount = $4,
+    tax_rate = $5,
+    prices_include_tax = $6,
+    total_amount = $7,
     updated_at = now()
 WHERE id = $1
 RETURNING *;
@@ -141,3 +214,19 @@ WHERE o.outlet_id = $1
   )
 ORDER BY o.created_at DESC
 LIMIT $2 OFFSET $3;
+
+-- name: ListActiveOrdersByTable :many
+-- Orders not yet completed or cancelled at a table, oldest first.
+SELECT * FROM orders
+WHERE outlet_id = $1 AND table_number = $2 AND status NOT IN ('COMPLETED', 'CANCELLED')
+ORDER BY created_at;
+
+-- name: UpdateOrderTable :one
+UPDATE orders SET table_number = $3, updated_at = now()
+WHERE id = $1 AND outlet_id = $2 AND status NOT IN ('COMPLETED', 'CANCELLED')
+RETURNING *;
+
+-- name: MoveOrderItems :exec
+-- Moves every item (and with it, its modifiers) from one order onto another.
+UPDATE order_items SET order_id = sqlc.arg('to_order_id')
+WHERE order_id = sqlc.arg('from_order_id');
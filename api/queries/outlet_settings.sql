@@ -0,0 +1,27 @@
+-- name: GetOutletSettings :one
+SELECT * FROM outlet_settings WHERE outlet_id = $1;
+
+-- name: UpsertOutletSettings :one
+INSERT INTO outlet_settings (
+    outlet_id, tax_rate, prices_include_tax, tax_order_types,
+    service_charge_rate, service_charge_order_types,
+    rounding_mode, rounding_unit,
+    receipt_header, receipt_footer, receipt_paper_width
+) VALUES (
+    $1, $2, $3, $4,
+    $5, $6,
+    $7, $8,
+    $9, $10, $11
+)
+ON CONFLICT (outlet_id) DO UPDATE SET
+    tax_rate = EXCLUDED.tax_rate,
+    prices_include_tax = EXCLUDED.prices_include_tax,
+    tax_order_types = EXCLUDED.tax_order_types,
+    service_charge_rate = EXCLUDED.service_charge_rate,
+    service_charge_order_types = EXCLUDED.service_charge_order_types,
+    rounding_mode = EXCLUDED.rounding_mode,
+    rounding_unit = EXCLUDED.rounding_unit,
+    receipt_header = EXCLUDED.receipt_header,
+    receipt_footer = EXCLUDED.receipt_footer,
+    receipt_paper_width = EXCLUDED.receipt_paper_width
+RETURNING *;
@@ -4,12 +4,17 @@ SELECT * FROM orders WHERE id = $1 AND outlet_id = $2 FOR NO KEY UPDATE;
 -- name: CreatePayment :one
 INSERT INTO payments (
     order_id, payment_method, amount, status,
-    reference_number, amount_received, change_amount, processed_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    reference_number, amount_received, change_amount, processed_by,
+    check_id, processed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9,
+    COALESCE(sqlc.narg('processed_at')::timestamptz, now())
+)
 RETURNING *;
 
 -- name: SumPaymentsByOrder :one
-SELECT COALESCE(SUM(amount), 0)::decimal(12,2) AS total_paid
+-- Net of refunds.
+SELECT COALESCE(SUM(amount - refunded_amount), 0)::decimal(12,2) AS total_paid
 FROM payments
 WHERE order_id = $1 AND status = 'COMPLETED';
 
@@ -22,3 +27,20 @@ RETURNING *;
 UPDATE orders SET status = 'COMPLETED', completed_at = now(), updated_at = now()
 WHERE id = $1 AND status != 'CANCELLED'
 RETURNING *;
+
+-- name: GetPaymentForUpdate :one
+SELECT * FROM payments WHERE id = $1 AND order_id = $2 FOR UPDATE;
+
+-- name: AddPaymentRefundedAmount :one
+UPDATE payments SET refunded_amount = refunded_amount + $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreatePaymentRefund :one
+INSERT INTO payment_refunds (
+    payment_id, order_id, amount, reason, refunded_by, authorized_by
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CountRefundsByOrder :one
+SELECT COUNT(*) FROM payment_refunds WHERE order_id = $1;
@@ -0,0 +1,45 @@
+-- name: GetPickupDisplay :one
+SELECT * FROM pickup_displays WHERE outlet_id = $1;
+
+-- name: GetPickupDisplayByToken :one
+SELECT * FROM pickup_displays WHERE token = $1;
+
+-- name: UpsertPickupDisplay :one
+-- The token is only set when the display is first created.
+INSERT INTO pickup_displays (outlet_id, token, order_types, ready_timeout_minutes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (outlet_id) DO UPDATE SET
+    order_types = EXCLUDED.order_types,
+    ready_timeout_minutes = EXCLUDED.ready_timeout_minutes
+RETURNING *;
+
+-- name: RotatePickupDisplayToken :one
+UPDATE pickup_displays SET token = $2
+WHERE outlet_id = $1
+RETURNING *;
+
+-- name: DeletePickupDisplay :exec
+DELETE FROM pickup_displays WHERE outlet_id = $1;
+
+-- name: ListPickupDisplayOrders :many
+-- Orders a pickup screen shows: in progress (skipping ones forgotten for
+-- half a day), or READY within the display's timeout. Oldest first.
+-- ready_at is when the order was marked READY; updated_at moves on later
+-- writes such as payments, so it can't time the READY window.
+SELECT o.order_number, o.status, o.updated_at, r.ready_at
+FROM orders o
+CROSS JOIN LATERAL (
+    SELECT COALESCE(
+        (SELECT MAX(e.created_at) FROM order_events e
+         WHERE e.order_id = o.id AND e.event_type = 'STATUS_CHANGED' AND e.after_value->>'status' = 'READY'),
+        (SELECT MAX(oi.ready_at) FROM order_items oi WHERE oi.order_id = o.id),
+        o.updated_at
+    )::timestamptz AS ready_at
+) r
+WHERE o.outlet_id = sqlc.arg('outlet_id')
+    AND o.order_type = ANY(sqlc.arg('order_types')::text[])
+    AND (
+        (o.status IN ('NEW', 'PREPARING') AND o.created_at > now() - interval '12 hours')
+        OR (o.status = 'READY' AND r.ready_at > now() - make_interval(mins => sqlc.arg('ready_timeout_minutes')::int))
+    )
+ORDER BY o.created_at;
@@ -0,0 +1,77 @@
+-- name: ListPromotions :many
+-- Promotions an outlet offers: its own and those for every outlet.
+SELECT * FROM promotions
+WHERE (outlet_id = sqlc.arg('outlet_id')::uuid OR outlet_id IS NULL) AND is_active = true
+ORDER BY name;
+
+-- name: GetPromotion :one
+SELECT * FROM promotions
+WHERE id = sqlc.arg('id') AND (outlet_id = sqlc.arg('outlet_id')::uuid OR outlet_id IS NULL) AND is_active = true;
+
+-- name: CreatePromotion :one
+INSERT INTO promotions (
+    outlet_id, name, code, promotion_type, category_id, product_id,
+    discount_value, buy_quantity, get_quantity, min_spend,
+    valid_from, valid_until, start_time, end_time, usage_limit
+) VALUES (
+    $1, $2, $3, $4, $5, $6,
+    $7, $8, $9, $10,
+    $11, $12, $13, $14, $15
+) RETURNING *;
+
+-- name: UpdatePromotion :one
+UPDATE promotions SET
+    name = $1, code = $2, promotion_type = $3, category_id = $4, product_id = $5,
+    discount_value = $6, buy_quantity = $7, get_quantity = $8, min_spend = $9,
+    valid_from = $10, valid_until = $11, start_time = $12, end_time = $13, usage_limit = $14
+WHERE id = $15 AND is_active = true
+RETURNING *;
+
+-- name: SoftDeletePromotion :one
+UPDATE promotions SET is_active = false WHERE id = $1 AND is_active = true RETURNING id;
+
+-- name: ListOrderPromotions :many
+-- Promotions an order may receive: the outlet's automatic promotions and the
+-- one matching the entered code, each with how many other orders used it.
+SELECT p.*,
+       (SELECT COUNT(*) FROM orders o
+        WHERE o.promotion_id = p.id AND o.status != 'CANCELLED' AND o.id != sqlc.arg('order_id')) AS used_count
+FROM promotions p
+WHERE (p.outlet_id = sqlc.arg('outlet_id')::uuid OR p.outlet_id IS NULL)
+    AND p.is_active = true
+    AND (p.code IS NULL OR p.code = sqlc.narg('code'))
+ORDER BY p.created_at;
+
+-- name: LockOrderPromotions :exec
+-- Locks the usage-limited promotions an order may receive until the
+-- transaction ends. Run before ListOrderPromotions so its used_count sees
+-- concurrent orders that took the same promotions.
+SELECT p.id FROM promotions p
+WHERE (p.outlet_id = sqlc.arg('outlet_id')::uuid OR p.outlet_id IS NULL)
+    AND p.is_active = true
+    AND p.usage_limit IS NOT NULL
+    AND (p.code IS NULL OR p.code = sqlc.narg('code'))
+ORDER BY p.id
+FOR UPDATE OF p;
+
+-- name: ListPromotionItems :many
+-- Order lines with their product's category; combo components are priced
+-- through their parent line.
+SELECT oi.product_id, p.category_id, oi.quantity, oi.subtotal
+FROM order_items oi
+JOIN products p ON p.id = oi.product_id
+WHERE oi.order_id = $1 AND oi.parent_item_id IS NULL
+ORDER BY oi.id;
+
+-- name: UpdateOrderPromotion :one
+-- Replaces the order's promotion, moving discount_amount and total_amount by
+-- the difference. total_amount stays net of discount only, as after
+-- UpdateOrderTotals.
+UPDATE orders SET
+    promotion_id = $2,
+    promotion_amount = $3,
+    discount_amount = discount_amount - promotion_amount + $3,
+    total_amount = total_amount + promotion_amount - $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,18 @@
+-- name: ListReceiptItems :many
+-- An order's items with the product and variant names a receipt prints.
+SELECT oi.id, oi.parent_item_id, p.name AS product_name,
+    COALESCE((SELECT array_agg(v.name ORDER BY v.name) FROM variants v WHERE v.id = ANY(oi.variant_ids)), '{}')::text[] AS variant_names,
+    oi.quantity, oi.unit_price, oi.discount_amount, oi.subtotal, oi.notes
+FROM order_items oi
+JOIN products p ON p.id = oi.product_id
+WHERE oi.order_id = $1
+ORDER BY oi.sent_at, oi.id;
+
+-- name: ListReceiptItemModifiers :many
+-- Modifiers of all of an order's items, with their names.
+SELECT oim.order_item_id, m.name, oim.quantity, oim.unit_price
+FROM order_item_modifiers oim
+JOIN order_items oi ON oi.id = oim.order_item_id
+JOIN modifiers m ON m.id = oim.modifier_id
+WHERE oi.order_id = $1
+ORDER BY m.name;
@@ -1,11 +1,18 @@
 -- name: GetDailySales :many
+-- Refunds are attributed to the sale date of the refunded order.
 SELECT
     DATE(o.created_at) AS sale_date,
     COUNT(o.id) AS order_count,
     SUM(o.total_amount)::decimal(12,2) AS total_revenue,
     SUM(o.discount_amount)::decimal(12,2) AS total_discount,
-    (SUM(o.total_amount) - SUM(o.discount_amount))::decimal(12,2) AS net_revenue
+    COALESCE(SUM(r.refund_amount), 0)::decimal(12,2) AS total_refunds,
+    (SUM(o.total_amount) - SUM(o.discount_amount) - COALESCE(SUM(r.refund_amount), 0))::decimal(12,2) AS net_revenue
 FROM orders o
+LEFT JOIN (
+    SELECT order_id, SUM(amount) AS refund_amount
+    FROM payment_refunds
+    GROUP BY order_id
+) r ON r.order_id = o.id
 WHERE o.outlet_id = $1
     AND o.status != 'CANCELLED'
     AND o.created_at >= $2
@@ -24,6 +31,7 @@ JOIN orders o ON o.id = oi.order_id
 JOIN products p ON p.id = oi.product_id
 WHERE o.outlet_id = $1
     AND o.status != 'CANCELLED'
+    AND oi.parent_item_id IS NULL
     AND o.created_at >= $2
     AND o.created_at < $3
 GROUP BY p.id, p.name
@@ -31,18 +39,35 @@ ORDER BY quantity_sold DESC
 LIMIT $4;
 
 -- name: GetPaymentSummary :many
+-- Payments are counted on their processed date and refunds on their refund date.
 SELECT
-    p.payment_method,
-    COUNT(p.id) AS transaction_count,
-    SUM(p.amount)::decimal(12,2) AS total_amount
-FROM payments p
-JOIN orders o ON o.id = p.order_id
-WHERE o.outlet_id = $1
-    AND o.status != 'CANCELLED'
-    AND p.processed_at >= $2
-    AND p.processed_at < $3
-GROUP BY p.payment_method
-ORDER BY p.payment_method;
+    t.payment_method,
+    COUNT(*) FILTER (WHERE t.kind = 'PAYMENT') AS transaction_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'PAYMENT'), 0)::decimal(12,2) AS total_amount,
+    COUNT(*) FILTER (WHERE t.kind = 'REFUND') AS refund_count,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'REFUND'), 0)::decimal(12,2) AS refund_amount,
+    (COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'PAYMENT'), 0)
+        - COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'REFUND'), 0))::decimal(12,2) AS net_amount
+FROM (
+    SELECT p.payment_method, p.amount, 'PAYMENT' AS kind
+    FROM payments p
+    JOIN orders o ON o.id = p.order_id
+    WHERE o.outlet_id = $1
+        AND o.status != 'CANCELLED'
+        AND p.processed_at >= $2
+        AND p.processed_at < $3
+    UNION ALL
+    SELECT p.payment_method, r.amount, 'REFUND' AS kind
+    FROM payment_refunds r
+    JOIN payments p ON p.id = r.payment_id
+    JOIN orders o ON o.id = r.order_id
+    WHERE o.outlet_id = $1
+        AND o.status != 'CANCELLED'
+        AND r.created_at >= $2
+        AND r.created_at < $3
+) t
+GROUP BY t.payment_method
+ORDER BY t.payment_method;
 
 -- name: GetHourlySales :many
 SELECT
@@ -71,3 +96,65 @@ LEFT JOIN orders o ON o.outlet_id = outlets.id
 WHERE outlets.is_active = true
 GROUP BY outlets.id, outlets.name
 ORDER BY total_revenue DESC;
+
+-- name: GetPrepTimesByProduct :many
+-- Kitchen timing for items that reached READY, by when they were sent.
+-- prep is sent to ready, cook is started to ready. Combo lines are left out;
+-- their components are counted instead.
+SELECT
+    p.id AS product_id,
+    p.name AS product_name,
+    p.preparation_time,
+    COUNT(*) AS item_count,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.sent_at)))), 0)::bigint AS avg_prep_seconds,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.started_at)))), 0)::bigint AS avg_cook_seconds,
+    COUNT(*) FILTER (WHERE p.preparation_time > 0
+        AND oi.ready_at > oi.sent_at + make_interval(mins => p.preparation_time)) AS late_count
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+JOIN products p ON p.id = oi.product_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND oi.ready_at IS NOT NULL
+    AND oi.sent_at >= $2
+    AND oi.sent_at < $3
+    AND NOT EXISTS (SELECT 1 FROM order_items c WHERE c.parent_item_id = oi.id)
+GROUP BY p.id, p.name, p.preparation_time
+ORDER BY avg_prep_seconds DESC;
+
+-- name: GetPrepTimesByStation :many
+SELECT
+    oi.station,
+    COUNT(*) AS item_count,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.sent_at)))), 0)::bigint AS avg_prep_seconds,
+    COALESCE(ROUND(AVG(EXTRACT(EPOCH FROM (oi.ready_at - oi.started_at)))), 0)::bigint AS avg_cook_seconds,
+    COUNT(*) FILTER (WHERE p.preparation_time > 0
+        AND oi.ready_at > oi.sent_at + make_interval(mins => p.preparation_time)) AS late_count
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+JOIN products p ON p.id = oi.product_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND oi.ready_at IS NOT NULL
+    AND oi.station IS NOT NULL
+    AND oi.sent_at >= $2
+    AND oi.sent_at < $3
+GROUP BY oi.station
+ORDER BY oi.station;
+
+-- name: GetPromotionUsage :many
+-- Orders that received each promotion and the discount it gave.
+SELECT
+    p.id AS promotion_id,
+    p.name AS promotion_name,
+    p.code,
+    COUNT(o.id) AS order_count,
+    SUM(o.promotion_amount)::decimal(12,2) AS total_discount
+FROM orders o
+JOIN promotions p ON p.id = o.promotion_id
+WHERE o.outlet_id = $1
+    AND o.status != 'CANCELLED'
+    AND o.created_at >= $2
+    AND o.created_at < $3
+GROUP BY p.id, p.name, p.code
+ORDER BY total_discount DESC;
@@ -0,0 +1,29 @@
+-- name: NextWSEventSeq :one
+-- The row lock is held until commit, so an outlet's events commit (and are
+-- notified) in seq order.
+INSERT INTO ws_outlet_sequences (outlet_id, last_seq)
+VALUES ($1, 1)
+ON CONFLICT (outlet_id) DO UPDATE
+SET last_seq = ws_outlet_sequences.last_seq + 1
+RETURNING last_seq;
+
+-- name: CreateWSEvent :exec
+INSERT INTO ws_events (outlet_id, seq, event_type, payload, stations)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: PruneWSEvents :exec
+DELETE FROM ws_events
+WHERE outlet_id = $1 AND seq <= $2;
+
+-- name: NotifyWSEvent :exec
+SELECT pg_notify('ws_events', sqlc.arg(message)::text);
+
+-- name: GetWSEvent :one
+SELECT * FROM ws_events
+WHERE outlet_id = $1 AND seq = $2;
+
+-- name: ListWSEventsSince :many
+-- An outlet's stored events after seq, oldest first.
+SELECT * FROM ws_events
+WHERE outlet_id = $1 AND seq > $2
+ORDER BY seq;